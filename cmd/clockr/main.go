@@ -1,36 +1,91 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"maps"
+	"net/http"
 	"os"
+	"os/exec"
 	"os/signal"
+	"path/filepath"
+	"runtime"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
+	"text/tabwriter"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/tj/go-naturaldate"
 	"github.com/christopherklint97/clockr/internal/ai"
+	"github.com/christopherklint97/clockr/internal/audit"
+	"github.com/christopherklint97/clockr/internal/backend"
 	"github.com/christopherklint97/clockr/internal/calendar"
+	"github.com/christopherklint97/clockr/internal/clipboard"
 	"github.com/christopherklint97/clockr/internal/clockify"
 	"github.com/christopherklint97/clockr/internal/config"
+	"github.com/christopherklint97/clockr/internal/demo"
+	"github.com/christopherklint97/clockr/internal/digest"
+	"github.com/christopherklint97/clockr/internal/export"
+	"github.com/christopherklint97/clockr/internal/format"
 	"github.com/christopherklint97/clockr/internal/github"
+	"github.com/christopherklint97/clockr/internal/harvest"
+	"github.com/christopherklint97/clockr/internal/httpcache"
+	"github.com/christopherklint97/clockr/internal/httpmetrics"
+	"github.com/christopherklint97/clockr/internal/journal"
+	"github.com/christopherklint97/clockr/internal/localdata"
+	"github.com/christopherklint97/clockr/internal/mirror"
 	"github.com/christopherklint97/clockr/internal/msgraph"
+	"github.com/christopherklint97/clockr/internal/plain"
+	"github.com/christopherklint97/clockr/internal/plugin"
+	"github.com/christopherklint97/clockr/internal/quality"
+	"github.com/christopherklint97/clockr/internal/release"
 	"github.com/christopherklint97/clockr/internal/scheduler"
+	"github.com/christopherklint97/clockr/internal/secrets"
+	"github.com/christopherklint97/clockr/internal/server"
+	"github.com/christopherklint97/clockr/internal/sources"
+	"github.com/christopherklint97/clockr/internal/stats"
+	"github.com/christopherklint97/clockr/internal/status"
 	"github.com/christopherklint97/clockr/internal/store"
+	"github.com/christopherklint97/clockr/internal/toggl"
 	"github.com/christopherklint97/clockr/internal/tui"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/tj/go-naturaldate"
+)
+
+// Set at build time via -ldflags (see .goreleaser.yaml).
+var (
+	version    = "dev"
+	signingKey = "" // base64 ed25519 public key used to sign release checksums
 )
 
 var rootCmd = &cobra.Command{
-	Use:   "clockr",
-	Short: "Time-tracking assistant powered by AI",
-	Long:  "clockr prompts you periodically, takes plain-English descriptions of your work, and creates Clockify time entries.",
+	Use:               "clockr",
+	Version:           version,
+	Short:             "Time-tracking assistant powered by AI",
+	Long:              "clockr prompts you periodically, takes plain-English descriptions of your work, and creates Clockify time entries.",
+	PersistentPreRunE: setupGlobals,
 }
 
+// readOnly disables Clockify writes and DB mutations for this invocation.
+// Set from --read-only or read_only in config by setupGlobals.
+var readOnly bool
+
+// outputJSON makes read commands print JSON instead of text. Set from
+// --output by setupGlobals.
+var outputJSON bool
+
 var startCmd = &cobra.Command{
 	Use:   "start",
 	Short: "Start the time-tracking scheduler",
@@ -43,36 +98,356 @@ var stopCmd = &cobra.Command{
 	RunE:  runStop,
 }
 
+var promptNowCmd = &cobra.Command{
+	Use:   "prompt-now",
+	Short: "Open the scheduled prompt immediately (run when a notification is clicked)",
+	RunE:  runPromptNow,
+}
+
+var pauseCmd = &cobra.Command{
+	Use:   "pause [duration | until DATE|HH:MM]",
+	Short: "Pause scheduled prompts (e.g. 2h, until 14:00, until monday)",
+	RunE:  runPause,
+}
+
+var resumeCmd = &cobra.Command{
+	Use:   "resume",
+	Short: "Resume scheduled prompts",
+	Args:  cobra.NoArgs,
+	RunE:  runResume,
+}
+
+var triggerCmd = &cobra.Command{
+	Use:   "trigger",
+	Short: "Open a prompt now in the running scheduler's terminal",
+	RunE:  runControl(scheduler.CmdPromptNow),
+}
+
+var reloadCmd = &cobra.Command{
+	Use:   "reload",
+	Short: "Make the running scheduler re-read config.toml",
+	RunE:  runControl(scheduler.CmdReloadConfig),
+}
+
+var skipCmd = &cobra.Command{
+	Use:   "skip",
+	Short: "Mark the pending (or last) prompt window as deliberately untracked",
+	RunE:  runSkip,
+}
+
 var logCmd = &cobra.Command{
 	Use:   "log",
 	Short: "Log a time entry interactively",
 	RunE:  runLog,
 }
 
+var digestCmd = &cobra.Command{
+	Use:   "digest",
+	Short: "Print last week's digest (totals per project, gaps, failed entries), or send it with --send",
+	RunE:  runDigest,
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Write a month's (or a date range's) entries in an accounting tool's import format",
+	RunE:  runExport,
+}
+
+var journalCmd = &cobra.Command{
+	Use:   "journal",
+	Short: "Print what you typed at each prompt and the entries it became, as a markdown work journal",
+	RunE:  runJournal,
+}
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show weekly trends per project and client, projects per day, and how the AI's suggestions fared",
+	Args:  cobra.NoArgs,
+	RunE:  runStats,
+}
+
+var demoCmd = &cobra.Command{
+	Use:   "demo",
+	Short: "Walk through logging, batch logging and reporting against a sandbox (no accounts needed)",
+	RunE:  runDemo,
+}
+
+var quickCmd = &cobra.Command{
+	Use:   "quick <description>",
+	Short: "Log a description without the TUI, for launchers: matched in the background, result shown as a notification",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runQuick,
+}
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve a token-guarded local HTTP API for launchers and buttons (today's entries, log a description, prompt now)",
+	RunE:  runServe,
+}
+
 var statusCmd = &cobra.Command{
 	Use:   "status",
-	Short: "Show today's logged entries",
+	Short: "Show logged entries and gaps for today, a past day, a week or a month",
 	RunE:  runStatus,
 }
 
+var standupCmd = &cobra.Command{
+	Use:   "standup",
+	Short: "Draft a yesterday/today/blockers standup from your entries and calendar",
+	RunE:  runStandup,
+}
+
 var projectsCmd = &cobra.Command{
 	Use:   "projects",
 	Short: "List Clockify projects",
 	RunE:  runProjects,
 }
 
+var pluginsCmd = &cobra.Command{
+	Use:   "plugins",
+	Short: "List plugin executables and what they provide",
+	RunE:  runPlugins,
+}
+
+var migrateWorkspaceCmd = &cobra.Command{
+	Use:   "migrate-workspace [WORKSPACE_ID]",
+	Short: "Switch to a new Clockify workspace, mapping old projects to new ones by name",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runMigrateWorkspace,
+}
+
 var clearFailedCmd = &cobra.Command{
 	Use:   "clear-failed",
 	Short: "Delete all failed time entries from the local database",
 	RunE:  runClearFailed,
 }
 
+var retryCmd = &cobra.Command{
+	Use:   "retry",
+	Short: "Re-submit failed time entries to Clockify",
+	RunE:  runRetry,
+}
+
+var auditDiffCmd = &cobra.Command{
+	Use:   "audit-diff",
+	Short: "Compare local entries with Clockify and fix missing, duplicated, shifted or diverged ones",
+	RunE:  runAuditDiff,
+}
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Pull edits made in Clockify into local entries and resolve entries edited on both sides",
+	RunE:  runSync,
+}
+
+var gapsCmd = &cobra.Command{
+	Use:   "gaps",
+	Short: "List work hours with nothing logged locally or in Clockify, and offer to log each one",
+	RunE:  runGaps,
+}
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify <file>",
+	Short: "Verify a downloaded clockr release artifact against signed checksums",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runVerify,
+}
+
+var checkCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check that API keys and tokens grant the access clockr needs",
+	RunE:  runCheck,
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose the setup: config, database, AI provider, API access and scheduler",
+	RunE:  runDoctor,
+}
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Interactive setup: verify credentials and write config.toml",
+	RunE:  runInit,
+}
+
 var configCmd = &cobra.Command{
 	Use:   "config",
 	Short: "Open config file in your editor",
 	RunE:  runConfig,
 }
 
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check config.toml for unknown keys and invalid values",
+	RunE:  runConfigValidate,
+}
+
+var recurringCmd = &cobra.Command{
+	Use:   "recurring",
+	Short: "Manage [[recurring]] entries the scheduler logs without prompting",
+}
+
+var recurringListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List recurring entries and when each was last logged",
+	Args:  cobra.NoArgs,
+	RunE:  runRecurringList,
+}
+
+var recurringAddCmd = &cobra.Command{
+	Use:   "add NAME",
+	Short: "Add a recurring entry to config.toml",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runRecurringAdd,
+}
+
+var recurringDisableCmd = &cobra.Command{
+	Use:   "disable NAME",
+	Short: "Stop logging a recurring entry",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runRecurringDisable,
+}
+
+var recurringEnableCmd = &cobra.Command{
+	Use:   "enable NAME",
+	Short: "Resume logging a disabled recurring entry",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runRecurringEnable,
+}
+
+var templatesCmd = &cobra.Command{
+	Use:     "templates",
+	Aliases: []string{"snippets"},
+	Short:   "Manage description snippets inserted with Ctrl+T",
+}
+
+var templatesAddCmd = &cobra.Command{
+	Use:   "add NAME TEXT",
+	Short: "Save a snippet (replaces one with the same name)",
+	Args:  cobra.MinimumNArgs(2),
+	RunE:  runTemplatesAdd,
+}
+
+var templatesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List saved snippets",
+	Args:  cobra.NoArgs,
+	RunE:  runTemplatesList,
+}
+
+var templatesRmCmd = &cobra.Command{
+	Use:   "rm NAME",
+	Short: "Delete a snippet",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runTemplatesRm,
+}
+
+var entryCmd = &cobra.Command{
+	Use:   "entry",
+	Short: "Inspect logged entries",
+}
+
+var entryShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Show an entry with the AI suggestion it was logged from",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runEntryShow,
+}
+
+var configPromptsCmd = &cobra.Command{
+	Use:   "prompts",
+	Short: "Copy the default AI prompt templates into the config dir for editing",
+	RunE:  runConfigPrompts,
+}
+
+var debugCmd = &cobra.Command{
+	Use:   "debug",
+	Short: "Inspect the running scheduler",
+}
+
+var debugHTTPStatsCmd = &cobra.Command{
+	Use:   "http-stats",
+	Short: "Show the running scheduler's API request counts, latencies and errors",
+	Args:  cobra.NoArgs,
+	RunE:  runDebugHTTPStats,
+}
+
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Inspect and maintain the local database",
+}
+
+var dbMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Apply pending schema migrations, show them (--status) or roll back (--to N)",
+	Args:  cobra.NoArgs,
+	RunE:  runDBMigrate,
+}
+
+var dbVacuumCmd = &cobra.Command{
+	Use:   "vacuum",
+	Short: "Rebuild the database file to reclaim space from deleted rows",
+	Args:  cobra.NoArgs,
+	RunE:  runDBVacuum,
+}
+
+var dbBackupCmd = &cobra.Command{
+	Use:   "backup [PATH]",
+	Short: "Copy the database to PATH (default clockr-backup-DATE.db), safely while clockr runs",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runDBBackup,
+}
+
+var dbPruneCmd = &cobra.Command{
+	Use:   "prune --older-than AGE",
+	Short: "Delete entries and history older than AGE (e.g. 90d, 6m, 1y)",
+	Args:  cobra.NoArgs,
+	RunE:  runDBPrune,
+}
+
+var dataCmd = &cobra.Command{
+	Use:   "data",
+	Short: "Review or remove the data clockr stores locally",
+}
+
+var dataExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export all local data (entries, raw inputs, state, redacted config) to a zip archive",
+	RunE:  runDataExport,
+}
+
+var dataWipeCmd = &cobra.Command{
+	Use:   "wipe",
+	Short: "Delete all local data (entries already in Clockify are not affected)",
+	RunE:  runDataWipe,
+}
+
+var secretsCmd = &cobra.Command{
+	Use:   "secrets",
+	Short: "Manage credentials stored in the OS keychain",
+}
+
+var secretsStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show where each credential is stored",
+	RunE:  runSecretsStatus,
+}
+
+var secretsMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Move plaintext credentials from config files into the OS keychain",
+	RunE:  runSecretsMigrate,
+}
+
+var secretsSetCmd = &cobra.Command{
+	Use:       "set NAME",
+	Short:     "Store a credential in the OS keychain (reads the value from stdin)",
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: secrets.Names,
+	RunE:      runSecretsSet,
+}
+
 var calendarCmd = &cobra.Command{
 	Use:   "calendar",
 	Short: "Calendar integration commands",
@@ -90,6 +465,23 @@ var calendarAuthCmd = &cobra.Command{
 	RunE:  runCalendarAuth,
 }
 
+var mirrorCmd = &cobra.Command{
+	Use:   "mirror",
+	Short: "Copy logged entries to the [mirror] destinations",
+}
+
+var mirrorSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Mirror logged entries that have not reached every destination",
+	RunE:  runMirrorSync,
+}
+
+var mirrorStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show where each logged entry has been mirrored",
+	RunE:  runMirrorStatus,
+}
+
 var githubCmd = &cobra.Command{
 	Use:   "github",
 	Short: "GitHub integration commands",
@@ -109,26 +501,173 @@ var githubReposResetCmd = &cobra.Command{
 
 func init() {
 	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "Enable verbose debug logging")
+	rootCmd.PersistentFlags().Bool("read-only", false, "Disable Clockify writes and local database changes")
+	rootCmd.PersistentFlags().Duration("timeout", 0, "Use this timeout (e.g. 45s, 3m) for Clockify, context fetches and AI calls instead of [timeouts]")
+	rootCmd.PersistentFlags().String("output", "text", "Output format for status, stats, projects, entry show, calendar test, github repos, debug http-stats and doctor: text or json")
+	rootCmd.PersistentFlags().String("config", "", "Path to config.toml (default: $CLOCKR_HOME, $XDG_CONFIG_HOME/clockr or ~/.config/clockr)")
 
+	startCmd.Flags().String("debug-addr", "", "Serve pprof and expvar on this localhost address (e.g. 127.0.0.1:6060) for debugging")
 	logCmd.Flags().Bool("same", false, "Log the same project/description as the last entry")
 	logCmd.Flags().Bool("repeat", false, "Pre-fill the textarea with the last description")
 	logCmd.Flags().String("from", "", "Start date (YYYY-MM-DD, or natural: monday, last friday, etc.)")
 	logCmd.Flags().String("to", "", "End date (YYYY-MM-DD, or natural: friday, today, etc.)")
 	logCmd.Flags().Bool("github", false, "Include GitHub commit/PR context from saved repos")
 	logCmd.Flags().Bool("prompt-file", false, "Write prompt to file and clipboard instead of calling the AI API")
+	logCmd.Flags().Bool("overtime", false, "Tag the entry as overtime (work outside configured hours)")
+	logCmd.Flags().Bool("dry-run", false, "With --from/--to: preview the entries without creating them in Clockify")
+	logCmd.Flags().Bool("resume", false, "Reopen the last suggestion that was neither logged nor skipped")
+	logCmd.Flags().Bool("manual", false, "Pick the project, minutes and description yourself without the AI")
+	auditDiffCmd.Flags().String("from", "today", "Start date (YYYY-MM-DD, or natural: monday, last friday, etc.)")
+	auditDiffCmd.Flags().String("to", "", "End date, inclusive (default: same as --from)")
+	syncCmd.Flags().String("from", "today", "Start date (YYYY-MM-DD, or natural: monday, last friday, etc.)")
+	syncCmd.Flags().String("to", "", "End date, inclusive (default: same as --from)")
+	gapsCmd.Flags().String("from", "", "Start date (YYYY-MM-DD, or natural: monday, last friday, etc.; default: first of this month)")
+	gapsCmd.Flags().String("to", "today", "End date, inclusive")
+	for _, c := range []*cobra.Command{mirrorSyncCmd, mirrorStatusCmd} {
+		c.Flags().String("from", "today", "Start date (YYYY-MM-DD, or natural: monday, last friday, etc.)")
+		c.Flags().String("to", "", "End date, inclusive (default: same as --from)")
+	}
+	logCmd.Flags().Bool("stdin", false, "Read the work description from stdin, e.g. git log --oneline | clockr log --stdin")
+	logCmd.Flags().Bool("plain", false, "Ask with line-based prompts and numbered lists instead of the full-screen UI (screen readers, dumb terminals)")
+
+	statusCmd.Flags().Bool("copy", false, "Copy the output to the clipboard")
+	statusCmd.Flags().String("date", "", "Show this day instead of today (YYYY-MM-DD, or natural: yesterday, last friday, etc.)")
+	statusCmd.Flags().Bool("week", false, "Show per-day subtotals for the week (Monday–Sunday) of --date or today")
+	statusCmd.Flags().Bool("month", false, "Show per-day subtotals for the month of --date or today")
+	for _, c := range []*cobra.Command{statusCmd, statsCmd} {
+		c.Flags().String("source", "", "Only entries logged this way ("+strings.Join(store.Sources, ", ")+")")
+		c.Flags().Bool("billable", false, "Only billable entries")
+		c.Flags().Bool("non-billable", false, "Only non-billable entries")
+		c.Flags().String("tag", "", "Only entries with this Clockify tag ID")
+	}
+	skipCmd.Flags().String("reason", "", "Why the window is untracked (e.g. lunch, personal, meeting-overrun)")
+	pauseCmd.Flags().String("reason", "", "Why prompts are paused (e.g. vacation, sick)")
+	standupCmd.Flags().Bool("copy", false, "Copy the standup to the clipboard")
+
+	digestCmd.Flags().String("date", "", "Summarise the week before this date's week (default: today, i.e. last week)")
+	digestCmd.Flags().Bool("send", false, "Deliver through the channels in [digest] instead of printing")
+	exportCmd.Flags().String("month", "", "Month to export as YYYY-MM, or \"last\" (default: this month)")
+	exportCmd.Flags().String("from", "", "Start date instead of --month (YYYY-MM-DD, or natural: monday, last friday, etc.)")
+	exportCmd.Flags().String("to", "", "End date, inclusive, with --from")
+	exportCmd.Flags().String("profile", "", "Format: csv, datev, quickbooks or template (default: [export] profile, else csv)")
+	exportCmd.Flags().String("template", "", "text/template file for --profile template (default: [export] template)")
+	exportCmd.Flags().String("out", "", "File to write (default: stdout)")
+	journalCmd.Flags().String("from", "", "First day to include (YYYY-MM-DD, or natural: monday, last friday, etc.; default: the first entry)")
+	journalCmd.Flags().String("to", "", "Last day to include (default: today)")
+	journalCmd.Flags().String("search", "", "Only include prompts whose input, descriptions or projects contain this text")
+	quickCmd.Flags().Int("minutes", 0, "Length of the window ending now (default: today's prompt interval)")
+	quickCmd.Flags().Bool("wait", false, "Match and log in the foreground and print the result instead of notifying")
+	quickCmd.Flags().Bool("notify", false, "Report the result as a desktop notification")
+	quickCmd.Flags().MarkHidden("notify")
+	serveCmd.Flags().String("listen", "", "Address to listen on (default: [serve] listen, else 127.0.0.1:8787)")
+	journalCmd.Flags().String("dir", "", "Write one journal-YYYY-MM.md file per month into this directory instead of printing")
+
+	dataExportCmd.Flags().StringP("output", "o", "", "Archive path (default: clockr-export-<timestamp>.zip)")
+	dataWipeCmd.Flags().Bool("yes", false, "Don't ask for confirmation")
+	dataWipeCmd.Flags().Bool("keep-config", false, "Keep config.toml")
+
+	for _, c := range []*cobra.Command{logCmd, auditDiffCmd, syncCmd, gapsCmd, mirrorSyncCmd, mirrorStatusCmd} {
+		c.RegisterFlagCompletionFunc("from", completeDates)
+		c.RegisterFlagCompletionFunc("to", completeDates)
+	}
+	statusCmd.RegisterFlagCompletionFunc("date", completeDates)
+	digestCmd.RegisterFlagCompletionFunc("date", completeDates)
+	exportCmd.RegisterFlagCompletionFunc("from", completeDates)
+	exportCmd.RegisterFlagCompletionFunc("to", completeDates)
+	exportCmd.RegisterFlagCompletionFunc("profile", cobra.FixedCompletions(export.ProfileNames, cobra.ShellCompDirectiveNoFileComp))
+	statsCmd.Flags().Int("weeks", 8, "Number of weeks to cover, ending with this one")
+	for _, c := range []*cobra.Command{statusCmd, statsCmd} {
+		c.RegisterFlagCompletionFunc("source", cobra.FixedCompletions(store.Sources, cobra.ShellCompDirectiveNoFileComp))
+	}
+	journalCmd.RegisterFlagCompletionFunc("from", completeDates)
+	journalCmd.RegisterFlagCompletionFunc("to", completeDates)
+	skipCmd.RegisterFlagCompletionFunc("reason", completeSkipReasons)
+	pauseCmd.RegisterFlagCompletionFunc("reason", completePauseReasons)
+	verifyCmd.Flags().String("release", "", "Release version to verify against (default: this binary's version)")
+	verifyCmd.Flags().String("checksums", "", "Local checksums file (skips download; requires --signature)")
+	verifyCmd.Flags().String("signature", "", "Local signature file for --checksums")
 
 	rootCmd.AddCommand(startCmd)
 	rootCmd.AddCommand(stopCmd)
+	rootCmd.AddCommand(pauseCmd)
+	rootCmd.AddCommand(resumeCmd)
+	rootCmd.AddCommand(triggerCmd)
+	rootCmd.AddCommand(reloadCmd)
+	rootCmd.AddCommand(promptNowCmd)
+	rootCmd.AddCommand(skipCmd)
 	rootCmd.AddCommand(logCmd)
+	rootCmd.AddCommand(auditDiffCmd)
+	rootCmd.AddCommand(syncCmd)
+	rootCmd.AddCommand(gapsCmd)
+	rootCmd.AddCommand(demoCmd)
+	rootCmd.AddCommand(journalCmd)
+	rootCmd.AddCommand(statsCmd)
+	entryCmd.AddCommand(entryShowCmd)
+	recurringAddCmd.Flags().String("project", "", "Project ID or name (required)")
+	recurringAddCmd.Flags().String("time", "", "Start time, HH:MM (required)")
+	recurringAddCmd.Flags().Int("minutes", 0, "Length in minutes (required)")
+	recurringAddCmd.Flags().String("description", "", "Entry description (default: the name)")
+	recurringAddCmd.Flags().StringSlice("days", nil, "Weekdays, e.g. monday,wednesday (default: work days)")
+	recurringAddCmd.Flags().Bool("suggest", false, "Mention it to the AI in the covering prompt instead of logging it on its own")
+	recurringCmd.AddCommand(recurringListCmd)
+	recurringCmd.AddCommand(recurringAddCmd)
+	recurringCmd.AddCommand(recurringDisableCmd)
+	recurringCmd.AddCommand(recurringEnableCmd)
+	rootCmd.AddCommand(recurringCmd)
+	templatesCmd.AddCommand(templatesAddCmd)
+	templatesCmd.AddCommand(templatesListCmd)
+	templatesCmd.AddCommand(templatesRmCmd)
+	rootCmd.AddCommand(templatesCmd)
+	rootCmd.AddCommand(entryCmd)
+	rootCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(digestCmd)
+	rootCmd.AddCommand(serveCmd)
+	rootCmd.AddCommand(quickCmd)
 	rootCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(standupCmd)
 	rootCmd.AddCommand(projectsCmd)
+	rootCmd.AddCommand(pluginsCmd)
+	rootCmd.AddCommand(migrateWorkspaceCmd)
 	rootCmd.AddCommand(clearFailedCmd)
+	rootCmd.AddCommand(retryCmd)
+	rootCmd.AddCommand(verifyCmd)
+	rootCmd.AddCommand(checkCmd)
+	rootCmd.AddCommand(doctorCmd)
+	rootCmd.AddCommand(initCmd)
+	configCmd.AddCommand(configValidateCmd)
+	configCmd.AddCommand(configPromptsCmd)
 	rootCmd.AddCommand(configCmd)
 
+	debugCmd.AddCommand(debugHTTPStatsCmd)
+	rootCmd.AddCommand(debugCmd)
+
+	dbMigrateCmd.Flags().Bool("status", false, "List migrations and whether each is applied, without changing anything")
+	dbMigrateCmd.Flags().Int("to", -1, "Roll back to this schema version (drops what later migrations added)")
+	dbMigrateCmd.Flags().Bool("yes", false, "Don't ask for confirmation before rolling back")
+	dbPruneCmd.Flags().String("older-than", "", "Age to keep, as a number of days, weeks, months or years: 90d, 8w, 6m, 1y")
+	dbPruneCmd.Flags().Bool("yes", false, "Don't ask for confirmation")
+	dbPruneCmd.Flags().Bool("vacuum", true, "Vacuum afterwards so the file shrinks")
+	dbPruneCmd.MarkFlagRequired("older-than")
+	dbCmd.AddCommand(dbMigrateCmd, dbVacuumCmd, dbBackupCmd, dbPruneCmd)
+	rootCmd.AddCommand(dbCmd)
+	dataCmd.AddCommand(dataExportCmd)
+	dataCmd.AddCommand(dataWipeCmd)
+	rootCmd.AddCommand(dataCmd)
+
+	secretsCmd.AddCommand(secretsStatusCmd)
+	secretsCmd.AddCommand(secretsMigrateCmd)
+	secretsCmd.AddCommand(secretsSetCmd)
+	rootCmd.AddCommand(secretsCmd)
+
 	calendarCmd.AddCommand(calendarTestCmd)
 	calendarCmd.AddCommand(calendarAuthCmd)
+	calendarAuthCmd.Flags().String("profile", "", "Name of a second Microsoft account to sign in, e.g. work or personal (default: the main sign-in)")
 	rootCmd.AddCommand(calendarCmd)
 
+	mirrorCmd.AddCommand(mirrorSyncCmd)
+	mirrorCmd.AddCommand(mirrorStatusCmd)
+	rootCmd.AddCommand(mirrorCmd)
+
 	githubReposCmd.AddCommand(githubReposResetCmd)
 	githubCmd.AddCommand(githubReposCmd)
 	rootCmd.AddCommand(githubCmd)
@@ -140,12 +679,138 @@ func main() {
 	}
 }
 
+// setupGlobals applies global flags before any command runs: the config
+// path, timeouts, migration of files from the legacy directory, and
+// read-only mode.
+func setupGlobals(cmd *cobra.Command, args []string) error {
+	switch output, _ := cmd.Flags().GetString("output"); output {
+	case "text":
+	case "json":
+		outputJSON = true
+	default:
+		return fmt.Errorf("invalid --output %q: use text or json", output)
+	}
+	if path, _ := cmd.Flags().GetString("config"); path != "" {
+		config.SetConfigPath(path)
+	}
+	if timeout, _ := cmd.Flags().GetDuration("timeout"); timeout > 0 {
+		config.SetTimeoutOverride(timeout)
+	}
+
+	moved, err := config.MigrateLegacyFiles()
+	for _, path := range moved {
+		fmt.Fprintf(os.Stderr, "Moved %s\n", path)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: migrating files from ~/.config/clockr: %v\n", err)
+	}
+
+	readOnly = resolveReadOnly(cmd)
+	return nil
+}
+
+// resolveReadOnly combines --read-only, CLOCKR_READ_ONLY and read_only in
+// the config. The environment counts even when the config doesn't load.
+func resolveReadOnly(cmd *cobra.Command) bool {
+	if ro, _ := cmd.Flags().GetBool("read-only"); ro || config.ReadOnlyFromEnv() {
+		return true
+	}
+	cfg, err := config.Load()
+	return err == nil && cfg.ReadOnly
+}
+
+// writeJSON prints v as indented JSON for --output json.
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("encoding JSON: %w", err)
+	}
+	return nil
+}
+
+// completeDates offers natural dates and the last week's dates for --from/--to.
+func completeDates(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	dates := []string{"today", "yesterday", "monday", "tuesday", "wednesday", "thursday", "friday"}
+	now := time.Now()
+	for i := range 7 {
+		dates = append(dates, now.AddDate(0, 0, -i).Format("2006-01-02"))
+	}
+	return dates, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeSkipReasons offers skip_reasons from the config, then reasons used
+// before, most used first.
+func completeSkipReasons(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if path, _ := cmd.Flags().GetString("config"); path != "" {
+		config.SetConfigPath(path)
+	}
+	var reasons []string
+	if cfg, err := config.Load(); err == nil {
+		reasons = append(reasons, cfg.Schedule.SkipReasons...)
+	}
+	if db, err := store.Open(readOnly); err == nil {
+		defer db.Close()
+		if skips, err := db.AllSkips(); err == nil {
+			for _, t := range store.SkipTotals(skips) {
+				reasons = append(reasons, t.Reason)
+			}
+		}
+	}
+	return completionList(reasons), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completePauseReasons offers the reasons of earlier pauses.
+func completePauseReasons(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var reasons []string
+	if db, err := store.Open(readOnly); err == nil {
+		defer db.Close()
+		if pauses, err := db.AllPauses(); err == nil {
+			for _, p := range pauses {
+				reasons = append(reasons, p.Reason)
+			}
+		}
+	}
+	return completionList(reasons), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completionList drops empty and repeated values, keeping the first of each.
+func completionList(values []string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, v := range values {
+		if v != "" && !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// openStore opens the local database, honoring read-only mode.
+func openStore() (*store.DB, error) {
+	return store.Open(readOnly)
+}
+
 func loadConfig() (*config.Config, error) {
 	cfg, err := config.Load()
 	if err != nil {
 		return nil, fmt.Errorf("loading config: %w", err)
 	}
-	if cfg.Clockify.APIKey == "" {
+	switch {
+	case cfg.Backend.Harvest():
+		if cfg.Harvest.AccountID == "" || cfg.Harvest.Token == "" {
+			return nil, fmt.Errorf("harvest account ID or token not configured — set account_id in [harvest] or HARVEST_ACCOUNT_ID, and the token there, in HARVEST_TOKEN or with 'clockr secrets set harvest_token'")
+		}
+	case cfg.Backend.Toggl():
+		if cfg.Toggl.APIToken == "" {
+			return nil, fmt.Errorf("toggl API token not configured — set api_token in [toggl], TOGGL_API_TOKEN or 'clockr secrets set toggl_api_token'")
+		}
+	case cfg.Backend.Tempo():
+		if cfg.Tempo.Token == "" || cfg.Tempo.JiraToken == "" {
+			return nil, fmt.Errorf("tempo or Jira API token not configured — set token and jira_token in [tempo], TEMPO_TOKEN and JIRA_API_TOKEN, or 'clockr secrets set tempo_token' and 'clockr secrets set jira_api_token'")
+		}
+	case cfg.Clockify.APIKey == "":
 		return nil, fmt.Errorf("clockify API key not configured — run 'clockr config' to set it up")
 	}
 	return cfg, nil
@@ -157,13 +822,51 @@ func setupLogger(cmd *cobra.Command) *slog.Logger {
 	if verbose {
 		level = slog.LevelDebug
 	}
-	return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
 		Level: level,
 	}))
+	if verbose && os.Getenv("CLOCKR_HTTP_TRACE") == "1" {
+		setupHTTPTrace(logger)
+	}
+	return logger
+}
+
+// setupHTTPTrace appends every API request and response to http-trace.log
+// in the state directory.
+func setupHTTPTrace(logger *slog.Logger) {
+	dir, err := config.StateDir()
+	if err != nil {
+		logger.Warn("HTTP trace disabled", "error", err)
+		return
+	}
+	path := filepath.Join(dir, "http-trace.log")
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		logger.Warn("HTTP trace disabled", "error", err)
+		return
+	}
+	httpmetrics.SetTrace(f)
+	logger.Debug("tracing HTTP requests", "file", path)
 }
 
 func newClockifyClient(cfg *config.Config, logger *slog.Logger) *clockify.Client {
-	return clockify.NewClient(cfg.Clockify.APIKey, cfg.Clockify.BaseURL, 1*time.Hour, logger)
+	client := clockify.NewClient(cfg.Clockify.APIKey, cfg.Clockify.BaseURL, 1*time.Hour, logger)
+	client.SetTimeout(cfg.Timeouts.Clockify())
+	client.SetReadOnly(readOnly)
+	if rt := httpCache(logger); rt != nil {
+		client.SetTransport(rt)
+	}
+	return client
+}
+
+// httpCache returns the ETag cache shared by the Clockify and GitHub
+// clients, or nil when there is no cache directory.
+func httpCache(logger *slog.Logger) http.RoundTripper {
+	dir, err := config.CacheDir()
+	if err != nil {
+		return nil
+	}
+	return httpcache.New(filepath.Join(dir, "http-cache"), nil, logger)
 }
 
 func resolveWorkspaceID(ctx context.Context, cfg *config.Config, client *clockify.Client) (string, error) {
@@ -180,7 +883,66 @@ func resolveWorkspaceID(ctx context.Context, cfg *config.Config, client *clockif
 	return user.DefaultWorkspace, nil
 }
 
+// newBackend returns the service entries are logged to: Harvest, Toggl
+// Track or Tempo when [backend] selects them, otherwise the Clockify
+// workspace, whose projects are cached in db when it is not nil.
+func newBackend(ctx context.Context, cfg *config.Config, db *store.DB, logger *slog.Logger) (backend.Backend, error) {
+	if cfg.Backend.Harvest() {
+		h := harvest.NewClient(cfg.Harvest.AccountID, cfg.Harvest.Token, cfg.Harvest.Task, cfg.Harvest.BaseURL, logger)
+		h.SetTimeout(cfg.Timeouts.Harvest())
+		h.SetReadOnly(readOnly)
+		h.SetLocation(cfg.Schedule.Location())
+		return h, nil
+	}
+	if cfg.Backend.Toggl() {
+		t := toggl.NewClient(cfg.Toggl.APIToken, cfg.Toggl.WorkspaceID, cfg.Toggl.BaseURL, logger)
+		t.SetTimeout(cfg.Timeouts.Toggl())
+		t.SetReadOnly(readOnly)
+		return t, nil
+	}
+	if cfg.Backend.Tempo() {
+		t := mirror.NewTempoClient(cfg, logger)
+		t.SetReadOnly(readOnly)
+		return t, nil
+	}
+	client := newClockifyClient(cfg, logger)
+	if db != nil {
+		client.SetCacheStore(db)
+	}
+	workspaceID, err := resolveWorkspaceID(ctx, cfg, client)
+	if err != nil {
+		return nil, err
+	}
+	return backend.Clockify(client, workspaceID), nil
+}
+
+// requireClockify fails commands that only work against Clockify when
+// [backend] selects another service.
+func requireClockify(cfg *config.Config, command string) error {
+	if !cfg.Backend.Clockify() {
+		return fmt.Errorf("'clockr %s' works with Clockify only, not [backend] type = %q", command, cfg.Backend.Type)
+	}
+	return nil
+}
+
 func newAIProvider(cfg *config.Config, logger *slog.Logger) ai.Provider {
+	p := newOpenRouter(cfg, logger)
+	p.SetReasoning(cfg.AI.Effort, cfg.AI.Thinking)
+	p.SetExtraParams(cfg.AI.ExtraParams)
+	p.SetPromptOptions(promptOptions(cfg))
+	return p
+}
+
+// promptOptions are the prompt settings from cfg: the [ai] description
+// rules, and whether to ask for Jira issue keys for mirroring to Tempo.
+func promptOptions(cfg *config.Config) ai.PromptOptions {
+	return ai.PromptOptions{
+		DescriptionRules: cfg.AI.DescriptionGuidelines(),
+		IssueKeys:        cfg.MirrorsTo("tempo"),
+	}
+}
+
+func newOpenRouter(cfg *config.Config, logger *slog.Logger) *ai.OpenRouterProvider {
 	switch cfg.AI.Provider {
 	case "openrouter", "":
 		apiKey := cfg.AI.OpenRouterAPIKey
@@ -205,49 +967,262 @@ func newAIProvider(cfg *config.Config, logger *slog.Logger) ai.Provider {
 	}
 }
 
+// buildProvider creates the AI (or prompt-file) provider with recent answers
+// cached in db, trimmed to the projects worth sending when [ai] max_projects
+// or clients is set, and, when the rules matcher is enabled, wraps it so
+// configured rules are tried first. Answers are validated, and sent back to
+// the model once when they break the prompt's rules. Each provider gets the
+// prompt options (description rules, Tempo issue keys) when it is created,
+// so a config reload builds new providers instead of changing old ones.
+func buildProvider(cfg *config.Config, db *store.DB, promptFile bool, logger *slog.Logger) (ai.Provider, error) {
+
+	var provider ai.Provider
+	retries := validationRetries
+	if promptFile {
+		p, err := ai.NewPromptFileProvider(logger)
+		if err != nil {
+			return nil, fmt.Errorf("creating prompt file provider: %w", err)
+		}
+		p.SetPromptOptions(promptOptions(cfg))
+		provider = p
+		// Asking again would mean another round trip through the
+		// clipboard; flag the problems instead.
+		retries = 0
+	} else {
+		provider = newAIProvider(cfg, logger)
+	}
+	if ttl := cfg.AI.CacheTTL(); ttl > 0 && db != nil {
+		cached := ai.NewCachedProvider(provider, db, ttl, logger)
+		cached.SetPromptOptions(promptOptions(cfg))
+		provider = cached
+	}
+	provider = ai.NewValidatingProvider(provider, cfg.AI.GranularityMinutes, retries, logger)
+	if cfg.AI.MaxProjects > 0 || len(cfg.AI.Clients) > 0 {
+		provider = ai.NewBudgetProvider(cfg.AI, recentProjectMinutes(db, logger), provider, logger)
+	}
+
+	if !cfg.Matcher.Enabled {
+		return provider, nil
+	}
+	fallback := provider
+	if !cfg.Matcher.AIFallback {
+		fallback = nil
+	}
+	rules, err := ai.NewRulesProvider(cfg.Matcher, fallback, logger)
+	if err != nil {
+		return nil, fmt.Errorf("configuring matcher: %w", err)
+	}
+	logger.Debug("rules matcher enabled", "rules", len(cfg.Matcher.Rules), "repos", len(cfg.Matcher.Repos), "ai_fallback", cfg.Matcher.AIFallback)
+	return rules, nil
+}
+
+// validationRetries is how often an invalid AI answer is sent back for
+// correction before its problems are shown instead.
+const validationRetries = 1
+
+// recentUsageDays is how far back logged minutes count as recent use when
+// trimming the projects sent to the AI.
+const recentUsageDays = 30
+
+// recentProjectMinutes returns the minutes logged per project lately, or nil
+// without a store.
+func recentProjectMinutes(db *store.DB, logger *slog.Logger) map[string]int {
+	if db == nil {
+		return nil
+	}
+	minutes, err := db.ProjectMinutesSince(time.Now().AddDate(0, 0, -recentUsageDays))
+	if err != nil {
+		logger.Warn("could not read recent project use", "error", err)
+	}
+	return minutes
+}
+
 func enrichProjectsWithClients(ctx context.Context, client *clockify.Client, workspaceID string, projects []clockify.Project, logger *slog.Logger) {
 	logger.Debug("fetching clients")
 	client.EnrichProjectsWithClients(ctx, workspaceID, projects)
 	logger.Debug("clients enriched")
 }
 
-func runStart(cmd *cobra.Command, args []string) error {
-	cfg, err := loadConfig()
-	if err != nil {
-		return err
-	}
+// knownProjectsKey is the state key holding the project IDs seen so far, so
+// projects added to the workspace later can be onboarded.
+const knownProjectsKey = "known_project_ids"
 
-	db, err := store.Open()
+// newProjects returns the projects not seen on earlier runs. The first run
+// only records the current projects.
+func newProjects(db *store.DB, projects []clockify.Project) ([]clockify.Project, error) {
+	raw, err := db.GetState(knownProjectsKey)
 	if err != nil {
-		return fmt.Errorf("opening database: %w", err)
+		return nil, err
 	}
-	defer db.Close()
-
-	logger := setupLogger(cmd)
-	client := newClockifyClient(cfg, logger)
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	if raw == "" {
+		return nil, saveKnownProjects(db, projects)
+	}
+	var known []string
+	if err := json.Unmarshal([]byte(raw), &known); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", knownProjectsKey, err)
+	}
+	var added []clockify.Project
+	for _, p := range projects {
+		if !slices.Contains(known, p.ID) {
+			added = append(added, p)
+		}
+	}
+	return added, nil
+}
 
-	workspaceID, err := resolveWorkspaceID(ctx, cfg, client)
+func saveKnownProjects(db *store.DB, projects []clockify.Project) error {
+	ids := make([]string, len(projects))
+	for i, p := range projects {
+		ids[i] = p.ID
+	}
+	data, err := json.Marshal(ids)
 	if err != nil {
 		return err
 	}
+	return db.SetState(knownProjectsKey, string(data))
+}
 
-	var provider ai.Provider
-	if cfg.AI.PromptFile {
-		provider, err = ai.NewPromptFileProvider(logger)
-		if err != nil {
-			return fmt.Errorf("creating prompt file provider: %w", err)
-		}
-	} else {
-		provider = newAIProvider(cfg, logger)
+// onboardNewProjects asks for aliases, keywords and repos for projects added
+// to the workspace since the last run and saves them as matcher rules. It
+// returns the config to use from here on, reloaded if anything was saved.
+// Nothing happens in read-only mode or without a terminal.
+func onboardNewProjects(cfg *config.Config, db *store.DB, projects []clockify.Project) *config.Config {
+	if db.ReadOnly() || !stdinIsTerminal() {
+		return cfg
 	}
-	sched := scheduler.New(cfg, client, db, provider, workspaceID)
-
+	added, err := newProjects(db, projects)
+	if err != nil {
+		fmt.Printf("Warning: checking for new projects: %v\n", err)
+		return cfg
+	}
+	if len(added) == 0 {
+		return cfg
+	}
+
+	fmt.Printf("%d new project(s) in the workspace — add aliases so they match from day one.\n", len(added))
+	app := tui.NewOnboardApp(added)
+	if _, err := tea.NewProgram(app).Run(); err != nil {
+		fmt.Printf("Warning: project onboarding failed: %v\n", err)
+		return cfg
+	}
+	result := app.GetResult()
+	if result == nil {
+		return cfg
+	}
+	if !result.Canceled {
+		if err := saveKnownProjects(db, projects); err != nil {
+			fmt.Printf("Warning: could not record known projects: %v\n", err)
+		}
+	}
+	if len(result.Mappings) == 0 {
+		return cfg
+	}
+
+	var rules []config.MatchRule
+	repos := make(map[string]string)
+	for _, m := range result.Mappings {
+		if terms := append(append([]string{}, m.Aliases...), m.Keywords...); len(terms) > 0 {
+			rules = append(rules, config.TermsRule(m.Project.ID, terms))
+		}
+		for _, r := range m.Repos {
+			repos[r] = m.Project.ID
+		}
+	}
+	if err := config.SaveProjectMappings(rules, repos); err != nil {
+		fmt.Printf("Warning: could not save project mappings: %v\n", err)
+		return cfg
+	}
+	fmt.Printf("Saved %d rule(s) and %d repo mapping(s) to [matcher].\n", len(rules), len(repos))
+
+	reloaded, err := loadConfig()
+	if err != nil {
+		fmt.Printf("Warning: reloading config: %v\n", err)
+		return cfg
+	}
+	return reloaded
+}
+
+// stdinIsTerminal reports whether stdin is interactive.
+func stdinIsTerminal() bool {
+	fi, err := os.Stdin.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// maxStdinDescription caps how much piped input is read as a description.
+const maxStdinDescription = 64 * 1024
+
+// readStdinDescription reads the work description piped to --stdin.
+func readStdinDescription() (string, error) {
+	if stdinIsTerminal() {
+		return "", fmt.Errorf("--stdin expects piped input, e.g. git log --oneline | clockr log --stdin")
+	}
+	data, err := io.ReadAll(io.LimitReader(os.Stdin, maxStdinDescription))
+	if err != nil {
+		return "", fmt.Errorf("reading stdin: %w", err)
+	}
+	description := strings.TrimSpace(string(data))
+	if description == "" {
+		return "", fmt.Errorf("no description on stdin")
+	}
+	return description, nil
+}
+
+// openTTY opens the controlling terminal for keyboard input when stdin is
+// taken by a pipe.
+func openTTY() (*os.File, error) {
+	if runtime.GOOS == "windows" {
+		return os.Open("CONIN$")
+	}
+	return os.Open("/dev/tty")
+}
+
+func runStart(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	db, err := openStore()
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer db.Close()
+
+	logger := setupLogger(cmd)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	b, err := newBackend(ctx, cfg, db, logger)
+	if err != nil {
+		return err
+	}
+
+	for _, w := range checkPermissions(ctx, cfg, b, logger) {
+		fmt.Printf("Warning: %s\n", w)
+	}
+
+	if projects, err := b.ListProjects(ctx); err != nil {
+		logger.Warn("fetching projects for onboarding", "error", err)
+	} else {
+		cfg = onboardNewProjects(cfg, db, projects)
+	}
+
+	provider, err := buildProvider(cfg, db, cfg.AI.PromptFile, logger)
+	if err != nil {
+		return err
+	}
+	sched := scheduler.New(cfg, b, db, provider)
+	if addr, _ := cmd.Flags().GetString("debug-addr"); addr != "" {
+		sched.SetDebugAddr(addr)
+	}
+
 	// Check if outside work hours and prompt for confirmation
 	if !scheduler.IsWorkTime(cfg, time.Now()) {
-		msg := fmt.Sprintf("Work hours are %s–%s. Start the scheduler anyway?",
-			cfg.Schedule.WorkStart, cfg.Schedule.WorkEnd)
+		msg := fmt.Sprintf("Work hours are %s. Start the scheduler anyway?",
+			cfg.Schedule.HoursSummary())
+		if cfg.Schedule.OnHoliday(time.Now().In(cfg.Schedule.Location())) {
+			msg = "Today is listed in [schedule] holidays. Start the scheduler anyway?"
+		}
 		confirm := tui.NewConfirmApp(msg)
 		p := tea.NewProgram(confirm)
 		if _, err := p.Run(); err != nil {
@@ -261,807 +1236,5592 @@ func runStart(cmd *cobra.Command, args []string) error {
 		sched.SetSkipWorkTimeCheck(true)
 	}
 
-	// Handle graceful shutdown
+	// Handle graceful shutdown; SIGHUP reloads config.toml.
 	sigCh := make(chan os.Signal, 1)
-	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
 	go func() {
-		<-sigCh
-		cancel()
+		for sig := range sigCh {
+			if sig != syscall.SIGHUP {
+				cancel()
+				return
+			}
+			summary, err := sched.Reload()
+			if err != nil {
+				fmt.Printf("Warning: %v\n", err)
+				continue
+			}
+			fmt.Printf("Config reloaded: %s\n", summary)
+		}
 	}()
 
 	return sched.Run(ctx)
 }
 
-func runStop(cmd *cobra.Command, args []string) error {
-	pid, err := scheduler.ReadPID()
+func runPromptNow(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
 	if err != nil {
 		return err
 	}
 
-	process, err := os.FindProcess(pid)
+	db, err := openStore()
 	if err != nil {
-		return fmt.Errorf("finding process %d: %w", pid, err)
+		return fmt.Errorf("opening database: %w", err)
 	}
+	defer db.Close()
 
-	if err := process.Signal(syscall.SIGTERM); err != nil {
-		return fmt.Errorf("sending stop signal: %w", err)
+	logger := setupLogger(cmd)
+	ctx := context.Background()
+
+	b, err := newBackend(ctx, cfg, db, logger)
+	if err != nil {
+		return err
 	}
 
-	fmt.Printf("Sent stop signal to clockr (PID %d)\n", pid)
+	provider, err := buildProvider(cfg, db, cfg.AI.PromptFile, logger)
+	if err != nil {
+		return err
+	}
+	scheduler.New(cfg, b, db, provider).PromptNow(ctx)
 	return nil
 }
 
-func runClearFailed(cmd *cobra.Command, args []string) error {
-	db, err := store.Open()
+func runSkip(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	db, err := openStore()
 	if err != nil {
 		return fmt.Errorf("opening database: %w", err)
 	}
 	defer db.Close()
 
-	// Show failed entries first
-	entries, err := db.GetFailedEntries()
+	reason, _ := cmd.Flags().GetString("reason")
+	interval := cfg.Schedule.IntervalFor(time.Now().In(cfg.Schedule.Location()).Weekday())
+	skip, err := scheduler.SkipWindow(db, interval, strings.TrimSpace(reason))
 	if err != nil {
-		return fmt.Errorf("fetching failed entries: %w", err)
+		return err
 	}
-	if len(entries) == 0 {
-		fmt.Println("No failed entries.")
-		return nil
+	fmt.Printf("Skipped %s–%s (%dmin)", skip.StartTime.Format("15:04"), skip.EndTime.Format("15:04"), skip.Minutes)
+	if skip.Reason != "" {
+		fmt.Printf(": %s", skip.Reason)
 	}
+	fmt.Println()
 
-	fmt.Printf("Found %d failed entries:\n\n", len(entries))
-	for _, e := range entries {
-		projectDisplay := e.ProjectName
-		if e.ClientName != "" {
-			projectDisplay = e.ProjectName + " (" + e.ClientName + ")"
+	if cfg.Coverage.ProjectID != "" {
+		ctx := context.Background()
+		b, err := newBackend(ctx, cfg, nil, setupLogger(cmd))
+		if err != nil {
+			return err
 		}
-		fmt.Printf("  #%d  %s  %dmin  %s  %s\n",
-			e.ID,
-			e.StartTime.Local().Format("2006-01-02 15:04"),
-			e.Minutes,
-			projectDisplay,
-			e.Description,
-		)
+		logBreak(ctx, cfg, b, db, &skip)
 	}
+	return nil
+}
 
-	deleted, err := db.DeleteFailedEntries()
+// logBreak logs a skip as a break under [coverage] and reports the outcome.
+func logBreak(ctx context.Context, cfg *config.Config, b backend.Backend, db *store.DB, skip *store.Skip) {
+	id, err := scheduler.LogBreak(ctx, cfg, b, db, skip)
 	if err != nil {
-		return fmt.Errorf("clearing failed entries: %w", err)
+		fmt.Printf("Warning: %v\n", err)
+	} else if id != "" {
+		fmt.Printf("Logged %dmin as a break.\n", skip.Minutes)
 	}
-
-	fmt.Printf("\nDeleted %d failed entries.\n", deleted)
-	return nil
 }
 
-func runLog(cmd *cobra.Command, args []string) error {
-	same, _ := cmd.Flags().GetBool("same")
-	repeat, _ := cmd.Flags().GetBool("repeat")
-	fromStr, _ := cmd.Flags().GetString("from")
-	toStr, _ := cmd.Flags().GetString("to")
-	useGitHub, _ := cmd.Flags().GetBool("github")
-	promptFile, _ := cmd.Flags().GetBool("prompt-file")
+// checkPermissions verifies that each configured credential allows the
+// operations clockr needs and returns one message per problem found.
+// GitHub and Graph are only checked when they are in use.
+func checkPermissions(ctx context.Context, cfg *config.Config, b backend.Backend, logger *slog.Logger) []string {
+	var problems []string
 
-	cfg, err := loadConfig()
-	if err != nil {
-		return err
-	}
+	checkCtx, cancel := context.WithTimeout(ctx, cfg.Timeouts.Clockify())
+	defer cancel()
 
-	// Config sets the default; flag overrides
-	if cfg.AI.PromptFile {
-		promptFile = true
+	if msg := b.CheckAccess(checkCtx); msg != "" {
+		problems = append(problems, msg)
 	}
 
-	// Validate flag combinations
-	if (fromStr != "") != (toStr != "") {
-		return fmt.Errorf("both --from and --to must be provided together")
-	}
-	if same && fromStr != "" {
-		return fmt.Errorf("--same cannot be combined with --from/--to")
-	}
-	if same && useGitHub {
-		return fmt.Errorf("--same cannot be combined with --github")
+	if cfg.GitHub.Token != "" || len(cfg.GitHub.Repos) > 0 {
+		token, err := github.ResolveToken(cfg.GitHub.Token)
+		if err != nil {
+			problems = append(problems, err.Error())
+		} else {
+			scopes, classic, err := github.NewClient(token, logger).TokenScopes(checkCtx)
+			if err != nil {
+				problems = append(problems, err.Error())
+			} else {
+				problems = append(problems, github.MissingScopeWarnings(scopes, classic)...)
+			}
+		}
 	}
-	if same && repeat {
-		return fmt.Errorf("--same cannot be combined with --repeat")
+
+	if cfg.Calendar.Source == "graph" {
+		profiles, err := msgraph.Profiles()
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("listing Graph sign-ins: %v", err))
+		}
+		if len(profiles) == 0 {
+			profiles = []string{""}
+		}
+		for _, profile := range profiles {
+			graph := "Microsoft Graph"
+			auth := "clockr calendar auth"
+			if profile != "" {
+				graph = fmt.Sprintf("Microsoft Graph (profile %s)", profile)
+				auth += " --profile " + profile
+			}
+			tokens, err := msgraph.LoadTokens(profile)
+			switch {
+			case err != nil:
+				problems = append(problems, fmt.Sprintf("reading %s tokens: %v", graph, err))
+			case tokens == nil:
+				problems = append(problems, fmt.Sprintf("%s not authenticated — run '%s'", graph, auth))
+			case tokens.NeedsReauth:
+				problems = append(problems, fmt.Sprintf("%s sign-in expired or was revoked — run '%s'", graph, auth))
+			default:
+				write := cfg.Calendar.WriteBack && profile == cfg.Calendar.Graph.WriteProfile
+				for _, m := range tokens.MissingScopes(write) {
+					problems = append(problems, graph+" token lacks "+m)
+				}
+			}
+		}
 	}
 
-	db, err := store.Open()
+	return problems
+}
+
+func runCheck(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
 	if err != nil {
-		return fmt.Errorf("opening database: %w", err)
+		return err
 	}
-	defer db.Close()
 
 	logger := setupLogger(cmd)
-	client := newClockifyClient(cfg, logger)
 	ctx := context.Background()
 
-	logger.Debug("resolving workspace ID")
-	workspaceID, err := resolveWorkspaceID(ctx, cfg, client)
+	b, err := newBackend(ctx, cfg, nil, logger)
 	if err != nil {
 		return err
 	}
-	logger.Debug("workspace resolved", "workspace_id", workspaceID)
 
-	if same {
-		return runLogSame(ctx, cfg, client, workspaceID, db)
+	problems := checkPermissions(ctx, cfg, b, logger)
+	if len(problems) == 0 {
+		fmt.Println("All credentials have the required access.")
+		return nil
 	}
+	for _, p := range problems {
+		fmt.Printf("  ✗ %s\n", p)
+	}
+	return fmt.Errorf("%d permission problem(s) found", len(problems))
+}
 
-	if fromStr != "" {
-		return runLogBatch(ctx, cfg, client, workspaceID, db, fromStr, toStr, useGitHub, repeat, promptFile, logger)
+// doctorCheck is one line of 'clockr doctor'. Status is "ok", "warn" or
+// "fail".
+type doctorCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+}
+
+// doctorReport is everything 'clockr doctor' found, with the versions to
+// quote in a bug report.
+type doctorReport struct {
+	Version string        `json:"version"`
+	Go      string        `json:"go"`
+	OS      string        `json:"os"`
+	SQLite  string        `json:"sqlite,omitempty"`
+	Checks  []doctorCheck `json:"checks"`
+}
+
+func (r *doctorReport) add(name, status, detail string) {
+	r.Checks = append(r.Checks, doctorCheck{Name: name, Status: status, Detail: detail})
+}
+
+func (r *doctorReport) failed() int {
+	n := 0
+	for _, c := range r.Checks {
+		if c.Status == "fail" {
+			n++
+		}
 	}
+	return n
+}
 
-	logger.Debug("fetching projects")
-	projects, err := client.GetProjects(ctx, workspaceID)
+func runDoctor(cmd *cobra.Command, args []string) error {
+	report := &doctorReport{Version: version, Go: runtime.Version(), OS: runtime.GOOS + "/" + runtime.GOARCH}
+	logger := setupLogger(cmd)
+	ctx := context.Background()
+
+	cfg, err := loadConfig()
 	if err != nil {
-		return fmt.Errorf("fetching projects: %w", err)
+		report.add("config", "fail", err.Error())
+	} else {
+		path, _ := config.ConfigPath()
+		report.add("config", "ok", path)
+	}
+	if templates, err := ai.CheckPromptTemplates(); err != nil {
+		report.add("prompts", "fail", err.Error())
+	} else if len(templates) > 0 {
+		report.add("prompts", "ok", fmt.Sprintf("%d custom template(s)", len(templates)))
 	}
-	logger.Debug("projects loaded", "count", len(projects))
-	enrichProjectsWithClients(ctx, client, workspaceID, projects, logger)
 
-	var provider ai.Provider
-	if promptFile {
-		var err error
-		provider, err = ai.NewPromptFileProvider(logger)
-		if err != nil {
-			return fmt.Errorf("creating prompt file provider: %w", err)
+	db, err := openStore()
+	if err != nil {
+		report.add("database", "fail", err.Error())
+	} else {
+		defer db.Close()
+		db.QueryRow("SELECT sqlite_version()").Scan(&report.SQLite)
+		detail := "opened"
+		if db.ReadOnly() {
+			detail = "opened read-only"
 		}
+		report.add("database", "ok", detail)
+	}
+
+	if cfg != nil {
+		doctorAI(ctx, cfg, report)
+		doctorAccess(ctx, cfg, report, logger)
+	}
+
+	statusCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	if _, err := scheduler.SendControl(statusCtx, scheduler.ControlRequest{Command: scheduler.CmdStatus}); err == nil {
+		report.add("scheduler", "ok", "running")
+	} else if errors.Is(err, scheduler.ErrNotRunning) {
+		report.add("scheduler", "warn", "not running — start it with 'clockr start'")
 	} else {
-		provider = newAIProvider(cfg, logger)
+		report.add("scheduler", "fail", err.Error())
 	}
-	now := time.Now()
-	interval := time.Duration(cfg.Schedule.IntervalMinutes) * time.Minute
-	startTime := now.Add(-interval)
-	endTime := now
 
-	var contextItems []string
-	if cfg.Calendar.Enabled && cfg.Calendar.Source != "" {
-		fmt.Println("Fetching calendar events...")
-		logger.Debug("fetching calendar events", "source", cfg.Calendar.Source, "start", startTime, "end", endTime)
-		fetchCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
-		events, err := fetchCalendarEvents(fetchCtx, cfg, startTime, endTime, logger)
-		cancel()
-		if err != nil {
-			fmt.Printf("Warning: calendar fetch failed: %v\n", err)
-			logger.Debug("calendar fetch error", "error", err)
-		} else {
-			logger.Debug("calendar events fetched", "count", len(events))
-			for _, e := range events {
-				contextItems = append(contextItems, e.Summary)
-			}
+	if outputJSON {
+		if err := writeJSON(os.Stdout, report); err != nil {
+			return err
+		}
+	} else {
+		fmt.Printf("clockr %s (%s, %s", report.Version, report.Go, report.OS)
+		if report.SQLite != "" {
+			fmt.Printf(", SQLite %s", report.SQLite)
+		}
+		fmt.Println(")")
+		marks := map[string]string{"ok": "✓", "warn": "!", "fail": "✗"}
+		for _, c := range report.Checks {
+			fmt.Printf("  %s %s: %s\n", marks[c.Status], c.Name, c.Detail)
 		}
 	}
+	if n := report.failed(); n > 0 {
+		return fmt.Errorf("%d check(s) failed", n)
+	}
+	return nil
+}
 
-	// Fetch GitHub context if requested (sent to AI via system prompt, not textarea)
-	if useGitHub {
-		logger.Debug("fetching GitHub context", "start", startTime, "end", endTime)
-		ghItems, err := fetchGitHubContext(ctx, cfg, startTime, endTime, logger)
-		if err != nil {
-			fmt.Printf("Warning: GitHub fetch failed: %v\n", err)
-			logger.Debug("GitHub fetch error", "error", err)
-		} else {
-			logger.Debug("GitHub items fetched", "count", len(ghItems))
-			for _, item := range ghItems {
-				contextItems = append(contextItems, item.Message)
+// doctorAI checks the AI provider: the OpenRouter key, or in prompt-file mode
+// the tools used to hand the prompt over.
+func doctorAI(ctx context.Context, cfg *config.Config, report *doctorReport) {
+	if cfg.AI.PromptFile {
+		report.add("ai", "ok", "prompt-file mode")
+		if os.Getenv("TMUX") != "" {
+			if _, err := exec.LookPath("claude"); err != nil {
+				report.add("ai", "warn", "no claude binary on PATH — prompts are not injected into a Claude Code pane")
 			}
 		}
+		return
 	}
 
-	lastInput, _ := db.GetState("last_description")
-	app := tui.NewApp(startTime, endTime, provider, projects, client, workspaceID, db, interval, contextItems, lastInput)
-	if repeat && lastInput != "" {
-		app.SetInitialInput(lastInput)
+	key := cfg.AI.OpenRouterAPIKey
+	if key == "" {
+		key = cfg.AI.APIKey
 	}
-	p := tea.NewProgram(app)
-
-	if _, err := p.Run(); err != nil {
-		return fmt.Errorf("running TUI: %w", err)
+	checkCtx, cancel := context.WithTimeout(ctx, cfg.Timeouts.Context())
+	defer cancel()
+	if err := ai.CheckOpenRouterKey(checkCtx, key); err != nil {
+		report.add("ai", "fail", err.Error())
+		return
 	}
-
-	result := app.GetResult()
-	if result != nil && result.Skipped {
-		fmt.Println("Entry skipped.")
+	model := cfg.AI.Model
+	if model == "" {
+		model = "default model"
 	}
-
-	return nil
+	report.add("ai", "ok", "OpenRouter key accepted, "+model)
 }
 
-func runLogBatch(ctx context.Context, cfg *config.Config, client *clockify.Client, workspaceID string, db *store.DB, fromStr, toStr string, useGitHub bool, repeat bool, promptFile bool, logger *slog.Logger) error {
-	from, err := parseDate(fromStr)
-	if err != nil {
-		return fmt.Errorf("invalid --from date: %w", err)
+// doctorAccess checks that the backend is reachable and that its, GitHub's
+// and Graph's credentials grant the access clockr needs.
+func doctorAccess(ctx context.Context, cfg *config.Config, report *doctorReport, logger *slog.Logger) {
+	var b backend.Backend
+	switch {
+	case cfg.Backend.Harvest():
+		b, _ = newBackend(ctx, cfg, nil, logger)
+		report.add("harvest", "ok", "account "+cfg.Harvest.AccountID)
+	case cfg.Backend.Toggl():
+		b, _ = newBackend(ctx, cfg, nil, logger)
+		workspaceID, err := b.(*toggl.Client).WorkspaceID(ctx)
+		if err != nil {
+			report.add("toggl", "fail", err.Error())
+			return
+		}
+		report.add("toggl", "ok", "workspace "+workspaceID)
+	case cfg.Backend.Tempo():
+		b, _ = newBackend(ctx, cfg, nil, logger)
+		report.add("tempo", "ok", "Jira "+cfg.Tempo.JiraURL)
+	default:
+		client := newClockifyClient(cfg, logger)
+		workspaceID, err := resolveWorkspaceID(ctx, cfg, client)
+		if err != nil {
+			report.add("clockify", "fail", err.Error())
+			return
+		}
+		report.add("clockify", "ok", "workspace "+workspaceID)
+		b = backend.Clockify(client, workspaceID)
 	}
-	to, err := parseDate(toStr)
-	if err != nil {
-		return fmt.Errorf("invalid --to date: %w", err)
+
+	problems := checkPermissions(ctx, cfg, b, logger)
+	for _, p := range problems {
+		report.add("access", "fail", p)
 	}
-	logger.Debug("batch date range parsed", "from", from.Format("2006-01-02"), "to", to.Format("2006-01-02"))
-	if to.Before(from) {
-		return fmt.Errorf("--to date must be on or after --from date")
+	if len(problems) == 0 {
+		report.add("access", "ok", "all credentials have the required access")
 	}
 
-	days, err := buildDaySlots(cfg, from, to)
-	if err != nil {
-		return err
+	if cfg.MirrorsTo("tempo") {
+		if problem := mirror.NewTempoClient(cfg, logger).CheckAccess(ctx); problem != "" {
+			report.add("tempo", "fail", problem)
+		} else {
+			report.add("tempo", "ok", "mirroring worklogs to "+cfg.Tempo.JiraURL)
+		}
 	}
-	if len(days) == 0 {
+	if dests := cfg.Mirrors(); len(dests) > 0 {
+		report.add("mirror", "ok", "copying entries to "+strings.Join(dests, ", "))
+	}
+}
+
+func runStop(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := scheduler.SendControl(ctx, scheduler.ControlRequest{Command: scheduler.CmdStop})
+	if err == nil {
+		fmt.Println("Stopped clockr scheduler.")
+		return nil
+	}
+	if !errors.Is(err, scheduler.ErrNotRunning) {
+		return err
+	}
+
+	// Schedulers started before the control socket existed only have a PID file.
+	pid, err := scheduler.ReadPID()
+	if err != nil {
+		return scheduler.ErrNotRunning
+	}
+
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return fmt.Errorf("finding process %d: %w", pid, err)
+	}
+
+	if err := process.Signal(syscall.SIGTERM); err != nil {
+		return fmt.Errorf("sending stop signal: %w", err)
+	}
+
+	fmt.Printf("Sent stop signal to clockr (PID %d)\n", pid)
+	return nil
+}
+
+func runPause(cmd *cobra.Command, args []string) error {
+	until, err := scheduler.ParsePauseEnd(args, time.Now())
+	if err != nil {
+		return err
+	}
+	reason, _ := cmd.Flags().GetString("reason")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	resp, err := scheduler.SendControl(ctx, scheduler.ControlRequest{Command: scheduler.CmdPause, Until: until, Reason: reason})
+	if err == nil {
+		fmt.Println(resp.Message)
+		return nil
+	}
+	if !errors.Is(err, scheduler.ErrNotRunning) {
+		return err
+	}
+
+	// No scheduler running: record the pause so the next one honors it.
+	db, err := openStore()
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer db.Close()
+	if err := scheduler.Pause(db, until, reason); err != nil {
+		return fmt.Errorf("recording pause: %w", err)
+	}
+	fmt.Println(scheduler.PausedMessage(until))
+	return nil
+}
+
+func runResume(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	resp, err := scheduler.SendControl(ctx, scheduler.ControlRequest{Command: scheduler.CmdResume})
+	if err == nil {
+		fmt.Println(resp.Message)
+		return nil
+	}
+	if !errors.Is(err, scheduler.ErrNotRunning) {
+		return err
+	}
+
+	db, err := openStore()
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer db.Close()
+	resumed, err := scheduler.Resume(db)
+	if err != nil {
+		return fmt.Errorf("ending pause: %w", err)
+	}
+	if !resumed {
+		fmt.Println("scheduler was not paused")
+		return nil
+	}
+	fmt.Println("resumed")
+	return nil
+}
+
+// runControl returns a RunE that sends command to the running scheduler.
+func runControl(command string) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		return sendControl(scheduler.ControlRequest{Command: command})
+	}
+}
+
+func sendControl(req scheduler.ControlRequest) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := scheduler.SendControl(ctx, req)
+	if err != nil {
+		return err
+	}
+	fmt.Println(resp.Message)
+	return nil
+}
+
+func runDebugHTTPStats(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := scheduler.SendControl(ctx, scheduler.ControlRequest{Command: scheduler.CmdHTTPStats})
+	if err != nil {
+		if errors.Is(err, scheduler.ErrNotRunning) {
+			return fmt.Errorf("%w — HTTP stats are kept by the running scheduler (clockr start)", err)
+		}
+		return err
+	}
+	if outputJSON {
+		return writeJSON(os.Stdout, resp.HTTPStats)
+	}
+	if len(resp.HTTPStats) == 0 {
+		fmt.Println("No API requests since the scheduler started.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SERVICE\tREQUESTS\tERRORS\tMEAN\tMAX\tSTATUS")
+	for _, st := range resp.HTTPStats {
+		codes := slices.Sorted(maps.Keys(st.Status))
+		var status []string
+		for _, code := range codes {
+			status = append(status, fmt.Sprintf("%d×%d", code, st.Status[code]))
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d (%.0f%%)\t%s\t%s\t%s\n", st.Service, st.Requests, st.Errors, st.ErrorRate()*100,
+			st.Mean().Round(time.Millisecond), st.Max.Round(time.Millisecond), strings.Join(status, " "))
+	}
+	return w.Flush()
+}
+
+// printSchedulerStatus prints one line about the running scheduler, if any,
+// or about a recorded pause when no scheduler is running.
+func printSchedulerStatus(w io.Writer, db *store.DB) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	resp, err := scheduler.SendControl(ctx, scheduler.ControlRequest{Command: scheduler.CmdStatus})
+	if err != nil || resp.Status == nil {
+		if pause, _ := db.ActivePause(time.Now()); pause != nil {
+			fmt.Fprintf(w, "Prompts: %s\n\n", pauseDescription(pause.EndTime, pause.Reason))
+		}
+		return
+	}
+	st := resp.Status
+	switch {
+	case st.Prompting:
+		fmt.Fprintln(w, "Scheduler: prompt open")
+	case st.Paused:
+		fmt.Fprintf(w, "Scheduler: %s\n", pauseDescription(st.PausedUntil, st.PauseReason))
+	case st.Holiday:
+		fmt.Fprintln(w, "Scheduler: holiday, no prompts today")
+	default:
+		fmt.Fprintf(w, "Scheduler: running, next prompt at %s\n", st.NextPrompt.Local().Format("15:04"))
+	}
+	fmt.Fprintln(w)
+}
+
+func pauseDescription(until time.Time, reason string) string {
+	msg := scheduler.PausedMessage(until.Local())
+	if until.IsZero() {
+		msg += " (clockr resume to continue)"
+	}
+	if reason != "" {
+		msg += " — " + reason
+	}
+	return msg
+}
+
+func runClearFailed(cmd *cobra.Command, args []string) error {
+	db, err := openStore()
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer db.Close()
+
+	// Show failed entries first
+	entries, err := db.GetFailedEntries()
+	if err != nil {
+		return fmt.Errorf("fetching failed entries: %w", err)
+	}
+	if len(entries) == 0 {
+		fmt.Println("No failed entries.")
+		return nil
+	}
+
+	fmt.Printf("Found %d failed entries:\n\n", len(entries))
+	for _, e := range entries {
+		projectDisplay := e.ProjectName
+		if e.ClientName != "" {
+			projectDisplay = e.ProjectName + " (" + e.ClientName + ")"
+		}
+		fmt.Printf("  #%d  %s  %dmin  %s  %s\n",
+			e.ID,
+			e.StartTime.Format("2006-01-02 15:04"),
+			e.Minutes,
+			projectDisplay,
+			e.Description,
+		)
+	}
+
+	deleted, err := db.DeleteFailedEntries()
+	if err != nil {
+		return fmt.Errorf("clearing failed entries: %w", err)
+	}
+
+	fmt.Printf("\nDeleted %d failed entries.\n", deleted)
+	return nil
+}
+
+func runAuditDiff(cmd *cobra.Command, args []string) error {
+	from, to, err := dateRangeFlags(cmd)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if err := requireClockify(cfg, "audit-diff"); err != nil {
+		return err
+	}
+	db, err := openStore()
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer db.Close()
+
+	logger := setupLogger(cmd)
+	client := newClockifyClient(cfg, logger)
+	client.SetCacheStore(db)
+	ctx := context.Background()
+	workspaceID, err := resolveWorkspaceID(ctx, cfg, client)
+	if err != nil {
+		return err
+	}
+	diffs, err := compareWithClockify(ctx, client, db, workspaceID, from, to)
+	if err != nil {
+		return err
+	}
+	if len(diffs) == 0 {
+		fmt.Println("No discrepancies.")
+		return nil
+	}
+	fmt.Printf("\n%d discrepancies:\n", len(diffs))
+	for i, d := range diffs {
+		fmt.Printf("  %d. %s\n", i+1, describeDiscrepancy(d))
+	}
+
+	if db.ReadOnly() || !stdinIsTerminal() {
+		return nil
+	}
+	fmt.Println()
+	in := bufio.NewReader(os.Stdin)
+	all := false
+	fixed := 0
+	var projects map[string]clockify.Project
+	for i, d := range diffs {
+		if d.Kind == audit.Diverged {
+			if projects == nil {
+				projects = projectsByID(ctx, client, workspaceID)
+			}
+			fields := conflictFields(d, projects)
+			choices := make([]tui.ConflictChoice, len(fields))
+			for j, f := range fields {
+				choices[j] = tui.ConflictChoice{Field: f.Name, Chosen: tui.ChooseRemote, Value: f.Remote}
+			}
+			if !all {
+				var quit bool
+				choices, quit, err = askConflict(fmt.Sprintf("%d. %s", i+1, describeDiscrepancy(d)), fields)
+				if err != nil {
+					return err
+				}
+				if quit {
+					fmt.Printf("Fixed %d of %d.\n", fixed, len(diffs))
+					return nil
+				}
+				if choices == nil {
+					continue
+				}
+			}
+			if err := applyConflict(ctx, client, db, workspaceID, d, projects, choices); err != nil {
+				fmt.Printf("  Fix failed: %s\n", clockify.FriendlyError(err))
+				continue
+			}
+			fixed++
+			continue
+		}
+		if !all {
+			ans, err := ask(in, fmt.Sprintf("%d. %s? [f]ix, [s]kip, fix [a]ll, [q]uit", i+1, auditFix(d)), "s")
+			if err != nil {
+				return err
+			}
+			switch strings.ToLower(ans) {
+			case "f":
+			case "a":
+				all = true
+			case "q":
+				fmt.Printf("Fixed %d of %d.\n", fixed, len(diffs))
+				return nil
+			default:
+				continue
+			}
+		}
+		if err := fixDiscrepancy(ctx, client, db, workspaceID, d); err != nil {
+			fmt.Printf("  Fix failed: %s\n", clockify.FriendlyError(err))
+			continue
+		}
+		fixed++
+	}
+	fmt.Printf("Fixed %d of %d.\n", fixed, len(diffs))
+	return nil
+}
+
+// dateRangeFlags parses --from and --to, where --to defaults to --from.
+func dateRangeFlags(cmd *cobra.Command) (from, to time.Time, err error) {
+	fromStr, _ := cmd.Flags().GetString("from")
+	toStr, _ := cmd.Flags().GetString("to")
+	if toStr == "" {
+		toStr = fromStr
+	}
+	if from, err = parseDate(fromStr); err != nil {
+		return from, to, fmt.Errorf("invalid --from date: %w", err)
+	}
+	if to, err = parseDate(toStr); err != nil {
+		return from, to, fmt.Errorf("invalid --to date: %w", err)
+	}
+	if to.Before(from) {
+		return from, to, fmt.Errorf("--to date must be on or after --from date")
+	}
+	return from, to, nil
+}
+
+// compareWithClockify compares the entries logged from the day from to the
+// day to, inclusive, with the user's Clockify time entries.
+func compareWithClockify(ctx context.Context, client *clockify.Client, db *store.DB, workspaceID string, from, to time.Time) ([]audit.Discrepancy, error) {
+	end := to.AddDate(0, 0, 1)
+	user, err := client.GetUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	remote, err := client.GetTimeEntries(ctx, workspaceID, user.ID, from, end)
+	if err != nil {
+		return nil, err
+	}
+	overlapping, err := db.GetEntriesBetween(from, end)
+	if err != nil {
+		return nil, fmt.Errorf("fetching entries: %w", err)
+	}
+	// Clockify filters by start time, so compare only entries starting in range
+	var local []store.Entry
+	for _, e := range overlapping {
+		if !e.StartTime.Before(from) {
+			local = append(local, e)
+		}
+	}
+
+	fmt.Printf("Comparing %s to %s: %d local entries, %d in Clockify.\n",
+		from.Format("2006-01-02"), to.Format("2006-01-02"), len(local), len(remote))
+	return audit.Compare(local, remote), nil
+}
+
+// askConflict opens the conflict view for the fields of a diverged entry.
+// It returns the chosen values, nil ones when the entry was skipped, and
+// quit when the user stopped.
+func askConflict(label string, fields []tui.ConflictField) (choices []tui.ConflictChoice, quit bool, err error) {
+	conflict := tui.NewConflictApp(label, fields)
+	if _, err := tea.NewProgram(conflict).Run(); err != nil {
+		return nil, false, fmt.Errorf("running conflict view: %w", err)
+	}
+	r := conflict.GetResult()
+	if r == nil || r.Quit {
+		return nil, true, nil
+	}
+	if r.Skipped {
+		return nil, false, nil
+	}
+	return r.Choices, false, nil
+}
+
+// runSync brings local entries in line with edits made in Clockify: new
+// times are copied over, and entries edited on both sides go through the
+// conflict view. Missing and duplicated entries are left to audit-diff.
+func runSync(cmd *cobra.Command, args []string) error {
+	from, to, err := dateRangeFlags(cmd)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if err := requireClockify(cfg, "sync"); err != nil {
+		return err
+	}
+	db, err := openStore()
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer db.Close()
+	if db.ReadOnly() {
+		return fmt.Errorf("sync is unavailable in read-only mode")
+	}
+
+	logger := setupLogger(cmd)
+	client := newClockifyClient(cfg, logger)
+	client.SetCacheStore(db)
+	ctx := context.Background()
+	workspaceID, err := resolveWorkspaceID(ctx, cfg, client)
+	if err != nil {
+		return err
+	}
+	diffs, err := compareWithClockify(ctx, client, db, workspaceID, from, to)
+	if err != nil {
+		return err
+	}
+
+	var pulled, resolved, unresolved, other int
+	var projects map[string]clockify.Project
+	for i, d := range diffs {
+		switch d.Kind {
+		case audit.Shifted:
+			if err := fixDiscrepancy(ctx, client, db, workspaceID, d); err != nil {
+				fmt.Printf("  %s: %s\n", describeDiscrepancy(d), clockify.FriendlyError(err))
+				continue
+			}
+			pulled++
+		case audit.Diverged:
+			if !stdinIsTerminal() {
+				fmt.Printf("  %s\n", describeDiscrepancy(d))
+				unresolved++
+				continue
+			}
+			if projects == nil {
+				projects = projectsByID(ctx, client, workspaceID)
+			}
+			choices, quit, err := askConflict(fmt.Sprintf("%d. %s", i+1, describeDiscrepancy(d)), conflictFields(d, projects))
+			if err != nil {
+				return err
+			}
+			if quit {
+				fmt.Printf("Pulled %d, resolved %d; stopped before the rest.\n", pulled, resolved)
+				return nil
+			}
+			if choices == nil {
+				unresolved++
+				continue
+			}
+			if err := applyConflict(ctx, client, db, workspaceID, d, projects, choices); err != nil {
+				fmt.Printf("  Fix failed: %s\n", clockify.FriendlyError(err))
+				unresolved++
+				continue
+			}
+			resolved++
+		default:
+			other++
+		}
+	}
+
+	fmt.Printf("Pulled %d changed times from Clockify, resolved %d conflicting edits.\n", pulled, resolved)
+	if unresolved > 0 {
+		fmt.Printf("%d entries edited on both sides are left as they are; run clockr sync in a terminal to resolve them.\n", unresolved)
+	}
+	if other > 0 {
+		fmt.Printf("%d entries are missing from or duplicated in Clockify; run clockr audit-diff to fix them.\n", other)
+	}
+	return nil
+}
+
+// describeDiscrepancy is one line of 'clockr audit-diff' output.
+func runGaps(cmd *cobra.Command, args []string) error {
+	fromStr, _ := cmd.Flags().GetString("from")
+	toStr, _ := cmd.Flags().GetString("to")
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	var err error
+	if fromStr != "" {
+		if from, err = parseDate(fromStr); err != nil {
+			return fmt.Errorf("invalid --from date: %w", err)
+		}
+	}
+	to, err := parseDate(toStr)
+	if err != nil {
+		return fmt.Errorf("invalid --to date: %w", err)
+	}
+	if to.Before(from) {
+		return fmt.Errorf("--to date must be on or after --from date")
+	}
+	end := to.AddDate(0, 0, 1)
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	db, err := openStore()
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer db.Close()
+
+	logger := setupLogger(cmd)
+	ctx := context.Background()
+	b, err := newBackend(ctx, cfg, nil, logger)
+	if err != nil {
+		return err
+	}
+
+	entries, err := db.GetEntriesBetween(from, end)
+	if err != nil {
+		return fmt.Errorf("fetching entries: %w", err)
+	}
+	skips, err := db.GetSkipsBetween(from, end)
+	if err != nil {
+		return fmt.Errorf("fetching skips: %w", err)
+	}
+	covered := coveredIntervals(entries, skips)
+	// Time logged in Clockify or Harvest directly (web app, mobile) covers a
+	// gap too
+	remote, err := b.ListEntries(ctx, from, end)
+	if err != nil {
+		return err
+	}
+	for _, r := range remote {
+		stop := r.TimeInterval.End
+		if stop.IsZero() { // a running timer
+			stop = now
+		}
+		covered = append(covered, audit.Interval{Start: r.TimeInterval.Start, End: stop})
+	}
+
+	gaps := audit.Gaps(audit.WorkHours(cfg.Schedule, from, end, now), covered, minStatusGap)
+	if len(gaps) == 0 {
+		fmt.Printf("No gaps in work hours from %s to %s.\n", from.Format("2006-01-02"), to.Format("2006-01-02"))
+		return nil
+	}
+	printGaps(os.Stdout, gaps, true)
+
+	if db.ReadOnly() || !stdinIsTerminal() {
+		return nil
+	}
+	projects, err := b.ListProjects(ctx)
+	if err != nil {
+		return fmt.Errorf("fetching projects: %w", err)
+	}
+	provider, err := buildProvider(cfg, db, cfg.AI.PromptFile, logger)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	in := bufio.NewReader(os.Stdin)
+	filled := 0
+	for i, g := range gaps {
+		ans, err := ask(in, fmt.Sprintf("%d/%d. Log %s %s–%s? [l]og, [s]kip, [q]uit", i+1, len(gaps),
+			g.Start.Format("Mon 2006-01-02"), g.Start.Format("15:04"), g.End.Format("15:04")), "l")
+		if err != nil {
+			return err
+		}
+		switch strings.ToLower(ans) {
+		case "l":
+		case "q":
+			fmt.Printf("Filled %d of %d gaps.\n", filled, len(gaps))
+			return nil
+		default:
+			continue
+		}
+		logged, err := logGap(ctx, cfg, b, db, provider, projects, g, in, logger)
+		if err != nil {
+			return err
+		}
+		if logged {
+			filled++
+		}
+	}
+	fmt.Printf("Filled %d of %d gaps.\n", filled, len(gaps))
+	return nil
+}
+
+// logGap opens the log TUI for one gap, with the gap's calendar events as
+// context, and reports whether anything was logged. A rejected Graph
+// sign-in is offered a new one through in.
+func logGap(ctx context.Context, cfg *config.Config, b backend.Backend, db *store.DB, provider ai.Provider, projects []clockify.Project, gap audit.Interval, in *bufio.Reader, logger *slog.Logger) (bool, error) {
+	var contextItems []ai.ContextItem
+	var events []calendar.Event
+	if cfg.Calendar.Enabled && cfg.Calendar.Source != "" {
+		var err error
+		events, err = fetchCalendarReauth(ctx, cfg, in, nil, gap.Start, gap.End, logger)
+		if err != nil {
+			calendarWarning(os.Stdout, err)
+		}
+		contextItems = sources.CalendarItems(events, cfg.Schedule.Location())
+	}
+
+	lastInput, _ := db.GetState("last_description")
+	app := tui.NewApp(gap.Start, gap.End, provider, projects, b, db, gap.End.Sub(gap.Start), contextItems, lastInput)
+	app.SetSkipReasons(cfg.Schedule.SkipReasons)
+	app.SetFormatter(format.New(cfg.Format))
+	app.SetRounding(format.Rounding(cfg.Format))
+	app.SetSplitAtMidnight(cfg.Schedule.SplitAtMidnight())
+	app.SetAITimeout(cfg.Timeouts.AI())
+	if cfg.Calendar.SplitAtMeetings {
+		app.SetMeetings(events)
+	}
+	if _, err := tea.NewProgram(app).Run(); err != nil {
+		return false, fmt.Errorf("running TUI: %w", err)
+	}
+
+	result := app.GetResult()
+	if result == nil {
+		return false, nil
+	}
+	if result.Skipped {
+		fmt.Println(scheduler.SkippedMessage(result.SkipReason))
+		logBreak(ctx, cfg, b, db, result.Skip)
+		return true, nil
+	}
+	fmt.Print(tui.Receipt(result.Entries, b))
+	publishEntries(ctx, cfg, db, scheduler.DiscoverPlugins(ctx, os.Stdout), result.Entries, logger)
+	return len(result.Entries) > 0, nil
+}
+
+func describeDiscrepancy(d audit.Discrepancy) string {
+	var what string
+	if d.Local != nil {
+		what = fmt.Sprintf("%s–%s  %s — %s (#%d)", d.Local.StartTime.Local().Format("Mon 01-02 15:04"),
+			d.Local.EndTime.Local().Format("15:04"), d.Local.ProjectName, d.Local.Description, d.Local.ID)
+	} else {
+		what = fmt.Sprintf("%s–%s  %s", d.Remote.TimeInterval.Start.Local().Format("Mon 01-02 15:04"),
+			d.Remote.TimeInterval.End.Local().Format("15:04"), d.Remote.Description)
+	}
+	switch d.Kind {
+	case audit.Missing:
+		return fmt.Sprintf("missing    %s: logged here but not in Clockify", what)
+	case audit.Duplicate:
+		return fmt.Sprintf("duplicate  %s: in Clockify more than once (extra copy %s)", what, d.Remote.ID)
+	case audit.Diverged:
+		return fmt.Sprintf("diverged   %s: Clockify has %s", what, d.Remote.Description)
+	default:
+		return fmt.Sprintf("shifted    %s: Clockify has %s–%s", what,
+			d.Remote.TimeInterval.Start.Local().Format("15:04"), d.Remote.TimeInterval.End.Local().Format("15:04"))
+	}
+}
+
+// auditFix says what fixDiscrepancy does for d.
+func auditFix(d audit.Discrepancy) string {
+	switch d.Kind {
+	case audit.Missing:
+		return "Create it in Clockify again"
+	case audit.Duplicate:
+		return "Delete the extra copy from Clockify"
+	case audit.Diverged:
+		return "Use Clockify's project and description locally"
+	default:
+		return "Use Clockify's times locally"
+	}
+}
+
+func fixDiscrepancy(ctx context.Context, client *clockify.Client, db *store.DB, workspaceID string, d audit.Discrepancy) error {
+	switch d.Kind {
+	case audit.Missing:
+		created, err := client.CreateTimeEntry(ctx, workspaceID, clockify.TimeEntryRequest{
+			Start:       d.Local.StartTime.UTC().Format("2006-01-02T15:04:05Z"),
+			End:         d.Local.EndTime.UTC().Format("2006-01-02T15:04:05Z"),
+			ProjectID:   d.Local.ProjectID,
+			Description: d.Local.Description,
+			TagIDs:      d.Local.TagIDs,
+		})
+		if err != nil {
+			return err
+		}
+		if err := db.UpdateEntryClockifyDetails(d.Local.ID, created.TaskID, created.TagIDs, created.Billable); err != nil {
+			return err
+		}
+		return db.UpdateEntryStatus(d.Local.ID, "logged", created.ID)
+	case audit.Duplicate:
+		return client.DeleteTimeEntry(ctx, workspaceID, d.Remote.ID)
+	default:
+		return db.UpdateEntryTimes(d.Local.ID, d.Remote.TimeInterval.Start, d.Remote.TimeInterval.End)
+	}
+}
+
+// projectsByID returns the workspace's projects keyed by ID, or an empty map
+// if they can't be fetched.
+func projectsByID(ctx context.Context, client *clockify.Client, workspaceID string) map[string]clockify.Project {
+	byID := map[string]clockify.Project{}
+	projects, err := client.GetProjects(ctx, workspaceID)
+	if err != nil {
+		return byID
+	}
+	client.EnrichProjectsWithClients(ctx, workspaceID, projects)
+	for _, p := range projects {
+		byID[p.ID] = p
+	}
+	return byID
+}
+
+// conflictFields lists the fields of a diverged entry that differ from
+// Clockify, for the conflict view.
+func conflictFields(d audit.Discrepancy, projects map[string]clockify.Project) []tui.ConflictField {
+	var fields []tui.ConflictField
+	if d.Local.ProjectID != d.Remote.ProjectID {
+		remote := d.Remote.ProjectID
+		if p, ok := projects[remote]; ok {
+			remote = p.Name
+		}
+		fields = append(fields, tui.ConflictField{Name: "project", Local: d.Local.ProjectName, Remote: remote})
+	}
+	if d.Local.Description != d.Remote.Description {
+		fields = append(fields, tui.ConflictField{Name: "description", Local: d.Local.Description, Remote: d.Remote.Description, Editable: true})
+	}
+	times := []struct {
+		name          string
+		local, remote time.Time
+	}{
+		{"start", d.Local.StartTime, d.Remote.TimeInterval.Start},
+		{"end", d.Local.EndTime, d.Remote.TimeInterval.End},
+	}
+	for _, t := range times {
+		local, remote := t.local.Local().Format("15:04"), t.remote.Local().Format("15:04")
+		if local != remote {
+			fields = append(fields, tui.ConflictField{Name: t.name, Local: local, Remote: remote})
+		}
+	}
+	return fields
+}
+
+// applyConflict makes both sides match the chosen values and records each
+// choice in the audit log.
+func applyConflict(ctx context.Context, client *clockify.Client, db *store.DB, workspaceID string, d audit.Discrepancy, projects map[string]clockify.Project, choices []tui.ConflictChoice) error {
+	final := *d.Local
+	for _, c := range choices {
+		switch c.Field {
+		case "project":
+			if c.Chosen == tui.ChooseRemote {
+				p := projects[d.Remote.ProjectID]
+				final.ProjectID, final.ProjectName, final.ClientName = d.Remote.ProjectID, p.Name, p.ClientName
+				if final.ProjectName == "" {
+					final.ProjectName = d.Remote.ProjectID
+				}
+			}
+		case "description":
+			final.Description = c.Value
+		case "start":
+			if c.Chosen == tui.ChooseRemote {
+				final.StartTime = d.Remote.TimeInterval.Start
+			}
+		case "end":
+			if c.Chosen == tui.ChooseRemote {
+				final.EndTime = d.Remote.TimeInterval.End
+			}
+		}
+	}
+
+	if final.ProjectID != d.Remote.ProjectID || final.Description != d.Remote.Description ||
+		!final.StartTime.Equal(d.Remote.TimeInterval.Start) || !final.EndTime.Equal(d.Remote.TimeInterval.End) {
+		if _, err := client.UpdateTimeEntry(ctx, workspaceID, d.Remote.ID, clockify.TimeEntryRequest{
+			Start:       final.StartTime.UTC().Format("2006-01-02T15:04:05Z"),
+			End:         final.EndTime.UTC().Format("2006-01-02T15:04:05Z"),
+			ProjectID:   final.ProjectID,
+			Description: final.Description,
+			TagIDs:      d.Remote.TagIDs, // an update without them clears the tags
+		}); err != nil {
+			return err
+		}
+	}
+	if err := db.UpdateEntryDetails(final.ID, final.ProjectID, final.ProjectName, final.ClientName, final.Description); err != nil {
+		return err
+	}
+	if !final.StartTime.Equal(d.Local.StartTime) || !final.EndTime.Equal(d.Local.EndTime) {
+		if err := db.UpdateEntryTimes(final.ID, final.StartTime, final.EndTime); err != nil {
+			return err
+		}
+	}
+
+	fields := conflictFields(d, projects)
+	for i, c := range choices {
+		if err := db.LogAuditDecision(store.AuditDecision{
+			EntryID:    d.Local.ID,
+			ClockifyID: d.Remote.ID,
+			Field:      c.Field,
+			Local:      fields[i].Local,
+			Remote:     fields[i].Remote,
+			Chosen:     c.Chosen,
+			Value:      c.Value,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runRetry(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	db, err := openStore()
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer db.Close()
+
+	logger := setupLogger(cmd)
+	ctx := context.Background()
+
+	b, err := newBackend(ctx, cfg, nil, logger)
+	if err != nil {
+		return err
+	}
+
+	if db.ReadOnly() {
+		return fmt.Errorf("retry is unavailable in read-only mode")
+	}
+
+	res, err := scheduler.RetryFailed(ctx, b, db, os.Stdout)
+	if err != nil {
+		return err
+	}
+	breaks, err := scheduler.RetryBreaks(ctx, cfg, b, db, os.Stdout)
+	if err != nil {
+		return err
+	}
+	res.Succeeded += breaks.Succeeded
+	res.Failed += breaks.Failed
+	res.Skipped += breaks.Skipped
+	if res == (scheduler.RetryResult{}) {
+		fmt.Println("No failed entries.")
+	} else {
+		fmt.Printf("\n%d succeeded, %d failed", res.Succeeded, res.Failed)
+		if res.Skipped > 0 {
+			fmt.Printf(", %d skipped (being retried elsewhere or attempted too recently)", res.Skipped)
+		}
+		fmt.Println()
+	}
+
+	n, err := mirror.Retry(ctx, cfg, db, logger)
+	if n > 0 {
+		fmt.Printf("Mirrored %d copy(ies) to %s.\n", n, strings.Join(cfg.Mirrors(), ", "))
+	}
+	if err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+	return nil
+}
+
+func runLog(cmd *cobra.Command, args []string) error {
+	same, _ := cmd.Flags().GetBool("same")
+	repeat, _ := cmd.Flags().GetBool("repeat")
+	fromStr, _ := cmd.Flags().GetString("from")
+	toStr, _ := cmd.Flags().GetString("to")
+	useGitHub, _ := cmd.Flags().GetBool("github")
+	promptFile, _ := cmd.Flags().GetBool("prompt-file")
+	overtime, _ := cmd.Flags().GetBool("overtime")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	resume, _ := cmd.Flags().GetBool("resume")
+	manual, _ := cmd.Flags().GetBool("manual")
+	fromStdin, _ := cmd.Flags().GetBool("stdin")
+	plainMode, _ := cmd.Flags().GetBool("plain")
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	// Config sets the default; flag overrides
+	if cfg.AI.PromptFile {
+		promptFile = true
+	}
+
+	// Validate flag combinations
+	if (fromStr != "") != (toStr != "") {
+		return fmt.Errorf("both --from and --to must be provided together")
+	}
+	if same && fromStr != "" {
+		return fmt.Errorf("--same cannot be combined with --from/--to")
+	}
+	if same && useGitHub {
+		return fmt.Errorf("--same cannot be combined with --github")
+	}
+	if same && repeat {
+		return fmt.Errorf("--same cannot be combined with --repeat")
+	}
+	if overtime && fromStr != "" {
+		return fmt.Errorf("--overtime cannot be combined with --from/--to")
+	}
+	if dryRun && fromStr == "" {
+		return fmt.Errorf("--dry-run needs --from/--to")
+	}
+	if resume && (same || repeat || fromStr != "" || useGitHub) {
+		return fmt.Errorf("--resume cannot be combined with --same, --repeat, --from/--to or --github")
+	}
+	if manual && (same || repeat || resume || fromStr != "" || useGitHub) {
+		return fmt.Errorf("--manual cannot be combined with --same, --repeat, --resume, --from/--to or --github")
+	}
+	if fromStdin && (same || repeat || resume || manual || fromStr != "") {
+		return fmt.Errorf("--stdin cannot be combined with --same, --repeat, --resume, --manual or --from/--to")
+	}
+	if plainMode && (same || resume || fromStdin || overtime || fromStr != "") {
+		return fmt.Errorf("--plain cannot be combined with --same, --resume, --stdin, --overtime or --from/--to")
+	}
+	if plainMode && promptFile {
+		return fmt.Errorf("--plain does not support prompt file mode (ai.prompt_file or --prompt-file)")
+	}
+
+	var stdinDescription string
+	if fromStdin {
+		stdinDescription, err = readStdinDescription()
+		if err != nil {
+			return err
+		}
+	}
+
+	db, err := openStore()
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer db.Close()
+
+	logger := setupLogger(cmd)
+	ctx := context.Background()
+
+	logger.Debug("resolving backend")
+	b, err := newBackend(ctx, cfg, db, logger)
+	if err != nil {
+		return err
+	}
+	logger.Debug("backend resolved", "backend", b.Name())
+
+	// Flush entries that failed earlier (e.g. while offline) before adding
+	// more. The single-entry prompt does this in the background and reports
+	// once the TUI closes.
+	retry := func(out io.Writer) {
+		retryCtx, cancelRetry := context.WithTimeout(ctx, 20*time.Second)
+		defer cancelRetry()
+		if _, err := scheduler.RetryFailed(retryCtx, b, db, out); err != nil {
+			logger.Warn("retrying failed entries", "error", err)
+		}
+	}
+	if dryRun {
+		// A preview must not create anything, not even earlier failed entries
+		b.SetReadOnly(true)
+	} else if same || fromStr != "" {
+		retry(os.Stdout)
+	}
+
+	if same {
+		return runLogSame(ctx, cfg, b, db, overtime, logger)
+	}
+
+	var saved *store.SavedSuggestion
+	var resumed ai.Suggestion
+	if resume {
+		saved, err = db.LatestSuggestion()
+		if err != nil {
+			return err
+		}
+		if saved == nil {
+			return fmt.Errorf("no suggestion to resume")
+		}
+		if err := json.Unmarshal([]byte(saved.Suggestion), &resumed); err != nil {
+			return fmt.Errorf("decoding saved suggestion: %w", err)
+		}
+	}
+
+	// A revoked Graph sign-in would only cost the calendar context, so
+	// offer to fix it before the prompt rather than warn after it.
+	if saved == nil && !manual && !fromStdin && stdinIsTerminal() {
+		offerGraphReauth(ctx, cfg, bufio.NewReader(os.Stdin), logger)
+	}
+
+	if fromStr != "" {
+		return runLogBatch(ctx, cfg, b, db, fromStr, toStr, useGitHub, repeat, promptFile, dryRun, logger)
+	}
+
+	// Output from the background work is held until the TUI closes.
+	var notes bytes.Buffer
+	var notesMu sync.Mutex
+	note := func(b []byte) {
+		notesMu.Lock()
+		defer notesMu.Unlock()
+		notes.Write(b)
+	}
+	retryDone := make(chan struct{})
+	go func() {
+		defer close(retryDone)
+		if !dryRun {
+			var out bytes.Buffer
+			retry(&out)
+			note(out.Bytes())
+		}
+	}()
+
+	// New projects are onboarded before the prompt, so their rules match in
+	// this session. The project list is usually cached; the prompt fetches
+	// it again with the context.
+	if !db.ReadOnly() && stdinIsTerminal() {
+		if projects, err := b.ListProjects(ctx); err != nil {
+			logger.Warn("fetching projects for onboarding", "error", err)
+		} else {
+			cfg = onboardNewProjects(cfg, db, projects)
+		}
+	}
+
+	var provider ai.Provider
+	if !manual {
+		provider, err = buildProvider(cfg, db, promptFile, logger)
+		if err != nil {
+			return err
+		}
+	}
+	now := time.Now().In(cfg.Schedule.Location())
+	interval := cfg.Schedule.IntervalFor(now.Weekday())
+	startTime := now.Add(-interval)
+	endTime := now
+	if saved != nil {
+		startTime = saved.StartTime.In(cfg.Schedule.Location())
+		endTime = saved.EndTime.In(cfg.Schedule.Location())
+		interval = endTime.Sub(startTime)
+	}
+
+	plugins := scheduler.DiscoverPlugins(ctx, os.Stdout)
+
+	// A Graph sign-in rejected while the calendar is fetched is offered a
+	// new one on the terminal. The TUI hands the terminal over for it.
+	var reauthIn *bufio.Reader
+	if stdinIsTerminal() && !fromStdin {
+		reauthIn = bufio.NewReader(os.Stdin)
+	}
+	var pauseTUI func() func()
+
+	// load fetches projects, calendar events, GitHub commits and plugin
+	// context at the same time. The saved context of a resumed suggestion
+	// keeps retries consistent without fetching again.
+	fetchContext := saved == nil && !manual
+	load := func() tui.Startup {
+		var st tui.Startup
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		add := func(items []ai.ContextItem) {
+			mu.Lock()
+			defer mu.Unlock()
+			st.ContextItems = append(st.ContextItems, items...)
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			logger.Debug("fetching projects")
+			projects, err := b.ListProjects(ctx)
+			if err != nil {
+				st.Err = fmt.Errorf("fetching projects: %w", err)
+				return
+			}
+			logger.Debug("projects loaded", "count", len(projects))
+			st.Projects = projects
+		}()
+
+		st.Rounding = format.Rounding(cfg.Format)
+
+		if fetchContext {
+			var providers []sources.Provider
+			var cal *sources.Calendar
+			if cfg.Calendar.Enabled && cfg.Calendar.Source != "" {
+				cal = sources.NewCalendar(func(ctx context.Context, start, end time.Time) ([]calendar.Event, error) {
+					logger.Debug("fetching calendar events", "source", cfg.Calendar.Source, "start", start, "end", end)
+					return fetchCalendarReauth(ctx, cfg, reauthIn, pauseTUI, start, end, logger)
+				})
+				providers = append(providers, cal)
+			}
+			// GitHub context is sent to the AI via the system prompt, not the textarea
+			if useGitHub {
+				providers = append(providers, sources.GitHub(func(ctx context.Context, start, end time.Time) ([]github.CommitContext, error) {
+					logger.Debug("fetching GitHub context", "start", start, "end", end)
+					return fetchGitHubContext(ctx, cfg, start, end, logger)
+				}))
+			}
+			providers = append(providers, sources.Plugins(plugins)...)
+			providers = append(providers, sources.Custom(cfg)...)
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				var out bytes.Buffer
+				add(sources.Collect(ctx, providers, startTime, endTime, func(p sources.Provider, err error) {
+					contextWarning(&out, p, err)
+					logger.Debug("context fetch error", "source", p.Name(), "error", err)
+				}))
+				note(out.Bytes())
+				if cal != nil && cfg.Calendar.SplitAtMeetings {
+					st.Events = cal.Events()
+				}
+			}()
+		}
+
+		wg.Wait()
+		return st
+	}
+
+	if plainMode {
+		st := load()
+		<-retryDone
+		os.Stdout.Write(notes.Bytes())
+		if st.Err != nil {
+			return st.Err
+		}
+		return runLogPlain(ctx, cfg, b, db, provider, st, startTime, endTime, repeat)
+	}
+
+	var projects []clockify.Project
+	var rounding clockify.Rounding
+	var contextItems []ai.ContextItem
+	if saved != nil {
+		// The suggestion is shown straight away, so its projects are needed first.
+		st := load()
+		if st.Err != nil {
+			return st.Err
+		}
+		projects = st.Projects
+		rounding = st.Rounding
+		if err := json.Unmarshal([]byte(saved.Context), &contextItems); err != nil {
+			return fmt.Errorf("decoding saved context: %w", err)
+		}
+	}
+
+	lastInput, _ := db.GetState("last_description")
+	app := tui.NewApp(startTime, endTime, provider, projects, b, db, interval, contextItems, lastInput)
+	if repeat && lastInput != "" {
+		app.SetInitialInput(lastInput)
+	}
+	app.SetOvertime(overtime, cfg.Clockify.OvertimeTagID)
+	app.SetSkipReasons(cfg.Schedule.SkipReasons)
+	app.SetFormatter(format.New(cfg.Format))
+	app.SetSplitAtMidnight(cfg.Schedule.SplitAtMidnight())
+	app.SetAITimeout(cfg.Timeouts.AI())
+	if saved != nil {
+		app.SetRounding(rounding)
+		app.Resume(saved.Description, &resumed)
+	} else {
+		app.SetStartup(load)
+	}
+	app.SetManual(manual)
+	var opts []tea.ProgramOption
+	if fromStdin {
+		app.SetDescription(stdinDescription)
+		// stdin is spent, so keys come from the terminal. Without one the
+		// suggestion can only be accepted by the auto-accept countdown.
+		if tty, err := openTTY(); err == nil {
+			defer tty.Close()
+			opts = append(opts, tea.WithInput(tty))
+		} else if cfg.Schedule.AutoAcceptSeconds > 0 {
+			app.SetAutoAccept(cfg.Schedule.AutoAcceptSeconds, cfg.Schedule.AutoAcceptConfidence)
+			opts = append(opts, tea.WithInput(nil))
+		} else {
+			return fmt.Errorf("--stdin without a terminal needs schedule.auto_accept_seconds to accept the suggestion")
+		}
+	}
+	p := tea.NewProgram(app, opts...)
+	pauseTUI = func() func() {
+		if err := p.ReleaseTerminal(); err != nil {
+			logger.Debug("releasing the terminal", "error", err)
+		}
+		return func() {
+			if err := p.RestoreTerminal(); err != nil {
+				logger.Debug("restoring the terminal", "error", err)
+			}
+		}
+	}
+
+	_, err = p.Run()
+	<-retryDone
+	os.Stdout.Write(notes.Bytes())
+	if err != nil {
+		return fmt.Errorf("running TUI: %w", err)
+	}
+
+	result := app.GetResult()
+	if result != nil && result.Skipped {
+		fmt.Println(scheduler.SkippedMessage(result.SkipReason))
+		logBreak(ctx, cfg, b, db, result.Skip)
+	}
+	if result != nil {
+		fmt.Print(tui.Receipt(result.Entries, b))
+		publishEntries(ctx, cfg, db, plugins, result.Entries, logger)
+	}
+
+	return nil
+}
+
+// runLogPlain is 'clockr log --plain': the line-based questions of package
+// plain instead of the TUI, logged through scheduler.SubmitAllocations.
+func runLogPlain(ctx context.Context, cfg *config.Config, b backend.Backend, db *store.DB, provider ai.Provider, st tui.Startup, start, end time.Time, repeat bool) error {
+	s := plain.New(os.Stdin, os.Stdout)
+	s.Start, s.End = start, end
+	s.Provider = provider
+	s.Projects = st.Projects
+	s.Context = st.ContextItems
+	s.Formatter = format.New(cfg.Format)
+	s.Rounding = st.Rounding
+	s.AITimeout = cfg.Timeouts.AI()
+	if repeat {
+		s.Description, _ = db.GetState("last_description")
+	}
+
+	res, err := s.Run(ctx)
+	if err != nil || res == nil {
+		return err
+	}
+	if db.ReadOnly() {
+		fmt.Println("Read-only mode — nothing was logged.")
+		return nil
+	}
+	if res.Skipped {
+		skip := &store.Skip{StartTime: res.Start, EndTime: end, Minutes: int(end.Sub(res.Start).Minutes()), Reason: res.SkipReason}
+		if _, err := db.InsertSkip(skip); err != nil {
+			return fmt.Errorf("recording skip: %w", err)
+		}
+		fmt.Println(scheduler.SkippedMessage(res.SkipReason))
+		logBreak(ctx, cfg, b, db, skip)
+		return nil
+	}
+
+	suggestionID := 0
+	if res.Suggestion != nil {
+		suggestionID = scheduler.RecordSuggestion(db, provider, res.Description, res.Suggestion, res.Clarifications)
+	}
+	entries, _ := scheduler.SubmitAllocations(ctx, cfg, b, db, mirror.New(cfg, slog.Default()), res.Allocations, res.Start, end, res.Description, suggestionID, store.SourceManual, os.Stdout)
+	db.SetState("last_description", res.Description)
+	fmt.Print(tui.Receipt(entries, b))
+	return nil
+}
+
+func runLogBatch(ctx context.Context, cfg *config.Config, b backend.Backend, db *store.DB, fromStr, toStr string, useGitHub bool, repeat bool, promptFile bool, dryRun bool, logger *slog.Logger) error {
+	from, err := parseDate(fromStr)
+	if err != nil {
+		return fmt.Errorf("invalid --from date: %w", err)
+	}
+	to, err := parseDate(toStr)
+	if err != nil {
+		return fmt.Errorf("invalid --to date: %w", err)
+	}
+	logger.Debug("batch date range parsed", "from", from.Format("2006-01-02"), "to", to.Format("2006-01-02"))
+	if to.Before(from) {
+		return fmt.Errorf("--to date must be on or after --from date")
+	}
+
+	days, err := buildDaySlots(cfg, from, to)
+	if err != nil {
+		return err
+	}
+	if len(days) == 0 {
 		return fmt.Errorf("no work days in the range %s to %s (check work_days config)", fromStr, toStr)
 	}
-	if len(days) > 10 {
-		return fmt.Errorf("batch limited to 10 work days, got %d (narrow the date range)", len(days))
+	if len(days) > 10 {
+		return fmt.Errorf("batch limited to 10 work days, got %d (narrow the date range)", len(days))
+	}
+	logger.Debug("day slots built", "count", len(days), "dates", func() string {
+		var dates []string
+		for _, d := range days {
+			dates = append(dates, d.Date)
+		}
+		return strings.Join(dates, ", ")
+	}())
+
+	logger.Debug("fetching projects")
+	projects, err := b.ListProjects(ctx)
+	if err != nil {
+		return fmt.Errorf("fetching projects: %w", err)
+	}
+	logger.Debug("projects loaded", "count", len(projects))
+	cfg = onboardNewProjects(cfg, db, projects)
+
+	// Fetch calendar events for the full range and attach to day slots (per-day AI context)
+	if cfg.Calendar.Enabled && cfg.Calendar.Source != "" {
+		fmt.Println("Fetching calendar events...")
+		rangeStart := days[0].Start
+		rangeEnd := days[len(days)-1].End
+		logger.Debug("fetching calendar events", "source", cfg.Calendar.Source, "start", rangeStart, "end", rangeEnd)
+		var in *bufio.Reader
+		if stdinIsTerminal() {
+			in = bufio.NewReader(os.Stdin)
+		}
+		events, err := fetchCalendarReauth(ctx, cfg, in, nil, rangeStart, rangeEnd, logger)
+		if err != nil {
+			calendarWarning(os.Stdout, err)
+			logger.Debug("calendar fetch error", "error", err)
+		}
+		logger.Debug("calendar events fetched", "count", len(events))
+		grouped := calendar.GroupByDay(events)
+		for i, d := range days {
+			if dayEvents, ok := grouped[d.Date]; ok {
+				days[i].Events = sources.CalendarItems(dayEvents, cfg.Schedule.Location())
+			}
+		}
+	}
+
+	// Fetch GitHub commits/PRs and attach to day slots (sent to AI via system prompt, not textarea)
+	if useGitHub {
+		rangeStart := days[0].Start
+		rangeEnd := days[len(days)-1].End
+		logger.Debug("fetching GitHub context", "start", rangeStart, "end", rangeEnd)
+		ghItems, err := fetchGitHubContext(ctx, cfg, rangeStart, rangeEnd, logger)
+		if err != nil {
+			fmt.Printf("Warning: GitHub fetch failed: %v\n", err)
+			logger.Debug("GitHub fetch error", "error", err)
+		} else if len(ghItems) > 0 {
+			logger.Debug("GitHub items fetched", "count", len(ghItems))
+			grouped := github.GroupByDay(ghItems)
+			for i, d := range days {
+				if dayItems, ok := grouped[d.Date]; ok {
+					days[i].Commits = sources.GitHubItems(dayItems, cfg.Schedule.Location())
+				}
+			}
+		}
+	}
+
+	plugins := scheduler.DiscoverPlugins(ctx, os.Stdout)
+	providers := append(sources.Plugins(plugins), sources.Custom(cfg)...)
+	for i, d := range days {
+		days[i].Context = sources.Collect(ctx, providers, d.Start, d.End, func(p sources.Provider, err error) {
+			contextWarning(os.Stdout, p, err)
+		})
+	}
+
+	provider, err := buildProvider(cfg, db, promptFile, logger)
+	if err != nil {
+		return err
+	}
+	lastInput, _ := db.GetState("last_description")
+	app := tui.NewBatchApp(days, provider, projects, b, db, lastInput)
+	app.SetFormatter(format.New(cfg.Format))
+	app.SetRounding(format.Rounding(cfg.Format))
+	app.SetDryRun(dryRun)
+	app.SetSplitAtMidnight(cfg.Schedule.SplitAtMidnight())
+	app.SetAITimeout(cfg.Timeouts.AI())
+	if repeat && lastInput != "" {
+		app.SetInitialInput(lastInput)
+	}
+	p := tea.NewProgram(app)
+
+	if _, err := p.Run(); err != nil {
+		return fmt.Errorf("running batch TUI: %w", err)
+	}
+
+	result := app.GetResult()
+	if result != nil && result.Skipped {
+		fmt.Println("Batch entry skipped.")
+	}
+	if result != nil {
+		fmt.Print(tui.Receipt(result.Entries, b))
+		publishEntries(ctx, cfg, db, plugins, result.Entries, logger)
+	}
+
+	return nil
+}
+
+// publishEntries mirrors logged entries into the calendar when
+// calendar.write_back is enabled and to the [mirror] destinations, and sends
+// them to submit plugins. Failures are warnings: the time is already in
+// Clockify.
+func publishEntries(ctx context.Context, cfg *config.Config, db *store.DB, plugins []plugin.Plugin, entries []store.Entry, logger *slog.Logger) {
+	scheduler.SubmitToPlugins(ctx, plugins, db, entries, os.Stdout)
+	n, err := scheduler.WriteBack(ctx, cfg, db, entries, logger)
+	if err != nil {
+		fmt.Printf("Warning: calendar write-back failed: %v\n", err)
+	}
+	if n > 0 {
+		fmt.Printf("Added %d busy block(s) to your calendar.\n", n)
+	}
+	n, err = mirror.Sync(ctx, cfg, db, entries, logger)
+	if err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+	if n > 0 {
+		fmt.Printf("Mirrored %d copy(ies) to %s.\n", n, strings.Join(cfg.Mirrors(), ", "))
+	}
+}
+
+func buildDaySlots(cfg *config.Config, from, to time.Time) ([]ai.DaySlot, error) {
+	// Work hours are wall-clock times in the schedule's zone, so walk the
+	// calendar dates of the range in that zone.
+	loc := cfg.Schedule.Location()
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, loc)
+	to = time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, loc)
+
+	var days []ai.DaySlot
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		if !cfg.Schedule.IsWorkDay(d.Weekday()) {
+			continue
+		}
+
+		at := func(mins int) time.Time {
+			return time.Date(d.Year(), d.Month(), d.Day(), mins/60, mins%60, 0, 0, d.Location())
+		}
+		blocks := cfg.Schedule.BlocksFor(d.Weekday())
+		slot := ai.DaySlot{
+			Date:    d.Format("2006-01-02"),
+			Weekday: d.Weekday().String(),
+			Start:   at(blocks[0].Start),
+			End:     at(blocks[len(blocks)-1].End),
+		}
+		for _, b := range blocks {
+			slot.Minutes += b.Minutes()
+			if len(blocks) > 1 {
+				slot.Blocks = append(slot.Blocks, ai.Segment{Start: at(b.Start), End: at(b.End)})
+			}
+		}
+		days = append(days, slot)
+	}
+
+	return days, nil
+}
+
+func parseTimeConfig(s string) (int, int, error) {
+	parts := strings.SplitN(s, ":", 2)
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("expected HH:MM format, got %q", s)
+	}
+	h, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid hour in %q: %w", s, err)
+	}
+	m, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid minute in %q: %w", s, err)
+	}
+	return h, m, nil
+}
+
+func parseDate(s string) (time.Time, error) {
+	loc := time.Now().Location()
+	if t, err := time.ParseInLocation("2006-01-02", s, loc); err == nil {
+		return t, nil
+	}
+	t, err := naturaldate.Parse(s, time.Now(), naturaldate.WithDirection(naturaldate.Past))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("cannot parse date %q (use YYYY-MM-DD or natural language like 'monday', 'last friday')", s)
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc), nil
+}
+
+func runLogSame(ctx context.Context, cfg *config.Config, b backend.Backend, db *store.DB, overtime bool, logger *slog.Logger) error {
+	last, err := db.GetLastEntry()
+	if err != nil {
+		return fmt.Errorf("getting last entry: %w", err)
+	}
+	if last == nil {
+		return fmt.Errorf("no previous entries found")
+	}
+
+	// Verify the project still exists in the backend
+	projects, err := b.ListProjects(ctx)
+	if err != nil {
+		return fmt.Errorf("fetching projects: %w", err)
+	}
+	found := false
+	for _, p := range projects {
+		if p.ID == last.ProjectID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("project %q (%s) from last entry no longer exists in Clockify — use 'clockr log' instead", last.ProjectName, last.ProjectID)
+	}
+
+	now := time.Now().In(cfg.Schedule.Location())
+	interval := cfg.Schedule.IntervalFor(now.Weekday())
+	startTime := now.Add(-interval)
+	endTime := now
+	description := format.New(cfg.Format).Description(last.ProjectID, last.ProjectName, last.ClientName, last.Description)
+
+	if db.ReadOnly() {
+		fmt.Printf("Read-only mode — would log: %s — %s (%dmin)\n",
+			last.ProjectName, description, int(interval.Minutes()))
+		return nil
+	}
+
+	storeEntry := store.Entry{
+		ProjectID:   last.ProjectID,
+		ProjectName: last.ProjectName,
+		ClientName:  last.ClientName,
+		Description: description,
+		StartTime:   startTime,
+		EndTime:     endTime,
+		Minutes:     int(interval.Minutes()),
+		RawInput:    "(--same)",
+		Overtime:    overtime,
+		Source:      store.SourceSame,
+		IssueKey:    last.IssueKey,
+	}
+	if overtime && cfg.Clockify.OvertimeTagID != "" {
+		storeEntry.TagIDs = []string{cfg.Clockify.OvertimeTagID}
+	}
+	parts := []store.Entry{storeEntry}
+	if cfg.Schedule.SplitAtMidnight() {
+		parts = store.SplitAtMidnight(storeEntry)
+	}
+
+	for i := range parts {
+		part := &parts[i]
+		entry := clockify.TimeEntryRequest{
+			Start:       part.StartTime.UTC().Format("2006-01-02T15:04:05Z"),
+			End:         part.EndTime.UTC().Format("2006-01-02T15:04:05Z"),
+			ProjectID:   part.ProjectID,
+			Description: part.Description,
+			TagIDs:      part.TagIDs,
+		}
+
+		created, err := b.CreateEntry(ctx, entry)
+
+		part.Status = "logged"
+		if err != nil {
+			part.Status = "failed"
+			fmt.Printf("Warning: failed to create Clockify entry: %s\n", clockify.FriendlyError(err))
+		} else {
+			part.ClockifyID = created.ID
+			part.TaskID, part.TagIDs, part.Billable = created.TaskID, created.TagIDs, created.Billable
+		}
+
+		if _, err := db.InsertEntry(part); err != nil {
+			return fmt.Errorf("saving entry: %w", err)
+		}
+
+		fmt.Printf("Logged: %s — %s (%dmin) [%s]\n",
+			part.ProjectName, part.Description, part.Minutes, part.Status)
+	}
+	publishEntries(ctx, cfg, db, scheduler.DiscoverPlugins(ctx, os.Stdout), parts, logger)
+
+	return nil
+}
+
+func runStatus(cmd *cobra.Command, args []string) error {
+	dateStr, _ := cmd.Flags().GetString("date")
+	week, _ := cmd.Flags().GetBool("week")
+	month, _ := cmd.Flags().GetBool("month")
+	if week && month {
+		return fmt.Errorf("--week cannot be combined with --month")
+	}
+	filter, err := entryFilter(cmd)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	day := today
+	if dateStr != "" {
+		d, err := parseDate(dateStr)
+		if err != nil {
+			return fmt.Errorf("invalid --date: %w", err)
+		}
+		day = time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, now.Location())
+	}
+	from, to := day, day.AddDate(0, 0, 1)
+	switch {
+	case week:
+		from = day.AddDate(0, 0, -int(day.Weekday()+6)%7) // Monday
+		to = from.AddDate(0, 0, 7)
+	case month:
+		from = time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, day.Location())
+		to = from.AddDate(0, 1, 0)
+	}
+
+	db, err := openStore()
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer db.Close()
+
+	entries, err := db.GetEntriesBetween(from, to)
+	if err != nil {
+		return fmt.Errorf("fetching entries: %w", err)
+	}
+	skips, err := db.GetSkipsBetween(from, to)
+	if err != nil {
+		return fmt.Errorf("fetching skips: %w", err)
+	}
+	// Gaps and the flex balance are worked out from every entry; a filter
+	// only narrows what is listed and totalled.
+	work := audit.WorkHours(statusConfig().Schedule, from, to, now)
+	gaps := audit.Gaps(work, coveredIntervals(entries, skips), minStatusGap)
+	expected, flex := status.Flex(entries, work, from, to)
+	entries = filter.Apply(entries)
+
+	// With --copy, everything printed is also captured for the clipboard.
+	var copied strings.Builder
+	out := io.Writer(os.Stdout)
+	copyOut, _ := cmd.Flags().GetBool("copy")
+	if copyOut {
+		out = io.MultiWriter(os.Stdout, &copied)
+	}
+
+	if outputJSON {
+		report := status.New(schedulerStatus(), entries, skips, gaps, from, to, week || month)
+		report.ExpectedMinutes, report.FlexMinutes = expected, flex
+		if err := writeJSON(out, report); err != nil {
+			return err
+		}
+		return copyStatus(copyOut, copied.String())
+	}
+	if !now.Before(from) && now.Before(to) {
+		printSchedulerStatus(os.Stdout, db)
+	}
+
+	if filter != (stats.Filter{}) {
+		fmt.Fprintf(out, "Only %s\n\n", filter)
+	}
+	if week || month {
+		printStatusDays(out, entries, from, to)
+		if filter == (stats.Filter{}) {
+			printFlex(out, expected, flex)
+		}
+	} else {
+		printStatusDay(out, entries, from, day.Equal(today))
+	}
+	printSkipSummary(out, skips)
+	printGaps(out, gaps, week || month)
+	switch {
+	case month:
+		printMonthScores(out, db, from, to, now)
+	case !week:
+		printDayScore(out, db, day, now)
+	}
+
+	return copyStatus(copyOut, copied.String())
+}
+
+// printDayScore prints the day's quality score, the streak of good days up
+// to it, and a tip when the day falls short.
+func printDayScore(w io.Writer, db *store.DB, day, now time.Time) {
+	days, err := quality.Days(db, statusConfig().Schedule, day.AddDate(0, 0, -60), day.AddDate(0, 0, 1), now)
+	if err != nil || len(days) == 0 || days[len(days)-1].Score < 0 {
+		return
+	}
+	d := days[len(days)-1]
+	fmt.Fprintf(w, "\nDay score: %d (%s)\n", d.Score, scoreParts(d))
+	switch streak := quality.Streak(days); streak {
+	case 0:
+	case 1:
+		fmt.Fprintln(w, "Streak: 1 good day")
+	default:
+		fmt.Fprintf(w, "Streak: %d good days in a row\n", streak)
+	}
+	if d.Score < quality.GoodDay {
+		fmt.Fprintf(w, "Tip: %s\n", scoreTip(d))
+	}
+}
+
+// printMonthScores summarizes the day scores of [from, to).
+func printMonthScores(w io.Writer, db *store.DB, from, to, now time.Time) {
+	days, err := quality.Days(db, statusConfig().Schedule, from, to, now)
+	if err != nil {
+		return
+	}
+	var scored []quality.Day
+	var total quality.Day
+	sum, good := 0, 0
+	for _, d := range days {
+		if d.Score < 0 {
+			continue
+		}
+		scored = append(scored, d)
+		sum += d.Score
+		if d.Score >= quality.GoodDay {
+			good++
+		}
+		total.Work += d.Work
+		total.Covered += d.Covered
+		total.Prompts += d.Prompts
+		total.Answered += d.Answered
+		total.Edit = (total.Edit*float64(total.Edits) + d.Edit*float64(d.Edits)) / float64(max(total.Edits+d.Edits, 1))
+		total.Edits += d.Edits
+	}
+	if len(scored) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\nDay scores: average %d, %d of %d days good (%d+), best streak %d\n",
+		sum/len(scored), good, len(scored), quality.GoodDay, quality.BestStreak(days))
+	for _, d := range scored {
+		fmt.Fprintf(w, "  %s  %3d  %s\n", d.Date.Format("Mon 01-02"), d.Score, scoreParts(d))
+	}
+	if sum/len(scored) < quality.GoodDay {
+		fmt.Fprintf(w, "Tip: %s\n", scoreTip(total))
+	}
+}
+
+// scoreParts describes what a day score is made of.
+func scoreParts(d quality.Day) string {
+	parts := []string{fmt.Sprintf("%d%% covered", int(d.Coverage()*100))}
+	if d.Prompts > 0 {
+		parts = append(parts, fmt.Sprintf("%d/%d prompts answered", d.Answered, d.Prompts))
+	}
+	if d.Edits > 0 {
+		parts = append(parts, fmt.Sprintf("suggestions %d%% edited", int(d.Edit*100+0.5)))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// scoreTip suggests how to improve the weakest part of a score.
+func scoreTip(d quality.Day) string {
+	coverage, answered, kept := d.Coverage(), 1.0, 1.0
+	if d.Prompts > 0 {
+		answered = float64(d.Answered) / float64(d.Prompts)
+	}
+	if d.Edits > 0 {
+		kept = 1 - d.Edit
+	}
+	switch {
+	case coverage <= answered && coverage <= kept:
+		return "fill the gaps in your work hours with 'clockr gaps' or 'clockr log'."
+	case answered <= kept:
+		return "answer prompts when they appear, or skip them with a reason."
+	default:
+		return "describe your work in more detail so suggestions need fewer edits."
+	}
+}
+
+// printStatusDay lists one day's entries with the day's totals.
+func printStatusDay(out io.Writer, entries []store.Entry, startOfDay time.Time, today bool) {
+	date := startOfDay.Format("Mon 2006-01-02")
+	switch {
+	case len(entries) == 0 && today:
+		fmt.Fprintln(out, "No entries logged today.")
+		return
+	case len(entries) == 0:
+		fmt.Fprintf(out, "No entries logged on %s.\n", date)
+		return
+	case today:
+		fmt.Fprintln(out, "Today's entries:")
+	default:
+		fmt.Fprintf(out, "Entries on %s:\n", date)
+	}
+	fmt.Fprintln(out)
+	for _, e := range entries {
+		localStart := e.StartTime
+		localEnd := e.EndTime
+		projectDisplay := e.ProjectName
+		if e.ClientName != "" {
+			projectDisplay = e.ClientName + " / " + e.ProjectName
+		}
+		status := e.Status
+		if e.Overtime {
+			status += ", overtime"
+		}
+		from, to := localStart.Format("15:04"), localEnd.Format("15:04")
+		if localStart.Before(startOfDay) {
+			from = localStart.Format("Mon 15:04")
+		}
+		if localEnd.After(startOfDay.AddDate(0, 0, 1)) {
+			to = localEnd.Format("Mon 15:04")
+		}
+		fmt.Fprintf(out, "  %s–%s  %dmin  %-30s  %s  [%s]\n",
+			from,
+			to,
+			e.Minutes,
+			projectDisplay,
+			e.Description,
+			status,
+		)
+	}
+
+	// An entry spanning midnight only counts its part of the day
+	totalMinutes, overtimeMinutes := status.Minutes(entries, startOfDay, startOfDay.AddDate(0, 0, 1))
+	fmt.Fprintf(out, "\nTotal: %dh %dmin (%d entries)\n", totalMinutes/60, totalMinutes%60, len(entries))
+	if overtimeMinutes > 0 {
+		fmt.Fprintf(out, "Overtime: %dh %dmin (not included in total)\n", overtimeMinutes/60, overtimeMinutes%60)
+	}
+}
+
+// printStatusDays prints a subtotal per day of [from, to). Days without
+// entries are listed only when they are work days.
+func printStatusDays(out io.Writer, entries []store.Entry, from, to time.Time) {
+	fmt.Fprintf(out, "Entries %s – %s:\n\n", from.Format("Mon 2006-01-02"), to.AddDate(0, 0, -1).Format("Mon 2006-01-02"))
+	cfg := statusConfig()
+	for d := from; d.Before(to); d = d.AddDate(0, 0, 1) {
+		var day []store.Entry
+		for _, e := range entries {
+			if e.StartTime.Before(d.AddDate(0, 0, 1)) && e.EndTime.After(d) {
+				day = append(day, e)
+			}
+		}
+		if len(day) == 0 && !cfg.Schedule.IsWorkDay(d.Weekday()) {
+			continue
+		}
+		minutes, overtime := status.Minutes(day, d, d.AddDate(0, 0, 1))
+		line := fmt.Sprintf("  %s  %2dh %02dmin  (%d entries)", d.Format("Mon 2006-01-02"), minutes/60, minutes%60, len(day))
+		if overtime > 0 {
+			line += fmt.Sprintf(" +%dmin overtime", overtime)
+		}
+		fmt.Fprintln(out, line)
+	}
+
+	totalMinutes, overtimeMinutes := status.Minutes(entries, from, to)
+	fmt.Fprintf(out, "\nTotal: %dh %dmin (%d entries)\n", totalMinutes/60, totalMinutes%60, len(entries))
+	if overtimeMinutes > 0 {
+		fmt.Fprintf(out, "Overtime: %dh %dmin (not included in total)\n", overtimeMinutes/60, overtimeMinutes%60)
+	}
+}
+
+// minStatusGap is the shortest unlogged stretch reported as a gap; anything
+// shorter is rounding between entries.
+const minStatusGap = 5 * time.Minute
+
+// entryFilter reads the --source, --billable, --non-billable and --tag
+// flags shared by status and stats.
+func entryFilter(cmd *cobra.Command) (stats.Filter, error) {
+	var f stats.Filter
+	f.Source, _ = cmd.Flags().GetString("source")
+	f.Tag, _ = cmd.Flags().GetString("tag")
+	if f.Source != "" && !slices.Contains(store.Sources, f.Source) {
+		return f, fmt.Errorf("invalid --source %q: must be one of %s", f.Source, strings.Join(store.Sources, ", "))
+	}
+	billable, _ := cmd.Flags().GetBool("billable")
+	nonBillable, _ := cmd.Flags().GetBool("non-billable")
+	switch {
+	case billable && nonBillable:
+		return f, fmt.Errorf("--billable cannot be combined with --non-billable")
+	case billable, nonBillable:
+		f.Billable = &billable
+	}
+	return f, nil
+}
+
+// statusConfig loads the config for work hours, falling back to the defaults
+// so status works before 'clockr init'.
+func statusConfig() *config.Config {
+	cfg, err := config.Load()
+	if err != nil {
+		def := config.DefaultConfig()
+		return &def
+	}
+	return cfg
+}
+
+// printFlex prints the flex balance: regular time logged against the work
+// hours so far. Overtime is printed on its own line and left out of it.
+func printFlex(w io.Writer, expected, flex int) {
+	if expected == 0 {
+		return
+	}
+	sign := "+"
+	if flex < 0 {
+		sign = "-"
+	}
+	abs := max(flex, -flex)
+	logged := expected + flex
+	fmt.Fprintf(w, "Flex balance: %s%dh %dmin (%dh %dmin logged of %dh %dmin expected so far)\n",
+		sign, abs/60, abs%60, logged/60, logged%60, expected/60, expected%60)
+}
+
+// coveredIntervals returns the time accounted for by entries and skips.
+func coveredIntervals(entries []store.Entry, skips []store.Skip) []audit.Interval {
+	var covered []audit.Interval
+	for _, e := range entries {
+		covered = append(covered, audit.Interval{Start: e.StartTime, End: e.EndTime})
+	}
+	for _, s := range skips {
+		covered = append(covered, audit.Interval{Start: s.StartTime, End: s.EndTime})
+	}
+	return covered
+}
+
+// printGaps lists work hours with no entries. withDate prefixes each gap
+// with its day, for ranges longer than a day.
+func printGaps(w io.Writer, gaps []audit.Interval, withDate bool) {
+	if len(gaps) == 0 {
+		return
+	}
+	total := 0
+	for _, g := range gaps {
+		total += g.Minutes()
+	}
+	fmt.Fprintf(w, "\nGaps in work hours: %dh %dmin\n", total/60, total%60)
+	for _, g := range gaps {
+		span := g.Start.Format("15:04") + "–" + g.End.Format("15:04")
+		if withDate {
+			span = g.Start.Format("Mon 2006-01-02") + "  " + span
+		}
+		fmt.Fprintf(w, "  %s  (%dmin)\n", span, g.Minutes())
+	}
+}
+
+// copyStatus copies the printed status to the clipboard for --copy.
+func copyStatus(copyOut bool, text string) error {
+	if !copyOut {
+		return nil
+	}
+	if err := clipboard.Copy(text); err != nil {
+		return fmt.Errorf("copying to clipboard: %w", err)
+	}
+	fmt.Fprintln(os.Stderr, "Copied to clipboard.")
+	return nil
+}
+
+// schedulerStatus asks the running scheduler for its status, returning nil
+// when none answers.
+func schedulerStatus() *scheduler.ControlStatus {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	resp, err := scheduler.SendControl(ctx, scheduler.ControlRequest{Command: scheduler.CmdStatus})
+	if err != nil {
+		return nil
+	}
+	return resp.Status
+}
+
+// printSkipSummary prints how much time was deliberately left untracked,
+// broken down by reason.
+func printSkipSummary(w io.Writer, skips []store.Skip) {
+	if len(skips) == 0 {
+		return
+	}
+	totals := store.SkipTotals(skips)
+	skipped := 0
+	parts := make([]string, len(totals))
+	for i, t := range totals {
+		skipped += t.Minutes
+		reason := t.Reason
+		if reason == "" {
+			reason = "no reason"
+		}
+		parts[i] = fmt.Sprintf("%s %dmin", reason, t.Minutes)
+	}
+	fmt.Fprintf(w, "Skipped: %dh %dmin untracked (%s)\n", skipped/60, skipped%60, strings.Join(parts, ", "))
+}
+
+func runStandup(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	db, err := openStore()
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer db.Close()
+
+	logger := setupLogger(cmd)
+	ctx := context.Background()
+
+	now := time.Now()
+	prevDay := previousWorkDay(cfg, now)
+	entries, err := db.GetEntriesBetween(prevDay, prevDay.AddDate(0, 0, 1))
+	if err != nil {
+		return fmt.Errorf("fetching entries: %w", err)
+	}
+	var entryLines []string
+	for _, e := range entries {
+		project := e.ProjectName
+		if e.ClientName != "" {
+			project += " (" + e.ClientName + ")"
+		}
+		entryLines = append(entryLines, fmt.Sprintf("%s–%s %s: %s",
+			e.StartTime.Format("15:04"), e.EndTime.Format("15:04"), project, e.Description))
+	}
+
+	var eventLines []string
+	if cfg.Calendar.Enabled && cfg.Calendar.Source != "" {
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		fetchCtx, cancel := context.WithTimeout(ctx, cfg.Timeouts.Context())
+		events, err := fetchCalendarEvents(fetchCtx, cfg, today, today.AddDate(0, 0, 1), logger)
+		cancel()
+		if err != nil {
+			calendarWarning(os.Stderr, err)
+		}
+		for _, e := range events {
+			eventLines = append(eventLines, fmt.Sprintf("%s–%s %s",
+				e.StartTime.Local().Format("15:04"), e.EndTime.Local().Format("15:04"), e.Summary))
+		}
+	}
+
+	if len(entryLines) == 0 && len(eventLines) == 0 {
+		return fmt.Errorf("no entries on %s and no calendar events today — nothing to summarise", prevDay.Format("Monday 2006-01-02"))
+	}
+
+	writer, ok := newAIProvider(cfg, logger).(ai.StandupWriter)
+	if !ok {
+		return fmt.Errorf("the configured AI provider cannot write standups")
+	}
+	aiCtx, cancel := context.WithTimeout(ctx, cfg.Timeouts.AI())
+	defer cancel()
+	standup, err := writer.WriteStandup(aiCtx, prevDay.Format("Monday 2006-01-02"), entryLines, eventLines)
+	if err != nil {
+		return fmt.Errorf("generating standup: %w", err)
+	}
+
+	text := standup.String()
+	fmt.Print(text)
+
+	if copyOut, _ := cmd.Flags().GetBool("copy"); copyOut {
+		if err := clipboard.Copy(text); err != nil {
+			return fmt.Errorf("copying to clipboard: %w", err)
+		}
+		fmt.Fprintln(os.Stderr, "Copied to clipboard.")
+	}
+	return nil
+}
+
+func runDigest(cmd *cobra.Command, args []string) error {
+	dateStr, _ := cmd.Flags().GetString("date")
+	send, _ := cmd.Flags().GetBool("send")
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	day := time.Now().In(cfg.Schedule.Location())
+	if dateStr != "" {
+		if day, err = parseDate(dateStr); err != nil {
+			return fmt.Errorf("invalid --date: %w", err)
+		}
+	}
+	monday := digest.WeekBefore(day)
+
+	db, err := openStore()
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer db.Close()
+
+	if !send {
+		d, err := digest.Build(db, cfg.Schedule, monday)
+		if err != nil {
+			return err
+		}
+		fmt.Print(d.Markdown())
+		return nil
+	}
+	if !cfg.Digest.Notify && cfg.Digest.File == "" && len(cfg.Digest.Email.To) == 0 {
+		return fmt.Errorf("no digest channels configured — set notify, file or email.to in [digest]")
+	}
+	return scheduler.SendDigest(cfg, db, monday, os.Stdout)
+}
+
+func runExport(cmd *cobra.Command, args []string) error {
+	monthStr, _ := cmd.Flags().GetString("month")
+	fromStr, _ := cmd.Flags().GetString("from")
+	toStr, _ := cmd.Flags().GetString("to")
+	profile, _ := cmd.Flags().GetString("profile")
+	templatePath, _ := cmd.Flags().GetString("template")
+	out, _ := cmd.Flags().GetString("out")
+
+	if (fromStr != "") != (toStr != "") {
+		return fmt.Errorf("both --from and --to must be provided together")
+	}
+	if monthStr != "" && fromStr != "" {
+		return fmt.Errorf("--month cannot be combined with --from/--to")
+	}
+
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	var to time.Time
+	switch {
+	case fromStr != "":
+		var err error
+		if from, err = parseDate(fromStr); err != nil {
+			return fmt.Errorf("invalid --from date: %w", err)
+		}
+		if to, err = parseDate(toStr); err != nil {
+			return fmt.Errorf("invalid --to date: %w", err)
+		}
+		if to.Before(from) {
+			return fmt.Errorf("--to date must be on or after --from date")
+		}
+	case monthStr == "last":
+		from = from.AddDate(0, -1, 0)
+		to = from.AddDate(0, 1, -1)
+	case monthStr != "":
+		m, err := time.ParseInLocation("2006-01", monthStr, now.Location())
+		if err != nil {
+			return fmt.Errorf("invalid --month %q: use YYYY-MM or last", monthStr)
+		}
+		from = m
+		to = from.AddDate(0, 1, -1)
+	default:
+		to = from.AddDate(0, 1, -1)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	if profile == "" {
+		profile = cfg.Export.Profile
+	}
+	if profile == "" {
+		profile = "csv"
+	}
+	if templatePath == "" {
+		templatePath = cfg.Export.Template
+	}
+	exporter, err := export.New(profile, templatePath)
+	if err != nil {
+		return err
+	}
+
+	db, err := openStore()
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer db.Close()
+
+	entries, err := db.GetEntriesBetween(from, to.AddDate(0, 0, 1))
+	if err != nil {
+		return fmt.Errorf("fetching entries: %w", err)
+	}
+	// Only time that reached Clockify belongs in a report
+	var logged []store.Entry
+	for _, e := range entries {
+		if e.Status == "logged" && !e.StartTime.Before(from) {
+			logged = append(logged, e)
+		}
+	}
+	if len(logged) < len(entries) {
+		fmt.Fprintf(os.Stderr, "Note: %d entries that are not logged in Clockify, or started before %s, were left out.\n",
+			len(entries)-len(logged), from.Format("2006-01-02"))
+	}
+	report := export.NewReport(from, to, cfg.Export.Employee, logged)
+
+	if out == "" {
+		return exporter.Export(os.Stdout, report)
+	}
+	f, err := os.Create(out)
+	if err != nil {
+		return fmt.Errorf("creating %s: %w", out, err)
+	}
+	err = exporter.Export(f, report)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("writing %s: %w", out, err)
+	}
+	fmt.Printf("Wrote %d entries (%s to %s, %s) to %s\n", len(logged), from.Format("2006-01-02"), to.Format("2006-01-02"), profile, out)
+	return nil
+}
+
+// statsTop is how many projects and clients 'clockr stats' lists.
+const statsTop = 10
+
+type statsJSON struct {
+	From        string            `json:"from"`
+	To          string            `json:"to"` // inclusive
+	Projects    []trendJSON       `json:"projects"`
+	Clients     []trendJSON       `json:"clients"`
+	Sources     []trendJSON       `json:"sources"`
+	Billable    []trendJSON       `json:"billable"`
+	Tags        []trendJSON       `json:"tags"`
+	Days        []statsDayJSON    `json:"days"`
+	Suggestions suggestionsJSON   `json:"suggestions"`
+	Weekly      []suggestionsJSON `json:"suggestions_weekly"`
+}
+
+type trendJSON struct {
+	Name          string `json:"name"`
+	WeeklyMinutes []int  `json:"weekly_minutes"`
+	TotalMinutes  int    `json:"total_minutes"`
+}
+
+type statsDayJSON struct {
+	Date     string `json:"date"`
+	Projects int    `json:"projects"`
+}
+
+type suggestionsJSON struct {
+	Week       string  `json:"week,omitempty"`
+	Entries    int     `json:"entries"`
+	Confidence float64 `json:"confidence"`
+	Accepted   int     `json:"accepted"`
+	Edited     int     `json:"edited"`
+	EditRate   float64 `json:"edit_rate"`
+}
+
+func runStats(cmd *cobra.Command, args []string) error {
+	weeks, _ := cmd.Flags().GetInt("weeks")
+	if weeks < 1 {
+		return fmt.Errorf("--weeks must be at least 1")
+	}
+	filter, err := entryFilter(cmd)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	monday := today.AddDate(0, 0, -int(today.Weekday()+6)%7)
+	from, to := monday.AddDate(0, 0, -7*(weeks-1)), monday.AddDate(0, 0, 7)
+
+	db, err := openStore()
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer db.Close()
+
+	entries, err := db.GetEntriesBetween(from, to)
+	if err != nil {
+		return fmt.Errorf("fetching entries: %w", err)
+	}
+	entries = filter.Apply(entries)
+	suggestions := make(map[int]ai.BatchSuggestion)
+	for _, e := range entries {
+		if _, ok := suggestions[e.SuggestionID]; e.SuggestionID == 0 || ok {
+			continue
+		}
+		saved, err := db.GetEntrySuggestion(e.SuggestionID)
+		if err != nil {
+			return err
+		}
+		var s ai.BatchSuggestion
+		if saved != nil && json.Unmarshal([]byte(saved.Suggestion), &s) == nil {
+			suggestions[e.SuggestionID] = s
+		}
+	}
+
+	// Suggestions per week, then over the whole range.
+	var weekly []stats.Suggestions
+	var total stats.Suggestions
+	var confidenceSum float64
+	for w := range weeks {
+		start := from.AddDate(0, 0, 7*w)
+		end := start.AddDate(0, 0, 7)
+		var inWeek []store.Entry
+		for _, e := range entries {
+			if !e.StartTime.Before(start) && e.StartTime.Before(end) {
+				inWeek = append(inWeek, e)
+			}
+		}
+		var s stats.Suggestions
+		s.Confidence, s.Entries = stats.Confidence(inWeek, suggestions)
+		if s.Accepted, s.Edited, err = db.EditCounts(start, end); err != nil {
+			return err
+		}
+		weekly = append(weekly, s)
+		confidenceSum += s.Confidence * float64(s.Entries)
+		total.Entries += s.Entries
+		total.Accepted += s.Accepted
+		total.Edited += s.Edited
+	}
+	if total.Entries > 0 {
+		total.Confidence = confidenceSum / float64(total.Entries)
+	}
+
+	projects := stats.ByProject(entries, from, weeks)
+	clients := stats.ByClient(entries, from, weeks)
+	sources := stats.BySource(entries, from, weeks)
+	billable := stats.ByBillable(entries, from, weeks)
+	tags := stats.ByTag(entries, from, weeks)
+	days := stats.ContextSwitches(entries, from, today.AddDate(0, 0, 1)) // up to today
+
+	if outputJSON {
+		out := statsJSON{
+			From:        from.Format("2006-01-02"),
+			To:          to.AddDate(0, 0, -1).Format("2006-01-02"),
+			Projects:    toTrendJSON(projects),
+			Clients:     toTrendJSON(clients),
+			Sources:     toTrendJSON(sources),
+			Billable:    toTrendJSON(billable),
+			Tags:        toTrendJSON(tags),
+			Days:        []statsDayJSON{},
+			Suggestions: toSuggestionsJSON(total, ""),
+		}
+		for _, d := range days {
+			out.Days = append(out.Days, statsDayJSON{Date: d.Date.Format("2006-01-02"), Projects: d.Projects})
+		}
+		for w, s := range weekly {
+			out.Weekly = append(out.Weekly, toSuggestionsJSON(s, from.AddDate(0, 0, 7*w).Format("2006-01-02")))
+		}
+		return writeJSON(os.Stdout, out)
+	}
+
+	fmt.Printf("Last %d weeks, %s – %s (one mark per week, oldest first)\n", weeks, from.Format("2006-01-02"), to.AddDate(0, 0, -1).Format("2006-01-02"))
+	if filter != (stats.Filter{}) {
+		fmt.Printf("Only %s\n", filter)
+	}
+	if len(projects) == 0 {
+		fmt.Println("\nNo entries in this period.")
+		return nil
+	}
+	printTrends("Projects", projects)
+	printTrends("Clients", clients)
+	printTrends("Billable", billable)
+	printTrends("Sources", sources)
+	printTrends("Tags", tags)
+
+	var worked []stats.Day
+	perDay := make([]float64, len(days))
+	busiest := days[0]
+	for i, d := range days {
+		perDay[i] = float64(d.Projects)
+		if d.Projects > 0 {
+			worked = append(worked, d)
+		}
+		if d.Projects > busiest.Projects {
+			busiest = d
+		}
+	}
+	if len(worked) > 0 {
+		sum := 0
+		for _, d := range worked {
+			sum += d.Projects
+		}
+		fmt.Printf("\nFocus: %.1f projects a day on the %d days with entries, most %d on %s\n",
+			float64(sum)/float64(len(worked)), len(worked), busiest.Projects, busiest.Date.Format("Mon 2006-01-02"))
+		fmt.Printf("  %s  (one mark per day)\n", stats.Sparkline(perDay))
+	}
+
+	fmt.Println()
+	if total.Entries == 0 && total.Accepted == 0 {
+		fmt.Println("AI suggestions: none accepted in this period")
+		return nil
+	}
+	confidence := make([]float64, weeks)
+	edits := make([]float64, weeks)
+	for w, s := range weekly {
+		confidence[w] = s.Confidence
+		edits[w] = s.EditRate()
+	}
+	fmt.Println("AI suggestions:")
+	if total.Entries > 0 {
+		fmt.Printf("  confidence  %s  %.2f on average over %d entries\n", stats.Sparkline(confidence), total.Confidence, total.Entries)
+	}
+	if total.Accepted > 0 {
+		fmt.Printf("  edit rate   %s  %d%% of %d accepted suggestions were changed first\n", stats.Sparkline(edits), int(total.EditRate()*100+0.5), total.Accepted)
+	}
+	return nil
+}
+
+// printTrends lists the first statsTop trends with their weekly sparkline
+// and total hours.
+func printTrends(title string, trends []stats.Trend) {
+	width := 0
+	for i, t := range trends {
+		if i == statsTop {
+			break
+		}
+		width = max(width, min(len([]rune(t.Name)), 36))
+	}
+	fmt.Printf("\n%s:\n", title)
+	for i, t := range trends {
+		if i == statsTop {
+			fmt.Printf("  … %d more\n", len(trends)-statsTop)
+			break
+		}
+		weeks := make([]float64, len(t.Weeks))
+		for w, m := range t.Weeks {
+			weeks[w] = float64(m)
+		}
+		name := []rune(t.Name)
+		if len(name) > width {
+			name = append(name[:width-1], '…')
+		}
+		fmt.Printf("  %-*s  %s  %6.1fh\n", width, string(name), stats.Sparkline(weeks), float64(t.Total)/60)
+	}
+}
+
+func toTrendJSON(trends []stats.Trend) []trendJSON {
+	out := []trendJSON{}
+	for _, t := range trends {
+		out = append(out, trendJSON{Name: t.Name, WeeklyMinutes: t.Weeks, TotalMinutes: t.Total})
+	}
+	return out
+}
+
+func toSuggestionsJSON(s stats.Suggestions, week string) suggestionsJSON {
+	return suggestionsJSON{
+		Week:       week,
+		Entries:    s.Entries,
+		Confidence: s.Confidence,
+		Accepted:   s.Accepted,
+		Edited:     s.Edited,
+		EditRate:   s.EditRate(),
+	}
+}
+
+func runJournal(cmd *cobra.Command, args []string) error {
+	fromStr, _ := cmd.Flags().GetString("from")
+	toStr, _ := cmd.Flags().GetString("to")
+	search, _ := cmd.Flags().GetString("search")
+	dir, _ := cmd.Flags().GetString("dir")
+
+	db, err := openStore()
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer db.Close()
+
+	var entries []store.Entry
+	if fromStr == "" && toStr == "" {
+		entries, err = db.AllEntries()
+	} else {
+		from, to := time.Time{}, time.Now()
+		if fromStr != "" {
+			if from, err = parseDate(fromStr); err != nil {
+				return fmt.Errorf("invalid --from date: %w", err)
+			}
+		}
+		if toStr != "" {
+			if to, err = parseDate(toStr); err != nil {
+				return fmt.Errorf("invalid --to date: %w", err)
+			}
+		}
+		to = time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, to.Location()).AddDate(0, 0, 1)
+		entries, err = db.GetEntriesBetween(from, to)
+	}
+	if err != nil {
+		return fmt.Errorf("fetching entries: %w", err)
+	}
+
+	groups := journal.Filter(journal.Groups(entries), search)
+	if len(groups) == 0 {
+		return fmt.Errorf("no entries to put in the journal")
+	}
+	if dir == "" {
+		return journal.Render(os.Stdout, "Work journal", groups)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating %s: %w", dir, err)
+	}
+	for i := 0; i < len(groups); {
+		month := groups[i].Month()
+		j := i
+		for j < len(groups) && groups[j].Month() == month {
+			j++
+		}
+		first := groups[i].Entries[0]
+		path := filepath.Join(dir, "journal-"+month+".md")
+		f, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("creating journal file: %w", err)
+		}
+		err = journal.Render(f, "Work journal — "+first.StartTime.In(first.Location()).Format("January 2006"), groups[i:j])
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return fmt.Errorf("writing %s: %w", path, err)
+		}
+		fmt.Printf("Wrote %s (%d prompts)\n", path, j-i)
+		i = j
+	}
+	return nil
+}
+
+// runQuick logs a one-line description over the window ending now, for
+// launcher scripts. Without --wait it re-runs itself detached and returns.
+func runQuick(cmd *cobra.Command, args []string) error {
+	description := strings.TrimSpace(strings.Join(args, " "))
+	if description == "" {
+		return fmt.Errorf("empty description")
+	}
+
+	// Launchers wait for the command to exit, so hand the slow part (Clockify
+	// and the AI) to a detached copy of ourselves and return at once.
+	if wait, _ := cmd.Flags().GetBool("wait"); !wait {
+		exe, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("finding clockr executable: %w", err)
+		}
+		child := exec.Command(exe, quickChildArgs(cmd, description)...)
+		if err := child.Start(); err != nil {
+			return fmt.Errorf("starting background log: %w", err)
+		}
+		child.Process.Release()
+		fmt.Println("Logging in the background — you'll get a notification.")
+		return nil
+	}
+
+	notify, _ := cmd.Flags().GetBool("notify")
+	report := func(msg string) {
+		fmt.Println(msg)
+		if notify {
+			_ = scheduler.SendNotification("clockr", msg, nil, "")
+		}
+	}
+	err := quickLog(cmd, description, report)
+	if err != nil && notify {
+		_ = scheduler.SendNotification("clockr", "Not logged: "+err.Error(), nil, "")
+	}
+	return err
+}
+
+// quickChildArgs rebuilds the command line for the detached copy of 'clockr
+// quick': the flags that were set, then the description after "--" so a
+// description starting with a dash isn't parsed as a flag.
+func quickChildArgs(cmd *cobra.Command, description string) []string {
+	args := []string{cmd.Name(), "--wait", "--notify"}
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		if f.Name == "wait" || f.Name == "notify" {
+			return
+		}
+		args = append(args, "--"+f.Name+"="+f.Value.String())
+	})
+	return append(args, "--", description)
+}
+
+// quickLog matches description over the window ending now and logs it,
+// falling back to the most-used project when the AI can't be reached.
+func quickLog(cmd *cobra.Command, description string, report func(string)) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	db, err := openStore()
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer db.Close()
+	if db.ReadOnly() {
+		return fmt.Errorf("clockr is in read-only mode")
+	}
+
+	logger := setupLogger(cmd)
+	ctx := context.Background()
+	b, err := newBackend(ctx, cfg, db, logger)
+	if err != nil {
+		return err
+	}
+
+	end := time.Now().In(cfg.Schedule.Location()).Truncate(time.Minute)
+	interval := cfg.Schedule.IntervalFor(end.Weekday())
+	if m, _ := cmd.Flags().GetInt("minutes"); m > 0 {
+		interval = time.Duration(m) * time.Minute
+	}
+	start := end.Add(-interval)
+
+	var allocs []ai.Allocation
+	suggestionID := 0
+	note := ""
+	provider, err := buildProvider(cfg, db, false, logger)
+	if err == nil {
+		var suggestion *ai.Suggestion
+		suggestion, err = scheduler.Suggest(ctx, cfg, provider, b, db, description, start, end)
+		if err == nil && len(suggestion.Allocations) == 0 {
+			if suggestion.Clarification != "" {
+				return fmt.Errorf("%s", suggestion.Clarification)
+			}
+			return fmt.Errorf("no project matched %q", description)
+		}
+		if err == nil {
+			allocs = suggestion.Allocations
+			suggestionID = scheduler.RecordSuggestion(db, provider, description, suggestion, nil)
+		}
+	}
+	if err != nil {
+		logger.Warn("quick log: AI unavailable, using the most-used project", "error", err)
+		top, topErr := topProject(db, end)
+		if topErr != nil {
+			return fmt.Errorf("AI unavailable (%v) and %w", err, topErr)
+		}
+		allocs = []ai.Allocation{{
+			ProjectID:   top.ProjectID,
+			ProjectName: top.ProjectName,
+			ClientName:  top.ClientName,
+			Minutes:     int(interval.Minutes()),
+			Description: format.New(cfg.Format).Description(top.ProjectID, top.ProjectName, top.ClientName, description),
+		}}
+		note = " (AI unavailable, used your most-used project)"
+	}
+
+	entries, failed := scheduler.SubmitAllocations(ctx, cfg, b, db, mirror.New(cfg, logger), allocs, start, end, description, suggestionID, store.SourceQuick, io.Discard)
+	db.SetState("last_description", description)
+
+	parts := make([]string, len(entries))
+	for i, e := range entries {
+		parts[i] = fmt.Sprintf("%s — %s (%dmin)", e.ProjectName, e.Description, e.Minutes)
+	}
+	msg := fmt.Sprintf("Logged %s–%s%s: %s", start.Format("15:04"), end.Format("15:04"), note, strings.Join(parts, "; "))
+	if failed > 0 {
+		msg += fmt.Sprintf(". %d failed to reach Clockify — 'clockr retry' resubmits them", failed)
+	}
+	report(msg)
+	return nil
+}
+
+// topProject returns an entry on the project logged most often in the 30
+// days before now.
+func topProject(db *store.DB, now time.Time) (store.Entry, error) {
+	entries, err := db.GetEntriesBetween(now.AddDate(0, 0, -30), now)
+	if err != nil {
+		return store.Entry{}, err
+	}
+	counts := map[string]int{}
+	var top store.Entry
+	for _, e := range entries {
+		if e.ProjectID == "" {
+			continue
+		}
+		counts[e.ProjectID]++
+		if counts[e.ProjectID] > counts[top.ProjectID] {
+			top = e
+		}
+	}
+	if top.ProjectID == "" {
+		return store.Entry{}, fmt.Errorf("no entries in the last 30 days to fall back on")
+	}
+	return top, nil
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	db, err := openStore()
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer db.Close()
+
+	logger := setupLogger(cmd)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	b, err := newBackend(ctx, cfg, db, logger)
+	if err != nil {
+		return err
+	}
+	provider, err := buildProvider(cfg, db, cfg.AI.PromptFile, logger)
+	if err != nil {
+		return err
+	}
+
+	token, generated, err := serveToken(cfg, db)
+	if err != nil {
+		return err
+	}
+	addr := cfg.Serve.Addr()
+	if v, _ := cmd.Flags().GetString("listen"); v != "" {
+		addr = v
+	}
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           server.New(cfg, b, db, provider, token).Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	fmt.Printf("Serving the clockr API on http://%s\n", addr)
+	if generated {
+		fmt.Printf("Token: %s (send it as \"Authorization: Bearer <token>\"; set [serve] token to choose your own)\n", token)
+	}
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("serving API: %w", err)
+	}
+	return nil
+}
+
+// serveToken returns the API token from [serve] token or CLOCKR_SERVE_TOKEN,
+// else the one generated on an earlier start, else a new one, which is kept
+// in the state table. generated reports whether it should be shown.
+func serveToken(cfg *config.Config, db *store.DB) (token string, generated bool, err error) {
+	if cfg.Serve.Token != "" {
+		return cfg.Serve.Token, false, nil
+	}
+	if v, _ := db.GetState("serve_token"); v != "" {
+		return v, true, nil
+	}
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", false, fmt.Errorf("generating API token: %w", err)
+	}
+	token = hex.EncodeToString(buf)
+	if !db.ReadOnly() {
+		if err := db.SetState("serve_token", token); err != nil {
+			return "", false, fmt.Errorf("saving API token: %w", err)
+		}
+	}
+	return token, true, nil
+}
+
+// runDemo walks through the main flows against an in-memory Clockify, a
+// keyword-matching AI and a fake week of calendar events and commits. All
+// state lives in a temporary CLOCKR_HOME that is deleted afterwards.
+func runDemo(cmd *cobra.Command, args []string) error {
+	if !stdinIsTerminal() {
+		return fmt.Errorf("the demo is interactive — run it in a terminal")
+	}
+
+	home, err := os.MkdirTemp("", "clockr-demo-")
+	if err != nil {
+		return fmt.Errorf("creating sandbox: %w", err)
+	}
+	defer os.RemoveAll(home)
+	os.Setenv("CLOCKR_HOME", home)
+
+	srv := demo.NewServer()
+	defer srv.Close()
+
+	db, err := store.Open(false)
+	if err != nil {
+		return fmt.Errorf("opening sandbox database: %w", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	logger := setupLogger(cmd)
+	cfg := config.DefaultConfig()
+	b := backend.Clockify(clockify.NewClient("demo", srv.URL, time.Hour, logger), demo.WorkspaceID)
+	projects, err := b.ListProjects(ctx)
+	if err != nil {
+		return fmt.Errorf("fetching demo projects: %w", err)
+	}
+	provider := &demo.Provider{Delay: time.Second}
+	in := bufio.NewReader(os.Stdin)
+	pause := func() {
+		fmt.Print("\nPress Enter to continue...")
+		in.ReadString('\n')
+	}
+
+	fmt.Println("Welcome to the clockr demo. Everything runs against a local sandbox:")
+	fmt.Println("a fake Clockify workspace, a keyword-matching stand-in for the AI, and a")
+	fmt.Println("week of made-up meetings and commits. Nothing leaves this machine.")
+	fmt.Println()
+	fmt.Println("Step 1 of 3: log the last hour, as you would when 'clockr start' prompts you.")
+	fmt.Println("  • The description is filled in — press Enter to send it to the AI.")
+	fmt.Println("  • On the suggestion, press e to edit: n adds a row, c copies one,")
+	fmt.Println("    s splits one and d deletes one. The help line lists the rest.")
+	fmt.Println("  • Press a to accept and create the entries.")
+	pause()
+
+	now := time.Now().Truncate(time.Minute)
+	interval := cfg.Schedule.IntervalFor(now.Weekday())
+	app := tui.NewApp(now.Add(-interval), now, provider, projects, b, db, interval, demo.ContextFor(now), "")
+	app.SetInitialInput("fixed the landing page css, then reviewed the data pipeline with Globex")
+	app.SetFormatter(format.New(cfg.Format))
+	if _, err := tea.NewProgram(app).Run(); err != nil {
+		return fmt.Errorf("running TUI: %w", err)
+	}
+
+	fmt.Println()
+	fmt.Println("Step 2 of 3: catch up on last week in one go, like 'clockr log --from monday --to friday'.")
+	fmt.Println("  • Each day already has its meetings and commits, so a short description is enough.")
+	fmt.Println("  • Use ←/→ to move between days and e to edit an entry.")
+	fmt.Println("  • Press a to accept the current day, or A to accept the whole week.")
+	pause()
+
+	monday := now.AddDate(0, 0, -int(now.Weekday()+6)%7-7)
+	days, err := buildDaySlots(&cfg, monday, monday.AddDate(0, 0, 4))
+	if err != nil {
+		return err
+	}
+	demo.SeedWeek(days)
+	batch := tui.NewBatchApp(days, provider, projects, b, db, "")
+	batch.SetInitialInput("website redesign most of the week, some mobile push work and the data warehouse")
+	batch.SetFormatter(format.New(cfg.Format))
+	if _, err := tea.NewProgram(batch).Run(); err != nil {
+		return fmt.Errorf("running batch TUI: %w", err)
+	}
+
+	fmt.Println()
+	fmt.Println("Step 3 of 3: report on what you logged.")
+	entries, err := db.AllEntries()
+	if err != nil {
+		return fmt.Errorf("fetching entries: %w", err)
+	}
+	if len(entries) == 0 {
+		fmt.Println("Nothing was logged — run 'clockr demo' again and accept a suggestion to see a report.")
+	} else {
+		totals := map[string]int{}
+		var entryLines []string
+		for _, e := range entries {
+			project := e.ProjectName
+			if e.ClientName != "" {
+				project += " (" + e.ClientName + ")"
+			}
+			totals[project] += int(e.EndTime.Sub(e.StartTime).Minutes())
+			entryLines = append(entryLines, fmt.Sprintf("%s–%s %s: %s",
+				e.StartTime.Format("15:04"), e.EndTime.Format("15:04"), project, e.Description))
+		}
+		fmt.Printf("\n%d entries in the sandbox workspace:\n", len(srv.Entries()))
+		for _, project := range slices.Sorted(maps.Keys(totals)) {
+			fmt.Printf("  %-34s %dh %dmin\n", project, totals[project]/60, totals[project]%60)
+		}
+
+		standup, err := provider.WriteStandup(ctx, "the demo", entryLines, ai.ContextTexts(demo.ContextFor(now)))
+		if err != nil {
+			return fmt.Errorf("generating standup: %w", err)
+		}
+		fmt.Println("\nAnd the standup 'clockr standup' would write from it:")
+		fmt.Print(standup.String())
+	}
+
+	fmt.Println()
+	fmt.Println("That's the tour. With a real account, 'clockr init' sets things up,")
+	fmt.Println("'clockr start' prompts you on a schedule, 'clockr status' shows today,")
+	fmt.Println("'clockr standup' summarises yesterday and 'clockr audit-diff' checks")
+	fmt.Println("your local history against Clockify.")
+	fmt.Println("Nothing was sent to Clockify; the sandbox has been deleted.")
+	return nil
+}
+
+// previousWorkDay returns midnight of the last configured work day before
+// now, so Monday's standup covers Friday.
+func previousWorkDay(cfg *config.Config, now time.Time) time.Time {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	for i := 1; i <= 7; i++ {
+		day := today.AddDate(0, 0, -i)
+		if cfg.Schedule.IsWorkDay(day.Weekday()) && !cfg.Schedule.OnHoliday(day) {
+			return day
+		}
+	}
+	return today.AddDate(0, 0, -1)
+}
+
+func runPlugins(cmd *cobra.Command, args []string) error {
+	dir, err := plugin.Dir()
+	if err != nil {
+		return err
+	}
+	plugins, errs := plugin.Discover(context.Background())
+	for _, err := range errs {
+		fmt.Printf("Warning: %v\n", err)
+	}
+	if len(plugins) == 0 {
+		fmt.Printf("No plugins in %s\n", dir)
+		return nil
+	}
+
+	fmt.Printf("Plugins in %s:\n", dir)
+	for _, p := range plugins {
+		caps := strings.Join(p.Capabilities, ", ")
+		if caps == "" {
+			caps = "nothing"
+		}
+		fmt.Printf("  %-20s %s (%s)\n", p.Name, caps, filepath.Base(p.Path))
+	}
+	return nil
+}
+
+func runProjects(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	db, err := openStore()
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer db.Close()
+
+	logger := setupLogger(cmd)
+	// Always list live projects, and save them for the next 'clockr log'.
+	if err := db.ClearCache(); err != nil && !errors.Is(err, store.ErrReadOnly) {
+		logger.Debug("clearing project cache", "error", err)
+	}
+	ctx := context.Background()
+
+	b, err := newBackend(ctx, cfg, db, logger)
+	if err != nil {
+		return err
+	}
+
+	projects, err := b.ListProjects(ctx)
+	if err != nil {
+		return fmt.Errorf("fetching projects: %w", err)
+	}
+
+	if outputJSON {
+		out := make([]projectJSON, len(projects))
+		for i, p := range projects {
+			out[i] = projectJSON{ID: p.ID, Name: p.Name, ClientID: p.ClientID, ClientName: p.ClientName, Archived: p.Archived}
+		}
+		return writeJSON(os.Stdout, out)
+	}
+
+	if len(projects) == 0 {
+		fmt.Println("No projects found.")
+		return nil
+	}
+
+	onboardNewProjects(cfg, db, projects)
+
+	fmt.Printf("Found %d projects:\n\n", len(projects))
+	for _, p := range projects {
+		if p.ClientName != "" {
+			fmt.Printf("  %s  %s / %s\n", p.ID, p.ClientName, p.Name)
+		} else {
+			fmt.Printf("  %s  %s\n", p.ID, p.Name)
+		}
+	}
+
+	return nil
+}
+
+// projectJSON is one project in 'clockr projects --output json'.
+type projectJSON struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	ClientID   string `json:"client_id,omitempty"`
+	ClientName string `json:"client_name,omitempty"`
+	Archived   bool   `json:"archived"`
+}
+
+func runMigrateWorkspace(cmd *cobra.Command, args []string) error {
+	if readOnly {
+		return fmt.Errorf("cannot migrate workspaces in read-only mode")
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if err := requireClockify(cfg, "migrate-workspace"); err != nil {
+		return err
+	}
+
+	in := bufio.NewReader(os.Stdin)
+	logger := setupLogger(cmd)
+	client := newClockifyClient(cfg, logger)
+	ctx := context.Background()
+
+	oldID, err := resolveWorkspaceID(ctx, cfg, client)
+	if err != nil {
+		return err
+	}
+	newID, err := pickNewWorkspace(ctx, in, client, oldID, args)
+	if err != nil || newID == "" {
+		return err
+	}
+
+	oldProjects, err := client.GetProjects(ctx, oldID)
+	if err != nil {
+		return fmt.Errorf("fetching projects of the old workspace: %w", err)
+	}
+	enrichProjectsWithClients(ctx, client, oldID, oldProjects, logger)
+	newProjects, err := client.GetProjects(ctx, newID)
+	if err != nil {
+		return fmt.Errorf("fetching projects of the new workspace: %w", err)
+	}
+	enrichProjectsWithClients(ctx, client, newID, newProjects, logger)
+
+	mapping, unmatched := matchProjects(oldProjects, newProjects)
+	byID := make(map[string]clockify.Project, len(newProjects))
+	for _, p := range newProjects {
+		byID[p.ID] = p
+	}
+	fmt.Printf("\nMatched %d of %d projects by name:\n", len(mapping), len(oldProjects))
+	for _, p := range oldProjects {
+		if newPID, ok := mapping[p.ID]; ok {
+			fmt.Printf("  %s → %s\n", projectLabel(p), projectLabel(byID[newPID]))
+		}
+	}
+
+	// Offer the new projects nobody matched for each old project left over.
+	if len(unmatched) > 0 {
+		var free []clockify.Project
+		taken := make(map[string]bool, len(mapping))
+		for _, id := range mapping {
+			taken[id] = true
+		}
+		for _, p := range newProjects {
+			if !taken[p.ID] {
+				free = append(free, p)
+			}
+		}
+		fmt.Printf("\n%d projects have no match by name.\n", len(unmatched))
+		if len(free) > 0 {
+			fmt.Println("New projects still unmatched:")
+			for i, p := range free {
+				fmt.Printf("  %d) %s\n", i+1, projectLabel(p))
+			}
+		}
+		for _, p := range unmatched {
+			if len(free) == 0 {
+				fmt.Printf("  %s: no match\n", projectLabel(p))
+				continue
+			}
+			for {
+				ans, err := ask(in, fmt.Sprintf("Match for %s (number, blank to skip)", projectLabel(p)), "")
+				if err != nil {
+					return err
+				}
+				if ans == "" {
+					break
+				}
+				n, err := strconv.Atoi(ans)
+				if err != nil || n < 1 || n > len(free) {
+					fmt.Printf("  Enter a number between 1 and %d, or leave blank.\n", len(free))
+					continue
+				}
+				mapping[p.ID] = free[n-1].ID
+				break
+			}
+		}
+	}
+
+	ok, err := askYesNo(in, fmt.Sprintf("\nSwitch to the new workspace and remap %d projects?", len(mapping)), true)
+	if err != nil || !ok {
+		fmt.Println("Cancelled.")
+		return err
+	}
+
+	rewritten, err := config.SaveWorkspaceMigration(newID, mapping)
+	if err != nil {
+		return fmt.Errorf("updating config: %w", err)
+	}
+	db, err := openStore()
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer db.Close()
+	entries, err := db.MigrateProjects(oldID, newID, mapping)
+	if err != nil {
+		return err
+	}
+	// The next command lists the new workspace's projects live.
+	if err := db.ClearCache(); err != nil {
+		logger.Debug("clearing project cache", "error", err)
+	}
+
+	// Only the mapped projects count as known, so new ones get onboarded.
+	var known []clockify.Project
+	for _, id := range mapping {
+		known = append(known, byID[id])
+	}
+	if err := saveKnownProjects(db, known); err != nil {
+		fmt.Printf("Warning: saving known projects: %v\n", err)
+	}
+
+	fmt.Printf("Switched workspace_id to %s, rewrote %d config references and %d local entries.\n", newID, rewritten, entries)
+	if os.Getenv("CLOCKIFY_WORKSPACE_ID") != "" {
+		fmt.Println("Note: CLOCKIFY_WORKSPACE_ID is set and overrides the config — update it too.")
+	}
+	if _, err := scheduler.SendControl(ctx, scheduler.ControlRequest{Command: scheduler.CmdStatus}); err == nil {
+		fmt.Println("Restart the scheduler (clockr stop && clockr start) to use the new workspace.")
+	}
+	return nil
+}
+
+// pickNewWorkspace returns the workspace ID given as an argument, or asks
+// for one of the other workspaces. It returns "" when there is none.
+func pickNewWorkspace(ctx context.Context, in *bufio.Reader, client *clockify.Client, current string, args []string) (string, error) {
+	if len(args) == 1 {
+		if args[0] == current {
+			return "", fmt.Errorf("%s is already the configured workspace", current)
+		}
+		return args[0], nil
+	}
+
+	workspaces, err := client.GetWorkspaces(ctx)
+	if err != nil {
+		return "", err
+	}
+	var others []clockify.Workspace
+	for _, w := range workspaces {
+		if w.ID != current {
+			others = append(others, w)
+		}
+	}
+	if len(others) == 0 {
+		fmt.Println("Your account has no other workspaces.")
+		return "", nil
+	}
+	for i, w := range others {
+		fmt.Printf("  %d) %s\n", i+1, w.Name)
+	}
+	for {
+		ans, err := ask(in, "Migrate to workspace", "1")
+		if err != nil {
+			return "", err
+		}
+		n, err := strconv.Atoi(ans)
+		if err != nil || n < 1 || n > len(others) {
+			fmt.Printf("  Enter a number between 1 and %d.\n", len(others))
+			continue
+		}
+		return others[n-1].ID, nil
+	}
+}
+
+// matchProjects maps old project IDs to new ones by name, case-insensitively.
+// A project with the same client wins; otherwise a name that is unique in
+// the new workspace is enough. It returns the old projects left unmatched.
+func matchProjects(old, cur []clockify.Project) (map[string]string, []clockify.Project) {
+	key := func(p clockify.Project) string {
+		return strings.ToLower(strings.TrimSpace(p.ClientName)) + "\x00" + strings.ToLower(strings.TrimSpace(p.Name))
+	}
+	byKey := make(map[string]string)
+	byName := make(map[string][]string)
+	for _, p := range cur {
+		byKey[key(p)] = p.ID
+		name := strings.ToLower(strings.TrimSpace(p.Name))
+		byName[name] = append(byName[name], p.ID)
+	}
+
+	mapping := make(map[string]string)
+	taken := make(map[string]bool)
+	var unmatched []clockify.Project
+	for _, p := range old {
+		id, ok := byKey[key(p)]
+		if !ok || taken[id] {
+			if ids := byName[strings.ToLower(strings.TrimSpace(p.Name))]; len(ids) == 1 && !taken[ids[0]] {
+				id, ok = ids[0], true
+			} else {
+				ok = false
+			}
+		}
+		if !ok {
+			unmatched = append(unmatched, p)
+			continue
+		}
+		mapping[p.ID] = id
+		taken[id] = true
+	}
+	return mapping, unmatched
+}
+
+func projectLabel(p clockify.Project) string {
+	if p.ClientName != "" {
+		return p.ClientName + " / " + p.Name
+	}
+	return p.Name
+}
+
+// mirrorRange parses the --from and --to flags of the mirror commands and
+// returns the logged entries in that range.
+func mirrorRange(cmd *cobra.Command, db *store.DB) ([]store.Entry, error) {
+	fromStr, _ := cmd.Flags().GetString("from")
+	toStr, _ := cmd.Flags().GetString("to")
+	if toStr == "" {
+		toStr = fromStr
+	}
+	from, err := parseDate(fromStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --from date: %w", err)
+	}
+	to, err := parseDate(toStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --to date: %w", err)
+	}
+	if to.Before(from) {
+		return nil, fmt.Errorf("--to date must be on or after --from date")
+	}
+	entries, err := db.GetEntriesBetween(from, to.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	var logged []store.Entry
+	for _, e := range entries {
+		if e.Status == "logged" {
+			logged = append(logged, e)
+		}
+	}
+	return logged, nil
+}
+
+// runMirrorSync mirrors the range's logged entries to the destinations they
+// have not reached yet, such as ones that were unreachable when the entries
+// were logged.
+func runMirrorSync(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if len(cfg.Mirrors()) == 0 {
+		return fmt.Errorf("mirroring is off — list destinations in [mirror] to")
+	}
+	db, err := openStore()
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer db.Close()
+	if db.ReadOnly() {
+		return fmt.Errorf("read-only mode — nothing was mirrored")
+	}
+
+	entries, err := mirrorRange(cmd, db)
+	if err != nil {
+		return err
+	}
+	n, err := mirror.Sync(context.Background(), cfg, db, entries, setupLogger(cmd))
+	if n > 0 || err == nil {
+		fmt.Printf("Mirrored %d copy(ies) to %s.\n", n, strings.Join(cfg.Mirrors(), ", "))
+	}
+	return err
+}
+
+// runMirrorStatus lists the range's logged entries with their state in each
+// mirror destination.
+func runMirrorStatus(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	dests := cfg.Mirrors()
+	if len(dests) == 0 {
+		return fmt.Errorf("mirroring is off — list destinations in [mirror] to")
+	}
+	db, err := openStore()
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer db.Close()
+
+	entries, err := mirrorRange(cmd, db)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		fmt.Println("No logged entries in that range.")
+		return nil
+	}
+	ids := make([]int, len(entries))
+	for i, e := range entries {
+		ids[i] = e.ID
+	}
+	state, err := db.GetMirrors(ids)
+	if err != nil {
+		return err
+	}
+
+	loc := cfg.Schedule.Location()
+	maxAttempts := cfg.Mirror.Attempts()
+	pending := 0
+	for _, e := range entries {
+		start := e.StartTime.In(loc)
+		fmt.Printf("%s %s–%s  %s — %s\n", start.Format("2006-01-02"), start.Format("15:04"), e.EndTime.In(loc).Format("15:04"), e.ProjectName, e.Description)
+		for _, d := range dests {
+			m, ok := state[e.ID][d]
+			switch {
+			case !ok:
+				fmt.Printf("  %-9s pending\n", d)
+				pending++
+			case m.Status == "logged":
+				fmt.Printf("  %-9s logged %s\n", d, m.RemoteID)
+			case m.Status == "writing":
+				fmt.Printf("  %-9s being written\n", d)
+			case m.Attempts >= maxAttempts:
+				fmt.Printf("  %-9s gave up after %d attempts: %s\n", d, m.Attempts, m.Error)
+			default:
+				fmt.Printf("  %-9s failed (%d/%d attempts): %s\n", d, m.Attempts, maxAttempts, m.Error)
+				pending++
+			}
+		}
+	}
+	if pending > 0 {
+		fmt.Printf("\n%d copy(ies) outstanding — run 'clockr mirror sync' or 'clockr retry'.\n", pending)
+	}
+	return nil
+}
+
+func runCalendarTest(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	if !cfg.Calendar.Enabled || cfg.Calendar.Source == "" {
+		return fmt.Errorf("calendar not configured — add [calendar] section to config with enabled = true and source = \"...\"")
+	}
+
+	now := time.Now()
+	windowStart := now.Add(-24 * time.Hour)
+	windowEnd := now.Add(7 * 24 * time.Hour)
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeouts.Context())
+	defer cancel()
+
+	logger := setupLogger(cmd)
+	events, err := fetchCalendarEvents(ctx, cfg, windowStart, windowEnd, logger)
+	if err != nil {
+		return fmt.Errorf("fetching calendar: %w", err)
+	}
+
+	if outputJSON {
+		out := calendarTestJSON{Events: []eventJSON{}, Prefill: ai.FormatPrefill(sources.CalendarItems(events, cfg.Schedule.Location()))}
+		for _, e := range events {
+			out.Events = append(out.Events, eventJSON{Summary: e.Summary, Start: e.StartTime, End: e.EndTime})
+		}
+		return writeJSON(os.Stdout, out)
+	}
+
+	if len(events) == 0 {
+		fmt.Println("No events found in the past 24h to next 7 days.")
+		return nil
+	}
+
+	fmt.Printf("Found %d events:\n\n", len(events))
+	for _, e := range events {
+		fmt.Printf("  %s – %s  %s\n",
+			e.StartTime.Local().Format("Mon Jan 02 15:04"),
+			e.EndTime.Local().Format("15:04"),
+			e.Summary,
+		)
+	}
+
+	fmt.Printf("\nInserted by Ctrl+P in the TUI:\n%s\n", ai.FormatPrefill(sources.CalendarItems(events, cfg.Schedule.Location())))
+	return nil
+}
+
+// calendarTestJSON is the --output json form of 'clockr calendar test'.
+type calendarTestJSON struct {
+	Events  []eventJSON `json:"events"`
+	Prefill string      `json:"prefill"`
+}
+
+type eventJSON struct {
+	Summary string    `json:"summary"`
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+}
+
+func runConfig(cmd *cobra.Command, args []string) error {
+	if err := config.EnsureConfigDir(); err != nil {
+		return fmt.Errorf("creating config directory: %w", err)
+	}
+
+	configPath, err := config.ConfigPath()
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		// Create default config file
+		cfg := config.DefaultConfig()
+		data := renderConfig(&cfg)
+		if err := os.WriteFile(configPath, []byte(data), 0644); err != nil {
+			return fmt.Errorf("writing default config: %w", err)
+		}
+	}
+
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = "vi"
+	}
+
+	fmt.Printf("Opening %s with %s...\n", configPath, editor)
+
+	proc := os.ProcAttr{
+		Files: []*os.File{os.Stdin, os.Stdout, os.Stderr},
+	}
+	process, err := os.StartProcess(editor, []string{editor, configPath}, &proc)
+	if err != nil {
+		// If editor fails, just print the path
+		fmt.Printf("Could not open editor. Config file is at: %s\n", configPath)
+		return nil
+	}
+	_, err = process.Wait()
+	return err
+}
+
+func runInit(cmd *cobra.Command, args []string) error {
+	configPath, err := config.ConfigPath()
+	if err != nil {
+		return err
+	}
+
+	in := bufio.NewReader(os.Stdin)
+	ctx := context.Background()
+	logger := setupLogger(cmd)
+
+	// Start from the existing config (and env vars) so its values become the
+	// prompt defaults when re-running init.
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Printf("Existing config could not be parsed (%v); starting from defaults.\n", err)
+		def := config.DefaultConfig()
+		cfg = &def
+	}
+	if _, err := os.Stat(configPath); err == nil {
+		ok, err := askYesNo(in, fmt.Sprintf("%s already exists. Overwrite it?", configPath), false)
+		if err != nil || !ok {
+			fmt.Println("Cancelled.")
+			return err
+		}
+	}
+
+	fmt.Println("\n— Clockify —")
+	fmt.Println("Find your API key at https://app.clockify.me/user/preferences#advanced")
+	var client *clockify.Client
+	var user *clockify.User
+	for {
+		key, err := askSecret(in, "Clockify API key", cfg.Clockify.APIKey)
+		if err != nil {
+			return err
+		}
+		if key == "" {
+			fmt.Println("  An API key is required.")
+			continue
+		}
+		cfg.Clockify.APIKey = key
+		client = clockify.NewClient(key, cfg.Clockify.BaseURL, 1*time.Hour, logger)
+
+		checkCtx, cancel := context.WithTimeout(ctx, cfg.Timeouts.Clockify())
+		user, err = client.GetUser(checkCtx)
+		cancel()
+		if err != nil {
+			fmt.Printf("  ✗ %v\n", err)
+			continue
+		}
+		fmt.Printf("  ✓ Authenticated as %s (%s)\n", user.Name, user.Email)
+		break
+	}
+
+	workspaces, err := client.GetWorkspaces(ctx)
+	if err != nil {
+		return err
+	}
+	if len(workspaces) == 0 {
+		return fmt.Errorf("your Clockify account has no workspaces")
+	}
+	current := cfg.Clockify.WorkspaceID
+	if current == "" {
+		current = user.DefaultWorkspace
+	}
+	defIdx := 1
+	for i, w := range workspaces {
+		if w.ID == current {
+			defIdx = i + 1
+		}
+	}
+	if len(workspaces) == 1 {
+		fmt.Printf("  Using workspace %s\n", workspaces[0].Name)
+		cfg.Clockify.WorkspaceID = workspaces[0].ID
+	} else {
+		for i, w := range workspaces {
+			fmt.Printf("  %d) %s\n", i+1, w.Name)
+		}
+		for {
+			ans, err := ask(in, "Workspace", strconv.Itoa(defIdx))
+			if err != nil {
+				return err
+			}
+			n, err := strconv.Atoi(ans)
+			if err != nil || n < 1 || n > len(workspaces) {
+				fmt.Printf("  Enter a number between 1 and %d.\n", len(workspaces))
+				continue
+			}
+			cfg.Clockify.WorkspaceID = workspaces[n-1].ID
+			break
+		}
+	}
+	if msg := client.CheckAccess(ctx, cfg.Clockify.WorkspaceID); msg != "" {
+		fmt.Printf("  ✗ %s\n", msg)
+	}
+
+	fmt.Println("\n— Schedule —")
+	for _, f := range []struct {
+		label string
+		value *string
+	}{
+		{"Work day starts (HH:MM)", &cfg.Schedule.WorkStart},
+		{"Work day ends (HH:MM)", &cfg.Schedule.WorkEnd},
+	} {
+		for {
+			ans, err := ask(in, f.label, *f.value)
+			if err != nil {
+				return err
+			}
+			if _, _, err := parseTimeConfig(ans); err != nil || len(ans) != 5 {
+				fmt.Println("  Enter a 24-hour time like 09:00.")
+				continue
+			}
+			*f.value = ans
+			break
+		}
+	}
+	for {
+		ans, err := ask(in, "Prompt interval (minutes)", strconv.Itoa(cfg.Schedule.IntervalMinutes))
+		if err != nil {
+			return err
+		}
+		n, err := strconv.Atoi(ans)
+		if err != nil || n <= 0 {
+			fmt.Println("  Enter a positive number of minutes.")
+			continue
+		}
+		cfg.Schedule.IntervalMinutes = n
+		break
+	}
+
+	fmt.Println("\n— AI (OpenRouter) —")
+	cfg.AI.Provider = "openrouter"
+	key := cfg.AI.OpenRouterAPIKey
+	if key == "" {
+		key = cfg.AI.APIKey
+	}
+	if key, err = askSecret(in, "OpenRouter API key (blank to use OPENROUTER_API_KEY)", key); err != nil {
+		return err
+	}
+	// Don't write a key that came from the environment into the file.
+	if key != os.Getenv("OPENROUTER_API_KEY") {
+		cfg.AI.APIKey = key
+	} else {
+		cfg.AI.APIKey = ""
+	}
+	if cfg.AI.Model, err = ask(in, "Model", cfg.AI.Model); err != nil {
+		return err
+	}
+	if err := ai.VerifyOpenRouterAPIKey(key); err != nil {
+		fmt.Printf("  ✗ %v\n", err)
+	} else {
+		fmt.Println("  Testing the AI provider...")
+		testCtx, cancel := context.WithTimeout(ctx, cfg.Timeouts.AI())
+		_, err := ai.NewOpenRouter(key, cfg.AI.Model, logger).MatchProjects(testCtx, "setup test: general admin work",
+			[]clockify.Project{{ID: "test", Name: "Admin"}}, 15*time.Minute, nil, nil)
+		cancel()
+		if err != nil {
+			fmt.Printf("  ✗ AI test failed: %v\n", err)
+		} else {
+			fmt.Println("  ✓ AI provider responded")
+		}
+	}
+
+	fmt.Println("\n— Calendar (optional) —")
+	useCal, err := askYesNo(in, "Use calendar events as context?", cfg.Calendar.Enabled)
+	if err != nil {
+		return err
+	}
+	cfg.Calendar.Enabled = useCal
+	if useCal {
+		if cfg.Calendar.Source, err = ask(in, `Source ("graph", ICS URL, or file path)`, cfg.Calendar.Source); err != nil {
+			return err
+		}
+		if cfg.Calendar.Source != "graph" {
+			cfg.Calendar.Graph = config.GraphConfig{}
+		} else {
+			if cfg.Calendar.Graph.ClientID, err = ask(in, "Azure AD client ID", cfg.Calendar.Graph.ClientID); err != nil {
+				return err
+			}
+			if cfg.Calendar.Graph.TenantID, err = ask(in, "Azure AD tenant ID", cfg.Calendar.Graph.TenantID); err != nil {
+				return err
+			}
+		}
+	}
+
+	fmt.Println("\n— GitHub (optional) —")
+	useGitHub, err := askYesNo(in, "Use GitHub commits/PRs as context?", len(cfg.GitHub.Repos) > 0)
+	if err != nil {
+		return err
+	}
+	if useGitHub {
+		token, err := github.ResolveToken(cfg.GitHub.Token)
+		if err != nil {
+			fmt.Printf("  ✗ %v\n", err)
+		} else {
+			ghClient := github.NewClient(token, logger)
+			scopes, classic, err := ghClient.TokenScopes(ctx)
+			if err != nil {
+				fmt.Printf("  ✗ %v\n", err)
+			}
+			for _, w := range github.MissingScopeWarnings(scopes, classic) {
+				fmt.Printf("  ✗ %s\n", w)
+			}
+			if err == nil && len(cfg.GitHub.Repos) == 0 {
+				fetchCtx, cancel := context.WithTimeout(ctx, cfg.Timeouts.Context())
+				repos, err := ghClient.GetRepos(fetchCtx)
+				cancel()
+				if err != nil {
+					fmt.Printf("  ✗ fetching repos: %v\n", err)
+				} else if len(repos) > 0 {
+					picker := tui.NewRepoPickerApp(repos)
+					if _, err := tea.NewProgram(picker).Run(); err != nil {
+						return fmt.Errorf("running repo picker: %w", err)
+					}
+					if result := picker.GetResult(); result != nil && !result.Canceled {
+						cfg.GitHub.Repos = result.Repos
+					}
+				}
+			}
+		}
+	}
+
+	if err := config.EnsureConfigDir(); err != nil {
+		return fmt.Errorf("creating config directory: %w", err)
+	}
+	// Leave tokens that come from the environment there.
+	if v := os.Getenv("GITHUB_TOKEN"); v != "" && cfg.GitHub.Token == v {
+		cfg.GitHub.Token = ""
+	}
+	if v := os.Getenv("HARVEST_TOKEN"); v != "" && cfg.Harvest.Token == v {
+		cfg.Harvest.Token = ""
+	}
+	if v := os.Getenv("TOGGL_API_TOKEN"); v != "" && cfg.Toggl.APIToken == v {
+		cfg.Toggl.APIToken = ""
+	}
+	if v := os.Getenv("TEMPO_TOKEN"); v != "" && cfg.Tempo.Token == v {
+		cfg.Tempo.Token = ""
+	}
+	if v := os.Getenv("JIRA_API_TOKEN"); v != "" && cfg.Tempo.JiraToken == v {
+		cfg.Tempo.JiraToken = ""
+	}
+	if secrets.Available() {
+		useKeychain, err := askYesNo(in, "\nStore the Clockify key and GitHub token in the OS keychain instead of config.toml?", true)
+		if err != nil {
+			return err
+		}
+		if useKeychain {
+			if err := secrets.Set(secrets.ClockifyAPIKey, cfg.Clockify.APIKey); err != nil {
+				return fmt.Errorf("storing Clockify key in keychain: %w", err)
+			}
+			cfg.Clockify.APIKey = ""
+			if cfg.GitHub.Token != "" {
+				if err := secrets.Set(secrets.GitHubToken, cfg.GitHub.Token); err != nil {
+					return fmt.Errorf("storing GitHub token in keychain: %w", err)
+				}
+				cfg.GitHub.Token = ""
+			}
+		}
+	}
+
+	data := []byte(renderConfig(cfg))
+	if err := config.Validate(configPath, data); err != nil {
+		return fmt.Errorf("not writing config: %w", err)
+	}
+	// The file holds API keys, so keep it private.
+	if err := os.WriteFile(configPath, data, 0600); err != nil {
+		return fmt.Errorf("writing config: %w", err)
+	}
+
+	fmt.Printf("\nWrote %s\n", configPath)
+	if cfg.Calendar.Enabled && cfg.Calendar.Source == "graph" {
+		fmt.Println("Next: run 'clockr calendar auth' to connect your Microsoft calendar.")
+	}
+	fmt.Println("Run 'clockr log' to log time or 'clockr start' to start the scheduler.")
+	return nil
+}
+
+// ask prompts for a line of input, returning def if the answer is blank.
+func ask(in *bufio.Reader, label, def string) (string, error) {
+	if def != "" {
+		fmt.Printf("%s [%s]: ", label, def)
+	} else {
+		fmt.Printf("%s: ", label)
+	}
+	line, err := in.ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("reading input: %w", err)
+	}
+	if line = strings.TrimSpace(line); line == "" {
+		return def, nil
+	}
+	return line, nil
+}
+
+// askSecret is like ask but only shows the last four characters of def.
+func askSecret(in *bufio.Reader, label, def string) (string, error) {
+	shown := ""
+	if def != "" {
+		shown = "…" + def[max(0, len(def)-4):]
+	}
+	ans, err := ask(in, label, shown)
+	if err != nil || ans == shown {
+		return def, err
+	}
+	return ans, nil
+}
+
+func askYesNo(in *bufio.Reader, label string, def bool) (bool, error) {
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+	ans, err := ask(in, label+" ("+hint+")", "")
+	if err != nil {
+		return false, err
+	}
+	switch strings.ToLower(ans) {
+	case "":
+		return def, nil
+	case "y", "yes":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+func runConfigValidate(cmd *cobra.Command, args []string) error {
+	configPath, err := config.ConfigPath()
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("no config file at %s — run 'clockr init' to create one", configPath)
+		}
+		return fmt.Errorf("reading config: %w", err)
+	}
+
+	if err := config.Validate(configPath, data); err != nil {
+		cmd.SilenceUsage = true
+		return err
+	}
+	fmt.Printf("✓ %s is valid\n", configPath)
+
+	templates, err := ai.CheckPromptTemplates()
+	if err != nil {
+		cmd.SilenceUsage = true
+		return err
+	}
+	for _, path := range templates {
+		fmt.Printf("✓ %s is valid\n", path)
+	}
+	return nil
+}
+
+func runConfigPrompts(cmd *cobra.Command, args []string) error {
+	written, err := ai.WriteDefaultPrompts()
+	if err != nil {
+		return err
+	}
+	dir, err := ai.PromptsDir()
+	if err != nil {
+		return err
+	}
+	for _, path := range written {
+		fmt.Printf("Wrote %s\n", path)
+	}
+	if len(written) < len(ai.PromptTemplates) {
+		fmt.Printf("Existing templates in %s were left unchanged.\n", dir)
+	}
+	fmt.Println("Edit them to change what the AI is told; delete a file to go back to the built-in default.")
+	return nil
+}
+
+// quoteList formats strings as the inside of a TOML array.
+func quoteList(items []string) string {
+	quoted := make([]string, len(items))
+	for i, s := range items {
+		quoted[i] = strconv.Quote(s)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// secretLine renders key = value for a credential kept in config.toml. One
+// that came from the keychain or the environment stays there: the line only
+// says where it is.
+func secretLine(cfg *config.Config, key, value, name, env string) string {
+	switch {
+	case value == "":
+		return ""
+	case cfg.SecretSource(name) == "keychain":
+		return fmt.Sprintf("# %s: in the OS keychain (%s)\n", key, name)
+	case cfg.SecretSource(name) == "env":
+		return fmt.Sprintf("# %s: from %s\n", key, env)
+	}
+	return fmt.Sprintf("%s = %q\n", key, value)
+}
+
+// renderConfig renders cfg as a commented config.toml. Optional settings that
+// are unset are written as comments so the file doubles as documentation.
+func renderConfig(cfg *config.Config) string {
+	var b strings.Builder
+
+	b.WriteString("[clockify]\n")
+	if line := secretLine(cfg, "api_key", cfg.Clockify.APIKey, secrets.ClockifyAPIKey, "CLOCKIFY_API_KEY"); line != "" {
+		b.WriteString(line)
+	} else {
+		b.WriteString("api_key = \"\"\n")
+	}
+	fmt.Fprintf(&b, "workspace_id = %q\n", cfg.Clockify.WorkspaceID)
+	if cfg.Clockify.BaseURL != "" {
+		fmt.Fprintf(&b, "base_url = %q\n", cfg.Clockify.BaseURL)
+	} else {
+		b.WriteString("# base_url = \"\"  # set for regional servers (e.g. https://euc1.clockify.me/api/v1)\n")
+	}
+	if cfg.Clockify.OvertimeTagID != "" {
+		fmt.Fprintf(&b, "overtime_tag_id = %q\n", cfg.Clockify.OvertimeTagID)
+	} else {
+		b.WriteString("# overtime_tag_id = \"\"  # Clockify tag added to entries logged with --overtime\n")
+	}
+
+	switch h, t := cfg.Harvest, cfg.Toggl; {
+	case cfg.Backend.Harvest():
+		fmt.Fprintf(&b, "\n[backend]\ntype = \"harvest\"\n\n[harvest]\naccount_id = %q\n", h.AccountID)
+		b.WriteString(secretLine(cfg, "token", h.Token, secrets.HarvestToken, "HARVEST_TOKEN"))
+		if h.Task != "" {
+			fmt.Fprintf(&b, "task = %q\n", h.Task)
+		}
+	case cfg.Backend.Toggl():
+		fmt.Fprintf(&b, "\n[backend]\ntype = \"toggl\"\n\n[toggl]\nworkspace_id = %q\n", t.WorkspaceID)
+		b.WriteString(secretLine(cfg, "api_token", t.APIToken, secrets.TogglAPIToken, "TOGGL_API_TOKEN"))
+	case cfg.Backend.Tempo():
+		b.WriteString("\n[backend]\ntype = \"tempo\"\n")
+	default:
+		b.WriteString(`
+# [backend]  # where entries are logged: "clockify" (default), "harvest", "toggl" or "tempo"
+# type = "harvest"
+
+# [harvest]  # token: HARVEST_TOKEN or the keychain (harvest_token)
+# account_id = ""
+# task = "Development"  # task to log to on each project; default: its first task
+
+# [toggl]  # api_token: TOGGL_API_TOKEN or the keychain (toggl_api_token)
+# workspace_id = ""  # default: your default workspace
+`)
+	}
+	if tm := cfg.Tempo; cfg.Backend.Tempo() || cfg.MirrorsTo("tempo") {
+		fmt.Fprintf(&b, "\n[tempo]\njira_url = %q\njira_email = %q\n", tm.JiraURL, tm.JiraEmail)
+		b.WriteString(secretLine(cfg, "token", tm.Token, secrets.TempoToken, "TEMPO_TOKEN"))
+		b.WriteString(secretLine(cfg, "jira_token", tm.JiraToken, secrets.JiraAPIToken, "JIRA_API_TOKEN"))
+		if tm.JQL != "" {
+			fmt.Fprintf(&b, "jql = %q\n", tm.JQL)
+		}
+		if tm.Mirror {
+			b.WriteString("mirror = true\n")
+		}
+		if len(tm.Issues) > 0 {
+			b.WriteString("\n[tempo.issues]  # project ID or name = Jira issue key\n")
+			for _, project := range slices.Sorted(maps.Keys(tm.Issues)) {
+				fmt.Fprintf(&b, "%q = %q\n", project, tm.Issues[project])
+			}
+		}
+	} else {
+		b.WriteString(`
+# [tempo]  # Tempo for Jira; token: TEMPO_TOKEN, jira_token: JIRA_API_TOKEN, or the keychain
+# jira_url = "https://acme.atlassian.net"
+# jira_email = ""
+# mirror = true  # also log every entry to Tempo, or use [backend] type = "tempo"
+`)
+	}
+	if m := cfg.Mirror; len(m.To) > 0 {
+		quoted := make([]string, len(m.To))
+		for i, d := range m.To {
+			quoted[i] = strconv.Quote(d)
+		}
+		fmt.Fprintf(&b, "\n[mirror]\nto = [%s]\n", strings.Join(quoted, ", "))
+		if m.CSV != "" {
+			fmt.Fprintf(&b, "csv = %q\n", m.CSV)
+		}
+		if m.MaxAttempts > 0 {
+			fmt.Fprintf(&b, "max_attempts = %d\n", m.MaxAttempts)
+		}
+		if len(m.Projects) > 0 {
+			b.WriteString("\n[mirror.projects]  # project ID or name = destination project ID or name\n")
+			for _, project := range slices.Sorted(maps.Keys(m.Projects)) {
+				fmt.Fprintf(&b, "%q = %q\n", project, m.Projects[project])
+			}
+		}
+	} else {
+		b.WriteString(`
+# [mirror]  # also copy every logged entry to these destinations
+# to = ["csv", "tempo"]  # clockify, harvest, toggl, tempo or csv
+# csv = "/Users/me/Documents/clockr-ledger.csv"  # ledger for the "csv" destination
+`)
+	}
+
+	days := make([]string, len(cfg.Schedule.WorkDays))
+	for i, d := range cfg.Schedule.WorkDays {
+		days[i] = strconv.Itoa(d)
+	}
+	fmt.Fprintf(&b, "\n[schedule]\ninterval_minutes = %d\nwork_start = %q\nwork_end = %q\nwork_days = [%s]\nskip_reasons = [%s]  # offered when skipping a prompt\n",
+		cfg.Schedule.IntervalMinutes, cfg.Schedule.WorkStart, cfg.Schedule.WorkEnd, strings.Join(days, ", "), quoteList(cfg.Schedule.SkipReasons))
+	if len(cfg.Schedule.Holidays) > 0 {
+		fmt.Fprintf(&b, "holidays = [%s]\n", quoteList(cfg.Schedule.Holidays))
+	} else {
+		b.WriteString("# holidays = [\"2026-12-24\", \"2026-07-06..2026-07-17\"]  # days off, no prompts\n")
+	}
+	if cfg.Schedule.HolidayCalendar != "" {
+		fmt.Fprintf(&b, "holiday_calendar = %q\n", cfg.Schedule.HolidayCalendar)
+	} else {
+		b.WriteString("# holiday_calendar = \"\"  # ICS URL or file of public holidays\n")
+	}
+	if len(cfg.Schedule.Blocks) > 0 {
+		fmt.Fprintf(&b, "blocks = [%s]\n", quoteList(cfg.Schedule.Blocks))
+	} else {
+		b.WriteString("# blocks = [\"09:00-12:00\", \"13:00-17:00\"]  # replaces work_start/work_end, e.g. to leave out lunch\n")
+	}
+	if cfg.Schedule.Timezone != "" {
+		fmt.Fprintf(&b, "timezone = %q\n", cfg.Schedule.Timezone)
+	} else {
+		b.WriteString("# timezone = \"\"  # IANA zone for work hours; empty follows the system zone\n")
+	}
+	if cfg.Schedule.AutoAcceptSeconds > 0 {
+		fmt.Fprintf(&b, "auto_accept_seconds = %d\nauto_accept_confidence = %g\n", cfg.Schedule.AutoAcceptSeconds, cfg.Schedule.AutoAcceptConfidence)
+	} else {
+		b.WriteString("# auto_accept_seconds = 20  # scheduler prompts accept confident suggestions after a countdown\n")
+	}
+	if cfg.Schedule.CrossMidnight != "" {
+		fmt.Fprintf(&b, "cross_midnight = %q\n", cfg.Schedule.CrossMidnight)
+	} else {
+		b.WriteString("# cross_midnight = \"keep\"  # \"split\" logs entries spanning midnight as one per day\n")
+	}
+	if len(cfg.Schedule.Days) > 0 {
+		b.WriteString("\n[schedule.days]  # per-weekday hours\n")
+		names := slices.Sorted(maps.Keys(cfg.Schedule.Days))
+		for _, name := range names {
+			fmt.Fprintf(&b, "%s = [%s]\n", name, quoteList(cfg.Schedule.Days[name]))
+		}
+	}
+	if len(cfg.Schedule.Intervals) > 0 {
+		b.WriteString("\n[schedule.intervals]  # per-weekday prompt interval in minutes\n")
+		for _, name := range slices.Sorted(maps.Keys(cfg.Schedule.Intervals)) {
+			fmt.Fprintf(&b, "%s = %d\n", name, cfg.Schedule.Intervals[name])
+		}
+	}
+
+	fmt.Fprintf(&b, "\n[ai]\nprovider = %q\nmodel = %q\n", cfg.AI.Provider, cfg.AI.Model)
+	if cfg.AI.APIKey != "" {
+		fmt.Fprintf(&b, "api_key = %q\n", cfg.AI.APIKey)
+	} else {
+		b.WriteString("# api_key = \"\"  # or set OPENROUTER_API_KEY env var\n")
+	}
+	b.WriteString("# prompt_file = false  # set to true to always use prompt-file mode\n")
+	if cfg.AI.MaxProjects > 0 {
+		fmt.Fprintf(&b, "max_projects = %d\n", cfg.AI.MaxProjects)
+	} else {
+		b.WriteString("# max_projects = 40  # send the AI only the most relevant and recently used projects; 0 sends all\n")
+	}
+	if len(cfg.AI.Clients) > 0 {
+		fmt.Fprintf(&b, "clients = [%s]\n", quoteList(cfg.AI.Clients))
+	} else {
+		b.WriteString("# clients = [\"Acme\"]  # send the AI only these clients' projects\n")
+	}
+	if cfg.AI.CacheMinutes != 0 {
+		fmt.Fprintf(&b, "cache_minutes = %d\n", cfg.AI.CacheMinutes)
+	} else {
+		b.WriteString("# cache_minutes = 10  # reuse an AI answer for the same input this long; -1 turns the cache off\n")
+	}
+	if cfg.AI.Effort != "" {
+		fmt.Fprintf(&b, "effort = %q\n", cfg.AI.Effort)
+	} else {
+		b.WriteString("# effort = \"low\"  # reasoning effort: \"minimal\", \"low\", \"medium\" or \"high\"\n")
+	}
+	if cfg.AI.Thinking != nil {
+		fmt.Fprintf(&b, "thinking = %t\n", *cfg.AI.Thinking)
+	} else {
+		b.WriteString("# thinking = false  # turn the model's reasoning off (or on); unset leaves it to the model\n")
+	}
+	if cfg.AI.DescriptionStyle != "" {
+		fmt.Fprintf(&b, "description_style = %q\n", cfg.AI.DescriptionStyle)
+	} else {
+		b.WriteString("# description_style = \"past tense, one sentence\"  # told to the AI with every prompt\n")
+	}
+	if len(cfg.AI.DescriptionRules) > 0 {
+		fmt.Fprintf(&b, "description_rules = [%s]\n", quoteList(cfg.AI.DescriptionRules))
+	} else {
+		b.WriteString("# description_rules = [\"prefix with the JIRA key\"]\n")
+	}
+	if cfg.AI.GranularityMinutes > 0 {
+		fmt.Fprintf(&b, "granularity_minutes = %d\n", cfg.AI.GranularityMinutes)
+	} else {
+		b.WriteString("# granularity_minutes = 15  # send suggestions back to the AI unless minutes are multiples of this\n")
+	}
+	if len(cfg.AI.ExtraParams) > 0 {
+		// JSON scalars and arrays are valid TOML values.
+		b.WriteString("\n[ai.extra_params]\n")
+		for _, k := range slices.Sorted(maps.Keys(cfg.AI.ExtraParams)) {
+			v, _ := json.Marshal(cfg.AI.ExtraParams[k])
+			fmt.Fprintf(&b, "%q = %s\n", k, v)
+		}
+	}
+
+	snooze := make([]string, len(cfg.Notifications.SnoozeOptions))
+	for i, m := range cfg.Notifications.SnoozeOptions {
+		snooze[i] = strconv.Itoa(m)
+	}
+	fmt.Fprintf(&b, "\n[notifications]\nenabled = %t\nsnooze_options = [%s]\nopen_on_click = %t\n", cfg.Notifications.Enabled, strings.Join(snooze, ", "), cfg.Notifications.OpenOnClick)
+	if cfg.Notifications.TerminalCommand != "" {
+		fmt.Fprintf(&b, "terminal_command = %q\n", cfg.Notifications.TerminalCommand)
+	} else {
+		b.WriteString("# terminal_command = \"x-terminal-emulator -e {command}\"  # run on click; default depends on OS\n")
+	}
+	fmt.Fprintf(&b, "bell = %t  # ring the terminal bell when a prompt or snooze reminder comes up\n", cfg.Notifications.Bell)
+	fmt.Fprintf(&b, "tmux_message = %t  # show a message in the tmux status line\n", cfg.Notifications.TmuxMessage)
+	fmt.Fprintf(&b, "urgent = %t  # set the X11 urgency hint on the terminal window (needs xdotool)\n", cfg.Notifications.Urgent)
+
+	fmt.Fprintf(&b, "\n[calendar]\nenabled = %t\nsource = %q\nsplit_at_meetings = %t  # align entries with meeting start/end times\n", cfg.Calendar.Enabled, cfg.Calendar.Source, cfg.Calendar.SplitAtMeetings)
+	if cfg.Calendar.WriteBack {
+		b.WriteString("write_back = true  # add logged entries to the Graph calendar as private busy events\n")
+	} else {
+		b.WriteString("# write_back = false  # add logged entries to the Graph calendar as private busy events\n")
+	}
+	if cfg.Calendar.Graph.ClientID != "" || cfg.Calendar.Graph.TenantID != "" {
+		fmt.Fprintf(&b, "\n[calendar.graph]\nclient_id = %q\ntenant_id = %q\n", cfg.Calendar.Graph.ClientID, cfg.Calendar.Graph.TenantID)
+	} else {
+		b.WriteString(`# For Microsoft Graph API calendar, set source = "graph" and configure below:
+# [calendar.graph]
+# client_id = ""  # Azure AD Application (client) ID
+# tenant_id = ""  # Azure AD Directory (tenant) ID
+# write_profile = ""  # sign-in whose calendar gets write-back events
+# [calendar.graph.profiles.personal]  # 'clockr calendar auth --profile personal'
+# tenant_id = "consumers"
+`)
+	}
+
+	b.WriteString("\n[github]\n")
+	if line := secretLine(cfg, "token", cfg.GitHub.Token, secrets.GitHubToken, "GITHUB_TOKEN"); line != "" {
+		b.WriteString(line)
+	} else {
+		b.WriteString("# token = \"\"  # optional: uses 'gh auth token' or GITHUB_TOKEN env var by default\n")
+	}
+	if len(cfg.GitHub.Repos) > 0 {
+		repos := make([]string, len(cfg.GitHub.Repos))
+		for i, r := range cfg.GitHub.Repos {
+			repos[i] = strconv.Quote(r)
+		}
+		fmt.Fprintf(&b, "repos = [%s]\n", strings.Join(repos, ", "))
+	} else {
+		b.WriteString("# repos = []  # auto-populated after first --github run via repo picker\n")
+	}
+
+	if c := cfg.Context.Custom; c.Command != "" {
+		fmt.Fprintf(&b, "\n[context.custom]\ncommand = %q\n", c.Command)
+		if c.Name != "" {
+			fmt.Fprintf(&b, "name = %q\n", c.Name)
+		}
+	}
+
+	f := cfg.Format
+	if len(f.Prefixes) > 0 || f.StripTrailingPeriod || f.Case != "" || f.MaxLength > 0 || f.Pattern != "" || f.RoundMinutes > 0 {
+		fmt.Fprintf(&b, "\n[format]\nstrip_trailing_period = %t\ncase = %q\n", f.StripTrailingPeriod, f.Case)
+		if f.MaxLength > 0 {
+			fmt.Fprintf(&b, "max_length = %d\n", f.MaxLength)
+		}
+		if f.Pattern != "" {
+			fmt.Fprintf(&b, "pattern = %q\n", f.Pattern)
+		}
+		if f.RoundMinutes > 0 {
+			fmt.Fprintf(&b, "round_minutes = %d\n", f.RoundMinutes)
+			if f.Round != "" {
+				fmt.Fprintf(&b, "round = %q\n", f.Round)
+			}
+		}
+		if len(f.Prefixes) > 0 {
+			b.WriteString("\n[format.prefixes]\n")
+			for _, key := range slices.Sorted(maps.Keys(f.Prefixes)) {
+				fmt.Fprintf(&b, "%q = %q\n", key, f.Prefixes[key])
+			}
+		}
+	} else {
+		b.WriteString(`
+# [format]  # applied to descriptions after AI suggestions and edits
+# strip_trailing_period = true
+# case = "sentence"  # or "title"
+# max_length = 80  # flag longer descriptions
+# pattern = "^[A-Z]+-[0-9]+: "  # flag descriptions that don't match
+# round_minutes = 15  # round allocations to this step
+# round = "nearest"  # or "up", "down"
+# [format.prefixes]  # project ID, project name or client name → prefix
+# "Meetings" = "MTG/"
+`)
+	}
+
+	cov := cfg.Coverage
+	if cov.Policy != "" || cov.ProjectID != "" || len(cov.Days) > 0 {
+		fmt.Fprintf(&b, "\n[coverage]\npolicy = %q\nproject_id = %q\ndescription = %q\n", cov.Policy, cov.ProjectID, cov.Description)
+		if len(cov.Days) > 0 {
+			b.WriteString("\n[coverage.days]\n")
+			for _, key := range slices.Sorted(maps.Keys(cov.Days)) {
+				fmt.Fprintf(&b, "%s = %q\n", key, cov.Days[key])
+			}
+		}
+	} else {
+		b.WriteString(`
+# [coverage]  # log skipped windows to Clockify as breaks
+# policy = "full"  # "off" (default) or "full"
+# project_id = ""  # the Break/Unassigned project
+# [coverage.days]  # per-weekday override
+# friday = "off"
+`)
+	}
+
+	dg := cfg.Digest
+	if dg.Enabled || dg.Notify || dg.File != "" || len(dg.Email.To) > 0 {
+		fmt.Fprintf(&b, "\n[digest]\nenabled = %t\nday = %q\ntime = %q\nnotify = %t\nfile = %q\n", dg.Enabled, dg.Day, dg.Time, dg.Notify, dg.File)
+		if e := dg.Email; len(e.To) > 0 {
+			fmt.Fprintf(&b, "\n[digest.email]\nto = [%s]\nfrom = %q\nhost = %q\nport = %d\nusername = %q\n", quoteList(e.To), e.From, e.Host, e.Port, e.Username)
+		}
+	} else {
+		b.WriteString(`
+# [digest]  # weekly summary of last week, sent by the scheduler
+# enabled = true
+# day = "monday"
+# time = "09:00"
+# notify = true
+# file = ""  # markdown file; {week} becomes e.g. 2026-W41
+# [digest.email]  # password: CLOCKR_SMTP_PASSWORD or the keychain (smtp_password)
+# to = ["me@example.com"]
+# from = "clockr@example.com"
+# host = "smtp.example.com"
+# port = 587
+`)
+	}
+
+	if sl := cfg.Slack; sl.Enabled || sl.UserID != "" {
+		fmt.Fprintf(&b, "\n[slack]\nenabled = %t\nuser_id = %q\nlisten = %q\n", sl.Enabled, sl.UserID, sl.Listen)
+	} else {
+		b.WriteString(`
+# [slack]  # prompts as Slack DMs; tokens: CLOCKR_SLACK_BOT_TOKEN / CLOCKR_SLACK_SIGNING_SECRET or the keychain
+# enabled = true
+# user_id = "U0123ABCD"
+# listen = "127.0.0.1:8788"
+`)
+	}
+
+	if sv := cfg.Serve; sv.Listen != "" {
+		fmt.Fprintf(&b, "\n[serve]\nlisten = %q\n", sv.Listen)
+	} else {
+		b.WriteString(`
+# [serve]  # 'clockr serve' HTTP API; token: CLOCKR_SERVE_TOKEN, or generated on first start
+# listen = "127.0.0.1:8787"
+`)
+	}
+
+	if m := cfg.Metrics; m.Enabled || m.Listen != "" {
+		fmt.Fprintf(&b, "\n[metrics]\nenabled = %t\nlisten = %q\n", m.Enabled, m.Listen)
+	} else {
+		b.WriteString(`
+# [metrics]  # Prometheus /metrics from 'clockr start' (and 'clockr serve', behind its token)
+# enabled = true
+# listen = "127.0.0.1:9464"
+`)
+	}
+
+	exp := cfg.Export
+	if exp.Profile != "" || exp.Template != "" || exp.Employee != "" {
+		fmt.Fprintf(&b, "\n[export]\nprofile = %q\ntemplate = %q\nemployee = %q\n", exp.Profile, exp.Template, exp.Employee)
+	} else {
+		b.WriteString(`
+# [export]  # defaults for 'clockr export'
+# profile = "datev"  # csv (default), datev, quickbooks or template
+# template = ""  # text/template file for profile = "template"
+# employee = ""  # your name, for datev and quickbooks
+`)
+	}
+
+	t := cfg.Timeouts
+	if t != (config.TimeoutsConfig{}) {
+		fmt.Fprintf(&b, "\n[timeouts]\nclockify_seconds = %d\ncontext_seconds = %d\nai_seconds = %d\n", t.ClockifySeconds, t.ContextSeconds, t.AISeconds)
+		for _, v := range []struct {
+			key     string
+			seconds int
+		}{{"harvest_seconds", t.HarvestSeconds}, {"toggl_seconds", t.TogglSeconds}, {"tempo_seconds", t.TempoSeconds}} {
+			if v.seconds > 0 {
+				fmt.Fprintf(&b, "%s = %d\n", v.key, v.seconds)
+			}
+		}
+	} else {
+		b.WriteString(`
+# [timeouts]  # seconds; 0 keeps the default, --timeout overrides all
+# clockify_seconds = 30
+# context_seconds = 15
+# ai_seconds = 120
+# harvest_seconds = 30  # also toggl_seconds and tempo_seconds
+`)
+	}
+
+	if len(cfg.Recurring) == 0 {
+		b.WriteString(`
+# [[recurring]]  # logged by the scheduler once it has ended, without a prompt
+# name = "standup"
+# project = "Meetings"  # project ID or name
+# description = "Daily standup"
+# time = "09:15"
+# minutes = 15
+# days = ["monday", "tuesday", "wednesday", "thursday", "friday"]  # default: work days
+# suggest = false  # true: only mention it to the AI in the prompt covering it
+`)
+	}
+	for _, r := range cfg.Recurring {
+		fmt.Fprintf(&b, "\n[[recurring]]\nname = %q\nproject = %q\ndescription = %q\ntime = %q\nminutes = %d\n", r.Name, r.Project, r.Description, r.Time, r.Minutes)
+		if len(r.Days) > 0 {
+			fmt.Fprintf(&b, "days = [%s]\n", quoteList(r.Days))
+		}
+		if r.Suggest {
+			b.WriteString("suggest = true\n")
+		}
+		if r.Disabled {
+			b.WriteString("disabled = true\n")
+		}
+	}
+
+	return b.String()
+}
+
+func runDataExport(cmd *cobra.Command, args []string) error {
+	output, _ := cmd.Flags().GetString("output")
+	if output == "" {
+		output = "clockr-export-" + time.Now().Format("20060102-150405") + ".zip"
+	}
+
+	db, err := openStore()
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer db.Close()
+
+	f, err := os.OpenFile(output, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return fmt.Errorf("creating archive: %w", err)
+	}
+	manifest, err := localdata.Export(f, db, version)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(output)
+		return err
+	}
+
+	fmt.Printf("Exported %d files to %s:\n", len(manifest.Files), output)
+	for _, mf := range manifest.Files {
+		fmt.Printf("  %-24s %s\n", mf.Name, mf.Description)
+	}
+	return nil
+}
+
+func runDataWipe(cmd *cobra.Command, args []string) error {
+	if readOnly {
+		return fmt.Errorf("cannot wipe data in read-only mode")
+	}
+	if pid, err := scheduler.ReadPID(); err == nil {
+		if p, err := os.FindProcess(pid); err == nil && p.Signal(syscall.Signal(0)) == nil {
+			return fmt.Errorf("scheduler is running (PID %d) — run 'clockr stop' first", pid)
+		}
+	}
+
+	keepConfig, _ := cmd.Flags().GetBool("keep-config")
+	yes, _ := cmd.Flags().GetBool("yes")
+	if !yes {
+		what := "all local clockr data, including config.toml"
+		if keepConfig {
+			what = "all local clockr data except config.toml"
+		}
+		ok, err := askYesNo(bufio.NewReader(os.Stdin), "Permanently delete "+what+"?", false)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Println("Cancelled.")
+			return nil
+		}
+	}
+
+	removed, err := localdata.Wipe(keepConfig)
+	for _, path := range removed {
+		fmt.Printf("  Removed %s\n", path)
+	}
+	if err != nil {
+		return err
+	}
+	if len(removed) == 0 {
+		fmt.Println("Nothing to remove.")
+	}
+	return nil
+}
+
+func runSecretsStatus(cmd *cobra.Command, args []string) error {
+	kc := secrets.Default()
+	if kc == nil {
+		fmt.Println("No OS keychain available — credentials are read from config.toml and msgraph_tokens.json.")
+		return nil
+	}
+	fmt.Printf("Keychain: %s\n\n", kc.Name())
+	for _, name := range secrets.Names {
+		_, err := kc.Get(name)
+		switch {
+		case err == nil:
+			fmt.Printf("  %-24s in keychain\n", name)
+		case errors.Is(err, secrets.ErrNotFound):
+			fmt.Printf("  %-24s not in keychain\n", name)
+		default:
+			fmt.Printf("  %-24s error: %v\n", name, err)
+		}
+	}
+	return nil
+}
+
+func runSecretsMigrate(cmd *cobra.Command, args []string) error {
+	if readOnly {
+		return fmt.Errorf("cannot migrate secrets in read-only mode")
+	}
+	moved, err := config.MigrateSecrets()
+	for _, name := range moved {
+		fmt.Printf("  Moved %s from config.toml to the keychain\n", name)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Re-saving Graph tokens moves the refresh token out of the token file.
+	profiles, err := msgraph.Profiles()
+	if err != nil {
+		return fmt.Errorf("listing Graph sign-ins: %w", err)
+	}
+	for _, profile := range profiles {
+		tokens, err := msgraph.LoadTokens(profile)
+		if err != nil {
+			return fmt.Errorf("reading Graph tokens: %w", err)
+		}
+		name := secrets.GraphProfileRefreshToken(profile)
+		if tokens != nil && tokens.RefreshToken != "" {
+			if _, err := secrets.Get(name); err != nil {
+				if err := msgraph.SaveTokens(profile, tokens); err != nil {
+					return fmt.Errorf("saving Graph tokens: %w", err)
+				}
+				fmt.Printf("  Moved %s from %s to the keychain\n", name, msgraph.TokenFile(profile))
+				moved = append(moved, name)
+			}
+		}
+	}
+
+	if len(moved) == 0 {
+		fmt.Println("No plaintext credentials found.")
+	}
+	return nil
+}
+
+func runSecretsSet(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	if !slices.Contains(secrets.Names, name) {
+		return fmt.Errorf("unknown secret %q — expected one of: %s", name, strings.Join(secrets.Names, ", "))
+	}
+	if readOnly {
+		return fmt.Errorf("cannot store secrets in read-only mode")
+	}
+
+	fmt.Fprintf(os.Stderr, "Value for %s: ", name)
+	value, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && value == "" {
+		return fmt.Errorf("reading value: %w", err)
+	}
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return fmt.Errorf("empty value")
+	}
+
+	if err := secrets.Set(name, value); err != nil {
+		return fmt.Errorf("storing %s: %w", name, err)
+	}
+	fmt.Printf("Stored %s in the keychain.\n", name)
+	return nil
+}
+
+// fetchCalendarEvents returns the events in [start, end). With source =
+// "graph" the calendars of every signed-in profile are merged; the events of
+// the profiles that could be fetched are returned along with the errors of
+// the others.
+func fetchCalendarEvents(ctx context.Context, cfg *config.Config, start, end time.Time, logger *slog.Logger) ([]calendar.Event, error) {
+	if cfg.Calendar.Source != "graph" {
+		return calendar.Fetch(ctx, cfg.Calendar.Source, start, end)
+	}
+
+	profiles, err := msgraph.Profiles()
+	if err != nil {
+		return nil, fmt.Errorf("listing Graph sign-ins: %w", err)
+	}
+	if len(profiles) == 0 {
+		profiles = []string{""} // reports that 'clockr calendar auth' is needed
+	}
+	fetched := make([][]calendar.Event, len(profiles))
+	errs := make([]error, len(profiles))
+	var wg sync.WaitGroup
+	for i, profile := range profiles {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fetched[i], errs[i] = fetchGraphEvents(ctx, cfg, profile, start, end, logger)
+			if errs[i] != nil && len(profiles) > 1 && !errors.Is(errs[i], msgraph.ErrReauthRequired) {
+				errs[i] = fmt.Errorf("%s calendar: %w", msgraph.ProfileLabel(profile), errs[i])
+			}
+		}()
+	}
+	wg.Wait()
+
+	var events []calendar.Event
+	for _, e := range fetched {
+		events = append(events, e...)
+	}
+	return calendar.Merge(events), errors.Join(errs...)
+}
+
+// fetchGraphEvents fetches the Outlook calendar of one Graph sign-in.
+func fetchGraphEvents(ctx context.Context, cfg *config.Config, profile string, start, end time.Time, logger *slog.Logger) ([]calendar.Event, error) {
+	clientID, tenantID := cfg.Calendar.Graph.App(profile)
+	if clientID == "" {
+		return nil, fmt.Errorf("calendar.graph.client_id not configured — see 'clockr calendar auth' setup instructions")
+	}
+	if tenantID == "" {
+		return nil, fmt.Errorf("calendar.graph.tenant_id not configured — set it in config or MSGRAPH_TENANT_ID env var")
+	}
+
+	graphClient := msgraph.NewClient(newGraphAuth(cfg, profile, logger), logger)
+	graphClient.SetBaseURL(graphAPIURL)
+	return graphClient.FetchEvents(ctx, start, end)
+}
+
+// graphLoginURL and graphAPIURL replace the Azure AD and Graph endpoints
+// when set; tests point them at fake servers.
+var graphLoginURL, graphAPIURL string
+
+// newGraphAuth returns the Auth of a Graph sign-in ("" for the default).
+func newGraphAuth(cfg *config.Config, profile string, logger *slog.Logger) *msgraph.Auth {
+	clientID, tenantID := cfg.Calendar.Graph.App(profile)
+	auth := msgraph.NewAuth(clientID, tenantID, logger)
+	auth.SetProfile(profile)
+	auth.SetLoginURL(graphLoginURL)
+	return auth
+}
+
+// fetchCalendarReauth is fetchCalendarEvents for commands with a terminal,
+// each fetch limited to the context timeout. When a Graph sign-in is
+// rejected during the fetch and in is not nil, it offers the device code
+// flow for that sign-in and fetches again once one is signed in. pause, when
+// not nil, takes the terminal from a running TUI for the questions and
+// returns the func that gives it back.
+func fetchCalendarReauth(ctx context.Context, cfg *config.Config, in *bufio.Reader, pause func() func(), start, end time.Time, logger *slog.Logger) ([]calendar.Event, error) {
+	fetch := func() ([]calendar.Event, error) {
+		fetchCtx, cancel := context.WithTimeout(ctx, cfg.Timeouts.Context())
+		defer cancel()
+		return fetchCalendarEvents(fetchCtx, cfg, start, end, logger)
+	}
+	events, err := fetch()
+	if in == nil || len(reauthErrors(err)) == 0 {
+		return events, err
+	}
+	if pause != nil {
+		defer pause()()
+	}
+	if !offerCalendarReauth(ctx, cfg, in, err, logger) {
+		return events, err
+	}
+	fmt.Println("Signed in. Fetching the calendar again...")
+	return fetch()
+}
+
+// offerCalendarReauth offers the device code flow for each Graph sign-in
+// err says was rejected, and reports whether any was signed in again.
+func offerCalendarReauth(ctx context.Context, cfg *config.Config, in *bufio.Reader, err error, logger *slog.Logger) bool {
+	signedIn := false
+	for _, re := range reauthErrors(err) {
+		fmt.Printf("⚠ %v\n", re)
+		ok, err := askYesNo(in, "Sign in again now?", true)
+		if err != nil || !ok {
+			continue
+		}
+		if err := graphSignIn(ctx, cfg, re.Profile, logger); err != nil {
+			fmt.Printf("Warning: %v — continuing without this calendar\n", err)
+			continue
+		}
+		signedIn = true
+	}
+	return signedIn
+}
+
+// reauthErrors returns the Graph sign-ins in err, joined or not, that have
+// to sign in again.
+func reauthErrors(err error) []*msgraph.ReauthError {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var out []*msgraph.ReauthError
+		for _, e := range joined.Unwrap() {
+			out = append(out, reauthErrors(e)...)
+		}
+		return out
+	}
+	var re *msgraph.ReauthError
+	if errors.As(err, &re) {
+		return []*msgraph.ReauthError{re}
+	}
+	return nil
+}
+
+// contextWarning reports a context source that could not be fetched; the
+// run goes on with the others.
+func contextWarning(w io.Writer, p sources.Provider, err error) {
+	switch p.(type) {
+	case *sources.Calendar:
+		calendarWarning(w, err)
+	default:
+		fmt.Fprintf(w, "Warning: %v\n", err)
+	}
+}
+
+// reauthBanners makes sure each revoked Graph sign-in is reported once per
+// run.
+var (
+	reauthMu      sync.Mutex
+	reauthBanners = map[string]bool{}
+)
+
+// calendarWarning reports a failed calendar fetch; the run goes on without
+// that calendar's context. A rejected Graph refresh token gets a one-line
+// banner instead of the raw error. Errors of several Graph profiles are
+// reported one by one.
+func calendarWarning(w io.Writer, err error) {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, e := range joined.Unwrap() {
+			calendarWarning(w, e)
+		}
+		return
+	}
+	if errors.Is(err, msgraph.ErrReauthRequired) {
+		reauthMu.Lock()
+		defer reauthMu.Unlock()
+		if !reauthBanners[err.Error()] {
+			reauthBanners[err.Error()] = true
+			fmt.Fprintf(w, "Calendar skipped: %v\n", err)
+		}
+		return
+	}
+	fmt.Fprintf(w, "Warning: calendar fetch failed: %v\n", err)
+}
+
+func runCalendarAuth(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	profile, _ := cmd.Flags().GetString("profile")
+	if !config.ValidGraphProfile(profile) {
+		return fmt.Errorf("invalid --profile %q: use lowercase letters, digits, '-' and '_'", profile)
+	}
+	clientID, tenantID := cfg.Calendar.Graph.App(profile)
+	if clientID == "" {
+		return fmt.Errorf("calendar.graph.client_id not configured — add [calendar.graph] section with client_id to your config")
+	}
+	if tenantID == "" {
+		return fmt.Errorf("calendar.graph.tenant_id not configured — add tenant_id to [calendar.graph] config section")
 	}
-	logger.Debug("day slots built", "count", len(days), "dates", func() string {
-		var dates []string
-		for _, d := range days {
-			dates = append(dates, d.Date)
-		}
-		return strings.Join(dates, ", ")
-	}())
 
-	logger.Debug("fetching projects")
-	projects, err := client.GetProjects(ctx, workspaceID)
-	if err != nil {
-		return fmt.Errorf("fetching projects: %w", err)
+	if err := graphSignIn(context.Background(), cfg, profile, setupLogger(cmd)); err != nil {
+		return err
 	}
-	logger.Debug("projects loaded", "count", len(projects))
-	enrichProjectsWithClients(ctx, client, workspaceID, projects, logger)
 
-	// Fetch calendar events for the full range and attach to day slots (per-day AI context)
-	if cfg.Calendar.Enabled && cfg.Calendar.Source != "" {
-		fmt.Println("Fetching calendar events...")
-		rangeStart := days[0].Start
-		rangeEnd := days[len(days)-1].End
-		logger.Debug("fetching calendar events", "source", cfg.Calendar.Source, "start", rangeStart, "end", rangeEnd)
-		fetchCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
-		events, err := fetchCalendarEvents(fetchCtx, cfg, rangeStart, rangeEnd, logger)
-		cancel()
-		if err != nil {
-			fmt.Printf("Warning: calendar fetch failed: %v\n", err)
-			logger.Debug("calendar fetch error", "error", err)
-		} else {
-			logger.Debug("calendar events fetched", "count", len(events))
-			grouped := calendar.GroupByDay(events)
-			for i, d := range days {
-				if dayEvents, ok := grouped[d.Date]; ok {
-					for _, e := range dayEvents {
-						days[i].Events = append(days[i].Events, e.Summary)
-					}
-				}
-			}
-		}
+	if profile != "" {
+		fmt.Printf("Authentication successful! Tokens saved for profile %q; its events are merged with your other calendars.\n", profile)
+		return nil
 	}
+	fmt.Println("Authentication successful! Tokens saved.")
+	fmt.Println("You can now use source = \"graph\" in your [calendar] config.")
+	return nil
+}
 
-	// Fetch GitHub commits/PRs and attach to day slots (sent to AI via system prompt, not textarea)
-	if useGitHub {
-		rangeStart := days[0].Start
-		rangeEnd := days[len(days)-1].End
-		logger.Debug("fetching GitHub context", "start", rangeStart, "end", rangeEnd)
-		ghItems, err := fetchGitHubContext(ctx, cfg, rangeStart, rangeEnd, logger)
-		if err != nil {
-			fmt.Printf("Warning: GitHub fetch failed: %v\n", err)
-			logger.Debug("GitHub fetch error", "error", err)
-		} else if len(ghItems) > 0 {
-			logger.Debug("GitHub items fetched", "count", len(ghItems))
-			grouped := github.GroupByDay(ghItems)
-			for i, d := range days {
-				if dayItems, ok := grouped[d.Date]; ok {
-					for _, item := range dayItems {
-						days[i].Commits = append(days[i].Commits, item.Message)
-					}
-				}
+// offerGraphReauth checks each Graph sign-in before a prompt and, for one
+// that was revoked, expired or lacks the write permission write_back needs,
+// offers to run the device code flow right away, reading the answers from
+// in. Whatever the answer, the caller goes on; a sign-in left as it is
+// shows up as a calendar warning.
+func offerGraphReauth(ctx context.Context, cfg *config.Config, in *bufio.Reader, logger *slog.Logger) {
+	if !cfg.Calendar.Enabled || cfg.Calendar.Source != "graph" {
+		return
+	}
+	profiles, err := msgraph.Profiles()
+	if err != nil {
+		logger.Debug("listing graph sign-ins", "error", err)
+		return
+	}
+	for _, profile := range profiles {
+		if clientID, tenantID := cfg.Calendar.Graph.App(profile); clientID == "" || tenantID == "" {
+			continue
+		}
+		auth := newGraphAuth(cfg, profile, logger)
+		checkCtx, cancel := context.WithTimeout(ctx, cfg.Timeouts.Context())
+		_, err := auth.EnsureValidToken(checkCtx)
+		cancel()
+
+		var problem string
+		switch {
+		case errors.Is(err, msgraph.ErrReauthRequired):
+			problem = err.Error()
+		case err != nil:
+			// Offline or similar: the fetch reports it, signing in won't help
+			logger.Debug("checking graph sign-in", "profile", profile, "error", err)
+			continue
+		case cfg.Calendar.WriteBack && profile == cfg.Calendar.Graph.WriteProfile:
+			if tokens, _ := msgraph.LoadTokens(profile); tokens != nil && !tokens.CanWrite() {
+				problem = fmt.Sprintf("Microsoft Graph sign-in %q can't create events, which calendar.write_back needs", msgraph.ProfileLabel(profile))
 			}
 		}
-	}
+		if problem == "" {
+			continue
+		}
 
-	var provider ai.Provider
-	if promptFile {
-		var err error
-		provider, err = ai.NewPromptFileProvider(logger)
-		if err != nil {
-			return fmt.Errorf("creating prompt file provider: %w", err)
+		fmt.Printf("⚠ %s\n", problem)
+		ok, err := askYesNo(in, "Sign in again now?", true)
+		if err != nil || !ok {
+			continue
 		}
-	} else {
-		provider = newAIProvider(cfg, logger)
-	}
-	lastInput, _ := db.GetState("last_description")
-	app := tui.NewBatchApp(days, provider, projects, client, workspaceID, db, lastInput)
-	if repeat && lastInput != "" {
-		app.SetInitialInput(lastInput)
+		if err := graphSignIn(ctx, cfg, profile, logger); err != nil {
+			fmt.Printf("Warning: %v — continuing without this calendar\n", err)
+			continue
+		}
+		fmt.Println("Signed in. Continuing...")
+		fmt.Println()
 	}
-	p := tea.NewProgram(app)
+}
 
-	if _, err := p.Run(); err != nil {
-		return fmt.Errorf("running batch TUI: %w", err)
+// graphSignIn runs the device code flow for a Graph profile ("" for the
+// default sign-in) and saves its tokens.
+func graphSignIn(ctx context.Context, cfg *config.Config, profile string, logger *slog.Logger) error {
+	auth := newGraphAuth(cfg, profile, logger)
+	auth.SetWriteAccess(cfg.Calendar.WriteBack && profile == cfg.Calendar.Graph.WriteProfile)
+
+	dcResp, err := auth.StartDeviceCodeFlow(ctx)
+	if err != nil {
+		return fmt.Errorf("starting device code flow: %w", err)
 	}
 
-	result := app.GetResult()
-	if result != nil && result.Skipped {
-		fmt.Println("Batch entry skipped.")
+	fmt.Println()
+	fmt.Println(dcResp.Message)
+	fmt.Println()
+
+	fmt.Println("Waiting for authorization...")
+	tokens, err := auth.PollForToken(ctx, dcResp.DeviceCode, dcResp.Interval)
+	if err != nil {
+		return fmt.Errorf("authorization failed: %w", err)
 	}
 
+	if err := msgraph.SaveTokens(profile, tokens); err != nil {
+		return fmt.Errorf("saving tokens: %w", err)
+	}
 	return nil
 }
 
-func buildDaySlots(cfg *config.Config, from, to time.Time) ([]ai.DaySlot, error) {
-	workStartH, workStartM, err := parseTimeConfig(cfg.Schedule.WorkStart)
-	if err != nil {
-		return nil, fmt.Errorf("parsing work_start: %w", err)
-	}
-	workEndH, workEndM, err := parseTimeConfig(cfg.Schedule.WorkEnd)
+func fetchGitHubContext(ctx context.Context, cfg *config.Config, start, end time.Time, logger *slog.Logger) ([]github.CommitContext, error) {
+	logger.Debug("resolving GitHub token")
+	token, err := github.ResolveToken(cfg.GitHub.Token)
 	if err != nil {
-		return nil, fmt.Errorf("parsing work_end: %w", err)
+		return nil, err
 	}
+	logger.Debug("GitHub token resolved")
 
-	workDays := make(map[int]bool)
-	for _, d := range cfg.Schedule.WorkDays {
-		workDays[d] = true
+	ghClient := github.NewClient(token, logger)
+	if rt := httpCache(logger); rt != nil {
+		ghClient.SetTransport(rt)
 	}
 
-	var days []ai.DaySlot
-	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
-		// Convert Go weekday (Sun=0) to ISO weekday (Mon=1..Sun=7)
-		goWd := int(d.Weekday())
-		isoWd := goWd
-		if goWd == 0 {
-			isoWd = 7
+	repos := cfg.GitHub.Repos
+	if len(repos) == 0 {
+		// Launch repo picker
+		fmt.Println("Fetching your GitHub repos...")
+		fetchCtx, cancel := context.WithTimeout(ctx, cfg.Timeouts.Context())
+		allRepos, err := ghClient.GetRepos(fetchCtx)
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("fetching GitHub repos: %w", err)
 		}
-		if !workDays[isoWd] {
-			continue
+		if len(allRepos) == 0 {
+			return nil, fmt.Errorf("no GitHub repos found for your account")
 		}
 
-		start := time.Date(d.Year(), d.Month(), d.Day(), workStartH, workStartM, 0, 0, d.Location())
-		end := time.Date(d.Year(), d.Month(), d.Day(), workEndH, workEndM, 0, 0, d.Location())
-		minutes := int(end.Sub(start).Minutes())
+		picker := tui.NewRepoPickerApp(allRepos)
+		p := tea.NewProgram(picker)
+		if _, err := p.Run(); err != nil {
+			return nil, fmt.Errorf("running repo picker: %w", err)
+		}
 
-		days = append(days, ai.DaySlot{
-			Date:    d.Format("2006-01-02"),
-			Weekday: d.Weekday().String(),
-			Start:   start,
-			End:     end,
-			Minutes: minutes,
-		})
+		result := picker.GetResult()
+		if result == nil || result.Canceled || len(result.Repos) == 0 {
+			return nil, fmt.Errorf("no repos selected")
+		}
+
+		repos = result.Repos
+		if err := config.SaveGitHubRepos(repos); err != nil {
+			fmt.Printf("Warning: could not save repo selection: %v\n", err)
+		} else {
+			fmt.Printf("Saved %d repos to config.\n", len(repos))
+		}
 	}
 
-	return days, nil
+	fmt.Printf("Fetching GitHub activity from %d repos...\n", len(repos))
+	fetchCtx, cancel := context.WithTimeout(ctx, cfg.Timeouts.Context())
+	defer cancel()
+
+	return github.Fetch(fetchCtx, ghClient, repos, start, end)
 }
 
-func parseTimeConfig(s string) (int, int, error) {
-	parts := strings.SplitN(s, ":", 2)
-	if len(parts) != 2 {
-		return 0, 0, fmt.Errorf("expected HH:MM format, got %q", s)
+func runVerify(cmd *cobra.Command, args []string) error {
+	releaseVersion, _ := cmd.Flags().GetString("release")
+	checksumsPath, _ := cmd.Flags().GetString("checksums")
+	signaturePath, _ := cmd.Flags().GetString("signature")
+
+	if (checksumsPath != "") != (signaturePath != "") {
+		return fmt.Errorf("--checksums and --signature must be provided together")
 	}
-	h, err := strconv.Atoi(parts[0])
-	if err != nil {
-		return 0, 0, fmt.Errorf("invalid hour in %q: %w", s, err)
+
+	var checksums, signature []byte
+	if checksumsPath != "" {
+		var err error
+		if checksums, err = os.ReadFile(checksumsPath); err != nil {
+			return fmt.Errorf("reading checksums file: %w", err)
+		}
+		if signature, err = os.ReadFile(signaturePath); err != nil {
+			return fmt.Errorf("reading signature file: %w", err)
+		}
+	} else {
+		if releaseVersion == "" {
+			releaseVersion = version
+		}
+		if releaseVersion == "dev" {
+			return fmt.Errorf("this is a development build — pass --release VERSION to choose which release to verify against")
+		}
+		fmt.Printf("Fetching signed checksums for v%s...\n", strings.TrimPrefix(releaseVersion, "v"))
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		var err error
+		if checksums, signature, err = release.FetchChecksums(ctx, releaseVersion); err != nil {
+			return err
+		}
 	}
-	m, err := strconv.Atoi(parts[1])
+
+	if err := release.VerifySignature(checksums, signature, signingKey); err != nil {
+		return fmt.Errorf("checksums signature invalid: %w", err)
+	}
+	fmt.Println("Checksums signature: OK")
+
+	digest, err := release.FileSHA256(args[0])
 	if err != nil {
-		return 0, 0, fmt.Errorf("invalid minute in %q: %w", s, err)
+		return err
 	}
-	return h, m, nil
+	name, ok := release.FindChecksum(checksums, digest)
+	if !ok {
+		return fmt.Errorf("%s (sha256 %s) does not match any published artifact", args[0], digest)
+	}
+
+	fmt.Printf("%s: OK (matches %s)\n", args[0], name)
+	return nil
 }
 
-func parseDate(s string) (time.Time, error) {
-	loc := time.Now().Location()
-	if t, err := time.ParseInLocation("2006-01-02", s, loc); err == nil {
-		return t, nil
-	}
-	t, err := naturaldate.Parse(s, time.Now(), naturaldate.WithDirection(naturaldate.Past))
+func runGitHubRepos(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
 	if err != nil {
-		return time.Time{}, fmt.Errorf("cannot parse date %q (use YYYY-MM-DD or natural language like 'monday', 'last friday')", s)
+		return fmt.Errorf("loading config: %w", err)
 	}
-	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc), nil
+
+	if outputJSON {
+		repos := cfg.GitHub.Repos
+		if repos == nil {
+			repos = []string{}
+		}
+		return writeJSON(os.Stdout, repos)
+	}
+
+	if len(cfg.GitHub.Repos) == 0 {
+		fmt.Println("No GitHub repos saved. Run 'clockr log --github' to select repos.")
+		return nil
+	}
+
+	fmt.Printf("Saved repos (%d):\n\n", len(cfg.GitHub.Repos))
+	for _, r := range cfg.GitHub.Repos {
+		fmt.Printf("  %s\n", r)
+	}
+	return nil
 }
 
-func runLogSame(ctx context.Context, cfg *config.Config, client *clockify.Client, workspaceID string, db *store.DB) error {
-	last, err := db.GetLastEntry()
+func runGitHubReposReset(cmd *cobra.Command, args []string) error {
+	if err := config.SaveGitHubRepos([]string{}); err != nil {
+		return fmt.Errorf("clearing saved repos: %w", err)
+	}
+	fmt.Println("GitHub repos cleared. Next --github run will prompt for selection.")
+	return nil
+}
+
+// entryShowJSON is 'clockr entry show' with --output json.
+type entryShowJSON struct {
+	Entry          status.Entry    `json:"entry"`
+	RawInput       string          `json:"raw_input,omitempty"`
+	Suggestion     json.RawMessage `json:"suggestion,omitempty"`
+	Clarifications json.RawMessage `json:"clarifications,omitempty"`
+	PromptVersion  string          `json:"prompt_version,omitempty"`
+	Model          string          `json:"model,omitempty"`
+	SuggestedAt    *time.Time      `json:"suggested_at,omitempty"`
+}
+
+func runEntryShow(cmd *cobra.Command, args []string) error {
+	id, err := strconv.Atoi(args[0])
 	if err != nil {
-		return fmt.Errorf("getting last entry: %w", err)
+		return fmt.Errorf("entry ID must be a number, got %q", args[0])
 	}
-	if last == nil {
-		return fmt.Errorf("no previous entries found")
+	db, err := openStore()
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
 	}
+	defer db.Close()
 
-	// Verify the project still exists in Clockify
-	projects, err := client.GetProjects(ctx, workspaceID)
+	e, err := db.GetEntry(id)
 	if err != nil {
-		return fmt.Errorf("fetching projects: %w", err)
+		return err
 	}
-	found := false
-	for _, p := range projects {
-		if p.ID == last.ProjectID {
-			found = true
-			break
-		}
+	if e == nil {
+		return fmt.Errorf("no entry with ID %d", id)
 	}
-	if !found {
-		return fmt.Errorf("project %q (%s) from last entry no longer exists in Clockify — use 'clockr log' instead", last.ProjectName, last.ProjectID)
+	var saved *store.EntrySuggestion
+	if e.SuggestionID != 0 {
+		if saved, err = db.GetEntrySuggestion(e.SuggestionID); err != nil {
+			return err
+		}
 	}
 
-	now := time.Now()
-	interval := time.Duration(cfg.Schedule.IntervalMinutes) * time.Minute
-	startTime := now.Add(-interval)
-	endTime := now
+	if outputJSON {
+		out := entryShowJSON{Entry: status.NewEntry(*e), RawInput: e.RawInput}
+		if saved != nil {
+			out.RawInput = saved.RawInput
+			out.Suggestion = json.RawMessage(saved.Suggestion)
+			out.Clarifications = json.RawMessage(saved.Clarifications)
+			out.PromptVersion = saved.PromptVersion
+			out.Model = saved.Model
+			out.SuggestedAt = &saved.CreatedAt
+		}
+		return writeJSON(os.Stdout, out)
+	}
 
-	entry := clockify.TimeEntryRequest{
-		Start:       startTime.UTC().Format("2006-01-02T15:04:05Z"),
-		End:         endTime.UTC().Format("2006-01-02T15:04:05Z"),
-		ProjectID:   last.ProjectID,
-		Description: last.Description,
+	project := e.ProjectName
+	if e.ClientName != "" {
+		project += " (" + e.ClientName + ")"
+	}
+	fmt.Printf("Entry %d: %s — %s\n", e.ID, project, e.Description)
+	fmt.Printf("  %s %s–%s (%d min), %s", e.StartTime.Format("2006-01-02"), e.StartTime.Format("15:04"), e.EndTime.Format("15:04"), e.Minutes, e.Status)
+	if e.ClockifyID != "" {
+		fmt.Printf(", Clockify ID %s", e.ClockifyID)
+	}
+	fmt.Println()
+	var meta []string
+	if e.Source != "" {
+		meta = append(meta, "logged via "+e.Source)
+	}
+	if e.Billable {
+		meta = append(meta, "billable")
+	}
+	if e.TaskID != "" {
+		meta = append(meta, "task "+e.TaskID)
+	}
+	if len(e.TagIDs) > 0 {
+		meta = append(meta, "tags "+strings.Join(e.TagIDs, ", "))
+	}
+	if len(meta) > 0 {
+		fmt.Printf("  %s\n", strings.Join(meta, ", "))
 	}
 
-	created, err := client.CreateTimeEntry(ctx, workspaceID, entry)
+	if saved == nil {
+		if e.RawInput != "" {
+			fmt.Printf("\nYou wrote: %s\n", e.RawInput)
+		}
+		fmt.Println("\nNo AI suggestion is stored for this entry. It was logged without the AI, or before clockr kept suggestions.")
+		return nil
+	}
 
-	status := "logged"
-	clockifyID := ""
-	if err != nil {
-		status = "failed"
-		fmt.Printf("Warning: failed to create Clockify entry: %v\n", err)
-	} else {
-		clockifyID = created.ID
+	fmt.Printf("\nYou wrote: %s\n", saved.RawInput)
+	var exchanges []ai.Exchange
+	if json.Unmarshal([]byte(saved.Clarifications), &exchanges) == nil && len(exchanges) > 0 {
+		fmt.Println("\nClarifications:")
+		for _, x := range exchanges {
+			fmt.Printf("  Q: %s\n  A: %s\n", x.Question, x.Answer)
+		}
 	}
 
-	storeEntry := store.Entry{
-		ClockifyID:  clockifyID,
-		ProjectID:   last.ProjectID,
-		ProjectName: last.ProjectName,
-		ClientName:  last.ClientName,
-		Description: last.Description,
-		StartTime:   startTime,
-		EndTime:     endTime,
-		Minutes:     int(interval.Minutes()),
-		Status:      status,
-		RawInput:    "(--same)",
+	// Single and batch suggestions share these fields; batch ones add the
+	// date and times.
+	var suggestion ai.BatchSuggestion
+	if err := json.Unmarshal([]byte(saved.Suggestion), &suggestion); err != nil {
+		return fmt.Errorf("decoding stored suggestion: %w", err)
+	}
+	model := saved.Model
+	if model == "" {
+		model = "unknown model"
+	}
+	fmt.Printf("\nAI suggestion (%s, prompt %s, %s):\n", model, saved.PromptVersion, saved.CreatedAt.Local().Format("2006-01-02 15:04"))
+	match := stats.SuggestedAllocation(*e, suggestion.Allocations)
+	for i, a := range suggestion.Allocations {
+		mark := "  "
+		if i == match {
+			mark = "→ "
+		}
+		when := ""
+		if a.Date != "" {
+			when = fmt.Sprintf("%s %s–%s  ", a.Date, a.StartTime, a.EndTime)
+		}
+		fmt.Printf("  %s%s%s  %d min  confidence %.2f  %s\n", mark, when, a.ProjectName, a.Minutes, a.Confidence, a.Description)
 	}
 
-	if _, err := db.InsertEntry(&storeEntry); err != nil {
-		return fmt.Errorf("saving entry: %w", err)
+	switch {
+	case match < 0:
+		fmt.Println("\nThe project was changed from the AI's suggestion before logging.")
+	case suggestion.Allocations[match].Description != e.Description:
+		fmt.Println("\nThe description was edited before logging.")
 	}
+	return nil
+}
 
-	fmt.Printf("Logged: %s — %s (%dmin) [%s]\n",
-		storeEntry.ProjectName, storeEntry.Description, storeEntry.Minutes, status)
+func runTemplatesAdd(cmd *cobra.Command, args []string) error {
+	name := strings.TrimSpace(args[0])
+	text := strings.TrimSpace(strings.Join(args[1:], " "))
+	if name == "" || text == "" {
+		return fmt.Errorf("a snippet needs a name and some text")
+	}
+	db, err := openStore()
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer db.Close()
 
+	if err := db.SaveSnippet(name, text); err != nil {
+		return err
+	}
+	fmt.Printf("Saved snippet %q. Press Ctrl+T while describing your work to insert it.\n", name)
 	return nil
 }
 
-func runStatus(cmd *cobra.Command, args []string) error {
-	db, err := store.Open()
+func runTemplatesList(cmd *cobra.Command, args []string) error {
+	db, err := openStore()
 	if err != nil {
 		return fmt.Errorf("opening database: %w", err)
 	}
 	defer db.Close()
 
-	entries, err := db.GetTodayEntries()
+	snippets, err := db.Snippets()
 	if err != nil {
-		return fmt.Errorf("fetching today's entries: %w", err)
+		return err
 	}
-
-	if len(entries) == 0 {
-		fmt.Println("No entries logged today.")
+	if len(snippets) == 0 {
+		fmt.Println("No snippets yet. Add one with: clockr templates add NAME TEXT")
 		return nil
 	}
-
-	totalMinutes := 0
-	fmt.Println("Today's entries:")
-	fmt.Println()
-	for _, e := range entries {
-		localStart := e.StartTime.Local()
-		localEnd := e.EndTime.Local()
-		projectDisplay := e.ProjectName
-		if e.ClientName != "" {
-			projectDisplay = e.ClientName + " / " + e.ProjectName
-		}
-		fmt.Printf("  %s–%s  %dmin  %-30s  %s  [%s]\n",
-			localStart.Format("15:04"),
-			localEnd.Format("15:04"),
-			e.Minutes,
-			projectDisplay,
-			e.Description,
-			e.Status,
-		)
-		totalMinutes += e.Minutes
+	width := 0
+	for _, s := range snippets {
+		width = max(width, len(s.Name))
+	}
+	for _, s := range snippets {
+		fmt.Printf("  %-*s  %s\n", width, s.Name, s.Text)
 	}
-
-	hours := totalMinutes / 60
-	mins := totalMinutes % 60
-	fmt.Printf("\nTotal: %dh %dmin (%d entries)\n", hours, mins, len(entries))
-
 	return nil
 }
 
-func runProjects(cmd *cobra.Command, args []string) error {
-	cfg, err := loadConfig()
+func runTemplatesRm(cmd *cobra.Command, args []string) error {
+	db, err := openStore()
 	if err != nil {
-		return err
+		return fmt.Errorf("opening database: %w", err)
 	}
+	defer db.Close()
 
-	logger := setupLogger(cmd)
-	client := newClockifyClient(cfg, logger)
-	ctx := context.Background()
-
-	workspaceID, err := resolveWorkspaceID(ctx, cfg, client)
+	found, err := db.DeleteSnippet(args[0])
 	if err != nil {
 		return err
 	}
+	if !found {
+		return fmt.Errorf("no snippet named %q", args[0])
+	}
+	fmt.Printf("Deleted snippet %q\n", args[0])
+	return nil
+}
 
-	projects, err := client.GetProjects(ctx, workspaceID)
+func runRecurringList(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
 	if err != nil {
-		return fmt.Errorf("fetching projects: %w", err)
+		return fmt.Errorf("loading config: %w", err)
 	}
-	enrichProjectsWithClients(ctx, client, workspaceID, projects, logger)
-
-	if len(projects) == 0 {
-		fmt.Println("No projects found.")
+	if len(cfg.Recurring) == 0 {
+		fmt.Println("No recurring entries. Add one with: clockr recurring add NAME --project P --time HH:MM --minutes N")
 		return nil
 	}
+	db, err := openStore()
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer db.Close()
 
-	fmt.Printf("Found %d projects:\n\n", len(projects))
-	for _, p := range projects {
-		if p.ClientName != "" {
-			fmt.Printf("  %s  %s / %s\n", p.ID, p.ClientName, p.Name)
-		} else {
-			fmt.Printf("  %s  %s\n", p.ID, p.Name)
+	for _, r := range cfg.Recurring {
+		mode := "logged automatically"
+		if r.Suggest {
+			mode = "suggested to the AI"
+		}
+		if r.Disabled {
+			mode = "disabled"
+		}
+		fmt.Printf("%s: %s — %s, %s (%s)\n", r.Name, r.Project, r.Text(), r.When(), mode)
+		if last, _ := db.GetState(scheduler.RecurringStateKey(r.Name)); last != "" && !r.Suggest {
+			fmt.Printf("  last logged %s\n", last)
 		}
 	}
-
 	return nil
 }
 
-func runCalendarTest(cmd *cobra.Command, args []string) error {
+func runRecurringAdd(cmd *cobra.Command, args []string) error {
 	cfg, err := config.Load()
 	if err != nil {
 		return fmt.Errorf("loading config: %w", err)
 	}
-
-	if !cfg.Calendar.Enabled || cfg.Calendar.Source == "" {
-		return fmt.Errorf("calendar not configured — add [calendar] section to config with enabled = true and source = \"...\"")
+	r := config.RecurringEntry{Name: args[0]}
+	r.Project, _ = cmd.Flags().GetString("project")
+	r.Time, _ = cmd.Flags().GetString("time")
+	r.Minutes, _ = cmd.Flags().GetInt("minutes")
+	r.Description, _ = cmd.Flags().GetString("description")
+	r.Days, _ = cmd.Flags().GetStringSlice("days")
+	r.Suggest, _ = cmd.Flags().GetBool("suggest")
+	if problems := config.CheckRecurring(r, cfg.Recurring); len(problems) > 0 {
+		return fmt.Errorf("invalid recurring entry:\n  %s", strings.Join(problems, "\n  "))
 	}
-
-	now := time.Now()
-	windowStart := now.Add(-24 * time.Hour)
-	windowEnd := now.Add(7 * 24 * time.Hour)
-
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
-	defer cancel()
-
-	logger := setupLogger(cmd)
-	events, err := fetchCalendarEvents(ctx, cfg, windowStart, windowEnd, logger)
-	if err != nil {
-		return fmt.Errorf("fetching calendar: %w", err)
+	if err := config.AddRecurring(r); err != nil {
+		return err
 	}
+	fmt.Printf("Added %q: %s — %s, %s. A running scheduler picks it up automatically.\n", r.Name, r.Project, r.Text(), r.When())
+	return nil
+}
 
-	if len(events) == 0 {
-		fmt.Println("No events found in the past 24h to next 7 days.")
-		return nil
+func runRecurringDisable(cmd *cobra.Command, args []string) error {
+	if err := config.SetRecurringDisabled(args[0], true); err != nil {
+		return err
 	}
+	fmt.Printf("Disabled %q.\n", args[0])
+	return nil
+}
 
-	fmt.Printf("Found %d events:\n\n", len(events))
-	for _, e := range events {
-		fmt.Printf("  %s – %s  %s\n",
-			e.StartTime.Local().Format("Mon Jan 02 15:04"),
-			e.EndTime.Local().Format("15:04"),
-			e.Summary,
-		)
+func runRecurringEnable(cmd *cobra.Command, args []string) error {
+	if err := config.SetRecurringDisabled(args[0], false); err != nil {
+		return err
 	}
-
-	fmt.Printf("\nPrefill text: %s\n", calendar.FormatPrefill(events))
+	fmt.Printf("Enabled %q.\n", args[0])
 	return nil
 }
 
-func runConfig(cmd *cobra.Command, args []string) error {
-	if err := config.EnsureConfigDir(); err != nil {
-		return fmt.Errorf("creating config directory: %w", err)
+func runDBMigrate(cmd *cobra.Command, args []string) error {
+	db, err := store.OpenUnmigrated()
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
 	}
+	defer db.Close()
+	db.SetReadOnly(readOnly)
 
-	configPath, err := config.ConfigPath()
+	current, err := db.SchemaVersion()
 	if err != nil {
 		return err
 	}
+	latest := store.LatestSchemaVersion()
 
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		// Create default config file
-		cfg := config.DefaultConfig()
-		data := fmt.Sprintf(`[clockify]
-api_key = "%s"
-workspace_id = "%s"
-# base_url = ""  # set for regional servers (e.g. https://euc1.clockify.me/api/v1)
-
-[schedule]
-interval_minutes = %d
-work_start = "%s"
-work_end = "%s"
-work_days = [1, 2, 3, 4, 5]
-
-[ai]
-provider = "%s"
-model = "%s"
-# api_key = ""  # or set OPENROUTER_API_KEY env var
-# prompt_file = false  # set to true to always use prompt-file mode
-
-[notifications]
-enabled = %t
-snooze_options = [5, 15]
-
-[calendar]
-enabled = %t
-source = "%s"
-# For Microsoft Graph API calendar, set source = "graph" and configure below:
-# [calendar.graph]
-# client_id = ""  # Azure AD Application (client) ID
-# tenant_id = ""  # Azure AD Directory (tenant) ID
-
-[github]
-# token = ""  # optional: uses 'gh auth token' or GITHUB_TOKEN env var by default
-# repos = []  # auto-populated after first --github run via repo picker
-`,
-			cfg.Clockify.APIKey,
-			cfg.Clockify.WorkspaceID,
-			cfg.Schedule.IntervalMinutes,
-			cfg.Schedule.WorkStart,
-			cfg.Schedule.WorkEnd,
-			cfg.AI.Provider,
-			cfg.AI.Model,
-			cfg.Notifications.Enabled,
-			cfg.Calendar.Enabled,
-			cfg.Calendar.Source,
-		)
-		if err := os.WriteFile(configPath, []byte(data), 0644); err != nil {
-			return fmt.Errorf("writing default config: %w", err)
+	if status, _ := cmd.Flags().GetBool("status"); status {
+		statuses, err := db.MigrationStatuses()
+		if err != nil {
+			return err
 		}
+		fmt.Printf("Schema version %d of %d\n\n", current, latest)
+		for _, m := range statuses {
+			state := "pending"
+			if m.Applied {
+				state = "applied " + m.AppliedAt.Local().Format("2006-01-02 15:04")
+			}
+			fmt.Printf("  %3d  %-34s %s\n", m.Version, m.Name, state)
+		}
+		if current > latest {
+			fmt.Printf("\nThe database is at version %d, newer than this clockr knows. Upgrade clockr.\n", current)
+		}
+		return nil
 	}
 
-	editor := os.Getenv("EDITOR")
-	if editor == "" {
-		editor = "vi"
+	if to, _ := cmd.Flags().GetInt("to"); to >= 0 {
+		if to >= current {
+			fmt.Printf("Schema is at version %d; nothing to roll back.\n", current)
+			return nil
+		}
+		if readOnly {
+			return fmt.Errorf("cannot roll back the schema in read-only mode")
+		}
+		if yes, _ := cmd.Flags().GetBool("yes"); !yes {
+			ok, err := askYesNo(bufio.NewReader(os.Stdin), fmt.Sprintf("Roll back from version %d to %d? Tables and columns added since are dropped with their data.", current, to), false)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				fmt.Println("Cancelled.")
+				return nil
+			}
+		}
+		undone, err := db.MigrateTo(to)
+		for _, v := range undone {
+			fmt.Printf("  Rolled back %d\n", v)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Schema is at version %d. Any clockr command of this version migrates it forward again.\n", to)
+		return nil
 	}
 
-	fmt.Printf("Opening %s with %s...\n", configPath, editor)
-
-	proc := os.ProcAttr{
-		Files: []*os.File{os.Stdin, os.Stdout, os.Stderr},
+	if readOnly {
+		return fmt.Errorf("cannot migrate in read-only mode")
+	}
+	applied, err := db.Migrate()
+	for _, v := range applied {
+		fmt.Printf("  Applied %d\n", v)
 	}
-	process, err := os.StartProcess(editor, []string{editor, configPath}, &proc)
 	if err != nil {
-		// If editor fails, just print the path
-		fmt.Printf("Could not open editor. Config file is at: %s\n", configPath)
-		return nil
+		return err
 	}
-	_, err = process.Wait()
-	return err
+	fmt.Printf("Schema is at version %d.\n", max(current, latest))
+	return nil
 }
 
-func fetchCalendarEvents(ctx context.Context, cfg *config.Config, start, end time.Time, logger *slog.Logger) ([]calendar.Event, error) {
-	if cfg.Calendar.Source == "graph" {
-		clientID := cfg.Calendar.Graph.ClientID
-		tenantID := cfg.Calendar.Graph.TenantID
-		if clientID == "" {
-			return nil, fmt.Errorf("calendar.graph.client_id not configured — see 'clockr calendar auth' setup instructions")
-		}
-		if tenantID == "" {
-			return nil, fmt.Errorf("calendar.graph.tenant_id not configured — set it in config or MSGRAPH_TENANT_ID env var")
-		}
-
-		auth := msgraph.NewAuth(clientID, tenantID, logger)
-		graphClient := msgraph.NewClient(auth, logger)
-		return graphClient.FetchEvents(ctx, start, end)
+func runDBVacuum(cmd *cobra.Command, args []string) error {
+	if readOnly {
+		return fmt.Errorf("cannot vacuum in read-only mode")
 	}
-
-	return calendar.Fetch(ctx, cfg.Calendar.Source, start, end)
-}
-
-func runCalendarAuth(cmd *cobra.Command, args []string) error {
-	cfg, err := config.Load()
+	db, err := openStore()
 	if err != nil {
-		return fmt.Errorf("loading config: %w", err)
+		return fmt.Errorf("opening database: %w", err)
 	}
+	defer db.Close()
+	return vacuumStore(db)
+}
 
-	clientID := cfg.Calendar.Graph.ClientID
-	tenantID := cfg.Calendar.Graph.TenantID
-	if clientID == "" {
-		return fmt.Errorf("calendar.graph.client_id not configured — add [calendar.graph] section with client_id to your config")
-	}
-	if tenantID == "" {
-		return fmt.Errorf("calendar.graph.tenant_id not configured — add tenant_id to [calendar.graph] config section")
+// vacuumStore vacuums db and reports how much the file shrank.
+func vacuumStore(db *store.DB) error {
+	before, _ := db.Size()
+	if err := db.Vacuum(); err != nil {
+		return err
 	}
+	after, _ := db.Size()
+	fmt.Printf("Vacuumed %s: %s → %s\n", db.Path(), formatSize(before), formatSize(after))
+	return nil
+}
 
-	logger := setupLogger(cmd)
-	auth := msgraph.NewAuth(clientID, tenantID, logger)
-
-	ctx := context.Background()
-	dcResp, err := auth.StartDeviceCodeFlow(ctx)
-	if err != nil {
-		return fmt.Errorf("starting device code flow: %w", err)
+func runDBBackup(cmd *cobra.Command, args []string) error {
+	path := "clockr-backup-" + time.Now().Format("20060102-150405") + ".db"
+	if len(args) == 1 {
+		path = args[0]
 	}
-
-	fmt.Println()
-	fmt.Println(dcResp.Message)
-	fmt.Println()
-
-	fmt.Println("Waiting for authorization...")
-	tokens, err := auth.PollForToken(ctx, dcResp.DeviceCode, dcResp.Interval)
+	db, err := openStore()
 	if err != nil {
-		return fmt.Errorf("authorization failed: %w", err)
+		return fmt.Errorf("opening database: %w", err)
 	}
+	defer db.Close()
 
-	if err := msgraph.SaveTokens(tokens); err != nil {
-		return fmt.Errorf("saving tokens: %w", err)
+	if err := db.Backup(cmd.Context(), path); err != nil {
+		return err
 	}
-
-	fmt.Println("Authentication successful! Tokens saved.")
-	fmt.Println("You can now use source = \"graph\" in your [calendar] config.")
+	size := int64(0)
+	if info, err := os.Stat(path); err == nil {
+		size = info.Size()
+	}
+	fmt.Printf("Backed up %s to %s (%s)\n", db.Path(), path, formatSize(size))
+	fmt.Printf("To restore, stop clockr and copy it back over %s.\n", db.Path())
 	return nil
 }
 
-func fetchGitHubContext(ctx context.Context, cfg *config.Config, start, end time.Time, logger *slog.Logger) ([]github.CommitContext, error) {
-	logger.Debug("resolving GitHub token")
-	token, err := github.ResolveToken(cfg.GitHub.Token)
+func runDBPrune(cmd *cobra.Command, args []string) error {
+	if readOnly {
+		return fmt.Errorf("cannot prune in read-only mode")
+	}
+	age, _ := cmd.Flags().GetString("older-than")
+	before, err := parseAge(age, time.Now())
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("invalid --older-than: %w", err)
 	}
-	logger.Debug("GitHub token resolved")
 
-	ghClient := github.NewClient(token, logger)
+	db, err := openStore()
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer db.Close()
 
-	repos := cfg.GitHub.Repos
-	if len(repos) == 0 {
-		// Launch repo picker
-		fmt.Println("Fetching your GitHub repos...")
-		fetchCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
-		allRepos, err := ghClient.GetRepos(fetchCtx)
-		cancel()
+	if yes, _ := cmd.Flags().GetBool("yes"); !yes {
+		ok, err := askYesNo(bufio.NewReader(os.Stdin), fmt.Sprintf("Delete entries and history from before %s? Failed entries are kept. Consider 'clockr db backup' first.", before.Format("2006-01-02")), false)
 		if err != nil {
-			return nil, fmt.Errorf("fetching GitHub repos: %w", err)
-		}
-		if len(allRepos) == 0 {
-			return nil, fmt.Errorf("no GitHub repos found for your account")
+			return err
 		}
-
-		picker := tui.NewRepoPickerApp(allRepos)
-		p := tea.NewProgram(picker)
-		if _, err := p.Run(); err != nil {
-			return nil, fmt.Errorf("running repo picker: %w", err)
-		}
-
-		result := picker.GetResult()
-		if result == nil || result.Canceled || len(result.Repos) == 0 {
-			return nil, fmt.Errorf("no repos selected")
-		}
-
-		repos = result.Repos
-		if err := config.SaveGitHubRepos(repos); err != nil {
-			fmt.Printf("Warning: could not save repo selection: %v\n", err)
-		} else {
-			fmt.Printf("Saved %d repos to config.\n", len(repos))
+		if !ok {
+			fmt.Println("Cancelled.")
+			return nil
 		}
 	}
 
-	fmt.Printf("Fetching GitHub activity from %d repos...\n", len(repos))
-	fetchCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
-	defer cancel()
-
-	return github.Fetch(fetchCtx, ghClient, repos, start, end)
-}
-
-func runGitHubRepos(cmd *cobra.Command, args []string) error {
-	cfg, err := config.Load()
+	counts, err := db.Prune(before)
 	if err != nil {
-		return fmt.Errorf("loading config: %w", err)
+		return err
 	}
-
-	if len(cfg.GitHub.Repos) == 0 {
-		fmt.Println("No GitHub repos saved. Run 'clockr log --github' to select repos.")
+	total := int64(0)
+	for _, c := range counts {
+		if c.Rows > 0 {
+			fmt.Printf("  %-18s %d\n", c.Table, c.Rows)
+		}
+		total += c.Rows
+	}
+	if total == 0 {
+		fmt.Printf("Nothing older than %s to prune.\n", before.Format("2006-01-02"))
 		return nil
 	}
-
-	fmt.Printf("Saved repos (%d):\n\n", len(cfg.GitHub.Repos))
-	for _, r := range cfg.GitHub.Repos {
-		fmt.Printf("  %s\n", r)
+	fmt.Printf("Pruned %d rows from before %s.\n", total, before.Format("2006-01-02"))
+	if vacuum, _ := cmd.Flags().GetBool("vacuum"); vacuum {
+		return vacuumStore(db)
 	}
 	return nil
 }
 
-func runGitHubReposReset(cmd *cobra.Command, args []string) error {
-	if err := config.SaveGitHubRepos([]string{}); err != nil {
-		return fmt.Errorf("clearing saved repos: %w", err)
+// parseAge turns an age like "90d", "8w", "6m" or "1y" into the time that
+// long before now.
+func parseAge(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(strings.ToLower(s))
+	if len(s) < 2 {
+		return time.Time{}, fmt.Errorf("%q is not an age (use e.g. 90d, 8w, 6m or 1y)", s)
 	}
-	fmt.Println("GitHub repos cleared. Next --github run will prompt for selection.")
-	return nil
+	n, err := strconv.Atoi(s[:len(s)-1])
+	if err != nil || n <= 0 {
+		return time.Time{}, fmt.Errorf("%q is not an age (use e.g. 90d, 8w, 6m or 1y)", s)
+	}
+	switch s[len(s)-1] {
+	case 'd':
+		return now.AddDate(0, 0, -n), nil
+	case 'w':
+		return now.AddDate(0, 0, -7*n), nil
+	case 'm':
+		return now.AddDate(0, -n, 0), nil
+	case 'y':
+		return now.AddDate(-n, 0, 0), nil
+	}
+	return time.Time{}, fmt.Errorf("%q is not an age (use e.g. 90d, 8w, 6m or 1y)", s)
+}
+
+// formatSize formats a byte count as KB or MB.
+func formatSize(n int64) string {
+	if n < 1024*1024 {
+		return fmt.Sprintf("%.1f KB", float64(n)/1024)
+	}
+	return fmt.Sprintf("%.1f MB", float64(n)/(1024*1024))
 }
@@ -0,0 +1,330 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/apitest"
+	"github.com/christopherklint97/clockr/internal/audit"
+	"github.com/christopherklint97/clockr/internal/clockify"
+	"github.com/christopherklint97/clockr/internal/config"
+	"github.com/christopherklint97/clockr/internal/msgraph"
+	"github.com/christopherklint97/clockr/internal/store"
+	"github.com/christopherklint97/clockr/internal/tui"
+)
+
+func TestRenderConfig_EnvSecrets(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("CLOCKR_HOME", home)
+	t.Setenv("CLOCKIFY_API_KEY", "clockify-secret")
+	t.Setenv("GITHUB_TOKEN", "github-secret")
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := renderConfig(cfg)
+	for _, secret := range []string{"clockify-secret", "github-secret"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("rendered config contains %q:\n%s", secret, out)
+		}
+	}
+	for _, want := range []string{"# api_key: from CLOCKIFY_API_KEY", "# token: from GITHUB_TOKEN"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("rendered config lacks %q", want)
+		}
+	}
+	if err := config.Validate(filepath.Join(home, "config.toml"), []byte(out)); err != nil {
+		t.Errorf("rendered config is invalid: %v", err)
+	}
+}
+
+// fakeGraphLogin points Graph sign-ins at an Azure AD stand-in whose device
+// code flow succeeds at once, and returns the scopes asked for.
+func fakeGraphLogin(t *testing.T) *[]string {
+	t.Helper()
+	t.Setenv("CLOCKR_HOME", t.TempDir())
+	t.Setenv("CLOCKR_NO_KEYCHAIN", "1")
+
+	var scopes []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /tenant/oauth2/v2.0/devicecode", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		scopes = append(scopes, r.Form.Get("scope"))
+		w.Write([]byte(`{"device_code":"dev-1","user_code":"ABCD","interval":1,"message":"enter ABCD"}`))
+	})
+	mux.HandleFunc("POST /tenant/oauth2/v2.0/token", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"access_token":"signed-in","refresh_token":"refresh","expires_in":3600,"scope":%q}`, scopes[len(scopes)-1])
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	graphLoginURL = srv.URL
+	t.Cleanup(func() { graphLoginURL = "" })
+	return &scopes
+}
+
+func graphConfig() *config.Config {
+	cfg := &config.Config{}
+	cfg.Calendar.Enabled = true
+	cfg.Calendar.Source = "graph"
+	cfg.Calendar.Graph.ClientID = "client"
+	cfg.Calendar.Graph.TenantID = "tenant"
+	return cfg
+}
+
+func TestGraphSignIn(t *testing.T) {
+	scopes := fakeGraphLogin(t)
+	cfg := graphConfig()
+	cfg.Calendar.WriteBack = true
+	cfg.Calendar.Graph.WriteProfile = "work"
+
+	if err := graphSignIn(context.Background(), cfg, "work", slog.New(slog.DiscardHandler)); err != nil {
+		t.Fatal(err)
+	}
+	tokens, err := msgraph.LoadTokens("work")
+	if err != nil || tokens == nil {
+		t.Fatalf("LoadTokens = %v, %v", tokens, err)
+	}
+	if tokens.AccessToken != "signed-in" || !tokens.CanWrite() {
+		t.Errorf("tokens = %+v, want a write token", tokens)
+	}
+	if got := *scopes; len(got) != 1 || !strings.Contains(got[0], "Calendars.ReadWrite") {
+		t.Errorf("scopes = %q, want Calendars.ReadWrite for the write profile", got)
+	}
+}
+
+func TestOfferGraphReauth(t *testing.T) {
+	for _, answer := range []string{"y", "n"} {
+		t.Run(answer, func(t *testing.T) {
+			fakeGraphLogin(t)
+			err := msgraph.SaveTokens("", &msgraph.TokenData{
+				AccessToken:  "old",
+				RefreshToken: "refresh",
+				ExpiresAt:    time.Now().Add(time.Hour),
+				Scope:        "Calendars.Read offline_access",
+				NeedsReauth:  true,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			offerGraphReauth(context.Background(), graphConfig(), bufio.NewReader(strings.NewReader(answer+"\n")), slog.New(slog.DiscardHandler))
+
+			tokens, err := msgraph.LoadTokens("")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if signedIn := !tokens.NeedsReauth; signedIn != (answer == "y") {
+				t.Errorf("answer %q: tokens = %+v", answer, tokens)
+			}
+		})
+	}
+}
+
+func TestOfferCalendarReauth(t *testing.T) {
+	fakeGraphLogin(t)
+	cfg := graphConfig()
+	err := errors.Join(
+		fmt.Errorf("profile work: %w", &msgraph.ReauthError{Profile: "work"}),
+		errors.New("profile home: connection refused"),
+		fmt.Errorf("profile school: %w", &msgraph.ReauthError{Profile: "school"}),
+	)
+
+	// Yes for work, no for school.
+	in := bufio.NewReader(strings.NewReader("y\nn\n"))
+	if !offerCalendarReauth(context.Background(), cfg, in, err, slog.New(slog.DiscardHandler)) {
+		t.Error("offerCalendarReauth = false, want true")
+	}
+	if tokens, _ := msgraph.LoadTokens("work"); tokens == nil || tokens.AccessToken != "signed-in" {
+		t.Errorf("work tokens = %+v, want signed in", tokens)
+	}
+	if tokens, _ := msgraph.LoadTokens("school"); tokens != nil {
+		t.Errorf("school tokens = %+v, want none", tokens)
+	}
+
+	if offerCalendarReauth(context.Background(), cfg, bufio.NewReader(strings.NewReader("")), errors.New("offline"), slog.New(slog.DiscardHandler)) {
+		t.Error("offerCalendarReauth without a rejected sign-in = true")
+	}
+}
+
+func TestFetchCalendarEvents_PartialFailure(t *testing.T) {
+	t.Setenv("CLOCKR_HOME", t.TempDir())
+	t.Setenv("CLOCKR_NO_KEYCHAIN", "1")
+
+	event := func(subject, start, end string) string {
+		return fmt.Sprintf(`{"subject":%q,"start":{"dateTime":"2026-03-02T%s:00.0000000","timeZone":"UTC"},"end":{"dateTime":"2026-03-02T%s:00.0000000","timeZone":"UTC"}}`, subject, start, end)
+	}
+	calendars := map[string]string{
+		"Bearer tok-default": `{"value":[` + event("Standup", "09:00", "09:15") + `,` + event("Review", "14:00", "15:00") + `]}`,
+		// The standup is on the work calendar too.
+		"Bearer tok-work": `{"value":[` + event("Standup", "09:00", "09:15") + `,` + event("Planning", "10:00", "11:00") + `]}`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := calendars[r.Header.Get("Authorization")]
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":{"code":"BadRequest"}}`))
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	graphAPIURL = srv.URL
+	t.Cleanup(func() { graphAPIURL = "" })
+
+	for profile, tokens := range map[string]*msgraph.TokenData{
+		"":       {AccessToken: "tok-default"},
+		"work":   {AccessToken: "tok-work"},
+		"broken": {AccessToken: "tok-broken"},
+		"home":   {AccessToken: "tok-home", NeedsReauth: true},
+	} {
+		tokens.Scope = "Calendars.Read offline_access"
+		tokens.ExpiresAt = time.Now().Add(time.Hour)
+		if err := msgraph.SaveTokens(profile, tokens); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	start := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	events, err := fetchCalendarEvents(context.Background(), graphConfig(), start, start.Add(24*time.Hour), slog.New(slog.DiscardHandler))
+
+	var got []string
+	for _, e := range events {
+		got = append(got, e.Summary)
+	}
+	if want := "Standup Planning Review"; strings.Join(got, " ") != want {
+		t.Errorf("events = %q, want %s", got, want)
+	}
+	if err == nil || !strings.Contains(err.Error(), "broken calendar:") {
+		t.Errorf("err = %v, want the broken calendar's error", err)
+	}
+	if re := reauthErrors(err); len(re) != 1 || re[0].Profile != "home" {
+		t.Errorf("reauthErrors = %v, want the home sign-in", re)
+	}
+}
+
+// divergedEntry stores a logged entry and returns it with a Clockify copy
+// edited in the web app: another project and description, and a later end.
+func divergedEntry(t *testing.T, db *store.DB) audit.Discrepancy {
+	t.Helper()
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	local := store.Entry{
+		ClockifyID:  "te1",
+		ProjectID:   "p1",
+		ProjectName: "Alpha",
+		Description: "Local text",
+		StartTime:   start,
+		EndTime:     start.Add(time.Hour),
+		Minutes:     60,
+		Status:      "logged",
+	}
+	if _, err := db.InsertEntry(&local); err != nil {
+		t.Fatal(err)
+	}
+	remote := &clockify.TimeEntry{ID: "te1", ProjectID: "p2", Description: "Remote text"}
+	remote.TimeInterval.Start = start
+	remote.TimeInterval.End = start.Add(75 * time.Minute)
+	return audit.Discrepancy{Kind: audit.Diverged, Local: &local, Remote: remote}
+}
+
+func TestApplyConflict(t *testing.T) {
+	t.Setenv("CLOCKR_HOME", t.TempDir())
+	db, err := store.Open(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	srv := apitest.New(t)
+	srv.Reply("PUT /workspaces/ws1/time-entries/te1", nil, `{"id":"te1"}`)
+	client := clockify.NewClient("key", srv.URL, time.Hour, nil)
+	projects := map[string]clockify.Project{"p2": {ID: "p2", Name: "Beta", ClientName: "Acme"}}
+
+	d := divergedEntry(t, db)
+	fields := conflictFields(d, projects)
+	if len(fields) != 3 || fields[0].Name != "project" || fields[0].Remote != "Beta" || fields[1].Name != "description" || fields[2].Name != "end" {
+		t.Fatalf("conflict fields = %+v", fields)
+	}
+	choices := []tui.ConflictChoice{
+		{Field: "project", Chosen: tui.ChooseRemote, Value: "Beta"},
+		{Field: "description", Chosen: tui.ChooseMerged, Value: "Merged text"},
+		{Field: "end", Chosen: tui.ChooseLocal, Value: fields[2].Local},
+	}
+	if err := applyConflict(context.Background(), client, db, "ws1", d, projects, choices); err != nil {
+		t.Fatal(err)
+	}
+
+	written := srv.Written()
+	if len(written) != 1 {
+		t.Fatalf("Clockify writes = %v, want one update", written)
+	}
+	for field, want := range map[string]string{"projectId": "p2", "description": "Merged text", "start": "2026-03-02T09:00:00Z", "end": "2026-03-02T10:00:00Z"} {
+		if got := written[0][field]; got != want {
+			t.Errorf("Clockify %s = %v, want %s", field, got, want)
+		}
+	}
+
+	e, err := db.GetEntry(d.Local.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.ProjectID != "p2" || e.ProjectName != "Beta" || e.ClientName != "Acme" || e.Description != "Merged text" || !e.EndTime.Equal(d.Local.EndTime) {
+		t.Errorf("local entry = %+v", e)
+	}
+
+	rows, err := db.Query("SELECT field, chosen, value FROM audit_log ORDER BY id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var logged []string
+	for rows.Next() {
+		var field, chosen, value string
+		if err := rows.Scan(&field, &chosen, &value); err != nil {
+			t.Fatal(err)
+		}
+		logged = append(logged, field+"="+chosen+":"+value)
+	}
+	if want := []string{"project=remote:Beta", "description=merged:Merged text", "end=local:" + fields[2].Local}; strings.Join(logged, "|") != strings.Join(want, "|") {
+		t.Errorf("audit log = %q, want %q", logged, want)
+	}
+}
+
+func TestApplyConflict_AllRemote(t *testing.T) {
+	t.Setenv("CLOCKR_HOME", t.TempDir())
+	db, err := store.Open(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	srv := apitest.New(t)
+	client := clockify.NewClient("key", srv.URL, time.Hour, nil)
+
+	d := divergedEntry(t, db)
+	var choices []tui.ConflictChoice
+	for _, f := range conflictFields(d, nil) {
+		choices = append(choices, tui.ConflictChoice{Field: f.Name, Chosen: tui.ChooseRemote, Value: f.Remote})
+	}
+	if err := applyConflict(context.Background(), client, db, "ws1", d, nil, choices); err != nil {
+		t.Fatal(err)
+	}
+	if written := srv.Written(); len(written) != 0 {
+		t.Errorf("Clockify writes = %v, want none when keeping its values", written)
+	}
+	e, err := db.GetEntry(d.Local.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.ProjectID != "p2" || e.Description != "Remote text" || !e.EndTime.Equal(d.Remote.TimeInterval.End) {
+		t.Errorf("local entry = %+v, want Clockify's values", e)
+	}
+}
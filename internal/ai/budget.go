@@ -0,0 +1,176 @@
+package ai
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/christopherklint97/clockr/internal/clockify"
+	"github.com/christopherklint97/clockr/internal/config"
+)
+
+// BudgetProvider keeps the prompt small in large workspaces: it sends Next
+// only the projects most likely to be meant, at most MaxProjects of them.
+// Projects named in the description or context come first, then the ones
+// logged to recently. A client allow-list narrows the list before that.
+type BudgetProvider struct {
+	Next        Provider
+	MaxProjects int // 0 keeps every project the allow-list lets through
+	clients     []string
+	recent      map[string]int // project ID → minutes logged recently
+	logger      *slog.Logger
+}
+
+// NewBudgetProvider trims the projects passed to next as cfg says. recent
+// holds the minutes logged per project ID lately and may be nil.
+func NewBudgetProvider(cfg config.AIConfig, recent map[string]int, next Provider, logger *slog.Logger) *BudgetProvider {
+	if logger == nil {
+		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+	return &BudgetProvider{
+		Next:        next,
+		MaxProjects: cfg.MaxProjects,
+		clients:     cfg.Clients,
+		recent:      recent,
+		logger:      logger,
+	}
+}
+
+type allProjectsKey struct{}
+
+// WithAllProjects returns a context under which BudgetProvider sends every
+// project, for when the trimmed list did not hold the right one.
+func WithAllProjects(ctx context.Context) context.Context {
+	return context.WithValue(ctx, allProjectsKey{}, true)
+}
+
+func allProjects(ctx context.Context) bool {
+	v, _ := ctx.Value(allProjectsKey{}).(bool)
+	return v
+}
+
+func (b *BudgetProvider) MatchProjects(ctx context.Context, description string, projects []clockify.Project, interval time.Duration, contextItems []ContextItem, segments []Segment) (*Suggestion, error) {
+	selected := b.selectFor(ctx, projects, description+"\n"+strings.Join(ContextTexts(contextItems), "\n"))
+	s, err := b.Next.MatchProjects(ctx, description, selected, interval, contextItems, segments)
+	if s != nil {
+		s.Hidden = len(projects) - len(selected)
+	}
+	return s, err
+}
+
+func (b *BudgetProvider) MatchProjectsBatch(ctx context.Context, description string, projects []clockify.Project, days []DaySlot) (*BatchSuggestion, error) {
+	text := []string{description}
+	for _, d := range days {
+		for _, items := range [][]ContextItem{d.Events, d.Commits, d.Context} {
+			text = append(text, ContextTexts(items)...)
+		}
+	}
+	selected := b.selectFor(ctx, projects, strings.Join(text, "\n"))
+	s, err := b.Next.MatchProjectsBatch(ctx, description, selected, days)
+	if s != nil {
+		s.Hidden = len(projects) - len(selected)
+	}
+	return s, err
+}
+
+func (b *BudgetProvider) selectFor(ctx context.Context, projects []clockify.Project, text string) []clockify.Project {
+	if allProjects(ctx) {
+		return projects
+	}
+	selected := SelectProjects(projects, text, b.clients, b.recent, b.MaxProjects)
+	if len(selected) < len(projects) {
+		b.logger.Debug("trimmed projects for the prompt", "sent", len(selected), "total", len(projects))
+	}
+	return selected
+}
+
+// SelectProjects returns at most n of projects (all when n is 0) in their
+// original order. When clients is non-empty only those clients' projects
+// are candidates, unless none of them is. Candidates are ranked by how well
+// their name matches text, then by their minutes in recent.
+func SelectProjects(projects []clockify.Project, text string, clients []string, recent map[string]int, n int) []clockify.Project {
+	candidates := projects
+	if len(clients) > 0 {
+		var allowed []clockify.Project
+		for _, p := range projects {
+			if slices.ContainsFunc(clients, func(c string) bool { return strings.EqualFold(c, p.ClientName) }) {
+				allowed = append(allowed, p)
+			}
+		}
+		if len(allowed) > 0 {
+			candidates = allowed
+		}
+	}
+	if n <= 0 || len(candidates) <= n {
+		return candidates
+	}
+
+	words := wordSet(text)
+	type ranked struct {
+		index     int
+		relevance int
+		recent    int
+	}
+	ranks := make([]ranked, len(candidates))
+	for i, p := range candidates {
+		ranks[i] = ranked{index: i, relevance: relevance(words, p), recent: recent[p.ID]}
+	}
+	slices.SortStableFunc(ranks, func(a, b ranked) int {
+		if a.relevance != b.relevance {
+			return b.relevance - a.relevance
+		}
+		return b.recent - a.recent
+	})
+
+	keep := make([]int, n)
+	for i := range keep {
+		keep[i] = ranks[i].index
+	}
+	slices.Sort(keep)
+	out := make([]clockify.Project, n)
+	for i, idx := range keep {
+		out[i] = candidates[idx]
+	}
+	return out
+}
+
+// relevance counts the words of the project and client name that appear in
+// words, allowing for inflections: "deployed" matches "deployment".
+func relevance(words map[string]bool, p clockify.Project) int {
+	score := 0
+	for w := range wordSet(p.Name + " " + p.ClientName) {
+		if len(w) < 3 {
+			continue
+		}
+		for t := range words {
+			if t == w || commonPrefix(t, w) >= min(len(t), len(w), 5) && len(t) >= 4 && len(w) >= 4 {
+				score++
+				break
+			}
+		}
+	}
+	return score
+}
+
+func commonPrefix(a, b string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
+
+// wordSet splits s into lower-case words of letters and digits.
+func wordSet(s string) map[string]bool {
+	words := map[string]bool{}
+	for _, w := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		words[w] = true
+	}
+	return words
+}
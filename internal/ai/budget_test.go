@@ -0,0 +1,92 @@
+package ai
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/clockify"
+	"github.com/christopherklint97/clockr/internal/config"
+)
+
+var budgetTestProjects = []clockify.Project{
+	{ID: "p1", Name: "Website Redesign", ClientName: "Acme"},
+	{ID: "p2", Name: "Internal", ClientName: "Ours"},
+	{ID: "p3", Name: "Mobile App", ClientName: "Globex"},
+	{ID: "p4", Name: "Deployment Pipeline", ClientName: "Acme"},
+	{ID: "p5", Name: "Support", ClientName: "Globex"},
+}
+
+func projectIDs(projects []clockify.Project) []string {
+	ids := make([]string, len(projects))
+	for i, p := range projects {
+		ids[i] = p.ID
+	}
+	return ids
+}
+
+func TestSelectProjects(t *testing.T) {
+	recent := map[string]int{"p5": 300, "p2": 60}
+	tests := []struct {
+		name    string
+		text    string
+		clients []string
+		n       int
+		want    []string
+	}{
+		{"no limit", "anything", nil, 0, []string{"p1", "p2", "p3", "p4", "p5"}},
+		{"relevant then recent, original order", "deployed the redesign", nil, 3, []string{"p1", "p4", "p5"}},
+		{"recent only", "misc", nil, 2, []string{"p2", "p5"}},
+		{"client allow-list", "misc", []string{"acme"}, 0, []string{"p1", "p4"}},
+		{"unknown client keeps all", "misc", []string{"Initech"}, 0, []string{"p1", "p2", "p3", "p4", "p5"}},
+	}
+	for _, tt := range tests {
+		got := projectIDs(SelectProjects(budgetTestProjects, tt.text, tt.clients, recent, tt.n))
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+type recordingProvider struct{ projects []clockify.Project }
+
+func (r *recordingProvider) MatchProjects(_ context.Context, _ string, projects []clockify.Project, _ time.Duration, _ []ContextItem, _ []Segment) (*Suggestion, error) {
+	r.projects = projects
+	return &Suggestion{Clarification: "Which project?"}, nil
+}
+
+func (r *recordingProvider) MatchProjectsBatch(_ context.Context, _ string, projects []clockify.Project, _ []DaySlot) (*BatchSuggestion, error) {
+	r.projects = projects
+	return &BatchSuggestion{}, nil
+}
+
+func TestBudgetProvider_TrimsUnlessAllProjects(t *testing.T) {
+	next := &recordingProvider{}
+	b := NewBudgetProvider(config.AIConfig{MaxProjects: 2}, nil, next, nil)
+
+	s, err := b.MatchProjects(context.Background(), "mobile app crash", budgetTestProjects, time.Hour, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := projectIDs(next.projects); len(got) != 2 || got[1] != "p3" || s.Hidden != 3 {
+		t.Errorf("sent %v with %d hidden, want [p1 p3] and 3 hidden", got, s.Hidden)
+	}
+
+	s, err = b.MatchProjects(WithAllProjects(context.Background()), "mobile app crash", budgetTestProjects, time.Hour, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(next.projects) != len(budgetTestProjects) || s.Hidden != 0 {
+		t.Errorf("with all projects sent %d, %d hidden", len(next.projects), s.Hidden)
+	}
+	if Unwrap(b) != next {
+		t.Error("Unwrap does not see through BudgetProvider")
+	}
+}
@@ -0,0 +1,111 @@
+package ai
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/clockify"
+)
+
+// ResponseCache stores AI answers by key. store.DB implements it.
+type ResponseCache interface {
+	LoadAIResponse(key string, ttl time.Duration) ([]byte, error)
+	SaveAIResponse(key string, data []byte) error
+}
+
+// CachedProvider answers a request it saw within TTL from the cache instead
+// of calling Next, so retrying after a crash or an accidental Ctrl+C
+// doesn't wait on the model again. Only successful answers are kept.
+type CachedProvider struct {
+	Next   Provider
+	TTL    time.Duration
+	cache  ResponseCache
+	logger *slog.Logger
+	prompt PromptOptions
+}
+
+func NewCachedProvider(next Provider, cache ResponseCache, ttl time.Duration, logger *slog.Logger) *CachedProvider {
+	if logger == nil {
+		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+	return &CachedProvider{Next: next, TTL: ttl, cache: cache, logger: logger}
+}
+
+// SetPromptOptions sets the prompt options Next was built with, which
+// answers are cached under.
+func (c *CachedProvider) SetPromptOptions(opts PromptOptions) {
+	c.prompt = opts
+}
+
+type noCacheKey struct{}
+
+// WithoutCache returns a context under which CachedProvider asks the model
+// even for a cached request, for when the user retries on purpose.
+func WithoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noCacheKey{}, true)
+}
+
+func (c *CachedProvider) MatchProjects(ctx context.Context, description string, projects []clockify.Project, interval time.Duration, contextItems []ContextItem, segments []Segment) (*Suggestion, error) {
+	key := cacheKey("match", c.prompt, description, projects, interval, contextItems, segments)
+	var cached Suggestion
+	if c.load(ctx, key, &cached) {
+		return &cached, nil
+	}
+	s, err := c.Next.MatchProjects(ctx, description, projects, interval, contextItems, segments)
+	if err == nil {
+		c.save(key, s)
+	}
+	return s, err
+}
+
+func (c *CachedProvider) MatchProjectsBatch(ctx context.Context, description string, projects []clockify.Project, days []DaySlot) (*BatchSuggestion, error) {
+	key := cacheKey("batch", c.prompt, description, projects, days)
+	var cached BatchSuggestion
+	if c.load(ctx, key, &cached) {
+		return &cached, nil
+	}
+	s, err := c.Next.MatchProjectsBatch(ctx, description, projects, days)
+	if err == nil {
+		c.save(key, s)
+	}
+	return s, err
+}
+
+// cacheKey hashes everything the prompt is built from, including any
+// customised prompt templates and the prompt options.
+func cacheKey(kind string, opts PromptOptions, input ...any) string {
+	data, _ := json.Marshal(append(input, opts))
+	sum := sha256.Sum256(append([]byte(kind+"\n"+promptOverrideHash()+"\n"), data...))
+	return hex.EncodeToString(sum[:])
+}
+
+func (c *CachedProvider) load(ctx context.Context, key string, v any) bool {
+	if skip, _ := ctx.Value(noCacheKey{}).(bool); skip {
+		return false
+	}
+	data, err := c.cache.LoadAIResponse(key, c.TTL)
+	if err != nil {
+		c.logger.Debug("reading AI cache", "error", err)
+		return false
+	}
+	if data == nil || json.Unmarshal(data, v) != nil {
+		return false
+	}
+	c.logger.Debug("using cached AI response", "key", key[:12])
+	return true
+}
+
+func (c *CachedProvider) save(key string, v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	if err := c.cache.SaveAIResponse(key, data); err != nil {
+		c.logger.Debug("saving AI response to cache", "error", err)
+	}
+}
@@ -0,0 +1,55 @@
+package ai
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/clockify"
+)
+
+type memoryCache map[string][]byte
+
+func (m memoryCache) LoadAIResponse(key string, _ time.Duration) ([]byte, error) {
+	return m[key], nil
+}
+
+func (m memoryCache) SaveAIResponse(key string, data []byte) error {
+	m[key] = data
+	return nil
+}
+
+type countingProvider struct{ calls int }
+
+func (c *countingProvider) MatchProjects(_ context.Context, description string, _ []clockify.Project, interval time.Duration, _ []ContextItem, _ []Segment) (*Suggestion, error) {
+	c.calls++
+	return &Suggestion{Allocations: []Allocation{{ProjectID: "p1", Minutes: int(interval.Minutes()), Description: description}}}, nil
+}
+
+func (c *countingProvider) MatchProjectsBatch(context.Context, string, []clockify.Project, []DaySlot) (*BatchSuggestion, error) {
+	c.calls++
+	return &BatchSuggestion{}, nil
+}
+
+func TestCachedProvider(t *testing.T) {
+	next := &countingProvider{}
+	c := NewCachedProvider(next, memoryCache{}, time.Minute, nil)
+	ctx := context.Background()
+	projects := []clockify.Project{{ID: "p1", Name: "Internal"}}
+
+	first, _ := c.MatchProjects(ctx, "email", projects, time.Hour, TextContext(SourceCommit, []string{"api: fix"}), nil)
+	again, _ := c.MatchProjects(ctx, "email", projects, time.Hour, TextContext(SourceCommit, []string{"api: fix"}), nil)
+	if next.calls != 1 {
+		t.Fatalf("model called %d times for the same input, want 1", next.calls)
+	}
+	if again.Allocations[0] != first.Allocations[0] {
+		t.Errorf("cached answer %+v differs from %+v", again.Allocations[0], first.Allocations[0])
+	}
+
+	c.MatchProjects(ctx, "email", projects, 30*time.Minute, TextContext(SourceCommit, []string{"api: fix"}), nil)
+	c.MatchProjects(ctx, "email", projects, time.Hour, TextContext(SourceCommit, []string{"web: fix"}), nil)
+	c.MatchProjects(WithoutCache(ctx), "email", projects, time.Hour, TextContext(SourceCommit, []string{"api: fix"}), nil)
+	if next.calls != 4 {
+		t.Errorf("model called %d times, want 4: another interval, other context and a retry miss the cache", next.calls)
+	}
+}
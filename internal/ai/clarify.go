@@ -0,0 +1,29 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Exchange is one clarification round: the question the model asked and the
+// user's answer.
+type Exchange struct {
+	Question string
+	Answer   string
+}
+
+// WithClarifications appends earlier clarification rounds to description, so
+// every provider gets the conversation so far without a separate message
+// history. The original description is kept as typed.
+func WithClarifications(description string, exchanges []Exchange) string {
+	if len(exchanges) == 0 {
+		return description
+	}
+	var sb strings.Builder
+	sb.WriteString(description)
+	sb.WriteString("\n\nClarifications:")
+	for _, e := range exchanges {
+		fmt.Fprintf(&sb, "\nQ: %s\nA: %s", e.Question, e.Answer)
+	}
+	return sb.String()
+}
@@ -0,0 +1,18 @@
+package ai
+
+import "testing"
+
+func TestWithClarifications(t *testing.T) {
+	if got := WithClarifications("fixed bugs", nil); got != "fixed bugs" {
+		t.Errorf("no exchanges should leave the description alone, got %q", got)
+	}
+
+	got := WithClarifications("fixed bugs", []Exchange{
+		{Question: "Which product?", Answer: "the billing service"},
+		{Question: "Which client?", Answer: "Acme"},
+	})
+	want := "fixed bugs\n\nClarifications:\nQ: Which product?\nA: the billing service\nQ: Which client?\nA: Acme"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
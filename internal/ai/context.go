@@ -0,0 +1,140 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Sources of context items, as labelled in prompts. Plugins and the
+// [context.custom] command label their items with their own name.
+const (
+	SourceCalendar = "calendar"
+	SourceCommit   = "commit"
+	SourcePR       = "pr"
+	// SourceManual is context the user set up by hand, such as suggest-only
+	// [[recurring]] entries.
+	SourceManual = "manual"
+)
+
+// ContextItem is one piece of context about a window, labelled with where
+// it came from and when, so the model can tell a meeting that took half the
+// window from a commit message.
+type ContextItem struct {
+	Source  string    `json:"source,omitempty"` // a Source constant or a plugin's name; "" if unknown
+	Text    string    `json:"text"`
+	Time    time.Time `json:"time,omitzero"`     // when it started or happened; zero if unknown
+	Minutes int       `json:"minutes,omitempty"` // how long it lasted, for calendar events
+}
+
+// In returns the item with its time in loc, the zone its String shows.
+// Context is converted to the schedule's zone, so a UTC calendar event or
+// commit reads in local time like the window it belongs to.
+func (c ContextItem) In(loc *time.Location) ContextItem {
+	if !c.Time.IsZero() && loc != nil {
+		c.Time = c.Time.In(loc)
+	}
+	return c
+}
+
+// String renders the item as it appears in prompts, e.g.
+// "[calendar 09:00–09:30, 30 min] Standup" or "[commit 14:12] api: Fix login".
+// Times are shown in the item's own zone; see In.
+func (c ContextItem) String() string {
+	var label []string
+	if c.Source != "" {
+		label = append(label, c.Source)
+	}
+	if !c.Time.IsZero() {
+		at := c.Time.Format("15:04")
+		if c.Minutes > 0 {
+			at += "–" + c.Time.Add(time.Duration(c.Minutes)*time.Minute).Format("15:04")
+		}
+		label = append(label, at)
+	}
+	prefix := strings.Join(label, " ")
+	if c.Minutes > 0 {
+		if prefix != "" {
+			prefix += ", "
+		}
+		prefix += fmt.Sprintf("%d min", c.Minutes)
+	}
+	if prefix == "" {
+		return c.Text
+	}
+	return "[" + prefix + "] " + c.Text
+}
+
+// UnmarshalJSON also accepts a plain string, the form context was saved in
+// before items were labelled.
+func (c *ContextItem) UnmarshalJSON(data []byte) error {
+	var text string
+	if json.Unmarshal(data, &text) == nil {
+		*c = ContextItem{Text: text}
+		return nil
+	}
+	type item ContextItem
+	return json.Unmarshal(data, (*item)(c))
+}
+
+// TextContext labels plain context lines with source.
+func TextContext(source string, lines []string) []ContextItem {
+	items := make([]ContextItem, len(lines))
+	for i, l := range lines {
+		items[i] = ContextItem{Source: source, Text: l}
+	}
+	return items
+}
+
+// ContextTexts returns the items' text without labels.
+func ContextTexts(items []ContextItem) []string {
+	texts := make([]string, len(items))
+	for i, c := range items {
+		texts[i] = c.Text
+	}
+	return texts
+}
+
+// FormatPrefill summarizes items as a bullet list for the description box:
+// meetings first with their length, then everything else, each text once.
+func FormatPrefill(items []ContextItem) string {
+	var meetings, other []string
+	seen := map[string]bool{}
+	for _, c := range items {
+		if c.Text == "" || seen[c.Text] {
+			continue
+		}
+		seen[c.Text] = true
+		if c.Source == SourceCalendar {
+			line := "- " + c.Text
+			if c.Minutes > 0 {
+				line += fmt.Sprintf(" (%d min)", c.Minutes)
+			}
+			meetings = append(meetings, line)
+		} else {
+			other = append(other, "- "+c.Text)
+		}
+	}
+	return strings.Join(append(meetings, other...), "\n")
+}
+
+// formatContext renders items one "  - " line each.
+func formatContext(items []ContextItem) string {
+	var sb strings.Builder
+	for _, c := range items {
+		sb.WriteString("  - ")
+		sb.WriteString(c.String())
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// joinContext renders items on one line, for the batch schedule.
+func joinContext(items []ContextItem) string {
+	parts := make([]string, len(items))
+	for i, c := range items {
+		parts[i] = c.String()
+	}
+	return "[" + strings.Join(parts, "; ") + "]"
+}
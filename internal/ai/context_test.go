@@ -0,0 +1,51 @@
+package ai
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestContextItem_String(t *testing.T) {
+	tests := []struct {
+		item ContextItem
+		want string
+	}{
+		{ContextItem{Source: SourceCalendar, Text: "Standup", Time: at("09:00"), Minutes: 30}, "[calendar 09:00–09:30, 30 min] Standup"},
+		{ContextItem{Source: SourcePR, Text: "api: PR #12 Add login", Time: at("14:05")}, "[pr 14:05] api: PR #12 Add login"},
+		{ContextItem{Source: "tracker", Text: "TICKET-1 reviewed"}, "[tracker] TICKET-1 reviewed"},
+		{ContextItem{Text: "Planning", Minutes: 45}, "[45 min] Planning"},
+		{ContextItem{Text: "plain"}, "plain"},
+	}
+	for _, tt := range tests {
+		if got := tt.item.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestContextItem_UnmarshalLegacyStrings(t *testing.T) {
+	var items []ContextItem
+	data := `["Standup", {"source": "commit", "text": "api: fix", "time": "2026-03-02T10:00:00Z"}]`
+	if err := json.Unmarshal([]byte(data), &items); err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[0] != (ContextItem{Text: "Standup"}) || items[1].Source != SourceCommit || items[1].Time.Hour() != 10 {
+		t.Errorf("items = %+v", items)
+	}
+}
+
+func TestFormatPrefill(t *testing.T) {
+	items := []ContextItem{
+		{Source: SourceCommit, Text: "api: Fix login", Time: at("10:12")},
+		{Source: SourceCalendar, Text: "Standup", Time: at("09:00"), Minutes: 15},
+		{Source: SourcePR, Text: "api: Fix login"},
+		{Source: "jira", Text: "ABC-1 In review"},
+	}
+	want := "- Standup (15 min)\n- api: Fix login\n- ABC-1 In review"
+	if got := FormatPrefill(items); got != want {
+		t.Errorf("FormatPrefill = %q, want %q", got, want)
+	}
+	if got := FormatPrefill(nil); got != "" {
+		t.Errorf("FormatPrefill(nil) = %q, want empty", got)
+	}
+}
@@ -1,10 +1,15 @@
 package ai
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 type Suggestion struct {
 	Allocations   []Allocation `json:"allocations" jsonschema:"required"`
 	Clarification string       `json:"clarification,omitempty"`
+	Hidden        int          `json:"-"` // projects left out of the prompt by BudgetProvider
+	Problems      []string     `json:"-"` // validation problems ValidatingProvider could not get fixed
 }
 
 type Allocation struct {
@@ -13,18 +18,34 @@ type Allocation struct {
 	ClientName  string  `json:"client_name,omitempty"`
 	Minutes     int     `json:"minutes" jsonschema:"required"`
 	Description string  `json:"description" jsonschema:"required"`
+	IssueKey    string  `json:"issue_key,omitempty"` // Jira issue, when asked for; see PromptOptions
 	Confidence  float64 `json:"confidence" jsonschema:"required"`
 }
 
 // DaySlot represents one work day in a batch time entry request.
 type DaySlot struct {
-	Date    string    // "YYYY-MM-DD"
-	Weekday string    // "Monday", "Tuesday", etc.
-	Start   time.Time // work start for this day
-	End     time.Time // work end for this day
-	Minutes int       // total work minutes this day
-	Events  []string  // calendar event summaries
-	Commits []string  // git commit/PR context messages
+	Date    string        // "YYYY-MM-DD"
+	Weekday string        // "Monday", "Tuesday", etc.
+	Start   time.Time     // work start for this day
+	End     time.Time     // work end for this day
+	Minutes int           // total work minutes this day
+	Blocks  []Segment     // work blocks when the day has breaks (e.g. lunch); nil for one block
+	Events  []ContextItem // calendar events, with their times and minutes
+	Commits []ContextItem // git commits and PRs
+	Context []ContextItem // other context, e.g. from plugins
+}
+
+// Hours formats the day's work hours, listing each block when there are
+// breaks.
+func (d DaySlot) Hours() string {
+	if len(d.Blocks) == 0 {
+		return d.Start.Format("15:04") + "–" + d.End.Format("15:04")
+	}
+	parts := make([]string, len(d.Blocks))
+	for i, b := range d.Blocks {
+		parts[i] = b.Start.Format("15:04") + "–" + b.End.Format("15:04")
+	}
+	return strings.Join(parts, ", ")
 }
 
 // BatchAllocation is like Allocation but tagged with date and time range.
@@ -37,6 +58,7 @@ type BatchAllocation struct {
 	ClientName  string  `json:"client_name,omitempty"`
 	Minutes     int     `json:"minutes" jsonschema:"required"`
 	Description string  `json:"description" jsonschema:"required"`
+	IssueKey    string  `json:"issue_key,omitempty"` // Jira issue, when asked for; see PromptOptions
 	Confidence  float64 `json:"confidence" jsonschema:"required"`
 }
 
@@ -44,4 +66,18 @@ type BatchAllocation struct {
 type BatchSuggestion struct {
 	Allocations   []BatchAllocation `json:"allocations" jsonschema:"required"`
 	Clarification string            `json:"clarification,omitempty"`
+	Hidden        int               `json:"-"` // projects left out of the prompt by BudgetProvider
+	Problems      []string          `json:"-"` // validation problems ValidatingProvider could not get fixed
+}
+
+// Standup is a three-part daily standup draft.
+type Standup struct {
+	Yesterday string `json:"yesterday" jsonschema:"required"`
+	Today     string `json:"today" jsonschema:"required"`
+	Blockers  string `json:"blockers" jsonschema:"required"`
+}
+
+// String renders the standup as three bullets.
+func (s *Standup) String() string {
+	return "- Yesterday: " + s.Yesterday + "\n- Today: " + s.Today + "\n- Blockers: " + s.Blockers + "\n"
 }
@@ -6,11 +6,13 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"net/http"
 	"os"
 	"strings"
 	"time"
 
 	"github.com/christopherklint97/clockr/internal/clockify"
+	"github.com/christopherklint97/clockr/internal/metrics"
 	"github.com/invopop/jsonschema"
 	"github.com/openai/openai-go/v3"
 	"github.com/openai/openai-go/v3/option"
@@ -21,6 +23,7 @@ import (
 var (
 	suggestionSchema      map[string]any
 	batchSuggestionSchema map[string]any
+	standupSchema         map[string]any
 )
 
 func init() {
@@ -31,6 +34,7 @@ func init() {
 
 	suggestionSchema = schemaToMap(r.Reflect(&Suggestion{}))
 	batchSuggestionSchema = schemaToMap(r.Reflect(&BatchSuggestion{}))
+	standupSchema = schemaToMap(r.Reflect(&Standup{}))
 }
 
 func schemaToMap(s *jsonschema.Schema) map[string]any {
@@ -51,6 +55,11 @@ type OpenRouterProvider struct {
 	logger     *slog.Logger
 	client     openai.Client
 	OnThinking func(text string) // optional: called with streaming text chunks
+	effort     string
+	thinking   *bool
+	extra      map[string]any
+	prompt     PromptOptions
+	keyErr     error // set when no API key was found, returned before any call
 }
 
 func NewOpenRouter(apiKey, model string, logger *slog.Logger) *OpenRouterProvider {
@@ -62,7 +71,7 @@ func NewOpenRouter(apiKey, model string, logger *slog.Logger) *OpenRouterProvide
 	}
 
 	var opts []option.RequestOption
-	opts = append(opts, option.WithBaseURL("https://openrouter.ai/api/v1"))
+	opts = append(opts, option.WithBaseURL(openRouterBaseURL))
 	if apiKey != "" {
 		opts = append(opts, option.WithAPIKey(apiKey))
 	} else if key := os.Getenv("OPENROUTER_API_KEY"); key != "" {
@@ -73,11 +82,78 @@ func NewOpenRouter(apiKey, model string, logger *slog.Logger) *OpenRouterProvide
 		Model:  model,
 		logger: logger,
 		client: openai.NewClient(opts...),
+		keyErr: VerifyOpenRouterAPIKey(apiKey),
 	}
 }
 
-func (o *OpenRouterProvider) MatchProjects(ctx context.Context, description string, projects []clockify.Project, interval time.Duration, contextItems []string) (*Suggestion, error) {
-	systemPrompt := buildSystemPrompt(projects, interval, contextItems)
+const openRouterBaseURL = "https://openrouter.ai/api/v1"
+
+// CheckOpenRouterKey asks OpenRouter whether apiKey (or OPENROUTER_API_KEY
+// when empty) is valid, without spending any credits.
+func CheckOpenRouterKey(ctx context.Context, apiKey string) error {
+	if err := VerifyOpenRouterAPIKey(apiKey); err != nil {
+		return err
+	}
+	if apiKey == "" {
+		apiKey = os.Getenv("OPENROUTER_API_KEY")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, openRouterBaseURL+"/key", nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("reaching OpenRouter: %w", err)
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return fmt.Errorf("OpenRouter rejected the API key (HTTP %d) — check api_key under [ai] or OPENROUTER_API_KEY", resp.StatusCode)
+	case resp.StatusCode >= 400:
+		return fmt.Errorf("OpenRouter key check failed: HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// SetReasoning sets the reasoning effort ("" leaves it to the model) and
+// turns reasoning on or off (nil leaves it to the model).
+func (o *OpenRouterProvider) SetReasoning(effort string, thinking *bool) {
+	o.effort = effort
+	o.thinking = thinking
+}
+
+// SetExtraParams adds params to every request body. Keys may be dotted
+// paths such as "provider.order".
+func (o *OpenRouterProvider) SetExtraParams(params map[string]any) {
+	o.extra = params
+}
+
+// SetPromptOptions sets what every match and batch prompt is built with.
+func (o *OpenRouterProvider) SetPromptOptions(opts PromptOptions) {
+	o.prompt = opts
+}
+
+// requestOptions are the body fields sent on top of the chat parameters.
+func (o *OpenRouterProvider) requestOptions() []option.RequestOption {
+	opts := []option.RequestOption{option.WithJSONSet("provider.zdr", true)}
+	if o.effort != "" {
+		opts = append(opts, option.WithJSONSet("reasoning.effort", o.effort))
+	}
+	if o.thinking != nil {
+		opts = append(opts, option.WithJSONSet("reasoning.enabled", *o.thinking))
+	}
+	for k, v := range o.extra {
+		opts = append(opts, option.WithJSONSet(k, v))
+	}
+	return opts
+}
+
+func (o *OpenRouterProvider) MatchProjects(ctx context.Context, description string, projects []clockify.Project, interval time.Duration, contextItems []ContextItem, segments []Segment) (*Suggestion, error) {
+	systemPrompt, err := buildSystemPrompt(o.prompt, projects, interval, contextItems, segments)
+	if err != nil {
+		return nil, err
+	}
 	userPrompt := buildUserPrompt(description)
 
 	o.logger.Debug("invoking OpenRouter API",
@@ -112,7 +188,10 @@ func (o *OpenRouterProvider) MatchProjects(ctx context.Context, description stri
 }
 
 func (o *OpenRouterProvider) MatchProjectsBatch(ctx context.Context, description string, projects []clockify.Project, days []DaySlot) (*BatchSuggestion, error) {
-	systemPrompt := buildBatchSystemPrompt(projects, days)
+	systemPrompt, err := buildBatchSystemPrompt(o.prompt, projects, days)
+	if err != nil {
+		return nil, err
+	}
 	userPrompt := buildBatchUserPrompt(description)
 
 	o.logger.Debug("invoking OpenRouter API (batch)",
@@ -146,9 +225,38 @@ func (o *OpenRouterProvider) MatchProjectsBatch(ctx context.Context, description
 	return &suggestion, nil
 }
 
+func (o *OpenRouterProvider) WriteStandup(ctx context.Context, previousDay string, entries, events []string) (*Standup, error) {
+	systemPrompt, err := buildStandupSystemPrompt()
+	if err != nil {
+		return nil, err
+	}
+	userPrompt := buildStandupUserPrompt(previousDay, entries, events)
+
+	o.logger.Debug("invoking OpenRouter API (standup)",
+		"model", o.Model,
+		"entries", len(entries),
+		"events", len(events),
+	)
+
+	result, err := o.call(ctx, systemPrompt, userPrompt, standupSchema, "standup")
+	if err != nil {
+		return nil, err
+	}
+
+	var standup Standup
+	if err := json.Unmarshal([]byte(result), &standup); err != nil {
+		o.logger.Error("failed to parse standup", "error", err, "raw", truncateStr(result, 2000))
+		return nil, fmt.Errorf("parsing standup: %w (raw: %s)", err, truncateStr(result, 1000))
+	}
+	return &standup, nil
+}
+
 // call sends a chat completion request to OpenRouter and returns the text response.
 // Uses streaming when OnThinking is set, buffered otherwise.
 func (o *OpenRouterProvider) call(ctx context.Context, systemPrompt, userPrompt string, schema map[string]any, schemaName string) (string, error) {
+	if o.keyErr != nil {
+		return "", fmt.Errorf("%w (run 'clockr doctor' to check the setup)", o.keyErr)
+	}
 	params := openai.ChatCompletionNewParams{
 		Model: o.Model,
 		Messages: []openai.ChatCompletionMessageParamUnion{
@@ -169,14 +277,19 @@ func (o *OpenRouterProvider) call(ctx context.Context, systemPrompt, userPrompt
 
 	startTime := time.Now()
 
+	var text string
+	var err error
 	if o.OnThinking != nil {
-		return o.callStreaming(ctx, params, startTime)
+		text, err = o.callStreaming(ctx, params, startTime)
+	} else {
+		text, err = o.callBuffered(ctx, params, startTime)
 	}
-	return o.callBuffered(ctx, params, startTime)
+	metrics.ObserveAI(time.Since(startTime), err)
+	return text, err
 }
 
 func (o *OpenRouterProvider) callBuffered(ctx context.Context, params openai.ChatCompletionNewParams, startTime time.Time) (string, error) {
-	resp, err := o.client.Chat.Completions.New(ctx, params, option.WithJSONSet("provider.zdr", true))
+	resp, err := o.client.Chat.Completions.New(ctx, params, o.requestOptions()...)
 	elapsed := time.Since(startTime)
 
 	if err != nil {
@@ -200,7 +313,7 @@ func (o *OpenRouterProvider) callBuffered(ctx context.Context, params openai.Cha
 }
 
 func (o *OpenRouterProvider) callStreaming(ctx context.Context, params openai.ChatCompletionNewParams, startTime time.Time) (string, error) {
-	stream := o.client.Chat.Completions.NewStreaming(ctx, params, option.WithJSONSet("provider.zdr", true))
+	stream := o.client.Chat.Completions.NewStreaming(ctx, params, o.requestOptions()...)
 	defer stream.Close()
 
 	var resultText string
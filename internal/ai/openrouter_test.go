@@ -1,8 +1,17 @@
 package ai
 
 import (
+	"context"
 	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/option"
 )
 
 func TestNewOpenRouter_DefaultModel(t *testing.T) {
@@ -21,6 +30,7 @@ func TestNewOpenRouter_CustomModel(t *testing.T) {
 
 func TestNewOpenRouter_ImplementsProvider(t *testing.T) {
 	var _ Provider = (*OpenRouterProvider)(nil)
+	var _ StandupWriter = (*OpenRouterProvider)(nil)
 }
 
 func TestVerifyOpenRouterAPIKey_WithKey(t *testing.T) {
@@ -191,3 +201,48 @@ func TestTruncateStr(t *testing.T) {
 		}
 	}
 }
+
+func TestOpenRouter_ReasoningAndExtraParams(t *testing.T) {
+	var body map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"choices":[{"index":0,"message":{"role":"assistant","content":"{\"allocations\":[]}"}}]}`)
+	}))
+	defer srv.Close()
+
+	p := NewOpenRouter("test-key", "", nil)
+	p.client = openai.NewClient(option.WithBaseURL(srv.URL), option.WithAPIKey("test-key"))
+	off := false
+	p.SetReasoning("high", &off)
+	p.SetExtraParams(map[string]any{"temperature": 0.2})
+	if _, err := p.MatchProjects(context.Background(), "email", nil, time.Hour, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	reasoning, _ := body["reasoning"].(map[string]any)
+	if reasoning["effort"] != "high" || reasoning["enabled"] != false {
+		t.Errorf("reasoning = %v, want effort high and enabled false", body["reasoning"])
+	}
+	if body["temperature"] != 0.2 {
+		t.Errorf("temperature = %v, want 0.2", body["temperature"])
+	}
+	if provider, _ := body["provider"].(map[string]any); provider["zdr"] != true {
+		t.Errorf("provider = %v, want zdr kept", body["provider"])
+	}
+}
+
+func TestOpenRouter_MissingKeyFailsBeforeCalling(t *testing.T) {
+	t.Setenv("OPENROUTER_API_KEY", "")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request sent without an API key")
+	}))
+	defer srv.Close()
+
+	p := NewOpenRouter("", "", nil)
+	p.client = openai.NewClient(option.WithBaseURL(srv.URL))
+	_, err := p.MatchProjects(context.Background(), "email", nil, time.Hour, nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "clockr doctor") {
+		t.Errorf("err = %v, want the missing key reported with a pointer to clockr doctor", err)
+	}
+}
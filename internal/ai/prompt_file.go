@@ -7,11 +7,10 @@ import (
 	"io"
 	"log/slog"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"strings"
 	"time"
 
+	"github.com/christopherklint97/clockr/internal/clipboard"
 	"github.com/christopherklint97/clockr/internal/clockify"
 	"github.com/christopherklint97/clockr/internal/config"
 )
@@ -24,15 +23,16 @@ type PromptFileProvider struct {
 	OnStatus func(string) // called with status messages for the loading view
 	ReadyCh  chan struct{} // TUI sends on this channel when user presses Enter
 	tmpDir   string        // absolute path to tmp/ directory
+	prompt   PromptOptions
 }
 
 func NewPromptFileProvider(logger *slog.Logger) (*PromptFileProvider, error) {
 	if logger == nil {
 		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
 	}
-	dir, err := config.ConfigDir()
+	dir, err := config.StateDir()
 	if err != nil {
-		return nil, fmt.Errorf("resolving config dir: %w", err)
+		return nil, fmt.Errorf("resolving state dir: %w", err)
 	}
 	return &PromptFileProvider{
 		logger:  logger,
@@ -41,8 +41,16 @@ func NewPromptFileProvider(logger *slog.Logger) (*PromptFileProvider, error) {
 	}, nil
 }
 
-func (p *PromptFileProvider) MatchProjects(_ context.Context, description string, projects []clockify.Project, interval time.Duration, contextItems []string) (*Suggestion, error) {
-	systemPrompt := buildSystemPrompt(projects, interval, contextItems)
+// SetPromptOptions sets what every match and batch prompt is built with.
+func (p *PromptFileProvider) SetPromptOptions(opts PromptOptions) {
+	p.prompt = opts
+}
+
+func (p *PromptFileProvider) MatchProjects(_ context.Context, description string, projects []clockify.Project, interval time.Duration, contextItems []ContextItem, segments []Segment) (*Suggestion, error) {
+	systemPrompt, err := buildSystemPrompt(p.prompt, projects, interval, contextItems, segments)
+	if err != nil {
+		return nil, err
+	}
 	userPrompt := buildUserPrompt(description)
 	combined := buildCombinedPrompt(systemPrompt, userPrompt, false, p.tmpDir)
 
@@ -67,7 +75,10 @@ func (p *PromptFileProvider) MatchProjects(_ context.Context, description string
 }
 
 func (p *PromptFileProvider) MatchProjectsBatch(_ context.Context, description string, projects []clockify.Project, days []DaySlot) (*BatchSuggestion, error) {
-	systemPrompt := buildBatchSystemPrompt(projects, days)
+	systemPrompt, err := buildBatchSystemPrompt(p.prompt, projects, days)
+	if err != nil {
+		return nil, err
+	}
 	userPrompt := buildBatchUserPrompt(description)
 	combined := buildCombinedPrompt(systemPrompt, userPrompt, true, p.tmpDir)
 
@@ -112,7 +123,7 @@ func (p *PromptFileProvider) writeAndWait(prompt string) error {
 	os.Remove(responsePath)
 
 	// Copy to clipboard
-	if err := copyToClipboard(prompt); err != nil {
+	if err := clipboard.Copy(prompt); err != nil {
 		p.logger.Debug("clipboard copy failed", "error", err)
 		p.emit("Clipboard copy failed: " + err.Error())
 	} else {
@@ -180,10 +191,3 @@ No markdown code fences, no explanation — just the raw JSON.
 If you are Claude Code, write the JSON to %s
 `, mode, systemPrompt, userPrompt, responsePath)
 }
-
-// copyToClipboard pipes the given text to pbcopy.
-func copyToClipboard(text string) error {
-	cmd := exec.Command("pbcopy")
-	cmd.Stdin = strings.NewReader(text)
-	return cmd.Run()
-}
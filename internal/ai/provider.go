@@ -8,6 +8,47 @@ import (
 )
 
 type Provider interface {
-	MatchProjects(ctx context.Context, description string, projects []clockify.Project, interval time.Duration, contextItems []string) (*Suggestion, error)
+	// MatchProjects suggests allocations for one window. When segments is
+	// non-empty the window is pre-split at meeting boundaries and the
+	// provider returns one allocation per segment.
+	MatchProjects(ctx context.Context, description string, projects []clockify.Project, interval time.Duration, contextItems []ContextItem, segments []Segment) (*Suggestion, error)
 	MatchProjectsBatch(ctx context.Context, description string, projects []clockify.Project, days []DaySlot) (*BatchSuggestion, error)
 }
+
+// StandupWriter drafts a standup update. previousDay labels the entries
+// (e.g. "Friday 2026-01-09"); entries and events are one line each.
+type StandupWriter interface {
+	WriteStandup(ctx context.Context, previousDay string, entries, events []string) (*Standup, error)
+}
+
+// Unwrap returns the provider that actually calls the model, looking through
+// wrappers such as RulesProvider. The TUI uses it to attach streaming hooks.
+func Unwrap(p Provider) Provider {
+	switch w := p.(type) {
+	case *RulesProvider:
+		if w.Fallback != nil {
+			return Unwrap(w.Fallback)
+		}
+	case *BudgetProvider:
+		return Unwrap(w.Next)
+	case *CachedProvider:
+		return Unwrap(w.Next)
+	case *ValidatingProvider:
+		return Unwrap(w.Next)
+	}
+	return p
+}
+
+// ModelName names what answers for p: the model for OpenRouter, or
+// "prompt-file" and "rules" for those providers. It is "" when unknown.
+func ModelName(p Provider) string {
+	switch u := Unwrap(p).(type) {
+	case *OpenRouterProvider:
+		return u.Model
+	case *PromptFileProvider:
+		return "prompt-file"
+	case *RulesProvider:
+		return "rules"
+	}
+	return ""
+}
@@ -0,0 +1,49 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RegenerateDescription asks for a replacement of allocations[row] only. The
+// other allocations are listed as fixed so the model neither repeats nor
+// rearranges them.
+func RegenerateDescription(description string, allocations []Allocation, row int) string {
+	var sb strings.Builder
+	sb.WriteString(description)
+	sb.WriteString("\n\nThe user kept these allocations and wants a different one for the remaining time:")
+	for i, a := range allocations {
+		if i == row {
+			continue
+		}
+		fmt.Fprintf(&sb, "\n- %s (%d min): %s", a.ProjectName, a.Minutes, a.Description)
+	}
+	r := allocations[row]
+	fmt.Fprintf(&sb, "\n\nReplace only this rejected allocation: %s (%d min): %s", r.ProjectName, r.Minutes, r.Description)
+	fmt.Fprintf(&sb, "\nReturn allocations covering exactly %d minutes, preferably one.", r.Minutes)
+	return sb.String()
+}
+
+// FitMinutes scales the allocations' minutes so they add up to total, giving
+// the rounding remainder to the last one. Every allocation keeps at least one
+// minute.
+func FitMinutes(allocations []Allocation, total int) {
+	if len(allocations) == 0 {
+		return
+	}
+	sum := 0
+	for _, a := range allocations {
+		sum += max(a.Minutes, 0)
+	}
+	left := total
+	for i := range allocations[:len(allocations)-1] {
+		m := total / len(allocations)
+		if sum > 0 {
+			m = max(allocations[i].Minutes, 0) * total / sum
+		}
+		m = max(m, 1)
+		allocations[i].Minutes = m
+		left -= m
+	}
+	allocations[len(allocations)-1].Minutes = max(left, 1)
+}
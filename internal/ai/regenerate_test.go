@@ -0,0 +1,52 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegenerateDescription(t *testing.T) {
+	allocs := []Allocation{
+		{ProjectName: "Alpha", Minutes: 30, Description: "Review"},
+		{ProjectName: "Beta", Minutes: 30, Description: "Emails"},
+	}
+	got := RegenerateDescription("worked on stuff", allocs, 1)
+	if !strings.HasPrefix(got, "worked on stuff\n\n") {
+		t.Errorf("description should come first:\n%s", got)
+	}
+	if !strings.Contains(got, "- Alpha (30 min): Review") || strings.Contains(got, "- Beta") {
+		t.Errorf("only the other rows should be listed as fixed:\n%s", got)
+	}
+	if !strings.Contains(got, "rejected allocation: Beta (30 min): Emails") || !strings.Contains(got, "exactly 30 minutes") {
+		t.Errorf("the rejected row and its budget should be named:\n%s", got)
+	}
+}
+
+func TestFitMinutes(t *testing.T) {
+	tests := []struct {
+		name  string
+		in    []int
+		total int
+		want  []int
+	}{
+		{"already fits", []int{45}, 45, []int{45}},
+		{"scales one", []int{60}, 45, []int{45}},
+		{"proportional", []int{20, 40}, 30, []int{10, 20}},
+		{"remainder to last", []int{10, 10, 10}, 31, []int{10, 10, 11}},
+		{"zero minutes", []int{0, 0}, 30, []int{15, 15}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allocs := make([]Allocation, len(tt.in))
+			for i, m := range tt.in {
+				allocs[i].Minutes = m
+			}
+			FitMinutes(allocs, tt.total)
+			for i, a := range allocs {
+				if a.Minutes != tt.want[i] {
+					t.Errorf("minutes[%d] = %d, want %d", i, a.Minutes, tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,241 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/clockify"
+	"github.com/christopherklint97/clockr/internal/config"
+)
+
+const (
+	// ruleConfidence is assigned to keyword/regex rule matches. Matches at or
+	// above skipAIConfidence are returned without calling the fallback.
+	ruleConfidence   = 1.0
+	repoConfidence   = 0.7
+	skipAIConfidence = 0.9
+)
+
+type compiledRule struct {
+	pattern     *regexp.Regexp
+	project     string
+	description string
+}
+
+// RulesProvider matches descriptions to projects using keyword/regex rules and
+// GitHub repo → project mappings from config. Confident matches skip the AI
+// entirely; otherwise the request is passed to Fallback (if set).
+type RulesProvider struct {
+	Fallback Provider
+	rules    []compiledRule
+	repos    map[string]string
+	logger   *slog.Logger
+}
+
+// NewRulesProvider compiles the configured rules. fallback may be nil, in which
+// case unmatched descriptions produce a clarification instead of an AI call.
+func NewRulesProvider(cfg config.MatcherConfig, fallback Provider, logger *slog.Logger) (*RulesProvider, error) {
+	if logger == nil {
+		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+
+	rules := make([]compiledRule, 0, len(cfg.Rules))
+	for i, r := range cfg.Rules {
+		if r.Pattern == "" || r.Project == "" {
+			return nil, fmt.Errorf("matcher rule %d: pattern and project are required", i+1)
+		}
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("matcher rule %d: invalid pattern %q: %w", i+1, r.Pattern, err)
+		}
+		rules = append(rules, compiledRule{pattern: re, project: r.Project, description: r.Description})
+	}
+
+	// GitHub context items use the bare repo name, so drop any "owner/" prefix.
+	repos := make(map[string]string, len(cfg.Repos))
+	for repo, project := range cfg.Repos {
+		if _, name, ok := strings.Cut(repo, "/"); ok {
+			repo = name
+		}
+		repos[strings.ToLower(repo)] = project
+	}
+
+	return &RulesProvider{
+		Fallback: fallback,
+		rules:    rules,
+		repos:    repos,
+		logger:   logger,
+	}, nil
+}
+
+// ruleMatch is the result of running the rules against one piece of input.
+type ruleMatch struct {
+	project     clockify.Project
+	description string
+	confidence  float64
+}
+
+func (r *RulesProvider) MatchProjects(ctx context.Context, description string, projects []clockify.Project, interval time.Duration, contextItems []ContextItem, segments []Segment) (*Suggestion, error) {
+	m := r.match(description, contextItems, projects)
+	if m != nil && m.confidence >= skipAIConfidence {
+		r.logger.Debug("rules matcher matched", "project", m.project.Name, "confidence", m.confidence)
+		return m.segmentedSuggestion(int(interval.Minutes()), segments), nil
+	}
+
+	if r.Fallback == nil {
+		if m != nil {
+			return m.segmentedSuggestion(int(interval.Minutes()), segments), nil
+		}
+		return &Suggestion{Clarification: "No matcher rule matched this description — add more detail or a rule under [matcher] in config."}, nil
+	}
+
+	suggestion, err := r.Fallback.MatchProjects(ctx, description, projects, interval, contextItems, segments)
+	if err != nil && m != nil {
+		r.logger.Warn("AI provider failed, using rules match", "error", err, "project", m.project.Name)
+		return m.segmentedSuggestion(int(interval.Minutes()), segments), nil
+	}
+	return suggestion, err
+}
+
+func (r *RulesProvider) MatchProjectsBatch(ctx context.Context, description string, projects []clockify.Project, days []DaySlot) (*BatchSuggestion, error) {
+	// Every day must resolve confidently for the batch to skip the AI.
+	var allocations []BatchAllocation
+	confident := true
+	for _, d := range days {
+		contextItems := append(append([]ContextItem{}, d.Events...), d.Commits...)
+		m := r.match(description, contextItems, projects)
+		if m == nil {
+			confident = false
+			allocations = nil
+			break
+		}
+		if m.confidence < skipAIConfidence {
+			confident = false
+		}
+		blocks := d.Blocks
+		if len(blocks) == 0 {
+			blocks = []Segment{{Start: d.Start, End: d.End}}
+		}
+		for _, b := range blocks {
+			allocations = append(allocations, BatchAllocation{
+				Date:        d.Date,
+				StartTime:   b.Start.Format("15:04"),
+				EndTime:     b.End.Format("15:04"),
+				ProjectID:   m.project.ID,
+				ProjectName: m.project.Name,
+				ClientName:  m.project.ClientName,
+				Minutes:     b.Minutes(),
+				Description: m.description,
+				Confidence:  m.confidence,
+			})
+		}
+	}
+
+	if confident && len(allocations) > 0 {
+		r.logger.Debug("rules matcher matched batch", "days", len(days))
+		return &BatchSuggestion{Allocations: allocations}, nil
+	}
+
+	if r.Fallback == nil {
+		if len(allocations) > 0 {
+			return &BatchSuggestion{Allocations: allocations}, nil
+		}
+		return &BatchSuggestion{Clarification: "No matcher rule matched every day in the range — add more detail or a rule under [matcher] in config."}, nil
+	}
+
+	suggestion, err := r.Fallback.MatchProjectsBatch(ctx, description, projects, days)
+	if err != nil && len(allocations) > 0 {
+		r.logger.Warn("AI provider failed, using rules match", "error", err)
+		return &BatchSuggestion{Allocations: allocations}, nil
+	}
+	return suggestion, err
+}
+
+// match runs the description rules first, then the repo mappings against
+// context items formatted as "repo: message". Returns nil if nothing matched
+// or the mapped project is not in the project list.
+func (r *RulesProvider) match(description string, contextItems []ContextItem, projects []clockify.Project) *ruleMatch {
+	for _, rule := range r.rules {
+		if !rule.pattern.MatchString(description) {
+			continue
+		}
+		p, ok := FindProject(projects, rule.project)
+		if !ok {
+			r.logger.Warn("matcher rule references unknown project", "project", rule.project)
+			continue
+		}
+		desc := rule.description
+		if desc == "" {
+			desc = description
+		}
+		return &ruleMatch{project: p, description: desc, confidence: ruleConfidence}
+	}
+
+	for _, item := range contextItems {
+		repo, _, ok := strings.Cut(item.Text, ": ")
+		if !ok {
+			continue
+		}
+		project, ok := r.repos[strings.ToLower(repo)]
+		if !ok {
+			continue
+		}
+		p, ok := FindProject(projects, project)
+		if !ok {
+			r.logger.Warn("matcher repo mapping references unknown project", "repo", repo, "project", project)
+			continue
+		}
+		return &ruleMatch{project: p, description: description, confidence: repoConfidence}
+	}
+
+	return nil
+}
+
+func (m *ruleMatch) suggestion(minutes int) *Suggestion {
+	return &Suggestion{
+		Allocations: []Allocation{{
+			ProjectID:   m.project.ID,
+			ProjectName: m.project.Name,
+			ClientName:  m.project.ClientName,
+			Minutes:     minutes,
+			Description: m.description,
+			Confidence:  m.confidence,
+		}},
+	}
+}
+
+// segmentedSuggestion is suggestion split into one allocation per segment,
+// all for the matched project, so entries still align with meetings.
+func (m *ruleMatch) segmentedSuggestion(minutes int, segments []Segment) *Suggestion {
+	s := m.suggestion(minutes)
+	if len(segments) == 0 {
+		return s
+	}
+	alloc := s.Allocations[0]
+	s.Allocations = make([]Allocation, len(segments))
+	for i, seg := range segments {
+		s.Allocations[i] = alloc
+		s.Allocations[i].Minutes = seg.Minutes()
+	}
+	return s
+}
+
+// FindProject looks up a project by exact ID, then by case-insensitive name.
+func FindProject(projects []clockify.Project, ref string) (clockify.Project, bool) {
+	for _, p := range projects {
+		if p.ID == ref {
+			return p, true
+		}
+	}
+	for _, p := range projects {
+		if strings.EqualFold(p.Name, ref) {
+			return p, true
+		}
+	}
+	return clockify.Project{}, false
+}
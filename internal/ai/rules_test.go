@@ -0,0 +1,76 @@
+package ai
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/clockify"
+	"github.com/christopherklint97/clockr/internal/config"
+)
+
+var rulesTestProjects = []clockify.Project{
+	{ID: "p1", Name: "Internal", ClientName: "Acme"},
+	{ID: "p2", Name: "Backend API"},
+}
+
+func TestRulesProvider_KeywordRuleSkipsFallback(t *testing.T) {
+	cfg := config.MatcherConfig{
+		Rules: []config.MatchRule{{Pattern: "(?i)standup", Project: "internal", Description: "Team standup"}},
+	}
+	r, err := NewRulesProvider(cfg, nil, nil)
+	if err != nil {
+		t.Fatalf("NewRulesProvider: %v", err)
+	}
+	s, err := r.MatchProjects(context.Background(), "Daily Standup and email", rulesTestProjects, time.Hour, nil, nil)
+	if err != nil {
+		t.Fatalf("MatchProjects: %v", err)
+	}
+	if len(s.Allocations) != 1 {
+		t.Fatalf("got %d allocations, want 1", len(s.Allocations))
+	}
+	a := s.Allocations[0]
+	if a.ProjectID != "p1" || a.ClientName != "Acme" || a.Minutes != 60 || a.Description != "Team standup" {
+		t.Errorf("unexpected allocation: %+v", a)
+	}
+}
+
+func TestRulesProvider_RepoMapping(t *testing.T) {
+	cfg := config.MatcherConfig{
+		Repos: map[string]string{"acme/api-server": "p2"},
+	}
+	r, err := NewRulesProvider(cfg, nil, nil)
+	if err != nil {
+		t.Fatalf("NewRulesProvider: %v", err)
+	}
+	s, err := r.MatchProjects(context.Background(), "fixed bugs", rulesTestProjects, 30*time.Minute, TextContext(SourceCommit, []string{"api-server: Fix nil pointer"}), nil)
+	if err != nil {
+		t.Fatalf("MatchProjects: %v", err)
+	}
+	if len(s.Allocations) != 1 || s.Allocations[0].ProjectID != "p2" {
+		t.Fatalf("expected repo mapping to match p2, got %+v", s)
+	}
+}
+
+func TestRulesProvider_NoMatchWithoutFallback(t *testing.T) {
+	r, err := NewRulesProvider(config.MatcherConfig{}, nil, nil)
+	if err != nil {
+		t.Fatalf("NewRulesProvider: %v", err)
+	}
+	s, err := r.MatchProjects(context.Background(), "something", rulesTestProjects, time.Hour, nil, nil)
+	if err != nil {
+		t.Fatalf("MatchProjects: %v", err)
+	}
+	if s.Clarification == "" || len(s.Allocations) != 0 {
+		t.Errorf("expected clarification and no allocations, got %+v", s)
+	}
+}
+
+func TestNewRulesProvider_InvalidPattern(t *testing.T) {
+	cfg := config.MatcherConfig{
+		Rules: []config.MatchRule{{Pattern: "(", Project: "p1"}},
+	}
+	if _, err := NewRulesProvider(cfg, nil, nil); err == nil {
+		t.Error("expected error for invalid pattern, got nil")
+	}
+}
@@ -0,0 +1,128 @@
+package ai
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// minSegment is the shortest segment worth its own entry; shorter slivers
+// (e.g. the last 5 minutes of a meeting that started before the window) are
+// folded into a neighbour.
+const minSegment = 10 * time.Minute
+
+// Segment is a fixed part of a prompt window. Meeting is the calendar event
+// covering it, or empty for time between meetings.
+type Segment struct {
+	Start   time.Time
+	End     time.Time
+	Meeting string
+}
+
+// Minutes returns the segment length in whole minutes.
+func (s Segment) Minutes() int {
+	return int(s.End.Sub(s.Start).Minutes())
+}
+
+// SplitAtMeetings divides [start, end) at the boundaries of meetings, so
+// each segment is either a meeting or the time between meetings. Overlapping
+// meetings are merged. Events covering the whole window, such as all-day
+// ones, say nothing about how it was split and are ignored. It returns nil
+// when there is nothing to split.
+func SplitAtMeetings(start, end time.Time, meetings []Segment) []Segment {
+	var busy []Segment
+	for _, m := range meetings {
+		if !m.Start.Before(end) || !m.End.After(start) {
+			continue
+		}
+		if !m.Start.After(start) && !m.End.Before(end) {
+			continue
+		}
+		if m.Start.Before(start) {
+			m.Start = start
+		}
+		if m.End.After(end) {
+			m.End = end
+		}
+		busy = append(busy, m)
+	}
+	if len(busy) == 0 {
+		return nil
+	}
+	sort.Slice(busy, func(i, j int) bool { return busy[i].Start.Before(busy[j].Start) })
+
+	merged := busy[:1]
+	for _, m := range busy[1:] {
+		last := &merged[len(merged)-1]
+		if m.Start.Before(last.End) {
+			if m.End.After(last.End) {
+				last.End = m.End
+			}
+			last.Meeting += " / " + m.Meeting
+			continue
+		}
+		merged = append(merged, m)
+	}
+
+	var segments []Segment
+	cursor := start
+	for _, m := range merged {
+		if m.Start.After(cursor) {
+			segments = append(segments, Segment{Start: cursor, End: m.Start})
+		}
+		segments = append(segments, m)
+		cursor = m.End
+	}
+	if cursor.Before(end) {
+		segments = append(segments, Segment{Start: cursor, End: end})
+	}
+
+	segments = foldShortSegments(segments)
+	if len(segments) < 2 {
+		return nil
+	}
+	return segments
+}
+
+// foldShortSegments merges segments shorter than minSegment into the
+// previous segment, or the next one if it is first.
+func foldShortSegments(segments []Segment) []Segment {
+	var out []Segment
+	for _, s := range segments {
+		if s.End.Sub(s.Start) >= minSegment || len(out) == 0 {
+			out = append(out, s)
+			continue
+		}
+		out[len(out)-1].End = s.End
+	}
+	if len(out) > 1 && out[0].End.Sub(out[0].Start) < minSegment {
+		out[1].Start = out[0].Start
+		out = out[1:]
+	}
+	return out
+}
+
+// AlignToSegments sets each allocation's minutes to its segment's length
+// when the suggestion has one allocation per segment, so entries start and
+// end exactly on meeting boundaries even if the model's arithmetic is off.
+func AlignToSegments(s *Suggestion, segments []Segment) {
+	if s == nil || len(segments) == 0 || len(s.Allocations) != len(segments) {
+		return
+	}
+	for i := range s.Allocations {
+		s.Allocations[i].Minutes = segments[i].Minutes()
+	}
+}
+
+func formatSegments(segments []Segment) string {
+	var sb strings.Builder
+	for i, s := range segments {
+		fmt.Fprintf(&sb, "  %d. %s–%s (%d min)", i+1, s.Start.Format("15:04"), s.End.Format("15:04"), s.Minutes())
+		if s.Meeting != "" {
+			fmt.Fprintf(&sb, " — meeting: %s", s.Meeting)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,83 @@
+package ai
+
+import (
+	"testing"
+	"time"
+)
+
+func at(hhmm string) time.Time {
+	t, _ := time.Parse("15:04", hhmm)
+	return time.Date(2026, 3, 2, t.Hour(), t.Minute(), 0, 0, time.Local)
+}
+
+func TestSplitAtMeetings_MeetingInMiddle(t *testing.T) {
+	segs := SplitAtMeetings(at("09:00"), at("10:00"), []Segment{
+		{Start: at("09:20"), End: at("09:45"), Meeting: "Standup"},
+	})
+	want := []int{20, 25, 15}
+	if len(segs) != len(want) {
+		t.Fatalf("got %d segments, want %d: %+v", len(segs), len(want), segs)
+	}
+	for i, m := range want {
+		if segs[i].Minutes() != m {
+			t.Errorf("segment %d: got %d min, want %d", i, segs[i].Minutes(), m)
+		}
+	}
+	if segs[1].Meeting != "Standup" || segs[0].Meeting != "" {
+		t.Errorf("unexpected meetings: %+v", segs)
+	}
+}
+
+func TestSplitAtMeetings_ClampsAndMerges(t *testing.T) {
+	segs := SplitAtMeetings(at("09:00"), at("10:00"), []Segment{
+		{Start: at("08:30"), End: at("09:30"), Meeting: "Planning"},
+		{Start: at("09:15"), End: at("09:40"), Meeting: "1:1"},
+	})
+	if len(segs) != 2 {
+		t.Fatalf("got %+v, want 2 segments", segs)
+	}
+	if !segs[0].Start.Equal(at("09:00")) || !segs[0].End.Equal(at("09:40")) || segs[0].Meeting != "Planning / 1:1" {
+		t.Errorf("unexpected merged meeting %+v", segs[0])
+	}
+}
+
+func TestSplitAtMeetings_FoldsSlivers(t *testing.T) {
+	// A meeting ending 5 minutes into the window would leave a 5-minute
+	// segment; it is folded into the next one, leaving nothing to split.
+	segs := SplitAtMeetings(at("09:00"), at("10:00"), []Segment{
+		{Start: at("08:00"), End: at("09:05"), Meeting: "Overrun"},
+	})
+	if segs != nil {
+		t.Errorf("expected no split, got %+v", segs)
+	}
+}
+
+func TestSplitAtMeetings_IgnoresWholeWindowEvents(t *testing.T) {
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.Local)
+	segs := SplitAtMeetings(at("09:00"), at("10:00"), []Segment{
+		{Start: day, End: day.AddDate(0, 0, 1), Meeting: "Conference"},
+		{Start: at("09:00"), End: at("10:00"), Meeting: "Working session"},
+		{Start: at("09:30"), End: at("10:00"), Meeting: "Review"},
+	})
+	if len(segs) != 2 {
+		t.Fatalf("got %+v, want 2 segments", segs)
+	}
+	if segs[0].Meeting != "" || segs[1].Meeting != "Review" || !segs[1].Start.Equal(at("09:30")) {
+		t.Errorf("unexpected segments %+v", segs)
+	}
+}
+
+func TestAlignToSegments(t *testing.T) {
+	segs := []Segment{{Start: at("09:00"), End: at("09:20")}, {Start: at("09:20"), End: at("10:00")}}
+	s := &Suggestion{Allocations: []Allocation{{Minutes: 30}, {Minutes: 30}}}
+	AlignToSegments(s, segs)
+	if s.Allocations[0].Minutes != 20 || s.Allocations[1].Minutes != 40 {
+		t.Errorf("minutes not aligned: %+v", s.Allocations)
+	}
+
+	mismatched := &Suggestion{Allocations: []Allocation{{Minutes: 60}}}
+	AlignToSegments(mismatched, segs)
+	if mismatched.Allocations[0].Minutes != 60 {
+		t.Error("suggestion with a different allocation count should be left alone")
+	}
+}
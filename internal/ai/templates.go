@@ -0,0 +1,215 @@
+package ai
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/christopherklint97/clockr/internal/config"
+)
+
+// The system prompts are text/template files. A file with the same name in
+// PromptsDir replaces the embedded default, so wording and company
+// conventions can change without recompiling. Files are read on every call,
+// so edits apply to the next prompt.
+
+//go:embed prompts/*.tmpl
+var defaultPrompts embed.FS
+
+// PromptTemplates are the template files clockr reads, without directory.
+var PromptTemplates = []string{"match.tmpl", "batch.tmpl", "standup.tmpl"}
+
+// MatchPromptData is what match.tmpl is rendered with.
+type MatchPromptData struct {
+	Projects     string        // JSON array of {id, name, client_name}
+	TotalMinutes int           // length of the window
+	Context      string        // context items, one labelled "  - " line each; empty when none
+	ContextItems []ContextItem // the same items, with source, time and minutes
+	Segments     string        // fixed segments, one numbered line each; empty when the window isn't split
+	SegmentCount int
+	// DescriptionRules are the [ai] description_style and
+	// description_rules, one rule each.
+	DescriptionRules []string
+	// IssueKeys asks for each allocation's Jira issue key, for Tempo.
+	IssueKeys bool
+}
+
+// BatchPromptData is what batch.tmpl is rendered with.
+type BatchPromptData struct {
+	Projects         string    // JSON array of {id, name, client_name}
+	Schedule         string    // one line per work day with hours, calendar and commits
+	Days             []DaySlot // the same days, unformatted
+	DescriptionRules []string  // as in MatchPromptData
+	IssueKeys        bool      // as in MatchPromptData
+}
+
+// PromptOptions are the settings a provider builds every match and batch
+// prompt with, set once when it is created.
+type PromptOptions struct {
+	// DescriptionRules are the house rules for descriptions passed to the
+	// model: the [ai] description_style and description_rules, one each.
+	DescriptionRules []string
+	// IssueKeys asks the model for the Jira issue key of each allocation,
+	// which entries mirrored to Tempo are logged on.
+	IssueKeys bool
+}
+
+// PromptsDir holds user overrides of the prompt templates.
+func PromptsDir() (string, error) {
+	dir, err := config.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "prompts"), nil
+}
+
+// loadPromptTemplate parses the user's copy of name when there is one and
+// the embedded default otherwise.
+func loadPromptTemplate(name string) (*template.Template, error) {
+	if dir, err := PromptsDir(); err == nil {
+		path := filepath.Join(dir, name)
+		data, err := os.ReadFile(path)
+		if err == nil {
+			t, err := template.New(name).Option("missingkey=error").Parse(string(data))
+			if err != nil {
+				return nil, fmt.Errorf("parsing prompt template %s: %w", path, err)
+			}
+			return t, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("reading prompt template: %w", err)
+		}
+	}
+	data, err := defaultPrompts.ReadFile("prompts/" + name)
+	if err != nil {
+		return nil, err
+	}
+	return template.New(name).Option("missingkey=error").Parse(string(data))
+}
+
+func renderPrompt(name string, data any) (string, error) {
+	t, err := loadPromptTemplate(name)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("rendering prompt template %s: %w", name, err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// CheckPromptTemplates parses every overridden template and renders it with
+// sample data, returning the paths of the overrides found.
+func CheckPromptTemplates() ([]string, error) {
+	dir, err := PromptsDir()
+	if err != nil {
+		return nil, err
+	}
+	sample := []ContextItem{{Source: SourceCalendar, Text: "Standup", Minutes: 15}}
+	sampleRules := []string{"use past tense"}
+	samples := map[string]any{
+		"match.tmpl":   MatchPromptData{Projects: "[]", TotalMinutes: 60, Context: formatContext(sample), ContextItems: sample, DescriptionRules: sampleRules, IssueKeys: true},
+		"batch.tmpl":   BatchPromptData{Projects: "[]", DescriptionRules: sampleRules, IssueKeys: true},
+		"standup.tmpl": nil,
+	}
+	var found []string
+	for _, name := range PromptTemplates {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		found = append(found, path)
+		if _, err := renderPrompt(name, samples[name]); err != nil {
+			return found, err
+		}
+	}
+	return found, nil
+}
+
+// WriteDefaultPrompts copies the embedded templates into PromptsDir as a
+// starting point for editing. Existing files are left alone. It returns the
+// paths it wrote.
+func WriteDefaultPrompts() ([]string, error) {
+	dir, err := PromptsDir()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("creating %s: %w", dir, err)
+	}
+	var written []string
+	for _, name := range PromptTemplates {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			continue
+		}
+		data, err := defaultPrompts.ReadFile("prompts/" + name)
+		if err != nil {
+			return written, err
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return written, fmt.Errorf("writing %s: %w", path, err)
+		}
+		written = append(written, path)
+	}
+	return written, nil
+}
+
+// PromptVersion identifies the match and batch templates in use by a short
+// hash of their text, prefixed "custom-" when either is overridden, so a
+// stored suggestion records which wording produced it.
+func PromptVersion() string {
+	h := sha256.New()
+	custom := false
+	dir, err := PromptsDir()
+	for _, name := range []string{"match.tmpl", "batch.tmpl"} {
+		var data []byte
+		if err == nil {
+			data, _ = os.ReadFile(filepath.Join(dir, name))
+		}
+		if data != nil {
+			custom = true
+		} else {
+			data, _ = defaultPrompts.ReadFile("prompts/" + name)
+		}
+		h.Write(data)
+	}
+	version := hex.EncodeToString(h.Sum(nil))[:12]
+	if custom {
+		return "custom-" + version
+	}
+	return version
+}
+
+// promptOverrideHash identifies the user's templates so cached answers made
+// with other wording aren't reused. It is empty when nothing is overridden.
+func promptOverrideHash() string {
+	dir, err := PromptsDir()
+	if err != nil {
+		return ""
+	}
+	h := sha256.New()
+	found := false
+	for _, name := range PromptTemplates {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		found = true
+		fmt.Fprintf(h, "%s\n%d\n", name, len(data))
+		h.Write(data)
+	}
+	if !found {
+		return ""
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,166 @@
+package ai
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/clockify"
+)
+
+func TestBuildSystemPrompt_Default(t *testing.T) {
+	t.Setenv("CLOCKR_HOME", t.TempDir())
+	projects := []clockify.Project{{ID: "p1", Name: "Backend", ClientName: "Acme"}}
+
+	standup := ContextItem{Source: SourceCalendar, Text: "Standup", Time: at("09:00"), Minutes: 15}
+	got, err := buildSystemPrompt(PromptOptions{}, projects, time.Hour, []ContextItem{standup, {Source: SourceCommit, Text: "api: Fix login"}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`[{"id":"p1","name":"Backend","client_name":"Acme"}]`,
+		"Context, each labelled [source time, length]:\n  - [calendar 09:00–09:15, 15 min] Standup\n  - [commit] api: Fix login\n",
+		"- The time period is 60 minutes total\n- Each allocation must be at least 30 minutes",
+		`"clarification": "string or empty"` + "\n}",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("prompt is missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "Fixed time segments") {
+		t.Errorf("unsplit window mentions segments:\n%s", got)
+	}
+
+	got, err = buildSystemPrompt(PromptOptions{}, projects, time.Hour, nil, []Segment{{Start: at("09:00"), End: at("10:00")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got, "- Return exactly 1 allocations, one per fixed segment") || strings.Contains(got, "Context (") {
+		t.Errorf("unexpected segmented prompt:\n%s", got)
+	}
+}
+
+func TestBuildSystemPrompt_Override(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("CLOCKR_HOME", home)
+	dir := filepath.Join(home, "prompts")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	tmpl := "Projects: {{.Projects}}\nAlways prefix descriptions with the ticket number. {{.TotalMinutes}} min.\n"
+	if err := os.WriteFile(filepath.Join(dir, "match.tmpl"), []byte(tmpl), 0644); err != nil {
+		t.Fatal(err)
+	}
+	before := cacheKey("match", PromptOptions{}, "x")
+
+	got, err := buildSystemPrompt(PromptOptions{}, nil, 30*time.Minute, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Projects: null\nAlways prefix descriptions with the ticket number. 30 min."; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// Other prompts still use the embedded defaults.
+	standup, err := buildStandupSystemPrompt()
+	if err != nil || !strings.HasPrefix(standup, "You are helping a developer prepare") {
+		t.Errorf("standup prompt = %q, %v", standup, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "match.tmpl"), []byte(tmpl+"More rules.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if cacheKey("match", PromptOptions{}, "x") == before {
+		t.Error("editing a template should change the cache key")
+	}
+}
+
+func TestCheckPromptTemplates(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("CLOCKR_HOME", home)
+
+	written, err := WriteDefaultPrompts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(written) != len(PromptTemplates) {
+		t.Fatalf("wrote %v", written)
+	}
+	found, err := CheckPromptTemplates()
+	if err != nil || len(found) != len(PromptTemplates) {
+		t.Fatalf("CheckPromptTemplates() = %v, %v", found, err)
+	}
+
+	bad := filepath.Join(home, "prompts", "batch.tmpl")
+	if err := os.WriteFile(bad, []byte("{{.Schedul}}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := CheckPromptTemplates(); err == nil || !strings.Contains(err.Error(), "batch.tmpl") {
+		t.Errorf("expected an error naming batch.tmpl, got %v", err)
+	}
+	if _, err := buildBatchSystemPrompt(PromptOptions{}, nil, nil); err == nil {
+		t.Error("expected a broken template to fail the prompt")
+	}
+}
+
+func TestBuildSystemPrompt_DescriptionRules(t *testing.T) {
+	t.Setenv("CLOCKR_HOME", t.TempDir())
+	before := cacheKey("match", PromptOptions{}, "x")
+	opts := PromptOptions{DescriptionRules: []string{"use past tense", "prefix with the JIRA key"}}
+
+	want := "- Every description must follow these house rules:\n  - use past tense\n  - prefix with the JIRA key\n"
+	got, err := buildSystemPrompt(opts, nil, time.Hour, nil, nil)
+	if err != nil || !strings.Contains(got, want) {
+		t.Errorf("match prompt is missing the rules (%v):\n%s", err, got)
+	}
+	got, err = buildBatchSystemPrompt(opts, nil, nil)
+	if err != nil || !strings.Contains(got, want) {
+		t.Errorf("batch prompt is missing the rules (%v):\n%s", err, got)
+	}
+	if cacheKey("match", opts, "x") == before {
+		t.Error("changing the rules should change the cache key")
+	}
+}
+
+func TestBuildSystemPrompt_IssueKeys(t *testing.T) {
+	t.Setenv("CLOCKR_HOME", t.TempDir())
+	opts := PromptOptions{IssueKeys: true}
+	for name, build := range map[string]func(PromptOptions) (string, error){
+		"match": func(o PromptOptions) (string, error) { return buildSystemPrompt(o, nil, time.Hour, nil, nil) },
+		"batch": func(o PromptOptions) (string, error) { return buildBatchSystemPrompt(o, nil, nil) },
+	} {
+		with, err := build(opts)
+		if err != nil || !strings.Contains(with, `"issue_key"`) {
+			t.Errorf("%s prompt should ask for issue keys (%v):\n%s", name, err, with)
+		}
+		without, err := build(PromptOptions{})
+		if err != nil || strings.Contains(without, "issue_key") {
+			t.Errorf("%s prompt should not ask for issue keys (%v)", name, err)
+		}
+	}
+	if cacheKey("match", opts, "x") == cacheKey("match", PromptOptions{}, "x") {
+		t.Error("asking for issue keys should change the cache key")
+	}
+}
+
+func TestPromptVersion(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("CLOCKR_HOME", home)
+	builtin := PromptVersion()
+	if len(builtin) != 12 || strings.HasPrefix(builtin, "custom-") {
+		t.Fatalf("built-in version = %q", builtin)
+	}
+
+	dir := filepath.Join(home, "prompts")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "batch.tmpl"), []byte("{{.Projects}}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := PromptVersion(); !strings.HasPrefix(got, "custom-") || got == "custom-"+builtin {
+		t.Errorf("overridden version = %q", got)
+	}
+}
@@ -0,0 +1,213 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/clockify"
+)
+
+// ValidatingProvider checks each answer from Next before it is shown. When
+// the answer breaks the rules the prompt set out — unknown project IDs,
+// minutes off the granularity, totals that don't add up, batch entries
+// outside work hours or overlapping — Next is asked again with the problems
+// listed, up to Retries times. Problems left after that are returned on the
+// suggestion for the TUI to show.
+type ValidatingProvider struct {
+	Next        Provider
+	Granularity int // minutes each allocation must be a multiple of; 0 skips the check
+	Retries     int
+	logger      *slog.Logger
+}
+
+func NewValidatingProvider(next Provider, granularity, retries int, logger *slog.Logger) *ValidatingProvider {
+	if logger == nil {
+		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+	return &ValidatingProvider{Next: next, Granularity: granularity, Retries: retries, logger: logger}
+}
+
+func (v *ValidatingProvider) MatchProjects(ctx context.Context, description string, projects []clockify.Project, interval time.Duration, contextItems []ContextItem, segments []Segment) (*Suggestion, error) {
+	desc := description
+	for attempt := 0; ; attempt++ {
+		s, err := v.Next.MatchProjects(ctx, desc, projects, interval, contextItems, segments)
+		if err != nil || s.Clarification != "" {
+			return s, err
+		}
+		problems := ValidateSuggestion(s, projects, interval, segments, v.Granularity)
+		if len(problems) == 0 {
+			return s, nil
+		}
+		v.logger.Warn("AI suggestion failed validation", "attempt", attempt+1, "problems", problems)
+		if attempt >= v.Retries || ctx.Err() != nil {
+			s.Problems = problems
+			return s, nil
+		}
+		desc = withCorrections(description, problems)
+	}
+}
+
+func (v *ValidatingProvider) MatchProjectsBatch(ctx context.Context, description string, projects []clockify.Project, days []DaySlot) (*BatchSuggestion, error) {
+	desc := description
+	for attempt := 0; ; attempt++ {
+		s, err := v.Next.MatchProjectsBatch(ctx, desc, projects, days)
+		if err != nil || s.Clarification != "" {
+			return s, err
+		}
+		problems := ValidateBatch(s, projects, days, v.Granularity)
+		if len(problems) == 0 {
+			return s, nil
+		}
+		v.logger.Warn("AI batch suggestion failed validation", "attempt", attempt+1, "problems", problems)
+		if attempt >= v.Retries || ctx.Err() != nil {
+			s.Problems = problems
+			return s, nil
+		}
+		desc = withCorrections(description, problems)
+	}
+}
+
+// withCorrections tells the model what was wrong with its last answer.
+func withCorrections(description string, problems []string) string {
+	var sb strings.Builder
+	sb.WriteString(description)
+	sb.WriteString("\n\nYour previous answer was rejected:")
+	for _, p := range problems {
+		sb.WriteString("\n- ")
+		sb.WriteString(p)
+	}
+	sb.WriteString("\nFix these problems in your new answer.")
+	return sb.String()
+}
+
+// ValidateSuggestion lists what is wrong with s for a window of interval,
+// or returns nil. With segments the minutes are snapped to the segments
+// afterwards, so only the number of allocations is checked.
+func ValidateSuggestion(s *Suggestion, projects []clockify.Project, interval time.Duration, segments []Segment, granularity int) []string {
+	var problems []string
+	known := knownProjectIDs(projects)
+	total := 0
+	for i, a := range s.Allocations {
+		if !known[a.ProjectID] {
+			problems = append(problems, fmt.Sprintf("allocation %d: project ID %q is not in the project list", i+1, a.ProjectID))
+		}
+		if a.Minutes <= 0 {
+			problems = append(problems, fmt.Sprintf("allocation %d: minutes must be positive, got %d", i+1, a.Minutes))
+		} else if granularity > 0 && len(segments) == 0 && a.Minutes%granularity != 0 {
+			problems = append(problems, fmt.Sprintf("allocation %d: %d minutes is not a multiple of %d", i+1, a.Minutes, granularity))
+		}
+		total += a.Minutes
+	}
+	if len(s.Allocations) == 0 {
+		problems = append(problems, "no allocations and no clarification")
+	} else if len(segments) > 0 {
+		if len(s.Allocations) != len(segments) {
+			problems = append(problems, fmt.Sprintf("expected %d allocations, one per fixed segment, got %d", len(segments), len(s.Allocations)))
+		}
+	} else if want := int(interval.Minutes()); total != want {
+		problems = append(problems, fmt.Sprintf("allocations sum to %d minutes, expected exactly %d", total, want))
+	}
+	return problems
+}
+
+// ValidateBatch lists what is wrong with s for days, or returns nil: each
+// allocation must fall inside its day's work hours (inside one block when
+// the day has breaks), match its own time range, not overlap another, and
+// each day must add up to its minutes.
+func ValidateBatch(s *BatchSuggestion, projects []clockify.Project, days []DaySlot, granularity int) []string {
+	var problems []string
+	known := knownProjectIDs(projects)
+	slots := make(map[string]DaySlot, len(days))
+	for _, d := range days {
+		slots[d.Date] = d
+	}
+
+	type span struct{ start, end, index int }
+	byDate := make(map[string][]span)
+	totals := make(map[string]int)
+	for i, a := range s.Allocations {
+		n := i + 1
+		if !known[a.ProjectID] {
+			problems = append(problems, fmt.Sprintf("allocation %d: project ID %q is not in the project list", n, a.ProjectID))
+		}
+		if granularity > 0 && a.Minutes%granularity != 0 {
+			problems = append(problems, fmt.Sprintf("allocation %d: %d minutes is not a multiple of %d", n, a.Minutes, granularity))
+		}
+		d, ok := slots[a.Date]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("allocation %d: %q is not one of the listed work days", n, a.Date))
+			continue
+		}
+		totals[a.Date] += a.Minutes
+
+		start, ok1 := clockMinutes(a.StartTime)
+		end, ok2 := clockMinutes(a.EndTime)
+		if !ok1 || !ok2 {
+			problems = append(problems, fmt.Sprintf("allocation %d: start_time and end_time must be HH:MM, got %q–%q", n, a.StartTime, a.EndTime))
+			continue
+		}
+		if end <= start {
+			end += 24 * 60 // runs past midnight
+		}
+		if end-start != a.Minutes {
+			problems = append(problems, fmt.Sprintf("allocation %d: %s–%s is %d minutes but minutes is %d", n, a.StartTime, a.EndTime, end-start, a.Minutes))
+		}
+		if !withinWorkHours(d, start, end) {
+			problems = append(problems, fmt.Sprintf("allocation %d: %s–%s on %s is outside the work hours %s", n, a.StartTime, a.EndTime, a.Date, d.Hours()))
+		}
+		byDate[a.Date] = append(byDate[a.Date], span{start, end, n})
+	}
+
+	for _, d := range days {
+		spans := byDate[d.Date]
+		sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+		for i := 1; i < len(spans); i++ {
+			if spans[i].start < spans[i-1].end {
+				problems = append(problems, fmt.Sprintf("allocations %d and %d overlap on %s", spans[i-1].index, spans[i].index, d.Date))
+			}
+		}
+		if totals[d.Date] != d.Minutes {
+			problems = append(problems, fmt.Sprintf("%s: allocations sum to %d minutes, expected exactly %d", d.Date, totals[d.Date], d.Minutes))
+		}
+	}
+	return problems
+}
+
+func knownProjectIDs(projects []clockify.Project) map[string]bool {
+	ids := make(map[string]bool, len(projects))
+	for _, p := range projects {
+		ids[p.ID] = true
+	}
+	return ids
+}
+
+// clockMinutes parses "HH:MM" into minutes after midnight.
+func clockMinutes(s string) (int, bool) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, false
+	}
+	return t.Hour()*60 + t.Minute(), true
+}
+
+// withinWorkHours reports whether start–end, in minutes after midnight,
+// lies inside d's work hours, or inside one of its blocks.
+func withinWorkHours(d DaySlot, start, end int) bool {
+	blocks := d.Blocks
+	if len(blocks) == 0 {
+		blocks = []Segment{{Start: d.Start, End: d.End}}
+	}
+	for _, b := range blocks {
+		from := b.Start.Hour()*60 + b.Start.Minute()
+		to := from + int(b.End.Sub(b.Start).Minutes())
+		if start >= from && end <= to {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,136 @@
+package ai
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/clockify"
+)
+
+var validateProjects = []clockify.Project{{ID: "p1", Name: "Backend"}, {ID: "p2", Name: "Meetings"}}
+
+func TestValidateSuggestion(t *testing.T) {
+	ok := &Suggestion{Allocations: []Allocation{{ProjectID: "p1", Minutes: 30}, {ProjectID: "p2", Minutes: 30}}}
+	if got := ValidateSuggestion(ok, validateProjects, time.Hour, nil, 15); got != nil {
+		t.Errorf("valid suggestion flagged: %v", got)
+	}
+
+	bad := &Suggestion{Allocations: []Allocation{{ProjectID: "p9", Minutes: 25}, {ProjectID: "p2", Minutes: 30}}}
+	got := ValidateSuggestion(bad, validateProjects, time.Hour, nil, 15)
+	want := []string{`project ID "p9"`, "not a multiple of 15", "sum to 55 minutes, expected exactly 60"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %d problems", got, len(want))
+	}
+	for i, w := range want {
+		if !strings.Contains(got[i], w) {
+			t.Errorf("problem %d = %q, want it to mention %q", i, got[i], w)
+		}
+	}
+
+	// With fixed segments only the count matters; minutes are snapped later.
+	segs := []Segment{{Start: at("09:00"), End: at("09:20")}, {Start: at("09:20"), End: at("10:00")}}
+	seg := &Suggestion{Allocations: []Allocation{{ProjectID: "p1", Minutes: 7}, {ProjectID: "p2", Minutes: 3}}}
+	if got := ValidateSuggestion(seg, validateProjects, time.Hour, segs, 15); got != nil {
+		t.Errorf("segmented suggestion flagged: %v", got)
+	}
+	seg.Allocations = seg.Allocations[:1]
+	if got := ValidateSuggestion(seg, validateProjects, time.Hour, segs, 15); len(got) != 1 {
+		t.Errorf("expected a count problem, got %v", got)
+	}
+}
+
+func TestValidateBatch(t *testing.T) {
+	days := []DaySlot{{
+		Date:    "2026-03-02",
+		Start:   at("09:00"),
+		End:     at("17:00"),
+		Minutes: 420,
+		Blocks:  []Segment{{Start: at("09:00"), End: at("12:00")}, {Start: at("13:00"), End: at("17:00")}},
+	}}
+	ok := &BatchSuggestion{Allocations: []BatchAllocation{
+		{Date: "2026-03-02", StartTime: "09:00", EndTime: "12:00", ProjectID: "p1", Minutes: 180},
+		{Date: "2026-03-02", StartTime: "13:00", EndTime: "17:00", ProjectID: "p2", Minutes: 240},
+	}}
+	if got := ValidateBatch(ok, validateProjects, days, 30); got != nil {
+		t.Errorf("valid batch flagged: %v", got)
+	}
+
+	bad := &BatchSuggestion{Allocations: []BatchAllocation{
+		{Date: "2026-03-02", StartTime: "09:00", EndTime: "13:00", ProjectID: "p1", Minutes: 240}, // spans lunch
+		{Date: "2026-03-02", StartTime: "12:30", EndTime: "17:00", ProjectID: "p2", Minutes: 270}, // overlaps
+		{Date: "2026-03-03", StartTime: "09:00", EndTime: "10:00", ProjectID: "p2", Minutes: 60},  // not a work day
+	}}
+	got := strings.Join(ValidateBatch(bad, validateProjects, days, 30), "\n")
+	for _, w := range []string{
+		"allocation 1: 09:00–13:00 on 2026-03-02 is outside the work hours",
+		"allocation 2: 12:30–17:00 on 2026-03-02 is outside the work hours",
+		`allocation 3: "2026-03-03" is not one of the listed work days`,
+		"allocations 1 and 2 overlap on 2026-03-02",
+		"2026-03-02: allocations sum to 510 minutes, expected exactly 420",
+	} {
+		if !strings.Contains(got, w) {
+			t.Errorf("missing %q in:\n%s", w, got)
+		}
+	}
+}
+
+// scriptedProvider returns its answers in turn and records the descriptions
+// it was asked with.
+type scriptedProvider struct {
+	answers      []*Suggestion
+	descriptions []string
+}
+
+func (p *scriptedProvider) MatchProjects(_ context.Context, description string, _ []clockify.Project, _ time.Duration, _ []ContextItem, _ []Segment) (*Suggestion, error) {
+	p.descriptions = append(p.descriptions, description)
+	s := p.answers[0]
+	if len(p.answers) > 1 {
+		p.answers = p.answers[1:]
+	}
+	return s, nil
+}
+
+func (p *scriptedProvider) MatchProjectsBatch(context.Context, string, []clockify.Project, []DaySlot) (*BatchSuggestion, error) {
+	return &BatchSuggestion{}, nil
+}
+
+func TestValidatingProvider_Reprompts(t *testing.T) {
+	next := &scriptedProvider{answers: []*Suggestion{
+		{Allocations: []Allocation{{ProjectID: "p9", Minutes: 60}}},
+		{Allocations: []Allocation{{ProjectID: "p1", Minutes: 60}}},
+	}}
+	v := NewValidatingProvider(next, 0, 1, nil)
+
+	s, err := v.MatchProjects(context.Background(), "fixed auth", validateProjects, time.Hour, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(next.descriptions) != 2 || s.Allocations[0].ProjectID != "p1" || s.Problems != nil {
+		t.Fatalf("expected a corrected second answer, got %+v after %d calls", s, len(next.descriptions))
+	}
+	if retry := next.descriptions[1]; !strings.HasPrefix(retry, "fixed auth\n\nYour previous answer was rejected:") || !strings.Contains(retry, `"p9"`) {
+		t.Errorf("retry description = %q", retry)
+	}
+}
+
+func TestValidatingProvider_KeepsProblemsAfterRetries(t *testing.T) {
+	next := &scriptedProvider{answers: []*Suggestion{{Allocations: []Allocation{{ProjectID: "p1", Minutes: 45}}}}}
+	v := NewValidatingProvider(next, 0, 1, nil)
+
+	s, err := v.MatchProjects(context.Background(), "fixed auth", validateProjects, time.Hour, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(next.descriptions) != 2 || len(s.Problems) != 1 {
+		t.Errorf("expected one retry and the problem kept, got %d calls and %v", len(next.descriptions), s.Problems)
+	}
+
+	// A clarification is passed through without validation.
+	next = &scriptedProvider{answers: []*Suggestion{{Clarification: "Which project?"}}}
+	s, _ = NewValidatingProvider(next, 0, 1, nil).MatchProjects(context.Background(), "stuff", validateProjects, time.Hour, nil, nil)
+	if len(next.descriptions) != 1 || s.Problems != nil {
+		t.Errorf("clarification was re-prompted or flagged: %+v", s)
+	}
+}
@@ -0,0 +1,82 @@
+// Package apitest is the fake API server behind the Harvest, Toggl and
+// Tempo client tests: canned JSON per route, a credentials check per route,
+// and a record of the bodies written.
+package apitest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// Server is an httptest server with routes added by Reply and Handle.
+type Server struct {
+	URL string
+
+	mux     *http.ServeMux
+	mu      sync.Mutex
+	written []map[string]any
+}
+
+// New starts a server that is closed when t ends.
+func New(t testing.TB) *Server {
+	t.Helper()
+	s := &Server{mux: http.NewServeMux()}
+	srv := httptest.NewServer(s.mux)
+	t.Cleanup(srv.Close)
+	s.URL = srv.URL
+	return s
+}
+
+// Auth checks a request's credentials.
+type Auth func(*http.Request) bool
+
+// Bearer accepts "Authorization: Bearer token".
+func Bearer(token string) Auth {
+	return func(r *http.Request) bool { return r.Header.Get("Authorization") == "Bearer "+token }
+}
+
+// Basic accepts HTTP basic auth with user and password.
+func Basic(user, password string) Auth {
+	return func(r *http.Request) bool {
+		u, p, ok := r.BasicAuth()
+		return ok && u == user && p == password
+	}
+}
+
+// Reply answers requests matching pattern, as in http.ServeMux, with body.
+func (s *Server) Reply(pattern string, auth Auth, body string) {
+	s.Handle(pattern, auth, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	})
+}
+
+// Handle serves pattern with h. A request that auth rejects gets 401
+// without reaching h, and the JSON body of any other request with one is
+// recorded before h runs.
+func (s *Server) Handle(pattern string, auth Auth, h http.HandlerFunc) {
+	s.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		if auth != nil && !auth(r) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Body != nil && r.ContentLength != 0 {
+			var body map[string]any
+			if json.NewDecoder(r.Body).Decode(&body) == nil {
+				s.mu.Lock()
+				s.written = append(s.written, body)
+				s.mu.Unlock()
+			}
+		}
+		h(w, r)
+	})
+}
+
+// Written returns the JSON bodies received so far, oldest first.
+func (s *Server) Written() []map[string]any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]map[string]any(nil), s.written...)
+}
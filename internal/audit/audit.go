@@ -0,0 +1,119 @@
+// Package audit checks logged time: against what Clockify holds, and against
+// work hours for gaps.
+package audit
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/clockify"
+	"github.com/christopherklint97/clockr/internal/store"
+)
+
+// Kind is the type of a discrepancy.
+type Kind string
+
+const (
+	Missing   Kind = "missing"   // logged locally but gone from Clockify
+	Duplicate Kind = "duplicate" // the same entry is in Clockify more than once
+	Shifted   Kind = "shifted"   // in both, but Clockify has different times
+	Diverged  Kind = "diverged"  // in both, but Clockify has a different project or description
+)
+
+// tolerance is how far times may differ before an entry counts as shifted;
+// Clockify drops seconds.
+const tolerance = time.Minute
+
+// Discrepancy is one difference between the local database and Clockify.
+type Discrepancy struct {
+	Kind   Kind
+	Local  *store.Entry        // the local entry; for a Duplicate, the one it copies if known
+	Remote *clockify.TimeEntry // the Clockify entry; nil when Missing
+}
+
+// Start returns when the affected entry starts, for ordering.
+func (d Discrepancy) Start() time.Time {
+	if d.Local != nil {
+		return d.Local.StartTime
+	}
+	return d.Remote.TimeInterval.Start
+}
+
+// Compare lists how remote differs from the logged entries in local. Failed
+// local entries are left to retry and running Clockify timers are ignored.
+func Compare(local []store.Entry, remote []clockify.TimeEntry) []Discrepancy {
+	byID := make(map[string]*clockify.TimeEntry, len(remote))
+	for i := range remote {
+		byID[remote[i].ID] = &remote[i]
+	}
+	linked := make(map[string]*store.Entry)
+
+	var out []Discrepancy
+	for i := range local {
+		e := &local[i]
+		if e.Status != "logged" || e.ClockifyID == "" {
+			continue
+		}
+		r, ok := byID[e.ClockifyID]
+		if !ok {
+			out = append(out, Discrepancy{Kind: Missing, Local: e})
+			continue
+		}
+		linked[r.ID] = e
+		if r.TimeInterval.End.IsZero() {
+			continue
+		}
+		if e.ProjectID != r.ProjectID || e.Description != r.Description {
+			out = append(out, Discrepancy{Kind: Diverged, Local: e, Remote: r})
+		} else if differs(e.StartTime, r.TimeInterval.Start) || differs(e.EndTime, r.TimeInterval.End) {
+			out = append(out, Discrepancy{Kind: Shifted, Local: e, Remote: r})
+		}
+	}
+
+	// Within a group of identical Clockify entries, keep the one clockr
+	// knows about (or the first) and report the others.
+	groups := make(map[string][]*clockify.TimeEntry)
+	var keys []string
+	for i := range remote {
+		r := &remote[i]
+		if r.TimeInterval.End.IsZero() {
+			continue
+		}
+		k := key(r)
+		if groups[k] == nil {
+			keys = append(keys, k)
+		}
+		groups[k] = append(groups[k], r)
+	}
+	for _, k := range keys {
+		group := groups[k]
+		if len(group) < 2 {
+			continue
+		}
+		keep := group[0]
+		for _, r := range group {
+			if linked[r.ID] != nil {
+				keep = r
+				break
+			}
+		}
+		for _, r := range group {
+			if r != keep && linked[r.ID] == nil {
+				out = append(out, Discrepancy{Kind: Duplicate, Local: linked[keep.ID], Remote: r})
+			}
+		}
+	}
+
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Start().Before(out[j].Start()) })
+	return out
+}
+
+func differs(a, b time.Time) bool {
+	d := a.Sub(b)
+	return d >= tolerance || d <= -tolerance
+}
+
+func key(r *clockify.TimeEntry) string {
+	return fmt.Sprintf("%s|%s|%d|%d", r.ProjectID, r.Description, r.TimeInterval.Start.Unix(), r.TimeInterval.End.Unix())
+}
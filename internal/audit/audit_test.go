@@ -0,0 +1,80 @@
+package audit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/clockify"
+	"github.com/christopherklint97/clockr/internal/store"
+)
+
+var nine = time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+func remoteEntry(id string, start time.Time, minutes int) clockify.TimeEntry {
+	r := clockify.TimeEntry{ID: id, ProjectID: "p1", Description: "Review"}
+	r.TimeInterval.Start = start
+	r.TimeInterval.End = start.Add(time.Duration(minutes) * time.Minute)
+	return r
+}
+
+func localEntry(id int, clockifyID string, start time.Time, minutes int) store.Entry {
+	return store.Entry{
+		ID: id, ClockifyID: clockifyID, ProjectID: "p1", Description: "Review", Status: "logged",
+		StartTime: start, EndTime: start.Add(time.Duration(minutes) * time.Minute), Minutes: minutes,
+	}
+}
+
+func TestCompare(t *testing.T) {
+	local := []store.Entry{
+		localEntry(1, "a", nine, 60),
+		localEntry(2, "b", nine.Add(time.Hour), 60),
+		localEntry(3, "c", nine.Add(2*time.Hour), 60),
+		localEntry(4, "", nine.Add(3*time.Hour), 60), // failed, left to retry
+		localEntry(5, "e", nine.Add(4*time.Hour), 60),
+	}
+	local[3].Status = "failed"
+	remote := []clockify.TimeEntry{
+		remoteEntry("x", nine, 60), // copy of a, listed before it
+		remoteEntry("a", nine, 60),
+		remoteEntry("b", nine.Add(time.Hour+15*time.Minute), 60),
+		remoteEntry("d", nine.Add(5*time.Hour), 30), // only in Clockify
+		remoteEntry("e", nine.Add(4*time.Hour), 60),
+	}
+	remote[4].Description = "Review PRs" // edited in the web UI
+
+	got := Compare(local, remote)
+	want := []struct {
+		kind     Kind
+		localID  int
+		remoteID string
+	}{
+		{Duplicate, 1, "x"},
+		{Shifted, 2, "b"},
+		{Missing, 3, ""},
+		{Diverged, 5, "e"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d discrepancies %+v, want %d", len(got), got, len(want))
+	}
+	for i, w := range want {
+		d := got[i]
+		if d.Kind != w.kind || d.Local == nil || d.Local.ID != w.localID {
+			t.Errorf("#%d = %s local %+v, want %s of local %d", i, d.Kind, d.Local, w.kind, w.localID)
+		}
+		remoteID := ""
+		if d.Remote != nil {
+			remoteID = d.Remote.ID
+		}
+		if remoteID != w.remoteID {
+			t.Errorf("#%d remote = %q, want %q", i, remoteID, w.remoteID)
+		}
+	}
+}
+
+func TestCompare_IgnoresSecondsAndMatches(t *testing.T) {
+	local := []store.Entry{localEntry(1, "a", nine.Add(20*time.Second), 60)}
+	remote := []clockify.TimeEntry{remoteEntry("a", nine, 60)}
+	if got := Compare(local, remote); len(got) != 0 {
+		t.Errorf("Compare = %+v, want no discrepancies", got)
+	}
+}
@@ -0,0 +1,85 @@
+package audit
+
+import (
+	"slices"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/config"
+)
+
+// Interval is a stretch of time.
+type Interval struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Minutes returns the interval's length in whole minutes.
+func (i Interval) Minutes() int {
+	return int(i.End.Sub(i.Start).Minutes())
+}
+
+// Gaps returns the parts of work not covered by any interval in covered.
+// Gaps shorter than minGap are dropped, so rounding between entries is not
+// reported.
+func Gaps(work, covered []Interval, minGap time.Duration) []Interval {
+	covered = slices.Clone(covered)
+	slices.SortFunc(covered, func(a, b Interval) int { return a.Start.Compare(b.Start) })
+
+	var gaps []Interval
+	add := func(start, end time.Time) {
+		if end.Sub(start) >= minGap && end.After(start) {
+			gaps = append(gaps, Interval{Start: start, End: end})
+		}
+	}
+	for _, w := range work {
+		cursor := w.Start
+		for _, c := range covered {
+			if !c.End.After(cursor) {
+				continue
+			}
+			if !c.Start.Before(w.End) {
+				break
+			}
+			if c.Start.After(cursor) {
+				add(cursor, c.Start)
+			}
+			cursor = c.End
+		}
+		if cursor.Before(w.End) {
+			add(cursor, w.End)
+		}
+	}
+	return gaps
+}
+
+// WorkHours returns the configured work hours between from and to,
+// skipping days off and holidays, and cut off at now.
+func WorkHours(sched config.ScheduleConfig, from, to, now time.Time) []Interval {
+	loc := sched.Location()
+	var work []Interval
+	for d := from.In(loc); d.Before(to); d = d.AddDate(0, 0, 1) {
+		d = time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, loc)
+		if !sched.IsWorkDay(d.Weekday()) || sched.OnHoliday(d) {
+			continue
+		}
+		for _, b := range sched.BlocksFor(d.Weekday()) {
+			w := Interval{
+				Start: d.Add(time.Duration(b.Start) * time.Minute),
+				End:   d.Add(time.Duration(b.End) * time.Minute),
+			}
+			if w.Start.Before(from) {
+				w.Start = from
+			}
+			if w.End.After(to) {
+				w.End = to
+			}
+			if w.End.After(now) {
+				w.End = now
+			}
+			if w.End.After(w.Start) {
+				work = append(work, w)
+			}
+		}
+	}
+	return work
+}
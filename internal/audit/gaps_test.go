@@ -0,0 +1,93 @@
+package audit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/config"
+)
+
+func TestGaps(t *testing.T) {
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	at := func(h, m int) time.Time { return day.Add(time.Duration(h)*time.Hour + time.Duration(m)*time.Minute) }
+	work := []Interval{{at(9, 0), at(12, 0)}, {at(13, 0), at(17, 0)}}
+	covered := []Interval{
+		{at(10, 0), at(11, 0)},
+		{at(8, 0), at(9, 30)},
+		{at(11, 2), at(12, 30)}, // the 2-minute gap before it is rounding
+		{at(14, 0), at(15, 0)},
+		{at(14, 30), at(16, 0)},
+	}
+
+	got := Gaps(work, covered, 5*time.Minute)
+	want := []Interval{
+		{at(9, 30), at(10, 0)},
+		{at(13, 0), at(14, 0)},
+		{at(16, 0), at(17, 0)},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Gaps() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if !got[i].Start.Equal(want[i].Start) || !got[i].End.Equal(want[i].End) {
+			t.Errorf("gap %d = %s–%s, want %s–%s", i,
+				got[i].Start.Format("15:04"), got[i].End.Format("15:04"),
+				want[i].Start.Format("15:04"), want[i].End.Format("15:04"))
+		}
+	}
+}
+
+func TestGaps_NothingLogged(t *testing.T) {
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	work := []Interval{{start, start.Add(8 * time.Hour)}}
+	got := Gaps(work, nil, 5*time.Minute)
+	if len(got) != 1 || got[0].Minutes() != 480 {
+		t.Errorf("Gaps() = %v, want the whole day", got)
+	}
+}
+
+func TestWorkHours(t *testing.T) {
+	sched := config.DefaultConfig().Schedule
+	sched.Timezone = "UTC"
+	sched.Holidays = []string{"2026-03-03"}
+	mon := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	now := mon.AddDate(0, 0, 2).Add(12 * time.Hour) // Wednesday noon
+
+	got := WorkHours(sched, mon, mon.AddDate(0, 0, 7), now)
+	if len(got) != 2 {
+		t.Fatalf("WorkHours() = %v, want Monday and Wednesday morning", got)
+	}
+	if got[0].Minutes() != 480 || got[1].Minutes() != 180 {
+		t.Errorf("WorkHours() minutes = %d, %d, want 480, 180", got[0].Minutes(), got[1].Minutes())
+	}
+}
+
+func TestPlace_StepsOverBusy(t *testing.T) {
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	at := func(h, m int) time.Time { return day.Add(time.Duration(h)*time.Hour + time.Duration(m)*time.Minute) }
+	busy := []Interval{{at(9, 20), at(9, 40)}}
+
+	if got := Free(Interval{at(9, 0), at(10, 0)}, busy); got != 40*time.Minute {
+		t.Errorf("Free() = %v, want 40m", got)
+	}
+
+	got := Place(at(9, 0), 30*time.Minute, busy)
+	want := []Interval{{at(9, 0), at(9, 20)}, {at(9, 40), at(9, 50)}}
+	if len(got) != len(want) {
+		t.Fatalf("Place() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if !got[i].Start.Equal(want[i].Start) || !got[i].End.Equal(want[i].End) {
+			t.Errorf("piece %d = %s–%s, want %s–%s", i,
+				got[i].Start.Format("15:04"), got[i].End.Format("15:04"),
+				want[i].Start.Format("15:04"), want[i].End.Format("15:04"))
+		}
+	}
+
+	if got := StartBefore(at(10, 0), 40*time.Minute, busy); !got.Equal(at(9, 0)) {
+		t.Errorf("StartBefore() = %s, want 09:00", got.Format("15:04"))
+	}
+	if got := StartBefore(at(10, 0), 10*time.Minute, busy); !got.Equal(at(9, 50)) {
+		t.Errorf("StartBefore() = %s, want 09:50", got.Format("15:04"))
+	}
+}
@@ -0,0 +1,54 @@
+package audit
+
+import "time"
+
+// Free returns how much of window is not covered by busy.
+func Free(window Interval, busy []Interval) time.Duration {
+	var free time.Duration
+	for _, g := range Gaps([]Interval{window}, busy, 0) {
+		free += g.End.Sub(g.Start)
+	}
+	return free
+}
+
+// Place lays d of time from start onwards, stepping over busy intervals,
+// and returns the pieces it fills.
+func Place(start time.Time, d time.Duration, busy []Interval) []Interval {
+	var pieces []Interval
+	for _, g := range Gaps([]Interval{{start, start.Add(d + total(busy))}}, busy, 0) {
+		if d <= 0 {
+			break
+		}
+		end := g.End
+		if g.End.Sub(g.Start) > d {
+			end = g.Start.Add(d)
+		}
+		pieces = append(pieces, Interval{Start: g.Start, End: end})
+		d -= end.Sub(g.Start)
+	}
+	return pieces
+}
+
+// StartBefore returns the latest start from which d of time not covered by
+// busy fits before end.
+func StartBefore(end time.Time, d time.Duration, busy []Interval) time.Time {
+	gaps := Gaps([]Interval{{end.Add(-d - total(busy)), end}}, busy, 0)
+	start := end
+	for i := len(gaps) - 1; i >= 0 && d > 0; i-- {
+		g := gaps[i]
+		start = g.Start
+		if g.End.Sub(g.Start) > d {
+			start = g.End.Add(-d)
+		}
+		d -= g.End.Sub(start)
+	}
+	return start
+}
+
+func total(intervals []Interval) time.Duration {
+	var sum time.Duration
+	for _, i := range intervals {
+		sum += i.End.Sub(i.Start)
+	}
+	return sum
+}
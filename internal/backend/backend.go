@@ -0,0 +1,107 @@
+// Package backend is the time-tracking service entries are logged to:
+// Clockify by default, or Harvest, Toggl Track or Tempo with [backend] type
+// = "harvest", "toggl" or "tempo". Projects and entries use the Clockify
+// types throughout clockr, so other backends translate to them.
+package backend
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/clockify"
+)
+
+// Backend creates and lists the user's time entries.
+type Backend interface {
+	// Name is shown in messages, e.g. "Clockify".
+	Name() string
+	// ListProjects returns the projects entries can be logged to, with
+	// ClientName filled in.
+	ListProjects(ctx context.Context) ([]clockify.Project, error)
+	CreateEntry(ctx context.Context, entry clockify.TimeEntryRequest) (*clockify.TimeEntry, error)
+	// ListEntries returns the user's entries that start in [start, end).
+	ListEntries(ctx context.Context, start, end time.Time) ([]clockify.TimeEntry, error)
+	DeleteEntry(ctx context.Context, id string) error
+	// CheckAccess verifies the credentials can log time. It returns a
+	// human-readable problem description, or "" if access looks fine.
+	CheckAccess(ctx context.Context) string
+	// SetReadOnly blocks all writes when enabled.
+	SetReadOnly(readOnly bool)
+}
+
+// Linker is implemented by backends with a web UI that can link to an entry.
+type Linker interface {
+	EntryURL(id string) string
+}
+
+// EntryURL returns the link to the entry with remote ID id in b's web UI, or
+// "" when b has none.
+func EntryURL(b Backend, id string) string {
+	if l, ok := b.(Linker); ok && id != "" {
+		return l.EntryURL(id)
+	}
+	return ""
+}
+
+// Clockify logs to a Clockify workspace.
+func Clockify(client *clockify.Client, workspaceID string) Backend {
+	return &clockifyBackend{client: client, workspaceID: workspaceID}
+}
+
+type clockifyBackend struct {
+	client      *clockify.Client
+	workspaceID string
+
+	mu     sync.Mutex
+	userID string // looked up on the first ListEntries
+}
+
+func (c *clockifyBackend) Name() string { return "Clockify" }
+
+func (c *clockifyBackend) ListProjects(ctx context.Context) ([]clockify.Project, error) {
+	projects, err := c.client.GetProjects(ctx, c.workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	c.client.EnrichProjectsWithClients(ctx, c.workspaceID, projects)
+	return projects, nil
+}
+
+func (c *clockifyBackend) CreateEntry(ctx context.Context, entry clockify.TimeEntryRequest) (*clockify.TimeEntry, error) {
+	return c.client.CreateTimeEntry(ctx, c.workspaceID, entry)
+}
+
+func (c *clockifyBackend) ListEntries(ctx context.Context, start, end time.Time) ([]clockify.TimeEntry, error) {
+	c.mu.Lock()
+	userID := c.userID
+	c.mu.Unlock()
+	if userID == "" {
+		user, err := c.client.GetUser(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("getting user: %w", err)
+		}
+		userID = user.ID
+		c.mu.Lock()
+		c.userID = userID
+		c.mu.Unlock()
+	}
+	return c.client.GetTimeEntries(ctx, c.workspaceID, userID, start, end)
+}
+
+func (c *clockifyBackend) DeleteEntry(ctx context.Context, id string) error {
+	return c.client.DeleteTimeEntry(ctx, c.workspaceID, id)
+}
+
+func (c *clockifyBackend) CheckAccess(ctx context.Context) string {
+	return c.client.CheckAccess(ctx, c.workspaceID)
+}
+
+func (c *clockifyBackend) SetReadOnly(readOnly bool) {
+	c.client.SetReadOnly(readOnly)
+}
+
+func (c *clockifyBackend) EntryURL(id string) string {
+	return c.client.EntryURL(c.workspaceID, id)
+}
@@ -0,0 +1,48 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/clockify"
+)
+
+// projectsTTL is how long cached projects are served before they are
+// fetched again, as for the Clockify client's own cache.
+const projectsTTL = time.Hour
+
+// Projects returns b's projects, served from cache while they are younger
+// than an hour. Clockify caches its projects itself, so cache only applies
+// to the other backends. When the fetch fails, a stale copy is returned if
+// there is one. cache may be nil.
+func Projects(ctx context.Context, b Backend, cache clockify.CacheStore) ([]clockify.Project, error) {
+	if _, ok := b.(*clockifyBackend); ok || cache == nil {
+		return b.ListProjects(ctx)
+	}
+
+	key := "projects:" + strings.ToLower(b.Name())
+	var stale []clockify.Project
+	if data, fetchedAt, err := cache.LoadCache(key); err == nil && data != nil {
+		var projects []clockify.Project
+		if err := json.Unmarshal(data, &projects); err == nil {
+			if time.Since(fetchedAt) < projectsTTL {
+				return projects, nil
+			}
+			stale = projects
+		}
+	}
+
+	projects, err := b.ListProjects(ctx)
+	if err != nil {
+		if stale != nil {
+			return stale, nil
+		}
+		return nil, err
+	}
+	if data, err := json.Marshal(projects); err == nil {
+		_ = cache.SaveCache(key, data)
+	}
+	return projects, nil
+}
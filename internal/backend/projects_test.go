@@ -0,0 +1,75 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/clockify"
+)
+
+type fakeBackend struct {
+	Backend
+	projects []clockify.Project
+	err      error
+	calls    int
+}
+
+func (f *fakeBackend) Name() string { return "Harvest" }
+
+func (f *fakeBackend) ListProjects(context.Context) ([]clockify.Project, error) {
+	f.calls++
+	return f.projects, f.err
+}
+
+type memCache struct {
+	data      map[string][]byte
+	fetchedAt time.Time
+}
+
+func (m *memCache) LoadCache(key string) ([]byte, time.Time, error) {
+	return m.data[key], m.fetchedAt, nil
+}
+
+func (m *memCache) SaveCache(key string, data []byte) error {
+	m.data[key] = data
+	m.fetchedAt = time.Now()
+	return nil
+}
+
+func (m *memCache) Go(f func()) { f() }
+
+func TestProjects_Cache(t *testing.T) {
+	ctx := context.Background()
+	b := &fakeBackend{projects: []clockify.Project{{ID: "1", Name: "Alpha"}}}
+	cache := &memCache{data: map[string][]byte{}}
+
+	for range 2 {
+		projects, err := Projects(ctx, b, cache)
+		if err != nil || len(projects) != 1 || projects[0].Name != "Alpha" {
+			t.Fatalf("Projects = %v, %v", projects, err)
+		}
+	}
+	if b.calls != 1 {
+		t.Errorf("ListProjects called %d times, want 1 with a fresh cache", b.calls)
+	}
+
+	// Expired: fetched again, and a failed fetch falls back to the stale copy.
+	cache.fetchedAt = time.Now().Add(-2 * projectsTTL)
+	b.err = errors.New("offline")
+	projects, err := Projects(ctx, b, cache)
+	if err != nil || len(projects) != 1 {
+		t.Fatalf("stale Projects = %v, %v", projects, err)
+	}
+	if b.calls != 2 {
+		t.Errorf("ListProjects called %d times, want 2 after expiry", b.calls)
+	}
+}
+
+func TestProjects_NoCache(t *testing.T) {
+	b := &fakeBackend{err: errors.New("offline")}
+	if _, err := Projects(context.Background(), b, nil); err == nil {
+		t.Error("want the fetch error without a cache")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"sort"
 	"strings"
 	"time"
 
@@ -101,14 +102,19 @@ func GroupByDay(events []Event) map[string][]Event {
 	return grouped
 }
 
-// FormatPrefill joins event summaries with "; " for use as TUI textarea prefill text.
-func FormatPrefill(events []Event) string {
-	if len(events) == 0 {
-		return ""
-	}
-	summaries := make([]string, len(events))
-	for i, e := range events {
-		summaries[i] = e.Summary
+// Merge sorts events fetched from several calendars by start time and drops
+// duplicates, such as a meeting on both a work and a personal calendar.
+func Merge(events []Event) []Event {
+	sort.SliceStable(events, func(i, j int) bool { return events[i].StartTime.Before(events[j].StartTime) })
+	var out []Event
+	seen := make(map[Event]bool)
+	for _, e := range events {
+		key := Event{Summary: e.Summary, StartTime: e.StartTime.UTC(), EndTime: e.EndTime.UTC()}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, e)
 	}
-	return strings.Join(summaries, "; ")
+	return out
 }
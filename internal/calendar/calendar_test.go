@@ -0,0 +1,48 @@
+package calendar
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMerge(t *testing.T) {
+	at := func(h, m int) time.Time { return time.Date(2026, 3, 2, h, m, 0, 0, time.UTC) }
+	// The same instant as seen from a calendar in another time zone.
+	cet := time.FixedZone("CET", 3600)
+	standup := Event{Summary: "Standup", StartTime: at(9, 0), EndTime: at(9, 15)}
+	review := Event{Summary: "Review", StartTime: at(14, 0), EndTime: at(15, 0)}
+	lunch := Event{Summary: "Lunch", StartTime: at(12, 0), EndTime: at(13, 0)}
+
+	tests := []struct {
+		name string
+		in   []Event
+		want []Event
+	}{
+		{"empty", nil, nil},
+		{"sorted by start", []Event{review, standup, lunch}, []Event{standup, lunch, review}},
+		{"exact duplicate", []Event{standup, review, standup}, []Event{standup, review}},
+		{
+			"duplicate in another zone",
+			[]Event{standup, {Summary: "Standup", StartTime: at(9, 0).In(cet), EndTime: at(9, 15).In(cet)}},
+			[]Event{standup},
+		},
+		{
+			"same time, other summary",
+			[]Event{standup, {Summary: "1:1", StartTime: at(9, 0), EndTime: at(9, 15)}},
+			[]Event{standup, {Summary: "1:1", StartTime: at(9, 0), EndTime: at(9, 15)}},
+		},
+		{
+			"same summary, other end",
+			[]Event{standup, {Summary: "Standup", StartTime: at(9, 0), EndTime: at(9, 30)}},
+			[]Event{standup, {Summary: "Standup", StartTime: at(9, 0), EndTime: at(9, 30)}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Merge(tt.in); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Merge() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+// Package clipboard copies text to and reads text from the system clipboard.
+package clipboard
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Copy writes text to the system clipboard using the first available tool:
+// pbcopy on macOS, clip.exe on Windows/WSL, wl-copy, xclip or xsel on Linux.
+// If none is installed and stderr is a terminal, it falls back to the OSC 52
+// escape sequence, which most terminals (including over SSH) honour.
+func Copy(text string) error {
+	for _, c := range commands() {
+		if _, err := exec.LookPath(c[0]); err != nil {
+			continue
+		}
+		cmd := exec.Command(c[0], c[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("%s: %w", c[0], err)
+		}
+		return nil
+	}
+
+	if fi, err := os.Stderr.Stat(); err == nil && fi.Mode()&os.ModeCharDevice != 0 {
+		_, err := fmt.Fprintf(os.Stderr, "\x1b]52;c;%s\x07", base64.StdEncoding.EncodeToString([]byte(text)))
+		return err
+	}
+	return fmt.Errorf("no clipboard tool found — install wl-clipboard, xclip or xsel")
+}
+
+// Paste reads text from the system clipboard with the first available tool:
+// pbpaste on macOS, PowerShell on Windows/WSL, wl-paste, xclip or xsel on
+// Linux. Windows line endings are turned into "\n".
+func Paste() (string, error) {
+	for _, c := range pasteCommands() {
+		if _, err := exec.LookPath(c[0]); err != nil {
+			continue
+		}
+		out, err := exec.Command(c[0], c[1:]...).Output()
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", c[0], err)
+		}
+		return strings.ReplaceAll(string(out), "\r\n", "\n"), nil
+	}
+	return "", fmt.Errorf("no clipboard tool found — install wl-clipboard, xclip or xsel")
+}
+
+func commands() [][]string {
+	switch runtime.GOOS {
+	case "darwin":
+		return [][]string{{"pbcopy"}}
+	case "windows":
+		return [][]string{{"clip.exe"}}
+	}
+
+	var cmds [][]string
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		cmds = append(cmds, []string{"wl-copy"})
+	}
+	if os.Getenv("DISPLAY") != "" {
+		cmds = append(cmds,
+			[]string{"xclip", "-selection", "clipboard"},
+			[]string{"xsel", "--clipboard", "--input"},
+		)
+	}
+	// WSL exposes the Windows clipboard through clip.exe.
+	cmds = append(cmds, []string{"clip.exe"})
+	return cmds
+}
+
+func pasteCommands() [][]string {
+	powershell := []string{"powershell.exe", "-NoProfile", "-Command", "Get-Clipboard -Raw"}
+	switch runtime.GOOS {
+	case "darwin":
+		return [][]string{{"pbpaste"}}
+	case "windows":
+		return [][]string{powershell}
+	}
+
+	var cmds [][]string
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		cmds = append(cmds, []string{"wl-paste", "--no-newline"})
+	}
+	if os.Getenv("DISPLAY") != "" {
+		cmds = append(cmds,
+			[]string{"xclip", "-selection", "clipboard", "-o"},
+			[]string{"xsel", "--clipboard", "--output"},
+		)
+	}
+	// WSL reads the Windows clipboard through PowerShell.
+	cmds = append(cmds, powershell)
+	return cmds
+}
@@ -4,23 +4,45 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
-	"math"
 	"net/http"
+	"net/url"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/christopherklint97/clockr/internal/httpmetrics"
+	"github.com/christopherklint97/clockr/internal/httpretry"
 )
 
 const defaultBaseURL = "https://api.clockify.me/api/v1"
 
+// ErrReadOnly is returned for any non-GET request while the client is read-only.
+var ErrReadOnly = errors.New("read-only mode: Clockify writes are disabled")
+
+// APIError is returned when Clockify responds with a non-2xx status.
+type APIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API error (status %d): %s", e.StatusCode, e.Body)
+}
+
 type Client struct {
 	apiKey     string
 	baseURL    string
 	httpClient *http.Client
 	cache      *ProjectCache
 	logger     *slog.Logger
+	readOnly   bool
+
+	store      CacheStore
+	refreshing sync.Map // cache keys being refreshed in the background
 }
 
 func NewClient(apiKey string, baseURL string, cacheTTL time.Duration, logger *slog.Logger) *Client {
@@ -34,60 +56,91 @@ func NewClient(apiKey string, baseURL string, cacheTTL time.Duration, logger *sl
 		apiKey:  apiKey,
 		baseURL: strings.TrimRight(baseURL, "/"),
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   30 * time.Second,
+			Transport: httpmetrics.Wrap("clockify", nil),
 		},
 		cache:  NewProjectCache(cacheTTL),
 		logger: logger,
 	}
 }
 
+// SetTimeout limits how long each request may take.
+func (c *Client) SetTimeout(d time.Duration) {
+	c.httpClient.Timeout = d
+}
+
+// SetTransport replaces the HTTP transport, e.g. with an ETag cache.
+// Requests are still counted by httpmetrics.
+func (c *Client) SetTransport(rt http.RoundTripper) {
+	c.httpClient.Transport = httpmetrics.Wrap("clockify", rt)
+}
+
+// SetReadOnly blocks all write requests (anything other than GET) when enabled.
+func (c *Client) SetReadOnly(readOnly bool) {
+	c.readOnly = readOnly
+}
+
+// EntryURL links to an entry in the Clockify web app: the tracker of the
+// workspace, opened on the entry. Regional API servers
+// (https://euc1.clockify.me/api/v1) keep their host.
+func (c *Client) EntryURL(workspaceID, entryID string) string {
+	web := strings.TrimSuffix(c.baseURL, "/api/v1")
+	web = strings.Replace(web, "://api.clockify.me", "://app.clockify.me", 1)
+	return fmt.Sprintf("%s/tracker?workspaceId=%s&timeEntryId=%s", web, url.QueryEscape(workspaceID), url.QueryEscape(entryID))
+}
+
 func (c *Client) doRequest(ctx context.Context, method, path string, body interface{}) ([]byte, error) {
-	var reqBody io.Reader
+	if c.readOnly && method != http.MethodGet {
+		c.logger.Debug("blocked write in read-only mode", "method", method, "path", path)
+		return nil, ErrReadOnly
+	}
+
+	var data []byte
 	if body != nil {
-		data, err := json.Marshal(body)
+		var err error
+		data, err = json.Marshal(body)
 		if err != nil {
 			return nil, fmt.Errorf("marshaling request body: %w", err)
 		}
-		reqBody = bytes.NewReader(data)
 	}
 
+	// Each attempt gets a new request with its own reader over data, so a
+	// retried POST or PUT sends the same payload as the first try.
 	url := c.baseURL + path
-	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
+	newReq := func(ctx context.Context) (*http.Request, error) {
+		var reqBody io.Reader
+		if data != nil {
+			reqBody = bytes.NewReader(data)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+		if err != nil {
+			return nil, fmt.Errorf("creating request: %w", err)
+		}
+		req.Header.Set("X-Api-Key", c.apiKey)
+		req.Header.Set("Content-Type", "application/json")
+		return req, nil
 	}
 
-	req.Header.Set("X-Api-Key", c.apiKey)
-	req.Header.Set("Content-Type", "application/json")
-
 	c.logger.Debug("clockify API request", "method", method, "path", path)
 
-	var resp *http.Response
-	maxRetries := 3
 	requestStart := time.Now()
-	for attempt := 0; attempt <= maxRetries; attempt++ {
-		resp, err = c.httpClient.Do(req)
+	policy := httpretry.Default
+	policy.OnRetry = func(attempt int, wait time.Duration, resp *http.Response, err error) {
 		if err != nil {
-			if attempt == maxRetries {
-				c.logger.Error("API request transport error", "method", method, "path", path, "error", err, "elapsed", time.Since(requestStart))
-				return nil, fmt.Errorf("sending request: %w", err)
-			}
-			c.logger.Debug("API request transport error, retrying", "method", method, "path", path, "attempt", attempt+1, "error", err)
-			time.Sleep(backoff(attempt))
-			continue
-		}
-
-		if resp.StatusCode == 429 || resp.StatusCode >= 500 {
-			resp.Body.Close()
-			if attempt == maxRetries {
-				c.logger.Error("API request failed after retries", "method", method, "path", path, "status", resp.StatusCode, "attempts", maxRetries+1, "elapsed", time.Since(requestStart))
-				return nil, fmt.Errorf("API returned status %d after %d retries", resp.StatusCode, maxRetries)
-			}
-			c.logger.Debug("API request retryable error", "method", method, "path", path, "status", resp.StatusCode, "attempt", attempt+1)
-			time.Sleep(backoff(attempt))
-			continue
-		}
-		break
+			c.logger.Debug("API request transport error, retrying", "method", method, "path", path, "attempt", attempt, "wait", wait, "error", err)
+			return
+		}
+		c.logger.Debug("API request retryable error", "method", method, "path", path, "status", resp.StatusCode, "attempt", attempt, "wait", wait)
+	}
+	resp, err := httpretry.DoFunc(ctx, c.httpClient, policy, newReq)
+	if err != nil {
+		c.logger.Error("API request transport error", "method", method, "path", path, "error", err, "elapsed", time.Since(requestStart))
+		return nil, fmt.Errorf("sending request: %w", err)
+	}
+	if httpretry.Retryable(resp.StatusCode) {
+		resp.Body.Close()
+		c.logger.Error("API request failed after retries", "method", method, "path", path, "status", resp.StatusCode, "elapsed", time.Since(requestStart))
+		return nil, fmt.Errorf("API returned status %d after retries", resp.StatusCode)
 	}
 	defer resp.Body.Close()
 
@@ -100,16 +153,12 @@ func (c *Client) doRequest(ctx context.Context, method, path string, body interf
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		c.logger.Error("API request failed", "method", method, "path", path, "status", resp.StatusCode, "response", truncate(string(respBody), 200))
-		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(respBody))
+		return nil, &APIError{StatusCode: resp.StatusCode, Body: string(respBody)}
 	}
 
 	return respBody, nil
 }
 
-func backoff(attempt int) time.Duration {
-	return time.Duration(math.Pow(2, float64(attempt))) * time.Second
-}
-
 func (c *Client) GetUser(ctx context.Context) (*User, error) {
 	data, err := c.doRequest(ctx, http.MethodGet, "/user", nil)
 	if err != nil {
@@ -124,6 +173,79 @@ func (c *Client) GetUser(ctx context.Context) (*User, error) {
 	return &user, nil
 }
 
+// GetWorkspaces returns the workspaces the API key's user belongs to.
+func (c *Client) GetWorkspaces(ctx context.Context) ([]Workspace, error) {
+	data, err := c.doRequest(ctx, http.MethodGet, "/workspaces", nil)
+	if err != nil {
+		return nil, fmt.Errorf("getting workspaces: %w", err)
+	}
+
+	var workspaces []Workspace
+	if err := json.Unmarshal(data, &workspaces); err != nil {
+		return nil, fmt.Errorf("parsing workspaces response: %w", err)
+	}
+
+	return workspaces, nil
+}
+
+// GetWorkspace returns a workspace with its settings. A workspace that
+// doesn't exist or isn't the user's is reported with the ones that are.
+func (c *Client) GetWorkspace(ctx context.Context, workspaceID string) (*Workspace, error) {
+	data, err := c.doRequest(ctx, http.MethodGet, "/workspaces/"+workspaceID, nil)
+	if err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusForbidden || apiErr.StatusCode == http.StatusNotFound) {
+			return nil, c.unknownWorkspace(ctx, workspaceID)
+		}
+		return nil, fmt.Errorf("getting workspace: %w", err)
+	}
+
+	var workspace Workspace
+	if err := json.Unmarshal(data, &workspace); err != nil {
+		return nil, fmt.Errorf("parsing workspace response: %w", err)
+	}
+	return &workspace, nil
+}
+
+// GetWorkspaceSettings returns the workspace's settings, cached like
+// projects.
+func (c *Client) GetWorkspaceSettings(ctx context.Context, workspaceID string) (*WorkspaceSettings, error) {
+	settings, err := persisted(ctx, c, "settings:"+workspaceID, func(ctx context.Context) (WorkspaceSettings, error) {
+		w, err := c.GetWorkspace(ctx, workspaceID)
+		if err != nil {
+			return WorkspaceSettings{}, err
+		}
+		return w.Settings, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// WorkspaceNotFoundError is returned for a workspace ID the user can't
+// see. Available lists the ones they can, when those could be fetched.
+type WorkspaceNotFoundError struct {
+	ID        string
+	Available []Workspace
+}
+
+func (e *WorkspaceNotFoundError) Error() string {
+	if len(e.Available) == 0 {
+		return fmt.Sprintf("workspace %s not found or not yours — check workspace_id or run 'clockr init'", e.ID)
+	}
+	var names []string
+	for _, w := range e.Available {
+		names = append(names, fmt.Sprintf("%s (%s)", w.Name, w.ID))
+	}
+	return fmt.Sprintf("workspace %s not found or not yours — your workspaces: %s", e.ID, strings.Join(names, ", "))
+}
+
+func (c *Client) unknownWorkspace(ctx context.Context, workspaceID string) error {
+	workspaces, _ := c.GetWorkspaces(ctx)
+	return &WorkspaceNotFoundError{ID: workspaceID, Available: workspaces}
+}
+
 func truncate(s string, maxLen int) string {
 	if len(s) <= maxLen {
 		return s
@@ -139,6 +261,17 @@ func (c *Client) GetProjects(ctx context.Context, workspaceID string) ([]Project
 		return cached, nil
 	}
 
+	projects, err := persisted(ctx, c, "projects:"+workspaceID, func(ctx context.Context) ([]Project, error) {
+		return c.fetchProjects(ctx, workspaceID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	c.cache.Set(projects)
+	return projects, nil
+}
+
+func (c *Client) fetchProjects(ctx context.Context, workspaceID string) ([]Project, error) {
 	var allProjects []Project
 	page := 1
 	pageSize := 500
@@ -162,17 +295,21 @@ func (c *Client) GetProjects(ctx context.Context, workspaceID string) ([]Project
 		}
 		page++
 	}
-
-	c.cache.Set(allProjects)
 	return allProjects, nil
 }
 
-func (c *Client) GetClients(ctx context.Context, workspaceID string) ([]ClockifyClient, error) {
+// GetClients returns the workspace's active clients, or its archived ones.
+func (c *Client) GetClients(ctx context.Context, workspaceID string, archived bool) ([]ClockifyClient, error) {
 	if workspaceID == "" {
 		return nil, fmt.Errorf("workspace ID is empty — set workspace_id in config or CLOCKIFY_WORKSPACE_ID env var")
 	}
+	return persisted(ctx, c, fmt.Sprintf("clients:%s:archived=%t", workspaceID, archived), func(ctx context.Context) ([]ClockifyClient, error) {
+		return c.fetchClients(ctx, workspaceID, archived)
+	})
+}
 
-	path := fmt.Sprintf("/workspaces/%s/clients?page-size=500&archived=false", workspaceID)
+func (c *Client) fetchClients(ctx context.Context, workspaceID string, archived bool) ([]ClockifyClient, error) {
+	path := fmt.Sprintf("/workspaces/%s/clients?page-size=500&archived=%t", workspaceID, archived)
 	data, err := c.doRequest(ctx, http.MethodGet, path, nil)
 	if err != nil {
 		return nil, fmt.Errorf("getting clients: %w", err)
@@ -186,20 +323,28 @@ func (c *Client) GetClients(ctx context.Context, workspaceID string) ([]Clockify
 	return clients, nil
 }
 
-// EnrichProjectsWithClients populates ClientName on each project by fetching
-// the workspace client list. Silently continues if the fetch fails.
+// EnrichProjectsWithClients populates ClientName and ClientArchived on each
+// project by fetching the workspace's active and archived clients. Silently
+// continues if a fetch fails.
 func (c *Client) EnrichProjectsWithClients(ctx context.Context, workspaceID string, projects []Project) {
-	clients, err := c.GetClients(ctx, workspaceID)
+	clients, err := c.GetClients(ctx, workspaceID, false)
 	if err != nil {
 		return
 	}
-	clientMap := make(map[string]string, len(clients))
+	if archived, err := c.GetClients(ctx, workspaceID, true); err == nil {
+		for i := range archived {
+			archived[i].Archived = true
+		}
+		clients = append(clients, archived...)
+	}
+	clientMap := make(map[string]ClockifyClient, len(clients))
 	for _, cl := range clients {
-		clientMap[cl.ID] = cl.Name
+		clientMap[cl.ID] = cl
 	}
 	for i := range projects {
-		if name, ok := clientMap[projects[i].ClientID]; ok {
-			projects[i].ClientName = name
+		if cl, ok := clientMap[projects[i].ClientID]; ok {
+			projects[i].ClientName = cl.Name
+			projects[i].ClientArchived = cl.Archived
 		}
 	}
 }
@@ -221,3 +366,111 @@ func (c *Client) CreateTimeEntry(ctx context.Context, workspaceID string, entry
 
 	return &created, nil
 }
+
+// UpdateTimeEntry replaces an entry's times, project and description.
+func (c *Client) UpdateTimeEntry(ctx context.Context, workspaceID, entryID string, entry TimeEntryRequest) (*TimeEntry, error) {
+	path := fmt.Sprintf("/workspaces/%s/time-entries/%s", workspaceID, entryID)
+	data, err := c.doRequest(ctx, http.MethodPut, path, entry)
+	if err != nil {
+		return nil, fmt.Errorf("updating time entry: %w", err)
+	}
+
+	var updated TimeEntry
+	if err := json.Unmarshal(data, &updated); err != nil {
+		return nil, fmt.Errorf("parsing time entry response: %w", err)
+	}
+	return &updated, nil
+}
+
+// GetTimeEntry returns a single time entry.
+func (c *Client) GetTimeEntry(ctx context.Context, workspaceID, entryID string) (*TimeEntry, error) {
+	path := fmt.Sprintf("/workspaces/%s/time-entries/%s", workspaceID, entryID)
+	data, err := c.doRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("getting time entry: %w", err)
+	}
+
+	var entry TimeEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("parsing time entry response: %w", err)
+	}
+	return &entry, nil
+}
+
+// GetTimeEntries returns the user's time entries that start within
+// [start, end), across all pages.
+func (c *Client) GetTimeEntries(ctx context.Context, workspaceID, userID string, start, end time.Time) ([]TimeEntry, error) {
+	return listTimeEntries[TimeEntry](ctx, c, workspaceID, userID, start, end, "")
+}
+
+// GetHydratedTimeEntries is GetTimeEntries with each entry's project, task
+// and tags included, for reports that need names rather than IDs.
+func (c *Client) GetHydratedTimeEntries(ctx context.Context, workspaceID, userID string, start, end time.Time) ([]HydratedTimeEntry, error) {
+	return listTimeEntries[HydratedTimeEntry](ctx, c, workspaceID, userID, start, end, "&hydrated=true")
+}
+
+func listTimeEntries[T any](ctx context.Context, c *Client, workspaceID, userID string, start, end time.Time, query string) ([]T, error) {
+	if workspaceID == "" {
+		return nil, fmt.Errorf("workspace ID is empty — set workspace_id in config or CLOCKIFY_WORKSPACE_ID env var")
+	}
+
+	var all []T
+	page := 1
+	pageSize := 500
+
+	for {
+		path := fmt.Sprintf("/workspaces/%s/user/%s/time-entries?start=%s&end=%s&page-size=%d&page=%d%s",
+			workspaceID, userID,
+			url.QueryEscape(start.UTC().Format("2006-01-02T15:04:05Z")),
+			url.QueryEscape(end.UTC().Format("2006-01-02T15:04:05Z")),
+			pageSize, page, query)
+		data, err := c.doRequest(ctx, http.MethodGet, path, nil)
+		if err != nil {
+			return nil, fmt.Errorf("getting time entries: %w", err)
+		}
+
+		var entries []T
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return nil, fmt.Errorf("parsing time entries response: %w", err)
+		}
+
+		all = append(all, entries...)
+
+		if len(entries) < pageSize {
+			break
+		}
+		page++
+	}
+
+	return all, nil
+}
+
+// DeleteTimeEntry removes a time entry, e.g. to roll back a partial batch.
+func (c *Client) DeleteTimeEntry(ctx context.Context, workspaceID, entryID string) error {
+	path := fmt.Sprintf("/workspaces/%s/time-entries/%s", workspaceID, entryID)
+	if _, err := c.doRequest(ctx, http.MethodDelete, path, nil); err != nil {
+		return fmt.Errorf("deleting time entry: %w", err)
+	}
+	return nil
+}
+
+// CheckAccess verifies the API key authenticates and can read the workspace.
+// Returns a human-readable problem description, or "" if access looks fine.
+func (c *Client) CheckAccess(ctx context.Context, workspaceID string) string {
+	if _, err := c.GetUser(ctx); err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnauthorized {
+			return "Clockify API key is invalid or revoked"
+		}
+		return fmt.Sprintf("Clockify API unreachable: %v", err)
+	}
+
+	if _, err := c.GetWorkspace(ctx, workspaceID); err != nil {
+		var notFound *WorkspaceNotFoundError
+		if errors.As(err, &notFound) {
+			return "Clockify " + notFound.Error()
+		}
+		return fmt.Sprintf("Clockify workspace check failed: %v", err)
+	}
+	return ""
+}
@@ -0,0 +1,57 @@
+package clockify
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/apitest"
+)
+
+func apiKey(key string) apitest.Auth {
+	return func(r *http.Request) bool { return r.Header.Get("X-Api-Key") == key }
+}
+
+func TestUpdateTimeEntry(t *testing.T) {
+	srv := apitest.New(t)
+	srv.Handle("PUT /workspaces/ws1/time-entries/te1", apiKey("key"), func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":"te1","description":"Merged","projectId":"p2","timeInterval":{"start":"2026-03-02T09:00:00Z","end":"2026-03-02T10:00:00Z"}}`))
+	})
+	c := NewClient("key", srv.URL, time.Hour, nil)
+
+	updated, err := c.UpdateTimeEntry(context.Background(), "ws1", "te1", TimeEntryRequest{
+		Start:       "2026-03-02T09:00:00Z",
+		End:         "2026-03-02T10:00:00Z",
+		ProjectID:   "p2",
+		Description: "Merged",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.ID != "te1" || updated.ProjectID != "p2" || !updated.TimeInterval.End.Equal(time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("updated = %+v", updated)
+	}
+	written := srv.Written()
+	if len(written) != 1 {
+		t.Fatalf("written = %v, want one PUT", written)
+	}
+	for field, want := range map[string]string{"start": "2026-03-02T09:00:00Z", "end": "2026-03-02T10:00:00Z", "projectId": "p2", "description": "Merged"} {
+		if got := written[0][field]; got != want {
+			t.Errorf("%s = %v, want %s", field, got, want)
+		}
+	}
+}
+
+func TestUpdateTimeEntry_Error(t *testing.T) {
+	srv := apitest.New(t)
+	srv.Reply("PUT /workspaces/ws1/time-entries/te1", apiKey("key"), `{}`)
+	c := NewClient("wrong", srv.URL, time.Hour, nil)
+
+	_, err := c.UpdateTimeEntry(context.Background(), "ws1", "te1", TimeEntryRequest{})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("err = %v, want a 401 APIError", err)
+	}
+}
@@ -0,0 +1,63 @@
+package clockify
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// apiErrorBody is the JSON body Clockify sends with a rejected request.
+type apiErrorBody struct {
+	Message string `json:"message"`
+	Code    int    `json:"code"`
+}
+
+// Message returns Clockify's own message from the response body, or the raw
+// body when it is not the usual JSON.
+func (e *APIError) Message() string {
+	var body apiErrorBody
+	if err := json.Unmarshal([]byte(e.Body), &body); err == nil && body.Message != "" {
+		return body.Message
+	}
+	return strings.TrimSpace(e.Body)
+}
+
+// friendlyMessages maps fragments of Clockify's validation messages to what
+// the user can do about them. Matching is case-insensitive.
+var friendlyMessages = []struct {
+	fragment, message string
+}{
+	{"archived", "The project is archived. Pick another project or unarchive it in Clockify."},
+	{"overlap", "Overlaps an existing time entry. Adjust the time or remove the other entry in Clockify."},
+	{"locked", "The period is locked in Clockify. Ask an admin to unlock it."},
+	{"task", "This project needs a task. Set one in Clockify or pick another project."},
+	{"description", "This workspace needs a description on every entry."},
+	{"project", "The project was rejected. It may be deleted or need a client; pick another project."},
+}
+
+// FriendlyError explains why Clockify rejected a request in terms the user
+// can act on, falling back to Clockify's own message.
+func FriendlyError(err error) string {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return err.Error()
+	}
+	msg := apiErr.Message()
+	switch apiErr.StatusCode {
+	case http.StatusUnauthorized:
+		return "Clockify rejected the API key. Run 'clockr init' to set a new one."
+	case http.StatusForbidden:
+		return "No permission to log time here: " + msg
+	}
+	lower := strings.ToLower(msg)
+	for _, f := range friendlyMessages {
+		if strings.Contains(lower, f.fragment) {
+			return f.message
+		}
+	}
+	if msg == "" {
+		return apiErr.Error()
+	}
+	return msg
+}
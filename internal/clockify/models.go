@@ -3,11 +3,45 @@ package clockify
 import "time"
 
 type User struct {
-	ID               string `json:"id"`
-	Email            string `json:"email"`
-	Name             string `json:"name"`
-	ActiveWorkspace  string `json:"activeWorkspace"`
-	DefaultWorkspace string `json:"defaultWorkspace"`
+	ID               string       `json:"id"`
+	Email            string       `json:"email"`
+	Name             string       `json:"name"`
+	ActiveWorkspace  string       `json:"activeWorkspace"`
+	DefaultWorkspace string       `json:"defaultWorkspace"`
+	Settings         UserSettings `json:"settings"`
+}
+
+// UserSettings are the user's display preferences in Clockify.
+type UserSettings struct {
+	TimeZone   string `json:"timeZone"`
+	WeekStart  string `json:"weekStart"` // e.g. MONDAY
+	TimeFormat string `json:"timeFormat"`
+	DateFormat string `json:"dateFormat"`
+}
+
+type Workspace struct {
+	ID       string            `json:"id"`
+	Name     string            `json:"name"`
+	Settings WorkspaceSettings `json:"workspaceSettings"`
+}
+
+// WorkspaceSettings are the rules a workspace admin sets for everyone.
+type WorkspaceSettings struct {
+	TimeRoundingInReports bool     `json:"timeRoundingInReports"`
+	Round                 Rounding `json:"round"`
+	ForceProjects         bool     `json:"forceProjects"`
+	ForceDescription      bool     `json:"forceDescription"`
+	LockTimeEntries       string   `json:"lockTimeEntries"` // entries before this time can't be changed
+	DurationFormat        string   `json:"durationFormat"`  // FULL, COMPACT or DECIMAL
+	TrackTimeDownToSecond bool     `json:"trackTimeDownToSecond"`
+}
+
+// Rounding is a rounding rule in the form of Clockify's workspace setting,
+// e.g. {"Round to nearest", "15"}. Clockify only applies its own to
+// reports; clockr rounds entries by [format] round_minutes.
+type Rounding struct {
+	Round   string `json:"round"`
+	Minutes string `json:"minutes"`
 }
 
 type Project struct {
@@ -17,26 +51,72 @@ type Project struct {
 	Color      string `json:"color"`
 	ClientID   string `json:"clientId"`
 	ClientName string `json:"-"` // populated after fetching clients
+	// ClientArchived is set after fetching clients when the project's
+	// client is archived; such entries are rejected at invoicing.
+	ClientArchived bool `json:"-"`
 }
 
 type ClockifyClient struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Archived bool   `json:"archived"`
 }
 
 type TimeEntryRequest struct {
-	Start       string `json:"start"`
-	End         string `json:"end"`
-	ProjectID   string `json:"projectId"`
-	Description string `json:"description"`
+	Start       string   `json:"start"`
+	End         string   `json:"end"`
+	ProjectID   string   `json:"projectId"`
+	Description string   `json:"description"`
+	TagIDs      []string `json:"tagIds,omitempty"`
 }
 
 type TimeEntry struct {
-	ID          string `json:"id"`
-	Description string `json:"description"`
-	ProjectID   string `json:"projectId"`
+	ID          string   `json:"id"`
+	Description string   `json:"description"`
+	ProjectID   string   `json:"projectId"`
+	TaskID      string   `json:"taskId"`
+	TagIDs      []string `json:"tagIds"`
+	Billable    bool     `json:"billable"`
 	TimeInterval struct {
 		Start time.Time `json:"start"`
 		End   time.Time `json:"end"`
 	} `json:"timeInterval"`
 }
+
+// HydratedTimeEntry is a time entry with its project, task and tags filled
+// in, as returned with hydrated=true. Project and Task are nil when unset;
+// TimeInterval.End is zero while the timer is running.
+type HydratedTimeEntry struct {
+	ID           string        `json:"id"`
+	Description  string        `json:"description"`
+	UserID       string        `json:"userId"`
+	WorkspaceID  string        `json:"workspaceId"`
+	Billable     bool          `json:"billable"`
+	Project      *EntryProject `json:"project"`
+	Task         *Task         `json:"task"`
+	Tags         []Tag         `json:"tags"`
+	TimeInterval struct {
+		Start    time.Time `json:"start"`
+		End      time.Time `json:"end"`
+		Duration string    `json:"duration"` // ISO 8601, e.g. PT1H30M
+	} `json:"timeInterval"`
+}
+
+// EntryProject is the project embedded in a hydrated time entry.
+type EntryProject struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	ClientID   string `json:"clientId"`
+	ClientName string `json:"clientName"`
+	Color      string `json:"color"`
+}
+
+type Task struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type Tag struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
@@ -0,0 +1,76 @@
+package clockify
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// CacheStore keeps fetched projects and clients between runs, so a new
+// process doesn't wait on Clockify before it can show anything.
+type CacheStore interface {
+	LoadCache(key string) (data []byte, fetchedAt time.Time, err error)
+	SaveCache(key string, data []byte) error
+	// Go runs a background refresh, letting it finish before the store
+	// is closed.
+	Go(f func())
+}
+
+// SetCacheStore serves projects and clients from s, refreshing them in the
+// background once they are older than the cache TTL.
+func (c *Client) SetCacheStore(s CacheStore) {
+	c.store = s
+}
+
+// persisted returns key from the store when it has it, otherwise calls
+// fetch and saves the result. A stale value is returned as is while a
+// background fetch replaces it.
+func persisted[T any](ctx context.Context, c *Client, key string, fetch func(context.Context) (T, error)) (T, error) {
+	if c.store != nil {
+		if data, fetchedAt, err := c.store.LoadCache(key); err == nil && data != nil {
+			var v T
+			if err := json.Unmarshal(data, &v); err == nil {
+				if time.Since(fetchedAt) > c.cache.ttl {
+					c.store.Go(func() { refresh(c, key, fetch) })
+				}
+				return v, nil
+			}
+		}
+	}
+
+	v, err := fetch(ctx)
+	if err != nil {
+		return v, err
+	}
+	c.save(key, v)
+	return v, nil
+}
+
+func refresh[T any](c *Client, key string, fetch func(context.Context) (T, error)) {
+	if _, busy := c.refreshing.LoadOrStore(key, true); busy {
+		return
+	}
+	defer c.refreshing.Delete(key)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	v, err := fetch(ctx)
+	if err != nil {
+		c.logger.Debug("background cache refresh failed", "key", key, "error", err)
+		return
+	}
+	c.save(key, v)
+}
+
+func (c *Client) save(key string, v any) {
+	if c.store == nil {
+		return
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	if err := c.store.SaveCache(key, data); err != nil {
+		c.logger.Debug("saving cache", "key", key, "error", err)
+	}
+}
@@ -0,0 +1,107 @@
+package clockify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memStore is a CacheStore in memory whose Go runs f before returning, so
+// background refreshes are done when persisted returns.
+type memStore struct {
+	mu        sync.Mutex
+	data      map[string][]byte
+	fetchedAt map[string]time.Time
+}
+
+func newMemStore() *memStore {
+	return &memStore{data: make(map[string][]byte), fetchedAt: make(map[string]time.Time)}
+}
+
+func (m *memStore) LoadCache(key string) ([]byte, time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.data[key], m.fetchedAt[key], nil
+}
+
+func (m *memStore) SaveCache(key string, data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data[key], m.fetchedAt[key] = data, time.Now()
+	return nil
+}
+
+func (m *memStore) Go(f func()) { f() }
+
+func (m *memStore) put(key string, v any, age time.Duration) {
+	data, _ := json.Marshal(v)
+	m.data[key], m.fetchedAt[key] = data, time.Now().Add(-age)
+}
+
+func TestPersisted(t *testing.T) {
+	fetchCount := 0
+	fetch := func(value string, err error) func(context.Context) (string, error) {
+		return func(context.Context) (string, error) {
+			fetchCount++
+			return value, err
+		}
+	}
+
+	tests := []struct {
+		name      string
+		cached    string
+		age       time.Duration
+		fetched   string
+		fetchErr  error
+		want      string
+		wantErr   bool
+		wantFetch int
+		wantSaved string
+	}{
+		{name: "miss fetches and saves", fetched: "live", want: "live", wantFetch: 1, wantSaved: "live"},
+		{name: "fresh hit", cached: "cached", age: time.Minute, fetched: "live", want: "cached", wantSaved: "cached"},
+		{name: "stale hit refreshes behind", cached: "cached", age: 2 * time.Hour, fetched: "live", want: "cached", wantFetch: 1, wantSaved: "live"},
+		{name: "failed refresh keeps the stale value", cached: "cached", age: 2 * time.Hour, fetchErr: errors.New("offline"), want: "cached", wantFetch: 1, wantSaved: "cached"},
+		{name: "failed miss", fetchErr: errors.New("offline"), wantErr: true, wantFetch: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetchCount = 0
+			c := NewClient("key", "", time.Hour, nil)
+			store := newMemStore()
+			if tt.cached != "" {
+				store.put("k", tt.cached, tt.age)
+			}
+			c.SetCacheStore(store)
+
+			got, err := persisted(context.Background(), c, "k", fetch(tt.fetched, tt.fetchErr))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("persisted() = %q, want %q", got, tt.want)
+			}
+			if fetchCount != tt.wantFetch {
+				t.Errorf("fetches = %d, want %d", fetchCount, tt.wantFetch)
+			}
+			var saved string
+			if data, _, _ := store.LoadCache("k"); data != nil {
+				json.Unmarshal(data, &saved)
+			}
+			if saved != tt.wantSaved {
+				t.Errorf("saved = %q, want %q", saved, tt.wantSaved)
+			}
+		})
+	}
+}
+
+func TestPersisted_NoStore(t *testing.T) {
+	c := NewClient("key", "", time.Hour, nil)
+	got, err := persisted(context.Background(), c, "k", func(context.Context) (int, error) { return 7, nil })
+	if err != nil || got != 7 {
+		t.Errorf("persisted() = %d, %v; want 7", got, err)
+	}
+}
@@ -0,0 +1,72 @@
+package clockify
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+func (r Rounding) step() int {
+	n, err := strconv.Atoi(strings.TrimSpace(r.Minutes))
+	if err != nil || n <= 1 {
+		return 0
+	}
+	return n
+}
+
+// Enabled reports whether r changes any durations.
+func (r Rounding) Enabled() bool {
+	return r.step() > 0
+}
+
+// Apply rounds minutes the way Clockify does. Time that would round away
+// entirely is kept as one step, so no allocation disappears.
+func (r Rounding) Apply(minutes int) int {
+	step := r.step()
+	if step == 0 || minutes <= 0 {
+		return minutes
+	}
+	var n int
+	switch strings.ToLower(r.Round) {
+	case "round up to":
+		n = (minutes + step - 1) / step
+	case "round down to":
+		n = minutes / step
+	default: // "Round to nearest"
+		n = (minutes + step/2) / step
+	}
+	return max(n, 1) * step
+}
+
+// ApplyCumulative rounds back-to-back durations by their cumulative ends,
+// so the total is rounded once rather than each part on its own: three
+// 20-minute parts at 15-minute steps become 15, 30 and 15 instead of 15 each.
+// A part that would round away entirely is kept as one step.
+func (r Rounding) ApplyCumulative(minutes []int) []int {
+	out := slices.Clone(minutes)
+	step := r.step()
+	if step == 0 {
+		return out
+	}
+	sum, prev := 0, 0
+	for i, m := range minutes {
+		sum += m
+		end := max(r.Apply(sum), prev+step)
+		out[i] = end - prev
+		prev = end
+	}
+	return out
+}
+
+// String describes r, e.g. "nearest 15 min".
+func (r Rounding) String() string {
+	if !r.Enabled() {
+		return ""
+	}
+	mode := strings.TrimSuffix(strings.TrimPrefix(strings.ToLower(r.Round), "round "), " to")
+	if mode == "" {
+		mode = "nearest"
+	}
+	return fmt.Sprintf("%s %d min", mode, r.step())
+}
@@ -0,0 +1,79 @@
+package config
+
+import "strings"
+
+// BackendConfig picks the time-tracking service entries are logged to.
+type BackendConfig struct {
+	Type string `toml:"type"` // "clockify" (default), "harvest", "toggl" or "tempo"
+}
+
+// Clockify reports whether entries go to Clockify, the default.
+func (b BackendConfig) Clockify() bool {
+	return b.Type == "" || b.Type == "clockify"
+}
+
+// Harvest reports whether entries go to Harvest instead of Clockify.
+func (b BackendConfig) Harvest() bool {
+	return b.Type == "harvest"
+}
+
+// Toggl reports whether entries go to Toggl Track instead of Clockify.
+func (b BackendConfig) Toggl() bool {
+	return b.Type == "toggl"
+}
+
+// Tempo reports whether entries go to Tempo for Jira instead of Clockify.
+func (b BackendConfig) Tempo() bool {
+	return b.Type == "tempo"
+}
+
+// HarvestConfig connects to Harvest when [backend] type = "harvest".
+type HarvestConfig struct {
+	AccountID string `toml:"account_id"` // or HARVEST_ACCOUNT_ID
+	Token     string `toml:"token"`      // personal access token, or HARVEST_TOKEN, or the keychain
+	// Task is the task entries are logged to on each project, by name.
+	// Empty uses the project's first active task.
+	Task    string `toml:"task"`
+	BaseURL string `toml:"base_url"`
+}
+
+// TogglConfig connects to Toggl Track when [backend] type = "toggl".
+type TogglConfig struct {
+	APIToken    string `toml:"api_token"`    // or TOGGL_API_TOKEN, or the keychain
+	WorkspaceID string `toml:"workspace_id"` // or TOGGL_WORKSPACE_ID; empty uses the default workspace
+	BaseURL     string `toml:"base_url"`
+}
+
+// TempoConfig connects to Tempo for Jira, either as the backend ([backend]
+// type = "tempo", with Jira issues as projects) or, with Mirror, as a second
+// destination for entries logged to another backend. Worklogs need the Jira
+// account and issue IDs, so both Tempo and Jira credentials are required.
+type TempoConfig struct {
+	Token     string `toml:"token"`      // Tempo API token, or TEMPO_TOKEN, or the keychain
+	JiraURL   string `toml:"jira_url"`   // e.g. "https://acme.atlassian.net", or JIRA_URL
+	JiraEmail string `toml:"jira_email"` // or JIRA_EMAIL
+	JiraToken string `toml:"jira_token"` // Atlassian API token, or JIRA_API_TOKEN, or the keychain
+	// JQL picks the issues offered as projects when Tempo is the backend.
+	// Empty offers the open issues assigned to the user.
+	JQL string `toml:"jql"`
+	// Mirror copies each entry logged to the backend to Tempo as well.
+	Mirror bool `toml:"mirror"`
+	// Issues maps a project ID or name to the Jira issue its mirrored
+	// entries are logged on when the AI found no issue key.
+	Issues  map[string]string `toml:"issues"`
+	BaseURL string            `toml:"base_url"`
+}
+
+// IssueFor returns the Jira issue mapped to a project in [tempo.issues], by
+// ID first and then by name, case-insensitively; "" when there is none.
+func (t TempoConfig) IssueFor(projectID, projectName string) string {
+	if key, ok := t.Issues[projectID]; ok && projectID != "" {
+		return key
+	}
+	for name, key := range t.Issues {
+		if projectName != "" && strings.EqualFold(name, projectName) {
+			return key
+		}
+	}
+	return ""
+}
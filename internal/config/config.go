@@ -4,17 +4,251 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
 
+	"github.com/christopherklint97/clockr/internal/secrets"
 	"github.com/pelletier/go-toml/v2"
 )
 
 type Config struct {
+	ReadOnly      bool            `toml:"read_only"` // disable Clockify writes and DB mutations
 	Clockify      ClockifyConfig  `toml:"clockify"`
+	Backend       BackendConfig   `toml:"backend"`
+	Harvest       HarvestConfig   `toml:"harvest"`
+	Toggl         TogglConfig     `toml:"toggl"`
+	Tempo         TempoConfig     `toml:"tempo"`
+	Mirror        MirrorConfig    `toml:"mirror"`
 	Schedule      ScheduleConfig  `toml:"schedule"`
 	AI            AIConfig        `toml:"ai"`
 	Notifications NotifyConfig    `toml:"notifications"`
 	Calendar      CalendarConfig  `toml:"calendar"`
 	GitHub        GitHubConfig    `toml:"github"`
+	Context       ContextConfig   `toml:"context"`
+	Matcher       MatcherConfig   `toml:"matcher"`
+	Format        FormatConfig    `toml:"format"`
+	Coverage      CoverageConfig  `toml:"coverage"`
+	Export        ExportConfig    `toml:"export"`
+	Digest        DigestConfig    `toml:"digest"`
+	Slack         SlackConfig     `toml:"slack"`
+	Serve         ServeConfig     `toml:"serve"`
+	Metrics       MetricsConfig   `toml:"metrics"`
+	Timeouts      TimeoutsConfig  `toml:"timeouts"`
+	Recurring     []RecurringEntry `toml:"recurring"`
+
+	// secretSources maps a secrets name to "env" or "keychain" for the
+	// credentials Load took from there.
+	secretSources map[string]string
+}
+
+// SecretSource returns where Load found the credential name (one of the
+// secrets constants): "env", "keychain", or "" for the config file or unset.
+// 'clockr init' uses it to keep such credentials out of config.toml.
+func (c *Config) SecretSource(name string) string {
+	return c.secretSources[name]
+}
+
+func (c *Config) setSecretSource(name, source string) {
+	if c.secretSources == nil {
+		c.secretSources = make(map[string]string)
+	}
+	c.secretSources[name] = source
+}
+
+// TimeoutsConfig sets how long clockr waits on the network; zero keeps the
+// default. The global --timeout flag overrides all of them.
+type TimeoutsConfig struct {
+	ClockifySeconds int `toml:"clockify_seconds"` // each Clockify request (default 30)
+	ContextSeconds  int `toml:"context_seconds"`  // calendar, GitHub and holiday fetches (default 15)
+	AISeconds       int `toml:"ai_seconds"`       // AI calls, or silence in a stream (default 120)
+	HarvestSeconds  int `toml:"harvest_seconds"`  // each Harvest request (default 30)
+	TogglSeconds    int `toml:"toggl_seconds"`    // each Toggl Track request (default 30)
+	TempoSeconds    int `toml:"tempo_seconds"`    // each Tempo or Jira request (default 30)
+}
+
+// ExportConfig sets the defaults of 'clockr export'.
+type ExportConfig struct {
+	Profile  string `toml:"profile"`  // csv (default), datev, quickbooks or template
+	Template string `toml:"template"` // text/template file for the template profile
+	Employee string `toml:"employee"` // your name, for the datev and quickbooks profiles
+}
+
+// DigestConfig has the scheduler summarise the previous week once a week:
+// totals per project, gaps in work hours and entries that failed to reach
+// Clockify.
+type DigestConfig struct {
+	Enabled bool   `toml:"enabled"`
+	Day     string `toml:"day"`    // weekday to send on (default "monday")
+	Time    string `toml:"time"`   // HH:MM to send at (default "09:00")
+	Notify  bool   `toml:"notify"` // desktop notification with the totals
+	// File writes the digest as markdown; {week} is replaced with the ISO
+	// week, e.g. "2026-W41".
+	File  string      `toml:"file"`
+	Email EmailConfig `toml:"email"`
+}
+
+// EmailConfig sends the digest over SMTP. STARTTLS is used when the server
+// offers it.
+type EmailConfig struct {
+	To       []string `toml:"to"`
+	From     string   `toml:"from"`
+	Host     string   `toml:"host"`
+	Port     int      `toml:"port"` // default 587
+	Username string   `toml:"username"`
+	Password string   `toml:"password"` // or CLOCKR_SMTP_PASSWORD, or the keychain
+}
+
+// SlackConfig has the scheduler send each prompt as a Slack DM and log the
+// reply. Replies reach clockr through the Events API, so the app's event
+// subscription URL must point at Listen (e.g. through a tunnel).
+type SlackConfig struct {
+	Enabled       bool   `toml:"enabled"`
+	BotToken      string `toml:"bot_token"`      // xoxb-…, or CLOCKR_SLACK_BOT_TOKEN, or the keychain
+	SigningSecret string `toml:"signing_secret"` // or CLOCKR_SLACK_SIGNING_SECRET, or the keychain
+	UserID        string `toml:"user_id"`        // your member ID, e.g. U0123ABCD
+	Listen        string `toml:"listen"`         // events endpoint (default "127.0.0.1:8788")
+}
+
+// Addr returns the address the events endpoint listens on.
+func (s SlackConfig) Addr() string {
+	if s.Listen != "" {
+		return s.Listen
+	}
+	return "127.0.0.1:8788"
+}
+
+// ServeConfig sets up 'clockr serve', the local HTTP API.
+type ServeConfig struct {
+	Listen string `toml:"listen"` // default "127.0.0.1:8787"
+	// Token guards the API; or CLOCKR_SERVE_TOKEN. When unset, serve
+	// generates one on first start and keeps it in the database.
+	Token string `toml:"token"`
+}
+
+// MetricsConfig exposes Prometheus metrics on /metrics: 'clockr start'
+// listens on Listen, 'clockr serve' adds the route to its API, behind the
+// token.
+type MetricsConfig struct {
+	Enabled bool   `toml:"enabled"`
+	Listen  string `toml:"listen"` // for 'clockr start'; default "127.0.0.1:9464"
+}
+
+// Addr returns the address the scheduler serves metrics on.
+func (m MetricsConfig) Addr() string {
+	if m.Listen != "" {
+		return m.Listen
+	}
+	return "127.0.0.1:9464"
+}
+
+// ContextConfig adds context sources for the AI besides the calendar,
+// GitHub and the plugins directory.
+type ContextConfig struct {
+	Custom CustomContextConfig `toml:"custom"`
+}
+
+// CustomContextConfig runs a command for context. It is sent the plugin
+// "context" request as JSON on stdin and answers with {"items": [...]} on
+// stdout, like a context plugin.
+type CustomContextConfig struct {
+	Command string `toml:"command"` // run through the shell
+	Name    string `toml:"name"`    // shown in warnings; default "custom"
+}
+
+// DescriptionGuidelines lists the description style followed by the rules,
+// without blank entries.
+func (a AIConfig) DescriptionGuidelines() []string {
+	var rules []string
+	for _, r := range append([]string{a.DescriptionStyle}, a.DescriptionRules...) {
+		if r = strings.TrimSpace(r); r != "" {
+			rules = append(rules, r)
+		}
+	}
+	return rules
+}
+
+// CacheTTL is how long AI answers are reused; 0 when the cache is off.
+func (a AIConfig) CacheTTL() time.Duration {
+	switch {
+	case a.CacheMinutes < 0:
+		return 0
+	case a.CacheMinutes == 0:
+		return 10 * time.Minute
+	}
+	return time.Duration(a.CacheMinutes) * time.Minute
+}
+
+// Addr returns the address the API listens on.
+func (s ServeConfig) Addr() string {
+	if s.Listen != "" {
+		return s.Listen
+	}
+	return "127.0.0.1:8787"
+}
+
+// Weekday returns the digest day, Monday by default.
+func (d DigestConfig) Weekday() time.Weekday {
+	if wd, ok := weekdayKeys[strings.ToLower(d.Day)]; ok {
+		return wd
+	}
+	return time.Monday
+}
+
+// Clock returns the send time in minutes since midnight, 09:00 by default.
+func (d DigestConfig) Clock() int {
+	if m, err := parseClock(d.Time); err == nil {
+		return m
+	}
+	return 9 * 60
+}
+
+// CoverageConfig logs skipped windows to Clockify as breaks, for employers
+// that require every work hour to be accounted for.
+type CoverageConfig struct {
+	// Policy is "off" (default) or "full": log skipped windows on ProjectID.
+	Policy string `toml:"policy"`
+	// Days overrides the policy per weekday ("friday" = "off").
+	Days      map[string]string `toml:"days"`
+	ProjectID string            `toml:"project_id"` // the "Break/Unassigned" project
+	// Description is used for skips without a reason; default "Break".
+	Description string `toml:"description"`
+}
+
+// FormatConfig rewrites final entry descriptions, after AI generation and
+// after manual edits.
+type FormatConfig struct {
+	// Prefixes maps a project ID, project name or client name to a prefix
+	// such as "DEV/" or "🐛 ", added as is.
+	Prefixes            map[string]string `toml:"prefixes"`
+	StripTrailingPeriod bool              `toml:"strip_trailing_period"`
+	Case                string            `toml:"case"` // "", "sentence" or "title"
+	// MaxLength and Pattern are checked, not enforced: a description longer
+	// than MaxLength characters, or not matching the Pattern regex, is
+	// flagged in the suggestion view. 0 and "" turn the checks off.
+	MaxLength int    `toml:"max_length"`
+	Pattern   string `toml:"pattern"`
+	// RoundMinutes rounds the entries of a window to steps of this many
+	// minutes, by their cumulative ends so the total is rounded once. 0 or
+	// 1 turns rounding off.
+	RoundMinutes int    `toml:"round_minutes"`
+	Round        string `toml:"round"` // "nearest" (default), "up" or "down"
+}
+
+// MatcherConfig configures the rules-based project matcher that runs before
+// (or instead of) the AI provider.
+type MatcherConfig struct {
+	Enabled    bool              `toml:"enabled"`
+	AIFallback bool              `toml:"ai_fallback"` // call the AI when no rule matches confidently
+	Rules      []MatchRule       `toml:"rules"`
+	Repos      map[string]string `toml:"repos"` // GitHub repo name → project ID or name
+}
+
+// MatchRule maps a regular expression on the work description to a project.
+type MatchRule struct {
+	Pattern     string `toml:"pattern"`
+	Project     string `toml:"project"`     // project ID or name
+	Description string `toml:"description"` // optional; defaults to the user's description
 }
 
 type GitHubConfig struct {
@@ -26,6 +260,9 @@ type ClockifyConfig struct {
 	APIKey      string `toml:"api_key"`
 	WorkspaceID string `toml:"workspace_id"`
 	BaseURL     string `toml:"base_url"`
+	// OvertimeTagID is the Clockify tag added to entries logged with
+	// --overtime; empty adds none.
+	OvertimeTagID string `toml:"overtime_tag_id"`
 }
 
 type ScheduleConfig struct {
@@ -33,6 +270,31 @@ type ScheduleConfig struct {
 	WorkStart       string `toml:"work_start"`
 	WorkEnd         string `toml:"work_end"`
 	WorkDays        []int  `toml:"work_days"`
+	// Blocks replaces WorkStart/WorkEnd with several "HH:MM-HH:MM" blocks,
+	// e.g. to leave out lunch.
+	Blocks []string `toml:"blocks"`
+	// Days overrides the hours per weekday ("friday" = ["09:00-13:00"]).
+	Days map[string][]string `toml:"days"`
+	// Intervals overrides IntervalMinutes per weekday ("monday" = 30).
+	Intervals map[string]int `toml:"intervals"`
+	// SkipReasons are offered when a prompt is skipped; empty skips without asking.
+	SkipReasons []string `toml:"skip_reasons"`
+	// Holidays are days off ("YYYY-MM-DD" or "YYYY-MM-DD..YYYY-MM-DD") with no prompts.
+	Holidays []string `toml:"holidays"`
+	// HolidayCalendar is an ICS URL or file (e.g. a public holiday feed);
+	// any day with an event in it counts as a holiday.
+	HolidayCalendar string `toml:"holiday_calendar"`
+	// Timezone pins work hours to an IANA zone (e.g. "Europe/Stockholm").
+	// Empty follows the system zone, re-detected while the scheduler runs.
+	Timezone string `toml:"timezone"`
+	// AutoAcceptSeconds > 0 makes scheduler prompts accept a confident
+	// suggestion after that many seconds unless a key is pressed.
+	AutoAcceptSeconds int `toml:"auto_accept_seconds"`
+	// AutoAcceptConfidence is the confidence every allocation needs to auto-accept.
+	AutoAcceptConfidence float64 `toml:"auto_accept_confidence"`
+	// CrossMidnight is "keep" (default) to log an entry spanning midnight as
+	// one, or "split" to log one entry per calendar day.
+	CrossMidnight string `toml:"cross_midnight"`
 }
 
 type AIConfig struct {
@@ -41,32 +303,116 @@ type AIConfig struct {
 	APIKey           string `toml:"api_key"`
 	OpenRouterAPIKey string `toml:"openrouter_api_key"`
 	PromptFile       bool   `toml:"prompt_file"`
+	// MaxProjects caps the projects sent to the model; 0 sends all. The
+	// most relevant and recently used ones are kept.
+	MaxProjects int `toml:"max_projects"`
+	// Clients limits the projects sent to the model to these clients.
+	Clients []string `toml:"clients"`
+	// CacheMinutes is how long an answer is reused for the same input, so a
+	// retry after a crash doesn't wait on the model again. 0 keeps the
+	// default of 10; a negative value turns the cache off.
+	CacheMinutes int `toml:"cache_minutes"`
+	// Effort is the reasoning effort asked of the model: "minimal", "low",
+	// "medium" or "high". Empty leaves it to the model.
+	Effort string `toml:"effort"`
+	// Thinking turns the model's reasoning on or off; unset leaves it to
+	// the model.
+	Thinking *bool `toml:"thinking"`
+	// ExtraParams are added to every OpenRouter request body as is, e.g.
+	// temperature = 0.2 or "provider.order" = ["anthropic"].
+	ExtraParams map[string]any `toml:"extra_params"`
+	// DescriptionStyle and DescriptionRules are house rules for entry
+	// descriptions, e.g. "past tense" or "prefix with the JIRA key", passed
+	// to the model with every prompt.
+	DescriptionStyle string   `toml:"description_style"`
+	DescriptionRules []string `toml:"description_rules"`
+	// GranularityMinutes is what each suggested allocation's minutes must be
+	// a multiple of; a suggestion that isn't is sent back to the model. 0
+	// skips the check.
+	GranularityMinutes int `toml:"granularity_minutes"`
 }
 
 type NotifyConfig struct {
 	Enabled       bool  `toml:"enabled"`
 	ReminderDelay int   `toml:"reminder_delay_seconds"`
 	SnoozeOptions []int `toml:"snooze_options"`
+	// OpenOnClick opens a terminal running `clockr prompt-now` when the
+	// notification is clicked.
+	OpenOnClick bool `toml:"open_on_click"`
+	// TerminalCommand is the command run on click; {command} is replaced
+	// with the prompt-now command line. Empty uses a per-OS default.
+	TerminalCommand string `toml:"terminal_command"`
+	// Attention cues when a scheduler prompt or snooze reminder comes up:
+	// a terminal bell, a tmux status-line message, and the X11 urgency hint.
+	Bell        bool `toml:"bell"`
+	TmuxMessage bool `toml:"tmux_message"`
+	Urgent      bool `toml:"urgent"`
 }
 
 type CalendarConfig struct {
 	Enabled bool        `toml:"enabled"`
 	Source  string      `toml:"source"` // "graph" | ICS URL | file path
 	Graph   GraphConfig `toml:"graph"`
+	// SplitAtMeetings aligns suggested entries with meeting start/end times.
+	SplitAtMeetings bool `toml:"split_at_meetings"`
+	// WriteBack adds a private busy event to the Graph calendar for each
+	// logged entry.
+	WriteBack bool `toml:"write_back"`
 }
 
 type GraphConfig struct {
 	ClientID string `toml:"client_id"`
 	TenantID string `toml:"tenant_id"`
+	// Profiles sets another app or tenant for a named sign-in ('clockr
+	// calendar auth --profile NAME'), e.g. tenant_id = "consumers" for a
+	// personal Microsoft account. Unset fields fall back to the ones above.
+	Profiles map[string]GraphProfile `toml:"profiles"`
+	// WriteProfile is the sign-in whose calendar gets write-back events;
+	// empty for the default one.
+	WriteProfile string `toml:"write_profile"`
+}
+
+// GraphProfile is the app registration of one named Graph sign-in.
+type GraphProfile struct {
+	ClientID string `toml:"client_id"`
+	TenantID string `toml:"tenant_id"`
+}
+
+// App returns the client and tenant IDs to sign profile in with.
+func (g GraphConfig) App(profile string) (clientID, tenantID string) {
+	clientID, tenantID = g.ClientID, g.TenantID
+	if p, ok := g.Profiles[profile]; ok {
+		if p.ClientID != "" {
+			clientID = p.ClientID
+		}
+		if p.TenantID != "" {
+			tenantID = p.TenantID
+		}
+	}
+	return clientID, tenantID
+}
+
+// ValidGraphProfile reports whether name can name a Graph sign-in:
+// lowercase letters, digits, '-' and '_'. The empty name is the default
+// sign-in.
+func ValidGraphProfile(name string) bool {
+	for _, r := range name {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' && r != '_' {
+			return false
+		}
+	}
+	return true
 }
 
 func DefaultConfig() Config {
 	return Config{
 		Schedule: ScheduleConfig{
-			IntervalMinutes: 60,
-			WorkStart:       "09:00",
-			WorkEnd:         "17:00",
-			WorkDays:        []int{1, 2, 3, 4, 5},
+			IntervalMinutes:      60,
+			WorkStart:            "09:00",
+			WorkEnd:              "17:00",
+			WorkDays:             []int{1, 2, 3, 4, 5},
+			SkipReasons:          []string{"lunch", "personal", "meeting-overrun"},
+			AutoAcceptConfidence: 0.8,
 		},
 		AI: AIConfig{
 			Provider: "openrouter",
@@ -76,30 +422,19 @@ func DefaultConfig() Config {
 			Enabled:       true,
 			ReminderDelay: 300,
 			SnoozeOptions: []int{5, 15},
+			OpenOnClick:   true,
 		},
 		Calendar: CalendarConfig{
-			Enabled: false,
-			Source:  "",
+			Enabled:         false,
+			Source:          "",
+			SplitAtMeetings: true,
+		},
+		Matcher: MatcherConfig{
+			AIFallback: true,
 		},
 	}
 }
 
-func ConfigDir() (string, error) {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", fmt.Errorf("finding home directory: %w", err)
-	}
-	return filepath.Join(home, ".config", "clockr"), nil
-}
-
-func ConfigPath() (string, error) {
-	dir, err := ConfigDir()
-	if err != nil {
-		return "", err
-	}
-	return filepath.Join(dir, "config.toml"), nil
-}
-
 func Load() (*Config, error) {
 	path, err := ConfigPath()
 	if err != nil {
@@ -111,17 +446,23 @@ func Load() (*Config, error) {
 		if os.IsNotExist(err) {
 			cfg := DefaultConfig()
 			applyEnvOverrides(&cfg)
+			applyKeychain(&cfg)
 			return &cfg, nil
 		}
 		return nil, fmt.Errorf("reading config file: %w", err)
 	}
 
 	cfg := DefaultConfig()
-	if err := toml.Unmarshal(data, &cfg); err != nil {
-		return nil, fmt.Errorf("parsing config file: %w", err)
+	if problems := decodeStrict(data, &cfg); len(problems) > 0 {
+		return nil, &ValidationError{Path: path, Problems: problems}
 	}
 
 	applyEnvOverrides(&cfg)
+	applyKeychain(&cfg)
+
+	if problems := cfg.check(data); len(problems) > 0 {
+		return nil, &ValidationError{Path: path, Problems: problems}
+	}
 
 	return &cfg, nil
 }
@@ -129,6 +470,7 @@ func Load() (*Config, error) {
 func applyEnvOverrides(cfg *Config) {
 	if v := os.Getenv("CLOCKIFY_API_KEY"); v != "" {
 		cfg.Clockify.APIKey = v
+		cfg.setSecretSource(secrets.ClockifyAPIKey, "env")
 	}
 	if v := os.Getenv("CLOCKIFY_WORKSPACE_ID"); v != "" {
 		cfg.Clockify.WorkspaceID = v
@@ -136,8 +478,37 @@ func applyEnvOverrides(cfg *Config) {
 	if v := os.Getenv("CLOCKIFY_BASE_URL"); v != "" {
 		cfg.Clockify.BaseURL = v
 	}
+	if v := os.Getenv("HARVEST_ACCOUNT_ID"); v != "" {
+		cfg.Harvest.AccountID = v
+	}
+	if v := os.Getenv("HARVEST_TOKEN"); v != "" {
+		cfg.Harvest.Token = v
+		cfg.setSecretSource(secrets.HarvestToken, "env")
+	}
+	if v := os.Getenv("TOGGL_API_TOKEN"); v != "" {
+		cfg.Toggl.APIToken = v
+		cfg.setSecretSource(secrets.TogglAPIToken, "env")
+	}
+	if v := os.Getenv("TOGGL_WORKSPACE_ID"); v != "" {
+		cfg.Toggl.WorkspaceID = v
+	}
+	if v := os.Getenv("TEMPO_TOKEN"); v != "" {
+		cfg.Tempo.Token = v
+		cfg.setSecretSource(secrets.TempoToken, "env")
+	}
+	if v := os.Getenv("JIRA_URL"); v != "" {
+		cfg.Tempo.JiraURL = v
+	}
+	if v := os.Getenv("JIRA_EMAIL"); v != "" {
+		cfg.Tempo.JiraEmail = v
+	}
+	if v := os.Getenv("JIRA_API_TOKEN"); v != "" {
+		cfg.Tempo.JiraToken = v
+		cfg.setSecretSource(secrets.JiraAPIToken, "env")
+	}
 	if v := os.Getenv("GITHUB_TOKEN"); v != "" {
 		cfg.GitHub.Token = v
+		cfg.setSecretSource(secrets.GitHubToken, "env")
 	}
 	if v := os.Getenv("MSGRAPH_CLIENT_ID"); v != "" {
 		cfg.Calendar.Graph.ClientID = v
@@ -151,14 +522,189 @@ func applyEnvOverrides(cfg *Config) {
 	if v := os.Getenv("OPENROUTER_API_KEY"); v != "" {
 		cfg.AI.OpenRouterAPIKey = v
 	}
+	if v := os.Getenv("CLOCKR_SMTP_PASSWORD"); v != "" {
+		cfg.Digest.Email.Password = v
+	}
+	if v := os.Getenv("CLOCKR_SLACK_BOT_TOKEN"); v != "" {
+		cfg.Slack.BotToken = v
+	}
+	if v := os.Getenv("CLOCKR_SLACK_SIGNING_SECRET"); v != "" {
+		cfg.Slack.SigningSecret = v
+	}
+	if v := os.Getenv("CLOCKR_SERVE_TOKEN"); v != "" {
+		cfg.Serve.Token = v
+	}
+	if ReadOnlyFromEnv() {
+		cfg.ReadOnly = true
+	}
+}
+
+// ReadOnlyFromEnv reports whether CLOCKR_READ_ONLY asks for read-only mode.
+func ReadOnlyFromEnv() bool {
+	v := os.Getenv("CLOCKR_READ_ONLY")
+	return v == "1" || v == "true"
+}
+
+// applyKeychain fills credentials that are set neither in the file nor the
+// environment from the OS keychain. Values are cached for the process, so
+// reloads don't ask the keychain again.
+func applyKeychain(cfg *Config) {
+	if cfg.Clockify.APIKey == "" {
+		if v, err := secrets.GetCached(secrets.ClockifyAPIKey); err == nil {
+			cfg.Clockify.APIKey = v
+			cfg.setSecretSource(secrets.ClockifyAPIKey, "keychain")
+		}
+	}
+	if (cfg.Backend.Harvest() || cfg.MirrorsTo("harvest")) && cfg.Harvest.Token == "" {
+		if v, err := secrets.GetCached(secrets.HarvestToken); err == nil {
+			cfg.Harvest.Token = v
+			cfg.setSecretSource(secrets.HarvestToken, "keychain")
+		}
+	}
+	if (cfg.Backend.Toggl() || cfg.MirrorsTo("toggl")) && cfg.Toggl.APIToken == "" {
+		if v, err := secrets.GetCached(secrets.TogglAPIToken); err == nil {
+			cfg.Toggl.APIToken = v
+			cfg.setSecretSource(secrets.TogglAPIToken, "keychain")
+		}
+	}
+	tempo := cfg.Backend.Tempo() || cfg.MirrorsTo("tempo")
+	if tempo && cfg.Tempo.Token == "" {
+		if v, err := secrets.GetCached(secrets.TempoToken); err == nil {
+			cfg.Tempo.Token = v
+			cfg.setSecretSource(secrets.TempoToken, "keychain")
+		}
+	}
+	if tempo && cfg.Tempo.JiraToken == "" {
+		if v, err := secrets.GetCached(secrets.JiraAPIToken); err == nil {
+			cfg.Tempo.JiraToken = v
+			cfg.setSecretSource(secrets.JiraAPIToken, "keychain")
+		}
+	}
+	if cfg.GitHub.Token == "" {
+		if v, err := secrets.GetCached(secrets.GitHubToken); err == nil {
+			cfg.GitHub.Token = v
+			cfg.setSecretSource(secrets.GitHubToken, "keychain")
+		}
+	}
+	if cfg.Digest.Email.Host != "" && cfg.Digest.Email.Password == "" {
+		if v, err := secrets.GetCached(secrets.SMTPPassword); err == nil {
+			cfg.Digest.Email.Password = v
+			cfg.setSecretSource(secrets.SMTPPassword, "keychain")
+		}
+	}
+	if cfg.Slack.Enabled && cfg.Slack.BotToken == "" {
+		if v, err := secrets.GetCached(secrets.SlackBotToken); err == nil {
+			cfg.Slack.BotToken = v
+			cfg.setSecretSource(secrets.SlackBotToken, "keychain")
+		}
+	}
+	if cfg.Slack.Enabled && cfg.Slack.SigningSecret == "" {
+		if v, err := secrets.GetCached(secrets.SlackSigningSecret); err == nil {
+			cfg.Slack.SigningSecret = v
+			cfg.setSecretSource(secrets.SlackSigningSecret, "keychain")
+		}
+	}
+}
+
+// fileSecrets maps keychain names to where the same credential lives in config.toml.
+var fileSecrets = []struct {
+	name, table, key string
+}{
+	{secrets.ClockifyAPIKey, "clockify", "api_key"},
+	{secrets.GitHubToken, "github", "token"},
+	{secrets.HarvestToken, "harvest", "token"},
+	{secrets.TogglAPIToken, "toggl", "api_token"},
+	{secrets.TempoToken, "tempo", "token"},
+	{secrets.JiraAPIToken, "tempo", "jira_token"},
+}
+
+// MigrateSecrets moves plaintext credentials from config.toml into the OS
+// keychain and returns the names of the secrets it moved.
+func MigrateSecrets() ([]string, error) {
+	if !secrets.Available() {
+		return nil, secrets.ErrUnavailable
+	}
+	path, err := ConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading config: %w", err)
+	}
+	var file Config
+	if err := toml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parsing config: %w", err)
+	}
+	values := map[string]string{
+		secrets.ClockifyAPIKey: file.Clockify.APIKey,
+		secrets.GitHubToken:    file.GitHub.Token,
+		secrets.HarvestToken:   file.Harvest.Token,
+		secrets.TogglAPIToken:  file.Toggl.APIToken,
+		secrets.TempoToken:     file.Tempo.Token,
+		secrets.JiraAPIToken:   file.Tempo.JiraToken,
+	}
+
+	var moved []string
+	for _, fs := range fileSecrets {
+		v := values[fs.name]
+		if v == "" {
+			continue
+		}
+		if err := secrets.Set(fs.name, v); err != nil {
+			return moved, fmt.Errorf("storing %s in keychain: %w", fs.name, err)
+		}
+		if _, err := ClearSecret(fs.table, fs.key); err != nil {
+			return moved, err
+		}
+		moved = append(moved, fs.name)
+	}
+	return moved, nil
+}
+
+// ClearSecret replaces a plaintext credential in the config file with a
+// comment noting it now lives in the keychain. Other lines, including
+// comments, are left untouched. It reports whether the key was found.
+func ClearSecret(table, key string) (bool, error) {
+	path, err := ConfigPath()
+	if err != nil {
+		return false, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("reading config: %w", err)
+	}
+
+	lines := splitLines(data)
+	n := keyLine(lines, table, key)
+	if n == 0 {
+		return false, nil
+	}
+	lines[n-1] = fmt.Sprintf("# %s is stored in the OS keychain (clockr secrets status)", key)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, err
+	}
+	out := strings.Join(lines, "\n") + "\n"
+	if err := os.WriteFile(path, []byte(out), info.Mode().Perm()); err != nil {
+		return false, fmt.Errorf("writing config: %w", err)
+	}
+	return true, nil
 }
 
 func EnsureConfigDir() error {
-	dir, err := ConfigDir()
+	path, err := ConfigPath()
 	if err != nil {
 		return err
 	}
-	return os.MkdirAll(dir, 0755)
+	return os.MkdirAll(filepath.Dir(path), 0755)
 }
 
 // SaveGitHubRepos persists the selected GitHub repos to the config file
@@ -198,3 +744,139 @@ func SaveGitHubRepos(repos []string) error {
 	}
 	return os.WriteFile(path, out, 0644)
 }
+
+// TermsRule returns a matcher rule that maps any of terms, as whole words and
+// case-insensitively, to projectID.
+func TermsRule(projectID string, terms []string) MatchRule {
+	quoted := make([]string, len(terms))
+	for i, t := range terms {
+		quoted[i] = regexp.QuoteMeta(t)
+	}
+	return MatchRule{
+		Pattern: `(?i)\b(?:` + strings.Join(quoted, "|") + `)\b`,
+		Project: projectID,
+	}
+}
+
+// SaveProjectMappings appends rules to [matcher] rules, merges repos into
+// [matcher.repos] and enables the matcher, using the same read-modify-write
+// approach as SaveGitHubRepos. AI fallback is turned on unless it was set.
+func SaveProjectMappings(rules []MatchRule, repos map[string]string) error {
+	path, err := ConfigPath()
+	if err != nil {
+		return err
+	}
+
+	cfg := make(map[string]any)
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("reading config: %w", err)
+	}
+	if len(data) > 0 {
+		if err := toml.Unmarshal(data, &cfg); err != nil {
+			return fmt.Errorf("parsing config: %w", err)
+		}
+	}
+
+	matcher, ok := cfg["matcher"].(map[string]any)
+	if !ok {
+		matcher = make(map[string]any)
+	}
+	matcher["enabled"] = true
+	if _, set := matcher["ai_fallback"]; !set {
+		matcher["ai_fallback"] = true
+	}
+
+	existing, _ := matcher["rules"].([]any)
+	for _, r := range rules {
+		rule := map[string]any{"pattern": r.Pattern, "project": r.Project}
+		if r.Description != "" {
+			rule["description"] = r.Description
+		}
+		existing = append(existing, rule)
+	}
+	if len(existing) > 0 {
+		matcher["rules"] = existing
+	}
+
+	if len(repos) > 0 {
+		repoMap, ok := matcher["repos"].(map[string]any)
+		if !ok {
+			repoMap = make(map[string]any)
+		}
+		for repo, project := range repos {
+			repoMap[repo] = project
+		}
+		matcher["repos"] = repoMap
+	}
+	cfg["matcher"] = matcher
+
+	if err := EnsureConfigDir(); err != nil {
+		return err
+	}
+	out, err := toml.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf("marshaling config: %w", err)
+	}
+	return os.WriteFile(path, out, 0644)
+}
+
+// SaveWorkspaceMigration switches [clockify] workspace_id to workspaceID and
+// rewrites matcher rules and repos that point at an old project ID in
+// projectIDs (old → new) to the new ID. References by project name are left
+// alone. It returns how many references were rewritten.
+func SaveWorkspaceMigration(workspaceID string, projectIDs map[string]string) (int, error) {
+	path, err := ConfigPath()
+	if err != nil {
+		return 0, err
+	}
+
+	cfg := make(map[string]any)
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return 0, fmt.Errorf("reading config: %w", err)
+	}
+	if len(data) > 0 {
+		if err := toml.Unmarshal(data, &cfg); err != nil {
+			return 0, fmt.Errorf("parsing config: %w", err)
+		}
+	}
+
+	clockify, ok := cfg["clockify"].(map[string]any)
+	if !ok {
+		clockify = make(map[string]any)
+	}
+	clockify["workspace_id"] = workspaceID
+	cfg["clockify"] = clockify
+
+	rewritten := 0
+	if matcher, ok := cfg["matcher"].(map[string]any); ok {
+		rules, _ := matcher["rules"].([]any)
+		for _, r := range rules {
+			rule, ok := r.(map[string]any)
+			if !ok {
+				continue
+			}
+			if id, ok := rule["project"].(string); ok && projectIDs[id] != "" {
+				rule["project"] = projectIDs[id]
+				rewritten++
+			}
+		}
+		repos, _ := matcher["repos"].(map[string]any)
+		for repo, project := range repos {
+			if id, ok := project.(string); ok && projectIDs[id] != "" {
+				repos[repo] = projectIDs[id]
+				rewritten++
+			}
+		}
+	}
+
+	if err := EnsureConfigDir(); err != nil {
+		return 0, err
+	}
+	out, err := toml.Marshal(cfg)
+	if err != nil {
+		return 0, fmt.Errorf("marshaling config: %w", err)
+	}
+	return rewritten, os.WriteFile(path, out, 0644)
+}
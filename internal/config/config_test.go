@@ -0,0 +1,166 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/christopherklint97/clockr/internal/secrets"
+)
+
+func TestClearSecret(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	dir := filepath.Join(home, ".config", "clockr")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "config.toml")
+	orig := "[clockify]\napi_key = \"secret\"  # from settings\nworkspace_id = \"ws\"\n\n[ai]\napi_key = \"other\"\n"
+	if err := os.WriteFile(path, []byte(orig), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := ClearSecret("clockify", "api_key")
+	if err != nil || !found {
+		t.Fatalf("ClearSecret() = %v, %v; want true, nil", found, err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(data)
+	if strings.Contains(got, `"secret"`) {
+		t.Errorf("clockify key still present:\n%s", got)
+	}
+	if !strings.Contains(got, `workspace_id = "ws"`) || !strings.Contains(got, `api_key = "other"`) {
+		t.Errorf("unrelated lines changed:\n%s", got)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("permissions changed to %v", info.Mode().Perm())
+	}
+}
+
+// countingKeychain is a Memory keychain that counts reads per secret.
+type countingKeychain struct {
+	*secrets.Memory
+	gets map[string]int
+}
+
+func (c *countingKeychain) Get(name string) (string, error) {
+	c.gets[name]++
+	return c.Memory.Get(name)
+}
+
+func TestLoad_ReadsKeychainOnce(t *testing.T) {
+	t.Setenv("CLOCKR_HOME", t.TempDir())
+	kc := &countingKeychain{Memory: secrets.NewMemory(), gets: make(map[string]int)}
+	kc.Set(secrets.ClockifyAPIKey, "from-keychain")
+	prev := secrets.Default()
+	secrets.SetDefault(kc)
+	t.Cleanup(func() { secrets.SetDefault(prev) })
+
+	// Reloads, as the running scheduler does on every change.
+	for range 3 {
+		cfg, err := Load()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.Clockify.APIKey != "from-keychain" || cfg.SecretSource(secrets.ClockifyAPIKey) != "keychain" {
+			t.Fatalf("clockify key = %q from %q, want it from the keychain", cfg.Clockify.APIKey, cfg.SecretSource(secrets.ClockifyAPIKey))
+		}
+	}
+	for name, n := range kc.gets {
+		if n != 1 {
+			t.Errorf("keychain reads of %s = %d, want 1", name, n)
+		}
+	}
+}
+
+func TestSecretSource(t *testing.T) {
+	t.Setenv("CLOCKR_HOME", t.TempDir())
+	t.Setenv("HARVEST_TOKEN", "from-env")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Harvest.Token != "from-env" || cfg.SecretSource(secrets.HarvestToken) != "env" {
+		t.Errorf("harvest token = %q from %q, want it from env", cfg.Harvest.Token, cfg.SecretSource(secrets.HarvestToken))
+	}
+	if got := cfg.SecretSource(secrets.TogglAPIToken); got != "" {
+		t.Errorf("unset toggl token from %q, want \"\"", got)
+	}
+}
+
+func TestSaveProjectMappings(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("CLOCKR_HOME", home)
+	t.Setenv("CLOCKIFY_API_KEY", "")
+	path := filepath.Join(home, "config.toml")
+	orig := "[clockify]\napi_key = \"key\"\n\n[matcher]\nai_fallback = false\n\n[[matcher.rules]]\npattern = \"standup\"\nproject = \"p0\"\n"
+	if err := os.WriteFile(path, []byte(orig), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	rule := TermsRule("p1", []string{"Acme", "acme.io"})
+	if err := SaveProjectMappings([]MatchRule{rule}, map[string]string{"acme/api": "p1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := cfg.Matcher
+	if !m.Enabled || m.AIFallback {
+		t.Errorf("expected matcher enabled with ai_fallback left false, got %+v", m)
+	}
+	if len(m.Rules) != 2 || m.Rules[0].Project != "p0" || m.Rules[1] != rule {
+		t.Errorf("unexpected rules %+v", m.Rules)
+	}
+	if m.Repos["acme/api"] != "p1" {
+		t.Errorf("unexpected repos %+v", m.Repos)
+	}
+
+	re := regexp.MustCompile(rule.Pattern)
+	if !re.MatchString("call with ACME about billing") || !re.MatchString("deploy acme.io") || re.MatchString("acmeish") {
+		t.Errorf("pattern %q matches the wrong things", rule.Pattern)
+	}
+}
+
+func TestSaveWorkspaceMigration(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("CLOCKR_HOME", home)
+	t.Setenv("CLOCKIFY_API_KEY", "")
+	t.Setenv("CLOCKIFY_WORKSPACE_ID", "")
+	path := filepath.Join(home, "config.toml")
+	orig := "[clockify]\napi_key = \"key\"\nworkspace_id = \"old\"\n\n[matcher]\nenabled = true\n\n[[matcher.rules]]\npattern = \"standup\"\nproject = \"p1\"\n\n[[matcher.rules]]\npattern = \"lunch\"\nproject = \"Internal\"\n\n[matcher.repos]\n\"acme/api\" = \"p2\"\n"
+	if err := os.WriteFile(path, []byte(orig), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := SaveWorkspaceMigration("new", map[string]string{"p1": "n1", "p2": "n2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("rewrote %d references, want 2", n)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Clockify.WorkspaceID != "new" || cfg.Clockify.APIKey != "key" {
+		t.Errorf("unexpected clockify config %+v", cfg.Clockify)
+	}
+	if cfg.Matcher.Rules[0].Project != "n1" || cfg.Matcher.Rules[1].Project != "Internal" {
+		t.Errorf("unexpected rules %+v", cfg.Matcher.Rules)
+	}
+	if cfg.Matcher.Repos["acme/api"] != "n2" {
+		t.Errorf("unexpected repos %+v", cfg.Matcher.Repos)
+	}
+}
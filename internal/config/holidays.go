@@ -0,0 +1,41 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// parseHoliday parses a holidays entry: a single date "YYYY-MM-DD" or an
+// inclusive range "YYYY-MM-DD..YYYY-MM-DD".
+func parseHoliday(h string) (from, to time.Time, err error) {
+	first, last, isRange := strings.Cut(strings.TrimSpace(h), "..")
+	if from, err = time.ParseInLocation(time.DateOnly, strings.TrimSpace(first), time.UTC); err != nil {
+		return from, to, fmt.Errorf("%q is not a YYYY-MM-DD date or YYYY-MM-DD..YYYY-MM-DD range", h)
+	}
+	if !isRange {
+		return from, from, nil
+	}
+	if to, err = time.ParseInLocation(time.DateOnly, strings.TrimSpace(last), time.UTC); err != nil {
+		return from, to, fmt.Errorf("%q is not a YYYY-MM-DD date or YYYY-MM-DD..YYYY-MM-DD range", h)
+	}
+	if to.Before(from) {
+		return from, to, fmt.Errorf("%q ends before it starts", h)
+	}
+	return from, to, nil
+}
+
+// OnHoliday reports whether t's date, in t's location, is listed in Holidays.
+func (s ScheduleConfig) OnHoliday(t time.Time) bool {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	for _, h := range s.Holidays {
+		from, to, err := parseHoliday(h)
+		if err != nil {
+			continue
+		}
+		if !day.Before(from) && !day.After(to) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOnHoliday(t *testing.T) {
+	s := ScheduleConfig{Holidays: []string{"2026-12-24", "2026-07-06..2026-07-17"}}
+
+	tests := []struct {
+		date string
+		want bool
+	}{
+		{"2026-12-24", true},
+		{"2026-12-23", false},
+		{"2026-07-06", true},
+		{"2026-07-10", true},
+		{"2026-07-17", true},
+		{"2026-07-18", false},
+	}
+	for _, tt := range tests {
+		d, _ := time.ParseInLocation(time.DateOnly, tt.date, time.Local)
+		if got := s.OnHoliday(d.Add(15 * time.Hour)); got != tt.want {
+			t.Errorf("OnHoliday(%s) = %v, want %v", tt.date, got, tt.want)
+		}
+	}
+}
+
+func TestParseHoliday_Invalid(t *testing.T) {
+	for _, h := range []string{"24/12/2026", "2026-07-17..2026-07-06", "2026-07-06..later"} {
+		if _, _, err := parseHoliday(h); err == nil {
+			t.Errorf("expected %q to be rejected", h)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package config
+
+import "slices"
+
+// MirrorDestinations are the places entries can be mirrored to besides the
+// backend.
+var MirrorDestinations = []string{"clockify", "harvest", "toggl", "tempo", "csv"}
+
+// DefaultMirrorAttempts is how often a failed mirror write is tried before
+// clockr gives up on it, when [mirror] max_attempts is zero.
+const DefaultMirrorAttempts = 5
+
+// MirrorConfig copies every logged entry to more destinations than the
+// backend, e.g. a CSV ledger and Tempo next to Clockify. Each destination
+// uses its own section ([harvest], [toggl], [tempo], [clockify]) for
+// credentials.
+type MirrorConfig struct {
+	To          []string `toml:"to"`           // destinations, from MirrorDestinations
+	CSV         string   `toml:"csv"`          // ledger file the "csv" destination appends to
+	MaxAttempts int      `toml:"max_attempts"` // default DefaultMirrorAttempts
+	// Projects maps a project ID or name to the project, by ID or name, its
+	// entries go to in the backend destinations. Unmapped projects are
+	// matched by name.
+	Projects map[string]string `toml:"projects"`
+}
+
+// Attempts is how often a failed write is tried before giving up.
+func (m MirrorConfig) Attempts() int {
+	if m.MaxAttempts > 0 {
+		return m.MaxAttempts
+	}
+	return DefaultMirrorAttempts
+}
+
+// Mirrors returns the destinations entries are mirrored to: [mirror] to,
+// plus "tempo" when [tempo] mirror is set, without the backend itself.
+func (c *Config) Mirrors() []string {
+	var out []string
+	add := func(d string) {
+		if d != c.backendName() && !slices.Contains(out, d) {
+			out = append(out, d)
+		}
+	}
+	for _, d := range c.Mirror.To {
+		add(d)
+	}
+	if c.Tempo.Mirror {
+		add("tempo")
+	}
+	return out
+}
+
+// MirrorsTo reports whether entries are mirrored to destination.
+func (c *Config) MirrorsTo(destination string) bool {
+	return slices.Contains(c.Mirrors(), destination)
+}
+
+func (c *Config) backendName() string {
+	if c.Backend.Clockify() {
+		return "clockify"
+	}
+	return c.Backend.Type
+}
@@ -0,0 +1,171 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// By default config, data, state and cache all live in ~/.config/clockr.
+// Setting CLOCKR_HOME puts everything in that directory instead; otherwise
+// XDG_CONFIG_HOME, XDG_DATA_HOME, XDG_STATE_HOME and XDG_CACHE_HOME are
+// honoured when set.
+
+// configPathOverride is set by the --config flag.
+var configPathOverride string
+
+// SetConfigPath makes ConfigPath return path instead of the default location.
+func SetConfigPath(path string) {
+	configPathOverride = path
+}
+
+func legacyDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("finding home directory: %w", err)
+	}
+	return filepath.Join(home, ".config", "clockr"), nil
+}
+
+func dirFor(xdgVar string) (string, error) {
+	if v := os.Getenv("CLOCKR_HOME"); v != "" {
+		return v, nil
+	}
+	// The XDG spec says relative paths are invalid and must be ignored.
+	if v := os.Getenv(xdgVar); v != "" && filepath.IsAbs(v) {
+		return filepath.Join(v, "clockr"), nil
+	}
+	return legacyDir()
+}
+
+// ConfigDir holds config.toml.
+func ConfigDir() (string, error) {
+	return dirFor("XDG_CONFIG_HOME")
+}
+
+// DataDir holds the SQLite database and cached Graph tokens.
+func DataDir() (string, error) {
+	return dirFor("XDG_DATA_HOME")
+}
+
+// StateDir holds the scheduler PID file and prompt-file scratch files.
+func StateDir() (string, error) {
+	return dirFor("XDG_STATE_HOME")
+}
+
+// CacheDir holds data that can be fetched again, such as the ETag cache of
+// API responses.
+func CacheDir() (string, error) {
+	return dirFor("XDG_CACHE_HOME")
+}
+
+func ConfigPath() (string, error) {
+	if configPathOverride != "" {
+		return configPathOverride, nil
+	}
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.toml"), nil
+}
+
+// legacyFiles maps files in ~/.config/clockr to the directory they now belong in.
+var legacyFiles = []struct {
+	name string
+	dir  func() (string, error)
+}{
+	{"config.toml", ConfigDir},
+	{"clockr.db", DataDir},
+	{"clockr.db-wal", DataDir},
+	{"clockr.db-shm", DataDir},
+	{"msgraph_tokens.json", DataDir},
+	{"tmp", StateDir},
+}
+
+// MigrateLegacyFiles moves files from ~/.config/clockr into the directories
+// selected by the XDG variables. Files that already exist at the destination
+// are left alone. CLOCKR_HOME is never migrated into: it is often pointed at
+// a scratch directory for a one-off run, and moving real data there would
+// lose it. It returns the destinations it wrote.
+func MigrateLegacyFiles() ([]string, error) {
+	if os.Getenv("CLOCKR_HOME") != "" {
+		return nil, nil
+	}
+	legacy, err := legacyDir()
+	if err != nil {
+		return nil, err
+	}
+
+	// Don't move the database out from under a running scheduler.
+	if _, err := os.Stat(filepath.Join(legacy, "clockr.pid")); err == nil {
+		return nil, nil
+	}
+
+	var moved []string
+	for _, f := range legacyFiles {
+		dir, err := f.dir()
+		if err != nil {
+			return moved, err
+		}
+		if filepath.Clean(dir) == filepath.Clean(legacy) {
+			continue
+		}
+		src := filepath.Join(legacy, f.name)
+		dst := filepath.Join(dir, f.name)
+		if _, err := os.Lstat(src); err != nil {
+			continue
+		}
+		if _, err := os.Lstat(dst); err == nil {
+			continue
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return moved, fmt.Errorf("creating %s: %w", dir, err)
+		}
+		if err := moveFile(src, dst); err != nil {
+			return moved, fmt.Errorf("moving %s to %s: %w", src, dst, err)
+		}
+		moved = append(moved, dst)
+	}
+	return moved, nil
+}
+
+// moveFile renames src to dst, copying across filesystems when needed.
+// Directories can only be renamed.
+func moveFile(src, dst string) error {
+	err := os.Rename(src, dst)
+	if err == nil || !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("cannot move directory across filesystems")
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(dst)
+		return err
+	}
+	return os.Remove(src)
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDirs(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("CLOCKR_HOME", "")
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("XDG_DATA_HOME", filepath.Join(home, "share"))
+	t.Setenv("XDG_STATE_HOME", "relative/ignored")
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, "cache"))
+
+	legacy := filepath.Join(home, ".config", "clockr")
+	if dir, _ := ConfigDir(); dir != legacy {
+		t.Errorf("ConfigDir() = %q, want %q", dir, legacy)
+	}
+	if dir, _ := DataDir(); dir != filepath.Join(home, "share", "clockr") {
+		t.Errorf("DataDir() = %q, want XDG_DATA_HOME/clockr", dir)
+	}
+	if dir, _ := StateDir(); dir != legacy {
+		t.Errorf("StateDir() = %q, want legacy dir for relative XDG_STATE_HOME", dir)
+	}
+	if dir, _ := CacheDir(); dir != filepath.Join(home, "cache", "clockr") {
+		t.Errorf("CacheDir() = %q, want XDG_CACHE_HOME/clockr", dir)
+	}
+
+	t.Setenv("CLOCKR_HOME", filepath.Join(home, "alt"))
+	if dir, _ := DataDir(); dir != filepath.Join(home, "alt") {
+		t.Errorf("DataDir() with CLOCKR_HOME = %q", dir)
+	}
+}
+
+func TestMigrateLegacyFiles(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("CLOCKR_HOME", "")
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("XDG_DATA_HOME", filepath.Join(home, "share"))
+	t.Setenv("XDG_STATE_HOME", "")
+
+	legacy := filepath.Join(home, ".config", "clockr")
+	if err := os.MkdirAll(legacy, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"config.toml", "clockr.db"} {
+		if err := os.WriteFile(filepath.Join(legacy, name), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	moved, err := MigrateLegacyFiles()
+	if err != nil {
+		t.Fatalf("MigrateLegacyFiles: %v", err)
+	}
+	want := filepath.Join(home, "share", "clockr", "clockr.db")
+	if len(moved) != 1 || moved[0] != want {
+		t.Fatalf("moved = %v, want [%s]", moved, want)
+	}
+	if _, err := os.Stat(filepath.Join(legacy, "config.toml")); err != nil {
+		t.Errorf("config.toml should stay in the legacy config dir: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(legacy, "clockr.db")); !os.IsNotExist(err) {
+		t.Errorf("clockr.db should have been moved, stat err = %v", err)
+	}
+}
@@ -0,0 +1,181 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// RecurringEntry is a [[recurring]] entry that happens on a fixed schedule,
+// such as a daily standup. The scheduler logs it once it has ended, without
+// prompting, or with Suggest only mentions it to the AI in the prompt that
+// covers it.
+type RecurringEntry struct {
+	Name        string `toml:"name"`    // identifies the entry in 'clockr recurring'
+	Project     string `toml:"project"` // project ID or name
+	Description string `toml:"description"`
+	Time        string `toml:"time"` // start, HH:MM
+	Minutes     int    `toml:"minutes"`
+	// Days are weekday names; empty means the [schedule] work days.
+	Days     []string `toml:"days"`
+	Suggest  bool     `toml:"suggest"`
+	Disabled bool     `toml:"disabled"`
+}
+
+// On reports whether r happens on t's weekday.
+func (r RecurringEntry) On(s ScheduleConfig, t time.Time) bool {
+	if len(r.Days) == 0 {
+		return s.IsWorkDay(t.Weekday())
+	}
+	for _, d := range r.Days {
+		if wd, ok := weekdayKeys[strings.ToLower(d)]; ok && wd == t.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+// At returns when r happens on day, in day's location.
+func (r RecurringEntry) At(day time.Time) (start, end time.Time) {
+	m, _ := parseClock(r.Time)
+	start = time.Date(day.Year(), day.Month(), day.Day(), m/60, m%60, 0, 0, day.Location())
+	return start, start.Add(time.Duration(r.Minutes) * time.Minute)
+}
+
+// Text returns the description to log, falling back to the name.
+func (r RecurringEntry) Text() string {
+	if r.Description != "" {
+		return r.Description
+	}
+	return r.Name
+}
+
+// When describes r's schedule, e.g. "09:15 for 15 min on work days".
+func (r RecurringEntry) When() string {
+	days := "work days"
+	if len(r.Days) > 0 {
+		days = strings.Join(r.Days, ", ")
+	}
+	return fmt.Sprintf("%s for %d min on %s", r.Time, r.Minutes, days)
+}
+
+// check reports what is wrong with r, the entry at index i, through add.
+// seen collects the names of the entries before it.
+func (r RecurringEntry) check(i int, seen map[string]bool, add func(key, msg string)) {
+	label := fmt.Sprintf("entry %d", i+1)
+	if r.Name != "" {
+		label = fmt.Sprintf("%q", r.Name)
+	}
+	switch name := strings.ToLower(r.Name); {
+	case name == "":
+		add("name", label+" has no name")
+	case seen[name]:
+		add("name", fmt.Sprintf("the name %q is used twice", r.Name))
+	default:
+		seen[name] = true
+	}
+	if r.Project == "" {
+		add("project", label+" has no project")
+	}
+	if _, err := parseClock(r.Time); err != nil {
+		add("time", fmt.Sprintf("%s: %v", label, err))
+	}
+	if r.Minutes <= 0 || r.Minutes > 24*60 {
+		add("minutes", fmt.Sprintf("%s: must be between 1 and 1440, got %d", label, r.Minutes))
+	}
+	for _, d := range r.Days {
+		if _, ok := weekdayKeys[strings.ToLower(d)]; !ok {
+			add("days", fmt.Sprintf("%s: %q is not a weekday name (monday … sunday)", label, d))
+		}
+	}
+}
+
+// CheckRecurring lists what is wrong with r as a new entry after existing.
+func CheckRecurring(r RecurringEntry, existing []RecurringEntry) []string {
+	seen := make(map[string]bool)
+	for _, e := range existing {
+		seen[strings.ToLower(e.Name)] = true
+	}
+	var problems []string
+	r.check(len(existing), seen, func(key, msg string) { problems = append(problems, key+": "+msg) })
+	return problems
+}
+
+// AddRecurring appends r to [[recurring]], using the same read-modify-write
+// approach as SaveGitHubRepos.
+func AddRecurring(r RecurringEntry) error {
+	return editConfig(func(cfg map[string]any) error {
+		entries, _ := cfg["recurring"].([]any)
+		entry := map[string]any{
+			"name":    r.Name,
+			"project": r.Project,
+			"time":    r.Time,
+			"minutes": r.Minutes,
+		}
+		if r.Description != "" {
+			entry["description"] = r.Description
+		}
+		if len(r.Days) > 0 {
+			entry["days"] = r.Days
+		}
+		if r.Suggest {
+			entry["suggest"] = true
+		}
+		cfg["recurring"] = append(entries, entry)
+		return nil
+	})
+}
+
+// SetRecurringDisabled turns the recurring entry named name off or back on.
+func SetRecurringDisabled(name string, disabled bool) error {
+	return editConfig(func(cfg map[string]any) error {
+		entries, _ := cfg["recurring"].([]any)
+		for _, e := range entries {
+			entry, ok := e.(map[string]any)
+			if n, _ := entry["name"].(string); ok && strings.EqualFold(n, name) {
+				if disabled {
+					entry["disabled"] = true
+				} else {
+					delete(entry, "disabled")
+				}
+				return nil
+			}
+		}
+		return fmt.Errorf("no recurring entry named %q", name)
+	})
+}
+
+// editConfig reads the config file into a generic map, applies edit and
+// writes it back.
+func editConfig(edit func(cfg map[string]any) error) error {
+	path, err := ConfigPath()
+	if err != nil {
+		return err
+	}
+
+	cfg := make(map[string]any)
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("reading config: %w", err)
+	}
+	if len(data) > 0 {
+		if err := toml.Unmarshal(data, &cfg); err != nil {
+			return fmt.Errorf("parsing config: %w", err)
+		}
+	}
+	if err := edit(cfg); err != nil {
+		return err
+	}
+
+	if err := EnsureConfigDir(); err != nil {
+		return err
+	}
+	out, err := toml.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf("marshaling config: %w", err)
+	}
+	return os.WriteFile(path, out, 0644)
+}
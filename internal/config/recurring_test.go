@@ -0,0 +1,44 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecurringEntry_OnAndAt(t *testing.T) {
+	s := ScheduleConfig{WorkDays: []int{1, 2, 3, 4, 5}}
+	monday := time.Date(2026, 3, 2, 14, 0, 0, 0, time.UTC)
+	saturday := monday.AddDate(0, 0, 5)
+
+	standup := RecurringEntry{Name: "standup", Time: "09:15", Minutes: 15}
+	if !standup.On(s, monday) || standup.On(s, saturday) {
+		t.Error("without days the entry should follow the work days")
+	}
+	start, end := standup.At(monday)
+	if start.Format("2006-01-02 15:04") != "2026-03-02 09:15" || end.Sub(start) != 15*time.Minute {
+		t.Errorf("At = %s–%s", start, end)
+	}
+
+	retro := RecurringEntry{Name: "retro", Time: "15:00", Minutes: 60, Days: []string{"Friday"}}
+	if retro.On(s, monday) || !retro.On(s, monday.AddDate(0, 0, 4)) {
+		t.Error("retro should happen on Fridays only")
+	}
+	if got := retro.When(); got != "15:00 for 60 min on Friday" {
+		t.Errorf("When = %q", got)
+	}
+}
+
+func TestCheckRecurring(t *testing.T) {
+	existing := []RecurringEntry{{Name: "Standup", Project: "p1", Time: "09:15", Minutes: 15}}
+	if got := CheckRecurring(RecurringEntry{Name: "retro", Project: "p1", Time: "15:00", Minutes: 60}, existing); got != nil {
+		t.Errorf("valid entry flagged: %v", got)
+	}
+
+	got := strings.Join(CheckRecurring(RecurringEntry{Name: "standup", Time: "9:15", Days: []string{"funday"}}, existing), "\n")
+	for _, want := range []string{`name: the name "standup" is used twice`, "project:", "time:", "minutes:", `"funday" is not a weekday name`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in:\n%s", want, got)
+		}
+	}
+}
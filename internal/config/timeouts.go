@@ -0,0 +1,65 @@
+package config
+
+import "time"
+
+// Default timeouts used when [timeouts] leaves a value at zero.
+const (
+	DefaultClockifyTimeout = 30 * time.Second
+	DefaultContextTimeout  = 15 * time.Second
+	DefaultAITimeout       = 2 * time.Minute
+	DefaultHarvestTimeout  = 30 * time.Second
+	DefaultTogglTimeout    = 30 * time.Second
+	DefaultTempoTimeout    = 30 * time.Second
+)
+
+// timeoutOverride is set from the global --timeout flag and replaces every
+// configured timeout.
+var timeoutOverride time.Duration
+
+// SetTimeoutOverride makes every timeout d, whatever the config says. Zero
+// clears the override.
+func SetTimeoutOverride(d time.Duration) {
+	timeoutOverride = d
+}
+
+// Clockify is the limit for a single Clockify API request.
+func (t TimeoutsConfig) Clockify() time.Duration {
+	return t.pick(t.ClockifySeconds, DefaultClockifyTimeout)
+}
+
+// Context is the limit for fetching calendar events, GitHub activity and
+// other context for a prompt.
+func (t TimeoutsConfig) Context() time.Duration {
+	return t.pick(t.ContextSeconds, DefaultContextTimeout)
+}
+
+// AI is the limit for an AI call; for streaming providers it is how long the
+// stream may stay silent.
+func (t TimeoutsConfig) AI() time.Duration {
+	return t.pick(t.AISeconds, DefaultAITimeout)
+}
+
+// Harvest is the limit for a single Harvest API request.
+func (t TimeoutsConfig) Harvest() time.Duration {
+	return t.pick(t.HarvestSeconds, DefaultHarvestTimeout)
+}
+
+// Toggl is the limit for a single Toggl Track API request.
+func (t TimeoutsConfig) Toggl() time.Duration {
+	return t.pick(t.TogglSeconds, DefaultTogglTimeout)
+}
+
+// Tempo is the limit for a single Tempo or Jira API request.
+func (t TimeoutsConfig) Tempo() time.Duration {
+	return t.pick(t.TempoSeconds, DefaultTempoTimeout)
+}
+
+func (t TimeoutsConfig) pick(seconds int, def time.Duration) time.Duration {
+	if timeoutOverride > 0 {
+		return timeoutOverride
+	}
+	if seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return def
+}
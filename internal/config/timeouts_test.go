@@ -0,0 +1,32 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeouts(t *testing.T) {
+	var cfg TimeoutsConfig
+	if cfg.Clockify() != DefaultClockifyTimeout || cfg.Context() != DefaultContextTimeout || cfg.AI() != DefaultAITimeout {
+		t.Errorf("zero values should use the defaults")
+	}
+
+	cfg = TimeoutsConfig{ClockifySeconds: 5, AISeconds: 300}
+	if cfg.Clockify() != 5*time.Second || cfg.AI() != 5*time.Minute || cfg.Context() != DefaultContextTimeout {
+		t.Errorf("configured values: clockify %s, context %s, ai %s", cfg.Clockify(), cfg.Context(), cfg.AI())
+	}
+	// The other backends don't follow the Clockify timeout.
+	if cfg.Harvest() != DefaultHarvestTimeout || cfg.Toggl() != DefaultTogglTimeout || cfg.Tempo() != DefaultTempoTimeout {
+		t.Errorf("backend timeouts: harvest %s, toggl %s, tempo %s", cfg.Harvest(), cfg.Toggl(), cfg.Tempo())
+	}
+	cfg.HarvestSeconds, cfg.TogglSeconds, cfg.TempoSeconds = 60, 90, 120
+	if cfg.Harvest() != time.Minute || cfg.Toggl() != 90*time.Second || cfg.Tempo() != 2*time.Minute {
+		t.Errorf("configured backend timeouts: harvest %s, toggl %s, tempo %s", cfg.Harvest(), cfg.Toggl(), cfg.Tempo())
+	}
+
+	SetTimeoutOverride(45 * time.Second)
+	defer SetTimeoutOverride(0)
+	if cfg.Clockify() != 45*time.Second || cfg.Context() != 45*time.Second || cfg.AI() != 45*time.Second {
+		t.Errorf("--timeout should override every value")
+	}
+}
@@ -0,0 +1,428 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// Problem is a single invalid setting. Line is 1-based and 0 when the value
+// did not come from the file (defaults or environment variables).
+type Problem struct {
+	Line    int
+	Source  string // the offending line from the file, if known
+	Message string
+}
+
+func (p Problem) String() string {
+	if p.Line == 0 {
+		return p.Message
+	}
+	return fmt.Sprintf("line %d: %s\n    %s", p.Line, p.Message, p.Source)
+}
+
+// ValidationError reports every problem found in a config file at once.
+type ValidationError struct {
+	Path     string
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s has %d problem(s):", e.Path, len(e.Problems))
+	for _, p := range e.Problems {
+		b.WriteString("\n  ")
+		b.WriteString(p.String())
+	}
+	return b.String()
+}
+
+var hhmm = regexp.MustCompile(`^([01][0-9]|2[0-3]):[0-5][0-9]$`)
+
+// decodeStrict parses data into cfg, rejecting keys clockr doesn't know.
+func decodeStrict(data []byte, cfg *Config) []Problem {
+	dec := toml.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(cfg)
+	if err == nil {
+		return nil
+	}
+
+	lines := splitLines(data)
+	var strict *toml.StrictMissingError
+	if errors.As(err, &strict) {
+		problems := make([]Problem, 0, len(strict.Errors))
+		for _, e := range strict.Errors {
+			row, _ := e.Position()
+			problems = append(problems, problemAt(lines, row, fmt.Sprintf("unknown key %q", strings.Join(e.Key(), "."))))
+		}
+		return problems
+	}
+	var decErr *toml.DecodeError
+	if errors.As(err, &decErr) {
+		row, _ := decErr.Position()
+		return []Problem{problemAt(lines, row, strings.TrimPrefix(decErr.Error(), "toml: "))}
+	}
+	return []Problem{{Message: err.Error()}}
+}
+
+// check validates setting values. data is the raw file, used to point each
+// problem at the line the setting was read from; it may be nil.
+func (c *Config) check(data []byte) []Problem {
+	lines := splitLines(data)
+	var problems []Problem
+	add := func(table, key, msg string) {
+		problems = append(problems, problemAt(lines, keyLine(lines, table, key), table+"."+key+": "+msg))
+	}
+
+	s := c.Schedule
+	if s.IntervalMinutes <= 0 {
+		add("schedule", "interval_minutes", fmt.Sprintf("must be greater than 0, got %d", s.IntervalMinutes))
+	}
+	startOK := hhmm.MatchString(s.WorkStart)
+	if !startOK {
+		add("schedule", "work_start", fmt.Sprintf("expected 24-hour HH:MM, got %q", s.WorkStart))
+	}
+	endOK := hhmm.MatchString(s.WorkEnd)
+	if !endOK {
+		add("schedule", "work_end", fmt.Sprintf("expected 24-hour HH:MM, got %q", s.WorkEnd))
+	}
+	// Zero-padded HH:MM strings compare in time order.
+	if startOK && endOK && s.WorkEnd <= s.WorkStart {
+		add("schedule", "work_end", fmt.Sprintf("%s is not after work_start %s", s.WorkEnd, s.WorkStart))
+	}
+	if len(s.Blocks) > 0 {
+		if _, err := parseBlocks(s.Blocks); err != nil {
+			add("schedule", "blocks", err.Error())
+		}
+	}
+	for key, list := range s.Days {
+		if _, ok := weekdayKeys[strings.ToLower(key)]; !ok {
+			add("schedule.days", key, fmt.Sprintf("%q is not a weekday name (monday … sunday)", key))
+			continue
+		}
+		if _, err := parseBlocks(list); err != nil {
+			add("schedule.days", key, err.Error())
+		}
+	}
+	for key, m := range s.Intervals {
+		if _, ok := weekdayKeys[strings.ToLower(key)]; !ok {
+			add("schedule.intervals", key, fmt.Sprintf("%q is not a weekday name (monday … sunday)", key))
+		} else if m <= 0 {
+			add("schedule.intervals", key, fmt.Sprintf("must be greater than 0, got %d", m))
+		}
+	}
+	if len(s.WorkDays) == 0 {
+		add("schedule", "work_days", "no work days set — the scheduler would never prompt")
+	}
+	for _, d := range s.WorkDays {
+		if d < 1 || d > 7 {
+			add("schedule", "work_days", fmt.Sprintf("%d is out of range (1 = Monday … 7 = Sunday)", d))
+		}
+	}
+
+	for _, h := range s.Holidays {
+		if _, _, err := parseHoliday(h); err != nil {
+			add("schedule", "holidays", err.Error())
+		}
+	}
+
+	if s.Timezone != "" {
+		if _, err := time.LoadLocation(s.Timezone); err != nil {
+			add("schedule", "timezone", fmt.Sprintf("unknown time zone %q (use an IANA name like Europe/Stockholm)", s.Timezone))
+		}
+	}
+	if s.AutoAcceptSeconds < 0 {
+		add("schedule", "auto_accept_seconds", fmt.Sprintf("must be 0 (off) or positive, got %d", s.AutoAcceptSeconds))
+	}
+	if s.AutoAcceptConfidence <= 0 || s.AutoAcceptConfidence > 1 {
+		add("schedule", "auto_accept_confidence", fmt.Sprintf("must be between 0 and 1, got %g", s.AutoAcceptConfidence))
+	}
+	if s.CrossMidnight != "" && s.CrossMidnight != "keep" && s.CrossMidnight != "split" {
+		add("schedule", "cross_midnight", fmt.Sprintf(`must be "keep" or "split", got %q`, s.CrossMidnight))
+	}
+
+	for _, r := range s.SkipReasons {
+		if strings.TrimSpace(r) == "" {
+			add("schedule", "skip_reasons", "skip reasons must not be empty")
+		}
+	}
+
+	for _, m := range c.Notifications.SnoozeOptions {
+		if m <= 0 {
+			add("notifications", "snooze_options", fmt.Sprintf("snooze minutes must be positive, got %d", m))
+		}
+	}
+	if t := c.Notifications.TerminalCommand; t != "" && !strings.Contains(t, "{command}") {
+		add("notifications", "terminal_command", "must contain {command}, which is replaced with the clockr prompt-now command line")
+	}
+
+	cal := c.Calendar
+	if cal.Enabled && cal.Source == "" {
+		add("calendar", "source", `calendar is enabled but source is empty — set "graph", an ICS URL, or a file path`)
+	}
+	if cal.Enabled && cal.Source == "graph" {
+		if cal.Graph.ClientID == "" {
+			add("calendar.graph", "client_id", `required when source = "graph" (or set MSGRAPH_CLIENT_ID)`)
+		}
+		if cal.Graph.TenantID == "" {
+			add("calendar.graph", "tenant_id", `required when source = "graph" (or set MSGRAPH_TENANT_ID)`)
+		}
+	}
+	if cal.WriteBack {
+		if cal.Source != "graph" {
+			add("calendar", "write_back", `needs source = "graph" (ICS calendars are read-only)`)
+		} else if cal.Graph.ClientID == "" || cal.Graph.TenantID == "" {
+			add("calendar", "write_back", "needs [calendar.graph] client_id and tenant_id")
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(cal.Graph.Profiles)) {
+		if name == "" || !ValidGraphProfile(name) {
+			add("calendar.graph.profiles", name, "profile names use lowercase letters, digits, '-' and '_'")
+		}
+	}
+	if !ValidGraphProfile(cal.Graph.WriteProfile) {
+		add("calendar.graph", "write_profile", fmt.Sprintf("%q is not a profile name: use lowercase letters, digits, '-' and '_'", cal.Graph.WriteProfile))
+	}
+	if cal.Enabled && cal.Source != "graph" && keyLine(lines, "calendar.graph", "client_id") > 0 {
+		add("calendar.graph", "client_id", fmt.Sprintf("[calendar.graph] is set but source is %q, so it is ignored", cal.Source))
+	}
+
+	for _, t := range []struct {
+		key   string
+		value int
+	}{
+		{"clockify_seconds", c.Timeouts.ClockifySeconds},
+		{"context_seconds", c.Timeouts.ContextSeconds},
+		{"ai_seconds", c.Timeouts.AISeconds},
+		{"harvest_seconds", c.Timeouts.HarvestSeconds},
+		{"toggl_seconds", c.Timeouts.TogglSeconds},
+		{"tempo_seconds", c.Timeouts.TempoSeconds},
+	} {
+		if t.value < 0 {
+			add("timeouts", t.key, fmt.Sprintf("must be 0 (default) or positive, got %d", t.value))
+		}
+	}
+
+	switch c.AI.Effort {
+	case "", "minimal", "low", "medium", "high":
+	default:
+		add("ai", "effort", fmt.Sprintf(`must be "minimal", "low", "medium" or "high", got %q`, c.AI.Effort))
+	}
+	if c.AI.GranularityMinutes < 0 || c.AI.GranularityMinutes > 60 {
+		add("ai", "granularity_minutes", fmt.Sprintf("must be between 0 (no check) and 60, got %d", c.AI.GranularityMinutes))
+	}
+	if c.AI.MaxProjects < 0 {
+		add("ai", "max_projects", fmt.Sprintf("must be 0 (all) or positive, got %d", c.AI.MaxProjects))
+	}
+
+	cov := c.Coverage
+	if cov.Policy != "" && cov.Policy != "off" && cov.Policy != "full" {
+		add("coverage", "policy", fmt.Sprintf(`must be "off" or "full", got %q`, cov.Policy))
+	}
+	for key, p := range cov.Days {
+		if _, ok := weekdayKeys[strings.ToLower(key)]; !ok {
+			add("coverage.days", key, fmt.Sprintf("%q is not a weekday name (monday … sunday)", key))
+		} else if p != "off" && p != "full" {
+			add("coverage.days", key, fmt.Sprintf(`must be "off" or "full", got %q`, p))
+		}
+	}
+	if cov.ProjectID == "" {
+		for wd := time.Sunday; wd <= time.Saturday; wd++ {
+			if cov.PolicyFor(wd) == "full" {
+				add("coverage", "project_id", "required when skipped windows are logged as breaks")
+				break
+			}
+		}
+	}
+
+	if d := c.Digest; d.Enabled {
+		if _, ok := weekdayKeys[strings.ToLower(d.Day)]; d.Day != "" && !ok {
+			add("digest", "day", fmt.Sprintf("%q is not a weekday name (monday … sunday)", d.Day))
+		}
+		if d.Time != "" && !hhmm.MatchString(d.Time) {
+			add("digest", "time", fmt.Sprintf("expected 24-hour HH:MM, got %q", d.Time))
+		}
+		if !d.Notify && d.File == "" && len(d.Email.To) == 0 {
+			add("digest", "enabled", "set notify, file or email.to — the digest has nowhere to go")
+		}
+		if len(d.Email.To) > 0 && (d.Email.Host == "" || d.Email.From == "") {
+			add("digest.email", "host", "host and from are required to send the digest by email")
+		}
+	}
+
+	switch c.Backend.Type {
+	case "", "clockify":
+	case "harvest":
+		if c.Harvest.AccountID == "" {
+			add("harvest", "account_id", `required when [backend] type = "harvest" (or set HARVEST_ACCOUNT_ID)`)
+		}
+	case "toggl", "tempo":
+	default:
+		add("backend", "type", fmt.Sprintf(`expected "clockify", "harvest", "toggl" or "tempo", got %q`, c.Backend.Type))
+	}
+	for i, d := range c.Mirror.To {
+		switch {
+		case !slices.Contains(MirrorDestinations, d):
+			add("mirror", fmt.Sprintf("to[%d]", i), fmt.Sprintf("expected one of %s, got %q", strings.Join(MirrorDestinations, ", "), d))
+		case d == c.backendName():
+			add("mirror", fmt.Sprintf("to[%d]", i), fmt.Sprintf("%q is already the [backend]", d))
+		}
+	}
+	if c.MirrorsTo("csv") && c.Mirror.CSV == "" {
+		add("mirror", "csv", `the ledger file is required to mirror to "csv"`)
+	}
+	if c.MirrorsTo("harvest") && c.Harvest.AccountID == "" {
+		add("harvest", "account_id", `required to mirror to "harvest" (or set HARVEST_ACCOUNT_ID)`)
+	}
+	if c.Mirror.MaxAttempts < 0 {
+		add("mirror", "max_attempts", "must not be negative")
+	}
+	if t := c.Tempo; c.Backend.Tempo() || c.MirrorsTo("tempo") {
+		if t.JiraURL == "" {
+			add("tempo", "jira_url", "required to log worklogs to Tempo (or set JIRA_URL)")
+		}
+		if t.JiraEmail == "" {
+			add("tempo", "jira_email", "required to log worklogs to Tempo (or set JIRA_EMAIL)")
+		}
+		if t.Mirror && c.Backend.Tempo() {
+			add("tempo", "mirror", `has no effect when [backend] type = "tempo"`)
+		}
+	}
+
+	if sl := c.Slack; sl.Enabled {
+		if sl.BotToken == "" {
+			add("slack", "bot_token", "required to send prompts (or set CLOCKR_SLACK_BOT_TOKEN)")
+		}
+		if sl.SigningSecret == "" {
+			add("slack", "signing_secret", "required to accept replies (or set CLOCKR_SLACK_SIGNING_SECRET)")
+		}
+		if sl.UserID == "" {
+			add("slack", "user_id", "your Slack member ID, e.g. U0123ABCD")
+		}
+		if _, _, err := net.SplitHostPort(sl.Addr()); err != nil {
+			add("slack", "listen", fmt.Sprintf("expected host:port, got %q", sl.Listen))
+		}
+	}
+
+	if cc := c.Context.Custom; cc.Command == "" && cc.Name != "" {
+		add("context.custom", "command", "required when [context.custom] is set")
+	}
+	if c.Metrics.Listen != "" {
+		if _, _, err := net.SplitHostPort(c.Metrics.Listen); err != nil {
+			add("metrics", "listen", fmt.Sprintf("expected host:port, got %q", c.Metrics.Listen))
+		}
+	}
+	if c.Serve.Listen != "" {
+		if _, _, err := net.SplitHostPort(c.Serve.Listen); err != nil {
+			add("serve", "listen", fmt.Sprintf("expected host:port, got %q", c.Serve.Listen))
+		}
+	}
+
+	switch c.Export.Profile {
+	case "", "csv", "datev", "quickbooks":
+	case "template":
+		if c.Export.Template == "" {
+			add("export", "template", `required when profile = "template"`)
+		}
+	default:
+		add("export", "profile", fmt.Sprintf(`must be "csv", "datev", "quickbooks" or "template", got %q`, c.Export.Profile))
+	}
+
+	switch c.Format.Case {
+	case "", "sentence", "title":
+	default:
+		add("format", "case", fmt.Sprintf(`must be "sentence" or "title", got %q`, c.Format.Case))
+	}
+	if c.Format.MaxLength < 0 {
+		add("format", "max_length", fmt.Sprintf("must be 0 (no limit) or positive, got %d", c.Format.MaxLength))
+	}
+	if _, err := regexp.Compile(c.Format.Pattern); err != nil {
+		add("format", "pattern", fmt.Sprintf("invalid regular expression %q: %v", c.Format.Pattern, err))
+	}
+	if c.Format.RoundMinutes < 0 {
+		add("format", "round_minutes", fmt.Sprintf("must be 0 (no rounding) or positive, got %d", c.Format.RoundMinutes))
+	}
+	switch c.Format.Round {
+	case "", "nearest", "up", "down":
+	default:
+		add("format", "round", fmt.Sprintf(`must be "nearest", "up" or "down", got %q`, c.Format.Round))
+	}
+
+	seen := make(map[string]bool)
+	for i, r := range c.Recurring {
+		r.check(i, seen, func(key, msg string) { add("recurring", key, msg) })
+	}
+
+	for _, r := range c.Matcher.Rules {
+		if _, err := regexp.Compile(r.Pattern); err != nil {
+			add("matcher.rules", "pattern", fmt.Sprintf("invalid regular expression %q: %v", r.Pattern, err))
+		}
+		if r.Project == "" {
+			add("matcher.rules", "project", fmt.Sprintf("rule %q has no project", r.Pattern))
+		}
+	}
+
+	return problems
+}
+
+// Validate parses a config file strictly and checks its values, returning a
+// *ValidationError listing every problem found.
+func Validate(path string, data []byte) error {
+	cfg := DefaultConfig()
+	problems := decodeStrict(data, &cfg)
+	if len(problems) == 0 {
+		applyEnvOverrides(&cfg)
+		problems = cfg.check(data)
+	}
+	if len(problems) > 0 {
+		return &ValidationError{Path: path, Problems: problems}
+	}
+	return nil
+}
+
+func splitLines(data []byte) []string {
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines
+}
+
+func problemAt(lines []string, row int, msg string) Problem {
+	p := Problem{Line: row, Message: msg}
+	if row > 0 && row <= len(lines) {
+		p.Source = strings.TrimSpace(lines[row-1])
+	}
+	return p
+}
+
+// keyLine returns the 1-based line where key is set inside table, or 0.
+// It handles the plain [table] and [[table]] layouts clockr's config uses.
+func keyLine(lines []string, table, key string) int {
+	current := ""
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") {
+			current = strings.Trim(line, "[] ")
+			continue
+		}
+		if current != table || !strings.HasPrefix(line, key) {
+			continue
+		}
+		if rest := strings.TrimSpace(line[len(key):]); strings.HasPrefix(rest, "=") {
+			return i + 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,349 @@
+package config
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestValidate_UnknownKeyReportsLine(t *testing.T) {
+	data := []byte("[clockify]\napi_key = \"x\"\napikey = \"y\"\n")
+
+	err := Validate("config.toml", data)
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected *ValidationError, got %v", err)
+	}
+	if len(verr.Problems) != 1 || verr.Problems[0].Line != 3 {
+		t.Fatalf("expected one problem on line 3, got %+v", verr.Problems)
+	}
+	if !strings.Contains(verr.Problems[0].Message, "clockify.apikey") {
+		t.Errorf("message should name the key, got %q", verr.Problems[0].Message)
+	}
+}
+
+func TestValidate_ScheduleValues(t *testing.T) {
+	data := []byte(`[schedule]
+interval_minutes = 0
+work_start = "17:00"
+work_end = "9:00"
+work_days = [1, 8]
+`)
+
+	err := Validate("config.toml", data)
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected *ValidationError, got %v", err)
+	}
+
+	lines := map[int]bool{}
+	for _, p := range verr.Problems {
+		lines[p.Line] = true
+	}
+	for _, want := range []int{2, 4, 5} {
+		if !lines[want] {
+			t.Errorf("expected a problem on line %d, got %+v", want, verr.Problems)
+		}
+	}
+}
+
+func TestValidate_WorkBlocks(t *testing.T) {
+	data := []byte(`[schedule]
+blocks = ["09:00-12:00", "11:00-17:00"]
+
+[schedule.days]
+friday = ["09:00-13:00"]
+funday = ["10:00-11:00"]
+`)
+
+	err := Validate("config.toml", data)
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected *ValidationError, got %v", err)
+	}
+	lines := map[int]bool{}
+	for _, p := range verr.Problems {
+		lines[p.Line] = true
+	}
+	if len(verr.Problems) != 2 || !lines[2] || !lines[6] {
+		t.Errorf("expected problems on lines 2 and 6, got %+v", verr.Problems)
+	}
+}
+
+func TestValidate_Intervals(t *testing.T) {
+	if err := Validate("config.toml", []byte("[schedule.intervals]\nmonday = 30\n")); err != nil {
+		t.Errorf("valid intervals: %v", err)
+	}
+	for _, bad := range []string{
+		"[schedule.intervals]\nmonday = 0\n",
+		"[schedule.intervals]\nmoonday = 30\n",
+	} {
+		if err := Validate("config.toml", []byte(bad)); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestValidate_Timezone(t *testing.T) {
+	if err := Validate("config.toml", []byte("[schedule]\ntimezone = \"Europe/Stockholm\"\n")); err != nil {
+		t.Errorf("valid zone: %v", err)
+	}
+	if err := Validate("config.toml", []byte("[schedule]\ntimezone = \"Mars/Olympus\"\n")); err == nil {
+		t.Error("expected error for unknown zone")
+	}
+}
+
+func TestValidate_GraphRequiresIDs(t *testing.T) {
+	t.Setenv("MSGRAPH_CLIENT_ID", "")
+	t.Setenv("MSGRAPH_TENANT_ID", "")
+	data := []byte("[calendar]\nenabled = true\nsource = \"graph\"\n")
+
+	if err := Validate("config.toml", data); err == nil {
+		t.Fatal("expected error for graph source without client/tenant IDs")
+	}
+
+	t.Setenv("MSGRAPH_CLIENT_ID", "client")
+	t.Setenv("MSGRAPH_TENANT_ID", "tenant")
+	if err := Validate("config.toml", data); err != nil {
+		t.Errorf("env vars should satisfy graph IDs, got %v", err)
+	}
+}
+
+func TestValidate_WriteBack(t *testing.T) {
+	t.Setenv("MSGRAPH_CLIENT_ID", "client")
+	t.Setenv("MSGRAPH_TENANT_ID", "tenant")
+	if err := Validate("config.toml", []byte("[calendar]\nenabled = true\nsource = \"graph\"\nwrite_back = true\n")); err != nil {
+		t.Errorf("graph write-back: %v", err)
+	}
+	if err := Validate("config.toml", []byte("[calendar]\nenabled = true\nsource = \"cal.ics\"\nwrite_back = true\n")); err == nil {
+		t.Error("expected error for write-back to an ICS calendar")
+	}
+}
+
+func TestValidate_Coverage(t *testing.T) {
+	ok := "[coverage]\npolicy = \"full\"\nproject_id = \"brk\"\n[coverage.days]\nfriday = \"off\"\n"
+	if err := Validate("config.toml", []byte(ok)); err != nil {
+		t.Errorf("valid coverage: %v", err)
+	}
+	for _, bad := range []string{
+		"[coverage]\npolicy = \"always\"\nproject_id = \"brk\"\n",
+		"[coverage]\npolicy = \"full\"\n",
+		"[coverage]\nproject_id = \"brk\"\n[coverage.days]\nfunday = \"full\"\n",
+	} {
+		if err := Validate("config.toml", []byte(bad)); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestValidate_Digest(t *testing.T) {
+	ok := "[digest]\nenabled = true\nday = \"Friday\"\ntime = \"16:30\"\nnotify = true\n"
+	if err := Validate("config.toml", []byte(ok)); err != nil {
+		t.Errorf("valid digest: %v", err)
+	}
+	for _, bad := range []string{
+		"[digest]\nenabled = true\n",
+		"[digest]\nenabled = true\nnotify = true\nday = \"someday\"\n",
+		"[digest]\nenabled = true\nnotify = true\ntime = \"9am\"\n",
+		"[digest]\nenabled = true\n[digest.email]\nto = [\"me@example.com\"]\n",
+	} {
+		if err := Validate("config.toml", []byte(bad)); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestValidate_AIOptions(t *testing.T) {
+	ok := "[ai]\neffort = \"high\"\nthinking = false\nmax_projects = 30\n[ai.extra_params]\ntemperature = 0.2\n"
+	if err := Validate("config.toml", []byte(ok)); err != nil {
+		t.Errorf("valid ai options: %v", err)
+	}
+	for _, bad := range []string{
+		"[ai]\neffort = \"max\"\n",
+		"[ai]\nmax_projects = -1\n",
+	} {
+		if err := Validate("config.toml", []byte(bad)); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestValidate_Slack(t *testing.T) {
+	ok := "[slack]\nenabled = true\nbot_token = \"xoxb-1\"\nsigning_secret = \"s\"\nuser_id = \"U1\"\n"
+	if err := Validate("config.toml", []byte(ok)); err != nil {
+		t.Errorf("valid slack: %v", err)
+	}
+	for _, bad := range []string{
+		"[slack]\nenabled = true\nsigning_secret = \"s\"\nuser_id = \"U1\"\n",
+		"[slack]\nenabled = true\nbot_token = \"xoxb-1\"\nsigning_secret = \"s\"\n",
+		ok + "listen = \"8788\"\n",
+	} {
+		if err := Validate("config.toml", []byte(bad)); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestValidate_ServeListen(t *testing.T) {
+	if err := Validate("config.toml", []byte("[serve]\nlisten = \"0.0.0.0:9000\"\n")); err != nil {
+		t.Errorf("valid listen: %v", err)
+	}
+	if err := Validate("config.toml", []byte("[serve]\nlisten = \"localhost\"\n")); err == nil {
+		t.Error("expected error for listen without a port")
+	}
+}
+
+func TestValidate_ExportProfile(t *testing.T) {
+	if err := Validate("config.toml", []byte("[export]\nprofile = \"template\"\ntemplate = \"report.tmpl\"\n")); err != nil {
+		t.Errorf("template profile: %v", err)
+	}
+	for _, bad := range []string{
+		"[export]\nprofile = \"template\"\n",
+		"[export]\nprofile = \"sap\"\n",
+	} {
+		if err := Validate("config.toml", []byte(bad)); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestValidate_CrossMidnight(t *testing.T) {
+	if err := Validate("config.toml", []byte("[schedule]\ncross_midnight = \"split\"\n")); err != nil {
+		t.Errorf("split: %v", err)
+	}
+	if err := Validate("config.toml", []byte("[schedule]\ncross_midnight = \"merge\"\n")); err == nil {
+		t.Error("expected error for an unknown cross_midnight mode")
+	}
+}
+
+func TestValidate_MinimalConfig(t *testing.T) {
+	if err := Validate("config.toml", []byte("[clockify]\napi_key = \"x\"\n")); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestValidate_TerminalCommandNeedsPlaceholder(t *testing.T) {
+	data := []byte("[notifications]\nterminal_command = \"kitty clockr prompt-now\"\n")
+	if err := Validate("config.toml", data); err == nil {
+		t.Fatal("expected error for terminal_command without {command}")
+	}
+	data = []byte("[notifications]\nterminal_command = \"kitty {command}\"\n")
+	if err := Validate("config.toml", data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestValidate_FormatChecks(t *testing.T) {
+	data := []byte(`[format]
+max_length = -1
+pattern = "^[A-Z+-"
+`)
+
+	err := Validate("config.toml", data)
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected *ValidationError, got %v", err)
+	}
+	if len(verr.Problems) != 2 {
+		t.Errorf("expected problems with max_length and pattern, got %+v", verr.Problems)
+	}
+}
+
+func TestValidate_Backend(t *testing.T) {
+	t.Setenv("HARVEST_ACCOUNT_ID", "")
+	t.Setenv("HARVEST_TOKEN", "")
+
+	if err := Validate("config.toml", []byte("[backend]\ntype = \"kimai\"\n")); err == nil {
+		t.Error("expected an unknown backend type to be rejected")
+	}
+	if err := Validate("config.toml", []byte("[backend]\ntype = \"toggl\"\n")); err != nil {
+		t.Errorf("unexpected error for toggl: %v", err)
+	}
+	if err := Validate("config.toml", []byte("[backend]\ntype = \"harvest\"\n")); err == nil {
+		t.Error("expected Harvest without an account ID to be rejected")
+	}
+	// The token may be in the keychain, so it is checked when loading.
+	data := []byte("[backend]\ntype = \"harvest\"\n\n[harvest]\naccount_id = \"123\"\n")
+	if err := Validate("config.toml", data); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestValidate_Tempo(t *testing.T) {
+	t.Setenv("JIRA_URL", "")
+	t.Setenv("JIRA_EMAIL", "")
+
+	err := Validate("config.toml", []byte("[tempo]\nmirror = true\n"))
+	if err == nil || !strings.Contains(err.Error(), "jira_url") || !strings.Contains(err.Error(), "jira_email") {
+		t.Errorf("expected mirroring without Jira details to be rejected, got %v", err)
+	}
+	data := []byte("[backend]\ntype = \"tempo\"\n\n[tempo]\njira_url = \"https://acme.atlassian.net\"\njira_email = \"me@acme.com\"\n")
+	if err := Validate("config.toml", data); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestValidate_Mirror(t *testing.T) {
+	t.Setenv("HARVEST_ACCOUNT_ID", "")
+
+	err := Validate("config.toml", []byte("[mirror]\nto = [\"csv\", \"clockify\", \"sheets\", \"harvest\"]\n"))
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, want := range []string{"csv", `"clockify" is already the [backend]`, `got "sheets"`, "account_id"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error should mention %s, got %v", want, err)
+		}
+	}
+	if err := Validate("config.toml", []byte("[mirror]\nto = [\"csv\"]\ncsv = \"/tmp/ledger.csv\"\n")); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestConfig_Mirrors(t *testing.T) {
+	c := DefaultConfig()
+	c.Mirror.To = []string{"csv", "clockify", "tempo"}
+	c.Tempo.Mirror = true
+	if got := c.Mirrors(); !slices.Equal(got, []string{"csv", "tempo"}) {
+		t.Errorf("Mirrors() = %v", got)
+	}
+	c.Backend.Type = "tempo"
+	if got := c.Mirrors(); !slices.Equal(got, []string{"csv", "clockify"}) {
+		t.Errorf("with the tempo backend, Mirrors() = %v", got)
+	}
+}
+
+func TestTempoConfig_IssueFor(t *testing.T) {
+	tc := TempoConfig{Issues: map[string]string{"p1": "WEB-1", "Internal": "OPS-7"}}
+	if got := tc.IssueFor("p1", "Website"); got != "WEB-1" {
+		t.Errorf("by ID: got %q", got)
+	}
+	if got := tc.IssueFor("p2", "internal"); got != "OPS-7" {
+		t.Errorf("by name: got %q", got)
+	}
+	if got := tc.IssueFor("p3", "Other"); got != "" {
+		t.Errorf("unmapped: got %q", got)
+	}
+}
+
+func TestValidate_GraphProfiles(t *testing.T) {
+	t.Setenv("MSGRAPH_CLIENT_ID", "client")
+	t.Setenv("MSGRAPH_TENANT_ID", "tenant")
+	data := []byte("[calendar]\nenabled = true\nsource = \"graph\"\n[calendar.graph]\nwrite_profile = \"work\"\n[calendar.graph.profiles.personal]\ntenant_id = \"consumers\"\n")
+	if err := Validate("config.toml", data); err != nil {
+		t.Errorf("graph profiles: %v", err)
+	}
+	if err := Validate("config.toml", []byte("[calendar.graph.profiles.Work]\ntenant_id = \"x\"\n")); err == nil {
+		t.Error("expected error for an upper-case profile name")
+	}
+
+	cfg := DefaultConfig()
+	cfg.Calendar.Graph = GraphConfig{ClientID: "app", TenantID: "org", Profiles: map[string]GraphProfile{"personal": {TenantID: "consumers"}}}
+	if client, tenant := cfg.Calendar.Graph.App("personal"); client != "app" || tenant != "consumers" {
+		t.Errorf("App(personal) = %q, %q", client, tenant)
+	}
+	if client, tenant := cfg.Calendar.Graph.App(""); client != "app" || tenant != "org" {
+		t.Errorf("App(default) = %q, %q", client, tenant)
+	}
+}
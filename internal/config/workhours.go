@@ -0,0 +1,219 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/timezone"
+)
+
+// WorkBlock is one stretch of work hours within a day, in minutes since
+// midnight.
+type WorkBlock struct {
+	Start int
+	End   int
+}
+
+// Minutes returns the block length.
+func (b WorkBlock) Minutes() int {
+	return b.End - b.Start
+}
+
+func (b WorkBlock) String() string {
+	return fmt.Sprintf("%02d:%02d–%02d:%02d", b.Start/60, b.Start%60, b.End/60, b.End%60)
+}
+
+// weekdayKeys maps [schedule.days] keys to weekdays.
+var weekdayKeys = map[string]time.Weekday{
+	"monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday,
+	"thursday": time.Thursday, "friday": time.Friday, "saturday": time.Saturday,
+	"sunday": time.Sunday,
+}
+
+// parseClock parses a zero-padded 24-hour "HH:MM" into minutes since midnight.
+func parseClock(s string) (int, error) {
+	if !hhmm.MatchString(s) {
+		return 0, fmt.Errorf("expected 24-hour HH:MM, got %q", s)
+	}
+	return int(s[0]-'0')*600 + int(s[1]-'0')*60 + int(s[3]-'0')*10 + int(s[4]-'0'), nil
+}
+
+// parseBlock parses a "HH:MM-HH:MM" work block.
+func parseBlock(s string) (WorkBlock, error) {
+	from, to, ok := strings.Cut(strings.TrimSpace(s), "-")
+	if !ok {
+		return WorkBlock{}, fmt.Errorf("%q is not a HH:MM-HH:MM block", s)
+	}
+	start, err := parseClock(strings.TrimSpace(from))
+	if err != nil {
+		return WorkBlock{}, fmt.Errorf("%q: %w", s, err)
+	}
+	end, err := parseClock(strings.TrimSpace(to))
+	if err != nil {
+		return WorkBlock{}, fmt.Errorf("%q: %w", s, err)
+	}
+	if end <= start {
+		return WorkBlock{}, fmt.Errorf("%q ends before it starts", s)
+	}
+	return WorkBlock{Start: start, End: end}, nil
+}
+
+// parseBlocks parses a list of blocks, which must be in order and not overlap.
+func parseBlocks(list []string) ([]WorkBlock, error) {
+	if len(list) == 0 {
+		return nil, fmt.Errorf("at least one HH:MM-HH:MM block is required")
+	}
+	blocks := make([]WorkBlock, 0, len(list))
+	for _, s := range list {
+		b, err := parseBlock(s)
+		if err != nil {
+			return nil, err
+		}
+		if n := len(blocks); n > 0 && b.Start < blocks[n-1].End {
+			return nil, fmt.Errorf("%q overlaps or comes before the previous block", s)
+		}
+		blocks = append(blocks, b)
+	}
+	return blocks, nil
+}
+
+// Location returns the zone work hours are interpreted in: Timezone if set,
+// else the current system zone.
+func (s ScheduleConfig) Location() *time.Location {
+	if s.Timezone != "" {
+		if loc, err := time.LoadLocation(s.Timezone); err == nil {
+			return loc
+		}
+	}
+	return timezone.Current()
+}
+
+// IsWorkDay reports whether wd is one of WorkDays.
+func (s ScheduleConfig) IsWorkDay(wd time.Weekday) bool {
+	iso := int(wd)
+	if iso == 0 {
+		iso = 7 // Sunday = 7
+	}
+	for _, d := range s.WorkDays {
+		if d == iso {
+			return true
+		}
+	}
+	return false
+}
+
+// BlocksFor returns the work hours for wd: the [schedule.days] override for
+// that weekday, else the default hours. It does not check WorkDays.
+func (s ScheduleConfig) BlocksFor(wd time.Weekday) []WorkBlock {
+	if list, ok := s.dayOverride(wd); ok {
+		if blocks, err := parseBlocks(list); err == nil {
+			return blocks
+		}
+	}
+	return s.defaultBlocks()
+}
+
+// defaultBlocks returns Blocks if set, else WorkStart–WorkEnd.
+func (s ScheduleConfig) defaultBlocks() []WorkBlock {
+	if blocks, err := parseBlocks(s.Blocks); err == nil {
+		return blocks
+	}
+	start, err1 := parseClock(s.WorkStart)
+	end, err2 := parseClock(s.WorkEnd)
+	if err1 != nil || err2 != nil || end <= start {
+		return []WorkBlock{{Start: 9 * 60, End: 17 * 60}}
+	}
+	return []WorkBlock{{Start: start, End: end}}
+}
+
+// HoursSummary describes the default work hours and any per-day overrides,
+// e.g. "09:00–12:00, 13:00–17:00 (Fri 09:00–13:00)".
+func (s ScheduleConfig) HoursSummary() string {
+	summary := formatBlocks(s.defaultBlocks())
+
+	var overrides []string
+	for i := range 7 {
+		wd := time.Weekday((i + 1) % 7) // Monday first
+		if _, ok := s.dayOverride(wd); ok {
+			overrides = append(overrides, wd.String()[:3]+" "+formatBlocks(s.BlocksFor(wd)))
+		}
+	}
+	if len(overrides) > 0 {
+		summary += " (" + strings.Join(overrides, "; ") + ")"
+	}
+	return summary
+}
+
+// IntervalFor returns the prompt interval on wd: the [schedule.intervals]
+// override for that weekday, else IntervalMinutes (60 if unset).
+func (s ScheduleConfig) IntervalFor(wd time.Weekday) time.Duration {
+	mins := s.IntervalMinutes
+	for key, m := range s.Intervals {
+		if d, ok := weekdayKeys[strings.ToLower(key)]; ok && d == wd && m > 0 {
+			mins = m
+		}
+	}
+	if mins <= 0 {
+		mins = 60
+	}
+	return time.Duration(mins) * time.Minute
+}
+
+// IntervalSummary describes the default interval and any per-day overrides,
+// e.g. "60m (Mon 30m)".
+func (s ScheduleConfig) IntervalSummary() string {
+	def := s.IntervalMinutes
+	if def <= 0 {
+		def = 60
+	}
+	var overrides []string
+	for i := range 7 {
+		wd := time.Weekday((i + 1) % 7) // Monday first
+		if m := int(s.IntervalFor(wd).Minutes()); m != def {
+			overrides = append(overrides, fmt.Sprintf("%s %dm", wd.String()[:3], m))
+		}
+	}
+	summary := fmt.Sprintf("%dm", def)
+	if len(overrides) > 0 {
+		summary += " (" + strings.Join(overrides, ", ") + ")"
+	}
+	return summary
+}
+
+// SplitAtMidnight reports whether entries spanning midnight are logged as
+// one entry per calendar day.
+func (s ScheduleConfig) SplitAtMidnight() bool {
+	return s.CrossMidnight == "split"
+}
+
+// PolicyFor returns the coverage policy for the weekday: "full" or "off".
+func (c CoverageConfig) PolicyFor(wd time.Weekday) string {
+	policy := c.Policy
+	for key, p := range c.Days {
+		if d, ok := weekdayKeys[strings.ToLower(key)]; ok && d == wd {
+			policy = p
+		}
+	}
+	if policy == "" {
+		return "off"
+	}
+	return policy
+}
+
+func (s ScheduleConfig) dayOverride(wd time.Weekday) ([]string, bool) {
+	for key, list := range s.Days {
+		if d, ok := weekdayKeys[strings.ToLower(key)]; ok && d == wd {
+			return list, true
+		}
+	}
+	return nil, false
+}
+
+func formatBlocks(blocks []WorkBlock) string {
+	parts := make([]string, len(blocks))
+	for i, b := range blocks {
+		parts[i] = b.String()
+	}
+	return strings.Join(parts, ", ")
+}
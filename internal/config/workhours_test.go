@@ -0,0 +1,72 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBlocksFor(t *testing.T) {
+	s := ScheduleConfig{
+		WorkStart: "08:30",
+		WorkEnd:   "16:30",
+		Days:      map[string][]string{"Friday": {"09:00-13:00"}, "monday": {"10:00-12:00", "13:00-18:00"}},
+	}
+
+	if got := s.BlocksFor(time.Wednesday); len(got) != 1 || got[0] != (WorkBlock{Start: 510, End: 990}) {
+		t.Errorf("Wednesday blocks = %v, want 08:30–16:30", got)
+	}
+	if got := s.BlocksFor(time.Friday); len(got) != 1 || got[0].Minutes() != 240 {
+		t.Errorf("Friday blocks = %v, want 09:00–13:00", got)
+	}
+	if got := s.BlocksFor(time.Monday); len(got) != 2 || got[1].String() != "13:00–18:00" {
+		t.Errorf("Monday blocks = %v", got)
+	}
+
+	want := "08:30–16:30 (Mon 10:00–12:00, 13:00–18:00; Fri 09:00–13:00)"
+	if got := s.HoursSummary(); got != want {
+		t.Errorf("HoursSummary = %q, want %q", got, want)
+	}
+}
+
+func TestIntervalFor(t *testing.T) {
+	s := ScheduleConfig{IntervalMinutes: 60, Intervals: map[string]int{"Monday": 30, "friday": 90}}
+	if got := s.IntervalFor(time.Monday); got != 30*time.Minute {
+		t.Errorf("Monday = %s, want 30m", got)
+	}
+	if got := s.IntervalFor(time.Tuesday); got != time.Hour {
+		t.Errorf("Tuesday = %s, want 1h", got)
+	}
+	if got, want := s.IntervalSummary(), "60m (Mon 30m, Fri 90m)"; got != want {
+		t.Errorf("IntervalSummary = %q, want %q", got, want)
+	}
+	if got := (ScheduleConfig{}).IntervalFor(time.Monday); got != time.Hour {
+		t.Errorf("unset = %s, want 1h", got)
+	}
+}
+
+func TestParseBlocks_Invalid(t *testing.T) {
+	for _, list := range [][]string{
+		{},
+		{"9-12"},
+		{"12:00-09:00"},
+		{"09:00-12:00", "11:00-17:00"},
+		{"13:00-17:00", "09:00-12:00"},
+	} {
+		if _, err := parseBlocks(list); err == nil {
+			t.Errorf("expected %q to be rejected", list)
+		}
+	}
+}
+
+func TestCoveragePolicyFor(t *testing.T) {
+	c := CoverageConfig{Policy: "full", Days: map[string]string{"Friday": "off"}}
+	if got := c.PolicyFor(time.Monday); got != "full" {
+		t.Errorf("Monday = %q, want full", got)
+	}
+	if got := c.PolicyFor(time.Friday); got != "off" {
+		t.Errorf("Friday = %q, want off", got)
+	}
+	if got := (CoverageConfig{}).PolicyFor(time.Monday); got != "off" {
+		t.Errorf("default = %q, want off", got)
+	}
+}
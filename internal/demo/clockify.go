@@ -0,0 +1,194 @@
+// Package demo provides in-memory stand-ins for Clockify, the AI provider and
+// context sources, so 'clockr demo' runs without accounts or network access.
+package demo
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/clockify"
+)
+
+// WorkspaceID is the demo workspace.
+const WorkspaceID = "demo-workspace"
+
+const userID = "demo-user"
+
+// Server is a fake Clockify API that keeps time entries in memory.
+type Server struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	entries []clockify.TimeEntry
+	nextID  int
+}
+
+// NewServer starts a fake Clockify API on a local port. Close it when done.
+func NewServer() *Server {
+	s := &Server{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /user", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, clockify.User{ID: userID, Name: "Demo User", ActiveWorkspace: WorkspaceID, DefaultWorkspace: WorkspaceID})
+	})
+	mux.HandleFunc("GET /workspaces", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, []clockify.Workspace{{ID: WorkspaceID, Name: "Demo"}})
+	})
+	mux.HandleFunc("GET /workspaces/{ws}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("ws") != WorkspaceID {
+			http.Error(w, `{"message":"Workspace not found","code":404}`, http.StatusNotFound)
+			return
+		}
+		writeJSON(w, clockify.Workspace{ID: WorkspaceID, Name: "Demo", Settings: settings})
+	})
+	mux.HandleFunc("GET /workspaces/{ws}/projects", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") != "1" {
+			writeJSON(w, []clockify.Project{})
+			return
+		}
+		writeJSON(w, projects)
+	})
+	mux.HandleFunc("GET /workspaces/{ws}/clients", func(w http.ResponseWriter, r *http.Request) {
+		archived := r.URL.Query().Get("archived") == "true"
+		var out []clockify.ClockifyClient
+		for _, c := range clients {
+			if c.Archived == archived {
+				out = append(out, c)
+			}
+		}
+		writeJSON(w, out)
+	})
+	mux.HandleFunc("POST /workspaces/{ws}/time-entries", s.createEntry)
+	mux.HandleFunc("GET /workspaces/{ws}/user/{user}/time-entries", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if r.URL.Query().Get("page") != "1" {
+			writeJSON(w, []clockify.TimeEntry{})
+			return
+		}
+		if r.URL.Query().Get("hydrated") == "true" {
+			writeJSON(w, hydrate(s.entries))
+			return
+		}
+		writeJSON(w, s.entries)
+	})
+	mux.HandleFunc("GET /workspaces/{ws}/time-entries/{id}", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for _, e := range s.entries {
+			if e.ID == r.PathValue("id") {
+				writeJSON(w, e)
+				return
+			}
+		}
+		http.Error(w, `{"message":"Time entry not found","code":404}`, http.StatusNotFound)
+	})
+	mux.HandleFunc("PUT /workspaces/{ws}/time-entries/{id}", s.updateEntry)
+	mux.HandleFunc("DELETE /workspaces/{ws}/time-entries/{id}", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for i, e := range s.entries {
+			if e.ID == r.PathValue("id") {
+				s.entries = append(s.entries[:i], s.entries[i+1:]...)
+				break
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+func (s *Server) createEntry(w http.ResponseWriter, r *http.Request) {
+	var req clockify.TimeEntryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"message":"invalid request body","code":400}`, http.StatusBadRequest)
+		return
+	}
+	if req.ProjectID == archivedProjectID {
+		http.Error(w, `{"message":"Client is archived","code":501}`, http.StatusBadRequest)
+		return
+	}
+	start, err1 := time.Parse(time.RFC3339, req.Start)
+	end, err2 := time.Parse(time.RFC3339, req.End)
+	if err1 != nil || err2 != nil {
+		http.Error(w, `{"message":"invalid start or end","code":400}`, http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	s.nextID++
+	e := clockify.TimeEntry{ID: "demo-" + strconv.Itoa(s.nextID), Description: req.Description, ProjectID: req.ProjectID}
+	e.TimeInterval.Start, e.TimeInterval.End = start, end
+	s.entries = append(s.entries, e)
+	s.mu.Unlock()
+
+	writeJSON(w, e)
+}
+
+func (s *Server) updateEntry(w http.ResponseWriter, r *http.Request) {
+	var req clockify.TimeEntryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"message":"invalid request body","code":400}`, http.StatusBadRequest)
+		return
+	}
+	start, err1 := time.Parse(time.RFC3339, req.Start)
+	end, err2 := time.Parse(time.RFC3339, req.End)
+	if err1 != nil || err2 != nil {
+		http.Error(w, `{"message":"invalid start or end","code":400}`, http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, e := range s.entries {
+		if e.ID == r.PathValue("id") {
+			e.Description, e.ProjectID = req.Description, req.ProjectID
+			e.TimeInterval.Start, e.TimeInterval.End = start, end
+			s.entries[i] = e
+			writeJSON(w, e)
+			return
+		}
+	}
+	http.Error(w, `{"message":"Time entry not found","code":404}`, http.StatusNotFound)
+}
+
+// Entries returns the time entries created so far.
+func (s *Server) Entries() []clockify.TimeEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]clockify.TimeEntry(nil), s.entries...)
+}
+
+// hydrate fills in the project and client names of entries.
+func hydrate(entries []clockify.TimeEntry) []clockify.HydratedTimeEntry {
+	out := []clockify.HydratedTimeEntry{}
+	for _, e := range entries {
+		h := clockify.HydratedTimeEntry{ID: e.ID, Description: e.Description, UserID: userID, WorkspaceID: WorkspaceID, Tags: []clockify.Tag{}}
+		h.TimeInterval.Start, h.TimeInterval.End = e.TimeInterval.Start, e.TimeInterval.End
+		for _, p := range projects {
+			if p.ID != e.ProjectID {
+				continue
+			}
+			h.Project = &clockify.EntryProject{ID: p.ID, Name: p.Name, ClientID: p.ClientID, Color: p.Color}
+			for _, c := range clients {
+				if c.ID == p.ClientID {
+					h.Project.ClientName = c.Name
+				}
+			}
+		}
+		out = append(out, h)
+	}
+	return out
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, fmt.Sprintf(`{"message":%q}`, err.Error()), http.StatusInternalServerError)
+	}
+}
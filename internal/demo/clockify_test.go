@@ -0,0 +1,144 @@
+package demo
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/clockify"
+)
+
+func TestServer_CreatesAndRejectsArchived(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	client := clockify.NewClient("demo", srv.URL, time.Hour, nil)
+	ctx := context.Background()
+
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	req := clockify.TimeEntryRequest{
+		Start:       start.Format(time.RFC3339),
+		End:         start.Add(time.Hour).Format(time.RFC3339),
+		ProjectID:   "demo-web",
+		Description: "Landing page",
+	}
+	if _, err := client.CreateTimeEntry(ctx, WorkspaceID, req); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(srv.Entries()); n != 1 {
+		t.Fatalf("entries = %d, want 1", n)
+	}
+
+	req.ProjectID = archivedProjectID
+	_, err := client.CreateTimeEntry(ctx, WorkspaceID, req)
+	if err == nil || !strings.Contains(clockify.FriendlyError(err), "archived") {
+		t.Errorf("archived client error = %v", err)
+	}
+}
+
+func TestServer_EntryCRUD(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	client := clockify.NewClient("demo", srv.URL, time.Hour, nil)
+	ctx := context.Background()
+
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	req := clockify.TimeEntryRequest{
+		Start:       start.Format(time.RFC3339),
+		End:         start.Add(time.Hour).Format(time.RFC3339),
+		ProjectID:   "demo-web",
+		Description: "Landing page",
+	}
+	created, err := client.CreateTimeEntry(ctx, WorkspaceID, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req.Description = "Landing page copy"
+	if _, err := client.UpdateTimeEntry(ctx, WorkspaceID, created.ID, req); err != nil {
+		t.Fatal(err)
+	}
+	got, err := client.GetTimeEntry(ctx, WorkspaceID, created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Description != "Landing page copy" {
+		t.Errorf("description = %q after update", got.Description)
+	}
+
+	hydrated, err := client.GetHydratedTimeEntries(ctx, WorkspaceID, userID, start, start.Add(24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hydrated) != 1 || hydrated[0].Project == nil || hydrated[0].Project.ClientName != "Acme Corp" {
+		t.Fatalf("hydrated = %+v, want one entry on Acme Corp", hydrated)
+	}
+
+	if err := client.DeleteTimeEntry(ctx, WorkspaceID, created.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetTimeEntry(ctx, WorkspaceID, created.ID); err == nil {
+		t.Error("entry still found after delete")
+	}
+}
+
+func TestServer_UnknownWorkspace(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	client := clockify.NewClient("demo", srv.URL, time.Hour, nil)
+
+	_, err := client.GetWorkspace(context.Background(), "nope")
+	var notFound *clockify.WorkspaceNotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("err = %v, want WorkspaceNotFoundError", err)
+	}
+	if !strings.Contains(err.Error(), "Demo ("+WorkspaceID+")") {
+		t.Errorf("error %q does not list the user's workspaces", err)
+	}
+}
+
+// failFirst answers the first request with a 503, after reading its body,
+// and passes later ones on.
+type failFirst struct {
+	base   http.RoundTripper
+	failed bool
+}
+
+func (f *failFirst) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !f.failed {
+		f.failed = true
+		io.Copy(io.Discard, req.Body)
+		return &http.Response{
+			StatusCode: http.StatusServiceUnavailable,
+			Header:     http.Header{"Retry-After": {"0"}},
+			Body:       io.NopCloser(strings.NewReader("")),
+			Request:    req,
+		}, nil
+	}
+	return f.base.RoundTrip(req)
+}
+
+func TestClient_RetriedPostCarriesPayload(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	client := clockify.NewClient("demo", srv.URL, time.Hour, nil)
+	client.SetTransport(&failFirst{base: http.DefaultTransport})
+
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	_, err := client.CreateTimeEntry(context.Background(), WorkspaceID, clockify.TimeEntryRequest{
+		Start:       start.Format(time.RFC3339),
+		End:         start.Add(time.Hour).Format(time.RFC3339),
+		ProjectID:   "demo-web",
+		Description: "Landing page",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := srv.Entries()
+	if len(entries) != 1 || entries[0].Description != "Landing page" {
+		t.Errorf("entries after retry = %+v, want the full payload", entries)
+	}
+}
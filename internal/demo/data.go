@@ -0,0 +1,74 @@
+package demo
+
+import (
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/ai"
+	"github.com/christopherklint97/clockr/internal/clockify"
+)
+
+const archivedProjectID = "demo-billing"
+
+var settings = clockify.WorkspaceSettings{DurationFormat: "FULL"}
+
+var clients = []clockify.ClockifyClient{
+	{ID: "demo-acme", Name: "Acme Corp"},
+	{ID: "demo-globex", Name: "Globex"},
+	{ID: "demo-initech", Name: "Initech", Archived: true},
+}
+
+var projects = []clockify.Project{
+	{ID: "demo-web", Name: "Website Redesign", ClientID: "demo-acme"},
+	{ID: "demo-mobile", Name: "Mobile App", ClientID: "demo-acme"},
+	{ID: "demo-data", Name: "Data Platform", ClientID: "demo-globex"},
+	{ID: "demo-internal", Name: "Internal"},
+	{ID: archivedProjectID, Name: "Legacy Billing", ClientID: "demo-initech"},
+}
+
+// keywords are the words the demo provider matches to each project.
+var keywords = map[string][]string{
+	"demo-web":        {"website", "web", "frontend", "css", "landing", "design", "redesign", "ui", "page"},
+	"demo-mobile":     {"mobile", "ios", "android", "app", "push", "notification"},
+	"demo-data":       {"data", "pipeline", "etl", "warehouse", "dashboard", "sql", "query"},
+	"demo-internal":   {"standup", "meeting", "planning", "retro", "1:1", "interview", "hiring", "email", "review"},
+	archivedProjectID: {"billing", "invoice", "legacy"},
+}
+
+// weekEvents and weekCommits are a work week of calendar events and commits,
+// Monday first.
+var weekEvents = [][]string{
+	{"Daily standup", "Sprint planning"},
+	{"Daily standup", "Design review: landing page"},
+	{"Daily standup", "1:1 with manager"},
+	{"Daily standup", "Data pipeline sync with Globex"},
+	{"Daily standup", "Sprint retro"},
+}
+
+var weekCommits = [][]string{
+	{"web: new landing page hero", "web: fix css grid on pricing page"},
+	{"web: responsive navigation", "mobile: push notification settings screen"},
+	{"data: nightly etl job retries", "data: warehouse schema for orders"},
+	{"data: dashboard query for weekly revenue", "mobile: android crash on login"},
+	{"web: redesign footer", "web: accessibility fixes on forms"},
+}
+
+// SeedWeek fills each day's calendar events and commits from a fake work
+// week, so batch logging has context to work with.
+func SeedWeek(days []ai.DaySlot) {
+	for i := range days {
+		wd := int(days[i].Start.Weekday()+6) % 7 // Monday = 0
+		if wd < len(weekEvents) {
+			days[i].Events = ai.TextContext(ai.SourceCalendar, weekEvents[wd])
+			days[i].Commits = ai.TextContext(ai.SourceCommit, weekCommits[wd])
+		}
+	}
+}
+
+// ContextFor returns fake calendar and commit context for a prompt window.
+func ContextFor(t time.Time) []ai.ContextItem {
+	wd := int(t.Weekday()+6) % 7
+	if wd >= len(weekEvents) {
+		wd = 0
+	}
+	return append(ai.TextContext(ai.SourceCalendar, weekEvents[wd]), ai.TextContext(ai.SourceCommit, weekCommits[wd])...)
+}
@@ -0,0 +1,223 @@
+package demo
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/christopherklint97/clockr/internal/ai"
+	"github.com/christopherklint97/clockr/internal/clockify"
+)
+
+// Provider is a stand-in AI that matches keywords to the demo projects. It
+// is deterministic, so the demo and screencasts look the same every time.
+type Provider struct {
+	// Delay imitates the time a real model takes to answer.
+	Delay time.Duration
+}
+
+var clauseSep = regexp.MustCompile(`(?i)\s*(?:,|;|\n|\band then\b|\band\b|\bplus\b)\s*`)
+
+// match is a project picked for a piece of the description.
+type match struct {
+	project     clockify.Project
+	description string
+	weight      int
+	confidence  float64
+}
+
+func (p *Provider) wait(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(p.Delay):
+		return nil
+	}
+}
+
+func (p *Provider) MatchProjects(ctx context.Context, description string, projects []clockify.Project, interval time.Duration, contextItems []ai.ContextItem, segments []ai.Segment) (*ai.Suggestion, error) {
+	if err := p.wait(ctx); err != nil {
+		return nil, err
+	}
+	matches := matchLines(splitClauses(description), projects)
+	if len(matches) == 0 {
+		return &ai.Suggestion{Clarification: "Which project was that for? Try naming the website, the mobile app or the data pipeline."}, nil
+	}
+
+	var allocs []ai.Allocation
+	if len(segments) > 0 {
+		for i, seg := range segments {
+			m := matches[i%len(matches)]
+			allocs = append(allocs, allocation(m, seg.Minutes()))
+		}
+		return &ai.Suggestion{Allocations: allocs}, nil
+	}
+	for i, minutes := range split(int(interval.Minutes()), matches) {
+		if minutes > 0 {
+			allocs = append(allocs, allocation(matches[i], minutes))
+		}
+	}
+	return &ai.Suggestion{Allocations: allocs}, nil
+}
+
+func (p *Provider) MatchProjectsBatch(ctx context.Context, description string, projects []clockify.Project, days []ai.DaySlot) (*ai.BatchSuggestion, error) {
+	if err := p.wait(ctx); err != nil {
+		return nil, err
+	}
+	shared := splitClauses(description)
+	var out ai.BatchSuggestion
+	for _, d := range days {
+		lines := append(append(append([]string(nil), shared...), ai.ContextTexts(d.Commits)...), ai.ContextTexts(d.Events)...)
+		matches := matchLines(lines, projects)
+		if len(matches) == 0 {
+			continue
+		}
+		blocks := d.Blocks
+		if len(blocks) == 0 {
+			blocks = []ai.Segment{{Start: d.Start, End: d.End}}
+		}
+		// Lay the projects out one after another across the work blocks.
+		b, at := 0, blocks[0].Start
+		for i, minutes := range split(d.Minutes, matches) {
+			for minutes > 0 && b < len(blocks) {
+				n := min(minutes, int(blocks[b].End.Sub(at).Minutes()))
+				if n > 0 {
+					a := allocation(matches[i], n)
+					out.Allocations = append(out.Allocations, ai.BatchAllocation{
+						Date:        d.Date,
+						StartTime:   at.Format("15:04"),
+						EndTime:     at.Add(time.Duration(n) * time.Minute).Format("15:04"),
+						ProjectID:   a.ProjectID,
+						ProjectName: a.ProjectName,
+						ClientName:  a.ClientName,
+						Minutes:     n,
+						Description: a.Description,
+						Confidence:  a.Confidence,
+					})
+					at = at.Add(time.Duration(n) * time.Minute)
+					minutes -= n
+				}
+				if !at.Before(blocks[b].End) {
+					if b++; b < len(blocks) {
+						at = blocks[b].Start
+					}
+				}
+			}
+		}
+	}
+	if len(out.Allocations) == 0 {
+		out.Clarification = "Which projects did you work on this week?"
+	}
+	return &out, nil
+}
+
+// WriteStandup summarises the entries without a model.
+func (p *Provider) WriteStandup(ctx context.Context, previousDay string, entries, events []string) (*ai.Standup, error) {
+	if err := p.wait(ctx); err != nil {
+		return nil, err
+	}
+	yesterday := "Nothing logged."
+	if len(entries) > 0 {
+		yesterday = strings.Join(entries, "; ")
+	}
+	today := "Continue where I left off."
+	if len(events) > 0 {
+		today = "Meetings: " + strings.Join(events, ", ")
+	}
+	return &ai.Standup{Yesterday: yesterday, Today: today, Blockers: "None."}, nil
+}
+
+func splitClauses(description string) []string {
+	var out []string
+	for _, c := range clauseSep.Split(description, -1) {
+		if c = strings.TrimSpace(c); c != "" {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// matchLines finds the project for each line and merges lines that land on
+// the same project, in first-seen order.
+func matchLines(lines []string, projects []clockify.Project) []match {
+	var out []match
+	index := make(map[string]int)
+	for _, line := range lines {
+		p, score := bestProject(line, projects)
+		if score == 0 {
+			continue
+		}
+		if i, ok := index[p.ID]; ok {
+			out[i].weight++
+			continue
+		}
+		index[p.ID] = len(out)
+		out = append(out, match{project: p, description: sentence(line), weight: 1, confidence: min(0.6+0.15*float64(score), 0.95)})
+	}
+	return out
+}
+
+func bestProject(line string, projects []clockify.Project) (clockify.Project, int) {
+	words := strings.FieldsFunc(strings.ToLower(line), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != ':'
+	})
+	var best clockify.Project
+	bestScore := 0
+	for _, p := range projects {
+		score := 0
+		for _, w := range words {
+			w = strings.TrimSuffix(w, ":") // "web:" commit prefixes, but keep "1:1"
+			for _, k := range keywords[p.ID] {
+				if w == k {
+					score++
+				}
+			}
+		}
+		if score > bestScore {
+			best, bestScore = p, score
+		}
+	}
+	return best, bestScore
+}
+
+// split shares total minutes between matches by weight in 15-minute steps,
+// giving the remainder to the first match.
+func split(total int, matches []match) []int {
+	weights := 0
+	for _, m := range matches {
+		weights += m.weight
+	}
+	out := make([]int, len(matches))
+	used := 0
+	for i, m := range matches {
+		out[i] = total * m.weight / weights / 15 * 15
+		used += out[i]
+	}
+	out[0] += total - used
+	return out
+}
+
+func allocation(m match, minutes int) ai.Allocation {
+	return ai.Allocation{
+		ProjectID:   m.project.ID,
+		ProjectName: m.project.Name,
+		ClientName:  m.project.ClientName,
+		Minutes:     minutes,
+		Description: m.description,
+		Confidence:  m.confidence,
+	}
+}
+
+// sentence turns a commit subject or clause into an entry description.
+func sentence(s string) string {
+	if i := strings.Index(s, ": "); i > 0 && !strings.Contains(s[:i], " ") {
+		s = s[i+2:] // drop a "web: " style prefix
+	}
+	if s == "" {
+		return s
+	}
+	return fmt.Sprintf("%s%s", strings.ToUpper(s[:1]), s[1:])
+}
@@ -0,0 +1,62 @@
+package demo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/ai"
+)
+
+func TestMatchProjects(t *testing.T) {
+	p := &Provider{}
+	s, err := p.MatchProjects(context.Background(), "fixed the landing page css and tuned the etl pipeline, plus standup",
+		projects, time.Hour, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"demo-web", "demo-data", "demo-internal"}
+	if len(s.Allocations) != len(want) {
+		t.Fatalf("allocations = %+v, want %v", s.Allocations, want)
+	}
+	total := 0
+	for i, a := range s.Allocations {
+		if a.ProjectID != want[i] {
+			t.Errorf("allocation %d = %s, want %s", i, a.ProjectID, want[i])
+		}
+		total += a.Minutes
+	}
+	if total != 60 {
+		t.Errorf("minutes add up to %d, want 60", total)
+	}
+
+	s, _ = p.MatchProjects(context.Background(), "stuff", projects, time.Hour, nil, nil)
+	if s.Clarification == "" {
+		t.Error("an unmatched description should ask for clarification")
+	}
+}
+
+func TestMatchProjectsBatch_FillsBlocks(t *testing.T) {
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC) // a Monday
+	at := func(h int) time.Time { return day.Add(time.Duration(h) * time.Hour) }
+	days := []ai.DaySlot{{
+		Date: "2026-03-02", Weekday: "Monday", Start: at(9), End: at(17), Minutes: 420,
+		Blocks: []ai.Segment{{Start: at(9), End: at(12)}, {Start: at(13), End: at(17)}},
+	}}
+	SeedWeek(days)
+
+	s, err := (&Provider{}).MatchProjectsBatch(context.Background(), "", projects, days)
+	if err != nil {
+		t.Fatal(err)
+	}
+	total := 0
+	for _, a := range s.Allocations {
+		if a.StartTime < "09:00" || a.EndTime > "17:00" || (a.StartTime < "13:00" && a.EndTime > "12:00") {
+			t.Errorf("allocation %s–%s is outside the work blocks", a.StartTime, a.EndTime)
+		}
+		total += a.Minutes
+	}
+	if total != 420 {
+		t.Errorf("minutes add up to %d, want the day's 420", total)
+	}
+}
@@ -0,0 +1,173 @@
+// Package digest builds the weekly summary the scheduler sends: totals per
+// project, gaps in work hours and entries that never reached Clockify.
+package digest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/audit"
+	"github.com/christopherklint97/clockr/internal/config"
+	"github.com/christopherklint97/clockr/internal/store"
+)
+
+// minGap is the shortest unlogged stretch worth reporting.
+const minGap = 5 * time.Minute
+
+// Digest summarises one week, Monday to Sunday.
+type Digest struct {
+	From            time.Time // Monday
+	To              time.Time // the following Monday
+	Projects        []ProjectTotal
+	TotalMinutes    int
+	OvertimeMinutes int
+	Gaps            []audit.Interval
+	Failed          []store.Entry
+}
+
+// ProjectTotal is the regular (non-overtime) time on one project.
+type ProjectTotal struct {
+	Name    string // "Client / Project", or the project alone
+	Minutes int
+}
+
+// WeekBefore returns midnight of the Monday of the week before t's week.
+func WeekBefore(t time.Time) time.Time {
+	monday := t.AddDate(0, 0, -int(t.Weekday()+6)%7)
+	return time.Date(monday.Year(), monday.Month(), monday.Day(), 0, 0, 0, 0, t.Location()).AddDate(0, 0, -7)
+}
+
+// Build summarises the week starting at monday.
+func Build(db *store.DB, sched config.ScheduleConfig, monday time.Time) (*Digest, error) {
+	d := &Digest{From: monday, To: monday.AddDate(0, 0, 7)}
+	entries, err := db.GetEntriesBetween(d.From, d.To)
+	if err != nil {
+		return nil, fmt.Errorf("fetching entries: %w", err)
+	}
+	skips, err := db.GetSkipsBetween(d.From, d.To)
+	if err != nil {
+		return nil, fmt.Errorf("fetching skips: %w", err)
+	}
+
+	totals := map[string]int{}
+	var covered []audit.Interval
+	for _, e := range entries {
+		covered = append(covered, audit.Interval{Start: e.StartTime, End: e.EndTime})
+		if e.Status == "failed" {
+			d.Failed = append(d.Failed, e)
+		}
+		minutes := e.MinutesWithin(d.From, d.To)
+		if e.Overtime {
+			d.OvertimeMinutes += minutes
+			continue
+		}
+		name := e.ProjectName
+		if e.ClientName != "" {
+			name = e.ClientName + " / " + e.ProjectName
+		}
+		totals[name] += minutes
+		d.TotalMinutes += minutes
+	}
+	for name, minutes := range totals {
+		d.Projects = append(d.Projects, ProjectTotal{Name: name, Minutes: minutes})
+	}
+	sort.Slice(d.Projects, func(i, j int) bool {
+		if d.Projects[i].Minutes != d.Projects[j].Minutes {
+			return d.Projects[i].Minutes > d.Projects[j].Minutes
+		}
+		return d.Projects[i].Name < d.Projects[j].Name
+	})
+
+	for _, s := range skips {
+		covered = append(covered, audit.Interval{Start: s.StartTime, End: s.EndTime})
+	}
+	d.Gaps = audit.Gaps(audit.WorkHours(sched, d.From, d.To, d.To), covered, minGap)
+	return d, nil
+}
+
+// Week returns the ISO week, e.g. "2026-W41".
+func (d *Digest) Week() string {
+	year, week := d.From.ISOWeek()
+	return fmt.Sprintf("%d-W%02d", year, week)
+}
+
+func hm(minutes int) string {
+	return fmt.Sprintf("%dh %02dmin", minutes/60, minutes%60)
+}
+
+// Summary is a short plain-text version for desktop notifications.
+func (d *Digest) Summary() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s logged", hm(d.TotalMinutes))
+	if len(d.Projects) > 0 {
+		fmt.Fprintf(&b, ", most on %s", d.Projects[0].Name)
+	}
+	if gap := gapMinutes(d.Gaps); gap > 0 {
+		fmt.Fprintf(&b, "; %s unlogged", hm(gap))
+	}
+	if n := len(d.Failed); n > 0 {
+		fmt.Fprintf(&b, "; %d failed to reach Clockify", n)
+	}
+	return b.String()
+}
+
+func gapMinutes(gaps []audit.Interval) int {
+	total := 0
+	for _, g := range gaps {
+		total += g.Minutes()
+	}
+	return total
+}
+
+// Markdown renders the full digest.
+func (d *Digest) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Week %s (%s – %s)\n\n", d.Week(), d.From.Format("Mon 2006-01-02"), d.To.AddDate(0, 0, -1).Format("Mon 2006-01-02"))
+	fmt.Fprintf(&b, "**Total:** %s", hm(d.TotalMinutes))
+	if d.OvertimeMinutes > 0 {
+		fmt.Fprintf(&b, " (+%s overtime)", hm(d.OvertimeMinutes))
+	}
+	b.WriteString("\n")
+
+	if len(d.Projects) > 0 {
+		b.WriteString("\n## Projects\n\n| Project | Time |\n|---------|------|\n")
+		for _, p := range d.Projects {
+			fmt.Fprintf(&b, "| %s | %s |\n", p.Name, hm(p.Minutes))
+		}
+	}
+
+	if len(d.Gaps) > 0 {
+		fmt.Fprintf(&b, "\n## Gaps (%s unlogged)\n\n", hm(gapMinutes(d.Gaps)))
+		for _, g := range d.Gaps {
+			fmt.Fprintf(&b, "- %s %s–%s\n", g.Start.Format("Mon 2006-01-02"), g.Start.Format("15:04"), g.End.Format("15:04"))
+		}
+		b.WriteString("\nFill them with `clockr gaps --from " + d.From.Format("2006-01-02") + " --to " + d.To.AddDate(0, 0, -1).Format("2006-01-02") + "`.\n")
+	}
+
+	if len(d.Failed) > 0 {
+		b.WriteString("\n## Failed entries\n\n")
+		for _, e := range d.Failed {
+			fmt.Fprintf(&b, "- %s %s–%s %s: %s\n", e.StartTime.Format("Mon 2006-01-02"), e.StartTime.Format("15:04"),
+				e.EndTime.Format("15:04"), e.ProjectName, e.Description)
+		}
+		b.WriteString("\nRun `clockr retry` to submit them again.\n")
+	}
+	return b.String()
+}
+
+// WriteFile writes the markdown digest to path, with {week} replaced, and
+// returns the path written.
+func (d *Digest) WriteFile(path string) (string, error) {
+	path = strings.ReplaceAll(path, "{week}", d.Week())
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("creating digest directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(d.Markdown()), 0644); err != nil {
+		return "", fmt.Errorf("writing digest: %w", err)
+	}
+	return path, nil
+}
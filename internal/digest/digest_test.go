@@ -0,0 +1,80 @@
+package digest
+
+import (
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/audit"
+	"github.com/christopherklint97/clockr/internal/config"
+	"github.com/christopherklint97/clockr/internal/store"
+)
+
+func testDigest() *Digest {
+	mon := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	return &Digest{
+		From:         mon,
+		To:           mon.AddDate(0, 0, 7),
+		Projects:     []ProjectTotal{{Name: "Acme / Website", Minutes: 600}, {Name: "Internal", Minutes: 90}},
+		TotalMinutes: 690,
+		Gaps:         []audit.Interval{{Start: mon.Add(13 * time.Hour), End: mon.Add(14 * time.Hour)}},
+		Failed: []store.Entry{{
+			ProjectName: "Internal", Description: "Planning",
+			StartTime: mon.Add(9 * time.Hour), EndTime: mon.Add(10 * time.Hour),
+		}},
+	}
+}
+
+func TestWeekBefore(t *testing.T) {
+	wed := time.Date(2026, 3, 11, 15, 0, 0, 0, time.UTC)
+	if got, want := WeekBefore(wed), time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("WeekBefore() = %v, want %v", got, want)
+	}
+	sun := time.Date(2026, 3, 15, 15, 0, 0, 0, time.UTC)
+	if got, want := WeekBefore(sun), time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("WeekBefore(Sunday) = %v, want %v", got, want)
+	}
+}
+
+func TestMarkdownAndSummary(t *testing.T) {
+	d := testDigest()
+	md := d.Markdown()
+	for _, want := range []string{
+		"# Week 2026-W10 (Mon 2026-03-02 – Sun 2026-03-08)",
+		"**Total:** 11h 30min",
+		"| Acme / Website | 10h 00min |",
+		"## Gaps (1h 00min unlogged)",
+		"- Mon 2026-03-02 13:00–14:00",
+		"## Failed entries",
+		"clockr retry",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q:\n%s", want, md)
+		}
+	}
+	if got, want := d.Summary(), "11h 30min logged, most on Acme / Website; 1h 00min unlogged; 1 failed to reach Clockify"; got != want {
+		t.Errorf("Summary() = %q, want %q", got, want)
+	}
+}
+
+func TestEmail(t *testing.T) {
+	var gotAddr string
+	var gotMsg []byte
+	sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		gotAddr, gotMsg = addr, msg
+		return nil
+	}
+	defer func() { sendMail = smtp.SendMail }()
+
+	cfg := config.EmailConfig{To: []string{"me@example.com"}, From: "clockr@example.com", Host: "smtp.example.com"}
+	if err := testDigest().Email(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if gotAddr != "smtp.example.com:587" {
+		t.Errorf("addr = %q, want the default port 587", gotAddr)
+	}
+	if !strings.Contains(string(gotMsg), "Subject: clockr week 2026-W10: 11h 30min\r\n") {
+		t.Errorf("message missing subject:\n%s", gotMsg)
+	}
+}
@@ -0,0 +1,44 @@
+package digest
+
+import (
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/config"
+)
+
+// sendMail is smtp.SendMail, replaced in tests.
+var sendMail = smtp.SendMail
+
+// Email sends the markdown digest as a plain-text mail.
+func (d *Digest) Email(cfg config.EmailConfig) error {
+	port := cfg.Port
+	if port == 0 {
+		port = 587
+	}
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(port))
+	var auth smtp.Auth
+	if cfg.Username != "" {
+		auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+	if err := sendMail(addr, auth, cfg.From, cfg.To, d.message(cfg)); err != nil {
+		return fmt.Errorf("sending digest email: %w", err)
+	}
+	return nil
+}
+
+func (d *Digest) message(cfg config.EmailConfig) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", cfg.From)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(cfg.To, ", "))
+	fmt.Fprintf(&b, "Subject: clockr week %s: %s\r\n", d.Week(), hm(d.TotalMinutes))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(d.Markdown(), "\n", "\r\n"))
+	return []byte(b.String())
+}
@@ -0,0 +1,94 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// CSV is the generic profile: one comma-separated row per entry with a
+// header, times in 24-hour format and hours with a decimal point.
+type CSV struct{}
+
+func (CSV) Export(w io.Writer, r Report) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"Date", "Start", "End", "Minutes", "Hours", "Client", "Project", "Description", "Overtime"})
+	for _, row := range r.Rows {
+		cw.Write([]string{
+			row.Date.Format("2006-01-02"),
+			row.Start.Format("15:04"),
+			row.End.Format("15:04"),
+			strconv.Itoa(row.Minutes),
+			strconv.FormatFloat(row.Hours(), 'f', 2, 64),
+			row.Client,
+			row.Project,
+			row.Description,
+			strconv.FormatBool(row.Overtime),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// DATEV writes the semicolon-separated layout German DATEV imports expect:
+// DD.MM.YYYY dates, decimal commas and a UTF-8 byte order mark so Excel and
+// DATEV detect the encoding.
+type DATEV struct{}
+
+func (DATEV) Export(w io.Writer, r Report) error {
+	if _, err := io.WriteString(w, "\ufeff"); err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	cw.Comma = ';'
+	cw.UseCRLF = true
+	cw.Write([]string{"Datum", "Mitarbeiter", "Beginn", "Ende", "Dauer (Std.)", "Kunde", "Projekt", "Tätigkeit", "Überstunden"})
+	for _, row := range r.Rows {
+		overtime := "Nein"
+		if row.Overtime {
+			overtime = "Ja"
+		}
+		cw.Write([]string{
+			row.Date.Format("02.01.2006"),
+			r.Employee,
+			row.Start.Format("15:04"),
+			row.End.Format("15:04"),
+			strings.Replace(strconv.FormatFloat(row.Hours(), 'f', 2, 64), ".", ",", 1),
+			row.Client,
+			row.Project,
+			row.Description,
+			overtime,
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// QuickBooks writes the columns of a QuickBooks time activity import:
+// MM/DD/YYYY dates, the customer as "Client:Project" and the duration as
+// HH:MM.
+type QuickBooks struct{}
+
+func (QuickBooks) Export(w io.Writer, r Report) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"Activity Date", "Employee", "Customer", "Service", "Duration", "Billable", "Description"})
+	for _, row := range r.Rows {
+		customer := row.Project
+		if row.Client != "" {
+			customer = row.Client + ":" + row.Project
+		}
+		cw.Write([]string{
+			row.Date.Format("01/02/2006"),
+			r.Employee,
+			customer,
+			row.Project,
+			fmt.Sprintf("%d:%02d", row.Minutes/60, row.Minutes%60),
+			"Yes",
+			row.Description,
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,89 @@
+// Package export writes logged entries in formats that accounting and
+// payroll tools import: a generic CSV, DATEV and QuickBooks CSV profiles,
+// and user-supplied text/template files.
+package export
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/store"
+)
+
+// Exporter writes entries in one format.
+type Exporter interface {
+	Export(w io.Writer, r Report) error
+}
+
+// Report is what an exporter writes: the entries of a period, oldest first.
+type Report struct {
+	From     time.Time // first day
+	To       time.Time // last day, inclusive
+	Employee string    // [export] employee, for profiles that need a name
+	Rows     []Row
+}
+
+// Row is one entry prepared for export.
+type Row struct {
+	Date        time.Time // midnight of the entry's start day
+	Start       time.Time
+	End         time.Time
+	Minutes     int
+	Client      string
+	Project     string
+	Description string
+	Overtime    bool
+}
+
+// Hours returns the row's length in hours.
+func (r Row) Hours() float64 {
+	return float64(r.Minutes) / 60
+}
+
+// NewReport builds a report from entries, in each entry's own time zone.
+func NewReport(from, to time.Time, employee string, entries []store.Entry) Report {
+	r := Report{From: from, To: to, Employee: employee}
+	for _, e := range entries {
+		loc := e.Location()
+		start, end := e.StartTime.In(loc), e.EndTime.In(loc)
+		r.Rows = append(r.Rows, Row{
+			Date:        time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc),
+			Start:       start,
+			End:         end,
+			Minutes:     e.Minutes,
+			Client:      e.ClientName,
+			Project:     e.ProjectName,
+			Description: e.Description,
+			Overtime:    e.Overtime,
+		})
+	}
+	sort.SliceStable(r.Rows, func(i, j int) bool { return r.Rows[i].Start.Before(r.Rows[j].Start) })
+	return r
+}
+
+// Profiles are the built-in exporters by name, for --profile.
+var Profiles = map[string]Exporter{
+	"csv":        CSV{},
+	"datev":      DATEV{},
+	"quickbooks": QuickBooks{},
+}
+
+// ProfileNames lists the --profile values, built-in profiles first.
+var ProfileNames = []string{"csv", "datev", "quickbooks", "template"}
+
+// New returns the exporter for profile. The "template" profile reads its
+// template from templatePath.
+func New(profile, templatePath string) (Exporter, error) {
+	if profile == "template" {
+		if templatePath == "" {
+			return nil, fmt.Errorf(`the "template" profile needs a template file (--template or [export] template)`)
+		}
+		return LoadTemplate(templatePath)
+	}
+	if e, ok := Profiles[profile]; ok {
+		return e, nil
+	}
+	return nil, fmt.Errorf("unknown export profile %q (use csv, datev, quickbooks or template)", profile)
+}
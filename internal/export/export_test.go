@@ -0,0 +1,79 @@
+package export
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/store"
+)
+
+func testReport() Report {
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	entries := []store.Entry{
+		{ProjectName: "Internal", Description: "Planning", StartTime: start.Add(2 * time.Hour), EndTime: start.Add(2*time.Hour + 30*time.Minute), Minutes: 30, Timezone: "UTC"},
+		{ProjectName: "Website", ClientName: "Acme", Description: `Landing page, "hero"`, StartTime: start, EndTime: start.Add(90 * time.Minute), Minutes: 90, Timezone: "UTC"},
+	}
+	return NewReport(start, start, "Ada Lovelace", entries)
+}
+
+func export(t *testing.T, e Exporter) string {
+	t.Helper()
+	var b strings.Builder
+	if err := e.Export(&b, testReport()); err != nil {
+		t.Fatal(err)
+	}
+	return b.String()
+}
+
+func TestCSV(t *testing.T) {
+	got := export(t, CSV{})
+	want := "Date,Start,End,Minutes,Hours,Client,Project,Description,Overtime\n" +
+		"2026-03-02,09:00,10:30,90,1.50,Acme,Website,\"Landing page, \"\"hero\"\"\",false\n" +
+		"2026-03-02,11:00,11:30,30,0.50,,Internal,Planning,false\n"
+	if got != want {
+		t.Errorf("CSV:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestDATEV(t *testing.T) {
+	got := export(t, DATEV{})
+	if !strings.HasPrefix(got, "\ufeffDatum;Mitarbeiter;") {
+		t.Errorf("DATEV export should start with a BOM and the header, got %q", got[:30])
+	}
+	if want := "02.03.2026;Ada Lovelace;09:00;10:30;1,50;Acme;Website;\"Landing page, \"\"hero\"\"\";Nein\r\n"; !strings.Contains(got, want) {
+		t.Errorf("DATEV export missing %q:\n%s", want, got)
+	}
+}
+
+func TestQuickBooks(t *testing.T) {
+	got := export(t, QuickBooks{})
+	if want := "03/02/2026,Ada Lovelace,Acme:Website,Website,1:30,Yes,"; !strings.Contains(got, want) {
+		t.Errorf("QuickBooks export missing %q:\n%s", want, got)
+	}
+}
+
+func TestTemplate(t *testing.T) {
+	tmpl, err := ParseTemplate("test", `{{range .Rows}}{{.Date.Format "2006-01-02"}};{{csv .Description}};{{decimal .Hours 1 ","}}
+{{end}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := export(t, tmpl)
+	want := "2026-03-02;\"Landing page, \"\"hero\"\"\";1,5\n2026-03-02;Planning;0,5\n"
+	if got != want {
+		t.Errorf("template export:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New("datev", ""); err != nil {
+		t.Errorf("New(datev): %v", err)
+	}
+	if _, err := New("template", ""); err == nil {
+		t.Error("the template profile without a file should fail")
+	}
+	if _, err := New("sap", ""); err == nil {
+		t.Error("an unknown profile should fail")
+	}
+}
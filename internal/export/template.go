@@ -0,0 +1,57 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// Template is an exporter driven by a text/template file. The template runs
+// once with the Report as its data; see README for the fields and functions.
+type Template struct {
+	tmpl *template.Template
+}
+
+// templateFuncs help templates produce CSV and locale-specific numbers.
+var templateFuncs = template.FuncMap{
+	// csv quotes a field for comma-separated output when it needs it.
+	"csv": func(s string) string {
+		if strings.ContainsAny(s, ",\"\r\n") {
+			return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+		}
+		return s
+	},
+	// decimal formats f with the given places and decimal separator.
+	"decimal": func(f float64, places int, sep string) string {
+		return strings.Replace(strconv.FormatFloat(f, 'f', places, 64), ".", sep, 1)
+	},
+}
+
+// ParseTemplate parses an export template.
+func ParseTemplate(name, text string) (*Template, error) {
+	t, err := template.New(name).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parsing export template: %w", err)
+	}
+	return &Template{tmpl: t}, nil
+}
+
+// LoadTemplate reads and parses an export template file.
+func LoadTemplate(path string) (*Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading export template: %w", err)
+	}
+	return ParseTemplate(filepath.Base(path), string(data))
+}
+
+func (t *Template) Export(w io.Writer, r Report) error {
+	if err := t.tmpl.Execute(w, r); err != nil {
+		return fmt.Errorf("running export template: %w", err)
+	}
+	return nil
+}
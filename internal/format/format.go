@@ -0,0 +1,140 @@
+// Package format applies the [format] rules to entry descriptions: a
+// per-project prefix, casing and trailing-period removal, plus length and
+// pattern checks. Rounding gives the rule for entry lengths.
+package format
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/christopherklint97/clockr/internal/clockify"
+	"github.com/christopherklint97/clockr/internal/config"
+)
+
+// Formatter rewrites descriptions. A nil Formatter leaves them unchanged.
+type Formatter struct {
+	prefixes    map[string]string // lowercased key → prefix
+	stripPeriod bool
+	casing      string
+	maxLength   int
+	pattern     *regexp.Regexp
+}
+
+// New returns a Formatter for cfg, or nil when cfg sets no rules.
+func New(cfg config.FormatConfig) *Formatter {
+	if len(cfg.Prefixes) == 0 && !cfg.StripTrailingPeriod && cfg.Case == "" && cfg.MaxLength <= 0 && cfg.Pattern == "" {
+		return nil
+	}
+	f := &Formatter{
+		prefixes:    make(map[string]string, len(cfg.Prefixes)),
+		stripPeriod: cfg.StripTrailingPeriod,
+		casing:      cfg.Case,
+		maxLength:   cfg.MaxLength,
+	}
+	// An invalid pattern is reported by config validation; here it is
+	// simply not checked.
+	if cfg.Pattern != "" {
+		f.pattern, _ = regexp.Compile(cfg.Pattern)
+	}
+	for k, v := range cfg.Prefixes {
+		f.prefixes[strings.ToLower(k)] = v
+	}
+	return f
+}
+
+// Description formats desc for an entry on the given project. It is
+// idempotent: a prefix already in place (from any project) is replaced
+// rather than repeated, so re-formatting after an edit is safe.
+func (f *Formatter) Description(projectID, projectName, clientName, desc string) string {
+	if f == nil {
+		return desc
+	}
+	desc = strings.TrimSpace(f.trimPrefix(strings.TrimSpace(desc)))
+
+	switch f.casing {
+	case "sentence":
+		desc = upperFirst(desc)
+	case "title":
+		words := strings.Fields(desc)
+		for i, w := range words {
+			words[i] = upperFirst(w)
+		}
+		desc = strings.Join(words, " ")
+	}
+	if f.stripPeriod {
+		// Keep an ellipsis; only drop a final full stop.
+		for strings.HasSuffix(desc, ".") && !strings.HasSuffix(desc, "...") {
+			desc = strings.TrimSuffix(desc, ".")
+		}
+	}
+
+	return f.prefix(projectID, projectName, clientName) + desc
+}
+
+// Check returns what is wrong with an already formatted description under
+// the max_length and pattern rules, or nil when it passes.
+func (f *Formatter) Check(desc string) []string {
+	if f == nil {
+		return nil
+	}
+	var problems []string
+	if n := utf8.RuneCountInString(desc); f.maxLength > 0 && n > f.maxLength {
+		problems = append(problems, fmt.Sprintf("%d characters, over the limit of %d", n, f.maxLength))
+	}
+	if f.pattern != nil && !f.pattern.MatchString(desc) {
+		problems = append(problems, fmt.Sprintf("doesn't match %s", f.pattern))
+	}
+	return problems
+}
+
+// prefix returns the prefix for a project: by ID, then name, then client.
+func (f *Formatter) prefix(projectID, projectName, clientName string) string {
+	for _, key := range []string{projectID, projectName, clientName} {
+		if key == "" {
+			continue
+		}
+		if p, ok := f.prefixes[strings.ToLower(key)]; ok {
+			return p
+		}
+	}
+	return ""
+}
+
+// trimPrefix removes the longest configured prefix desc starts with.
+func (f *Formatter) trimPrefix(desc string) string {
+	longest := ""
+	for _, p := range f.prefixes {
+		if p != "" && strings.HasPrefix(desc, p) && len(p) > len(longest) {
+			longest = p
+		}
+	}
+	return strings.TrimPrefix(desc, longest)
+}
+
+func upperFirst(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError || unicode.IsUpper(r) {
+		return s
+	}
+	return string(unicode.ToUpper(r)) + s[size:]
+}
+
+// Rounding returns the rule round_minutes and round in cfg set for entry
+// lengths, or no rounding.
+func Rounding(cfg config.FormatConfig) clockify.Rounding {
+	if cfg.RoundMinutes <= 1 {
+		return clockify.Rounding{}
+	}
+	round := "Round to nearest"
+	switch cfg.Round {
+	case "up":
+		round = "Round up to"
+	case "down":
+		round = "Round down to"
+	}
+	return clockify.Rounding{Round: round, Minutes: strconv.Itoa(cfg.RoundMinutes)}
+}
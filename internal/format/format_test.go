@@ -0,0 +1,69 @@
+package format
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/christopherklint97/clockr/internal/config"
+)
+
+func TestDescription(t *testing.T) {
+	f := New(config.FormatConfig{
+		Prefixes:            map[string]string{"p1": "DEV/", "Meetings": "MTG/", "acme": "🐛 "},
+		StripTrailingPeriod: true,
+		Case:                "sentence",
+	})
+	tests := []struct {
+		name                      string
+		id, project, client, desc string
+		want                      string
+	}{
+		{"by project ID", "p1", "Backend", "", "fixed auth bug.", "DEV/Fixed auth bug"},
+		{"by project name", "p2", "meetings", "", "weekly sync", "MTG/Weekly sync"},
+		{"by client", "p3", "Website", "Acme", "landing page", "🐛 Landing page"},
+		{"no prefix", "p4", "Other", "", "admin.", "Admin"},
+		{"idempotent", "p1", "Backend", "", "DEV/Fixed auth bug", "DEV/Fixed auth bug"},
+		{"project changed", "p2", "Meetings", "", "DEV/planning", "MTG/Planning"},
+		{"ellipsis kept", "p4", "Other", "", "more to come...", "More to come..."},
+	}
+	for _, tt := range tests {
+		if got := f.Description(tt.id, tt.project, tt.client, tt.desc); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDescription_TitleCase(t *testing.T) {
+	f := New(config.FormatConfig{Case: "title"})
+	if got := f.Description("p1", "", "", "reviewed  API docs"); got != "Reviewed API Docs" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestNew_NoRules(t *testing.T) {
+	f := New(config.FormatConfig{})
+	if f != nil {
+		t.Fatal("expected nil formatter without rules")
+	}
+	if got := f.Description("p1", "", "", "as is. "); got != "as is. " {
+		t.Errorf("nil formatter changed the description: %q", got)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	f := New(config.FormatConfig{MaxLength: 20, Pattern: `^[A-Z]+-\d+: `})
+	if got := f.Check("ABC-12: Fix login"); got != nil {
+		t.Errorf("valid description flagged: %v", got)
+	}
+	if got := f.Check("Fix the login redirect loop"); len(got) != 2 {
+		t.Errorf("expected a length and a pattern problem, got %v", got)
+	}
+	if got := f.Check("ABC-12: Fix the login redirect"); len(got) != 1 || !strings.Contains(got[0], "over the limit of 20") {
+		t.Errorf("expected a length problem, got %v", got)
+	}
+
+	// The length is counted in characters, not bytes.
+	if got := New(config.FormatConfig{MaxLength: 3}).Check("🐛🐛🐛"); got != nil {
+		t.Errorf("three emoji flagged as too long: %v", got)
+	}
+}
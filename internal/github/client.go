@@ -6,13 +6,15 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
-	"math"
 	"net/http"
 	"os"
 	"os/exec"
 	"sort"
 	"strings"
 	"time"
+
+	"github.com/christopherklint97/clockr/internal/httpmetrics"
+	"github.com/christopherklint97/clockr/internal/httpretry"
 )
 
 const defaultBaseURL = "https://api.github.com"
@@ -48,6 +50,7 @@ type CommitContext struct {
 	Repo    string
 	Message string // formatted: "reponame: commit msg"
 	Date    time.Time
+	PR      bool // a merged pull request rather than a commit
 }
 
 // Client is a GitHub API client with retry logic.
@@ -95,12 +98,19 @@ func NewClient(token string, logger *slog.Logger) *Client {
 		token:   token,
 		baseURL: defaultBaseURL,
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   30 * time.Second,
+			Transport: httpmetrics.Wrap("github", nil),
 		},
 		logger: logger,
 	}
 }
 
+// SetTransport replaces the HTTP transport, e.g. with an ETag cache.
+// Requests are still counted by httpmetrics.
+func (c *Client) SetTransport(rt http.RoundTripper) {
+	c.httpClient.Transport = httpmetrics.Wrap("github", rt)
+}
+
 func (c *Client) doRequest(ctx context.Context, method, path string) ([]byte, error) {
 	url := c.baseURL + path
 	req, err := http.NewRequestWithContext(ctx, method, url, nil)
@@ -111,29 +121,23 @@ func (c *Client) doRequest(ctx context.Context, method, path string) ([]byte, er
 	req.Header.Set("Authorization", "Bearer "+c.token)
 	req.Header.Set("Accept", "application/vnd.github+json")
 
-	var resp *http.Response
-	maxRetries := 3
-	for attempt := 0; attempt <= maxRetries; attempt++ {
-		resp, err = c.httpClient.Do(req)
+	policy := httpretry.Default
+	policy.OnRetry = func(attempt int, wait time.Duration, resp *http.Response, err error) {
 		if err != nil {
-			if attempt == maxRetries {
-				c.logger.Error("GitHub API transport error", "method", method, "path", path, "error", err)
-				return nil, fmt.Errorf("sending request: %w", err)
-			}
-			time.Sleep(backoff(attempt))
-			continue
-		}
-
-		if resp.StatusCode == 429 || resp.StatusCode >= 500 {
-			resp.Body.Close()
-			if attempt == maxRetries {
-				c.logger.Error("GitHub API failed after retries", "method", method, "path", path, "status", resp.StatusCode)
-				return nil, fmt.Errorf("GitHub API returned status %d after %d retries", resp.StatusCode, maxRetries)
-			}
-			time.Sleep(backoff(attempt))
-			continue
+			c.logger.Debug("GitHub API transport error, retrying", "method", method, "path", path, "attempt", attempt, "wait", wait, "error", err)
+			return
 		}
-		break
+		c.logger.Debug("GitHub API retrying", "method", method, "path", path, "status", resp.StatusCode, "attempt", attempt, "wait", wait)
+	}
+	resp, err := httpretry.Do(c.httpClient, req, policy)
+	if err != nil {
+		c.logger.Error("GitHub API transport error", "method", method, "path", path, "error", err)
+		return nil, fmt.Errorf("sending request: %w", err)
+	}
+	if httpretry.Retryable(resp.StatusCode) {
+		resp.Body.Close()
+		c.logger.Error("GitHub API failed after retries", "method", method, "path", path, "status", resp.StatusCode)
+		return nil, fmt.Errorf("GitHub API returned status %d after retries", resp.StatusCode)
 	}
 	defer resp.Body.Close()
 
@@ -150,10 +154,6 @@ func (c *Client) doRequest(ctx context.Context, method, path string) ([]byte, er
 	return body, nil
 }
 
-func backoff(attempt int) time.Duration {
-	return time.Duration(math.Pow(2, float64(attempt))) * time.Second
-}
-
 func truncate(s string, maxLen int) string {
 	if len(s) <= maxLen {
 		return s
@@ -388,6 +388,7 @@ func Fetch(ctx context.Context, client *Client, repos []string, start, end time.
 				Repo:    pr.Repo,
 				Message: fmt.Sprintf("%s: PR #%d %s", pr.Repo, pr.Number, pr.Title),
 				Date:    pr.MergedAt,
+				PR:      true,
 			})
 		}
 	}
@@ -409,14 +410,53 @@ func GroupByDay(items []CommitContext) map[string][]CommitContext {
 	return grouped
 }
 
-// FormatPrefill joins commit context messages with "; " for use as TUI textarea prefill.
-func FormatPrefill(items []CommitContext) string {
-	if len(items) == 0 {
-		return ""
+// TokenScopes returns the OAuth scopes granted to the token, read from the
+// X-OAuth-Scopes header. classic is false for fine-grained tokens and GitHub
+// App tokens, which don't report scopes.
+func (c *Client) TokenScopes(ctx context.Context) (scopes []string, classic bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/user", nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, false, fmt.Errorf("sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, false, fmt.Errorf("GitHub token is invalid or expired")
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, false, fmt.Errorf("GitHub API error (status %d)", resp.StatusCode)
+	}
+
+	header, ok := resp.Header["X-Oauth-Scopes"]
+	if !ok {
+		return nil, false, nil
+	}
+	for _, h := range header {
+		for _, s := range strings.Split(h, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				scopes = append(scopes, s)
+			}
+		}
+	}
+	return scopes, true, nil
+}
+
+// MissingScopeWarnings describes what clockr can't see with the given scopes.
+func MissingScopeWarnings(scopes []string, classic bool) []string {
+	if !classic {
+		return nil
 	}
-	msgs := make([]string, len(items))
-	for i, item := range items {
-		msgs[i] = item.Message
+	for _, s := range scopes {
+		if s == "repo" {
+			return nil
+		}
 	}
-	return strings.Join(msgs, "; ")
+	return []string{"GitHub token lacks repo scope — private repo commits and PRs will be invisible"}
 }
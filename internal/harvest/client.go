@@ -0,0 +1,409 @@
+// Package harvest logs time to Harvest (API v2) for [backend] type =
+// "harvest". It implements backend.Backend, translating projects and time
+// entries to the Clockify types the rest of clockr uses.
+package harvest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/clockify"
+	"github.com/christopherklint97/clockr/internal/httpmetrics"
+	"github.com/christopherklint97/clockr/internal/httpretry"
+)
+
+const defaultBaseURL = "https://api.harvestapp.com/v2"
+
+// userAgent is required by Harvest to identify the application.
+const userAgent = "clockr (https://github.com/christopherklint97/clockr)"
+
+// ErrReadOnly is returned for any non-GET request while the client is read-only.
+var ErrReadOnly = errors.New("read-only mode: Harvest writes are disabled")
+
+// APIError is returned when Harvest responds with a non-2xx status.
+type APIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("Harvest API error (status %d): %s", e.StatusCode, e.Body)
+}
+
+// Client is a Harvest API client for one account and user.
+type Client struct {
+	accountID  string
+	token      string
+	task       string // task name to log to; "" for each project's first
+	baseURL    string
+	httpClient *http.Client
+	logger     *slog.Logger
+	readOnly   bool
+	loc        *time.Location
+
+	mu     sync.Mutex
+	tasks  map[string]taskRef // project ID → task entries are logged to
+	userID int64              // looked up on the first ListEntries
+}
+
+type taskRef struct {
+	ID   int64
+	Name string
+}
+
+// NewClient returns a client for the account, authenticated with a personal
+// access token. Entries are logged to the task named task on each project,
+// or its first active task when task is empty.
+func NewClient(accountID, token, task, baseURL string, logger *slog.Logger) *Client {
+	if logger == nil {
+		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+	return &Client{
+		accountID: accountID,
+		token:     token,
+		task:      task,
+		baseURL:   strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: httpmetrics.Wrap("harvest", nil),
+		},
+		logger: logger,
+		loc:    time.Local,
+	}
+}
+
+// SetTimeout limits how long each request may take.
+func (c *Client) SetTimeout(d time.Duration) {
+	c.httpClient.Timeout = d
+}
+
+// SetReadOnly blocks all write requests (anything other than GET) when enabled.
+func (c *Client) SetReadOnly(readOnly bool) {
+	c.readOnly = readOnly
+}
+
+// SetLocation sets the zone Harvest's dates and clock times are in, the
+// schedule's zone. The default is time.Local.
+func (c *Client) SetLocation(loc *time.Location) {
+	c.loc = loc
+}
+
+func (c *Client) Name() string { return "Harvest" }
+
+func (c *Client) doRequest(ctx context.Context, method, path string, body any) ([]byte, error) {
+	if c.readOnly && method != http.MethodGet {
+		c.logger.Debug("blocked write in read-only mode", "method", method, "path", path)
+		return nil, ErrReadOnly
+	}
+
+	c.logger.Debug("harvest API request", "method", method, "path", path)
+	status, respBody, err := httpretry.JSON(ctx, c.httpClient, httpretry.Default, c.logger, "Harvest", method, c.baseURL+path, body, func(req *http.Request) {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+		req.Header.Set("Harvest-Account-Id", c.accountID)
+		req.Header.Set("User-Agent", userAgent)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if status < 200 || status >= 300 {
+		c.logger.Error("Harvest API request failed", "method", method, "path", path, "status", status)
+		return nil, &APIError{StatusCode: status, Body: string(respBody)}
+	}
+	return respBody, nil
+}
+
+type projectAssignment struct {
+	IsActive bool `json:"is_active"`
+	Project  struct {
+		ID   int64  `json:"id"`
+		Name string `json:"name"`
+	} `json:"project"`
+	Client struct {
+		ID   int64  `json:"id"`
+		Name string `json:"name"`
+	} `json:"client"`
+	TaskAssignments []struct {
+		IsActive bool `json:"is_active"`
+		Task     struct {
+			ID   int64  `json:"id"`
+			Name string `json:"name"`
+		} `json:"task"`
+	} `json:"task_assignments"`
+}
+
+// ListProjects returns the active projects the user is assigned to, and
+// remembers the task to log to on each.
+func (c *Client) ListProjects(ctx context.Context) ([]clockify.Project, error) {
+	var assignments []projectAssignment
+	for page := 1; page > 0; {
+		data, err := c.doRequest(ctx, http.MethodGet, fmt.Sprintf("/users/me/project_assignments?is_active=true&per_page=100&page=%d", page), nil)
+		if err != nil {
+			return nil, fmt.Errorf("fetching projects: %w", err)
+		}
+		var resp struct {
+			ProjectAssignments []projectAssignment `json:"project_assignments"`
+			NextPage           int                 `json:"next_page"`
+		}
+		if err := json.Unmarshal(data, &resp); err != nil {
+			return nil, fmt.Errorf("parsing projects: %w", err)
+		}
+		assignments = append(assignments, resp.ProjectAssignments...)
+		page = resp.NextPage
+	}
+
+	tasks := make(map[string]taskRef)
+	var projects []clockify.Project
+	for _, a := range assignments {
+		if !a.IsActive {
+			continue
+		}
+		id := strconv.FormatInt(a.Project.ID, 10)
+		projects = append(projects, clockify.Project{
+			ID:         id,
+			Name:       a.Project.Name,
+			ClientID:   strconv.FormatInt(a.Client.ID, 10),
+			ClientName: a.Client.Name,
+		})
+		for _, ta := range a.TaskAssignments {
+			if !ta.IsActive {
+				continue
+			}
+			if _, ok := tasks[id]; !ok || strings.EqualFold(ta.Task.Name, c.task) {
+				tasks[id] = taskRef{ID: ta.Task.ID, Name: ta.Task.Name}
+			}
+		}
+	}
+	c.mu.Lock()
+	c.tasks = tasks
+	c.mu.Unlock()
+	return projects, nil
+}
+
+// taskFor returns the task to log to on projectID, fetching the projects
+// when they haven't been yet.
+func (c *Client) taskFor(ctx context.Context, projectID string) (taskRef, error) {
+	c.mu.Lock()
+	task, ok := c.tasks[projectID]
+	loaded := c.tasks != nil
+	c.mu.Unlock()
+	if ok {
+		return task, nil
+	}
+	if !loaded {
+		if _, err := c.ListProjects(ctx); err != nil {
+			return taskRef{}, err
+		}
+		return c.taskFor(ctx, projectID)
+	}
+	return taskRef{}, fmt.Errorf("project %s has no active task you are assigned to in Harvest", projectID)
+}
+
+type timeEntry struct {
+	ID          int64   `json:"id"`
+	SpentDate   string  `json:"spent_date"`
+	Hours       float64 `json:"hours"`
+	Notes       string  `json:"notes"`
+	StartedTime string  `json:"started_time"`
+	EndedTime   string  `json:"ended_time"`
+	IsRunning   bool    `json:"is_running"`
+	Billable    bool    `json:"billable"`
+	Project     struct {
+		ID int64 `json:"id"`
+	} `json:"project"`
+	Task struct {
+		ID int64 `json:"id"`
+	} `json:"task"`
+}
+
+// toClockify converts e. Entries tracked by duration have no clock times;
+// they are placed at the start of their day.
+func (c *Client) toClockify(e timeEntry) clockify.TimeEntry {
+	out := clockify.TimeEntry{
+		ID:          strconv.FormatInt(e.ID, 10),
+		Description: e.Notes,
+		ProjectID:   strconv.FormatInt(e.Project.ID, 10),
+		TaskID:      strconv.FormatInt(e.Task.ID, 10),
+		Billable:    e.Billable,
+	}
+	day, err := time.ParseInLocation("2006-01-02", e.SpentDate, c.loc)
+	if err != nil {
+		return out
+	}
+	start := day
+	if t, ok := parseClock(day, e.StartedTime); ok {
+		start = t
+	}
+	out.TimeInterval.Start = start
+	if !e.IsRunning {
+		out.TimeInterval.End = start.Add(time.Duration(e.Hours * float64(time.Hour)))
+		if t, ok := parseClock(day, e.EndedTime); ok && t.After(start) {
+			out.TimeInterval.End = t
+		}
+	}
+	return out
+}
+
+// parseClock reads Harvest's clock times, "8:00am" or "08:00" depending on
+// the account's settings, on day.
+func parseClock(day time.Time, s string) (time.Time, bool) {
+	for _, layout := range []string{"3:04pm", "15:04"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), 0, 0, day.Location()), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// CreateEntry logs entry on its start date, on the project's task (see
+// NewClient). The clock times are sent along with the hours, so accounts
+// that track start and end times keep them; duration-only accounts ignore
+// them.
+func (c *Client) CreateEntry(ctx context.Context, entry clockify.TimeEntryRequest) (*clockify.TimeEntry, error) {
+	start, err := time.Parse(time.RFC3339, entry.Start)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start %q: %w", entry.Start, err)
+	}
+	end, err := time.Parse(time.RFC3339, entry.End)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end %q: %w", entry.End, err)
+	}
+	projectID, err := strconv.ParseInt(entry.ProjectID, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%q is not a Harvest project ID", entry.ProjectID)
+	}
+	task, err := c.taskFor(ctx, entry.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	localStart, localEnd := start.In(c.loc), end.In(c.loc)
+	body := map[string]any{
+		"project_id":   projectID,
+		"task_id":      task.ID,
+		"spent_date":   localStart.Format("2006-01-02"),
+		"started_time": localStart.Format("3:04pm"),
+		"hours":        end.Sub(start).Hours(),
+		"notes":        entry.Description,
+	}
+	if localEnd.YearDay() == localStart.YearDay() && localEnd.Year() == localStart.Year() {
+		body["ended_time"] = localEnd.Format("3:04pm")
+	}
+	data, err := c.doRequest(ctx, http.MethodPost, "/time_entries", body)
+	if err != nil {
+		return nil, fmt.Errorf("creating time entry: %w", err)
+	}
+	var created timeEntry
+	if err := json.Unmarshal(data, &created); err != nil {
+		return nil, fmt.Errorf("parsing time entry response: %w", err)
+	}
+	out := c.toClockify(created)
+	// A duration-only account keeps no clock times; report the ones clockr
+	// logged.
+	out.TimeInterval.Start, out.TimeInterval.End = start, end
+	return &out, nil
+}
+
+// ListEntries returns the user's entries that start in [start, end).
+// Entries without clock times can't be placed in the day, so they are
+// matched by spent_date instead: one is returned when its day overlaps
+// [start, end), at the start of that day with its hours as duration.
+func (c *Client) ListEntries(ctx context.Context, start, end time.Time) ([]clockify.TimeEntry, error) {
+	userID, err := c.currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	q := url.Values{}
+	q.Set("user_id", strconv.FormatInt(userID, 10))
+	q.Set("from", start.In(c.loc).Format("2006-01-02"))
+	q.Set("to", end.In(c.loc).Format("2006-01-02"))
+	q.Set("per_page", "100")
+
+	var entries []clockify.TimeEntry
+	for page := 1; page > 0; {
+		q.Set("page", strconv.Itoa(page))
+		data, err := c.doRequest(ctx, http.MethodGet, "/time_entries?"+q.Encode(), nil)
+		if err != nil {
+			return nil, fmt.Errorf("fetching time entries: %w", err)
+		}
+		var resp struct {
+			TimeEntries []timeEntry `json:"time_entries"`
+			NextPage    int         `json:"next_page"`
+		}
+		if err := json.Unmarshal(data, &resp); err != nil {
+			return nil, fmt.Errorf("parsing time entries: %w", err)
+		}
+		for _, e := range resp.TimeEntries {
+			te := c.toClockify(e)
+			at := te.TimeInterval.Start
+			if _, timed := parseClock(at, e.StartedTime); !timed {
+				// at is midnight; the whole day has to overlap the range.
+				if at.Before(end) && at.AddDate(0, 0, 1).After(start) {
+					entries = append(entries, te)
+				}
+				continue
+			}
+			if !at.Before(start) && at.Before(end) {
+				entries = append(entries, te)
+			}
+		}
+		page = resp.NextPage
+	}
+	return entries, nil
+}
+
+func (c *Client) currentUserID(ctx context.Context) (int64, error) {
+	c.mu.Lock()
+	id := c.userID
+	c.mu.Unlock()
+	if id != 0 {
+		return id, nil
+	}
+	data, err := c.doRequest(ctx, http.MethodGet, "/users/me", nil)
+	if err != nil {
+		return 0, fmt.Errorf("getting user: %w", err)
+	}
+	var user struct {
+		ID int64 `json:"id"`
+	}
+	if err := json.Unmarshal(data, &user); err != nil {
+		return 0, fmt.Errorf("parsing user: %w", err)
+	}
+	c.mu.Lock()
+	c.userID = user.ID
+	c.mu.Unlock()
+	return user.ID, nil
+}
+
+// CheckAccess verifies the token authenticates for the account. Returns a
+// human-readable problem description, or "" if access looks fine.
+func (c *Client) CheckAccess(ctx context.Context) string {
+	if _, err := c.currentUserID(ctx); err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden) {
+			return "Harvest token is invalid or has no access to account " + c.accountID
+		}
+		return fmt.Sprintf("Harvest API unreachable: %v", err)
+	}
+	return ""
+}
+
+// DeleteEntry deletes the time entry with the given ID.
+func (c *Client) DeleteEntry(ctx context.Context, id string) error {
+	if _, err := c.doRequest(ctx, http.MethodDelete, "/time_entries/"+url.PathEscape(id), nil); err != nil {
+		return fmt.Errorf("deleting time entry: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package harvest
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/apitest"
+	"github.com/christopherklint97/clockr/internal/clockify"
+)
+
+// fakeHarvest serves one project with two tasks and records created entries.
+func fakeHarvest(t *testing.T) (*Client, *apitest.Server) {
+	t.Helper()
+	srv := apitest.New(t)
+	auth := func(r *http.Request) bool {
+		return r.Header.Get("Harvest-Account-Id") == "42" && apitest.Bearer("tok")(r)
+	}
+	srv.Reply("GET /users/me/project_assignments", auth, `{"project_assignments": [{
+		"is_active": true,
+		"project": {"id": 7, "name": "Website"},
+		"client": {"id": 3, "name": "Acme"},
+		"task_assignments": [
+			{"is_active": true, "task": {"id": 100, "name": "Design"}},
+			{"is_active": true, "task": {"id": 101, "name": "Development"}}
+		]
+	}], "next_page": null}`)
+	srv.Handle("POST /time_entries", auth, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id": 9001, "spent_date": "2026-03-02", "hours": 1.5, "notes": "Fixed the header", "billable": true,
+			"project": {"id": 7}, "task": {"id": 101}}`))
+	})
+	srv.Reply("GET /users/me", auth, `{"id": 5}`)
+	srv.Handle("GET /time_entries", auth, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("user_id") != "5" {
+			t.Errorf("entries listed for user %q", r.URL.Query().Get("user_id"))
+		}
+		w.Write([]byte(`{"time_entries": [
+			{"id": 1, "spent_date": "2026-03-02", "hours": 1, "started_time": "9:00am", "ended_time": "10:00am", "project": {"id": 7}, "task": {"id": 100}},
+			{"id": 2, "spent_date": "2026-03-02", "hours": 0.5, "started_time": "14:30", "project": {"id": 7}, "task": {"id": 100}},
+			{"id": 3, "spent_date": "2026-03-02", "hours": 2, "project": {"id": 7}, "task": {"id": 100}}
+		], "next_page": null}`))
+	})
+
+	c := NewClient("42", "tok", "development", srv.URL, nil)
+	c.SetLocation(time.UTC)
+	return c, srv
+}
+
+func TestListProjects(t *testing.T) {
+	c, _ := fakeHarvest(t)
+	projects, err := c.ListProjects(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(projects) != 1 || projects[0].ID != "7" || projects[0].ClientName != "Acme" {
+		t.Fatalf("unexpected projects: %+v", projects)
+	}
+}
+
+func TestCreateEntry_LogsHoursOnTheConfiguredTask(t *testing.T) {
+	c, srv := fakeHarvest(t)
+	entry, err := c.CreateEntry(context.Background(), clockify.TimeEntryRequest{
+		Start:       "2026-03-02T09:00:00Z",
+		End:         "2026-03-02T10:30:00Z",
+		ProjectID:   "7",
+		Description: "Fixed the header",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(srv.Written()) != 1 {
+		t.Fatalf("expected one entry created, got %d", len(srv.Written()))
+	}
+	body := srv.Written()[0]
+	if body["task_id"] != float64(101) || body["hours"] != 1.5 || body["spent_date"] != "2026-03-02" {
+		t.Errorf("unexpected request: %v", body)
+	}
+	if body["started_time"] != "9:00am" || body["ended_time"] != "10:30am" {
+		t.Errorf("clock times = %v–%v, want 9:00am–10:30am", body["started_time"], body["ended_time"])
+	}
+	if entry.ID != "9001" || entry.TaskID != "101" || !entry.Billable {
+		t.Errorf("unexpected entry: %+v", entry)
+	}
+	if want := time.Date(2026, 3, 2, 10, 30, 0, 0, time.UTC); !entry.TimeInterval.End.Equal(want) {
+		t.Errorf("end = %v, want %v", entry.TimeInterval.End, want)
+	}
+}
+
+func TestCreateEntry_UnknownProject(t *testing.T) {
+	c, srv := fakeHarvest(t)
+	_, err := c.CreateEntry(context.Background(), clockify.TimeEntryRequest{
+		Start:     "2026-03-02T09:00:00Z",
+		End:       "2026-03-02T10:00:00Z",
+		ProjectID: "8",
+	})
+	if err == nil || len(srv.Written()) != 0 {
+		t.Fatalf("expected an error and nothing created, got %v", err)
+	}
+}
+
+func TestListEntries_ClockTimesAndDurations(t *testing.T) {
+	c, _ := fakeHarvest(t)
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	entries, err := c.ListEntries(context.Background(), day, day.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(entries))
+	}
+	want := []struct{ start, end string }{
+		{"09:00", "10:00"},
+		{"14:30", "15:00"},
+		{"00:00", "02:00"}, // tracked by duration only
+	}
+	for i, w := range want {
+		iv := entries[i].TimeInterval
+		if got := iv.Start.Format("15:04") + "–" + iv.End.Format("15:04"); got != w.start+"–"+w.end {
+			t.Errorf("entry %d: got %s, want %s–%s", i, got, w.start, w.end)
+		}
+	}
+}
+
+func TestListEntries_DurationsMatchByDay(t *testing.T) {
+	c, _ := fakeHarvest(t)
+	// An afternoon window: the 9:00 entry starts before it, but the
+	// duration-only entry has no time of day and is kept by its date.
+	start := time.Date(2026, 3, 2, 13, 0, 0, 0, time.UTC)
+	entries, err := c.ListEntries(context.Background(), start, start.Add(2*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, e := range entries {
+		ids = append(ids, e.ID)
+	}
+	if len(ids) != 2 || ids[0] != "2" || ids[1] != "3" {
+		t.Fatalf("entries = %v, want [2 3]", ids)
+	}
+	if d := entries[1].TimeInterval.End.Sub(entries[1].TimeInterval.Start); d != 2*time.Hour {
+		t.Errorf("duration entry lasts %s, want 2h", d)
+	}
+
+	// The next day holds none of them.
+	next := start.AddDate(0, 0, 1)
+	entries, err = c.ListEntries(context.Background(), next, next.Add(2*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected no entries on the next day, got %d", len(entries))
+	}
+}
+
+func TestReadOnlyBlocksWrites(t *testing.T) {
+	c, srv := fakeHarvest(t)
+	c.SetReadOnly(true)
+	if err := c.DeleteEntry(context.Background(), "1"); err == nil {
+		t.Fatal("expected read-only mode to block the delete")
+	}
+	if len(srv.Written()) != 0 {
+		t.Fatal("nothing should have been sent")
+	}
+}
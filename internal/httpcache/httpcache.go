@@ -0,0 +1,153 @@
+// Package httpcache revalidates GET responses with ETags. The last body of
+// each URL is kept on disk, so an unchanged project or repo list costs a
+// 304 Not Modified instead of a full download, across invocations.
+package httpcache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// Transport is an http.RoundTripper that adds If-None-Match to GET requests
+// it has a cached response for, and answers a 304 with the cached body as a
+// 200. Responses without an ETag pass through untouched.
+type Transport struct {
+	dir    string
+	base   http.RoundTripper
+	logger *slog.Logger
+}
+
+// New returns a Transport caching in dir over base (http.DefaultTransport
+// when nil).
+func New(dir string, base http.RoundTripper, logger *slog.Logger) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if logger == nil {
+		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+	return &Transport{dir: dir, base: base, logger: logger}
+}
+
+// entry is a cached response, stored as JSON.
+type entry struct {
+	ETag        string `json:"etag"`
+	ContentType string `json:"content_type"`
+	Body        []byte `json:"body"`
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Header.Get("If-None-Match") != "" || req.Header.Get("Range") != "" {
+		return t.base.RoundTrip(req)
+	}
+
+	path := t.path(req)
+	cached := t.load(path)
+	if cached != nil {
+		req = req.Clone(req.Context())
+		req.Header.Set("If-None-Match", cached.ETag)
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		resp.Body.Close()
+		t.logger.Debug("not modified, served from cache", "url", req.URL.Redacted(), "bytes", len(cached.Body))
+		header := resp.Header.Clone()
+		if header.Get("Content-Type") == "" {
+			header.Set("Content-Type", cached.ContentType)
+		}
+		header.Set("Content-Length", strconv.Itoa(len(cached.Body)))
+		return &http.Response{
+			Status:        "200 OK",
+			StatusCode:    http.StatusOK,
+			Proto:         resp.Proto,
+			ProtoMajor:    resp.ProtoMajor,
+			ProtoMinor:    resp.ProtoMinor,
+			Header:        header,
+			Body:          io.NopCloser(bytes.NewReader(cached.Body)),
+			ContentLength: int64(len(cached.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	etag := resp.Header.Get("ETag")
+	if resp.StatusCode != http.StatusOK || etag == "" {
+		return resp, nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	t.store(path, entry{ETag: etag, ContentType: resp.Header.Get("Content-Type"), Body: body})
+	return resp, nil
+}
+
+// path returns the cache file for req. The credentials are part of the key
+// so one account's responses are never served to another.
+func (t *Transport) path(req *http.Request) string {
+	h := sha256.New()
+	for _, part := range []string{
+		req.URL.String(),
+		req.Header.Get("Authorization"),
+		req.Header.Get("X-Api-Key"),
+		req.Header.Get("Accept"),
+	} {
+		io.WriteString(h, part)
+		h.Write([]byte{0})
+	}
+	return filepath.Join(t.dir, hex.EncodeToString(h.Sum(nil))+".json")
+}
+
+func (t *Transport) load(path string) *entry {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil || e.ETag == "" {
+		return nil
+	}
+	return &e
+}
+
+// store writes e atomically; failures only cost a full download next time.
+func (t *Transport) store(path string, e entry) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(t.dir, 0700); err != nil {
+		t.logger.Debug("creating HTTP cache directory", "error", err)
+		return
+	}
+	tmp, err := os.CreateTemp(t.dir, "*.tmp")
+	if err != nil {
+		t.logger.Debug("writing HTTP cache", "error", err)
+		return
+	}
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		t.logger.Debug("writing HTTP cache", "error", err)
+	}
+}
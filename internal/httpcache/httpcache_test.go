@@ -0,0 +1,78 @@
+package httpcache
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestTransport_RevalidatesWithETag(t *testing.T) {
+	var full, notModified atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `[{"id":"p1"}]`)
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	get := func(key string) string {
+		t.Helper()
+		// A fresh client each time, like separate clockr invocations
+		client := &http.Client{Transport: New(dir, nil, nil)}
+		req, _ := http.NewRequest(http.MethodGet, srv.URL+"/projects", nil)
+		req.Header.Set("X-Api-Key", key)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("status = %d, want 200", resp.StatusCode)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+
+	for i := 0; i < 3; i++ {
+		if body := get("key-a"); body != `[{"id":"p1"}]` {
+			t.Fatalf("request %d body = %q", i, body)
+		}
+	}
+	if full.Load() != 1 || notModified.Load() != 2 {
+		t.Errorf("full = %d, not modified = %d; want 1 and 2", full.Load(), notModified.Load())
+	}
+
+	// Another API key must not be served the first key's cached response
+	get("key-b")
+	if full.Load() != 2 {
+		t.Errorf("a different API key reused the cache (full downloads = %d)", full.Load())
+	}
+}
+
+func TestTransport_PassesThroughWithoutETag(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") != "" {
+			t.Error("sent If-None-Match without a cached ETag")
+		}
+		io.WriteString(w, "ok")
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: New(t.TempDir(), nil, nil)}
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+}
@@ -0,0 +1,173 @@
+// Package httpmetrics counts the requests each API client sends, with
+// latencies and errors, and can write full request/response traces for
+// debugging. Every Clockify, GitHub and Graph client wraps its transport
+// with Wrap.
+package httpmetrics
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Stats are the totals for one service since the process started.
+type Stats struct {
+	Service  string        `json:"service"`
+	Requests int           `json:"requests"`
+	Errors   int           `json:"errors"` // transport errors and 4xx/5xx responses
+	Total    time.Duration `json:"total_ns"`
+	Max      time.Duration `json:"max_ns"`
+	Status   map[int]int   `json:"status"` // responses per status code
+}
+
+// Mean is the average latency of a request.
+func (s Stats) Mean() time.Duration {
+	if s.Requests == 0 {
+		return 0
+	}
+	return s.Total / time.Duration(s.Requests)
+}
+
+// ErrorRate is the share of requests that failed.
+func (s Stats) ErrorRate() float64 {
+	if s.Requests == 0 {
+		return 0
+	}
+	return float64(s.Errors) / float64(s.Requests)
+}
+
+var (
+	mu    sync.Mutex
+	stats = map[string]*Stats{}
+	trace io.Writer
+)
+
+// Snapshot returns a copy of the stats, sorted by service.
+func Snapshot() []Stats {
+	mu.Lock()
+	defer mu.Unlock()
+	var out []Stats
+	for _, s := range stats {
+		c := *s
+		c.Status = make(map[int]int, len(s.Status))
+		for k, v := range s.Status {
+			c.Status[k] = v
+		}
+		out = append(out, c)
+	}
+	slices.SortFunc(out, func(a, b Stats) int { return strings.Compare(a.Service, b.Service) })
+	return out
+}
+
+// SetTrace writes every request and response, bodies included, to w. A nil
+// w turns tracing off. Credentials in headers are masked.
+func SetTrace(w io.Writer) {
+	mu.Lock()
+	defer mu.Unlock()
+	trace = w
+}
+
+func record(service string, d time.Duration, status int, failed bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	s, ok := stats[service]
+	if !ok {
+		s = &Stats{Service: service, Status: map[int]int{}}
+		stats[service] = s
+	}
+	s.Requests++
+	s.Total += d
+	s.Max = max(s.Max, d)
+	if status != 0 {
+		s.Status[status]++
+	}
+	if failed {
+		s.Errors++
+	}
+}
+
+type transport struct {
+	service string
+	base    http.RoundTripper
+}
+
+// Wrap returns base (http.DefaultTransport when nil) with its requests
+// counted under service.
+func Wrap(service string, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{service: service, base: base}
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	mu.Lock()
+	w := trace
+	mu.Unlock()
+	if w != nil {
+		req = dumpRequest(w, t.service, req)
+	}
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	d := time.Since(start)
+
+	if err != nil {
+		record(t.service, d, 0, true)
+		if w != nil {
+			writeTrace(w, fmt.Sprintf("<<< %s error after %s: %v\n\n", t.service, d.Round(time.Millisecond), err))
+		}
+		return nil, err
+	}
+	record(t.service, d, resp.StatusCode, resp.StatusCode >= 400)
+	if w != nil {
+		dump, _ := httputil.DumpResponse(resp, true)
+		writeTrace(w, fmt.Sprintf("<<< %s %d in %s\n%s\n\n", t.service, resp.StatusCode, d.Round(time.Millisecond), dump))
+	}
+	return resp, nil
+}
+
+// secretHeaders are masked in traces.
+var secretHeaders = []string{"Authorization", "X-Api-Key", "Cookie"}
+
+// dumpRequest writes req to w and returns the request to send in its place,
+// with the body read for the dump put back.
+func dumpRequest(w io.Writer, service string, req *http.Request) *http.Request {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		body, _ = io.ReadAll(req.Body)
+		req.Body.Close()
+	}
+	withBody := func() *http.Request {
+		r := req.Clone(req.Context())
+		if body != nil {
+			r.Body = io.NopCloser(bytes.NewReader(body))
+		}
+		return r
+	}
+
+	shown := withBody()
+	for _, h := range secretHeaders {
+		if shown.Header.Get(h) != "" {
+			shown.Header.Set(h, "***")
+		}
+	}
+	if dump, err := httputil.DumpRequestOut(shown, true); err != nil {
+		writeTrace(w, fmt.Sprintf(">>> %s %s %s (dump failed: %v)\n\n", service, req.Method, req.URL, err))
+	} else {
+		writeTrace(w, fmt.Sprintf(">>> %s %s\n%s\n\n", service, time.Now().Format(time.RFC3339), dump))
+	}
+	return withBody()
+}
+
+func writeTrace(w io.Writer, s string) {
+	mu.Lock()
+	defer mu.Unlock()
+	io.WriteString(w, s)
+}
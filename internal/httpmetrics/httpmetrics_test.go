@@ -0,0 +1,65 @@
+package httpmetrics
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWrap_CountsAndTraces(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Method == http.MethodPost && string(body) != `{"a":1}` {
+			t.Errorf("server got body %q", body)
+		}
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		io.WriteString(w, "ok")
+	}))
+	defer srv.Close()
+
+	var trace bytes.Buffer
+	SetTrace(&trace)
+	defer SetTrace(nil)
+
+	client := &http.Client{Transport: Wrap("test", nil)}
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/ok", strings.NewReader(`{"a":1}`))
+	req.Header.Set("X-Api-Key", "secret-key")
+	for _, r := range []*http.Request{req, mustGet(t, srv.URL+"/fail")} {
+		resp, err := client.Do(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	var got Stats
+	for _, s := range Snapshot() {
+		if s.Service == "test" {
+			got = s
+		}
+	}
+	if got.Requests != 2 || got.Errors != 1 || got.Status[200] != 1 || got.Status[500] != 1 {
+		t.Errorf("stats = %+v, want 2 requests, 1 error", got)
+	}
+	if strings.Contains(trace.String(), "secret-key") {
+		t.Error("trace contains the API key")
+	}
+	if !strings.Contains(trace.String(), `{"a":1}`) {
+		t.Error("trace is missing the request body")
+	}
+}
+
+func mustGet(t *testing.T, url string) *http.Request {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return req
+}
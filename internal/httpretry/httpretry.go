@@ -0,0 +1,145 @@
+// Package httpretry sends HTTP requests again after transport errors, 429
+// and 5xx responses. Waits grow exponentially with jitter, a Retry-After
+// header is honored, and retrying stops once a time budget is spent.
+package httpretry
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Policy says how often and how long to retry.
+type Policy struct {
+	MaxRetries int
+	BaseDelay  time.Duration // first wait; doubled on each retry
+	MaxDelay   time.Duration // longest single wait; a longer Retry-After ends retrying
+	MaxElapsed time.Duration // no retry starts after this much time in total
+
+	// OnRetry, when set, is called before each wait, with either the
+	// retryable response or the transport error.
+	OnRetry func(attempt int, wait time.Duration, resp *http.Response, err error)
+}
+
+// Default is the policy of the Clockify, GitHub and Graph clients.
+var Default = Policy{
+	MaxRetries: 3,
+	BaseDelay:  time.Second,
+	MaxDelay:   30 * time.Second,
+	MaxElapsed: 2 * time.Minute,
+}
+
+// Retryable reports whether a response with status is worth retrying.
+func Retryable(status int) bool {
+	return status == http.StatusTooManyRequests || status >= 500
+}
+
+// Do sends req with client, retrying as p allows. Each attempt sends a copy
+// of req with its body replayed through req.GetBody, which
+// http.NewRequest sets for in-memory bodies.
+func Do(client *http.Client, req *http.Request, p Policy) (*http.Response, error) {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return nil, fmt.Errorf("httpretry: request body can't be replayed")
+	}
+	return DoFunc(req.Context(), client, p, func(ctx context.Context) (*http.Request, error) {
+		r := req.Clone(ctx)
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("replaying request body: %w", err)
+			}
+			r.Body = body
+		}
+		return r, nil
+	})
+}
+
+// DoFunc sends a request built by newReq, retrying as p allows. newReq is
+// called once per attempt, so every attempt has its own body and headers.
+// It returns the last response, which may still be retryable, or the last
+// error. Once ctx is done no further attempt is built, and a wait between
+// attempts ends early.
+func DoFunc(ctx context.Context, client *http.Client, p Policy, newReq func(ctx context.Context) (*http.Request, error)) (*http.Response, error) {
+	start := time.Now()
+	for attempt := 0; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		req, err := newReq(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := client.Do(req)
+		if err == nil && !Retryable(resp.StatusCode) {
+			return resp, nil
+		}
+		if attempt == p.MaxRetries || ctx.Err() != nil {
+			return resp, err
+		}
+
+		wait := p.delay(attempt)
+		if resp != nil {
+			if after, ok := retryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+				if after > p.MaxDelay {
+					return resp, nil
+				}
+				wait = after
+			}
+		}
+		if p.MaxElapsed > 0 && time.Since(start)+wait > p.MaxElapsed {
+			return resp, err
+		}
+		if p.OnRetry != nil {
+			p.OnRetry(attempt+1, wait, resp, err)
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// delay is the wait before retry attempt+1: BaseDelay doubled per attempt,
+// capped at MaxDelay, with the upper half randomized so clients that failed
+// together don't retry together.
+func (p Policy) delay(attempt int) time.Duration {
+	d := p.BaseDelay << attempt
+	if d <= 0 || (p.MaxDelay > 0 && d > p.MaxDelay) {
+		d = p.MaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + rand.N(d/2+1)
+}
+
+// retryAfter parses a Retry-After header: seconds or an HTTP date.
+func retryAfter(v string, now time.Time) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return max(time.Duration(secs)*time.Second, 0), true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(t.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
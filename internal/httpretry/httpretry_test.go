@@ -0,0 +1,193 @@
+package httpretry
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var fast = Policy{MaxRetries: 3, BaseDelay: time.Millisecond, MaxDelay: time.Second, MaxElapsed: 10 * time.Second}
+
+func TestDo_ReplaysBodyAndHonorsRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != `{"a":1}` {
+			t.Errorf("attempt %d body = %q", calls.Load()+1, body)
+		}
+		if calls.Add(1) < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodPost, srv.URL, bytes.NewReader([]byte(`{"a":1}`)))
+	resp, err := Do(srv.Client(), req, fast)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated || calls.Load() != 3 {
+		t.Errorf("status %d after %d calls, want 201 after 3", resp.StatusCode, calls.Load())
+	}
+}
+
+func TestDo_GivesUpOnLongRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	resp, err := Do(srv.Client(), req, fast)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable || calls.Load() != 1 {
+		t.Errorf("status %d after %d calls, want 503 after 1", resp.StatusCode, calls.Load())
+	}
+}
+
+func TestDo_StopsWaitingWhenCancelled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	slow := Policy{MaxRetries: 3, BaseDelay: time.Minute, MaxDelay: time.Minute, MaxElapsed: time.Hour}
+
+	start := time.Now()
+	if _, err := Do(srv.Client(), req, slow); err == nil {
+		t.Fatal("expected an error after cancellation")
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("Do waited %s after the context was done", d)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in   string
+		want time.Duration
+		ok   bool
+	}{
+		{"", 0, false},
+		{"120", 2 * time.Minute, true},
+		{now.Add(30 * time.Second).Format(http.TimeFormat), 30 * time.Second, true},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0, true},
+		{"soon", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := retryAfter(tt.in, now)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("retryAfter(%q) = %s, %v; want %s, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestDelay_JitterWithinBounds(t *testing.T) {
+	p := Policy{BaseDelay: time.Second, MaxDelay: 4 * time.Second}
+	for attempt, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 4 * time.Second} {
+		for range 20 {
+			if d := p.delay(attempt); d < want/2 || d > want {
+				t.Fatalf("delay(%d) = %s, want within [%s, %s]", attempt, d, want/2, want)
+			}
+		}
+	}
+}
+
+func TestDoFunc_BuildsEachAttempt(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	var built []*http.Request
+	resp, err := DoFunc(context.Background(), srv.Client(), fast, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPut, srv.URL, bytes.NewReader([]byte("x")))
+		built = append(built, req)
+		return req, err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if len(built) != 2 || built[0] == built[1] {
+		t.Errorf("built %d requests, want a new one for each of 2 attempts", len(built))
+	}
+}
+
+func TestDoFunc_NoAttemptAfterCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := DoFunc(ctx, http.DefaultClient, fast, func(ctx context.Context) (*http.Request, error) {
+		t.Error("request built after the context was done")
+		return http.NewRequestWithContext(ctx, http.MethodGet, "http://127.0.0.1:1", nil)
+	})
+	if err != context.Canceled {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
+
+func TestJSON_RetriesWithFreshRequests(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != `{"a":1}` || r.Header.Get("Authorization") != "Bearer tok" || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("attempt %d: body %q, headers %v", calls.Load()+1, body, r.Header)
+		}
+		if calls.Add(1) < 2 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("no such entry"))
+	}))
+	defer srv.Close()
+
+	status, body, err := JSON(context.Background(), srv.Client(), fast, slog.New(slog.DiscardHandler), "Test", http.MethodPost, srv.URL, map[string]int{"a": 1}, func(req *http.Request) {
+		req.Header.Set("Authorization", "Bearer tok")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A non-retryable status is the caller's to judge.
+	if status != http.StatusNotFound || string(body) != "no such entry" || calls.Load() != 2 {
+		t.Errorf("got %d %q after %d calls, want 404 after 2", status, body, calls.Load())
+	}
+}
+
+func TestJSON_StillRetryableIsAnError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	_, _, err := JSON(context.Background(), srv.Client(), fast, slog.New(slog.DiscardHandler), "Test", http.MethodGet, srv.URL, nil, func(*http.Request) {})
+	if err == nil || !strings.Contains(err.Error(), "Test API returned status 503 after retries") {
+		t.Errorf("err = %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package httpretry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// JSON is the request loop of the Harvest, Toggl and Tempo clients. It
+// sends body marshaled as JSON (nil for none) to url, retrying as p allows
+// and logging each retry as service. prepare adds the credentials and any
+// other headers to every attempt. It returns the status and body of the
+// final response; one that is still retryable after the retries is an
+// error, and any other status is left to the caller.
+func JSON(ctx context.Context, client *http.Client, p Policy, logger *slog.Logger, service, method, url string, body any, prepare func(*http.Request)) (int, []byte, error) {
+	var data []byte
+	if body != nil {
+		var err error
+		data, err = json.Marshal(body)
+		if err != nil {
+			return 0, nil, fmt.Errorf("marshaling request body: %w", err)
+		}
+	}
+
+	// Each attempt gets a new request so a retried POST sends the same
+	// payload.
+	newReq := func(ctx context.Context) (*http.Request, error) {
+		var reqBody io.Reader
+		if data != nil {
+			reqBody = bytes.NewReader(data)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+		if err != nil {
+			return nil, fmt.Errorf("creating request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		prepare(req)
+		return req, nil
+	}
+
+	p.OnRetry = func(attempt int, wait time.Duration, resp *http.Response, err error) {
+		if err != nil {
+			logger.Debug(service+" API transport error, retrying", "method", method, "url", url, "attempt", attempt, "wait", wait, "error", err)
+			return
+		}
+		logger.Debug(service+" API retrying", "method", method, "url", url, "status", resp.StatusCode, "attempt", attempt, "wait", wait)
+	}
+	resp, err := DoFunc(ctx, client, p, newReq)
+	if err != nil {
+		return 0, nil, fmt.Errorf("sending request: %w", err)
+	}
+	defer resp.Body.Close()
+	if Retryable(resp.StatusCode) {
+		return 0, nil, fmt.Errorf("%s API returned status %d after retries", service, resp.StatusCode)
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, fmt.Errorf("reading response: %w", err)
+	}
+	return resp.StatusCode, respBody, nil
+}
@@ -0,0 +1,110 @@
+// Package journal renders logged entries as a markdown work journal: what
+// was typed at each prompt, followed by the entries it became.
+package journal
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/christopherklint97/clockr/internal/store"
+)
+
+// Group is the entries logged from one prompt, in start order.
+type Group struct {
+	RawInput string // what was typed; "" for entries logged without one
+	Entries  []store.Entry
+}
+
+// Groups splits entries (oldest first) into prompts: consecutive entries
+// with the same raw input on the same day belong together.
+func Groups(entries []store.Entry) []Group {
+	var groups []Group
+	for _, e := range entries {
+		raw := rawInput(e)
+		if n := len(groups); n > 0 {
+			last := &groups[n-1]
+			prev := last.Entries[len(last.Entries)-1]
+			if raw != "" && raw == last.RawInput && day(prev) == day(e) {
+				last.Entries = append(last.Entries, e)
+				continue
+			}
+		}
+		groups = append(groups, Group{RawInput: raw, Entries: []store.Entry{e}})
+	}
+	return groups
+}
+
+// rawInput returns e's raw input, ignoring the marker 'log --same' stores.
+func rawInput(e store.Entry) string {
+	if e.RawInput == "(--same)" {
+		return ""
+	}
+	return strings.TrimSpace(e.RawInput)
+}
+
+func day(e store.Entry) string {
+	return e.StartTime.In(e.Location()).Format("2006-01-02")
+}
+
+// Month returns the group's month as YYYY-MM, for per-month files.
+func (g Group) Month() string {
+	return day(g.Entries[0])[:7]
+}
+
+// Filter keeps the groups whose raw input, descriptions or project names
+// contain query, ignoring case. An empty query keeps everything.
+func Filter(groups []Group, query string) []Group {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return groups
+	}
+	var out []Group
+	for _, g := range groups {
+		text := []string{g.RawInput}
+		for _, e := range g.Entries {
+			text = append(text, e.Description, e.ProjectName, e.ClientName)
+		}
+		if strings.Contains(strings.ToLower(strings.Join(text, "\n")), query) {
+			out = append(out, g)
+		}
+	}
+	return out
+}
+
+// Render writes groups as markdown under a level-one title, with a heading
+// per day.
+func Render(w io.Writer, title string, groups []Group) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n", title)
+	lastDay := ""
+	for _, g := range groups {
+		first := g.Entries[0]
+		loc := first.Location()
+		if d := day(first); d != lastDay {
+			fmt.Fprintf(&b, "\n## %s\n", first.StartTime.In(loc).Format("Monday 2006-01-02"))
+			lastDay = d
+		}
+		last := g.Entries[len(g.Entries)-1]
+		fmt.Fprintf(&b, "\n### %s–%s\n\n", first.StartTime.In(loc).Format("15:04"), last.EndTime.In(loc).Format("15:04"))
+		if g.RawInput != "" {
+			for _, line := range strings.Split(g.RawInput, "\n") {
+				fmt.Fprintf(&b, "> %s\n", strings.TrimRight(line, " "))
+			}
+			b.WriteString("\n")
+		}
+		for _, e := range g.Entries {
+			project := e.ProjectName
+			if e.ClientName != "" {
+				project = e.ClientName + " / " + e.ProjectName
+			}
+			fmt.Fprintf(&b, "- %dmin **%s**: %s", e.Minutes, project, e.Description)
+			if e.Status != "logged" {
+				fmt.Fprintf(&b, " _(%s)_", e.Status)
+			}
+			b.WriteString("\n")
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,70 @@
+package journal
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/store"
+)
+
+func entry(start time.Time, minutes int, project, desc, raw string) store.Entry {
+	return store.Entry{
+		ProjectName: project,
+		Description: desc,
+		StartTime:   start,
+		EndTime:     start.Add(time.Duration(minutes) * time.Minute),
+		Minutes:     minutes,
+		Status:      "logged",
+		RawInput:    raw,
+		Timezone:    "UTC",
+	}
+}
+
+func TestGroupsAndRender(t *testing.T) {
+	mon := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	entries := []store.Entry{
+		entry(mon, 30, "Web", "Landing page CSS", "fixed css\nand reviewed a PR"),
+		entry(mon.Add(30*time.Minute), 30, "Internal", "Code review", "fixed css\nand reviewed a PR"),
+		entry(mon.Add(time.Hour), 60, "Web", "Landing page CSS", "(--same)"),
+		entry(mon.AddDate(0, 0, 1), 60, "Data", "ETL retries", "etl retries"),
+	}
+	entries[3].Status = "failed"
+
+	groups := Groups(entries)
+	if len(groups) != 3 || len(groups[0].Entries) != 2 {
+		t.Fatalf("Groups() = %+v, want the first two entries together", groups)
+	}
+
+	var b strings.Builder
+	if err := Render(&b, "Work journal", groups); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	for _, want := range []string{
+		"# Work journal\n",
+		"## Monday 2026-03-02\n",
+		"### 09:00–10:00\n\n> fixed css\n> and reviewed a PR\n\n- 30min **Web**: Landing page CSS\n- 30min **Internal**: Code review\n",
+		"### 10:00–11:00\n\n- 60min **Web**",
+		"## Tuesday 2026-03-03\n",
+		"ETL retries _(failed)_",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("journal missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestFilter(t *testing.T) {
+	mon := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	groups := Groups([]store.Entry{
+		entry(mon, 60, "Web", "Landing page", "css work"),
+		entry(mon.Add(time.Hour), 60, "Data", "Warehouse schema", "orders table"),
+	})
+	if got := Filter(groups, "WAREHOUSE"); len(got) != 1 || got[0].RawInput != "orders table" {
+		t.Errorf("Filter() = %+v", got)
+	}
+	if got := Filter(groups, ""); len(got) != 2 {
+		t.Errorf("empty query kept %d groups, want 2", len(got))
+	}
+}
@@ -0,0 +1,293 @@
+// Package localdata exports and wipes everything clockr stores on this machine.
+package localdata
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/config"
+	"github.com/christopherklint97/clockr/internal/msgraph"
+	"github.com/christopherklint97/clockr/internal/store"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// secretKeys are config keys whose values are replaced in the export.
+var secretKeys = map[string]bool{
+	"api_key":            true,
+	"openrouter_api_key": true,
+	"api_token":          true,
+	"token":              true,
+	"jira_token":         true,
+	"password":           true,
+	"bot_token":          true,
+	"signing_secret":     true,
+}
+
+// Manifest describes the contents of an export archive.
+type Manifest struct {
+	CreatedAt  time.Time      `json:"created_at"`
+	Version    string         `json:"clockr_version"`
+	ConfigPath string         `json:"config_path"`
+	DataDir    string         `json:"data_dir"`
+	StateDir   string         `json:"state_dir"`
+	Files      []ManifestFile `json:"files"`
+	NotStored  []string       `json:"not_stored"`
+}
+
+// ManifestFile is one file in the archive.
+type ManifestFile struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Size        int    `json:"size"`
+	SHA256      string `json:"sha256"`
+}
+
+// Export writes a zip archive of all local clockr data to w. Secrets are
+// redacted: the archive shows which credentials exist, not their values.
+func Export(w io.Writer, db *store.DB, version string) (*Manifest, error) {
+	configPath, err := config.ConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	dataDir, err := config.DataDir()
+	if err != nil {
+		return nil, err
+	}
+	stateDir, err := config.StateDir()
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Manifest{
+		CreatedAt:  time.Now(),
+		Version:    version,
+		ConfigPath: configPath,
+		DataDir:    dataDir,
+		StateDir:   stateDir,
+		NotStored: []string{
+			"AI requests are not logged verbatim; responses are kept for up to a day in ai_cache.json, keyed by a hash of the request",
+		},
+	}
+
+	zw := zip.NewWriter(w)
+	add := func(name, description string, data []byte) error {
+		f, err := zw.Create(name)
+		if err != nil {
+			return fmt.Errorf("adding %s: %w", name, err)
+		}
+		if _, err := f.Write(data); err != nil {
+			return fmt.Errorf("writing %s: %w", name, err)
+		}
+		sum := sha256.Sum256(data)
+		m.Files = append(m.Files, ManifestFile{
+			Name:        name,
+			Description: description,
+			Size:        len(data),
+			SHA256:      hex.EncodeToString(sum[:]),
+		})
+		return nil
+	}
+	// addTable adds every row of a table that has no typed reader.
+	addTable := func(name, table, description string) error {
+		rows, err := db.TableRows(table)
+		if err != nil {
+			return err
+		}
+		data, err := json.MarshalIndent(rows, "", "  ")
+		if err != nil {
+			return fmt.Errorf("encoding %s: %w", table, err)
+		}
+		return add(name, description, data)
+	}
+
+	entries, err := db.AllEntries()
+	if err != nil {
+		return nil, fmt.Errorf("reading entries: %w", err)
+	}
+	if entries == nil {
+		entries = []store.Entry{}
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encoding entries: %w", err)
+	}
+	if err := add("entries.json", "Time entries including the raw text you typed (RawInput)", data); err != nil {
+		return nil, err
+	}
+
+	skips, err := db.AllSkips()
+	if err != nil {
+		return nil, fmt.Errorf("reading skips: %w", err)
+	}
+	if skips == nil {
+		skips = []store.Skip{}
+	}
+	if data, err = json.MarshalIndent(skips, "", "  "); err != nil {
+		return nil, fmt.Errorf("encoding skips: %w", err)
+	}
+	if err := add("skips.json", "Prompt windows you skipped and the reason given", data); err != nil {
+		return nil, err
+	}
+
+	pauses, err := db.AllPauses()
+	if err != nil {
+		return nil, fmt.Errorf("reading pauses: %w", err)
+	}
+	if pauses == nil {
+		pauses = []store.Pause{}
+	}
+	if data, err = json.MarshalIndent(pauses, "", "  "); err != nil {
+		return nil, fmt.Errorf("encoding pauses: %w", err)
+	}
+	if err := add("pauses.json", "Periods when scheduled prompts were paused", data); err != nil {
+		return nil, err
+	}
+
+	migrations, err := db.AllProjectMigrations()
+	if err != nil {
+		return nil, fmt.Errorf("reading project migrations: %w", err)
+	}
+	if migrations == nil {
+		migrations = []store.ProjectMigration{}
+	}
+	if data, err = json.MarshalIndent(migrations, "", "  "); err != nil {
+		return nil, fmt.Errorf("encoding project migrations: %w", err)
+	}
+	if err := add("project_migrations.json", "Old → new project IDs from workspace migrations", data); err != nil {
+		return nil, err
+	}
+
+	state, err := db.AllState()
+	if err != nil {
+		return nil, fmt.Errorf("reading state: %w", err)
+	}
+	if data, err = json.MarshalIndent(state, "", "  "); err != nil {
+		return nil, fmt.Errorf("encoding state: %w", err)
+	}
+	if err := add("state.json", "Scheduler and session state (pending prompt windows, etc.)", data); err != nil {
+		return nil, err
+	}
+
+	for _, t := range []struct{ name, table, description string }{
+		{"clockify_cache.json", "clockify_cache", "Cached Clockify projects, clients and workspace details"},
+		{"ai_cache.json", "ai_cache", "AI responses from the last day, reused for identical requests"},
+		{"suggestions.json", "suggestions", "The latest AI suggestion per prompt window, with your description and context, for --resume"},
+		{"entry_suggestions.json", "entry_suggestions", "The AI output and clarification history behind each logged entry"},
+		{"suggestion_edits.json", "suggestion_edits", "How much you changed each AI suggestion before accepting it"},
+		{"audit_log.json", "audit_log", "Choices made in 'clockr audit-diff' when local and Clockify entries differed"},
+		{"prompts.json", "prompts", "Scheduled prompts and whether they were answered"},
+		{"raw_inputs.json", "raw_inputs", "Descriptions exactly as you typed them, for the input history"},
+		{"drafts.json", "drafts", "Descriptions typed but not yet submitted, kept for restoring"},
+	} {
+		if err := addTable(t.name, t.table, t.description); err != nil {
+			return nil, err
+		}
+	}
+
+	if data, err = redactedConfig(configPath); err != nil {
+		return nil, err
+	} else if data != nil {
+		if err := add("config.toml", "Configuration with API keys and tokens redacted", data); err != nil {
+			return nil, err
+		}
+	}
+
+	profiles, err := msgraph.Profiles()
+	if err != nil {
+		return nil, fmt.Errorf("listing Graph sign-ins: %w", err)
+	}
+	for _, profile := range profiles {
+		tokens, err := msgraph.LoadTokens(profile)
+		if err != nil {
+			return nil, fmt.Errorf("reading Graph tokens: %w", err)
+		}
+		if tokens == nil {
+			continue
+		}
+		meta := map[string]any{
+			"expires_at":        tokens.ExpiresAt,
+			"scope":             tokens.Scope,
+			"has_access_token":  tokens.AccessToken != "",
+			"has_refresh_token": tokens.RefreshToken != "",
+		}
+		if data, err = json.MarshalIndent(meta, "", "  "); err != nil {
+			return nil, fmt.Errorf("encoding token metadata: %w", err)
+		}
+		if err := add(msgraph.TokenFile(profile), "Microsoft Graph token metadata (token values omitted)", data); err != nil {
+			return nil, err
+		}
+	}
+
+	tmpFiles, _ := filepath.Glob(filepath.Join(stateDir, "tmp", "*"))
+	sort.Strings(tmpFiles)
+	for _, path := range tmpFiles {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue // directories or files removed mid-export
+		}
+		if err := add("tmp/"+filepath.Base(path), "Prompt-file mode scratch file", data); err != nil {
+			return nil, err
+		}
+	}
+
+	if data, err = json.MarshalIndent(m, "", "  "); err != nil {
+		return nil, fmt.Errorf("encoding manifest: %w", err)
+	}
+	f, err := zw.Create("manifest.json")
+	if err != nil {
+		return nil, fmt.Errorf("adding manifest: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		return nil, fmt.Errorf("writing manifest: %w", err)
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("finishing archive: %w", err)
+	}
+	return m, nil
+}
+
+// redactedConfig returns the config file with secret values replaced, or nil
+// if there is no config file.
+func redactedConfig(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading config: %w", err)
+	}
+
+	var doc map[string]any
+	if err := toml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parsing config: %w", err)
+	}
+	redact(doc)
+
+	out, err := toml.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("encoding config: %w", err)
+	}
+	return out, nil
+}
+
+func redact(table map[string]any) {
+	for k, v := range table {
+		switch v := v.(type) {
+		case map[string]any:
+			redact(v)
+		case string:
+			if secretKeys[k] && v != "" {
+				table[k] = "[redacted]"
+			}
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package localdata
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"github.com/christopherklint97/clockr/internal/store"
+)
+
+func TestExport_Tables(t *testing.T) {
+	t.Setenv("CLOCKR_HOME", t.TempDir())
+	db, err := store.Open(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var buf bytes.Buffer
+	m, err := Export(&buf, db, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string]bool)
+	for _, f := range zr.File {
+		files[f.Name] = true
+	}
+	for _, want := range []string{"entries.json", "clockify_cache.json", "ai_cache.json", "entry_suggestions.json", "audit_log.json", "raw_inputs.json", "drafts.json", "manifest.json"} {
+		if !files[want] {
+			t.Errorf("export lacks %s; has %v", want, files)
+		}
+	}
+	if len(m.Files)+1 != len(zr.File) {
+		t.Errorf("manifest lists %d files, archive has %d besides the manifest", len(m.Files), len(zr.File)-1)
+	}
+}
+
+func TestRedact(t *testing.T) {
+	doc := map[string]any{
+		"clockify": map[string]any{"api_key": "secret", "workspace_id": "ws"},
+		"ai":       map[string]any{"api_key": "", "model": "m"},
+		"github":   map[string]any{"token": "ghp_x", "repos": []any{"a/b"}},
+		"slack":    map[string]any{"bot_token": "xoxb-1", "signing_secret": "s3cret", "user_id": "U01"},
+	}
+	redact(doc)
+
+	if got := doc["clockify"].(map[string]any)["api_key"]; got != "[redacted]" {
+		t.Errorf("clockify.api_key = %v, want redacted", got)
+	}
+	if got := doc["clockify"].(map[string]any)["workspace_id"]; got != "ws" {
+		t.Errorf("workspace_id should be kept, got %v", got)
+	}
+	if got := doc["ai"].(map[string]any)["api_key"]; got != "" {
+		t.Errorf("empty secrets should stay empty so the export shows they are unset, got %v", got)
+	}
+	if got := doc["github"].(map[string]any)["token"]; got != "[redacted]" {
+		t.Errorf("github.token = %v, want redacted", got)
+	}
+	slack := doc["slack"].(map[string]any)
+	if slack["bot_token"] != "[redacted]" || slack["signing_secret"] != "[redacted]" {
+		t.Errorf("slack secrets = %v, %v; want redacted", slack["bot_token"], slack["signing_secret"])
+	}
+	if slack["user_id"] != "U01" {
+		t.Errorf("slack.user_id should be kept, got %v", slack["user_id"])
+	}
+}
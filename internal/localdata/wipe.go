@@ -0,0 +1,101 @@
+package localdata
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/christopherklint97/clockr/internal/config"
+	"github.com/christopherklint97/clockr/internal/msgraph"
+	"github.com/christopherklint97/clockr/internal/secrets"
+)
+
+// Wipe deletes all local clockr data, including keychain items, and returns
+// what it removed. The config file is kept when keepConfig is set. Time
+// entries already sent to Clockify are not affected.
+func Wipe(keepConfig bool) ([]string, error) {
+	dataDir, err := config.DataDir()
+	if err != nil {
+		return nil, err
+	}
+	stateDir, err := config.StateDir()
+	if err != nil {
+		return nil, err
+	}
+	cacheDir, err := config.CacheDir()
+	if err != nil {
+		return nil, err
+	}
+
+	paths := []string{
+		filepath.Join(dataDir, "clockr.db"),
+		filepath.Join(dataDir, "clockr.db-wal"),
+		filepath.Join(dataDir, "clockr.db-shm"),
+		filepath.Join(stateDir, "tmp"),
+		filepath.Join(cacheDir, "http-cache"),
+	}
+	// Every Graph sign-in has a token file and may have a keychain item.
+	profiles, err := msgraph.Profiles()
+	if err != nil {
+		return nil, err
+	}
+	if len(profiles) == 0 || profiles[0] != "" {
+		profiles = append([]string{""}, profiles...)
+	}
+	for _, profile := range profiles {
+		paths = append(paths, filepath.Join(dataDir, msgraph.TokenFile(profile)))
+	}
+	dirs := []string{dataDir, stateDir, cacheDir}
+	if !keepConfig {
+		configPath, err := config.ConfigPath()
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, configPath)
+		dirs = append(dirs, filepath.Dir(configPath))
+	}
+
+	var removed []string
+	for _, path := range paths {
+		if _, err := os.Lstat(path); os.IsNotExist(err) {
+			continue
+		}
+		if err := os.RemoveAll(path); err != nil {
+			return removed, fmt.Errorf("removing %s: %w", path, err)
+		}
+		removed = append(removed, path)
+	}
+
+	// Credentials kept in the OS keychain. Clockify/GitHub keys, the SMTP
+	// password and the Slack credentials are part of the configuration, so keep-config leaves them in
+	// place.
+	var keychainNames []string
+	for _, profile := range profiles {
+		keychainNames = append(keychainNames, secrets.GraphProfileRefreshToken(profile))
+	}
+	if !keepConfig {
+		keychainNames = append(keychainNames, secrets.ClockifyAPIKey, secrets.GitHubToken, secrets.SMTPPassword, secrets.SlackBotToken, secrets.SlackSigningSecret, secrets.HarvestToken, secrets.TogglAPIToken, secrets.TempoToken, secrets.JiraAPIToken)
+	}
+	if secrets.Available() {
+		for _, name := range keychainNames {
+			if _, err := secrets.Get(name); err != nil {
+				continue
+			}
+			if err := secrets.Delete(name); err != nil {
+				return removed, fmt.Errorf("removing %s from keychain: %w", name, err)
+			}
+			removed = append(removed, "keychain: "+name)
+		}
+	}
+
+	// Remove the directories themselves once nothing else is left in them.
+	// os.Remove fails on non-empty directories, which is what we want.
+	if !keepConfig {
+		for _, dir := range dirs {
+			if err := os.Remove(dir); err == nil {
+				removed = append(removed, dir)
+			}
+		}
+	}
+	return removed, nil
+}
@@ -0,0 +1,139 @@
+// Package metrics counts what clockr does in this process — prompts shown
+// and skipped, entries logged and failed, AI calls — and writes them, with
+// the API request stats from httpmetrics, in the Prometheus text format for
+// 'clockr start' and 'clockr serve' to expose on /metrics.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/httpmetrics"
+)
+
+// aiBuckets are the upper bounds, in seconds, of the AI latency histogram.
+var aiBuckets = []float64{1, 2, 5, 10, 20, 30, 60, 120}
+
+var (
+	mu             sync.Mutex
+	promptsShown   int
+	promptsSkipped int
+	entriesLogged  int
+	entriesFailed  int
+	aiErrors       int
+	aiCounts       = make([]int, len(aiBuckets)) // per bucket, not cumulative
+	aiCount        int
+	aiSum          time.Duration
+)
+
+// PromptShown counts a scheduled prompt.
+func PromptShown() {
+	mu.Lock()
+	defer mu.Unlock()
+	promptsShown++
+}
+
+// PromptSkipped counts a window the user chose not to log.
+func PromptSkipped() {
+	mu.Lock()
+	defer mu.Unlock()
+	promptsSkipped++
+}
+
+// EntrySubmitted counts one attempt to log an entry to the backend by its
+// outcome, "logged" or "failed"; other statuses are ignored. A retry that
+// fails again counts again.
+func EntrySubmitted(status string) {
+	mu.Lock()
+	defer mu.Unlock()
+	switch status {
+	case "logged":
+		entriesLogged++
+	case "failed":
+		entriesFailed++
+	}
+}
+
+// ObserveAI records how long an AI call took and whether it failed.
+func ObserveAI(d time.Duration, err error) {
+	mu.Lock()
+	defer mu.Unlock()
+	aiCount++
+	aiSum += d
+	if err != nil {
+		aiErrors++
+	}
+	for i, b := range aiBuckets {
+		if d.Seconds() <= b {
+			aiCounts[i]++
+			break
+		}
+	}
+}
+
+// Reset zeroes the counters, for tests.
+func Reset() {
+	mu.Lock()
+	defer mu.Unlock()
+	promptsShown, promptsSkipped, entriesLogged, entriesFailed = 0, 0, 0, 0
+	aiErrors, aiCount, aiSum = 0, 0, 0
+	aiCounts = make([]int, len(aiBuckets))
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		Write(w)
+	})
+}
+
+// Write writes the metrics in the Prometheus text format.
+func Write(w io.Writer) {
+	mu.Lock()
+	counters := []struct {
+		name, help string
+		value      int
+	}{
+		{"clockr_prompts_shown_total", "Scheduled prompts shown.", promptsShown},
+		{"clockr_prompts_skipped_total", "Windows skipped instead of logged.", promptsSkipped},
+		{"clockr_entries_logged_total", "Entries logged to the backend, including retries that succeeded.", entriesLogged},
+		{"clockr_entries_failed_total", "Attempts to log an entry that the backend rejected or could not be reached for.", entriesFailed},
+		{"clockr_ai_errors_total", "AI calls that failed.", aiErrors},
+	}
+	buckets := append([]int(nil), aiCounts...)
+	count, sum := aiCount, aiSum
+	mu.Unlock()
+
+	for _, c := range counters {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n%s %d\n", c.name, c.help, c.name, c.name, c.value)
+	}
+
+	fmt.Fprintf(w, "# HELP clockr_ai_request_duration_seconds How long AI calls took.\n# TYPE clockr_ai_request_duration_seconds histogram\n")
+	cumulative := 0
+	for i, b := range aiBuckets {
+		cumulative += buckets[i]
+		fmt.Fprintf(w, "clockr_ai_request_duration_seconds_bucket{le=%q} %d\n", strconv.FormatFloat(b, 'f', -1, 64), cumulative)
+	}
+	fmt.Fprintf(w, "clockr_ai_request_duration_seconds_bucket{le=\"+Inf\"} %d\n", count)
+	fmt.Fprintf(w, "clockr_ai_request_duration_seconds_sum %g\n", sum.Seconds())
+	fmt.Fprintf(w, "clockr_ai_request_duration_seconds_count %d\n", count)
+
+	stats := httpmetrics.Snapshot()
+	fmt.Fprintf(w, "# HELP clockr_http_requests_total Requests to each API, e.g. clockify or github.\n# TYPE clockr_http_requests_total counter\n")
+	for _, s := range stats {
+		fmt.Fprintf(w, "clockr_http_requests_total{service=%q} %d\n", s.Service, s.Requests)
+	}
+	fmt.Fprintf(w, "# HELP clockr_http_errors_total Requests to each API that failed or got a 4xx/5xx response.\n# TYPE clockr_http_errors_total counter\n")
+	for _, s := range stats {
+		fmt.Fprintf(w, "clockr_http_errors_total{service=%q} %d\n", s.Service, s.Errors)
+	}
+	fmt.Fprintf(w, "# HELP clockr_http_request_duration_seconds_total Time spent waiting on each API.\n# TYPE clockr_http_request_duration_seconds_total counter\n")
+	for _, s := range stats {
+		fmt.Fprintf(w, "clockr_http_request_duration_seconds_total{service=%q} %g\n", s.Service, s.Total.Seconds())
+	}
+}
@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandler(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	PromptShown()
+	PromptShown()
+	PromptSkipped()
+	EntrySubmitted("logged")
+	EntrySubmitted("failed")
+	EntrySubmitted("pending")
+	ObserveAI(1500*time.Millisecond, nil)
+	ObserveAI(45*time.Second, errors.New("timeout"))
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	for _, want := range []string{
+		"# TYPE clockr_prompts_shown_total counter\nclockr_prompts_shown_total 2\n",
+		"clockr_prompts_skipped_total 1\n",
+		"clockr_entries_logged_total 1\n",
+		"clockr_entries_failed_total 1\n",
+		"clockr_ai_errors_total 1\n",
+		"clockr_ai_request_duration_seconds_bucket{le=\"1\"} 0\n",
+		"clockr_ai_request_duration_seconds_bucket{le=\"2\"} 1\n",
+		"clockr_ai_request_duration_seconds_bucket{le=\"30\"} 1\n",
+		"clockr_ai_request_duration_seconds_bucket{le=\"60\"} 2\n",
+		"clockr_ai_request_duration_seconds_bucket{le=\"+Inf\"} 2\n",
+		"clockr_ai_request_duration_seconds_sum 46.5\n",
+		"clockr_ai_request_duration_seconds_count 2\n",
+		"# TYPE clockr_http_errors_total counter\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("missing %q in:\n%s", want, body)
+		}
+	}
+}
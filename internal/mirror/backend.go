@@ -0,0 +1,131 @@
+package mirror
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/backend"
+	"github.com/christopherklint97/clockr/internal/clockify"
+	"github.com/christopherklint97/clockr/internal/store"
+)
+
+// backendDest mirrors entries to another time tracker. Projects are matched
+// by the [mirror.projects] override if there is one, otherwise by name.
+type backendDest struct {
+	name      string
+	overrides map[string]string
+	connect   func(ctx context.Context) (backend.Backend, error)
+
+	mu       sync.Mutex
+	b        backend.Backend
+	projects []clockify.Project
+	listedAt time.Time
+}
+
+// projectsTTL is how long the listed projects are trusted before an entry
+// without a match lists them again, e.g. after a project was added.
+const projectsTTL = time.Hour
+
+func newBackend(name string, overrides map[string]string, connect func(ctx context.Context) (backend.Backend, error)) *backendDest {
+	return &backendDest{name: name, overrides: overrides, connect: connect}
+}
+
+func (d *backendDest) Name() string { return d.name }
+
+func (d *backendDest) Write(ctx context.Context, e store.Entry) (string, error) {
+	b, projects, err := d.load(ctx, false)
+	if err != nil {
+		return "", err
+	}
+	p, ok := d.match(projects, e)
+	if !ok {
+		if b, projects, err = d.load(ctx, true); err != nil {
+			return "", err
+		}
+		p, ok = d.match(projects, e)
+	}
+	if !ok {
+		return "", fmt.Errorf("no %s project for %q — map it in [mirror.projects]", b.Name(), e.ProjectName)
+	}
+	created, err := b.CreateEntry(ctx, clockify.TimeEntryRequest{
+		Start:       e.StartTime.UTC().Format("2006-01-02T15:04:05Z"),
+		End:         e.EndTime.UTC().Format("2006-01-02T15:04:05Z"),
+		ProjectID:   p.ID,
+		Description: e.Description,
+	})
+	if err != nil {
+		return "", err
+	}
+	return created.ID, nil
+}
+
+// load connects and lists the projects on first use. With stale set, it
+// lists them again once they are older than projectsTTL.
+func (d *backendDest) load(ctx context.Context, stale bool) (backend.Backend, []clockify.Project, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.b != nil && (!stale || time.Since(d.listedAt) < projectsTTL) {
+		return d.b, d.projects, nil
+	}
+	b := d.b
+	if b == nil {
+		var err error
+		if b, err = d.connect(ctx); err != nil {
+			return nil, nil, err
+		}
+	}
+	projects, err := b.ListProjects(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("listing %s projects: %w", b.Name(), err)
+	}
+	d.b, d.projects, d.listedAt = b, projects, time.Now()
+	return b, projects, nil
+}
+
+// match finds e's project among projects: the one [mirror.projects] names
+// for its ID or name, or else one with the same name, preferring the same
+// client.
+func (d *backendDest) match(projects []clockify.Project, e store.Entry) (clockify.Project, bool) {
+	want, client := e.ProjectName, e.ClientName
+	if target, ok := lookup(d.overrides, e.ProjectID, e.ProjectName); ok {
+		want, client = target, ""
+		for _, p := range projects {
+			if p.ID == target {
+				return p, true
+			}
+		}
+	}
+	var found *clockify.Project
+	for i, p := range projects {
+		if !strings.EqualFold(p.Name, want) {
+			continue
+		}
+		if client == "" || strings.EqualFold(p.ClientName, client) {
+			return p, true
+		}
+		if found == nil {
+			found = &projects[i]
+		}
+	}
+	if found != nil {
+		return *found, true
+	}
+	return clockify.Project{}, false
+}
+
+// lookup returns m's value for id, or else for name compared
+// case-insensitively.
+func lookup(m map[string]string, id, name string) (string, bool) {
+	if v, ok := m[id]; ok && id != "" {
+		return v, true
+	}
+	for k, v := range m {
+		if strings.EqualFold(k, name) {
+			return v, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,71 @@
+package mirror
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/store"
+)
+
+// csvHeader starts a new ledger file.
+var csvHeader = []string{"Entry", "Date", "Start", "End", "Minutes", "Client", "Project", "Description", "Issue"}
+
+// CSV appends one row per entry to a ledger file, writing the header when
+// the file is new. Times are in Location.
+type CSV struct {
+	Path     string
+	Location *time.Location
+
+	mu sync.Mutex
+}
+
+func (c *CSV) Name() string { return "csv" }
+
+func (c *CSV) Write(_ context.Context, e store.Entry) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(c.Path), 0o755); err != nil {
+		return "", fmt.Errorf("creating ledger directory: %w", err)
+	}
+	f, err := os.OpenFile(c.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return "", fmt.Errorf("opening ledger: %w", err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return "", fmt.Errorf("opening ledger: %w", err)
+	}
+
+	loc := c.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	start, end := e.StartTime.In(loc), e.EndTime.In(loc)
+	w := csv.NewWriter(f)
+	if info.Size() == 0 {
+		w.Write(csvHeader)
+	}
+	w.Write([]string{
+		strconv.Itoa(e.ID),
+		start.Format("2006-01-02"),
+		start.Format("15:04"),
+		end.Format("15:04"),
+		strconv.Itoa(e.Minutes),
+		e.ClientName,
+		e.ProjectName,
+		e.Description,
+		e.IssueKey,
+	})
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return "", fmt.Errorf("writing ledger: %w", err)
+	}
+	return "", nil
+}
@@ -0,0 +1,240 @@
+// Package mirror copies logged entries to destinations besides the backend
+// — another time tracker, Tempo or a CSV ledger — and records per entry and
+// destination whether the copy was made, so failed writes are retried.
+package mirror
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/backend"
+	"github.com/christopherklint97/clockr/internal/clockify"
+	"github.com/christopherklint97/clockr/internal/config"
+	"github.com/christopherklint97/clockr/internal/harvest"
+	"github.com/christopherklint97/clockr/internal/store"
+	"github.com/christopherklint97/clockr/internal/tempo"
+	"github.com/christopherklint97/clockr/internal/toggl"
+)
+
+// Destination is one place entries are mirrored to.
+type Destination interface {
+	// Name is the destination as written in [mirror] to, e.g. "csv".
+	Name() string
+	// Write copies e and returns the destination's ID for the copy, or ""
+	// if it has none.
+	Write(ctx context.Context, e store.Entry) (string, error)
+}
+
+// Open returns the destinations cfg mirrors to. A destination that cannot be
+// set up still takes part and fails every write with the reason, so the
+// problem is recorded against each entry.
+func Open(cfg *config.Config, logger *slog.Logger) []Destination {
+	var dests []Destination
+	for _, name := range cfg.Mirrors() {
+		d, err := open(cfg, name, logger)
+		if err != nil {
+			d = broken{name: name, err: err}
+		}
+		dests = append(dests, d)
+	}
+	return dests
+}
+
+func open(cfg *config.Config, name string, logger *slog.Logger) (Destination, error) {
+	switch name {
+	case "csv":
+		if cfg.Mirror.CSV == "" {
+			return nil, fmt.Errorf("[mirror] csv is not set")
+		}
+		return &CSV{Path: cfg.Mirror.CSV, Location: cfg.Schedule.Location()}, nil
+	case "tempo":
+		if cfg.Tempo.Token == "" || cfg.Tempo.JiraToken == "" {
+			return nil, fmt.Errorf("Tempo mirroring needs token and jira_token in [tempo] (or TEMPO_TOKEN and JIRA_API_TOKEN)")
+		}
+		return &Tempo{Client: NewTempoClient(cfg, logger), Issues: cfg.Tempo}, nil
+	case "harvest":
+		if cfg.Harvest.Token == "" || cfg.Harvest.AccountID == "" {
+			return nil, fmt.Errorf("Harvest mirroring needs token and account_id in [harvest] (or HARVEST_TOKEN)")
+		}
+		h := harvest.NewClient(cfg.Harvest.AccountID, cfg.Harvest.Token, cfg.Harvest.Task, cfg.Harvest.BaseURL, logger)
+		h.SetTimeout(cfg.Timeouts.Harvest())
+		h.SetLocation(cfg.Schedule.Location())
+		return newBackend(name, cfg.Mirror.Projects, func(context.Context) (backend.Backend, error) { return h, nil }), nil
+	case "toggl":
+		if cfg.Toggl.APIToken == "" {
+			return nil, fmt.Errorf("Toggl mirroring needs api_token in [toggl] (or TOGGL_API_TOKEN)")
+		}
+		t := toggl.NewClient(cfg.Toggl.APIToken, cfg.Toggl.WorkspaceID, cfg.Toggl.BaseURL, logger)
+		t.SetTimeout(cfg.Timeouts.Toggl())
+		return newBackend(name, cfg.Mirror.Projects, func(context.Context) (backend.Backend, error) { return t, nil }), nil
+	case "clockify":
+		if cfg.Clockify.APIKey == "" {
+			return nil, fmt.Errorf("Clockify mirroring needs api_key in [clockify] (or CLOCKIFY_API_KEY)")
+		}
+		c := clockify.NewClient(cfg.Clockify.APIKey, cfg.Clockify.BaseURL, 0, logger)
+		c.SetTimeout(cfg.Timeouts.Clockify())
+		return newBackend(name, cfg.Mirror.Projects, func(ctx context.Context) (backend.Backend, error) {
+			workspaceID := cfg.Clockify.WorkspaceID
+			if workspaceID == "" {
+				user, err := c.GetUser(ctx)
+				if err != nil {
+					return nil, fmt.Errorf("getting Clockify user: %w", err)
+				}
+				workspaceID = user.DefaultWorkspace
+			}
+			return backend.Clockify(c, workspaceID), nil
+		}), nil
+	}
+	return nil, fmt.Errorf("unknown mirror destination %q", name)
+}
+
+// NewTempoClient returns a Tempo client for cfg's [tempo] settings, in the
+// schedule's zone.
+func NewTempoClient(cfg *config.Config, logger *slog.Logger) *tempo.Client {
+	t := cfg.Tempo
+	c := tempo.NewClient(t.Token, t.BaseURL, tempo.Jira{URL: t.JiraURL, Email: t.JiraEmail, Token: t.JiraToken}, logger)
+	c.SetJQL(t.JQL)
+	c.SetTimeout(cfg.Timeouts.Tempo())
+	c.SetLocation(cfg.Schedule.Location())
+	return c
+}
+
+// writeLease is how long a claimed copy is reserved for one write before
+// another pass may take it over.
+const writeLease = 3 * time.Minute
+
+// Run writes each logged entry to every destination it has not reached yet,
+// skipping destinations that already failed maxAttempts times for it, and
+// records each outcome in db. Each copy is claimed in db first (see
+// store.DB.ClaimMirror), so concurrent runs never write it twice. It returns
+// how many copies were written; the error sums up the writes that failed.
+func Run(ctx context.Context, dests []Destination, db *store.DB, entries []store.Entry, maxAttempts int) (int, error) {
+	if len(dests) == 0 {
+		return 0, nil
+	}
+
+	written := 0
+	failed := make(map[string][]string) // destination → errors
+	var order []string
+	for _, e := range entries {
+		if e.ID == 0 || e.Status != "logged" {
+			continue
+		}
+		for _, d := range dests {
+			if err := ctx.Err(); err != nil {
+				return written, err
+			}
+			claimed, err := db.ClaimMirror(e.ID, d.Name(), maxAttempts, writeLease)
+			if err != nil {
+				return written, err
+			}
+			if !claimed {
+				continue
+			}
+			remoteID, writeErr := d.Write(ctx, e)
+			if err := db.RecordMirror(e.ID, d.Name(), remoteID, writeErr); err != nil {
+				return written, err
+			}
+			if writeErr != nil {
+				if failed[d.Name()] == nil {
+					order = append(order, d.Name())
+				}
+				failed[d.Name()] = append(failed[d.Name()], writeErr.Error())
+				continue
+			}
+			written++
+		}
+	}
+	if len(order) == 0 {
+		return written, nil
+	}
+	var parts []string
+	for _, name := range order {
+		errs := failed[name]
+		parts = append(parts, fmt.Sprintf("%s: %d failed (%s)", name, len(errs), joinUnique(errs)))
+	}
+	return written, fmt.Errorf("mirroring: %s", strings.Join(parts, "; "))
+}
+
+// Mirrors is the destinations one config mirrors to. They are opened once,
+// so a long-running process reuses their clients and project lists across
+// syncs until the config is reloaded.
+type Mirrors struct {
+	dests    []Destination
+	attempts int
+}
+
+// New opens the destinations cfg mirrors to.
+func New(cfg *config.Config, logger *slog.Logger) *Mirrors {
+	return &Mirrors{dests: Open(cfg, logger), attempts: cfg.Mirror.Attempts()}
+}
+
+// Sync mirrors entries to the destinations. It does nothing when there are
+// none or db is read-only.
+func (m *Mirrors) Sync(ctx context.Context, db *store.DB, entries []store.Entry) (int, error) {
+	if len(m.dests) == 0 || db.ReadOnly() {
+		return 0, nil
+	}
+	return Run(ctx, m.dests, db, entries, m.attempts)
+}
+
+// Sync mirrors entries to the destinations cfg lists, for one-off commands.
+func Sync(ctx context.Context, cfg *config.Config, db *store.DB, entries []store.Entry, logger *slog.Logger) (int, error) {
+	if len(cfg.Mirrors()) == 0 || db.ReadOnly() {
+		return 0, nil
+	}
+	return New(cfg, logger).Sync(ctx, db, entries)
+}
+
+// broken is a destination that could not be set up.
+type broken struct {
+	name string
+	err  error
+}
+
+func (b broken) Name() string { return b.name }
+
+func (b broken) Write(context.Context, store.Entry) (string, error) { return "", b.err }
+
+// joinUnique joins s, skipping repeats.
+func joinUnique(s []string) string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, v := range s {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return strings.Join(out, ", ")
+}
+
+// RetryWindow is how far back Retry looks for entries missing a copy.
+const RetryWindow = 7 * 24 * time.Hour
+
+// Retry mirrors the entries of the last RetryWindow that have not reached
+// every destination yet, e.g. because a destination was down or the entry
+// only just made it to the backend.
+func (m *Mirrors) Retry(ctx context.Context, db *store.DB) (int, error) {
+	if len(m.dests) == 0 || db.ReadOnly() {
+		return 0, nil
+	}
+	now := time.Now()
+	entries, err := db.GetEntriesBetween(now.Add(-RetryWindow), now)
+	if err != nil {
+		return 0, fmt.Errorf("fetching entries to mirror: %w", err)
+	}
+	return m.Sync(ctx, db, entries)
+}
+
+// Retry is Mirrors.Retry for the destinations cfg lists, for one-off
+// commands.
+func Retry(ctx context.Context, cfg *config.Config, db *store.DB, logger *slog.Logger) (int, error) {
+	if len(cfg.Mirrors()) == 0 || db.ReadOnly() {
+		return 0, nil
+	}
+	return New(cfg, logger).Retry(ctx, db)
+}
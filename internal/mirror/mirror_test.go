@@ -0,0 +1,209 @@
+package mirror
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/clockify"
+	"github.com/christopherklint97/clockr/internal/config"
+	"github.com/christopherklint97/clockr/internal/store"
+)
+
+func testDB(t *testing.T) *store.DB {
+	t.Helper()
+	t.Setenv("CLOCKR_HOME", t.TempDir())
+	db, err := store.Open(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func insert(t *testing.T, db *store.DB, entries ...store.Entry) []store.Entry {
+	t.Helper()
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	var out []store.Entry
+	for _, e := range entries {
+		e.StartTime, e.EndTime, e.Minutes, e.Status = start, start.Add(time.Hour), 60, "logged"
+		if _, err := db.InsertEntry(&e); err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, e)
+		start = start.Add(time.Hour)
+	}
+	return out
+}
+
+// flaky fails its first n writes.
+type flaky struct {
+	n      int
+	writes int
+}
+
+func (f *flaky) Name() string { return "flaky" }
+
+func (f *flaky) Write(context.Context, store.Entry) (string, error) {
+	f.writes++
+	if f.writes <= f.n {
+		return "", errors.New("unavailable")
+	}
+	return fmt.Sprintf("r%d", f.writes), nil
+}
+
+func TestRun_RecordsAndRetries(t *testing.T) {
+	db := testDB(t)
+	entries := insert(t, db, store.Entry{ProjectID: "p1", ProjectName: "Website"})
+	ledger := filepath.Join(t.TempDir(), "ledger.csv")
+	f := &flaky{n: 1}
+	dests := []Destination{&CSV{Path: ledger, Location: time.UTC}, f}
+
+	n, err := Run(context.Background(), dests, db, entries, 3)
+	if n != 1 || err == nil || !strings.Contains(err.Error(), "flaky: 1 failed (unavailable)") {
+		t.Fatalf("first run wrote %d, err %v", n, err)
+	}
+	state, _ := db.GetMirrors([]int{entries[0].ID})
+	if m := state[entries[0].ID]["flaky"]; m.Status != "failed" || m.Attempts != 1 || m.Error != "unavailable" {
+		t.Errorf("flaky state = %+v", m)
+	}
+
+	// The retry only writes to the destination that failed.
+	if n, err := Run(context.Background(), dests, db, entries, 3); n != 1 || err != nil {
+		t.Fatalf("retry wrote %d, err %v", n, err)
+	}
+	state, _ = db.GetMirrors([]int{entries[0].ID})
+	if m := state[entries[0].ID]["flaky"]; m.Status != "logged" || m.RemoteID != "r2" || m.Attempts != 2 {
+		t.Errorf("flaky state after retry = %+v", m)
+	}
+	if n, _ := Run(context.Background(), dests, db, entries, 3); n != 0 {
+		t.Errorf("nothing should be left to mirror, wrote %d", n)
+	}
+
+	data, err := os.ReadFile(ledger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, _ := csv.NewReader(strings.NewReader(string(data))).ReadAll()
+	if len(rows) != 2 || rows[0][0] != "Entry" || rows[1][2] != "09:00" || rows[1][6] != "Website" {
+		t.Errorf("ledger = %q", rows)
+	}
+}
+
+func TestRun_GivesUpAfterMaxAttempts(t *testing.T) {
+	db := testDB(t)
+	entries := insert(t, db, store.Entry{ProjectName: "Website"})
+	f := &flaky{n: 10}
+	for range 4 {
+		Run(context.Background(), []Destination{f}, db, entries, 2)
+	}
+	if f.writes != 2 {
+		t.Errorf("wrote %d times, want 2", f.writes)
+	}
+}
+
+func TestRun_SkipsClaimedCopies(t *testing.T) {
+	db := testDB(t)
+	entries := insert(t, db, store.Entry{ProjectName: "Website"})
+	id := entries[0].ID
+
+	// Another pass or process is writing the copy.
+	if ok, err := db.ClaimMirror(id, "flaky", 3, writeLease); !ok || err != nil {
+		t.Fatalf("first claim = %v, %v", ok, err)
+	}
+	f := &flaky{}
+	if n, err := Run(context.Background(), []Destination{f}, db, entries, 3); n != 0 || err != nil || f.writes != 0 {
+		t.Fatalf("claimed copy written: n=%d writes=%d err=%v", n, f.writes, err)
+	}
+
+	// A claim that was never recorded is taken over once the lease expires.
+	if ok, _ := db.ClaimMirror(id, "flaky", 3, 0); !ok {
+		t.Error("an expired claim should be taken over")
+	}
+	db.RecordMirror(id, "flaky", "r1", nil)
+	if ok, _ := db.ClaimMirror(id, "flaky", 3, 0); ok {
+		t.Error("a logged copy should not be claimed")
+	}
+}
+
+func TestBackendDest_MatchesProjects(t *testing.T) {
+	d := newBackend("toggl", map[string]string{"Internal": "t9"}, nil)
+	projects := []clockify.Project{
+		{ID: "t1", Name: "Website", ClientName: "Beta"},
+		{ID: "t2", Name: "website", ClientName: "Acme"},
+		{ID: "t9", Name: "Overhead"},
+	}
+	tests := []struct {
+		entry store.Entry
+		want  string
+	}{
+		{store.Entry{ProjectName: "Website", ClientName: "Acme"}, "t2"},
+		{store.Entry{ProjectName: "Website", ClientName: "Other"}, "t1"},
+		{store.Entry{ProjectName: "internal"}, "t9"},
+		{store.Entry{ProjectName: "Admin"}, ""},
+	}
+	for _, tt := range tests {
+		p, _ := d.match(projects, tt.entry)
+		if p.ID != tt.want {
+			t.Errorf("match(%q/%q) = %q, want %q", tt.entry.ClientName, tt.entry.ProjectName, p.ID, tt.want)
+		}
+	}
+}
+
+func TestSync_Tempo(t *testing.T) {
+	db := testDB(t)
+	var issues []float64
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /rest/api/3/myself", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"accountId": "acc-1"}`))
+	})
+	mux.HandleFunc("GET /rest/api/3/issue/{key}", func(w http.ResponseWriter, r *http.Request) {
+		id := map[string]int{"WEB-1": 10001, "OPS-2": 10002}[r.PathValue("key")]
+		fmt.Fprintf(w, `{"id": "%d", "key": %q}`, id, r.PathValue("key"))
+	})
+	mux.HandleFunc("POST /worklogs", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		issues = append(issues, body["issueId"].(float64))
+		fmt.Fprintf(w, `{"tempoWorklogId": %d}`, len(issues))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	cfg := config.DefaultConfig()
+	cfg.Mirror.To = []string{"tempo"}
+	cfg.Tempo = config.TempoConfig{
+		Token: "tok", JiraURL: srv.URL, JiraEmail: "me@acme.com", JiraToken: "jira", BaseURL: srv.URL,
+		Issues: map[string]string{"Ops": "OPS-2"},
+	}
+	entries := insert(t, db,
+		store.Entry{ProjectID: "p1", ProjectName: "Website", IssueKey: "WEB-1"},
+		store.Entry{ProjectID: "p2", ProjectName: "Ops"},
+		store.Entry{ProjectID: "p3", ProjectName: "Admin"},
+	)
+
+	n, err := Sync(context.Background(), &cfg, db, entries, nil)
+	if n != 2 || len(issues) != 2 || issues[0] != 10001 || issues[1] != 10002 {
+		t.Fatalf("mirrored %d to issues %v", n, issues)
+	}
+	if err == nil || !strings.Contains(err.Error(), "Admin") {
+		t.Errorf("the unmapped entry should be reported, got %v", err)
+	}
+	state, _ := db.GetMirrors([]int{entries[0].ID})
+	if m := state[entries[0].ID]["tempo"]; m.RemoteID != "1" {
+		t.Errorf("worklog ID = %q, want 1", m.RemoteID)
+	}
+
+	if n, _ := Sync(context.Background(), &cfg, db, entries[:2], nil); n != 0 {
+		t.Errorf("entries with a worklog should not be mirrored again, mirrored %d", n)
+	}
+}
@@ -0,0 +1,30 @@
+package mirror
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/christopherklint97/clockr/internal/config"
+	"github.com/christopherklint97/clockr/internal/store"
+	"github.com/christopherklint97/clockr/internal/tempo"
+)
+
+// Tempo logs a worklog on the entry's Jira issue: the key the AI gave it,
+// or else the one [tempo.issues] maps its project to.
+type Tempo struct {
+	Client *tempo.Client
+	Issues config.TempoConfig
+}
+
+func (t *Tempo) Name() string { return "tempo" }
+
+func (t *Tempo) Write(ctx context.Context, e store.Entry) (string, error) {
+	key := e.IssueKey
+	if key == "" {
+		key = t.Issues.IssueFor(e.ProjectID, e.ProjectName)
+	}
+	if key == "" {
+		return "", fmt.Errorf("no Jira issue for %q — add the project to [tempo.issues]", e.ProjectName)
+	}
+	return t.Client.AddWorklog(ctx, key, e.StartTime, e.EndTime, e.Description)
+}
@@ -0,0 +1,92 @@
+package msgraph
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// aadReasons explains the Azure AD (AADSTS) errors that only a new sign-in
+// fixes.
+var aadReasons = map[int]string{
+	50076:  "multi-factor authentication is required",
+	50079:  "multi-factor authentication must be set up",
+	50133:  "the session expired or the password was changed",
+	50173:  "the password was changed",
+	53003:  "a Conditional Access policy blocked the sign-in",
+	65001:  "the app's permissions changed and need consent",
+	70043:  "the refresh token expired under a sign-in frequency policy",
+	70008:  "the refresh token expired after inactivity",
+	700082: "the refresh token expired after inactivity",
+	700084: "the refresh token expired",
+}
+
+// reauthGrantErrors are OAuth error codes from the token endpoint that mean
+// the refresh token can no longer be used.
+var reauthGrantErrors = map[string]bool{
+	"invalid_grant":        true,
+	"interaction_required": true,
+	"consent_required":     true,
+	"login_required":       true,
+}
+
+var aadstsCode = regexp.MustCompile(`AADSTS(\d+)`)
+
+// aadCode returns the AADSTS code of a token endpoint error, from its
+// error_codes or its description, or 0.
+func aadCode(codes []int, desc string) int {
+	if len(codes) > 0 {
+		return codes[0]
+	}
+	if m := aadstsCode.FindStringSubmatch(desc); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		return n
+	}
+	return 0
+}
+
+// aadReason describes an AADSTS code for the user, e.g. "AADSTS70008: the
+// refresh token expired after inactivity". Unknown codes get the first line
+// of desc.
+func aadReason(code int, desc string) string {
+	if code == 0 {
+		return ""
+	}
+	reason, ok := aadReasons[code]
+	if !ok {
+		reason = strings.TrimSpace(strings.TrimPrefix(firstLine(desc), fmt.Sprintf("AADSTS%d:", code)))
+	}
+	return fmt.Sprintf("AADSTS%d: %s", code, reason)
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexAny(s, "\r\n"); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
+
+// graphAuthError returns a ReauthError when a Graph API response says the
+// token is no longer valid (401) or lacks a permission (403), or nil.
+func graphAuthError(status int, body []byte) error {
+	if status != 401 && status != 403 {
+		return nil
+	}
+	var resp struct {
+		Error struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	json.Unmarshal(body, &resp)
+	reason := fmt.Sprintf("Graph answered %d", status)
+	if resp.Error.Code != "" {
+		reason += " " + resp.Error.Code
+	}
+	if status == 403 {
+		reason += ": the sign-in lacks a permission this needs"
+	}
+	return &ReauthError{Reason: reason}
+}
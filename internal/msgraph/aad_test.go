@@ -0,0 +1,50 @@
+package msgraph
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestAADReason(t *testing.T) {
+	tests := []struct {
+		codes []int
+		desc  string
+		want  string
+	}{
+		{[]int{70008}, "AADSTS70008: The provided authorization code or refresh token has expired.", "AADSTS70008: the refresh token expired after inactivity"},
+		{nil, "AADSTS50173: The provided grant has expired due to it being revoked.\r\nTrace ID: x", "AADSTS50173: the password was changed"},
+		{nil, "AADSTS12345: Something new happened.\r\nTrace ID: x", "AADSTS12345: Something new happened."},
+		{nil, "no code here", ""},
+	}
+	for _, tt := range tests {
+		if got := aadReason(aadCode(tt.codes, tt.desc), tt.desc); got != tt.want {
+			t.Errorf("aadReason(%v, %q) = %q, want %q", tt.codes, tt.desc, got, tt.want)
+		}
+	}
+}
+
+func TestReauthError(t *testing.T) {
+	err := fmt.Errorf("fetching: %w", reauthError("work", "AADSTS53003: a Conditional Access policy blocked the sign-in"))
+	if !errors.Is(err, ErrReauthRequired) {
+		t.Error("errors.Is(ErrReauthRequired) = false")
+	}
+	want := `fetching: Microsoft Graph sign-in "work" expired or was revoked (AADSTS53003: a Conditional Access policy blocked the sign-in) — run 'clockr calendar auth --profile work'`
+	if err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err, want)
+	}
+	if got := reauthError("", "").Error(); got != ErrReauthRequired.Error() {
+		t.Errorf("default sign-in without a reason = %q, want %q", got, ErrReauthRequired)
+	}
+}
+
+func TestGraphAuthError(t *testing.T) {
+	if err := graphAuthError(500, nil); err != nil {
+		t.Errorf("500 = %v, want nil", err)
+	}
+	err := graphAuthError(403, []byte(`{"error":{"code":"ErrorAccessDenied","message":"Access is denied."}}`))
+	var re *ReauthError
+	if !errors.As(err, &re) || re.Reason != "Graph answered 403 ErrorAccessDenied: the sign-in lacks a permission this needs" {
+		t.Errorf("403 = %#v", err)
+	}
+}
@@ -3,6 +3,7 @@ package msgraph
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -12,12 +13,51 @@ import (
 	"time"
 )
 
-const defaultScope = "Calendars.Read offline_access"
+const (
+	defaultLoginURL = "https://login.microsoftonline.com"
+
+	defaultScope = "Calendars.Read offline_access"
+	// writeScope is requested when calendar write-back is enabled.
+	writeScope = "Calendars.ReadWrite offline_access"
+)
+
+// ErrReauthRequired means the refresh token was rejected (expired, or
+// revoked by tenant policy) and only a new device code sign-in helps.
+var ErrReauthRequired = errors.New("Microsoft Graph sign-in expired or was revoked — run 'clockr calendar auth'")
+
+// ReauthError is ErrReauthRequired with the sign-in it is about and, when
+// Azure AD or Graph said why, the reason. errors.Is matches it against
+// ErrReauthRequired.
+type ReauthError struct {
+	Profile string // "" for the default sign-in
+	Reason  string // e.g. "AADSTS70008: the refresh token expired after inactivity"
+}
+
+func (e *ReauthError) Error() string {
+	msg := "Microsoft Graph sign-in expired or was revoked"
+	if e.Profile != "" {
+		msg = fmt.Sprintf("Microsoft Graph sign-in %q expired or was revoked", e.Profile)
+	}
+	if e.Reason != "" {
+		msg += " (" + e.Reason + ")"
+	}
+	return msg + " — run '" + authCommand(e.Profile) + "'"
+}
+
+func (e *ReauthError) Is(target error) bool { return target == ErrReauthRequired }
+
+// reauthError is the error asking to sign profile in again.
+func reauthError(profile, reason string) error {
+	return &ReauthError{Profile: profile, Reason: reason}
+}
 
 // Auth handles OAuth2 device code flow for Microsoft Graph API.
 type Auth struct {
 	clientID   string
 	tenantID   string
+	scope      string
+	profile    string // token file to use; "" for the default sign-in
+	loginURL   string // Azure AD endpoint
 	httpClient *http.Client
 	logger     *slog.Logger
 }
@@ -30,6 +70,8 @@ func NewAuth(clientID, tenantID string, logger *slog.Logger) *Auth {
 	return &Auth{
 		clientID: clientID,
 		tenantID: tenantID,
+		scope:    defaultScope,
+		loginURL: defaultLoginURL,
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
@@ -55,10 +97,36 @@ type tokenResponse struct {
 	Scope        string `json:"scope"`
 	Error        string `json:"error"`
 	ErrorDesc    string `json:"error_description"`
+	ErrorCodes   []int  `json:"error_codes"`
+}
+
+// SetWriteAccess makes the device code flow ask for Calendars.ReadWrite,
+// which calendar write-back needs.
+func (a *Auth) SetWriteAccess(write bool) {
+	a.scope = defaultScope
+	if write {
+		a.scope = writeScope
+	}
+}
+
+// SetProfile selects the named sign-in's tokens, as saved by 'clockr
+// calendar auth --profile NAME'.
+func (a *Auth) SetProfile(profile string) {
+	a.profile = profile
+}
+
+// SetLoginURL sends the device code and token requests to another Azure AD
+// endpoint than login.microsoftonline.com, such as a national cloud's. An
+// empty loginURL restores the default.
+func (a *Auth) SetLoginURL(loginURL string) {
+	if loginURL == "" {
+		loginURL = defaultLoginURL
+	}
+	a.loginURL = strings.TrimRight(loginURL, "/")
 }
 
 func (a *Auth) baseURL() string {
-	return fmt.Sprintf("https://login.microsoftonline.com/%s/oauth2/v2.0", a.tenantID)
+	return fmt.Sprintf("%s/%s/oauth2/v2.0", a.loginURL, a.tenantID)
 }
 
 // StartDeviceCodeFlow initiates the device code flow and returns the response
@@ -68,7 +136,7 @@ func (a *Auth) StartDeviceCodeFlow(ctx context.Context) (*DeviceCodeResponse, er
 
 	form := url.Values{
 		"client_id": {a.clientID},
-		"scope":     {defaultScope},
+		"scope":     {a.scope},
 	}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
@@ -169,13 +237,17 @@ func (a *Auth) PollForToken(ctx context.Context, deviceCode string, interval int
 
 // RefreshAccessToken uses a refresh token to obtain a new access token.
 func (a *Auth) RefreshAccessToken(ctx context.Context, refreshToken string) (*TokenData, error) {
+	return a.refresh(ctx, refreshToken, a.scope)
+}
+
+func (a *Auth) refresh(ctx context.Context, refreshToken, scope string) (*TokenData, error) {
 	endpoint := a.baseURL() + "/token"
 
 	form := url.Values{
 		"client_id":     {a.clientID},
 		"grant_type":    {"refresh_token"},
 		"refresh_token": {refreshToken},
-		"scope":         {defaultScope},
+		"scope":         {scope},
 	}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
@@ -200,6 +272,11 @@ func (a *Auth) RefreshAccessToken(ctx context.Context, refreshToken string) (*To
 		return nil, fmt.Errorf("parsing refresh response: %w", err)
 	}
 
+	code := aadCode(tokenResp.ErrorCodes, tokenResp.ErrorDesc)
+	if reauthGrantErrors[tokenResp.Error] || (tokenResp.Error != "" && aadReasons[code] != "") {
+		a.logger.Warn("graph refresh token rejected", "error", tokenResp.Error, "error_description", tokenResp.ErrorDesc)
+		return nil, reauthError(a.profile, aadReason(code, tokenResp.ErrorDesc))
+	}
 	if tokenResp.Error != "" {
 		return nil, fmt.Errorf("refresh failed: %s — %s", tokenResp.Error, tokenResp.ErrorDesc)
 	}
@@ -212,28 +289,48 @@ func (a *Auth) RefreshAccessToken(ctx context.Context, refreshToken string) (*To
 	}, nil
 }
 
-// EnsureValidToken loads cached tokens, auto-refreshes if expired, and returns a valid access token.
+// EnsureValidToken loads the profile's cached tokens, auto-refreshes if expired, and returns a valid access token.
 // Returns an error telling the user to run `clockr calendar auth` if no tokens are cached.
 func (a *Auth) EnsureValidToken(ctx context.Context) (string, error) {
-	tokens, err := LoadTokens()
+	tokens, err := LoadTokens(a.profile)
 	if err != nil {
 		return "", fmt.Errorf("loading cached tokens: %w", err)
 	}
 	if tokens == nil {
-		return "", fmt.Errorf("not authenticated with Microsoft Graph — run 'clockr calendar auth' first")
+		return "", fmt.Errorf("not authenticated with Microsoft Graph — run '%s' first", authCommand(a.profile))
 	}
 
+	if tokens.NeedsReauth {
+		return "", reauthError(a.profile, tokens.ReauthReason)
+	}
 	if !tokens.IsExpired() {
 		return tokens.AccessToken, nil
 	}
 
+	// Keep write access across refreshes, even for read-only callers.
+	scope := a.scope
+	if tokens.CanWrite() {
+		scope = writeScope
+	}
 	a.logger.Debug("access token expired, refreshing")
-	newTokens, err := a.RefreshAccessToken(ctx, tokens.RefreshToken)
+	newTokens, err := a.refresh(ctx, tokens.RefreshToken, scope)
+	if errors.Is(err, ErrReauthRequired) {
+		// Remember it, so later fetches fail fast instead of asking again.
+		tokens.NeedsReauth = true
+		var re *ReauthError
+		if errors.As(err, &re) {
+			tokens.ReauthReason = re.Reason
+		}
+		if err := SaveTokens(a.profile, tokens); err != nil {
+			a.logger.Warn("failed to mark tokens as needing reauth", "error", err)
+		}
+		return "", err
+	}
 	if err != nil {
-		return "", fmt.Errorf("token refresh failed (run 'clockr calendar auth' to re-authenticate): %w", err)
+		return "", fmt.Errorf("token refresh failed (run '%s' to re-authenticate): %w", authCommand(a.profile), err)
 	}
 
-	if err := SaveTokens(newTokens); err != nil {
+	if err := SaveTokens(a.profile, newTokens); err != nil {
 		a.logger.Warn("failed to cache refreshed tokens", "error", err)
 	}
 
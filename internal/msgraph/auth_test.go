@@ -0,0 +1,173 @@
+package msgraph
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeLogin starts an Azure AD stand-in for tenant "tenant" whose token
+// endpoint answers with token, and returns an Auth for profile pointed at
+// it and a count of the token requests.
+func fakeLogin(t *testing.T, profile string, token http.HandlerFunc) (*Auth, *atomic.Int32) {
+	t.Helper()
+	t.Setenv("CLOCKR_HOME", t.TempDir())
+	t.Setenv("CLOCKR_NO_KEYCHAIN", "1")
+
+	var calls atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /tenant/oauth2/v2.0/devicecode", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"device_code":"dev-1","user_code":"ABCD","interval":1,"message":"Go to the page and enter ABCD"}`))
+	})
+	mux.HandleFunc("POST /tenant/oauth2/v2.0/token", func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		token(w, r)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	auth := NewAuth("client", "tenant", nil)
+	auth.SetProfile(profile)
+	auth.SetLoginURL(srv.URL + "/")
+	return auth, &calls
+}
+
+// saveExpired caches an expired access token for profile.
+func saveExpired(t *testing.T, profile, scope string) {
+	t.Helper()
+	err := SaveTokens(profile, &TokenData{
+		AccessToken:  "old",
+		RefreshToken: "refresh-1",
+		ExpiresAt:    time.Now().Add(-time.Hour),
+		Scope:        scope,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEnsureValidToken_Refreshes(t *testing.T) {
+	var gotScope, gotRefresh string
+	auth, _ := fakeLogin(t, "", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		gotScope, gotRefresh = r.Form.Get("scope"), r.Form.Get("refresh_token")
+		w.Write([]byte(`{"access_token":"new","refresh_token":"refresh-2","expires_in":3600,"scope":"Calendars.ReadWrite offline_access"}`))
+	})
+	saveExpired(t, "", "Calendars.ReadWrite offline_access")
+
+	token, err := auth.EnsureValidToken(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "new" {
+		t.Errorf("token = %q, want new", token)
+	}
+	if gotRefresh != "refresh-1" {
+		t.Errorf("refresh_token = %q, want refresh-1", gotRefresh)
+	}
+	// Write access is kept even though auth only asks to read.
+	if gotScope != writeScope {
+		t.Errorf("scope = %q, want %q", gotScope, writeScope)
+	}
+	saved, err := LoadTokens("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.AccessToken != "new" || saved.RefreshToken != "refresh-2" {
+		t.Errorf("saved tokens = %+v", saved)
+	}
+}
+
+func TestEnsureValidToken_Reauth(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		reason string
+	}{
+		{
+			"expired after inactivity",
+			`{"error":"invalid_grant","error_description":"AADSTS70008: The provided authorization code or refresh token has expired due to inactivity.\r\nTrace ID: x","error_codes":[70008]}`,
+			"AADSTS70008: the refresh token expired after inactivity",
+		},
+		{
+			"interaction required",
+			`{"error":"interaction_required","error_description":"AADSTS50076: Due to a configuration change made by your administrator, you must use multi-factor authentication.\r\nTrace ID: x","error_codes":[50076]}`,
+			"AADSTS50076: multi-factor authentication is required",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auth, calls := fakeLogin(t, "work", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(tt.body))
+			})
+			saveExpired(t, "work", defaultScope)
+
+			_, err := auth.EnsureValidToken(context.Background())
+			var re *ReauthError
+			if !errors.As(err, &re) {
+				t.Fatalf("err = %v, want a ReauthError", err)
+			}
+			if re.Profile != "work" || re.Reason != tt.reason {
+				t.Errorf("ReauthError = %+v, want profile work, reason %q", re, tt.reason)
+			}
+
+			saved, err := LoadTokens("work")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !saved.NeedsReauth || saved.ReauthReason != tt.reason {
+				t.Errorf("saved tokens = %+v, want NeedsReauth with the reason", saved)
+			}
+
+			// The next check fails without asking Azure AD again.
+			if _, err := auth.EnsureValidToken(context.Background()); !errors.Is(err, ErrReauthRequired) {
+				t.Errorf("second err = %v, want ErrReauthRequired", err)
+			}
+			if n := calls.Load(); n != 1 {
+				t.Errorf("token requests = %d, want 1", n)
+			}
+		})
+	}
+}
+
+func TestDeviceCodeFlow(t *testing.T) {
+	var pending atomic.Bool
+	pending.Store(true)
+	auth, calls := fakeLogin(t, "", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("device_code") != "dev-1" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+		if pending.Swap(false) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"authorization_pending"}`))
+			return
+		}
+		w.Write([]byte(`{"access_token":"access","refresh_token":"refresh","expires_in":3600,"scope":"Calendars.Read offline_access"}`))
+	})
+
+	dc, err := auth.StartDeviceCodeFlow(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dc.UserCode != "ABCD" || dc.Interval != 1 {
+		t.Errorf("device code = %+v", dc)
+	}
+	tokens, err := auth.PollForToken(context.Background(), dc.DeviceCode, dc.Interval)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tokens.AccessToken != "access" || tokens.RefreshToken != "refresh" {
+		t.Errorf("tokens = %+v", tokens)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("token requests = %d, want 2", n)
+	}
+}
@@ -1,17 +1,21 @@
 package msgraph
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
-	"math"
 	"net/http"
 	"net/url"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/christopherklint97/clockr/internal/calendar"
+	"github.com/christopherklint97/clockr/internal/httpmetrics"
+	"github.com/christopherklint97/clockr/internal/httpretry"
 )
 
 const graphBaseURL = "https://graph.microsoft.com/v1.0"
@@ -19,6 +23,7 @@ const graphBaseURL = "https://graph.microsoft.com/v1.0"
 // Client is a Microsoft Graph API client for calendar operations.
 type Client struct {
 	auth       *Auth
+	baseURL    string
 	httpClient *http.Client
 	logger     *slog.Logger
 }
@@ -29,14 +34,25 @@ func NewClient(auth *Auth, logger *slog.Logger) *Client {
 		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
 	}
 	return &Client{
-		auth: auth,
+		auth:    auth,
+		baseURL: graphBaseURL,
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   30 * time.Second,
+			Transport: httpmetrics.Wrap("graph", nil),
 		},
 		logger: logger,
 	}
 }
 
+// SetBaseURL sends the API requests to another Graph endpoint than
+// graph.microsoft.com/v1.0. An empty baseURL restores the default.
+func (c *Client) SetBaseURL(baseURL string) {
+	if baseURL == "" {
+		baseURL = graphBaseURL
+	}
+	c.baseURL = strings.TrimRight(baseURL, "/")
+}
+
 // calendarViewResponse represents the Graph API calendarView response.
 type calendarViewResponse struct {
 	Value    []graphEvent `json:"value"`
@@ -49,6 +65,7 @@ type graphEvent struct {
 	End         graphDateTime  `json:"end"`
 	IsCancelled bool           `json:"isCancelled"`
 	IsAllDay    bool           `json:"isAllDay"`
+	Categories  []string       `json:"categories"`
 }
 
 type graphDateTime struct {
@@ -67,12 +84,12 @@ func (c *Client) FetchEvents(ctx context.Context, start, end time.Time) ([]calen
 	params := url.Values{
 		"startDateTime": {start.UTC().Format("2006-01-02T15:04:05")},
 		"endDateTime":   {end.UTC().Format("2006-01-02T15:04:05")},
-		"$select":       {"subject,start,end,isCancelled,isAllDay"},
+		"$select":       {"subject,start,end,isCancelled,isAllDay,categories"},
 		"$top":          {"100"},
 		"$orderby":      {"start/dateTime"},
 	}
 
-	requestURL := graphBaseURL + "/me/calendarView?" + params.Encode()
+	requestURL := c.baseURL + "/me/calendarView?" + params.Encode()
 	var allEvents []calendar.Event
 
 	for requestURL != "" {
@@ -96,28 +113,13 @@ func (c *Client) fetchPage(ctx context.Context, token, requestURL string) ([]cal
 	req.Header.Set("Authorization", "Bearer "+token)
 	req.Header.Set("Prefer", "outlook.timezone=\"UTC\"")
 
-	var resp *http.Response
-	maxRetries := 3
-	for attempt := 0; attempt <= maxRetries; attempt++ {
-		resp, err = c.httpClient.Do(req)
-		if err != nil {
-			if attempt == maxRetries {
-				return nil, "", fmt.Errorf("graph API request failed: %w", err)
-			}
-			time.Sleep(backoff(attempt))
-			continue
-		}
-
-		if resp.StatusCode == 429 || resp.StatusCode >= 500 {
-			resp.Body.Close()
-			if attempt == maxRetries {
-				return nil, "", fmt.Errorf("graph API returned status %d after %d retries", resp.StatusCode, maxRetries)
-			}
-			c.logger.Debug("graph API retrying", "status", resp.StatusCode, "attempt", attempt+1)
-			time.Sleep(backoff(attempt))
-			continue
-		}
-		break
+	resp, err := httpretry.Do(c.httpClient, req, c.retryPolicy())
+	if err != nil {
+		return nil, "", fmt.Errorf("graph API request failed: %w", err)
+	}
+	if httpretry.Retryable(resp.StatusCode) {
+		resp.Body.Close()
+		return nil, "", fmt.Errorf("graph API returned status %d after retries", resp.StatusCode)
 	}
 	defer resp.Body.Close()
 
@@ -126,6 +128,9 @@ func (c *Client) fetchPage(ctx context.Context, token, requestURL string) ([]cal
 		return nil, "", fmt.Errorf("reading graph response: %w", err)
 	}
 
+	if err := c.authError(resp.StatusCode, body); err != nil {
+		return nil, "", err
+	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		return nil, "", fmt.Errorf("graph API error (status %d): %s", resp.StatusCode, truncateStr(string(body), 200))
 	}
@@ -143,6 +148,10 @@ func (c *Client) fetchPage(ctx context.Context, token, requestURL string) ([]cal
 		if ge.Subject == "" {
 			continue
 		}
+		// Busy blocks written back by clockr are logged time, not meetings.
+		if slices.Contains(ge.Categories, WriteBackCategory) {
+			continue
+		}
 
 		startTime, err := parseGraphDateTime(ge.Start)
 		if err != nil {
@@ -165,6 +174,98 @@ func (c *Client) fetchPage(ctx context.Context, token, requestURL string) ([]cal
 	return events, viewResp.NextLink, nil
 }
 
+// WriteBackCategory tags events created by CreateEvent, so they are not read
+// back as meetings.
+const WriteBackCategory = "clockr"
+
+// NewEvent is a calendar event to create.
+type NewEvent struct {
+	Subject string
+	Body    string
+	Start   time.Time
+	End     time.Time
+	// TransactionID identifies the event to Graph, which refuses to create
+	// a second event with it. A POST retried after its response was lost
+	// thus cannot add the event twice.
+	TransactionID string
+}
+
+// CreateEvent adds a private, busy, reminder-free event to the user's
+// calendar, tagged with WriteBackCategory, and returns its ID. The token must
+// have Calendars.ReadWrite.
+func (c *Client) CreateEvent(ctx context.Context, e NewEvent) (string, error) {
+	token, err := c.auth.EnsureValidToken(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	event := map[string]any{
+		"subject":      e.Subject,
+		"body":         map[string]string{"contentType": "text", "content": e.Body},
+		"start":        graphDateTime{DateTime: e.Start.UTC().Format("2006-01-02T15:04:05"), TimeZone: "UTC"},
+		"end":          graphDateTime{DateTime: e.End.UTC().Format("2006-01-02T15:04:05"), TimeZone: "UTC"},
+		"showAs":       "busy",
+		"sensitivity":  "private",
+		"isReminderOn": false,
+		"categories":   []string{WriteBackCategory},
+	}
+	if e.TransactionID != "" {
+		event["transactionId"] = e.TransactionID
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return "", fmt.Errorf("encoding event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/me/events", bytes.NewReader(payload))
+	if err != nil {
+		return "", fmt.Errorf("creating graph request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpretry.Do(c.httpClient, req, c.retryPolicy())
+	if err != nil {
+		return "", fmt.Errorf("graph API request failed: %w", err)
+	}
+	if httpretry.Retryable(resp.StatusCode) {
+		resp.Body.Close()
+		return "", fmt.Errorf("graph API returned status %d after retries", resp.StatusCode)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("reading graph response: %w", err)
+	}
+	if resp.StatusCode == http.StatusForbidden {
+		return "", fmt.Errorf("graph API refused to create the event — the token needs Calendars.ReadWrite (run '%s')", authCommand(c.auth.profile))
+	}
+	if err := c.authError(resp.StatusCode, body); err != nil {
+		return "", err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("graph API error (status %d): %s", resp.StatusCode, truncateStr(string(body), 200))
+	}
+
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(body, &created); err != nil {
+		return "", fmt.Errorf("parsing graph response: %w", err)
+	}
+	return created.ID, nil
+}
+
+// authError is graphAuthError for this client's sign-in.
+func (c *Client) authError(status int, body []byte) error {
+	err := graphAuthError(status, body)
+	if re, ok := err.(*ReauthError); ok {
+		re.Profile = c.auth.profile
+	}
+	return err
+}
+
 func parseGraphDateTime(gdt graphDateTime) (time.Time, error) {
 	// When we request Prefer: outlook.timezone="UTC", times come back in UTC.
 	// The dateTime field is in format "2006-01-02T15:04:05.0000000"
@@ -190,8 +291,16 @@ func parseGraphDateTime(gdt graphDateTime) (time.Time, error) {
 	return time.Time{}, fmt.Errorf("cannot parse datetime %q", gdt.DateTime)
 }
 
-func backoff(attempt int) time.Duration {
-	return time.Duration(math.Pow(2, float64(attempt))) * time.Second
+func (c *Client) retryPolicy() httpretry.Policy {
+	p := httpretry.Default
+	p.OnRetry = func(attempt int, wait time.Duration, resp *http.Response, err error) {
+		if err != nil {
+			c.logger.Debug("graph API retrying", "attempt", attempt, "wait", wait, "error", err)
+			return
+		}
+		c.logger.Debug("graph API retrying", "status", resp.StatusCode, "attempt", attempt, "wait", wait)
+	}
+	return p
 }
 
 func truncateStr(s string, maxLen int) string {
@@ -0,0 +1,103 @@
+package msgraph
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCreateEvent_RetrySendsSameTransactionID(t *testing.T) {
+	auth, _ := fakeLogin(t, "", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("token refreshed while the access token is valid")
+	})
+	err := SaveTokens("", &TokenData{AccessToken: "tok", ExpiresAt: time.Now().Add(time.Hour), Scope: writeScope})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var sent []map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/me/events" || r.Header.Get("Authorization") != "Bearer tok" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		sent = append(sent, body)
+		first := len(sent) == 1
+		mu.Unlock()
+		if first {
+			// The event may have been created before the failure.
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":"event-1"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	c := NewClient(auth, nil)
+	c.SetBaseURL(srv.URL)
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	id, err := c.CreateEvent(context.Background(), NewEvent{
+		Subject:       "Acme: API work",
+		Body:          "Logged with clockr.",
+		Start:         start,
+		End:           start.Add(time.Hour),
+		TransactionID: "clockr-entry-7",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "event-1" {
+		t.Errorf("id = %q, want event-1", id)
+	}
+
+	if len(sent) != 2 {
+		t.Fatalf("sent %d requests, want 2", len(sent))
+	}
+	for i, body := range sent {
+		if body["transactionId"] != "clockr-entry-7" {
+			t.Errorf("request %d transactionId = %v", i, body["transactionId"])
+		}
+	}
+	body := sent[0]
+	if body["subject"] != "Acme: API work" || body["sensitivity"] != "private" || body["showAs"] != "busy" || body["isReminderOn"] != false {
+		t.Errorf("event = %v", body)
+	}
+	if cats, _ := body["categories"].([]any); len(cats) != 1 || cats[0] != WriteBackCategory {
+		t.Errorf("categories = %v, want [%s]", body["categories"], WriteBackCategory)
+	}
+	if s, _ := body["start"].(map[string]any); s["dateTime"] != "2026-03-02T09:00:00" || s["timeZone"] != "UTC" {
+		t.Errorf("start = %v", body["start"])
+	}
+}
+
+func TestCreateEvent_Forbidden(t *testing.T) {
+	auth, _ := fakeLogin(t, "work", func(w http.ResponseWriter, r *http.Request) {})
+	err := SaveTokens("work", &TokenData{AccessToken: "tok", ExpiresAt: time.Now().Add(time.Hour), Scope: defaultScope})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"error":{"code":"ErrorAccessDenied","message":"Access is denied."}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	c := NewClient(auth, nil)
+	c.SetBaseURL(srv.URL)
+	_, err = c.CreateEvent(context.Background(), NewEvent{Subject: "x", Start: time.Now(), End: time.Now().Add(time.Hour)})
+	if err == nil {
+		t.Fatal("want an error for a read-only token")
+	}
+	if want := "clockr calendar auth --profile work"; !strings.Contains(err.Error(), want) {
+		t.Errorf("err = %v, want it to name %q", err, want)
+	}
+}
@@ -5,7 +5,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
+
+	"github.com/christopherklint97/clockr/internal/config"
+	"github.com/christopherklint97/clockr/internal/secrets"
 )
 
 // TokenData holds OAuth2 token data for Microsoft Graph API.
@@ -14,6 +19,12 @@ type TokenData struct {
 	RefreshToken string    `json:"refresh_token"`
 	ExpiresAt    time.Time `json:"expires_at"`
 	Scope        string    `json:"scope"`
+	// NeedsReauth is set when the refresh token was rejected; cleared by the
+	// next 'clockr calendar auth'.
+	NeedsReauth bool `json:"needs_reauth,omitempty"`
+	// ReauthReason is why, e.g. "AADSTS70008: the refresh token expired
+	// after inactivity", when Azure AD said.
+	ReauthReason string `json:"reauth_reason,omitempty"`
 }
 
 // IsExpired returns true if the token is expired or will expire within 5 minutes.
@@ -21,18 +32,102 @@ func (t *TokenData) IsExpired() bool {
 	return time.Now().Add(5 * time.Minute).After(t.ExpiresAt)
 }
 
-func tokenPath() (string, error) {
-	home, err := os.UserHomeDir()
+// granted returns the token's scopes, lowercased. Graph may return scopes
+// with or without the resource prefix.
+func (t *TokenData) granted() map[string]bool {
+	granted := make(map[string]bool)
+	for _, s := range strings.Fields(t.Scope) {
+		s = strings.TrimPrefix(s, "https://graph.microsoft.com/")
+		granted[strings.ToLower(s)] = true
+	}
+	return granted
+}
+
+// CanWrite reports whether the token may create calendar events.
+func (t *TokenData) CanWrite() bool {
+	return t.granted()["calendars.readwrite"]
+}
+
+// MissingScopes reports required delegated permissions that the cached token
+// was not granted. write adds Calendars.ReadWrite, needed for write-back.
+func (t *TokenData) MissingScopes(write bool) []string {
+	granted := t.granted()
+
+	var missing []string
+	if !granted["calendars.read"] && !granted["calendars.readwrite"] {
+		missing = append(missing, "Calendars.Read — calendar events cannot be fetched")
+	}
+	if write && !granted["calendars.readwrite"] {
+		missing = append(missing, "Calendars.ReadWrite — logged time cannot be written back (re-run 'clockr calendar auth')")
+	}
+	if t.RefreshToken == "" {
+		missing = append(missing, "offline_access — tokens cannot be refreshed, re-auth needed every hour")
+	}
+	return missing
+}
+
+// ProfileLabel names profile in messages.
+func ProfileLabel(profile string) string {
+	if profile == "" {
+		return "default"
+	}
+	return profile
+}
+
+// authCommand is the command that signs profile in again.
+func authCommand(profile string) string {
+	if profile == "" {
+		return "clockr calendar auth"
+	}
+	return "clockr calendar auth --profile " + profile
+}
+
+// TokenFile is msgraph_tokens.json for the default sign-in and
+// msgraph_tokens_<profile>.json for a named one.
+func TokenFile(profile string) string {
+	if profile == "" {
+		return "msgraph_tokens.json"
+	}
+	return "msgraph_tokens_" + profile + ".json"
+}
+
+func tokenPath(profile string) (string, error) {
+	dir, err := config.DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, TokenFile(profile)), nil
+}
+
+// Profiles returns the sign-ins with a token file, the default one ("")
+// first and the named ones sorted.
+func Profiles() ([]string, error) {
+	dir, err := config.DataDir()
+	if err != nil {
+		return nil, err
+	}
+	var profiles []string
+	if _, err := os.Stat(filepath.Join(dir, TokenFile(""))); err == nil {
+		profiles = append(profiles, "")
+	}
+	matches, err := filepath.Glob(filepath.Join(dir, "msgraph_tokens_*.json"))
 	if err != nil {
-		return "", fmt.Errorf("finding home directory: %w", err)
+		return nil, err
 	}
-	return filepath.Join(home, ".config", "clockr", "msgraph_tokens.json"), nil
+	sort.Strings(matches)
+	for _, m := range matches {
+		name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(m), "msgraph_tokens_"), ".json")
+		if name != "" && config.ValidGraphProfile(name) {
+			profiles = append(profiles, name)
+		}
+	}
+	return profiles, nil
 }
 
-// LoadTokens reads cached tokens from ~/.config/clockr/msgraph_tokens.json.
-// Returns nil, nil if the file does not exist.
-func LoadTokens() (*TokenData, error) {
-	path, err := tokenPath()
+// LoadTokens reads the cached tokens of profile ("" for the default
+// sign-in) from the data directory. Returns nil, nil if there are none.
+func LoadTokens(profile string) (*TokenData, error) {
+	path, err := tokenPath(profile)
 	if err != nil {
 		return nil, err
 	}
@@ -50,18 +145,32 @@ func LoadTokens() (*TokenData, error) {
 		return nil, fmt.Errorf("parsing token file: %w", err)
 	}
 
+	// The refresh token is kept in the OS keychain when one is available.
+	if tokens.RefreshToken == "" {
+		if v, err := secrets.Get(secrets.GraphProfileRefreshToken(profile)); err == nil {
+			tokens.RefreshToken = v
+		}
+	}
+
 	return &tokens, nil
 }
 
-// SaveTokens writes tokens to ~/.config/clockr/msgraph_tokens.json with 0600 permissions.
-// Uses atomic write (tmp + rename) to prevent corruption.
-func SaveTokens(tokens *TokenData) error {
-	path, err := tokenPath()
+// SaveTokens writes the tokens of profile to its token file in the data
+// directory with 0600 permissions. The long-lived refresh token goes to the
+// OS keychain instead of the file when possible. Uses atomic write (tmp +
+// rename) to prevent corruption.
+func SaveTokens(profile string, tokens *TokenData) error {
+	path, err := tokenPath(profile)
 	if err != nil {
 		return err
 	}
 
-	data, err := json.MarshalIndent(tokens, "", "  ")
+	onDisk := *tokens
+	if onDisk.RefreshToken != "" && secrets.Set(secrets.GraphProfileRefreshToken(profile), onDisk.RefreshToken) == nil {
+		onDisk.RefreshToken = ""
+	}
+
+	data, err := json.MarshalIndent(&onDisk, "", "  ")
 	if err != nil {
 		return fmt.Errorf("marshaling tokens: %w", err)
 	}
@@ -0,0 +1,130 @@
+package msgraph
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/secrets"
+)
+
+func TestTokenFile(t *testing.T) {
+	if got := TokenFile(""); got != "msgraph_tokens.json" {
+		t.Errorf("TokenFile(\"\") = %q", got)
+	}
+	if got := TokenFile("personal"); got != "msgraph_tokens_personal.json" {
+		t.Errorf("TokenFile(personal) = %q", got)
+	}
+}
+
+func TestMissingScopes(t *testing.T) {
+	tests := []struct {
+		name     string
+		scope    string
+		refresh  string
+		write    bool
+		want     []string // the first word of each message
+		canWrite bool
+	}{
+		{"read", "Calendars.Read offline_access", "r", false, nil, false},
+		{"read, write wanted", "Calendars.Read offline_access", "r", true, []string{"Calendars.ReadWrite"}, false},
+		{"readwrite covers read", "Calendars.ReadWrite offline_access", "r", true, nil, true},
+		{"resource prefix and case", "https://graph.microsoft.com/calendars.readwrite", "r", true, nil, true},
+		{"nothing granted", "User.Read", "r", true, []string{"Calendars.Read", "Calendars.ReadWrite"}, false},
+		{"no refresh token", "Calendars.Read", "", false, []string{"offline_access"}, false},
+		{"empty", "", "", true, []string{"Calendars.Read", "Calendars.ReadWrite", "offline_access"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens := &TokenData{Scope: tt.scope, RefreshToken: tt.refresh}
+			var got []string
+			for _, m := range tokens.MissingScopes(tt.write) {
+				got = append(got, strings.Fields(m)[0])
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MissingScopes(%v) = %q, want %q", tt.write, tokens.MissingScopes(tt.write), tt.want)
+			}
+			if tokens.CanWrite() != tt.canWrite {
+				t.Errorf("CanWrite() = %v, want %v", tokens.CanWrite(), tt.canWrite)
+			}
+		})
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("CLOCKR_HOME", dir)
+
+	profiles, err := Profiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(profiles) != 0 {
+		t.Errorf("Profiles() in an empty dir = %q", profiles)
+	}
+
+	for _, name := range []string{
+		"msgraph_tokens.json",
+		"msgraph_tokens_work.json",
+		"msgraph_tokens_home-2.json",
+		"msgraph_tokens_.json",         // no name
+		"msgraph_tokens_Bad Name.json", // not a valid profile
+		"msgraph_tokens_work.json.tmp",
+		"config.toml",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("{}"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	profiles, err = Profiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"", "home-2", "work"}; !reflect.DeepEqual(profiles, want) {
+		t.Errorf("Profiles() = %q, want %q", profiles, want)
+	}
+}
+
+func TestSaveTokens_KeychainPerProfile(t *testing.T) {
+	t.Setenv("CLOCKR_HOME", t.TempDir())
+	kc := secrets.NewMemory()
+	prev := secrets.Default()
+	secrets.SetDefault(kc)
+	t.Cleanup(func() { secrets.SetDefault(prev) })
+
+	expires := time.Now().Add(time.Hour).Truncate(time.Second)
+	for _, profile := range []string{"", "work"} {
+		err := SaveTokens(profile, &TokenData{AccessToken: "access-" + profile, RefreshToken: "refresh-" + profile, ExpiresAt: expires})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tt := range []struct{ profile, item string }{
+		{"", "msgraph_refresh_token"},
+		{"work", "msgraph_refresh_token_work"},
+	} {
+		if v, err := kc.Get(tt.item); err != nil || v != "refresh-"+tt.profile {
+			t.Errorf("keychain %s = %q, %v; want refresh-%s", tt.item, v, err, tt.profile)
+		}
+		// The refresh token stays out of the file.
+		data, err := os.ReadFile(filepath.Join(os.Getenv("CLOCKR_HOME"), TokenFile(tt.profile)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := `"refresh_token": ""`; !strings.Contains(string(data), want) {
+			t.Errorf("%s lacks %s:\n%s", TokenFile(tt.profile), want, data)
+		}
+
+		tokens, err := LoadTokens(tt.profile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tokens.AccessToken != "access-"+tt.profile || tokens.RefreshToken != "refresh-"+tt.profile {
+			t.Errorf("LoadTokens(%q) = %+v", tt.profile, tokens)
+		}
+	}
+}
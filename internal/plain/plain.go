@@ -0,0 +1,493 @@
+// Package plain is the line-based alternative to the TUI behind 'clockr log
+// --plain': one question per line and numbered lists instead of a
+// full-screen view, for screen readers, dumb terminals and SSH sessions
+// where the alternate screen breaks.
+package plain
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/ai"
+	"github.com/christopherklint97/clockr/internal/clockify"
+	"github.com/christopherklint97/clockr/internal/format"
+)
+
+// maxListed is how many projects a search lists before asking for more text.
+const maxListed = 20
+
+// errQuit ends the session on "q" or end of input.
+var errQuit = errors.New("quit")
+
+// Session asks for one window's entries. Set the exported fields before Run.
+type Session struct {
+	Start, End time.Time
+	Provider   ai.Provider // nil: the entries are entered by hand
+	Projects   []clockify.Project
+	Context    []ai.ContextItem
+	Formatter  *format.Formatter
+	Rounding   clockify.Rounding
+	AITimeout  time.Duration
+	// Description is offered as the default description, e.g. for --repeat.
+	Description string
+
+	in  *bufio.Scanner
+	out io.Writer
+}
+
+// Result is what the user chose. Allocations run back to back from Start.
+type Result struct {
+	Start       time.Time // End minus the minutes confirmed at the start
+	Description string
+	Allocations []ai.Allocation
+	Suggestion  *ai.Suggestion // the AI's answer, nil when entered by hand
+	// Clarifications are the AI's questions answered before Suggestion.
+	Clarifications []ai.Exchange
+	Skipped        bool
+	SkipReason     string
+}
+
+// New returns a session reading answers from in and writing to out.
+func New(in io.Reader, out io.Writer) *Session {
+	return &Session{in: bufio.NewScanner(in), out: out}
+}
+
+// Run asks the questions. It returns nil when the user quits or the input
+// ends before anything was chosen.
+func (s *Session) Run(ctx context.Context) (*Result, error) {
+	res, err := s.run(ctx)
+	if errors.Is(err, errQuit) {
+		fmt.Fprintln(s.out, "Nothing logged.")
+		return nil, nil
+	}
+	return res, err
+}
+
+func (s *Session) run(ctx context.Context) (*Result, error) {
+	total := int(s.End.Sub(s.Start).Minutes())
+	fmt.Fprintf(s.out, "Logging %s–%s (%d min). Answer q at any question to quit.\n", s.Start.Format("15:04"), s.End.Format("15:04"), total)
+	minutes, err := s.askMinutes("Minutes to log", total)
+	if err != nil {
+		return nil, err
+	}
+	res := &Result{Start: s.End.Add(-time.Duration(minutes) * time.Minute)}
+
+	if len(s.Context) > 0 {
+		fmt.Fprintln(s.out, "Context sent to the AI:")
+		for _, item := range s.Context {
+			fmt.Fprintf(s.out, "  - %s\n", item)
+		}
+	}
+
+	if s.Provider == nil {
+		alloc, err := s.editAllocation(ai.Allocation{Minutes: minutes})
+		if err != nil {
+			return nil, err
+		}
+		res.Description = alloc.Description
+		res.Allocations = []ai.Allocation{alloc}
+		return s.review(ctx, res, minutes)
+	}
+
+	res.Description, err = s.askDescription()
+	if err != nil {
+		return nil, err
+	}
+	if res.Description == "" {
+		return s.skip(res)
+	}
+	return s.suggest(ctx, res, minutes)
+}
+
+// suggest asks the AI, answering its clarification questions, then hands
+// the allocations to review.
+func (s *Session) suggest(ctx context.Context, res *Result, minutes int) (*Result, error) {
+	var exchanges []ai.Exchange
+	for {
+		fmt.Fprintln(s.out, "Asking the AI…")
+		suggestion, err := s.match(ctx, ai.WithClarifications(res.Description, exchanges), minutes)
+		if err != nil {
+			fmt.Fprintf(s.out, "The AI request failed: %v\n", err)
+			choice, err := s.choose("r: try again, m: enter the entry yourself, s: skip, q: quit", "r", "rmsq")
+			if err != nil {
+				return nil, err
+			}
+			switch choice {
+			case "m":
+				alloc, err := s.editAllocation(ai.Allocation{Minutes: minutes, Description: res.Description})
+				if err != nil {
+					return nil, err
+				}
+				res.Allocations = []ai.Allocation{alloc}
+				return s.review(ctx, res, minutes)
+			case "s":
+				return s.skip(res)
+			}
+			continue
+		}
+
+		if len(suggestion.Allocations) == 0 && suggestion.Clarification != "" {
+			fmt.Fprintf(s.out, "The AI asks: %s\n", suggestion.Clarification)
+			answer, err := s.ask("Answer (empty to describe the work again)", "")
+			if err != nil {
+				return nil, err
+			}
+			if answer == "" {
+				if res.Description, err = s.askDescription(); err != nil {
+					return nil, err
+				}
+				if res.Description == "" {
+					return s.skip(res)
+				}
+				exchanges = nil
+				continue
+			}
+			exchanges = append(exchanges, ai.Exchange{Question: suggestion.Clarification, Answer: answer})
+			continue
+		}
+
+		// Edits change res.Allocations only; the suggestion is stored as the
+		// AI gave it.
+		res.Suggestion = suggestion
+		res.Clarifications = exchanges
+		res.Allocations = slices.Clone(suggestion.Allocations)
+		return s.review(ctx, res, minutes)
+	}
+}
+
+func (s *Session) match(ctx context.Context, description string, minutes int) (*ai.Suggestion, error) {
+	if s.AITimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.AITimeout)
+		defer cancel()
+	}
+	suggestion, err := s.Provider.MatchProjects(ctx, description, s.Projects, time.Duration(minutes)*time.Minute, s.Context, nil)
+	if err != nil {
+		return nil, err
+	}
+	allocMinutes := make([]int, len(suggestion.Allocations))
+	for i, a := range suggestion.Allocations {
+		suggestion.Allocations[i].Description = s.Formatter.Description(a.ProjectID, a.ProjectName, a.ClientName, a.Description)
+		allocMinutes[i] = a.Minutes
+	}
+	for i, m := range s.Rounding.ApplyCumulative(allocMinutes) {
+		suggestion.Allocations[i].Minutes = m
+	}
+	return suggestion, nil
+}
+
+// review lists the allocations until they are accepted, edited, retried or
+// skipped.
+func (s *Session) review(ctx context.Context, res *Result, minutes int) (*Result, error) {
+	for {
+		s.printAllocations(res.Allocations, minutes)
+		options, keys := "a: accept, e: edit, s: skip, q: quit", "aesq"
+		if s.Provider != nil {
+			options, keys = "a: accept, e: edit, r: describe the work again, s: skip, q: quit", "aersq"
+		}
+		choice, err := s.choose(options, "a", keys)
+		if err != nil {
+			return nil, err
+		}
+		switch choice {
+		case "a":
+			if len(res.Allocations) == 0 {
+				fmt.Fprintln(s.out, "There is nothing to log. Add an entry with e or skip with s.")
+				continue
+			}
+			return res, nil
+		case "e":
+			if res.Allocations, err = s.editList(res.Allocations, minutes); err != nil {
+				return nil, err
+			}
+		case "r":
+			if res.Description, err = s.askDescription(); err != nil {
+				return nil, err
+			}
+			if res.Description == "" {
+				return s.skip(res)
+			}
+			return s.suggest(ctx, res, minutes)
+		case "s":
+			return s.skip(res)
+		}
+	}
+}
+
+func (s *Session) printAllocations(allocs []ai.Allocation, minutes int) {
+	sum := 0
+	fmt.Fprintln(s.out, "Entries:")
+	for i, a := range allocs {
+		sum += a.Minutes
+		line := fmt.Sprintf("  %d. %s — %s, %d min", i+1, projectLabel(a.ProjectName, a.ClientName), a.Description, a.Minutes)
+		if a.Confidence > 0 && a.Confidence < 1 {
+			line += fmt.Sprintf(", %.0f%% confident", a.Confidence*100)
+		}
+		fmt.Fprintln(s.out, line)
+	}
+	if sum != minutes {
+		fmt.Fprintf(s.out, "Total %d of %d min.\n", sum, minutes)
+	}
+}
+
+// editList changes, adds or deletes allocations until the user is done.
+func (s *Session) editList(allocs []ai.Allocation, minutes int) ([]ai.Allocation, error) {
+	for {
+		answer, err := s.ask(fmt.Sprintf("Entry to change (1-%d), + to add, -N to delete, Enter when done", len(allocs)), "")
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case answer == "":
+			return allocs, nil
+		case answer == "+":
+			used := 0
+			for _, a := range allocs {
+				used += a.Minutes
+			}
+			alloc, err := s.editAllocation(ai.Allocation{Minutes: max(minutes-used, 0)})
+			if err != nil {
+				return nil, err
+			}
+			allocs = append(allocs, alloc)
+		case strings.HasPrefix(answer, "-"):
+			n, err := strconv.Atoi(answer[1:])
+			if err != nil || n < 1 || n > len(allocs) {
+				fmt.Fprintf(s.out, "No entry %s.\n", answer[1:])
+				continue
+			}
+			allocs = append(allocs[:n-1], allocs[n:]...)
+		default:
+			n, err := strconv.Atoi(answer)
+			if err != nil || n < 1 || n > len(allocs) {
+				fmt.Fprintf(s.out, "No entry %s.\n", answer)
+				continue
+			}
+			if allocs[n-1], err = s.editAllocation(allocs[n-1]); err != nil {
+				return nil, err
+			}
+		}
+		s.printAllocations(allocs, minutes)
+	}
+}
+
+// editAllocation asks for the project, minutes and description, keeping
+// a's values on Enter.
+func (s *Session) editAllocation(a ai.Allocation) (ai.Allocation, error) {
+	p, err := s.pickProject(a.ProjectName)
+	if err != nil {
+		return a, err
+	}
+	if p.ID != "" {
+		a.ProjectID, a.ProjectName, a.ClientName = p.ID, p.Name, p.ClientName
+	}
+	if a.Minutes, err = s.askMinutes("Minutes", a.Minutes); err != nil {
+		return a, err
+	}
+	a.Minutes = s.Rounding.Apply(a.Minutes)
+	for {
+		desc, err := s.ask("Description", a.Description)
+		if err != nil {
+			return a, err
+		}
+		if desc != "" {
+			a.Description = s.Formatter.Description(a.ProjectID, a.ProjectName, a.ClientName, desc)
+			break
+		}
+		fmt.Fprintln(s.out, "A description is needed.")
+	}
+	a.Confidence = 1
+	return a, nil
+}
+
+// pickProject finds a project by number or search text. With a current
+// project, Enter keeps it and returns the zero Project.
+func (s *Session) pickProject(current string) (clockify.Project, error) {
+	listed := s.activeProjects()
+	if len(listed) <= maxListed {
+		s.printProjects(listed)
+	}
+	for {
+		answer, err := s.ask("Project (number, or text to search)", current)
+		if err != nil {
+			return clockify.Project{}, err
+		}
+		if answer == "" {
+			fmt.Fprintln(s.out, "A project is needed.")
+			continue
+		}
+		if answer == current {
+			return clockify.Project{}, nil
+		}
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(listed) && len(listed) <= maxListed {
+			return listed[n-1], nil
+		}
+		matches := searchProjects(s.activeProjects(), answer)
+		switch {
+		case len(matches) == 1:
+			fmt.Fprintf(s.out, "Project: %s\n", projectLabel(matches[0].Name, matches[0].ClientName))
+			return matches[0], nil
+		case len(matches) == 0:
+			fmt.Fprintf(s.out, "No project matches %q.\n", answer)
+		case len(matches) > maxListed:
+			fmt.Fprintf(s.out, "%d projects match %q. Type more of the name.\n", len(matches), answer)
+		default:
+			listed = matches
+			s.printProjects(listed)
+		}
+	}
+}
+
+func (s *Session) activeProjects() []clockify.Project {
+	var out []clockify.Project
+	for _, p := range s.Projects {
+		if !p.Archived {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func (s *Session) printProjects(projects []clockify.Project) {
+	for i, p := range projects {
+		fmt.Fprintf(s.out, "  %d. %s\n", i+1, projectLabel(p.Name, p.ClientName))
+	}
+}
+
+// searchProjects returns the projects whose name or client contains text,
+// ignoring case.
+func searchProjects(projects []clockify.Project, text string) []clockify.Project {
+	text = strings.ToLower(text)
+	var out []clockify.Project
+	for _, p := range projects {
+		if strings.Contains(strings.ToLower(p.Name), text) || strings.Contains(strings.ToLower(p.ClientName), text) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func projectLabel(name, client string) string {
+	if name == "" {
+		return "(no project)"
+	}
+	if client == "" {
+		return name
+	}
+	return name + " (" + client + ")"
+}
+
+// skip confirms skipping the window and asks for an optional reason.
+func (s *Session) skip(res *Result) (*Result, error) {
+	choice, err := s.choose("Skip this window? y: skip it, n: quit without recording anything", "y", "yn")
+	if err != nil {
+		return nil, err
+	}
+	if choice == "n" {
+		return nil, errQuit
+	}
+	reason, err := s.ask("Reason (optional)", "")
+	if err != nil {
+		return nil, err
+	}
+	res.Skipped, res.SkipReason, res.Allocations = true, reason, nil
+	return res, nil
+}
+
+// askDescription reads the description as lines up to an empty one. With a
+// default description, an empty first line uses it.
+func (s *Session) askDescription() (string, error) {
+	if s.Description != "" {
+		fmt.Fprintf(s.out, "What did you work on? End with an empty line. An empty line on its own uses: %s\n", s.Description)
+	} else {
+		fmt.Fprintln(s.out, "What did you work on? End with an empty line. An empty description skips this window.")
+	}
+	var lines []string
+	for {
+		line, err := s.readLine("> ")
+		if err != nil {
+			if errors.Is(err, io.EOF) && len(lines) > 0 {
+				break
+			}
+			return "", err
+		}
+		if line == "q" && len(lines) == 0 {
+			return "", errQuit
+		}
+		if line == "" {
+			break
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		return s.Description, nil
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func (s *Session) askMinutes(prompt string, def int) (int, error) {
+	for {
+		answer, err := s.ask(prompt, strconv.Itoa(def))
+		if err != nil {
+			return 0, err
+		}
+		n, err := strconv.Atoi(answer)
+		if err == nil && n > 0 {
+			return n, nil
+		}
+		fmt.Fprintln(s.out, "Enter a number of minutes above zero.")
+	}
+}
+
+// choose asks for one of the single-letter keys, def on Enter.
+func (s *Session) choose(options, def, keys string) (string, error) {
+	for {
+		answer, err := s.ask(options, def)
+		if err != nil {
+			return "", err
+		}
+		answer = strings.ToLower(answer)
+		if len(answer) == 1 && strings.Contains(keys, answer) {
+			return answer, nil
+		}
+		fmt.Fprintf(s.out, "Answer one of: %s.\n", strings.Join(strings.Split(keys, ""), ", "))
+	}
+}
+
+// ask reads one answer, def on Enter. "q" quits.
+func (s *Session) ask(prompt, def string) (string, error) {
+	if def != "" {
+		prompt += " [" + def + "]"
+	}
+	answer, err := s.readLine(prompt + ": ")
+	if err != nil {
+		return "", err
+	}
+	if answer == "q" {
+		return "", errQuit
+	}
+	if answer == "" {
+		return def, nil
+	}
+	return answer, nil
+}
+
+// readLine prints prompt and reads a trimmed line. End of input quits.
+func (s *Session) readLine(prompt string) (string, error) {
+	fmt.Fprint(s.out, prompt)
+	if !s.in.Scan() {
+		fmt.Fprintln(s.out)
+		if err := s.in.Err(); err != nil {
+			return "", err
+		}
+		return "", fmt.Errorf("%w: %w", errQuit, io.EOF)
+	}
+	return strings.TrimSpace(s.in.Text()), nil
+}
@@ -0,0 +1,127 @@
+package plain
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/ai"
+	"github.com/christopherklint97/clockr/internal/clockify"
+)
+
+// scriptedProvider answers with its suggestions in turn and records the
+// descriptions it was asked about.
+type scriptedProvider struct {
+	answers []*ai.Suggestion
+	asked   []string
+}
+
+func (p *scriptedProvider) MatchProjects(_ context.Context, description string, _ []clockify.Project, _ time.Duration, _ []ai.ContextItem, _ []ai.Segment) (*ai.Suggestion, error) {
+	p.asked = append(p.asked, description)
+	s := p.answers[0]
+	p.answers = p.answers[1:]
+	return s, nil
+}
+
+func (p *scriptedProvider) MatchProjectsBatch(context.Context, string, []clockify.Project, []ai.DaySlot) (*ai.BatchSuggestion, error) {
+	return nil, nil
+}
+
+var testProjects = []clockify.Project{
+	{ID: "p1", Name: "Alpha", ClientName: "Acme"},
+	{ID: "p2", Name: "Beta"},
+	{ID: "p3", Name: "Old", Archived: true},
+}
+
+func newTestSession(input string) (*Session, *strings.Builder) {
+	var out strings.Builder
+	s := New(strings.NewReader(input), &out)
+	s.End = time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	s.Start = s.End.Add(-time.Hour)
+	s.Projects = testProjects
+	return s, &out
+}
+
+func TestRun_ClarifyEditAccept(t *testing.T) {
+	provider := &scriptedProvider{answers: []*ai.Suggestion{
+		{Clarification: "Which client?"},
+		{Allocations: []ai.Allocation{
+			{ProjectID: "p1", ProjectName: "Alpha", ClientName: "Acme", Minutes: 40, Description: "Review", Confidence: 0.8},
+			{ProjectID: "p2", ProjectName: "Beta", Minutes: 20, Description: "Build", Confidence: 0.6},
+		}},
+	}}
+	// Keep 60 min, describe, answer the question, change entry 2 to Alpha
+	// with 15 min, delete nothing, accept.
+	s, out := newTestSession("\nreviewed and built\n\nAcme\ne\n2\n1\n15\n\n\na\n")
+	s.Provider = provider
+
+	res, err := s.Run(context.Background())
+	if err != nil || res == nil {
+		t.Fatalf("Run = %+v, %v\n%s", res, err, out)
+	}
+	if len(provider.asked) != 2 || !strings.Contains(provider.asked[1], "Q: Which client?\nA: Acme") {
+		t.Errorf("asked = %q, want the answer sent the second time", provider.asked)
+	}
+	if res.Description != "reviewed and built" || res.Suggestion == nil {
+		t.Errorf("result = %+v", res)
+	}
+	if got := res.Allocations[1]; got.ProjectID != "p1" || got.Minutes != 15 || got.Description != "Build" {
+		t.Errorf("edited entry = %+v", got)
+	}
+	if got := res.Suggestion.Allocations[1]; got.ProjectID != "p2" || got.Minutes != 20 {
+		t.Errorf("editing changed the AI's suggestion: %+v", got)
+	}
+	if len(res.Clarifications) != 1 || res.Clarifications[0].Answer != "Acme" {
+		t.Errorf("clarifications = %+v", res.Clarifications)
+	}
+	if !strings.Contains(out.String(), "1. Alpha (Acme) — Review, 40 min, 80% confident") {
+		t.Errorf("entries not listed:\n%s", out)
+	}
+	if !strings.Contains(out.String(), "Total 55 of 60 min.") {
+		t.Errorf("changed total not reported:\n%s", out)
+	}
+}
+
+func TestRun_ManualSearch(t *testing.T) {
+	// 30 min, search "alp", then minutes and description, accept.
+	s, out := newTestSession("30\nalp\n\nWrote docs\na\n")
+
+	res, err := s.Run(context.Background())
+	if err != nil || res == nil {
+		t.Fatalf("Run = %+v, %v\n%s", res, err, out)
+	}
+	if !res.Start.Equal(s.End.Add(-30 * time.Minute)) {
+		t.Errorf("start = %v, want 30 min before the end", res.Start)
+	}
+	want := ai.Allocation{ProjectID: "p1", ProjectName: "Alpha", ClientName: "Acme", Minutes: 30, Description: "Wrote docs", Confidence: 1}
+	if len(res.Allocations) != 1 || res.Allocations[0] != want {
+		t.Errorf("allocations = %+v, want %+v", res.Allocations, want)
+	}
+	if strings.Contains(out.String(), "Old") {
+		t.Errorf("archived project listed:\n%s", out)
+	}
+}
+
+func TestRun_SkipAndQuit(t *testing.T) {
+	s, _ := newTestSession("\n\ny\nlunch\n")
+	s.Provider = &scriptedProvider{}
+	res, err := s.Run(context.Background())
+	if err != nil || res == nil || !res.Skipped || res.SkipReason != "lunch" {
+		t.Errorf("empty description: Run = %+v, %v; want a skip", res, err)
+	}
+
+	s, out := newTestSession("\nq\n")
+	s.Provider = &scriptedProvider{}
+	if res, err := s.Run(context.Background()); res != nil || err != nil {
+		t.Errorf("q: Run = %+v, %v; want nothing", res, err)
+	}
+	if !strings.Contains(out.String(), "Nothing logged.") {
+		t.Errorf("quit not reported:\n%s", out)
+	}
+
+	s, _ = newTestSession("")
+	if res, err := s.Run(context.Background()); res != nil || err != nil {
+		t.Errorf("end of input: Run = %+v, %v; want nothing", res, err)
+	}
+}
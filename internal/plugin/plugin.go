@@ -0,0 +1,217 @@
+// Package plugin runs external executables that add context for the AI or
+// receive logged entries, so private integrations work with stock clockr.
+//
+// A plugin is any executable in the plugins directory next to config.toml.
+// clockr runs it once per call with a single JSON Request on stdin and reads
+// a single JSON Response from stdout. Anything written to stderr is shown
+// when the call fails. On "describe" the plugin reports its name and which
+// of "context" and "submit" it supports.
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/config"
+)
+
+// Actions a plugin is called with.
+const (
+	ActionDescribe = "describe"
+	ActionContext  = "context"
+	ActionSubmit   = "submit"
+)
+
+const (
+	describeTimeout = 5 * time.Second
+	contextTimeout  = 10 * time.Second
+	submitTimeout   = 30 * time.Second
+)
+
+// Request is written to the plugin's stdin.
+type Request struct {
+	Action  string     `json:"action"`
+	Start   *time.Time `json:"start,omitempty"` // context: window start
+	End     *time.Time `json:"end,omitempty"`   // context: window end
+	Entries []Entry    `json:"entries,omitempty"`
+}
+
+// Entry is a time entry logged to Clockify, sent with "submit".
+type Entry struct {
+	ClockifyID  string    `json:"clockify_id"`
+	ProjectID   string    `json:"project_id"`
+	ProjectName string    `json:"project_name"`
+	ClientName  string    `json:"client_name,omitempty"`
+	Description string    `json:"description"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	Minutes     int       `json:"minutes"`
+}
+
+// Response is read from the plugin's stdout.
+type Response struct {
+	Name         string   `json:"name,omitempty"`         // describe
+	Capabilities []string `json:"capabilities,omitempty"` // describe: "context", "submit"
+	Items        []string `json:"items,omitempty"`        // context: one line each
+	Results      []Result `json:"results,omitempty"`      // submit: one per entry, in order
+	Error        string   `json:"error,omitempty"`        // set when the whole call failed
+}
+
+// Result reports one submitted entry.
+type Result struct {
+	ID    string `json:"id,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// Plugin is a discovered plugin executable, or a configured command line.
+type Plugin struct {
+	Name         string
+	Path         string
+	Command      string // run through the shell instead of Path when set
+	Capabilities []string
+}
+
+// NewCommand returns a context plugin that runs command through the
+// platform shell, such as the [context.custom] command.
+func NewCommand(name, command string) Plugin {
+	return Plugin{Name: name, Command: command, Capabilities: []string{ActionContext}}
+}
+
+// Can reports whether the plugin supports the action.
+func (p Plugin) Can(action string) bool {
+	return slices.Contains(p.Capabilities, action)
+}
+
+// Dir returns the plugins directory.
+func Dir() (string, error) {
+	dir, err := config.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "plugins"), nil
+}
+
+// Discover describes every executable in the plugins directory. A missing
+// directory means no plugins. Plugins that fail to describe themselves are
+// reported in errs and left out.
+func Discover(ctx context.Context) (plugins []Plugin, errs []error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, []error{err}
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, []error{fmt.Errorf("reading plugins directory: %w", err)}
+	}
+
+	for _, e := range entries {
+		path := filepath.Join(dir, e.Name())
+		if !isExecutable(path) {
+			continue
+		}
+		p := Plugin{Name: strings.TrimSuffix(e.Name(), filepath.Ext(e.Name())), Path: path}
+		resp, err := p.call(ctx, Request{Action: ActionDescribe}, describeTimeout)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("plugin %s: %w", p.Name, err))
+			continue
+		}
+		if resp.Name != "" {
+			p.Name = resp.Name
+		}
+		p.Capabilities = resp.Capabilities
+		plugins = append(plugins, p)
+	}
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].Name < plugins[j].Name })
+	return plugins, errs
+}
+
+// Context asks the plugin for context lines about the window.
+func (p Plugin) Context(ctx context.Context, start, end time.Time) ([]string, error) {
+	resp, err := p.call(ctx, Request{Action: ActionContext, Start: &start, End: &end}, contextTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s: %w", p.Name, err)
+	}
+	return resp.Items, nil
+}
+
+// Submit sends logged entries to the plugin and returns one result per entry.
+func (p Plugin) Submit(ctx context.Context, entries []Entry) ([]Result, error) {
+	resp, err := p.call(ctx, Request{Action: ActionSubmit, Entries: entries}, submitTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s: %w", p.Name, err)
+	}
+	if len(resp.Results) != len(entries) {
+		return resp.Results, fmt.Errorf("plugin %s: returned %d results for %d entries", p.Name, len(resp.Results), len(entries))
+	}
+	return resp.Results, nil
+}
+
+func (p Plugin) call(ctx context.Context, req Request, timeout time.Duration) (*Response, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	in, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("encoding request: %w", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.Path)
+	if p.Command != "" {
+		if runtime.GOOS == "windows" {
+			cmd = exec.CommandContext(ctx, "cmd", "/c", p.Command)
+		} else {
+			cmd = exec.CommandContext(ctx, "sh", "-c", p.Command)
+		}
+	}
+	cmd.Stdin = bytes.NewReader(in)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("%s timed out after %s", req.Action, timeout)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s failed: %w: %s", req.Action, err, msg)
+		}
+		return nil, fmt.Errorf("%s failed: %w", req.Action, err)
+	}
+
+	var resp Response
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		return nil, fmt.Errorf("parsing %s response: %w", req.Action, err)
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("%s: %s", req.Action, resp.Error)
+	}
+	return &resp, nil
+}
+
+// isExecutable reports whether path is a regular file the OS will run.
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".exe", ".bat", ".cmd":
+			return true
+		}
+		return false
+	}
+	return info.Mode()&0o111 != 0
+}
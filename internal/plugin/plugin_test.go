@@ -0,0 +1,86 @@
+package plugin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+const trackerScript = `#!/bin/sh
+req=$(cat)
+case "$req" in
+  *'"describe"'*) echo '{"name":"tracker","capabilities":["context","submit"]}' ;;
+  *'"context"'*) echo '{"items":["TICKET-1 reviewed"]}' ;;
+  *'"submit"'*) echo '{"results":[{"id":"t-1"}]}' ;;
+esac
+`
+
+func writePlugin(t *testing.T, dir, name, script string, mode os.FileMode) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(script), mode); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDiscoverAndCall(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script plugin")
+	}
+	home := t.TempDir()
+	t.Setenv("CLOCKR_HOME", home)
+	dir := filepath.Join(home, "plugins")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writePlugin(t, dir, "tracker.sh", trackerScript, 0o755)
+	writePlugin(t, dir, "README", "not a plugin", 0o644)
+	writePlugin(t, dir, "broken", "#!/bin/sh\necho boom >&2\nexit 1\n", 0o755)
+
+	ctx := context.Background()
+	plugins, errs := Discover(ctx)
+	if len(plugins) != 1 || plugins[0].Name != "tracker" || !plugins[0].Can(ActionSubmit) {
+		t.Fatalf("unexpected plugins %+v", plugins)
+	}
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "boom") {
+		t.Errorf("expected the broken plugin's stderr in the error, got %v", errs)
+	}
+
+	p := plugins[0]
+	items, err := p.Context(ctx, time.Now().Add(-time.Hour), time.Now())
+	if err != nil || !slices.Equal(items, []string{"TICKET-1 reviewed"}) {
+		t.Errorf("Context = %v, %v", items, err)
+	}
+	results, err := p.Submit(ctx, []Entry{{ProjectName: "Alpha", Description: "Review", Minutes: 60}})
+	if err != nil || len(results) != 1 || results[0].ID != "t-1" {
+		t.Errorf("Submit = %+v, %v", results, err)
+	}
+}
+
+func TestDiscover_NoDirectory(t *testing.T) {
+	t.Setenv("CLOCKR_HOME", t.TempDir())
+	plugins, errs := Discover(context.Background())
+	if len(plugins) != 0 || len(errs) != 0 {
+		t.Errorf("missing directory should mean no plugins, got %v %v", plugins, errs)
+	}
+}
+
+func TestNewCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell command")
+	}
+	p := NewCommand("custom", `grep -q '"context"' && echo '{"items":["visited docs.acme.com"]}'`)
+	items, err := p.Context(context.Background(), time.Now().Add(-time.Hour), time.Now())
+	if err != nil || !slices.Equal(items, []string{"visited docs.acme.com"}) {
+		t.Errorf("Context = %v, %v", items, err)
+	}
+
+	_, err = NewCommand("custom", "echo nope >&2; exit 3").Context(context.Background(), time.Now(), time.Now())
+	if err == nil || !strings.Contains(err.Error(), "plugin custom") || !strings.Contains(err.Error(), "nope") {
+		t.Errorf("expected the failing command's stderr, got %v", err)
+	}
+}
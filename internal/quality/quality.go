@@ -0,0 +1,176 @@
+// Package quality scores how well each day was tracked: how much of the
+// work hours is logged, how many prompts were answered, and how much of the
+// AI's suggestions had to be changed.
+package quality
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/ai"
+	"github.com/christopherklint97/clockr/internal/audit"
+	"github.com/christopherklint97/clockr/internal/config"
+	"github.com/christopherklint97/clockr/internal/store"
+)
+
+// GoodDay is the score a day needs to extend a streak.
+const GoodDay = 80
+
+// Day is one day's score and the parts it is made of.
+type Day struct {
+	Date     time.Time
+	Work     time.Duration // work hours so far; 0 on a day off
+	Covered  time.Duration // work hours logged or skipped
+	Prompts  int
+	Answered int
+	Edit     float64 // mean share of accepted suggestions that was changed
+	Edits    int     // accepted AI suggestions
+	Score    int     // 0–100; -1 when there was nothing to score
+}
+
+// Coverage is the share of work hours logged or skipped.
+func (d Day) Coverage() float64 {
+	if d.Work <= 0 {
+		return 0
+	}
+	return min(float64(d.Covered)/float64(d.Work), 1)
+}
+
+// score weighs coverage most, then answering prompts, then accepting
+// suggestions unchanged. Parts with no data are left out.
+func (d Day) score() int {
+	type part struct{ value, weight float64 }
+	var parts []part
+	if d.Work > 0 {
+		parts = append(parts, part{d.Coverage(), 0.5})
+	}
+	if d.Prompts > 0 {
+		parts = append(parts, part{float64(d.Answered) / float64(d.Prompts), 0.3})
+	}
+	if d.Edits > 0 {
+		parts = append(parts, part{1 - d.Edit, 0.2})
+	}
+	if len(parts) == 0 {
+		return -1
+	}
+	var sum, weights float64
+	for _, p := range parts {
+		sum += p.value * p.weight
+		weights += p.weight
+	}
+	return int(sum/weights*100 + 0.5)
+}
+
+// Days scores every day in [from, to), up to now.
+func Days(db *store.DB, sched config.ScheduleConfig, from, to, now time.Time) ([]Day, error) {
+	var days []Day
+	for d := from; d.Before(to) && d.Before(now); d = d.AddDate(0, 0, 1) {
+		next := d.AddDate(0, 0, 1)
+		day := Day{Date: d}
+
+		entries, err := db.GetEntriesBetween(d, next)
+		if err != nil {
+			return nil, fmt.Errorf("fetching entries: %w", err)
+		}
+		skips, err := db.GetSkipsBetween(d, next)
+		if err != nil {
+			return nil, fmt.Errorf("fetching skips: %w", err)
+		}
+		var covered []audit.Interval
+		for _, e := range entries {
+			covered = append(covered, audit.Interval{Start: e.StartTime, End: e.EndTime})
+		}
+		for _, s := range skips {
+			covered = append(covered, audit.Interval{Start: s.StartTime, End: s.EndTime})
+		}
+		for _, w := range audit.WorkHours(sched, d, next, now) {
+			day.Work += w.End.Sub(w.Start)
+			day.Covered += w.End.Sub(w.Start) - audit.Free(w, covered)
+		}
+
+		if day.Prompts, day.Answered, err = db.PromptCounts(d, next); err != nil {
+			return nil, err
+		}
+		if day.Edit, day.Edits, err = db.AverageEdit(d, next); err != nil {
+			return nil, err
+		}
+		day.Score = day.score()
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+// Streak counts the good days in a row at the end of days. Days without a
+// score (days off) neither break nor extend it, and a last day that is not
+// good yet is left out, since it may still be in progress.
+func Streak(days []Day) int {
+	streak := 0
+	for i := len(days) - 1; i >= 0; i-- {
+		switch s := days[i].Score; {
+		case s < 0:
+			continue
+		case s >= GoodDay:
+			streak++
+		case i == len(days)-1:
+			continue
+		default:
+			return streak
+		}
+	}
+	return streak
+}
+
+// BestStreak is the longest run of good days in days.
+func BestStreak(days []Day) int {
+	best, run := 0, 0
+	for _, d := range days {
+		switch {
+		case d.Score < 0:
+		case d.Score >= GoodDay:
+			run++
+			best = max(best, run)
+		default:
+			run = 0
+		}
+	}
+	return best
+}
+
+// EditDistance is how much of the suggested allocations was changed before
+// they were accepted: 0 when accepted as is, 1 when completely rewritten.
+func EditDistance(suggested, accepted []ai.Allocation) float64 {
+	a, b := []rune(render(suggested)), []rune(render(accepted))
+	if len(a) == 0 && len(b) == 0 {
+		return 0
+	}
+	return float64(levenshtein(a, b)) / float64(max(len(a), len(b)))
+}
+
+func render(allocs []ai.Allocation) string {
+	var b strings.Builder
+	for _, a := range allocs {
+		fmt.Fprintf(&b, "%s %d %s\n", a.ProjectID, a.Minutes, a.Description)
+	}
+	return b.String()
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,59 @@
+package quality
+
+import (
+	"testing"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/ai"
+)
+
+func TestDayScore(t *testing.T) {
+	d := Day{Work: 8 * time.Hour, Covered: 6 * time.Hour, Prompts: 8, Answered: 6, Edit: 0.5, Edits: 4}
+	// 0.75*0.5 + 0.75*0.3 + 0.5*0.2 = 0.7
+	if got := d.score(); got != 70 {
+		t.Errorf("score = %d, want 70", got)
+	}
+	if got := (Day{Work: 8 * time.Hour, Covered: 8 * time.Hour}).score(); got != 100 {
+		t.Errorf("coverage only = %d, want 100", got)
+	}
+	if got := (Day{}).score(); got != -1 {
+		t.Errorf("day off = %d, want -1", got)
+	}
+}
+
+func TestStreak(t *testing.T) {
+	days := func(scores ...int) []Day {
+		var out []Day
+		for _, s := range scores {
+			out = append(out, Day{Score: s})
+		}
+		return out
+	}
+	for _, tc := range []struct {
+		scores       []int
+		streak, best int
+	}{
+		{[]int{90, 50, 85, -1, 92, 40}, 2, 2}, // today still low, weekend skipped
+		{[]int{90, 90, 90, 50, 95}, 1, 3},
+		{[]int{50, 60}, 0, 0},
+	} {
+		d := days(tc.scores...)
+		if got := Streak(d); got != tc.streak {
+			t.Errorf("Streak(%v) = %d, want %d", tc.scores, got, tc.streak)
+		}
+		if got := BestStreak(d); got != tc.best {
+			t.Errorf("BestStreak(%v) = %d, want %d", tc.scores, got, tc.best)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	suggested := []ai.Allocation{{ProjectID: "p1", Minutes: 60, Description: "Review PRs"}}
+	if got := EditDistance(suggested, suggested); got != 0 {
+		t.Errorf("unchanged = %v, want 0", got)
+	}
+	edited := []ai.Allocation{{ProjectID: "p1", Minutes: 60, Description: "Review PRs for auth"}}
+	if got := EditDistance(suggested, edited); got <= 0 || got >= 0.5 {
+		t.Errorf("small edit = %v, want between 0 and 0.5", got)
+	}
+}
@@ -0,0 +1,116 @@
+package release
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const downloadBaseURL = "https://github.com/christopherklint97/clockr/releases/download"
+
+// ChecksumsName returns the goreleaser checksums file name for a version.
+func ChecksumsName(version string) string {
+	return fmt.Sprintf("clockr_%s_checksums.txt", strings.TrimPrefix(version, "v"))
+}
+
+// FetchChecksums downloads the checksums file and its detached signature for
+// the given release version from GitHub.
+func FetchChecksums(ctx context.Context, version string) (checksums, signature []byte, err error) {
+	base := fmt.Sprintf("%s/v%s/%s", downloadBaseURL, strings.TrimPrefix(version, "v"), ChecksumsName(version))
+
+	checksums, err = download(ctx, base)
+	if err != nil {
+		return nil, nil, fmt.Errorf("downloading checksums: %w", err)
+	}
+	signature, err = download(ctx, base+".sig")
+	if err != nil {
+		return nil, nil, fmt.Errorf("downloading checksums signature: %w", err)
+	}
+	return checksums, signature, nil
+}
+
+func download(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned status %d", url, resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// VerifySignature checks an ed25519 signature over data. The public key is
+// base64-encoded (as embedded at build time); the signature may be raw bytes
+// or base64 text.
+func VerifySignature(data, signature []byte, publicKey string) error {
+	if publicKey == "" {
+		return fmt.Errorf("this build has no embedded signing key — only official release builds can verify signatures")
+	}
+	key, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return fmt.Errorf("embedded signing key is malformed")
+	}
+
+	sig := signature
+	if len(sig) != ed25519.SignatureSize {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+		if err != nil {
+			return fmt.Errorf("signature is neither raw ed25519 nor base64")
+		}
+		sig = decoded
+	}
+
+	if !ed25519.Verify(ed25519.PublicKey(key), data, sig) {
+		return fmt.Errorf("signature does not match checksums file — it may have been tampered with")
+	}
+	return nil
+}
+
+// FileSHA256 returns the hex-encoded SHA-256 digest of the file at path.
+func FileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("opening %s: %w", path, err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("hashing %s: %w", path, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// FindChecksum looks up a digest in a "sha256  filename" checksums file and
+// returns the artifact name it belongs to. Matching is by digest so renamed
+// downloads still verify.
+func FindChecksum(checksums []byte, digest string) (string, bool) {
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		if strings.EqualFold(fields[0], digest) {
+			return strings.TrimPrefix(fields[1], "*"), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,48 @@
+package release
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"testing"
+)
+
+func TestVerifySignature(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+	key := base64.StdEncoding.EncodeToString(pub)
+	data := []byte("abc123  clockr_1.0.0_linux_amd64.tar.gz\n")
+	sig := ed25519.Sign(priv, data)
+
+	if err := VerifySignature(data, sig, key); err != nil {
+		t.Errorf("raw signature: unexpected error: %v", err)
+	}
+	if err := VerifySignature(data, []byte(base64.StdEncoding.EncodeToString(sig)+"\n"), key); err != nil {
+		t.Errorf("base64 signature: unexpected error: %v", err)
+	}
+	if err := VerifySignature([]byte("tampered"), sig, key); err == nil {
+		t.Error("expected error for tampered data, got nil")
+	}
+	if err := VerifySignature(data, sig, ""); err == nil {
+		t.Error("expected error without embedded key, got nil")
+	}
+}
+
+func TestFindChecksum(t *testing.T) {
+	checksums := []byte("aaaa  clockr_1.0.0_darwin_arm64.tar.gz\nBBBB  clockr_1.0.0_linux_amd64.tar.gz\n")
+
+	name, ok := FindChecksum(checksums, "bbbb")
+	if !ok || name != "clockr_1.0.0_linux_amd64.tar.gz" {
+		t.Errorf("FindChecksum() = %q, %v; want linux artifact", name, ok)
+	}
+	if _, ok := FindChecksum(checksums, "cccc"); ok {
+		t.Error("expected no match for unknown digest")
+	}
+}
+
+func TestChecksumsName(t *testing.T) {
+	if got := ChecksumsName("v1.2.3"); got != "clockr_1.2.3_checksums.txt" {
+		t.Errorf("ChecksumsName() = %q", got)
+	}
+}
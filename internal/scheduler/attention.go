@@ -0,0 +1,51 @@
+package scheduler
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+
+	"github.com/christopherklint97/clockr/internal/config"
+)
+
+// Attention draws the eye to the terminal running clockr when a prompt or
+// reminder becomes active, for when it sits behind other windows. Each cue
+// is opt-in and best effort: a missing tool is ignored.
+func Attention(cfg config.NotifyConfig, tmux *TmuxTarget, message string, out io.Writer) {
+	if cfg.Bell {
+		// Most terminals also mark their window or tab on a bell.
+		fmt.Fprint(out, "\a")
+	}
+	if cfg.TmuxMessage && tmux != nil {
+		runQuiet("tmux", tmuxMessageArgs(tmux, message)...)
+	}
+	if cfg.Urgent {
+		if args := urgentArgs(os.Getenv("WINDOWID")); args != nil {
+			runQuiet(args[0], args[1:]...)
+		}
+	}
+}
+
+// tmuxMessageArgs shows message in the status line of every client attached
+// to the session clockr runs in.
+func tmuxMessageArgs(t *TmuxTarget, message string) []string {
+	return []string{"display-message", "-t", t.Session + ":" + t.Window, "-d", "10000", "clockr: " + message}
+}
+
+// urgentArgs returns the command that sets the urgency hint on the X11
+// window with the given ID, or nil where that is not possible.
+func urgentArgs(windowID string) []string {
+	if windowID == "" || runtime.GOOS == "darwin" || runtime.GOOS == "windows" {
+		return nil
+	}
+	return []string{"xdotool", "set_window", "--urgency", "1", windowID}
+}
+
+func runQuiet(name string, args ...string) {
+	if _, err := exec.LookPath(name); err != nil {
+		return
+	}
+	_ = exec.Command(name, args...).Run()
+}
@@ -0,0 +1,48 @@
+package scheduler
+
+import (
+	"bytes"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/christopherklint97/clockr/internal/config"
+)
+
+func TestAttention_Bell(t *testing.T) {
+	var buf bytes.Buffer
+	Attention(config.NotifyConfig{Bell: true}, nil, "time to log", &buf)
+	if buf.String() != "\a" {
+		t.Errorf("output = %q, want bell", buf.String())
+	}
+
+	buf.Reset()
+	Attention(config.NotifyConfig{}, nil, "time to log", &buf)
+	if buf.Len() != 0 {
+		t.Errorf("output = %q with cues disabled, want none", buf.String())
+	}
+}
+
+func TestTmuxMessageArgs(t *testing.T) {
+	args := tmuxMessageArgs(&TmuxTarget{Session: "work", Window: "2", PaneID: "%5"}, "time to log")
+	got := strings.Join(args, " ")
+	if !strings.HasPrefix(got, "display-message -t work:2 ") || !strings.HasSuffix(got, "clockr: time to log") {
+		t.Errorf("args = %q", got)
+	}
+}
+
+func TestUrgentArgs(t *testing.T) {
+	if args := urgentArgs(""); args != nil {
+		t.Errorf("urgentArgs(\"\") = %v, want nil", args)
+	}
+	args := urgentArgs("12345")
+	if runtime.GOOS == "darwin" || runtime.GOOS == "windows" {
+		if args != nil {
+			t.Errorf("urgentArgs on %s = %v, want nil", runtime.GOOS, args)
+		}
+		return
+	}
+	if len(args) == 0 || args[0] != "xdotool" || args[len(args)-1] != "12345" {
+		t.Errorf("urgentArgs = %v", args)
+	}
+}
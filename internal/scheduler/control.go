@@ -0,0 +1,320 @@
+package scheduler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/config"
+	"github.com/christopherklint97/clockr/internal/httpmetrics"
+	"github.com/christopherklint97/clockr/internal/mirror"
+	"github.com/christopherklint97/clockr/internal/secrets"
+)
+
+// Control commands understood by the scheduler's socket.
+const (
+	CmdStop         = "stop"
+	CmdPause        = "pause"
+	CmdResume       = "resume"
+	CmdPromptNow    = "prompt-now"
+	CmdStatus       = "status"
+	CmdReloadConfig = "reload-config"
+	CmdHTTPStats    = "http-stats"
+)
+
+// ErrNotRunning is returned by SendControl when no scheduler is listening.
+var ErrNotRunning = errors.New("scheduler is not running")
+
+// ControlRequest is one command sent to the scheduler, encoded as a single
+// JSON line.
+type ControlRequest struct {
+	Command string `json:"command"`
+	// Duration limits a pause (Go duration syntax); Until ends it at a set
+	// time. With neither, the pause lasts until resumed.
+	Duration string    `json:"duration,omitempty"`
+	Until    time.Time `json:"until,omitzero"`
+	Reason   string    `json:"reason,omitempty"`
+}
+
+// ControlResponse is the scheduler's reply to a ControlRequest.
+type ControlResponse struct {
+	OK      bool           `json:"ok"`
+	Message string         `json:"message,omitempty"`
+	Status  *ControlStatus `json:"status,omitempty"`
+
+	HTTPStats []httpmetrics.Stats `json:"http_stats,omitempty"`
+}
+
+// ControlStatus describes the running scheduler.
+type ControlStatus struct {
+	PID             int       `json:"pid"`
+	StartedAt       time.Time `json:"started_at"`
+	NextPrompt      time.Time `json:"next_prompt"`
+	IntervalMinutes int       `json:"interval_minutes"`
+	Paused          bool      `json:"paused"`
+	PausedUntil     time.Time `json:"paused_until,omitzero"`
+	PauseReason     string    `json:"pause_reason,omitempty"`
+	Holiday         bool      `json:"holiday"`
+	Prompting       bool      `json:"prompting"`
+}
+
+func socketPath() (string, error) {
+	dir, err := config.StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "clockr.sock"), nil
+}
+
+// SendControl sends req to the running scheduler and returns its response.
+func SendControl(ctx context.Context, req ControlRequest) (*ControlResponse, error) {
+	path, err := socketPath()
+	if err != nil {
+		return nil, err
+	}
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "unix", path)
+	if err != nil {
+		return nil, ErrNotRunning
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return nil, fmt.Errorf("sending %s: %w", req.Command, err)
+	}
+	var resp ControlResponse
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("reading reply to %s: %w", req.Command, err)
+	}
+	if !resp.OK {
+		return &resp, errors.New(resp.Message)
+	}
+	return &resp, nil
+}
+
+// listenControl opens the control socket. A leftover socket from a crashed
+// scheduler is removed; a live one means another scheduler is running.
+func listenControl() (net.Listener, error) {
+	path, err := socketPath()
+	if err != nil {
+		return nil, err
+	}
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		conn.Close()
+		return nil, fmt.Errorf("another scheduler is already listening on %s", path)
+	}
+	os.Remove(path)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("creating state directory: %w", err)
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("listening on %s: %w", path, err)
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		l.Close()
+		return nil, fmt.Errorf("restricting %s: %w", path, err)
+	}
+	return l, nil
+}
+
+// serveControl answers control requests until ctx is done.
+func (s *Scheduler) serveControl(ctx context.Context, l net.Listener) {
+	go func() {
+		<-ctx.Done()
+		l.Close()
+	}()
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		go s.handleControl(conn)
+	}
+}
+
+func (s *Scheduler) handleControl(conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+
+	var req ControlRequest
+	line, err := bufio.NewReader(conn).ReadBytes('\n')
+	if err == nil {
+		err = json.Unmarshal(line, &req)
+	}
+	var resp ControlResponse
+	if err != nil {
+		resp = ControlResponse{Message: fmt.Sprintf("invalid request: %v", err)}
+	} else {
+		resp = s.control(req)
+	}
+	json.NewEncoder(conn).Encode(resp)
+}
+
+// control executes one control request.
+func (s *Scheduler) control(req ControlRequest) ControlResponse {
+	switch req.Command {
+	case CmdStatus:
+		st := s.status()
+		return ControlResponse{OK: true, Status: &st}
+
+	case CmdStop:
+		s.stop()
+		return ControlResponse{OK: true, Message: "scheduler stopping"}
+
+	case CmdPause:
+		until := req.Until
+		if req.Duration != "" {
+			d, err := time.ParseDuration(req.Duration)
+			if err != nil || d <= 0 {
+				return ControlResponse{Message: fmt.Sprintf("invalid pause duration %q", req.Duration)}
+			}
+			until = time.Now().Add(d)
+		}
+		if err := Pause(s.db, until, req.Reason); err != nil {
+			return ControlResponse{Message: err.Error()}
+		}
+		s.wake()
+		return ControlResponse{OK: true, Message: PausedMessage(until)}
+
+	case CmdResume:
+		resumed, err := Resume(s.db)
+		if err != nil {
+			return ControlResponse{Message: err.Error()}
+		}
+		s.wake()
+		if !resumed {
+			return ControlResponse{OK: true, Message: "scheduler was not paused"}
+		}
+		return ControlResponse{OK: true, Message: "resumed"}
+
+	case CmdPromptNow:
+		if !s.trigger() {
+			return ControlResponse{Message: "a prompt is already open"}
+		}
+		return ControlResponse{OK: true, Message: "prompt opened in the scheduler's terminal"}
+
+	case CmdHTTPStats:
+		return ControlResponse{OK: true, HTTPStats: httpmetrics.Snapshot()}
+
+	case CmdReloadConfig:
+		// An explicit reload also picks up 'clockr secrets set' from
+		// another process.
+		secrets.Forget()
+		summary, err := s.Reload()
+		if err != nil {
+			return ControlResponse{Message: err.Error()}
+		}
+		fmt.Printf("Config reloaded: %s\n", summary)
+		return ControlResponse{OK: true, Message: "config reloaded: " + summary}
+	}
+	return ControlResponse{Message: fmt.Sprintf("unknown command %q", req.Command)}
+}
+
+func (s *Scheduler) config() *config.Config {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cfg
+}
+
+// mirrors returns the mirror destinations of the current config.
+func (s *Scheduler) mirrors() *mirror.Mirrors {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.mirror
+}
+
+func (s *Scheduler) setConfig(cfg *config.Config) {
+	m := mirror.New(cfg, slog.Default())
+	s.mu.Lock()
+	s.cfg = cfg
+	s.mirror = m
+	s.mu.Unlock()
+	s.wake()
+}
+
+// wake makes the run loop recompute its next tick.
+func (s *Scheduler) wake() {
+	select {
+	case s.wakeCh <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Scheduler) stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		s.cancel()
+	}
+}
+
+// PausedMessage describes a pause ending at until (zero: until resumed).
+func PausedMessage(until time.Time) string {
+	switch {
+	case until.IsZero():
+		return "paused until resumed"
+	case until.Format(time.DateOnly) == time.Now().Format(time.DateOnly):
+		return "paused until " + until.Format("15:04")
+	default:
+		return "paused until " + until.Format("Mon 2 Jan 15:04")
+	}
+}
+
+// trigger asks the run loop to open a prompt. It returns false if a prompt
+// is already open or queued.
+func (s *Scheduler) trigger() bool {
+	s.mu.Lock()
+	prompting := s.prompting
+	s.mu.Unlock()
+	if prompting {
+		return false
+	}
+	select {
+	case s.triggerCh <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *Scheduler) setPrompting(v bool) {
+	s.mu.Lock()
+	s.prompting = v
+	s.mu.Unlock()
+}
+
+func (s *Scheduler) status() ControlStatus {
+	now := time.Now()
+	pause, _ := s.db.ActivePause(now)
+	cfg := s.config()
+	st := ControlStatus{
+		PID:             os.Getpid(),
+		IntervalMinutes: int(cfg.Schedule.IntervalFor(now.In(cfg.Schedule.Location()).Weekday()).Minutes()),
+		Holiday:         s.onHoliday(context.Background(), now),
+	}
+	if pause != nil {
+		st.Paused = true
+		st.PausedUntil = pause.EndTime
+		st.PauseReason = pause.Reason
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st.StartedAt = s.startedAt
+	st.NextPrompt = s.nextTick
+	st.Prompting = s.prompting
+	return st
+}
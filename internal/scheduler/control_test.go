@@ -0,0 +1,152 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/config"
+	"github.com/christopherklint97/clockr/internal/store"
+)
+
+// testHome points CLOCKR_HOME at a fresh temp dir and opens its database.
+func testHome(t *testing.T) *store.DB {
+	t.Helper()
+	// Short base path: unix socket paths are limited to ~104 bytes.
+	dir, err := os.MkdirTemp("", "clockr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	t.Setenv("CLOCKR_HOME", dir)
+
+	db, err := store.Open(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestControl_PauseResume(t *testing.T) {
+	db := testHome(t)
+	cfg := config.DefaultConfig()
+	s := New(&cfg, nil, db, nil)
+
+	resp := s.control(ControlRequest{Command: CmdPause, Duration: "30m", Reason: "errand"})
+	if !resp.OK {
+		t.Fatalf("pause failed: %s", resp.Message)
+	}
+	if st := s.status(); !st.Paused || st.PauseReason != "errand" {
+		t.Fatalf("expected scheduler to be paused, got %+v", st)
+	}
+	if p, _ := db.ActivePause(time.Now().Add(31 * time.Minute)); p != nil {
+		t.Error("timed pause should expire")
+	}
+
+	s.control(ControlRequest{Command: CmdPause})
+	if resp := s.control(ControlRequest{Command: CmdResume}); !resp.OK || resp.Message != "resumed" {
+		t.Errorf("unexpected resume response %+v", resp)
+	}
+	if s.status().Paused {
+		t.Error("expected scheduler to be resumed")
+	}
+	if resp := s.control(ControlRequest{Command: CmdResume}); resp.Message != "scheduler was not paused" {
+		t.Errorf("unexpected second resume response %+v", resp)
+	}
+
+	if resp := s.control(ControlRequest{Command: CmdPause, Until: time.Now().Add(-time.Hour)}); resp.OK {
+		t.Error("expected a pause ending in the past to fail")
+	}
+
+	if resp := s.control(ControlRequest{Command: CmdPause, Duration: "soon"}); resp.OK {
+		t.Error("expected invalid duration to fail")
+	}
+	if resp := s.control(ControlRequest{Command: "dance"}); resp.OK {
+		t.Error("expected unknown command to fail")
+	}
+}
+
+func TestControl_TriggerOnlyOnce(t *testing.T) {
+	cfg := config.DefaultConfig()
+	s := New(&cfg, nil, nil, nil)
+
+	if !s.trigger() {
+		t.Fatal("first trigger should be queued")
+	}
+	if s.trigger() {
+		t.Error("second trigger should be refused while one is queued")
+	}
+}
+
+func TestSendControl_Socket(t *testing.T) {
+	db := testHome(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := SendControl(ctx, ControlRequest{Command: CmdStatus}); !errors.Is(err, ErrNotRunning) {
+		t.Fatalf("expected ErrNotRunning, got %v", err)
+	}
+
+	cfg := config.DefaultConfig()
+	s := New(&cfg, nil, db, nil)
+	l, err := listenControl()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.serveControl(ctx, l)
+
+	if _, err := listenControl(); err == nil {
+		t.Error("expected a second listener to be refused")
+	}
+
+	resp, err := SendControl(ctx, ControlRequest{Command: CmdStatus})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Status == nil || resp.Status.IntervalMinutes != 60 || resp.Status.PID != os.Getpid() {
+		t.Errorf("unexpected status %+v", resp.Status)
+	}
+
+	if _, err := SendControl(ctx, ControlRequest{Command: "dance"}); err == nil {
+		t.Error("expected error for unknown command")
+	}
+}
+
+func TestDebug_LoopbackOnly(t *testing.T) {
+	for _, addr := range []string{"0.0.0.0:0", ":0", "192.0.2.1:0"} {
+		if l, err := listenDebug(addr); err == nil {
+			l.Close()
+			t.Errorf("listenDebug(%q) accepted a non-loopback address", addr)
+		}
+	}
+
+	db := testHome(t)
+	cfg := config.DefaultConfig()
+	s := New(&cfg, nil, db, nil)
+	l, err := listenDebug("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.serveDebug(ctx, l)
+
+	resp, err := http.Get("http://" + l.Addr().String() + "/debug/vars")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var vars map[string]json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&vars); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := vars["scheduler"]; !ok {
+		t.Errorf("/debug/vars has no scheduler var: %v", vars)
+	}
+}
@@ -0,0 +1,118 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/christopherklint97/clockr/internal/backend"
+	"github.com/christopherklint97/clockr/internal/clockify"
+	"github.com/christopherklint97/clockr/internal/config"
+	"github.com/christopherklint97/clockr/internal/store"
+)
+
+// LogBreak logs a skipped window to Clockify on the [coverage] break project
+// when the day's policy is "full", and returns the new entry's ID. It returns
+// "" without logging anything otherwise. A break that can't be logged is
+// queued on the skip, and RetryBreaks logs it later.
+func LogBreak(ctx context.Context, cfg *config.Config, b backend.Backend, db *store.DB, skip *store.Skip) (string, error) {
+	cov := cfg.Coverage
+	if skip == nil || skip.Minutes <= 0 || db.ReadOnly() || cov.ProjectID == "" {
+		return "", nil
+	}
+	if cov.PolicyFor(skip.StartTime.In(cfg.Schedule.Location()).Weekday()) != "full" {
+		return "", nil
+	}
+
+	id, err := createBreak(ctx, cfg, b, db, skip)
+	if err != nil && id == "" && skip.ID != 0 {
+		if qerr := db.MarkBreakFailed(skip.ID); qerr != nil {
+			return "", errors.Join(err, qerr)
+		}
+		return "", fmt.Errorf("%w (queued for retry)", err)
+	}
+	return id, err
+}
+
+// RetryBreaks logs the break entries that LogBreak queued, writing progress
+// to out. Breaks are claimed first, like entries in RetryFailed, so
+// concurrent passes never log one twice.
+func RetryBreaks(ctx context.Context, cfg *config.Config, b backend.Backend, db *store.DB, out io.Writer) (RetryResult, error) {
+	var res RetryResult
+	if db.ReadOnly() || cfg.Coverage.ProjectID == "" {
+		return res, nil
+	}
+
+	skips, err := db.GetFailedBreaks()
+	if err != nil {
+		return res, fmt.Errorf("fetching failed breaks: %w", err)
+	}
+	if len(skips) == 0 {
+		return res, nil
+	}
+
+	fmt.Fprintf(out, "Retrying %d failed breaks...\n", len(skips))
+	for _, skip := range skips {
+		if ctx.Err() != nil {
+			res.Skipped++
+			continue
+		}
+		claimed, err := db.ClaimBreakRetry(skip.ID, retryLease)
+		if err != nil {
+			fmt.Fprintf(out, "  Could not claim break %d: %v\n", skip.ID, err)
+			res.Failed++
+			continue
+		}
+		if !claimed {
+			res.Skipped++
+			continue
+		}
+
+		if _, err := createBreak(ctx, cfg, b, db, &skip); err != nil {
+			fmt.Fprintf(out, "  Retry failed for break %d: %s\n", skip.ID, clockify.FriendlyError(err))
+			res.Failed++
+			continue
+		}
+		fmt.Fprintf(out, "  Logged %dmin break from %s\n", skip.Minutes, skip.StartTime.In(cfg.Schedule.Location()).Format("Mon 15:04"))
+		res.Succeeded++
+	}
+	return res, nil
+}
+
+// createBreak creates the break entry for skip and records it on the skip.
+func createBreak(ctx context.Context, cfg *config.Config, b backend.Backend, db *store.DB, skip *store.Skip) (string, error) {
+	desc := cfg.Coverage.Description
+	if desc == "" {
+		desc = "Break"
+	}
+	if skip.Reason != "" {
+		desc += ": " + skip.Reason
+	}
+
+	created, err := b.CreateEntry(ctx, clockify.TimeEntryRequest{
+		Start:       skip.StartTime.UTC().Format("2006-01-02T15:04:05Z"),
+		End:         skip.EndTime.UTC().Format("2006-01-02T15:04:05Z"),
+		ProjectID:   cfg.Coverage.ProjectID,
+		Description: desc,
+	})
+	if err != nil {
+		return "", fmt.Errorf("logging break: %w", err)
+	}
+	if skip.ID != 0 {
+		if err := db.SetSkipClockifyID(skip.ID, created.ID); err != nil {
+			return created.ID, err
+		}
+	}
+	return created.ID, nil
+}
+
+// logBreak logs a skip as a break and reports the outcome.
+func (s *Scheduler) logBreak(ctx context.Context, skip *store.Skip) {
+	id, err := LogBreak(ctx, s.config(), s.backend, s.db, skip)
+	if err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	} else if id != "" {
+		fmt.Printf("Logged %dmin as a break.\n", skip.Minutes)
+	}
+}
@@ -0,0 +1,130 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/backend"
+	"github.com/christopherklint97/clockr/internal/clockify"
+	"github.com/christopherklint97/clockr/internal/config"
+	"github.com/christopherklint97/clockr/internal/store"
+)
+
+// offlineBackend records the entries created and fails while offline is set.
+type offlineBackend struct {
+	backend.Backend
+	offline bool
+	created []clockify.TimeEntryRequest
+}
+
+func (b *offlineBackend) CreateEntry(_ context.Context, e clockify.TimeEntryRequest) (*clockify.TimeEntry, error) {
+	if b.offline {
+		return nil, errors.New("connection refused")
+	}
+	b.created = append(b.created, e)
+	return &clockify.TimeEntry{ID: fmt.Sprintf("break-%d", len(b.created))}, nil
+}
+
+func coverageConfig() *config.Config {
+	cfg := config.DefaultConfig()
+	cfg.Coverage = config.CoverageConfig{Policy: "full", Days: map[string]string{"friday": "off"}, ProjectID: "p-break"}
+	return &cfg
+}
+
+// insertSkip stores a one-hour skip starting at start.
+func insertSkip(t *testing.T, db *store.DB, start time.Time, reason string) *store.Skip {
+	t.Helper()
+	skip := &store.Skip{StartTime: start, EndTime: start.Add(time.Hour), Minutes: 60, Reason: reason}
+	if _, err := db.InsertSkip(skip); err != nil {
+		t.Fatal(err)
+	}
+	return skip
+}
+
+func TestLogBreak(t *testing.T) {
+	db := testHome(t)
+	cfg := coverageConfig()
+	b := &offlineBackend{}
+	monday := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+
+	skip := insertSkip(t, db, monday, "lunch")
+	id, err := LogBreak(context.Background(), cfg, b, db, skip)
+	if err != nil || id != "break-1" {
+		t.Fatalf("LogBreak = %q, %v; want break-1", id, err)
+	}
+	want := clockify.TimeEntryRequest{Start: "2026-03-02T12:00:00Z", End: "2026-03-02T13:00:00Z", ProjectID: "p-break", Description: "Break: lunch"}
+	if !reflect.DeepEqual(b.created[0], want) {
+		t.Errorf("created %+v, want %+v", b.created[0], want)
+	}
+	skips, _ := db.AllSkips()
+	if skips[0].ClockifyID != "break-1" {
+		t.Errorf("skip clockify id = %q, want break-1", skips[0].ClockifyID)
+	}
+
+	// Days with policy "off" and skips without a project are left alone.
+	friday := insertSkip(t, db, monday.AddDate(0, 0, 4), "")
+	if id, err := LogBreak(context.Background(), cfg, b, db, friday); id != "" || err != nil {
+		t.Errorf("LogBreak on a Friday = %q, %v; want nothing", id, err)
+	}
+	cfg.Coverage.ProjectID = ""
+	if id, err := LogBreak(context.Background(), cfg, b, db, skip); id != "" || err != nil {
+		t.Errorf("LogBreak without a project = %q, %v; want nothing", id, err)
+	}
+	if len(b.created) != 1 {
+		t.Errorf("created %d entries, want 1", len(b.created))
+	}
+}
+
+func TestLogBreak_QueuesFailure(t *testing.T) {
+	db := testHome(t)
+	cfg := coverageConfig()
+	b := &offlineBackend{offline: true}
+	skip := insertSkip(t, db, time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC), "")
+
+	if _, err := LogBreak(context.Background(), cfg, b, db, skip); err == nil {
+		t.Fatal("LogBreak succeeded while offline")
+	}
+	queued, err := db.GetFailedBreaks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(queued) != 1 || queued[0].ID != skip.ID {
+		t.Fatalf("failed breaks = %+v, want the skip", queued)
+	}
+
+	// Still offline: the break stays queued.
+	res, err := RetryBreaks(context.Background(), cfg, b, db, io.Discard)
+	if err != nil || res.Failed != 1 {
+		t.Errorf("RetryBreaks offline = %+v, %v; want 1 failed", res, err)
+	}
+
+	// A retry within the lease is skipped, so concurrent passes never log the
+	// break twice.
+	b.offline = false
+	res, err = RetryBreaks(context.Background(), cfg, b, db, io.Discard)
+	if err != nil || res.Skipped != 1 || len(b.created) != 0 {
+		t.Errorf("RetryBreaks within the lease = %+v, %v, created %d; want 1 skipped", res, err, len(b.created))
+	}
+	if _, err := db.Exec("UPDATE skips SET break_attempt_at = NULL"); err != nil {
+		t.Fatal(err)
+	}
+	res, err = RetryBreaks(context.Background(), cfg, b, db, io.Discard)
+	if err != nil || res.Succeeded != 1 {
+		t.Errorf("RetryBreaks = %+v, %v; want 1 succeeded", res, err)
+	}
+	if len(b.created) != 1 || b.created[0].Description != "Break" {
+		t.Errorf("created %+v, want one Break entry", b.created)
+	}
+	skips, _ := db.AllSkips()
+	if skips[0].ClockifyID != "break-1" || skips[0].BreakFailed {
+		t.Errorf("skip = %+v, want logged as break-1", skips[0])
+	}
+	if queued, _ := db.GetFailedBreaks(); len(queued) != 0 {
+		t.Errorf("failed breaks after the retry = %+v", queued)
+	}
+}
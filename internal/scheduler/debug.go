@@ -0,0 +1,99 @@
+package scheduler
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/httpmetrics"
+	"github.com/christopherklint97/clockr/internal/metrics"
+)
+
+// SetDebugAddr serves pprof and expvar on addr while the scheduler runs.
+// addr must be a loopback address: profiles expose the process's memory.
+func (s *Scheduler) SetDebugAddr(addr string) {
+	s.debugAddr = addr
+}
+
+var (
+	publishOnce sync.Once
+	debugged    atomic.Pointer[Scheduler]
+)
+
+// listenDebug checks that addr is on the loopback interface and opens it.
+func listenDebug(addr string) (net.Listener, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("debug address %q: %w", addr, err)
+	}
+	if host != "localhost" {
+		if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+			return nil, fmt.Errorf("debug address %q must be on localhost or 127.0.0.1", addr)
+		}
+	}
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("opening debug address: %w", err)
+	}
+	return l, nil
+}
+
+// debugHandler serves /debug/pprof/ and /debug/vars. The "scheduler" var
+// holds the control status and the goroutine count, "http" the API request
+// stats.
+func (s *Scheduler) debugHandler() http.Handler {
+	debugged.Store(s)
+	publishOnce.Do(func() {
+		expvar.Publish("scheduler", expvar.Func(func() any {
+			s := debugged.Load()
+			if s == nil {
+				return nil
+			}
+			return map[string]any{
+				"status":     s.status(),
+				"goroutines": runtime.NumGoroutine(),
+			}
+		}))
+		expvar.Publish("http", expvar.Func(func() any { return httpmetrics.Snapshot() }))
+	})
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /debug/vars", expvar.Handler())
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	return mux
+}
+
+// serveDebug answers debug requests until ctx is done.
+func (s *Scheduler) serveDebug(ctx context.Context, l net.Listener) {
+	serveHTTP(ctx, l, s.debugHandler())
+}
+
+// metricsHandler serves the Prometheus metrics on /metrics.
+func metricsHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", metrics.Handler())
+	return mux
+}
+
+// serveHTTP answers requests on l with h until ctx is done.
+func serveHTTP(ctx context.Context, l net.Listener, h http.Handler) {
+	srv := &http.Server{Handler: h, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+	srv.Serve(l)
+}
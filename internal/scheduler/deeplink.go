@@ -0,0 +1,67 @@
+package scheduler
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// commandPlaceholder is replaced with the `clockr prompt-now` command line
+// in a terminal command template.
+const commandPlaceholder = "{command}"
+
+// DefaultTerminalCommand returns the per-OS template used to open a terminal
+// running {command} when a notification is clicked.
+func DefaultTerminalCommand() string {
+	switch runtime.GOOS {
+	case "darwin":
+		return `osascript -e 'tell application "Terminal" to do script "{command}"' -e 'tell application "Terminal" to activate'`
+	case "windows":
+		return `cmd /c start "clockr" cmd /k {command}`
+	default:
+		return "x-terminal-emulator -e {command}"
+	}
+}
+
+// PromptNowCommand returns the command line that opens a prompt for the
+// current window. The executable's absolute path is used when it is safe to
+// embed unquoted; otherwise clockr must be on PATH.
+func PromptNowCommand(configPath string) string {
+	bin := "clockr"
+	if exe, err := os.Executable(); err == nil && plainWord(exe) {
+		bin = exe
+	}
+	parts := []string{bin, "prompt-now"}
+	if configPath != "" && plainWord(configPath) {
+		parts = append(parts, "--config", configPath)
+	}
+	return strings.Join(parts, " ")
+}
+
+// plainWord reports whether s can be placed in a shell command, an AppleScript
+// string and a cmd.exe line without quoting.
+func plainWord(s string) bool {
+	special := " \t\n\"'`$&|;<>()*?!%^"
+	if runtime.GOOS != "windows" {
+		special += `\`
+	}
+	return s != "" && !strings.ContainsAny(s, special)
+}
+
+// ClickCommand expands a terminal command template. An empty template uses
+// DefaultTerminalCommand.
+func ClickCommand(template, configPath string) string {
+	if template == "" {
+		template = DefaultTerminalCommand()
+	}
+	return strings.ReplaceAll(template, commandPlaceholder, PromptNowCommand(configPath))
+}
+
+// shellCommand returns an exec.Cmd that runs line through the platform shell.
+func shellCommand(line string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.Command("cmd", "/c", line)
+	}
+	return exec.Command("sh", "-c", line)
+}
@@ -0,0 +1,100 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/config"
+	"github.com/christopherklint97/clockr/internal/digest"
+	"github.com/christopherklint97/clockr/internal/store"
+)
+
+// digestCheckInterval is how often the scheduler checks whether the weekly
+// digest is due. Checking rather than sleeping until the send time picks up
+// config reloads and catches up after the machine slept.
+const digestCheckInterval = 10 * time.Minute
+
+// digestStateKey records the Monday of the last week a digest was sent for.
+const digestStateKey = "digest_last_week"
+
+func (s *Scheduler) digestLoop(ctx context.Context) {
+	for {
+		s.maybeSendDigest(time.Now().In(s.config().Schedule.Location()))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(digestCheckInterval):
+		}
+	}
+}
+
+// digestDue reports whether the digest time of now's week has passed.
+func digestDue(cfg config.DigestConfig, now time.Time) bool {
+	monday := now.AddDate(0, 0, -int(now.Weekday()+6)%7)
+	day := time.Date(monday.Year(), monday.Month(), monday.Day(), 0, 0, 0, 0, now.Location()).
+		AddDate(0, 0, int(cfg.Weekday()+6)%7)
+	return !now.Before(day.Add(time.Duration(cfg.Clock()) * time.Minute))
+}
+
+// maybeSendDigest sends last week's digest once it is due, at most once per
+// week even across restarts.
+func (s *Scheduler) maybeSendDigest(now time.Time) {
+	cfg := s.config()
+	if !cfg.Digest.Enabled || s.db.ReadOnly() || !digestDue(cfg.Digest, now) {
+		return
+	}
+	week := digest.WeekBefore(now)
+	key := week.Format("2006-01-02")
+	if last, _ := s.db.GetState(digestStateKey); last == key {
+		return
+	}
+	// Mark the week first: a failing channel is reported, not retried every
+	// few minutes through the others.
+	if err := s.db.SetState(digestStateKey, key); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+		return
+	}
+	if err := SendDigest(cfg, s.db, week, nil); err != nil {
+		fmt.Printf("Warning: weekly digest: %v\n", err)
+	}
+}
+
+// SendDigest builds the digest for the week starting at monday and delivers
+// it through every channel in [digest]. out, when not nil, gets a line per
+// channel delivered.
+func SendDigest(cfg *config.Config, db *store.DB, monday time.Time, out io.Writer) error {
+	if out == nil {
+		out = io.Discard
+	}
+	d, err := digest.Build(db, cfg.Schedule, monday)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	if cfg.Digest.Notify {
+		if err := SendNotification("clockr — week "+d.Week(), d.Summary(), nil, ""); err != nil {
+			errs = append(errs, fmt.Errorf("notification: %w", err))
+		} else {
+			fmt.Fprintln(out, "Sent the digest as a notification.")
+		}
+	}
+	if cfg.Digest.File != "" {
+		if path, err := d.WriteFile(cfg.Digest.File); err != nil {
+			errs = append(errs, err)
+		} else {
+			fmt.Fprintf(out, "Wrote the digest to %s.\n", path)
+		}
+	}
+	if len(cfg.Digest.Email.To) > 0 {
+		if err := d.Email(cfg.Digest.Email); err != nil {
+			errs = append(errs, err)
+		} else {
+			fmt.Fprintf(out, "Emailed the digest to %d recipient(s).\n", len(cfg.Digest.Email.To))
+		}
+	}
+	return errors.Join(errs...)
+}
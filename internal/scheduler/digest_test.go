@@ -0,0 +1,29 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/config"
+)
+
+func TestDigestDue(t *testing.T) {
+	cfg := config.DigestConfig{Enabled: true, Day: "tuesday", Time: "08:30"}
+	at := func(day, h, m int) time.Time { return time.Date(2026, 3, day, h, m, 0, 0, time.UTC) } // March 2 is a Monday
+
+	for _, tc := range []struct {
+		now  time.Time
+		want bool
+	}{
+		{at(2, 23, 0), false}, // Monday
+		{at(3, 8, 29), false},
+		{at(3, 8, 30), true},
+		{at(6, 12, 0), true}, // later that week, e.g. after sleeping through Tuesday
+		{at(8, 23, 59), true},
+		{at(9, 9, 0), false}, // the next Monday
+	} {
+		if got := digestDue(cfg, tc.now); got != tc.want {
+			t.Errorf("digestDue(%s) = %v, want %v", tc.now.Format("Mon 15:04"), got, tc.want)
+		}
+	}
+}
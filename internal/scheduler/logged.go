@@ -0,0 +1,37 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/audit"
+)
+
+// loggedIn returns the time already logged between start and end, locally or
+// straight in Clockify or Harvest (e.g. a timer started by hand mid-hour).
+// The backend is best effort: when it can't be reached, only local entries count.
+func (s *Scheduler) loggedIn(ctx context.Context, start, end time.Time) []audit.Interval {
+	var logged []audit.Interval
+	if local, err := s.db.GetEntriesBetween(start, end); err == nil {
+		for _, e := range local {
+			logged = append(logged, audit.Interval{Start: e.StartTime, End: e.EndTime})
+		}
+	}
+
+	// The backend filters by start time; look back far enough to catch entries
+	// that began before the window and run into it.
+	remote, err := s.backend.ListEntries(ctx, start.Add(-12*time.Hour), end)
+	if err != nil {
+		return logged
+	}
+	for _, r := range remote {
+		stop := r.TimeInterval.End
+		if stop.IsZero() { // a running timer
+			stop = end
+		}
+		if stop.After(start) && r.TimeInterval.Start.Before(end) {
+			logged = append(logged, audit.Interval{Start: r.TimeInterval.Start, End: stop})
+		}
+	}
+	return logged
+}
@@ -137,25 +137,35 @@ func ShowPromptDialog(ctx context.Context, title, message string, snoozeOptions
 	return DialogResult{Action: ActionLogNow}, nil
 }
 
-// SendNotification sends a desktop notification. If tmuxTarget is provided and
-// terminal-notifier is available on macOS, clicking the notification will focus
-// the tmux pane where clockr is running.
-func SendNotification(title, message string, tmuxTarget *TmuxTarget) error {
-	if runtime.GOOS == "darwin" {
+// SendNotification sends a desktop notification. Clicking it focuses the tmux
+// pane where clockr is running if tmuxTarget is set, and otherwise runs
+// clickCmd (typically a terminal running `clockr prompt-now`). Click actions
+// need terminal-notifier on macOS or a notify-send with --action on Linux;
+// elsewhere the notification is informational only.
+func SendNotification(title, message string, tmuxTarget *TmuxTarget, clickCmd string) error {
+	switch runtime.GOOS {
+	case "darwin":
 		if notifierPath, err := exec.LookPath("terminal-notifier"); err == nil {
-			return sendTerminalNotification(notifierPath, title, message, tmuxTarget)
+			return sendTerminalNotification(notifierPath, title, message, tmuxTarget, clickCmd)
+		}
+	case "linux", "freebsd", "openbsd":
+		if clickCmd != "" && tmuxTarget == nil && notifySendHasActions() {
+			return sendActionNotification(title, message, clickCmd)
 		}
 	}
 	return zenity.Notify(message, zenity.Title(title), zenity.InfoIcon)
 }
 
 // sendTerminalNotification uses terminal-notifier on macOS to show a
-// notification that focuses the clockr tmux pane when clicked.
-func sendTerminalNotification(notifierPath, title, message string, target *TmuxTarget) error {
+// notification that focuses the clockr tmux pane, or runs clickCmd, when
+// clicked.
+func sendTerminalNotification(notifierPath, title, message string, target *TmuxTarget, clickCmd string) error {
 	args := []string{"-title", title, "-message", message, "-sound", "default", "-group", "clockr"}
 
 	if focusCmd := target.FocusCommand(); focusCmd != "" {
 		args = append(args, "-execute", focusCmd)
+	} else if clickCmd != "" {
+		args = append(args, "-execute", clickCmd)
 	} else {
 		// No tmux target — just activate the terminal on click.
 		bundleID := terminalBundleID()
@@ -174,3 +184,33 @@ func sendTerminalNotification(notifierPath, title, message string, target *TmuxT
 	go cmd.Wait()
 	return nil
 }
+
+// notifySendHasActions reports whether notify-send supports --action
+// (libnotify 0.7.10+).
+func notifySendHasActions() bool {
+	out, err := exec.Command("notify-send", "--help").Output()
+	return err == nil && strings.Contains(string(out), "--action")
+}
+
+// sendActionNotification shows a notify-send notification whose default
+// action runs clickCmd. notify-send blocks until the notification is
+// clicked or dismissed and prints the chosen action, so it is waited on in
+// the background.
+func sendActionNotification(title, message, clickCmd string) error {
+	cmd := exec.Command("notify-send", "--app-name=clockr", "--wait", "--action=default=Log now", title, message)
+	var out strings.Builder
+	cmd.Stdout = &out
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go func() {
+		if err := cmd.Wait(); err != nil || strings.TrimSpace(out.String()) != "default" {
+			return
+		}
+		launch := shellCommand(clickCmd)
+		if err := launch.Start(); err == nil {
+			go launch.Wait()
+		}
+	}()
+	return nil
+}
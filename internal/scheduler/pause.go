@@ -0,0 +1,110 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/calendar"
+	"github.com/christopherklint97/clockr/internal/store"
+	"github.com/tj/go-naturaldate"
+)
+
+// Pause records a pause starting now so the scheduler stops prompting. A
+// zero until pauses until Resume is called. Pauses live in the store, so
+// they survive restarts and work whether or not the scheduler is running.
+func Pause(db *store.DB, until time.Time, reason string) error {
+	now := time.Now()
+	if !until.IsZero() && !until.After(now) {
+		return fmt.Errorf("pause end %s is in the past", until.Format("2006-01-02 15:04"))
+	}
+	_, err := db.InsertPause(&store.Pause{StartTime: now, EndTime: until, Reason: reason})
+	return err
+}
+
+// Resume ends any active pause and reports whether there was one.
+func Resume(db *store.DB) (bool, error) {
+	n, err := db.EndActivePauses(time.Now())
+	return n > 0, err
+}
+
+// ParsePauseEnd turns 'clockr pause' arguments into the time prompts
+// resume: none (zero, until resumed), a duration like "2h", or "until"
+// followed by a clock time (today, or tomorrow if already past) or a date
+// (resuming at the start of that day).
+func ParsePauseEnd(args []string, now time.Time) (time.Time, error) {
+	if len(args) == 0 {
+		return time.Time{}, nil
+	}
+	if args[0] != "until" {
+		if len(args) > 1 {
+			return time.Time{}, fmt.Errorf("expected a duration or 'until DATE', got %q", strings.Join(args, " "))
+		}
+		d, err := time.ParseDuration(args[0])
+		if err != nil || d <= 0 {
+			return time.Time{}, fmt.Errorf("invalid pause duration %q (use e.g. 30m, 2h, or 'until friday')", args[0])
+		}
+		return now.Add(d), nil
+	}
+
+	s := strings.TrimSpace(strings.Join(args[1:], " "))
+	if s == "" {
+		return time.Time{}, fmt.Errorf("'until' needs a date or HH:MM time")
+	}
+	if clock, err := time.Parse("15:04", s); err == nil {
+		t := time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), 0, 0, now.Location())
+		if !t.After(now) {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, s, now.Location()); err == nil {
+		return t, nil
+	}
+	t, err := naturaldate.Parse(s, now, naturaldate.WithDirection(naturaldate.Future))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("cannot parse %q (use YYYY-MM-DD, HH:MM or e.g. 'monday')", s)
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, now.Location()), nil
+}
+
+// holidayCalendar caches which days have events in the configured holiday
+// calendar so the feed is fetched at most once per day.
+type holidayCalendar struct {
+	mu     sync.Mutex
+	source string
+	days   map[string]bool // "YYYY-MM-DD" → holiday
+}
+
+// isHoliday reports whether t's date has an event in the ICS calendar at
+// source, waiting up to timeout for the feed. Fetch errors count as a normal
+// work day so a broken feed never silences prompts for good.
+func (h *holidayCalendar) isHoliday(ctx context.Context, source string, timeout time.Duration, t time.Time) bool {
+	if source == "" {
+		return false
+	}
+	key := t.Format(time.DateOnly)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.source != source {
+		h.source = source
+		h.days = make(map[string]bool)
+	}
+	if holiday, ok := h.days[key]; ok {
+		return holiday
+	}
+
+	dayStart := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	fetchCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	events, err := calendar.Fetch(fetchCtx, source, dayStart, dayStart.AddDate(0, 0, 1))
+	if err != nil {
+		fmt.Printf("Warning: holiday calendar fetch failed: %v\n", err)
+		return false
+	}
+	h.days[key] = len(events) > 0
+	return h.days[key]
+}
@@ -0,0 +1,43 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParsePauseEnd(t *testing.T) {
+	now := time.Date(2026, 3, 4, 14, 30, 0, 0, time.UTC) // a Wednesday
+	tests := []struct {
+		args []string
+		want time.Time
+	}{
+		{nil, time.Time{}},
+		{[]string{"2h"}, now.Add(2 * time.Hour)},
+		{[]string{"until", "16:00"}, time.Date(2026, 3, 4, 16, 0, 0, 0, time.UTC)},
+		{[]string{"until", "9:15"}, time.Date(2026, 3, 5, 9, 15, 0, 0, time.UTC)}, // already past today
+		{[]string{"until", "14:30"}, time.Date(2026, 3, 5, 14, 30, 0, 0, time.UTC)},
+		{[]string{"until", "2026-03-10"}, time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)},
+		{[]string{"until", "friday"}, time.Date(2026, 3, 6, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := ParsePauseEnd(tt.args, now)
+		if err != nil {
+			t.Errorf("ParsePauseEnd(%q): %v", tt.args, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("ParsePauseEnd(%q) = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+
+	for _, args := range [][]string{
+		{"-1h"},
+		{"soon"},
+		{"2h", "30m"},
+		{"until"},
+	} {
+		if _, err := ParsePauseEnd(args, now); err == nil {
+			t.Errorf("ParsePauseEnd(%q) succeeded, want an error", args)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package scheduler
+
+import (
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/config"
+	"github.com/christopherklint97/clockr/internal/store"
+)
+
+const (
+	pendingStartKey = "pending_window_start"
+	pendingEndKey   = "pending_window_end"
+)
+
+// pendingWindow is a prompt window the user dismissed without answering.
+// It is re-offered at the next prompt so the time is not silently lost.
+type pendingWindow struct {
+	Start time.Time
+	End   time.Time
+}
+
+// loadPendingWindow returns the pending window stored in the state table,
+// or nil if there is none.
+func loadPendingWindow(db *store.DB) *pendingWindow {
+	startStr, err := db.GetState(pendingStartKey)
+	if err != nil || startStr == "" {
+		return nil
+	}
+	endStr, err := db.GetState(pendingEndKey)
+	if err != nil || endStr == "" {
+		return nil
+	}
+
+	start, err := time.Parse(time.RFC3339, startStr)
+	if err != nil {
+		return nil
+	}
+	end, err := time.Parse(time.RFC3339, endStr)
+	if err != nil {
+		return nil
+	}
+	return &pendingWindow{Start: start.Local(), End: end.Local()}
+}
+
+func savePendingWindow(db *store.DB, w pendingWindow) error {
+	if db.ReadOnly() {
+		return nil
+	}
+	if err := db.SetState(pendingStartKey, w.Start.UTC().Format(time.RFC3339)); err != nil {
+		return err
+	}
+	return db.SetState(pendingEndKey, w.End.UTC().Format(time.RFC3339))
+}
+
+func clearPendingWindow(db *store.DB) error {
+	if db.ReadOnly() {
+		return nil
+	}
+	if err := db.DeleteState(pendingStartKey); err != nil {
+		return err
+	}
+	return db.DeleteState(pendingEndKey)
+}
+
+// mergeWindow returns the window to offer at tickTime: the newest interval,
+// extended back to the start of any pending window so both are covered.
+func mergeWindow(pending *pendingWindow, tickTime time.Time, interval time.Duration) (time.Time, time.Time) {
+	start := tickTime.Add(-interval)
+	if pending != nil && pending.Start.Before(start) {
+		start = pending.Start
+	}
+	return start, tickTime
+}
+
+// mergeable reports whether pending may be merged into the window offered
+// at tickTime: it must start on the same day and have been prompted (at
+// its end) in the same work block, so the first prompt of the morning does
+// not span the night. Without work hours (anyWorkBlock) only the day counts.
+func mergeable(cfg *config.Config, pending *pendingWindow, tickTime time.Time, anyWorkBlock bool) bool {
+	loc := cfg.Schedule.Location()
+	start, end, tick := pending.Start.In(loc), pending.End.In(loc), tickTime.In(loc)
+	if start.Year() != tick.Year() || start.YearDay() != tick.YearDay() {
+		return false
+	}
+	if anyWorkBlock {
+		return true
+	}
+	endMins := end.Hour()*60 + end.Minute()
+	tickMins := tick.Hour()*60 + tick.Minute()
+	for _, b := range cfg.Schedule.BlocksFor(tick.Weekday()) {
+		if endMins >= b.Start && endMins <= b.End {
+			return end.YearDay() == tick.YearDay() && tickMins >= b.Start && tickMins <= b.End
+		}
+	}
+	return false
+}
+
+// clipToWorkBlock moves start up to the start of the work block that end
+// falls in, so a window offered early in a block does not reach back into
+// the lunch break or the time before work. Outside work hours start is
+// returned as it is.
+func clipToWorkBlock(cfg *config.Config, start, end time.Time) time.Time {
+	loc := cfg.Schedule.Location()
+	end = end.In(loc)
+	endMins := end.Hour()*60 + end.Minute()
+	for _, b := range cfg.Schedule.BlocksFor(end.Weekday()) {
+		if endMins >= b.Start && endMins <= b.End {
+			blockStart := time.Date(end.Year(), end.Month(), end.Day(), b.Start/60, b.Start%60, 0, 0, loc)
+			if start.Before(blockStart) {
+				return blockStart
+			}
+			return start
+		}
+	}
+	return start
+}
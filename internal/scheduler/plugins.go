@@ -0,0 +1,67 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/christopherklint97/clockr/internal/plugin"
+	"github.com/christopherklint97/clockr/internal/store"
+)
+
+// DiscoverPlugins finds the installed plugins, writing a warning to out for
+// each one that could not be described.
+func DiscoverPlugins(ctx context.Context, out io.Writer) []plugin.Plugin {
+	plugins, errs := plugin.Discover(ctx)
+	for _, err := range errs {
+		fmt.Fprintf(out, "Warning: %v\n", err)
+	}
+	return plugins
+}
+
+// SubmitToPlugins sends the entries that reached Clockify to every submit
+// plugin and reports the outcome per plugin to out. Nothing is sent in
+// read-only mode.
+func SubmitToPlugins(ctx context.Context, plugins []plugin.Plugin, db *store.DB, entries []store.Entry, out io.Writer) {
+	if db.ReadOnly() {
+		return
+	}
+	var logged []plugin.Entry
+	for _, e := range entries {
+		if e.Status != "logged" {
+			continue
+		}
+		logged = append(logged, plugin.Entry{
+			ClockifyID:  e.ClockifyID,
+			ProjectID:   e.ProjectID,
+			ProjectName: e.ProjectName,
+			ClientName:  e.ClientName,
+			Description: e.Description,
+			Start:       e.StartTime,
+			End:         e.EndTime,
+			Minutes:     e.Minutes,
+		})
+	}
+	if len(logged) == 0 {
+		return
+	}
+
+	for _, p := range plugins {
+		if !p.Can(plugin.ActionSubmit) {
+			continue
+		}
+		results, err := p.Submit(ctx, logged)
+		if err != nil {
+			fmt.Fprintf(out, "Warning: %v\n", err)
+			continue
+		}
+		failed := 0
+		for i, r := range results {
+			if r.Error != "" {
+				failed++
+				fmt.Fprintf(out, "Warning: plugin %s rejected %s — %s: %s\n", p.Name, logged[i].ProjectName, logged[i].Description, r.Error)
+			}
+		}
+		fmt.Fprintf(out, "Sent %d entries to %s.\n", len(results)-failed, p.Name)
+	}
+}
@@ -0,0 +1,147 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/ai"
+	"github.com/christopherklint97/clockr/internal/audit"
+	"github.com/christopherklint97/clockr/internal/config"
+	"github.com/christopherklint97/clockr/internal/format"
+	"github.com/christopherklint97/clockr/internal/store"
+)
+
+// recurringCheckInterval is how often the scheduler looks for recurring
+// entries that have ended and are not logged yet.
+const recurringCheckInterval = time.Minute
+
+// RecurringStateKey records the last date a recurring entry was logged, so
+// it is logged once a day even across restarts.
+func RecurringStateKey(name string) string {
+	return "recurring_last:" + strings.ToLower(name)
+}
+
+func (s *Scheduler) recurringLoop(ctx context.Context) {
+	for {
+		s.logRecurring(ctx, s.now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(recurringCheckInterval):
+		}
+	}
+}
+
+// DueRecurring returns the recurring entries to log by now: enabled, not
+// suggest-only, happening today and already over.
+func DueRecurring(cfg *config.Config, now time.Time) []config.RecurringEntry {
+	var due []config.RecurringEntry
+	for _, r := range cfg.Recurring {
+		if r.Disabled || r.Suggest || !r.On(cfg.Schedule, now) {
+			continue
+		}
+		if _, end := r.At(now); !now.Before(end) {
+			due = append(due, r)
+		}
+	}
+	return due
+}
+
+// RecurringContext describes the suggest-only recurring entries that fall
+// inside start–end, as context items for the AI.
+func RecurringContext(cfg *config.Config, start, end time.Time) []ai.ContextItem {
+	var items []ai.ContextItem
+	for _, r := range cfg.Recurring {
+		if r.Disabled || !r.Suggest || !r.On(cfg.Schedule, start) {
+			continue
+		}
+		from, to := r.At(start)
+		if from.Before(end) && to.After(start) {
+			items = append(items, ai.ContextItem{
+				Source:  ai.SourceManual,
+				Text:    fmt.Sprintf("Recurring: %s on project %s", r.Text(), r.Project),
+				Time:    from,
+				Minutes: int(to.Sub(from).Minutes()),
+			})
+		}
+	}
+	return items
+}
+
+// logRecurring logs today's recurring entries that are due and not logged
+// yet. Nothing is logged while paused, on a holiday, or when the time is
+// already taken by another entry (e.g. the prompt was answered first).
+func (s *Scheduler) logRecurring(ctx context.Context, now time.Time) {
+	cfg := s.config()
+	if s.db.ReadOnly() {
+		return
+	}
+	due := DueRecurring(cfg, now)
+	if len(due) == 0 {
+		return
+	}
+	date := now.Format("2006-01-02")
+	var todo []config.RecurringEntry
+	for _, r := range due {
+		if last, _ := s.db.GetState(RecurringStateKey(r.Name)); last != date {
+			todo = append(todo, r)
+		}
+	}
+	if len(todo) == 0 {
+		return
+	}
+	if pause, _ := s.db.ActivePause(now); pause != nil {
+		return
+	}
+	if s.onHoliday(ctx, now) {
+		return
+	}
+
+	projects, err := s.backend.ListProjects(ctx)
+	if err != nil {
+		fmt.Printf("Warning: recurring entries: fetching projects: %v\n", err)
+		return
+	}
+	f := format.New(cfg.Format)
+
+	for _, r := range todo {
+		// Claim the day first, like the digest: a failure is reported once,
+		// not retried every minute, and the tick and recurringLoop can't both
+		// log the entry.
+		claimed, err := s.db.ClaimState(RecurringStateKey(r.Name), date)
+		if err != nil {
+			fmt.Printf("Warning: %v\n", err)
+			return
+		}
+		if !claimed {
+			continue
+		}
+		project, ok := ai.FindProject(projects, r.Project)
+		if !ok {
+			fmt.Printf("Warning: recurring entry %q: no project %q\n", r.Name, r.Project)
+			continue
+		}
+		start, end := r.At(now)
+		span := audit.Interval{Start: start, End: end}
+		if audit.Free(span, s.loggedIn(ctx, start, end)) < end.Sub(start) {
+			fmt.Printf("Recurring entry %q: %s–%s is already logged.\n", r.Name, start.Format("15:04"), end.Format("15:04"))
+			continue
+		}
+		alloc := ai.Allocation{
+			ProjectID:   project.ID,
+			ProjectName: project.Name,
+			ClientName:  project.ClientName,
+			Description: f.Description(project.ID, project.Name, project.ClientName, r.Text()),
+			Minutes:     r.Minutes,
+		}
+		entries, failed := SubmitAllocations(ctx, cfg, s.backend, s.db, s.mirrors(), []ai.Allocation{alloc}, start, end, "", 0, store.SourceRecurring, os.Stdout)
+		if failed > 0 {
+			fmt.Printf("Recurring entry %q saved locally; it will be retried.\n", r.Name)
+		} else if len(entries) > 0 {
+			fmt.Printf("Logged recurring %s — %s (%dmin) at %s.\n", project.Name, alloc.Description, r.Minutes, start.Format("15:04"))
+		}
+	}
+}
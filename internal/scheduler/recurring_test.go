@@ -0,0 +1,97 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/backend"
+	"github.com/christopherklint97/clockr/internal/clockify"
+	"github.com/christopherklint97/clockr/internal/config"
+	"github.com/christopherklint97/clockr/internal/store"
+)
+
+func TestDueRecurring(t *testing.T) {
+	cfg := &config.Config{
+		Schedule: config.ScheduleConfig{WorkDays: []int{1, 2, 3, 4, 5}},
+		Recurring: []config.RecurringEntry{
+			{Name: "standup", Time: "09:15", Minutes: 15},
+			{Name: "planning", Project: "Meetings", Time: "09:00", Minutes: 30, Suggest: true},
+			{Name: "off", Time: "08:00", Minutes: 15, Disabled: true},
+		},
+	}
+	at := func(h, m int) time.Time { return time.Date(2026, 3, 2, h, m, 0, 0, time.UTC) } // a Monday
+
+	if due := DueRecurring(cfg, at(9, 29)); len(due) != 0 {
+		t.Errorf("standup is due before it ended: %v", due)
+	}
+	if due := DueRecurring(cfg, at(9, 30)); len(due) != 1 || due[0].Name != "standup" {
+		t.Errorf("DueRecurring at 09:30 = %v, want only standup", due)
+	}
+	if due := DueRecurring(cfg, at(9, 30).AddDate(0, 0, 5)); len(due) != 0 {
+		t.Errorf("due on a Saturday: %v", due)
+	}
+
+	items := RecurringContext(cfg, at(9, 0), at(10, 0))
+	if len(items) != 1 || items[0].String() != "[manual 09:00–09:30, 30 min] Recurring: planning on project Meetings" {
+		t.Errorf("RecurringContext = %v", items)
+	}
+	if items := RecurringContext(cfg, at(10, 0), at(11, 0)); len(items) != 0 {
+		t.Errorf("planning is outside 10–11: %v", items)
+	}
+}
+
+// countingBackend has one project and counts the entries created.
+type countingBackend struct {
+	backend.Backend
+	created atomic.Int32
+}
+
+func (b *countingBackend) Name() string { return "Test" }
+
+func (b *countingBackend) ListProjects(context.Context) ([]clockify.Project, error) {
+	time.Sleep(20 * time.Millisecond) // lets concurrent callers all find the entry due
+	return []clockify.Project{{ID: "p1", Name: "Meetings"}}, nil
+}
+
+func (b *countingBackend) ListEntries(context.Context, time.Time, time.Time) ([]clockify.TimeEntry, error) {
+	return nil, nil
+}
+
+func (b *countingBackend) CreateEntry(_ context.Context, e clockify.TimeEntryRequest) (*clockify.TimeEntry, error) {
+	time.Sleep(20 * time.Millisecond) // lets concurrent callers all find the time free
+	n := b.created.Add(1)
+	return &clockify.TimeEntry{ID: string(rune('a' + n))}, nil
+}
+
+func TestLogRecurring_Once(t *testing.T) {
+	t.Setenv("CLOCKR_HOME", t.TempDir())
+	db, err := store.Open(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.Schedule.WorkDays = []int{1, 2, 3, 4, 5}
+	cfg.Recurring = []config.RecurringEntry{{Name: "standup", Project: "Meetings", Time: "09:15", Minutes: 15}}
+	b := &countingBackend{}
+	s := New(&cfg, b, db, nil)
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC) // a Monday
+
+	// The tick and recurringLoop can run at the same moment.
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.logRecurring(context.Background(), now)
+		}()
+	}
+	wg.Wait()
+	if n := b.created.Load(); n != 1 {
+		t.Errorf("standup logged %d times, want 1", n)
+	}
+}
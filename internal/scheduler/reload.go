@@ -0,0 +1,131 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/config"
+)
+
+// configPollInterval is how often the running scheduler checks config.toml
+// for changes.
+const configPollInterval = 2 * time.Second
+
+// Reload re-reads config.toml and applies it to the running scheduler. The
+// next tick is realigned to the new interval. Invalid configs are rejected
+// and the current config stays in effect. It returns a summary of what
+// changed.
+func (s *Scheduler) Reload() (string, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return "", fmt.Errorf("config not reloaded: %w", err)
+	}
+	old := s.config()
+	s.setConfig(cfg)
+	return describeConfigChanges(old, cfg), nil
+}
+
+// watchConfig reloads the config whenever config.toml's modification time
+// changes, until ctx is done.
+func (s *Scheduler) watchConfig(ctx context.Context) {
+	path, err := config.ConfigPath()
+	if err != nil {
+		return
+	}
+	last := modTime(path)
+
+	ticker := time.NewTicker(configPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		mt := modTime(path)
+		if mt.Equal(last) || mt.IsZero() {
+			continue
+		}
+		last = mt
+
+		summary, err := s.Reload()
+		if err != nil {
+			fmt.Printf("Warning: %v\n", err)
+			continue
+		}
+		fmt.Printf("Config reloaded: %s\n", summary)
+	}
+}
+
+func modTime(path string) time.Time {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return fi.ModTime()
+}
+
+// describeConfigChanges lists the settings that differ between old and cur,
+// flagging those that only take effect after a restart.
+func describeConfigChanges(old, cur *config.Config) string {
+	var changes []string
+	add := func(name string, from, to any) {
+		changes = append(changes, fmt.Sprintf("%s %v → %v", name, from, to))
+	}
+
+	if from, to := old.Schedule.IntervalSummary(), cur.Schedule.IntervalSummary(); from != to {
+		add("interval", from, to)
+	}
+	if from, to := old.Schedule.HoursSummary(), cur.Schedule.HoursSummary(); from != to {
+		add("work hours", from, to)
+	}
+	if !slices.Equal(old.Schedule.WorkDays, cur.Schedule.WorkDays) {
+		add("work days", old.Schedule.WorkDays, cur.Schedule.WorkDays)
+	}
+	if old.Schedule.Timezone != cur.Schedule.Timezone {
+		add("time zone", zoneOrSystem(old.Schedule.Timezone), zoneOrSystem(cur.Schedule.Timezone))
+	}
+	if !slices.Equal(old.Schedule.Holidays, cur.Schedule.Holidays) || old.Schedule.HolidayCalendar != cur.Schedule.HolidayCalendar {
+		changes = append(changes, "holidays")
+	}
+	if old.Schedule.AutoAcceptSeconds != cur.Schedule.AutoAcceptSeconds || old.Schedule.AutoAcceptConfidence != cur.Schedule.AutoAcceptConfidence {
+		add("auto-accept", fmt.Sprintf("%ds@%g", old.Schedule.AutoAcceptSeconds, old.Schedule.AutoAcceptConfidence), fmt.Sprintf("%ds@%g", cur.Schedule.AutoAcceptSeconds, cur.Schedule.AutoAcceptConfidence))
+	}
+	if old.Notifications.Enabled != cur.Notifications.Enabled {
+		add("notifications", old.Notifications.Enabled, cur.Notifications.Enabled)
+	}
+	if !slices.Equal(old.Notifications.SnoozeOptions, cur.Notifications.SnoozeOptions) {
+		add("snooze options", old.Notifications.SnoozeOptions, cur.Notifications.SnoozeOptions)
+	}
+	if !reflect.DeepEqual(old.Format, cur.Format) {
+		changes = append(changes, "description format")
+	}
+	if !reflect.DeepEqual(old.Coverage, cur.Coverage) {
+		changes = append(changes, "break coverage")
+	}
+	if old.Calendar.Enabled != cur.Calendar.Enabled || old.Calendar.Source != cur.Calendar.Source {
+		changes = append(changes, "calendar settings")
+	}
+
+	if old.Clockify != cur.Clockify || !reflect.DeepEqual(old.AI, cur.AI) || old.ReadOnly != cur.ReadOnly {
+		changes = append(changes, "credentials/AI/read-only changes need a restart")
+	}
+
+	if len(changes) == 0 {
+		return "no changes"
+	}
+	return strings.Join(changes, ", ")
+}
+
+func zoneOrSystem(name string) string {
+	if name == "" {
+		return "system"
+	}
+	return name
+}
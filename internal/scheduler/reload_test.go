@@ -0,0 +1,56 @@
+package scheduler
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/christopherklint97/clockr/internal/config"
+)
+
+func TestDescribeConfigChanges(t *testing.T) {
+	old := config.DefaultConfig()
+	cur := config.DefaultConfig()
+	if got := describeConfigChanges(&old, &cur); got != "no changes" {
+		t.Errorf("got %q, want no changes", got)
+	}
+
+	cur.Schedule.IntervalMinutes = 30
+	cur.AI.Model = "other/model"
+	got := describeConfigChanges(&old, &cur)
+	if !strings.Contains(got, "interval 60m → 30m") || !strings.Contains(got, "need a restart") {
+		t.Errorf("unexpected summary %q", got)
+	}
+}
+
+func TestReload_KeepsConfigOnError(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("CLOCKR_HOME", dir)
+	t.Setenv("CLOCKR_NO_KEYCHAIN", "1")
+	t.Setenv("CLOCKIFY_API_KEY", "key")
+	path := filepath.Join(dir, "config.toml")
+
+	cfg := config.DefaultConfig()
+	s := New(&cfg, nil, nil, nil)
+
+	if err := os.WriteFile(path, []byte("[schedule]\ninterval_minutes = 0\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Reload(); err == nil {
+		t.Fatal("expected invalid config to be rejected")
+	}
+	if s.interval().Minutes() != 60 {
+		t.Errorf("interval changed after failed reload: %s", s.interval())
+	}
+
+	if err := os.WriteFile(path, []byte("[schedule]\ninterval_minutes = 15\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if s.interval().Minutes() != 15 {
+		t.Errorf("got interval %s, want 15m", s.interval())
+	}
+}
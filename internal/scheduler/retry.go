@@ -0,0 +1,129 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/backend"
+	"github.com/christopherklint97/clockr/internal/clockify"
+	"github.com/christopherklint97/clockr/internal/metrics"
+	"github.com/christopherklint97/clockr/internal/store"
+)
+
+const (
+	// retryLease is how long a claimed entry is reserved for one attempt.
+	// It must outlast a full doRequest cycle (timeouts plus backoff).
+	retryLease = 3 * time.Minute
+
+	minRetryDelay = 1 * time.Minute
+	maxRetryDelay = 30 * time.Minute
+)
+
+// RetryResult summarizes one pass over the failed entries.
+type RetryResult struct {
+	Succeeded int
+	Failed    int
+	Skipped   int // claimed by another process or attempted too recently
+}
+
+// Remaining reports whether any entries are still waiting to be submitted.
+func (r RetryResult) Remaining() bool {
+	return r.Failed > 0 || r.Skipped > 0
+}
+
+// RetryFailed re-submits every failed entry to Clockify, writing progress to
+// out. Entries are claimed in the DB first so the scheduler, `clockr log` and
+// `clockr retry` can all run this concurrently without creating duplicates.
+func RetryFailed(ctx context.Context, b backend.Backend, db *store.DB, out io.Writer) (RetryResult, error) {
+	var res RetryResult
+	if db.ReadOnly() {
+		return res, nil
+	}
+
+	entries, err := db.GetFailedEntries()
+	if err != nil {
+		return res, fmt.Errorf("fetching failed entries: %w", err)
+	}
+	if len(entries) == 0 {
+		return res, nil
+	}
+
+	fmt.Fprintf(out, "Retrying %d failed entries...\n", len(entries))
+	for _, e := range entries {
+		if ctx.Err() != nil {
+			res.Skipped++
+			continue
+		}
+
+		claimed, err := db.ClaimRetry(e.ID, retryLease)
+		if err != nil {
+			fmt.Fprintf(out, "  Could not claim entry %d: %v\n", e.ID, err)
+			res.Failed++
+			continue
+		}
+		if !claimed {
+			res.Skipped++
+			continue
+		}
+
+		entry := clockify.TimeEntryRequest{
+			Start:       e.StartTime.UTC().Format("2006-01-02T15:04:05Z"),
+			End:         e.EndTime.UTC().Format("2006-01-02T15:04:05Z"),
+			ProjectID:   e.ProjectID,
+			Description: e.Description,
+			TagIDs:      e.TagIDs,
+		}
+
+		created, err := b.CreateEntry(ctx, entry)
+		if err != nil {
+			metrics.EntrySubmitted("failed")
+			fmt.Fprintf(out, "  Retry failed for entry %d: %s\n", e.ID, clockify.FriendlyError(err))
+			res.Failed++
+			continue
+		}
+
+		if err := db.UpdateEntryStatus(e.ID, "logged", created.ID); err != nil {
+			fmt.Fprintf(out, "  Failed to update entry %d status: %v\n", e.ID, err)
+			res.Failed++
+			continue
+		}
+		metrics.EntrySubmitted("logged")
+		db.UpdateEntryClockifyDetails(e.ID, created.TaskID, created.TagIDs, created.Billable)
+
+		fmt.Fprintf(out, "  Retried entry %d successfully\n", e.ID)
+		res.Succeeded++
+	}
+
+	return res, nil
+}
+
+// retryLoop periodically retries failed entries and breaks, and mirror
+// copies that have not been made yet, in the background, backing off
+// exponentially while entries keep failing. Output is discarded so it doesn't interfere with a
+// TUI that may be running.
+func (s *Scheduler) retryLoop(ctx context.Context) {
+	delay := minRetryDelay
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+
+		res, err := RetryFailed(ctx, s.backend, s.db, io.Discard)
+		breaks, berr := RetryBreaks(ctx, s.config(), s.backend, s.db, io.Discard)
+		s.mirrors().Retry(ctx, s.db)
+		delay = nextRetryDelay(delay, err == nil && berr == nil && !res.Remaining() && !breaks.Remaining())
+	}
+}
+
+// nextRetryDelay doubles the delay while entries remain, capped at
+// maxRetryDelay, and resets to minRetryDelay once the queue is empty.
+func nextRetryDelay(current time.Duration, drained bool) time.Duration {
+	if drained {
+		return minRetryDelay
+	}
+	return min(current*2, maxRetryDelay)
+}
@@ -0,0 +1,77 @@
+package scheduler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/metrics"
+	"github.com/christopherklint97/clockr/internal/store"
+)
+
+func TestRetryFailed_CountsSubmissions(t *testing.T) {
+	db := testHome(t)
+	metrics.Reset()
+	t.Cleanup(metrics.Reset)
+
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	e := store.Entry{ProjectID: "p1", Description: "work", StartTime: start, EndTime: start.Add(time.Hour), Minutes: 60, Status: "failed"}
+	if _, err := db.InsertEntry(&e); err != nil {
+		t.Fatal(err)
+	}
+	// Storing the failed entry is not a submission.
+	assertCounters(t, 0, 0)
+
+	b := &offlineBackend{offline: true}
+	if res, err := RetryFailed(context.Background(), b, db, io.Discard); err != nil || res.Failed != 1 {
+		t.Fatalf("RetryFailed offline = %+v, %v", res, err)
+	}
+	assertCounters(t, 0, 1)
+
+	if _, err := db.Exec("UPDATE entries SET last_attempt_at = NULL"); err != nil {
+		t.Fatal(err)
+	}
+	b.offline = false
+	if res, err := RetryFailed(context.Background(), b, db, io.Discard); err != nil || res.Succeeded != 1 {
+		t.Fatalf("RetryFailed = %+v, %v", res, err)
+	}
+	assertCounters(t, 1, 1)
+}
+
+func TestRetryFailed_KeepsOvertimeTag(t *testing.T) {
+	db := testHome(t)
+	start := time.Date(2026, 3, 7, 19, 0, 0, 0, time.UTC)
+	e := store.Entry{ProjectID: "p1", Description: "release", StartTime: start, EndTime: start.Add(time.Hour), Minutes: 60,
+		Status: "failed", Overtime: true, TagIDs: []string{"tag-overtime"}}
+	if _, err := db.InsertEntry(&e); err != nil {
+		t.Fatal(err)
+	}
+
+	b := &offlineBackend{}
+	if res, err := RetryFailed(context.Background(), b, db, io.Discard); err != nil || res.Succeeded != 1 {
+		t.Fatalf("RetryFailed = %+v, %v", res, err)
+	}
+	if len(b.created) != 1 || !reflect.DeepEqual(b.created[0].TagIDs, []string{"tag-overtime"}) {
+		t.Errorf("created %+v, want the overtime tag", b.created)
+	}
+}
+
+// assertCounters checks the entries logged and failed metrics.
+func assertCounters(t *testing.T, logged, failed int) {
+	t.Helper()
+	var buf bytes.Buffer
+	metrics.Write(&buf)
+	for _, want := range []string{
+		fmt.Sprintf("clockr_entries_logged_total %d\n", logged),
+		fmt.Sprintf("clockr_entries_failed_total %d\n", failed),
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("metrics lack %q", strings.TrimSpace(want))
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/metrics"
+	"github.com/christopherklint97/clockr/internal/store"
+)
+
+// SkipWindow records the pending window, or the last interval up to now if
+// there is none, as deliberately untracked and clears it so it is not
+// offered again. It is the scriptable counterpart of skipping in the TUI.
+func SkipWindow(db *store.DB, interval time.Duration, reason string) (store.Skip, error) {
+	start, end := mergeWindow(nil, time.Now(), interval)
+	if pending := loadPendingWindow(db); pending != nil {
+		start, end = pending.Start, pending.End
+	}
+
+	skip := store.Skip{
+		StartTime: start,
+		EndTime:   end,
+		Minutes:   int(end.Sub(start).Minutes()),
+		Reason:    reason,
+	}
+	if _, err := db.InsertSkip(&skip); err != nil {
+		return skip, err
+	}
+	if err := clearPendingWindow(db); err != nil {
+		return skip, fmt.Errorf("clearing pending window: %w", err)
+	}
+	db.AnswerPrompts(start, end)
+	return skip, nil
+}
+
+// SkippedMessage is printed after a prompt is skipped.
+func SkippedMessage(reason string) string {
+	if reason == "" {
+		return "Entry skipped."
+	}
+	return fmt.Sprintf("Entry skipped (%s).", reason)
+}
+
+// recordSkip stores a skipped window, warning on failure.
+func (s *Scheduler) recordSkip(start, end time.Time, reason string) {
+	if s.db.ReadOnly() {
+		return
+	}
+	skip := store.Skip{
+		StartTime: start,
+		EndTime:   end,
+		Minutes:   int(end.Sub(start).Minutes()),
+		Reason:    reason,
+	}
+	if _, err := s.db.InsertSkip(&skip); err != nil {
+		fmt.Printf("Warning: could not record skip: %v\n", err)
+		return
+	}
+	metrics.PromptSkipped()
+	s.db.AnswerPrompts(start, end)
+	s.logBreak(context.Background(), &skip)
+}
@@ -0,0 +1,177 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/ai"
+	"github.com/christopherklint97/clockr/internal/clockify"
+	"github.com/christopherklint97/clockr/internal/slack"
+	"github.com/christopherklint97/clockr/internal/store"
+)
+
+// slackSession is the window last announced in Slack and the suggestion
+// waiting for confirmation, if any.
+type slackSession struct {
+	start, end time.Time
+	rawInput   string
+	suggestion *ai.Suggestion
+}
+
+// announceSlack sends the prompt for the window as a Slack DM. The window is
+// marked pending first: whichever of Slack and the terminal answers it
+// clears it, which tells the other one it is done.
+func (s *Scheduler) announceSlack(ctx context.Context, start, end time.Time) {
+	cfg := s.config().Slack
+	if !cfg.Enabled || s.db.ReadOnly() {
+		return
+	}
+	s.markPending(start, end)
+
+	s.slackMu.Lock()
+	s.slack = &slackSession{start: start, end: end}
+	s.slackMu.Unlock()
+
+	text := fmt.Sprintf("What did you work on %s–%s? Reply here to log it, or *skip*.", start.Format("15:04"), end.Format("15:04"))
+	if err := slack.NewClient(cfg.BotToken).SendDM(ctx, cfg.UserID, text); err != nil {
+		fmt.Printf("Warning: could not send Slack prompt: %v\n", err)
+	}
+}
+
+// slackLoop serves the Events API endpoint that receives Slack replies until
+// ctx is done. It only starts when [slack] is enabled at startup.
+func (s *Scheduler) slackLoop(ctx context.Context) {
+	cfg := s.config().Slack
+	if !cfg.Enabled {
+		return
+	}
+
+	handler := slack.NewHandler(cfg.SigningSecret, cfg.UserID, func(text string) {
+		s.handleSlackReply(ctx, text)
+	})
+	mux := http.NewServeMux()
+	mux.Handle("/slack/events", handler)
+	srv := &http.Server{Addr: cfg.Addr(), Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+	}()
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fmt.Printf("Warning: Slack events endpoint: %v\n", err)
+	}
+}
+
+// handleSlackReply answers one DM: "skip" skips the window, "ok" logs the
+// pending suggestion, and anything else is a description for the AI.
+// Replies are handled one at a time.
+func (s *Scheduler) handleSlackReply(ctx context.Context, text string) {
+	s.slackMu.Lock()
+	defer s.slackMu.Unlock()
+
+	cfg := s.config()
+	reply := func(msg string) {
+		if err := slack.NewClient(cfg.Slack.BotToken).SendDM(ctx, cfg.Slack.UserID, msg); err != nil {
+			fmt.Printf("Warning: could not reply in Slack: %v\n", err)
+		}
+	}
+
+	sess := s.slack
+	pending := loadPendingWindow(s.db)
+	if sess == nil || pending == nil || !pending.Start.Equal(sess.start) {
+		s.slack = nil
+		reply("Nothing is waiting to be logged. I'll message you at the next prompt.")
+		return
+	}
+	if s.db.ReadOnly() {
+		reply("clockr is read-only; nothing was logged.")
+		return
+	}
+	window := fmt.Sprintf("%s–%s", sess.start.Format("15:04"), sess.end.Format("15:04"))
+
+	switch strings.ToLower(strings.TrimSpace(text)) {
+	case "skip":
+		s.recordSkip(sess.start, sess.end, "")
+		s.finishSlack()
+		reply("Skipped " + window + ".")
+		return
+	case "ok", "yes", "y":
+		if sess.suggestion == nil {
+			reply("Tell me what you worked on " + window + " first.")
+			return
+		}
+		entries, failed := s.submitSlack(ctx, sess)
+		s.finishSlack()
+		var b strings.Builder
+		fmt.Fprintf(&b, "Logged %s:\n", window)
+		for _, e := range entries {
+			fmt.Fprintf(&b, "• %s — %s (%dmin)", e.ProjectName, e.Description, e.Minutes)
+			if e.Status == "failed" {
+				b.WriteString(" _failed, will retry_")
+			}
+			b.WriteString("\n")
+		}
+		if failed > 0 {
+			fmt.Fprintf(&b, "%d entries could not reach Clockify; the scheduler keeps retrying them.", failed)
+		}
+		reply(strings.TrimSpace(b.String()))
+		return
+	}
+
+	suggestion, err := Suggest(ctx, cfg, s.provider, s.backend, s.db, text, sess.start, sess.end)
+	if err != nil {
+		reply("Could not match that: " + clockify.FriendlyError(err))
+		return
+	}
+	if len(suggestion.Allocations) == 0 {
+		if suggestion.Clarification != "" {
+			reply(suggestion.Clarification)
+		} else {
+			reply("I couldn't match that to a project. Try describing it differently.")
+		}
+		return
+	}
+
+	sess.rawInput = text
+	sess.suggestion = suggestion
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "For %s:\n", window)
+	for _, a := range suggestion.Allocations {
+		fmt.Fprintf(&b, "• %s — %s (%dmin)\n", a.ProjectName, a.Description, a.Minutes)
+	}
+	b.WriteString("Reply *ok* to log this, describe it again to change it, or *skip*.")
+	reply(b.String())
+}
+
+// finishSlack ends the Slack session and closes a terminal prompt that is
+// still open for the same window.
+func (s *Scheduler) finishSlack() {
+	s.db.AnswerPrompts(s.slack.start, s.slack.end)
+	s.slack = nil
+	if err := clearPendingWindow(s.db); err != nil {
+		fmt.Printf("Warning: could not clear pending window: %v\n", err)
+	}
+	s.mu.Lock()
+	p := s.program
+	s.mu.Unlock()
+	if p != nil {
+		p.Quit()
+	}
+}
+
+// submitSlack logs the confirmed suggestion and returns the entries with the
+// number that failed to reach Clockify.
+func (s *Scheduler) submitSlack(ctx context.Context, sess *slackSession) ([]store.Entry, int) {
+	entries, failed := SubmitAllocations(ctx, s.config(), s.backend, s.db, s.mirrors(), sess.suggestion.Allocations, sess.start, sess.end, sess.rawInput,
+		RecordSuggestion(s.db, s.provider, sess.rawInput, sess.suggestion, nil), store.SourceSlack, os.Stdout)
+	for _, e := range entries {
+		fmt.Printf("Logged from Slack: %s — %s (%dmin) [%s]\n", e.ProjectName, e.Description, e.Minutes, e.Status)
+	}
+	return entries, failed
+}
@@ -0,0 +1,128 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/ai"
+	"github.com/christopherklint97/clockr/internal/backend"
+	"github.com/christopherklint97/clockr/internal/clockify"
+	"github.com/christopherklint97/clockr/internal/config"
+	"github.com/christopherklint97/clockr/internal/format"
+	"github.com/christopherklint97/clockr/internal/metrics"
+	"github.com/christopherklint97/clockr/internal/mirror"
+	"github.com/christopherklint97/clockr/internal/store"
+)
+
+// Suggest asks the AI to allocate a description over the window, outside the
+// TUI (Slack replies, `clockr serve`). Descriptions and minutes are run through the
+// [format] rules, as the TUI does. Projects come from db's cache when it has them; db may be nil.
+func Suggest(ctx context.Context, cfg *config.Config, provider ai.Provider, b backend.Backend, db *store.DB, description string, start, end time.Time) (*ai.Suggestion, error) {
+	var cache clockify.CacheStore
+	if db != nil {
+		cache = db
+	}
+	projects, err := backend.Projects(ctx, b, cache)
+	if err != nil {
+		return nil, fmt.Errorf("fetching projects: %w", err)
+	}
+	aiCtx, cancel := context.WithTimeout(ctx, cfg.Timeouts.AI())
+	defer cancel()
+	suggestion, err := provider.MatchProjects(aiCtx, description, projects, end.Sub(start), nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("matching projects: %w", err)
+	}
+
+	f := format.New(cfg.Format)
+	minutes := make([]int, len(suggestion.Allocations))
+	for i, a := range suggestion.Allocations {
+		suggestion.Allocations[i].Description = f.Description(a.ProjectID, a.ProjectName, a.ClientName, a.Description)
+		minutes[i] = a.Minutes
+	}
+	for i, m := range format.Rounding(cfg.Format).ApplyCumulative(minutes) {
+		suggestion.Allocations[i].Minutes = m
+	}
+	return suggestion, nil
+}
+
+// RecordSuggestion stores the AI's suggestion, and the clarification
+// questions answered on the way to it, for 'clockr entry show' and returns
+// the ID to log its entries with, or 0 when it could not be stored.
+func RecordSuggestion(db *store.DB, provider ai.Provider, rawInput string, suggestion *ai.Suggestion, clarifications []ai.Exchange) int {
+	id, err := db.SaveEntrySuggestion(rawInput, suggestion, clarifications, ai.PromptVersion(), ai.ModelName(provider))
+	if err != nil {
+		return 0
+	}
+	return id
+}
+
+// SubmitAllocations logs allocations back to back from start, capped at end,
+// like the TUI does, then hands them to plugins and calendar write-back. The
+// entries are linked to suggestionID (see RecordSuggestion; 0 for none) and
+// recorded with source (a store.Source constant), and rawInput joins the
+// Ctrl+R history. It returns the stored entries and how many failed to reach
+// Clockify; those are left for RetryFailed.
+func SubmitAllocations(ctx context.Context, cfg *config.Config, b backend.Backend, db *store.DB, mirrors *mirror.Mirrors, allocs []ai.Allocation, start, end time.Time, rawInput string, suggestionID int, source string, out io.Writer) ([]store.Entry, int) {
+	if err := db.AddRawInput(rawInput); err != nil {
+		fmt.Fprintf(out, "Warning: could not save input history: %v\n", err)
+	}
+	var entries []store.Entry
+	failed := 0
+	for _, alloc := range allocs {
+		allocEnd := start.Add(time.Duration(alloc.Minutes) * time.Minute)
+		if allocEnd.After(end) {
+			allocEnd = end
+		}
+		e := store.Entry{
+			ProjectID:    alloc.ProjectID,
+			ProjectName:  alloc.ProjectName,
+			ClientName:   alloc.ClientName,
+			Description:  alloc.Description,
+			StartTime:    start,
+			EndTime:      allocEnd,
+			Minutes:      alloc.Minutes,
+			RawInput:     rawInput,
+			SuggestionID: suggestionID,
+			Source:       source,
+			IssueKey:     alloc.IssueKey,
+		}
+		parts := []store.Entry{e}
+		if cfg.Schedule.SplitAtMidnight() {
+			parts = store.SplitAtMidnight(e)
+		}
+		for _, part := range parts {
+			created, err := b.CreateEntry(ctx, clockify.TimeEntryRequest{
+				Start:       part.StartTime.UTC().Format("2006-01-02T15:04:05Z"),
+				End:         part.EndTime.UTC().Format("2006-01-02T15:04:05Z"),
+				ProjectID:   part.ProjectID,
+				Description: part.Description,
+			})
+			part.Status = "logged"
+			if err != nil {
+				part.Status = "failed"
+				failed++
+			} else {
+				part.ClockifyID = created.ID
+				part.TaskID, part.TagIDs, part.Billable = created.TaskID, created.TagIDs, created.Billable
+			}
+			metrics.EntrySubmitted(part.Status)
+			if _, err := db.InsertEntry(&part); err != nil {
+				fmt.Fprintf(out, "Warning: could not save entry: %v\n", err)
+			}
+			entries = append(entries, part)
+		}
+		start = allocEnd
+	}
+
+	SubmitToPlugins(ctx, DiscoverPlugins(ctx, out), db, entries, out)
+	if _, err := WriteBack(ctx, cfg, db, entries, slog.Default()); err != nil {
+		fmt.Fprintf(out, "Warning: calendar write-back failed: %v\n", err)
+	}
+	if _, err := mirrors.Sync(ctx, db, entries); err != nil {
+		fmt.Fprintf(out, "Warning: %v\n", err)
+	}
+	return entries, failed
+}
@@ -3,38 +3,72 @@ package scheduler
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"net"
 	"os"
 	"path/filepath"
 	"strconv"
+	"sync"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/christopherklint97/clockr/internal/ai"
+	"github.com/christopherklint97/clockr/internal/audit"
+	"github.com/christopherklint97/clockr/internal/backend"
 	"github.com/christopherklint97/clockr/internal/calendar"
-	"github.com/christopherklint97/clockr/internal/clockify"
 	"github.com/christopherklint97/clockr/internal/config"
+	"github.com/christopherklint97/clockr/internal/format"
+	"github.com/christopherklint97/clockr/internal/metrics"
+	"github.com/christopherklint97/clockr/internal/mirror"
+	"github.com/christopherklint97/clockr/internal/sources"
 	"github.com/christopherklint97/clockr/internal/store"
 	"github.com/christopherklint97/clockr/internal/tui"
 )
 
 type Scheduler struct {
-	cfg               *config.Config
-	client            *clockify.Client
+	backend           backend.Backend
 	db                *store.DB
 	provider          ai.Provider
-	workspaceID       string
 	skipWorkTimeCheck bool
 	tmuxTarget        *TmuxTarget
+
+	// triggerCh asks the run loop to prompt now; wakeCh makes it recompute
+	// the next tick after a pause, resume or config reload.
+	triggerCh chan struct{}
+	wakeCh    chan struct{}
+
+	// mu guards the fields below, which the control socket reads and changes.
+	mu        sync.Mutex
+	cfg       *config.Config
+	mirror    *mirror.Mirrors // opened from cfg, reopened on reload
+	cancel    context.CancelFunc
+	startedAt time.Time
+	nextTick  time.Time
+	prompting bool
+	program   *tea.Program // the open prompt TUI, closed when Slack answers first
+	// zone is the name of the time zone the last tick was computed in, used
+	// to notice when the system zone changes (e.g. after travel).
+	zone string
+
+	holidays holidayCalendar
+
+	// slackMu serializes Slack replies and guards slack.
+	slackMu sync.Mutex
+	slack   *slackSession
+
+	debugAddr string // serves pprof and expvar when set
 }
 
-func New(cfg *config.Config, client *clockify.Client, db *store.DB, provider ai.Provider, workspaceID string) *Scheduler {
+func New(cfg *config.Config, b backend.Backend, db *store.DB, provider ai.Provider) *Scheduler {
 	return &Scheduler{
-		cfg:         cfg,
-		client:      client,
-		db:          db,
-		provider:    provider,
-		workspaceID: workspaceID,
-		tmuxTarget:  DetectTmuxTarget(),
+		cfg:        cfg,
+		mirror:     mirror.New(cfg, slog.Default()),
+		backend:    b,
+		db:         db,
+		provider:   provider,
+		tmuxTarget: DetectTmuxTarget(),
+		triggerCh:  make(chan struct{}, 1),
+		wakeCh:     make(chan struct{}, 1),
 	}
 }
 
@@ -43,42 +77,139 @@ func (s *Scheduler) SetSkipWorkTimeCheck(skip bool) {
 }
 
 func (s *Scheduler) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	s.mu.Lock()
+	s.cancel = cancel
+	s.startedAt = time.Now()
+	s.mu.Unlock()
+
 	if err := s.writePID(); err != nil {
 		return fmt.Errorf("writing PID file: %w", err)
 	}
 	defer s.removePID()
 
-	// Retry any failed entries from previous runs
-	s.retryFailed(ctx)
+	l, err := listenControl()
+	if err != nil {
+		return fmt.Errorf("opening control socket: %w", err)
+	}
+	defer os.Remove(l.Addr().String())
+	go s.serveControl(ctx, l)
 
-	interval := time.Duration(s.cfg.Schedule.IntervalMinutes) * time.Minute
+	if s.debugAddr != "" {
+		dl, err := listenDebug(s.debugAddr)
+		if err != nil {
+			return err
+		}
+		go s.serveDebug(ctx, dl)
+		fmt.Printf("Debug endpoints on http://%s/debug/pprof/ and /debug/vars\n", dl.Addr())
+	}
+	if m := s.config().Metrics; m.Enabled {
+		ml, err := net.Listen("tcp", m.Addr())
+		if err != nil {
+			return fmt.Errorf("opening metrics address: %w", err)
+		}
+		go serveHTTP(ctx, ml, metricsHandler())
+		fmt.Printf("Metrics on http://%s/metrics\n", ml.Addr())
+	}
+
+	// Retry any failed entries from previous runs, then keep retrying in the
+	// background so entries created while offline converge.
+	if _, err := RetryFailed(ctx, s.backend, s.db, os.Stdout); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+	if _, err := RetryBreaks(ctx, s.config(), s.backend, s.db, os.Stdout); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+	if _, err := s.mirrors().Retry(ctx, s.db); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+	go s.retryLoop(ctx)
+	go s.watchConfig(ctx)
+	go s.digestLoop(ctx)
+	go s.recurringLoop(ctx)
+	go s.slackLoop(ctx)
 
+	cfg := s.config()
 	if s.skipWorkTimeCheck {
-		fmt.Printf("Scheduler started (interval: %s, work hours overridden)\n", interval)
+		fmt.Printf("Scheduler started (interval: %s, work hours overridden)\n", cfg.Schedule.IntervalSummary())
 	} else {
-		fmt.Printf("Scheduler started (interval: %s, hours: %s–%s)\n",
-			interval, s.cfg.Schedule.WorkStart, s.cfg.Schedule.WorkEnd)
+		fmt.Printf("Scheduler started (interval: %s, hours: %s)\n",
+			cfg.Schedule.IntervalSummary(), cfg.Schedule.HoursSummary())
 	}
 
 	for {
-		nextTick := s.nextAlignedTick(time.Now(), interval)
+		now := s.now()
+		nextTick := s.nextAlignedTick(now, s.intervalOn(now))
+		// The window ending at the tick is as long as that day's interval,
+		// even when the tick was computed the evening before.
+		interval := s.intervalOn(nextTick)
+		s.mu.Lock()
+		s.nextTick = nextTick
+		s.mu.Unlock()
 		fmt.Printf("Next prompt at %s\n", nextTick.Format("15:04"))
 
+		timer := time.NewTimer(time.Until(nextTick))
 		select {
 		case <-ctx.Done():
+			timer.Stop()
 			fmt.Println("\nScheduler stopped.")
 			return nil
-		case <-time.After(time.Until(nextTick)):
+		case <-s.wakeCh:
+			timer.Stop()
+			continue
+		case <-s.triggerCh:
+			timer.Stop()
+			s.setPrompting(true)
+			s.PromptNow(ctx)
+			s.setPrompting(false)
+			continue
+		case <-timer.C:
 		}
 
-		if !s.skipWorkTimeCheck && !s.isWorkTime(time.Now()) {
+		now = s.now()
+		if pause, _ := s.db.ActivePause(now); pause != nil {
+			fmt.Println("Paused — skipping prompt.")
+			continue
+		}
+		if !s.skipWorkTimeCheck && !s.isWorkTime(now) {
+			continue
+		}
+		if !s.skipWorkTimeCheck && s.onHoliday(ctx, now) {
+			fmt.Println("Holiday — skipping prompt.")
 			continue
 		}
 
+		s.setPrompting(true)
 		s.prompt(ctx, nextTick, interval)
+		s.setPrompting(false)
 	}
 }
 
+// now returns the current time in the schedule's zone, reporting when that
+// zone differs from the one used for the previous tick.
+func (s *Scheduler) now() time.Time {
+	s.mu.Lock()
+	loc := s.cfg.Schedule.Location()
+	zone, prev := loc.String(), s.zone
+	s.zone = zone
+	s.mu.Unlock()
+	if prev != "" && prev != zone {
+		fmt.Printf("Time zone changed: %s → %s\n", prev, zone)
+	}
+	return time.Now().In(loc)
+}
+
+// interval returns today's prompt interval.
+func (s *Scheduler) interval() time.Duration {
+	return s.intervalOn(s.now())
+}
+
+// intervalOn returns the prompt interval for t's weekday.
+func (s *Scheduler) intervalOn(t time.Time) time.Duration {
+	return s.config().Schedule.IntervalFor(t.Weekday())
+}
+
 // showDialogWithSnooze shows the prompt dialog in a loop, handling snooze
 // internally. Returns only ActionLogNow or ActionNextTimer.
 func (s *Scheduler) showDialogWithSnooze(ctx context.Context) DialogAction {
@@ -87,7 +218,7 @@ func (s *Scheduler) showDialogWithSnooze(ctx context.Context) DialogAction {
 			ctx,
 			"clockr",
 			"What did you work on this hour?",
-			s.cfg.Notifications.SnoozeOptions,
+			s.config().Notifications.SnoozeOptions,
 		)
 		if err != nil {
 			// On error (including context cancellation), default to log now
@@ -108,59 +239,232 @@ func (s *Scheduler) showDialogWithSnooze(ctx context.Context) DialogAction {
 			return ActionLogNow
 		case <-snoozeTimer.C:
 		}
+		Attention(s.config().Notifications, s.tmuxTarget, "snooze is over, time to log your work", os.Stdout)
 	}
 }
 
 func (s *Scheduler) prompt(ctx context.Context, tickTime time.Time, interval time.Duration) {
-	if s.cfg.Notifications.Enabled {
+	pending := loadPendingWindow(s.db)
+	if pending != nil && !mergeable(s.config(), pending, tickTime, s.skipWorkTimeCheck) {
+		// Left over from an earlier day or work block: keep it out of this
+		// window but account for it.
+		fmt.Printf("Recording the unanswered window %s–%s as skipped.\n", pending.Start.Format("Mon 15:04"), pending.End.Format("15:04"))
+		s.recordSkip(pending.Start, pending.End, "unanswered")
+		if err := clearPendingWindow(s.db); err != nil {
+			fmt.Printf("Warning: could not clear pending window: %v\n", err)
+		}
+		pending = nil
+	}
+	startTime, endTime := mergeWindow(pending, tickTime, interval)
+	if !s.skipWorkTimeCheck {
+		startTime = clipToWorkBlock(s.config(), startTime, endTime)
+		if !startTime.Before(endTime) {
+			// The tick that opens a block covers only the time before it.
+			return
+		}
+	}
+	s.db.LogPrompt(tickTime)
+	metrics.PromptShown()
+	Attention(s.config().Notifications, s.tmuxTarget, "time to log your work", os.Stdout)
+	s.announceSlack(ctx, startTime, endTime)
+
+	if s.config().Notifications.Enabled {
+		// Record the window before notifying so `clockr prompt-now`, opened
+		// by clicking the notification, offers the same window.
+		s.markPending(startTime, endTime)
+
 		// Send a system notification first so the user gets a banner + sound
 		// even if the interactive dialog appears behind other windows.
-		_ = SendNotification("clockr", "Time to log your work!", s.tmuxTarget)
+		_ = SendNotification("clockr", "Time to log your work!", s.tmuxTarget, s.clickCommand())
 
 		action := s.showDialogWithSnooze(ctx)
+		if !s.db.ReadOnly() && loadPendingWindow(s.db) == nil {
+			fmt.Println("Window answered from another terminal or Slack.")
+			return
+		}
 		if action == ActionNextTimer {
+			s.restorePending(pending)
+			s.recordSkip(tickTime.Add(-interval), tickTime, "")
 			fmt.Println("Skipped to next timer.")
 			return
 		}
 	}
 
-	projects, err := s.client.GetProjects(ctx, s.workspaceID)
+	if pending != nil {
+		fmt.Printf("Re-offering unanswered window from %s\n", pending.Start.Format("15:04"))
+	}
+	s.runPrompt(ctx, startTime, endTime)
+}
+
+// PromptNow opens the prompt immediately, outside the schedule. It offers the
+// pending window if there is one (e.g. the one just announced by a
+// notification), otherwise the last interval up to now.
+func (s *Scheduler) PromptNow(ctx context.Context) {
+	startTime, endTime := mergeWindow(nil, s.now(), s.interval())
+	if pending := loadPendingWindow(s.db); pending != nil {
+		startTime, endTime = pending.Start, pending.End
+	}
+	s.runPrompt(ctx, startTime, endTime)
+}
+
+// clickCommand returns the command run when the notification is clicked, or
+// "" if clicking should not open a prompt.
+func (s *Scheduler) clickCommand() string {
+	cfg := s.config()
+	if !cfg.Notifications.OpenOnClick {
+		return ""
+	}
+	configPath, _ := config.ConfigPath()
+	return ClickCommand(cfg.Notifications.TerminalCommand, configPath)
+}
+
+func (s *Scheduler) runPrompt(ctx context.Context, startTime, endTime time.Time) {
+	// Show and store the window in the schedule's zone, even if it was saved
+	// as pending before the zone changed.
+	loc := s.config().Schedule.Location()
+	startTime, endTime = startTime.In(loc), endTime.In(loc)
+
+	projects, err := s.backend.ListProjects(ctx)
 	if err != nil {
 		fmt.Printf("Error fetching projects: %v\n", err)
+		s.markPending(startTime, endTime)
 		return
 	}
-	s.client.EnrichProjectsWithClients(ctx, s.workspaceID, projects)
 
-	startTime := tickTime.Add(-interval)
-	endTime := tickTime
+	// Log recurring entries that just ended first, so they are counted as
+	// logged below.
+	s.logRecurring(ctx, s.now())
 
-	var contextItems []string
-	if s.cfg.Calendar.Enabled && s.cfg.Calendar.Source != "" {
-		fmt.Println("Fetching calendar events...")
-		fetchCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
-		events, err := calendar.Fetch(fetchCtx, s.cfg.Calendar.Source, startTime, endTime)
-		cancel()
-		if err != nil {
-			fmt.Printf("Warning: calendar fetch failed: %v\n", err)
-		} else {
-			for _, e := range events {
-				contextItems = append(contextItems, e.Summary)
+	window := endTime.Sub(startTime)
+
+	// Only ask about the part of the window that isn't logged yet.
+	logged := s.loggedIn(ctx, startTime, endTime)
+	if free := audit.Free(audit.Interval{Start: startTime, End: endTime}, logged); free < window {
+		if free < time.Minute {
+			fmt.Printf("%s–%s is already logged — nothing to ask.\n", startTime.Format("15:04"), endTime.Format("15:04"))
+			s.db.AnswerPrompts(startTime, endTime)
+			if err := clearPendingWindow(s.db); err != nil {
+				fmt.Printf("Warning: could not clear pending window: %v\n", err)
 			}
+			return
 		}
+		fmt.Printf("%d min already logged in this window; asking about the other %d.\n", int((window - free).Minutes()), int(free.Minutes()))
+		window = free
+	}
+
+	cfg := s.config()
+	var providers []sources.Provider
+	var cal *sources.Calendar
+	if cfg.Calendar.Enabled && cfg.Calendar.Source != "" {
+		fmt.Println("Fetching calendar events...")
+		cal = sources.NewCalendar(func(ctx context.Context, start, end time.Time) ([]calendar.Event, error) {
+			fetchCtx, cancel := context.WithTimeout(ctx, cfg.Timeouts.Context())
+			defer cancel()
+			events, err := calendar.Fetch(fetchCtx, cfg.Calendar.Source, start, end)
+			if err != nil {
+				return nil, fmt.Errorf("calendar fetch failed: %w", err)
+			}
+			return events, nil
+		})
+		providers = append(providers, cal)
+	}
+	plugins := DiscoverPlugins(ctx, os.Stdout)
+	providers = append(providers, sources.Plugins(plugins)...)
+	providers = append(providers, sources.Custom(cfg)...)
+
+	contextItems := sources.Collect(ctx, providers, startTime, endTime, func(_ sources.Provider, err error) {
+		fmt.Printf("Warning: %v\n", err)
+	})
+	contextItems = append(contextItems, RecurringContext(cfg, startTime, endTime)...)
+	var events []calendar.Event
+	if cal != nil {
+		events = cal.Events()
 	}
 
 	lastInput, _ := s.db.GetLastRawInput()
-	app := tui.NewApp(startTime, endTime, s.provider, projects, s.client, s.workspaceID, s.db, interval, contextItems, lastInput)
+	app := tui.NewApp(startTime, endTime, s.provider, projects, s.backend, s.db, window, contextItems, lastInput)
+	app.SetSource(store.SourceScheduler)
+	app.SetSkipReasons(cfg.Schedule.SkipReasons)
+	app.SetAutoAccept(cfg.Schedule.AutoAcceptSeconds, cfg.Schedule.AutoAcceptConfidence)
+	app.SetFormatter(format.New(cfg.Format))
+	app.SetRounding(format.Rounding(cfg.Format))
+	app.SetSplitAtMidnight(cfg.Schedule.SplitAtMidnight())
+	app.SetAITimeout(cfg.Timeouts.AI())
+	if window < endTime.Sub(startTime) {
+		app.SetLogged(logged)
+	}
+	if cfg.Calendar.SplitAtMeetings {
+		app.SetMeetings(events)
+	}
 	p := tea.NewProgram(app)
-
-	if _, err := p.Run(); err != nil {
+	s.mu.Lock()
+	s.program = p
+	s.mu.Unlock()
+
+	_, err = p.Run()
+	s.mu.Lock()
+	s.program = nil
+	s.mu.Unlock()
+	if err != nil {
 		fmt.Printf("Error running TUI: %v\n", err)
+		s.markPending(startTime, endTime)
 		return
 	}
 
 	result := app.GetResult()
-	if result != nil && result.Skipped {
-		fmt.Println("Entry skipped.")
+	if (result == nil || result.Interrupted) && cfg.Slack.Enabled && !s.db.ReadOnly() && loadPendingWindow(s.db) == nil {
+		fmt.Println("Window answered in Slack.")
+		return
+	}
+	if result == nil || result.Interrupted {
+		s.markPending(startTime, endTime)
+		fmt.Println("Prompt closed without an answer — it will be offered again at the next prompt.")
+		return
+	}
+
+	if err := clearPendingWindow(s.db); err != nil {
+		fmt.Printf("Warning: could not clear pending window: %v\n", err)
+	}
+	s.db.AnswerPrompts(startTime, endTime)
+	if result.Skipped {
+		metrics.PromptSkipped()
+		fmt.Println(SkippedMessage(result.SkipReason))
+		s.logBreak(ctx, result.Skip)
+	}
+	for _, e := range result.Entries {
+		metrics.EntrySubmitted(e.Status)
+	}
+	if result.AutoAccepted {
+		for _, e := range result.Entries {
+			fmt.Printf("Auto-accepted: %s — %s (%dmin) [%s]\n", e.ProjectName, e.Description, e.Minutes, e.Status)
+		}
+	}
+	SubmitToPlugins(ctx, plugins, s.db, result.Entries, os.Stdout)
+	if _, err := WriteBack(ctx, cfg, s.db, result.Entries, slog.Default()); err != nil {
+		fmt.Printf("Warning: calendar write-back failed: %v\n", err)
+	}
+	if _, err := s.mirrors().Sync(ctx, s.db, result.Entries); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+}
+
+// restorePending puts back the pending window as it was before the prompt.
+func (s *Scheduler) restorePending(pending *pendingWindow) {
+	var err error
+	if pending == nil {
+		err = clearPendingWindow(s.db)
+	} else {
+		err = savePendingWindow(s.db, *pending)
+	}
+	if err != nil {
+		fmt.Printf("Warning: could not restore pending window: %v\n", err)
+	}
+}
+
+// markPending records the window as unanswered so the next prompt covers it.
+func (s *Scheduler) markPending(start, end time.Time) {
+	if err := savePendingWindow(s.db, pendingWindow{Start: start, End: end}); err != nil {
+		fmt.Printf("Warning: could not save pending window: %v\n", err)
 	}
 }
 
@@ -179,79 +483,35 @@ func (s *Scheduler) nextAlignedTick(now time.Time, interval time.Duration) time.
 	return next
 }
 
-// IsWorkTime checks whether the given time falls within configured work hours and work days.
+// IsWorkTime checks whether the given time falls within configured work hours
+// and work days, outside the configured holidays.
 func IsWorkTime(cfg *config.Config, t time.Time) bool {
-	weekday := int(t.Weekday())
-	if weekday == 0 {
-		weekday = 7 // Sunday = 7
-	}
-
-	isWorkDay := false
-	for _, d := range cfg.Schedule.WorkDays {
-		if d == weekday {
-			isWorkDay = true
-			break
-		}
-	}
-	if !isWorkDay {
+	t = t.In(cfg.Schedule.Location())
+	if cfg.Schedule.OnHoliday(t) || !cfg.Schedule.IsWorkDay(t.Weekday()) {
 		return false
 	}
 
-	startH, startM := parseTime(cfg.Schedule.WorkStart)
-	endH, endM := parseTime(cfg.Schedule.WorkEnd)
-
 	nowMins := t.Hour()*60 + t.Minute()
-	startMins := startH*60 + startM
-	endMins := endH*60 + endM
-
-	return nowMins >= startMins && nowMins <= endMins
+	for _, b := range cfg.Schedule.BlocksFor(t.Weekday()) {
+		if nowMins >= b.Start && nowMins <= b.End {
+			return true
+		}
+	}
+	return false
 }
 
 func (s *Scheduler) isWorkTime(t time.Time) bool {
-	return IsWorkTime(s.cfg, t)
-}
-
-func parseTime(s string) (int, int) {
-	if len(s) == 5 && s[2] == ':' {
-		h, _ := strconv.Atoi(s[:2])
-		m, _ := strconv.Atoi(s[3:])
-		return h, m
-	}
-	return 9, 0
+	return IsWorkTime(s.config(), t)
 }
 
-func (s *Scheduler) retryFailed(ctx context.Context) {
-	entries, err := s.db.GetFailedEntries()
-	if err != nil || len(entries) == 0 {
-		return
-	}
-
-	fmt.Printf("Retrying %d failed entries...\n", len(entries))
-	for _, e := range entries {
-		entry := clockify.TimeEntryRequest{
-			Start:       e.StartTime.UTC().Format("2006-01-02T15:04:05Z"),
-			End:         e.EndTime.UTC().Format("2006-01-02T15:04:05Z"),
-			ProjectID:   e.ProjectID,
-			Description: e.Description,
-		}
-
-		created, err := s.client.CreateTimeEntry(ctx, s.workspaceID, entry)
-		if err != nil {
-			fmt.Printf("  Retry failed for entry %d: %v\n", e.ID, err)
-			continue
-		}
-
-		if err := s.db.UpdateEntryStatus(e.ID, "logged", created.ID); err != nil {
-			fmt.Printf("  Failed to update entry %d status: %v\n", e.ID, err)
-			continue
-		}
-
-		fmt.Printf("  Retried entry %d successfully\n", e.ID)
-	}
+// onHoliday reports whether t falls on a day in the holiday calendar.
+func (s *Scheduler) onHoliday(ctx context.Context, t time.Time) bool {
+	cfg := s.config()
+	return s.holidays.isHoliday(ctx, cfg.Schedule.HolidayCalendar, cfg.Timeouts.Context(), t)
 }
 
 func pidPath() (string, error) {
-	dir, err := config.ConfigDir()
+	dir, err := config.StateDir()
 	if err != nil {
 		return "", err
 	}
@@ -1,6 +1,8 @@
 package scheduler
 
 import (
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -132,6 +134,52 @@ func TestIsWorkTime_CustomWorkDays(t *testing.T) {
 	}
 }
 
+func TestIsWorkTime_BlocksAndDayOverrides(t *testing.T) {
+	cfg := &config.Config{
+		Schedule: config.ScheduleConfig{
+			WorkStart: "09:00",
+			WorkEnd:   "17:00",
+			WorkDays:  []int{1, 2, 3, 4, 5},
+			Blocks:    []string{"09:00-12:00", "13:00-17:00"},
+			Days:      map[string][]string{"friday": {"09:00-13:00"}},
+		},
+	}
+	tests := []struct {
+		name string
+		t    time.Time
+		want bool
+	}{
+		{"Wednesday morning", time.Date(2026, 3, 4, 10, 0, 0, 0, time.Local), true},
+		{"Wednesday lunch", time.Date(2026, 3, 4, 12, 30, 0, 0, time.Local), false},
+		{"Wednesday afternoon", time.Date(2026, 3, 4, 16, 0, 0, 0, time.Local), true},
+		{"Friday morning", time.Date(2026, 3, 6, 12, 30, 0, 0, time.Local), true},
+		{"Friday afternoon", time.Date(2026, 3, 6, 15, 0, 0, 0, time.Local), false},
+	}
+	for _, tt := range tests {
+		if got := IsWorkTime(cfg, tt.t); got != tt.want {
+			t.Errorf("%s: IsWorkTime = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestIsWorkTime_Timezone(t *testing.T) {
+	cfg := &config.Config{
+		Schedule: config.ScheduleConfig{
+			WorkStart: "09:00",
+			WorkEnd:   "17:00",
+			WorkDays:  []int{1, 2, 3, 4, 5},
+			Timezone:  "Asia/Tokyo",
+		},
+	}
+	// 01:00 UTC is 10:00 in Tokyo; 10:00 UTC is 19:00 there.
+	if !IsWorkTime(cfg, time.Date(2026, 3, 4, 1, 0, 0, 0, time.UTC)) {
+		t.Error("expected 10:00 Tokyo time to be work time")
+	}
+	if IsWorkTime(cfg, time.Date(2026, 3, 4, 10, 0, 0, 0, time.UTC)) {
+		t.Error("expected 19:00 Tokyo time to be outside work hours")
+	}
+}
+
 func TestSetSkipWorkTimeCheck(t *testing.T) {
 	cfg := &config.Config{
 		Schedule: config.ScheduleConfig{
@@ -141,7 +189,7 @@ func TestSetSkipWorkTimeCheck(t *testing.T) {
 			WorkDays:        []int{1, 2, 3, 4, 5},
 		},
 	}
-	sched := New(cfg, nil, nil, nil, "ws-123")
+	sched := New(cfg, nil, nil, nil)
 	if sched.skipWorkTimeCheck {
 		t.Error("expected skipWorkTimeCheck to be false by default")
 	}
@@ -154,3 +202,124 @@ func TestSetSkipWorkTimeCheck(t *testing.T) {
 		t.Error("expected skipWorkTimeCheck to be false after unsetting")
 	}
 }
+
+func TestNow_ZoneChange(t *testing.T) {
+	cfg := &config.Config{Schedule: config.ScheduleConfig{Timezone: "Europe/Stockholm"}}
+	s := &Scheduler{cfg: cfg, wakeCh: make(chan struct{}, 1)}
+
+	// The run loop, recurringLoop and a config reload touch the zone at once;
+	// run with -race.
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.now()
+		}()
+	}
+	s.setConfig(&config.Config{Schedule: config.ScheduleConfig{Timezone: "America/New_York"}})
+	wg.Wait()
+
+	if got := s.now().Location().String(); got != "America/New_York" {
+		t.Errorf("now() in %s after reload, want America/New_York", got)
+	}
+}
+
+func TestMergeWindow_NoPending(t *testing.T) {
+	tick := time.Date(2026, 3, 4, 11, 0, 0, 0, time.Local)
+	start, end := mergeWindow(nil, tick, time.Hour)
+	if !start.Equal(tick.Add(-time.Hour)) || !end.Equal(tick) {
+		t.Errorf("mergeWindow() = %s–%s, want 10:00–11:00", start.Format("15:04"), end.Format("15:04"))
+	}
+}
+
+func TestMergeWindow_ExtendsToPendingStart(t *testing.T) {
+	tick := time.Date(2026, 3, 4, 11, 0, 0, 0, time.Local)
+	pending := &pendingWindow{
+		Start: time.Date(2026, 3, 4, 9, 0, 0, 0, time.Local),
+		End:   time.Date(2026, 3, 4, 10, 0, 0, 0, time.Local),
+	}
+	start, end := mergeWindow(pending, tick, time.Hour)
+	if !start.Equal(pending.Start) || !end.Equal(tick) {
+		t.Errorf("mergeWindow() = %s–%s, want 09:00–11:00", start.Format("15:04"), end.Format("15:04"))
+	}
+}
+
+func TestMergeable(t *testing.T) {
+	cfg := &config.Config{
+		Schedule: config.ScheduleConfig{Blocks: []string{"09:00-12:00", "13:00-17:00"}},
+	}
+	at := func(day, hour int) time.Time { return time.Date(2026, 3, day, hour, 0, 0, 0, time.Local) }
+	tests := []struct {
+		name       string
+		start, end time.Time
+		tick       time.Time
+		want       bool
+	}{
+		{"same block", at(4, 9), at(4, 10), at(4, 11), true},
+		{"first window of the block", at(4, 8), at(4, 9), at(4, 10), true},
+		{"previous day", at(3, 16), at(3, 17), at(4, 10), false},
+		{"earlier block", at(4, 10), at(4, 11), at(4, 14), false},
+	}
+	for _, tt := range tests {
+		pending := &pendingWindow{Start: tt.start, End: tt.end}
+		if got := mergeable(cfg, pending, tt.tick, false); got != tt.want {
+			t.Errorf("%s: mergeable() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	if !mergeable(cfg, &pendingWindow{Start: at(4, 18), End: at(4, 19)}, at(4, 20), true) {
+		t.Error("outside work hours with the work time check off: want the same day to merge")
+	}
+}
+
+func TestNextRetryDelay(t *testing.T) {
+	if got := nextRetryDelay(minRetryDelay, false); got != 2*minRetryDelay {
+		t.Errorf("nextRetryDelay(min, false) = %s, want %s", got, 2*minRetryDelay)
+	}
+	if got := nextRetryDelay(maxRetryDelay, false); got != maxRetryDelay {
+		t.Errorf("nextRetryDelay(max, false) = %s, want capped at %s", got, maxRetryDelay)
+	}
+	if got := nextRetryDelay(maxRetryDelay, true); got != minRetryDelay {
+		t.Errorf("nextRetryDelay(max, true) = %s, want reset to %s", got, minRetryDelay)
+	}
+}
+
+func TestClickCommand(t *testing.T) {
+	got := ClickCommand("kitty {command}", "/tmp/clockr/config.toml")
+	if !strings.HasPrefix(got, "kitty ") || !strings.HasSuffix(got, " prompt-now --config /tmp/clockr/config.toml") {
+		t.Errorf("unexpected click command %q", got)
+	}
+
+	got = ClickCommand("kitty {command}", "/tmp/my config.toml")
+	if strings.Contains(got, "--config") {
+		t.Errorf("paths that need quoting should be left out, got %q", got)
+	}
+
+	if got := ClickCommand("", ""); !strings.Contains(got, "prompt-now") {
+		t.Errorf("default template should run prompt-now, got %q", got)
+	}
+}
+
+func TestClipToWorkBlock(t *testing.T) {
+	cfg := &config.Config{
+		Schedule: config.ScheduleConfig{Blocks: []string{"09:00-12:00", "13:00-17:00"}},
+	}
+	at := func(hour, min int) time.Time { return time.Date(2026, 3, 4, hour, min, 0, 0, time.Local) }
+	tests := []struct {
+		name       string
+		start, end time.Time
+		want       time.Time
+	}{
+		{"inside the block", at(10, 0), at(11, 0), at(10, 0)},
+		{"merged across lunch", at(11, 0), at(14, 0), at(13, 0)},
+		{"first tick of the afternoon", at(12, 0), at(13, 0), at(13, 0)},
+		{"first tick of the day", at(8, 0), at(9, 0), at(9, 0)},
+		{"end of the morning block", at(11, 0), at(12, 0), at(11, 0)},
+		{"outside work hours", at(17, 0), at(18, 0), at(17, 0)},
+	}
+	for _, tt := range tests {
+		if got := clipToWorkBlock(cfg, tt.start, tt.end); !got.Equal(tt.want) {
+			t.Errorf("%s: clipToWorkBlock() = %s, want %s", tt.name, got.Format("15:04"), tt.want.Format("15:04"))
+		}
+	}
+}
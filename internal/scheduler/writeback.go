@@ -0,0 +1,85 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/christopherklint97/clockr/internal/config"
+	"github.com/christopherklint97/clockr/internal/msgraph"
+	"github.com/christopherklint97/clockr/internal/store"
+)
+
+// graphLoginURL and graphAPIURL replace the Azure AD and Graph endpoints
+// of write-back when set; tests point them at fake servers.
+var graphLoginURL, graphAPIURL string
+
+// WriteBack creates a private busy event in the Graph calendar of
+// [calendar.graph] write_profile for each logged entry that does not have one
+// yet, and returns how many were created.
+// It does nothing unless calendar.write_back is enabled.
+func WriteBack(ctx context.Context, cfg *config.Config, db *store.DB, entries []store.Entry, logger *slog.Logger) (int, error) {
+	if !cfg.Calendar.WriteBack || db.ReadOnly() {
+		return 0, nil
+	}
+	if cfg.Calendar.Source != "graph" {
+		// ICS feeds, Google's included, can only be read.
+		return 0, fmt.Errorf("calendar write-back needs source = \"graph\"; %q is a read-only calendar", cfg.Calendar.Source)
+	}
+
+	var pending []store.Entry
+	for _, e := range entries {
+		if e.ID != 0 && e.Status == "logged" && e.CalendarEventID == "" {
+			pending = append(pending, e)
+		}
+	}
+	if len(pending) == 0 {
+		return 0, nil
+	}
+
+	profile := cfg.Calendar.Graph.WriteProfile
+	tokens, err := msgraph.LoadTokens(profile)
+	if err != nil {
+		return 0, fmt.Errorf("loading calendar tokens: %w", err)
+	}
+	if tokens == nil || !tokens.CanWrite() {
+		auth := "clockr calendar auth"
+		if profile != "" {
+			auth += " --profile " + profile
+		}
+		return 0, fmt.Errorf("calendar write-back needs Calendars.ReadWrite — run '%s'", auth)
+	}
+
+	clientID, tenantID := cfg.Calendar.Graph.App(profile)
+	auth := msgraph.NewAuth(clientID, tenantID, logger)
+	auth.SetProfile(profile)
+	auth.SetWriteAccess(true)
+	auth.SetLoginURL(graphLoginURL)
+	client := msgraph.NewClient(auth, logger)
+	client.SetBaseURL(graphAPIURL)
+
+	created := 0
+	for _, e := range pending {
+		subject := e.Description
+		if e.ProjectName != "" {
+			subject = e.ProjectName + ": " + e.Description
+		}
+		id, err := client.CreateEvent(ctx, msgraph.NewEvent{
+			Subject: subject,
+			Body:    "Logged with clockr.",
+			Start:   e.StartTime,
+			End:     e.EndTime,
+			// Stable per entry, so a retried or repeated write-back
+			// doesn't add the event twice.
+			TransactionID: fmt.Sprintf("clockr-entry-%d-%d", e.ID, e.StartTime.Unix()),
+		})
+		if err != nil {
+			return created, fmt.Errorf("creating calendar event for entry %d: %w", e.ID, err)
+		}
+		if err := db.SetCalendarEventID(e.ID, id); err != nil {
+			return created, err
+		}
+		created++
+	}
+	return created, nil
+}
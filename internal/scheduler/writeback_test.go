@@ -0,0 +1,108 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/config"
+	"github.com/christopherklint97/clockr/internal/msgraph"
+	"github.com/christopherklint97/clockr/internal/store"
+)
+
+func writeBackConfig() *config.Config {
+	cfg := config.DefaultConfig()
+	cfg.Calendar.Enabled = true
+	cfg.Calendar.Source = "graph"
+	cfg.Calendar.WriteBack = true
+	cfg.Calendar.Graph.ClientID = "client"
+	cfg.Calendar.Graph.TenantID = "tenant"
+	return &cfg
+}
+
+func TestWriteBack(t *testing.T) {
+	db := testHome(t)
+	t.Setenv("CLOCKR_NO_KEYCHAIN", "1")
+	err := msgraph.SaveTokens("", &msgraph.TokenData{AccessToken: "tok", ExpiresAt: time.Now().Add(time.Hour), Scope: "Calendars.ReadWrite offline_access"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var subjects, transactions []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Subject       string `json:"subject"`
+			TransactionID string `json:"transactionId"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		subjects = append(subjects, body.Subject)
+		transactions = append(transactions, body.TransactionID)
+		n := len(subjects)
+		mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"id":"event-%d"}`, n)
+	}))
+	t.Cleanup(srv.Close)
+	graphAPIURL = srv.URL
+	t.Cleanup(func() { graphAPIURL = "" })
+
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	var entries []store.Entry
+	for i, status := range []string{"logged", "failed", "logged"} {
+		e := store.Entry{ProjectName: "Acme", Description: fmt.Sprintf("task %d", i), StartTime: start, EndTime: start.Add(time.Hour), Minutes: 60, Status: status}
+		if _, err := db.InsertEntry(&e); err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, e)
+		start = start.Add(time.Hour)
+	}
+
+	n, err := WriteBack(context.Background(), writeBackConfig(), db, entries, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("created %d events, want 2 (the failed entry has no time logged)", n)
+	}
+	if want := "Acme: task 0|Acme: task 2"; strings.Join(subjects, "|") != want {
+		t.Errorf("subjects = %q, want %s", subjects, want)
+	}
+	if transactions[0] == "" || transactions[0] == transactions[1] {
+		t.Errorf("transaction IDs = %q, want one per entry", transactions)
+	}
+	for i, want := range []string{"event-1", "", "event-2"} {
+		e, err := db.GetEntry(entries[i].ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e.CalendarEventID != want {
+			t.Errorf("entry %d calendar event = %q, want %q", i, e.CalendarEventID, want)
+		}
+		entries[i] = *e
+	}
+
+	// Entries with an event are not written again.
+	if n, err := WriteBack(context.Background(), writeBackConfig(), db, entries, nil); err != nil || n != 0 {
+		t.Errorf("second WriteBack = %d, %v; want nothing new", n, err)
+	}
+}
+
+func TestWriteBack_ReadOnlyCalendar(t *testing.T) {
+	db := testHome(t)
+	cfg := writeBackConfig()
+	cfg.Calendar.Source = "https://calendar.google.com/calendar/ical/me/private-x/basic.ics"
+	entries := []store.Entry{{ID: 1, Status: "logged", StartTime: time.Now(), EndTime: time.Now()}}
+
+	_, err := WriteBack(context.Background(), cfg, db, entries, nil)
+	if err == nil || !strings.Contains(err.Error(), `source = "graph"`) {
+		t.Errorf("err = %v, want write-back refused for an ICS calendar", err)
+	}
+}
@@ -0,0 +1,118 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// cliTimeout bounds keychain tool calls; an unlock prompt that nobody
+// answers must not hang a scheduled prompt.
+const cliTimeout = 10 * time.Second
+
+func platformKeychain() Keychain {
+	switch runtime.GOOS {
+	case "darwin":
+		if _, err := exec.LookPath("security"); err == nil {
+			return macKeychain{}
+		}
+	case "linux", "freebsd", "openbsd":
+		if _, err := exec.LookPath("secret-tool"); err == nil {
+			return secretTool{}
+		}
+	case "windows":
+		return newWinCred()
+	}
+	return nil
+}
+
+func run(stdin string, name string, args ...string) (string, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), cliTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	if stdin != "" {
+		cmd.Stdin = strings.NewReader(stdin)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return stdout.String(), exitErr.ExitCode(), fmt.Errorf("%s: %s", name, strings.TrimSpace(stderr.String()))
+	}
+	if err != nil {
+		return "", -1, fmt.Errorf("running %s: %w", name, err)
+	}
+	return stdout.String(), 0, nil
+}
+
+// macKeychain uses the `security` tool to store generic passwords.
+type macKeychain struct{}
+
+func (macKeychain) Name() string { return "macOS Keychain" }
+
+func (macKeychain) Get(name string) (string, error) {
+	out, code, err := run("", "security", "find-generic-password", "-s", service, "-a", name, "-w")
+	if code == 44 { // errSecItemNotFound
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(out, "\n"), nil
+}
+
+func (macKeychain) Set(name, value string) error {
+	// The command goes to 'security -i' on stdin, with the value hex-encoded
+	// for -X, so the value never appears in the process list. Service and
+	// item names are plain identifiers and need no more than single quotes.
+	command := fmt.Sprintf("add-generic-password -U -s '%s' -a '%s' -l 'clockr %s' -X %s\n", service, name, name, hex.EncodeToString([]byte(value)))
+	_, _, err := run(command, "security", "-i")
+	return err
+}
+
+func (macKeychain) Delete(name string) error {
+	_, code, err := run("", "security", "delete-generic-password", "-s", service, "-a", name)
+	if code == 44 {
+		return ErrNotFound
+	}
+	return err
+}
+
+// secretTool uses libsecret's secret-tool, which talks to GNOME Keyring,
+// KWallet or any other Secret Service provider.
+type secretTool struct{}
+
+func (secretTool) Name() string { return "Secret Service (secret-tool)" }
+
+func (secretTool) Get(name string) (string, error) {
+	out, code, err := run("", "secret-tool", "lookup", "service", service, "account", name)
+	// lookup exits 1 with no output when the item doesn't exist.
+	if code == 1 && out == "" {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(out, "\n"), nil
+}
+
+func (secretTool) Set(name, value string) error {
+	// The value is passed on stdin so it never appears in the process list.
+	_, _, err := run(value, "secret-tool", "store", "--label", "clockr "+name, "service", service, "account", name)
+	return err
+}
+
+func (secretTool) Delete(name string) error {
+	_, _, err := run("", "secret-tool", "clear", "service", service, "account", name)
+	return err
+}
@@ -0,0 +1,165 @@
+// Package secrets stores credentials in the OS keychain: macOS Keychain,
+// libsecret (via secret-tool) on Linux, or Windows Credential Manager.
+// Callers fall back to plaintext files when no keychain is available.
+package secrets
+
+import (
+	"errors"
+	"os"
+	"sync"
+)
+
+// service groups all clockr items in the keychain.
+const service = "clockr"
+
+// Names of the secrets clockr keeps in the keychain.
+const (
+	ClockifyAPIKey     = "clockify_api_key"
+	GitHubToken        = "github_token"
+	GraphRefreshToken  = "msgraph_refresh_token"
+	SMTPPassword       = "smtp_password"
+	SlackBotToken      = "slack_bot_token"
+	SlackSigningSecret = "slack_signing_secret"
+	HarvestToken       = "harvest_token"
+	TogglAPIToken      = "toggl_api_token"
+	TempoToken         = "tempo_token"
+	JiraAPIToken       = "jira_api_token"
+)
+
+// Names lists every secret clockr may store, for status and wipe.
+var Names = []string{ClockifyAPIKey, GitHubToken, GraphRefreshToken, SMTPPassword, SlackBotToken, SlackSigningSecret, HarvestToken, TogglAPIToken, TempoToken, JiraAPIToken}
+
+// GraphProfileRefreshToken names the refresh token of a Graph sign-in made
+// with 'clockr calendar auth --profile NAME'; the default one ("") is
+// GraphRefreshToken.
+func GraphProfileRefreshToken(profile string) string {
+	if profile == "" {
+		return GraphRefreshToken
+	}
+	return GraphRefreshToken + "_" + profile
+}
+
+var (
+	ErrNotFound    = errors.New("secret not found in keychain")
+	ErrUnavailable = errors.New("no OS keychain available")
+)
+
+// Keychain is a platform credential store.
+type Keychain interface {
+	Get(name string) (string, error)
+	Set(name, value string) error
+	Delete(name string) error
+	// Name describes the backend for status output.
+	Name() string
+}
+
+var (
+	defaultOnce sync.Once
+	defaultKC   Keychain
+)
+
+// Default returns the platform keychain, or nil if none is usable.
+// Setting CLOCKR_NO_KEYCHAIN=1 disables keychain use entirely.
+func Default() Keychain {
+	defaultOnce.Do(func() {
+		if v := os.Getenv("CLOCKR_NO_KEYCHAIN"); v == "1" || v == "true" {
+			return
+		}
+		defaultKC = platformKeychain()
+	})
+	return defaultKC
+}
+
+// SetDefault replaces the keychain Default returns; nil disables it. Tests
+// use it with a Memory keychain.
+func SetDefault(kc Keychain) {
+	defaultOnce.Do(func() {})
+	defaultKC = kc
+	Forget()
+}
+
+// Available reports whether an OS keychain can be used.
+func Available() bool {
+	return Default() != nil
+}
+
+// Get reads a secret from the default keychain.
+func Get(name string) (string, error) {
+	kc := Default()
+	if kc == nil {
+		return "", ErrUnavailable
+	}
+	return kc.Get(name)
+}
+
+// cached is a keychain answer: the value, or ErrNotFound for a secret that
+// isn't stored. Other errors, such as a locked keychain, are not kept.
+type cached struct {
+	value string
+	err   error
+}
+
+var (
+	cacheMu sync.Mutex
+	cache   = make(map[string]cached)
+)
+
+// GetCached is Get, remembering the answer for later calls in this
+// process. Config loads use it so the running scheduler, which reloads the
+// config on every change, runs the keychain tool (and any unlock prompt)
+// once per secret rather than on every reload. Set and Delete keep it
+// current; a change made by another process is only seen after Forget.
+func GetCached(name string) (string, error) {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	if c, ok := cache[name]; ok {
+		return c.value, c.err
+	}
+	v, err := Get(name)
+	if err == nil || errors.Is(err, ErrNotFound) {
+		cache[name] = cached{v, err}
+	}
+	return v, err
+}
+
+// Forget drops everything GetCached remembered.
+func Forget() {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	clear(cache)
+}
+
+// remember records a value just written (or, with ErrNotFound, deleted)
+// for GetCached.
+func remember(name, value string, err error) {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	cache[name] = cached{value, err}
+}
+
+// Set writes a secret to the default keychain.
+func Set(name, value string) error {
+	kc := Default()
+	if kc == nil {
+		return ErrUnavailable
+	}
+	if err := kc.Set(name, value); err != nil {
+		return err
+	}
+	remember(name, value, nil)
+	return nil
+}
+
+// Delete removes a secret from the default keychain. Deleting a secret that
+// doesn't exist is not an error.
+func Delete(name string) error {
+	kc := Default()
+	if kc == nil {
+		return ErrUnavailable
+	}
+	if err := kc.Delete(name); err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	remember(name, "", ErrNotFound)
+	return nil
+}
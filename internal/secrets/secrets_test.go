@@ -0,0 +1,228 @@
+package secrets
+
+import (
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// useKeychain makes kc the default keychain for the rest of the test.
+func useKeychain(t *testing.T, kc Keychain) {
+	t.Helper()
+	prev := Default()
+	SetDefault(kc)
+	t.Cleanup(func() { SetDefault(prev) })
+}
+
+// countingKeychain is a Memory keychain that counts reads and can fail them.
+type countingKeychain struct {
+	*Memory
+	gets int
+	err  error
+}
+
+func (c *countingKeychain) Get(name string) (string, error) {
+	c.gets++
+	if c.err != nil {
+		return "", c.err
+	}
+	return c.Memory.Get(name)
+}
+
+func TestNoKeychain(t *testing.T) {
+	useKeychain(t, nil)
+
+	if Available() {
+		t.Error("Available() = true with no keychain")
+	}
+	if _, err := Get(ClockifyAPIKey); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("Get err = %v, want ErrUnavailable", err)
+	}
+	if _, err := GetCached(ClockifyAPIKey); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("GetCached err = %v, want ErrUnavailable", err)
+	}
+	if err := Set(ClockifyAPIKey, "key"); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("Set err = %v, want ErrUnavailable", err)
+	}
+	if err := Delete(ClockifyAPIKey); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("Delete err = %v, want ErrUnavailable", err)
+	}
+}
+
+func TestSetGetDelete(t *testing.T) {
+	useKeychain(t, NewMemory())
+
+	if err := Set(GitHubToken, "ghp_1"); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := Get(GitHubToken); err != nil || v != "ghp_1" {
+		t.Errorf("Get = %q, %v; want ghp_1", v, err)
+	}
+	if err := Delete(GitHubToken); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Get(GitHubToken); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Delete err = %v, want ErrNotFound", err)
+	}
+	// Deleting what isn't there is not an error.
+	if err := Delete(GitHubToken); err != nil {
+		t.Errorf("second Delete = %v", err)
+	}
+}
+
+func TestGetCached(t *testing.T) {
+	kc := &countingKeychain{Memory: NewMemory()}
+	kc.Memory.Set(ClockifyAPIKey, "key-1")
+	useKeychain(t, kc)
+
+	for range 3 {
+		if v, err := GetCached(ClockifyAPIKey); err != nil || v != "key-1" {
+			t.Fatalf("GetCached = %q, %v; want key-1", v, err)
+		}
+		if _, err := GetCached(TogglAPIToken); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("GetCached(missing) err = %v, want ErrNotFound", err)
+		}
+	}
+	if kc.gets != 2 {
+		t.Errorf("keychain reads = %d, want 2 (one per secret)", kc.gets)
+	}
+
+	// Writes through the package keep the cache current without a read.
+	if err := Set(ClockifyAPIKey, "key-2"); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := GetCached(ClockifyAPIKey); v != "key-2" {
+		t.Errorf("GetCached after Set = %q, want key-2", v)
+	}
+	if err := Delete(ClockifyAPIKey); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GetCached(ClockifyAPIKey); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetCached after Delete err = %v, want ErrNotFound", err)
+	}
+	if kc.gets != 2 {
+		t.Errorf("keychain reads = %d, want still 2", kc.gets)
+	}
+
+	// A change made elsewhere shows up after Forget.
+	kc.Memory.Set(ClockifyAPIKey, "key-3")
+	Forget()
+	if v, _ := GetCached(ClockifyAPIKey); v != "key-3" {
+		t.Errorf("GetCached after Forget = %q, want key-3", v)
+	}
+}
+
+func TestGetCached_KeepsNoFailures(t *testing.T) {
+	kc := &countingKeychain{Memory: NewMemory(), err: errors.New("keychain locked")}
+	kc.Memory.Set(GitHubToken, "ghp_1")
+	useKeychain(t, kc)
+
+	if _, err := GetCached(GitHubToken); err == nil {
+		t.Fatal("GetCached with a locked keychain succeeded")
+	}
+	kc.err = nil
+	if v, err := GetCached(GitHubToken); err != nil || v != "ghp_1" {
+		t.Errorf("GetCached once unlocked = %q, %v; want ghp_1", v, err)
+	}
+}
+
+func TestGraphProfileRefreshToken(t *testing.T) {
+	if got := GraphProfileRefreshToken(""); got != GraphRefreshToken {
+		t.Errorf("GraphProfileRefreshToken(\"\") = %q", got)
+	}
+	if got := GraphProfileRefreshToken("work"); got != "msgraph_refresh_token_work" {
+		t.Errorf("GraphProfileRefreshToken(work) = %q", got)
+	}
+}
+
+// fakeTool puts an executable shell script called name first on PATH. The
+// script keeps its items as files in the directory $FAKE_KEYCHAIN, which
+// is returned.
+func fakeTool(t *testing.T, name, script string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a POSIX shell")
+	}
+	bin, store := t.TempDir(), t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, name), []byte("#!/bin/sh\n"+script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("FAKE_KEYCHAIN", store)
+	return store
+}
+
+func TestSecretTool(t *testing.T) {
+	// secret-tool lookup|store|clear [--label L] service S account A
+	fakeTool(t, "secret-tool", `
+case "$1" in
+lookup) [ -f "$FAKE_KEYCHAIN/$3.$5" ] || exit 1; cat "$FAKE_KEYCHAIN/$3.$5" ;;
+store) cat > "$FAKE_KEYCHAIN/$5.$7" ;;
+clear) rm -f "$FAKE_KEYCHAIN/$3.$5" ;;
+esac
+`)
+	kc := secretTool{}
+
+	if _, err := kc.Get(HarvestToken); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get of a missing item err = %v, want ErrNotFound", err)
+	}
+	if err := kc.Set(HarvestToken, "token with spaces"); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := kc.Get(HarvestToken); err != nil || v != "token with spaces" {
+		t.Errorf("Get = %q, %v; want the stored token", v, err)
+	}
+	if err := kc.Delete(HarvestToken); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := kc.Get(HarvestToken); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Delete err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestMacKeychain(t *testing.T) {
+	// security find-generic-password|delete-generic-password -s S -a A [-w],
+	// or security -i with the command on stdin.
+	store := fakeTool(t, "security", `
+case "$1" in
+find-generic-password) [ -f "$FAKE_KEYCHAIN/$3.$5" ] || exit 44; cat "$FAKE_KEYCHAIN/$3.$5"; echo ;;
+delete-generic-password) [ -f "$FAKE_KEYCHAIN/$3.$5" ] || exit 44; rm "$FAKE_KEYCHAIN/$3.$5" ;;
+-i) cat > "$FAKE_KEYCHAIN/stdin" ;;
+esac
+`)
+	kc := macKeychain{}
+
+	if _, err := kc.Get(TempoToken); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get of a missing item err = %v, want ErrNotFound", err)
+	}
+	if err := kc.Delete(TempoToken); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Delete of a missing item err = %v, want ErrNotFound", err)
+	}
+
+	// The value goes hex-encoded on stdin, never as an argument.
+	if err := kc.Set(TempoToken, "s3cret"); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(store, "stdin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd := string(data)
+	if !strings.Contains(cmd, "-s 'clockr' -a 'tempo_token'") || !strings.Contains(cmd, "-X "+hex.EncodeToString([]byte("s3cret"))) || strings.Contains(cmd, "s3cret") {
+		t.Errorf("security -i got %q", cmd)
+	}
+
+	if err := os.WriteFile(filepath.Join(store, "clockr.tempo_token"), []byte("s3cret"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := kc.Get(TempoToken); err != nil || v != "s3cret" {
+		t.Errorf("Get = %q, %v; want s3cret", v, err)
+	}
+	if err := kc.Delete(TempoToken); err != nil {
+		t.Errorf("Delete = %v", err)
+	}
+}
@@ -0,0 +1,5 @@
+//go:build !windows
+
+package secrets
+
+func newWinCred() Keychain { return nil }
@@ -0,0 +1,244 @@
+// Package server is the local HTTP API behind 'clockr serve', for launcher
+// extensions and hardware buttons that drive clockr without a terminal.
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/ai"
+	"github.com/christopherklint97/clockr/internal/backend"
+	"github.com/christopherklint97/clockr/internal/clockify"
+	"github.com/christopherklint97/clockr/internal/config"
+	"github.com/christopherklint97/clockr/internal/metrics"
+	"github.com/christopherklint97/clockr/internal/mirror"
+	"github.com/christopherklint97/clockr/internal/scheduler"
+	"github.com/christopherklint97/clockr/internal/store"
+)
+
+// Server answers the API. Every route except /health requires
+// "Authorization: Bearer <token>".
+type Server struct {
+	cfg      *config.Config
+	backend  backend.Backend
+	db       *store.DB
+	mirrors  *mirror.Mirrors
+	provider ai.Provider
+	token    string
+
+	now       func() time.Time
+	promptNow func(ctx context.Context) error
+
+	// mu serializes entry creation so two button presses can't log the
+	// same window twice.
+	mu sync.Mutex
+}
+
+// New creates a Server. token must not be empty.
+func New(cfg *config.Config, b backend.Backend, db *store.DB, provider ai.Provider, token string) *Server {
+	return &Server{
+		cfg:      cfg,
+		backend:  b,
+		db:       db,
+		mirrors:  mirror.New(cfg, slog.Default()),
+		provider: provider,
+		token:    token,
+		now:      time.Now,
+		promptNow: func(ctx context.Context) error {
+			_, err := scheduler.SendControl(ctx, scheduler.ControlRequest{Command: scheduler.CmdPromptNow})
+			return err
+		},
+	}
+}
+
+// Handler returns the API routes.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", s.health)
+	mux.Handle("GET /entries/today", s.auth(s.todayEntries))
+	mux.Handle("POST /entries", s.auth(s.createEntries))
+	mux.Handle("POST /prompt", s.auth(s.prompt))
+	if s.cfg.Metrics.Enabled {
+		mux.Handle("GET /metrics", s.auth(metrics.Handler().ServeHTTP))
+	}
+	return mux
+}
+
+func (s *Server) auth(next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(s.token)) != 1 {
+			writeError(w, http.StatusUnauthorized, "missing or wrong token")
+			return
+		}
+		next(w, r)
+	})
+}
+
+// EntryJSON is an entry as returned by the API.
+type EntryJSON struct {
+	ID          int       `json:"id"`
+	ClockifyID  string    `json:"clockify_id,omitempty"`
+	ProjectID   string    `json:"project_id"`
+	ProjectName string    `json:"project_name"`
+	ClientName  string    `json:"client_name,omitempty"`
+	Description string    `json:"description"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	Minutes     int       `json:"minutes"`
+	Status      string    `json:"status"`
+}
+
+func toJSON(entries []store.Entry) []EntryJSON {
+	out := []EntryJSON{}
+	for _, e := range entries {
+		out = append(out, EntryJSON{
+			ID:          e.ID,
+			ClockifyID:  e.ClockifyID,
+			ProjectID:   e.ProjectID,
+			ProjectName: e.ProjectName,
+			ClientName:  e.ClientName,
+			Description: e.Description,
+			Start:       e.StartTime,
+			End:         e.EndTime,
+			Minutes:     e.Minutes,
+			Status:      e.Status,
+		})
+	}
+	return out
+}
+
+func (s *Server) health(w http.ResponseWriter, r *http.Request) {
+	_, err := scheduler.SendControl(r.Context(), scheduler.ControlRequest{Command: scheduler.CmdStatus})
+	writeJSON(w, http.StatusOK, map[string]any{
+		"ok":        true,
+		"read_only": s.db.ReadOnly(),
+		"scheduler": err == nil,
+	})
+}
+
+// todayEntries lists the entries overlapping today in the schedule's zone.
+// The total only counts the part of each entry that falls on today, so an
+// entry across midnight is not counted in full on both days.
+func (s *Server) todayEntries(w http.ResponseWriter, r *http.Request) {
+	now := s.now().In(s.cfg.Schedule.Location())
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	dayEnd := dayStart.AddDate(0, 0, 1)
+	entries, err := s.db.GetEntriesBetween(dayStart, dayEnd)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	total := 0
+	for _, e := range entries {
+		total += e.MinutesWithin(dayStart, dayEnd)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"entries": toJSON(entries), "total_minutes": total})
+}
+
+// CreateRequest logs a description. The window is Start–End when given,
+// otherwise the Minutes (default: today's schedule interval) up to now.
+type CreateRequest struct {
+	Description string    `json:"description"`
+	Minutes     int       `json:"minutes,omitempty"`
+	Start       time.Time `json:"start,omitzero"`
+	End         time.Time `json:"end,omitzero"`
+	DryRun      bool      `json:"dry_run,omitempty"` // return the suggestion without logging it
+}
+
+func (s *Server) createEntries(w http.ResponseWriter, r *http.Request) {
+	var req CreateRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+		return
+	}
+	req.Description = strings.TrimSpace(req.Description)
+	if req.Description == "" {
+		writeError(w, http.StatusBadRequest, "description is required")
+		return
+	}
+	start, end, err := s.window(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !req.DryRun && s.db.ReadOnly() {
+		writeError(w, http.StatusForbidden, "clockr is in read-only mode")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	suggestion, err := scheduler.Suggest(r.Context(), s.cfg, s.provider, s.backend, s.db, req.Description, start, end)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, clockify.FriendlyError(err))
+		return
+	}
+	if len(suggestion.Allocations) == 0 {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]any{
+			"error":         "no project matched the description",
+			"clarification": suggestion.Clarification,
+		})
+		return
+	}
+	if req.DryRun {
+		writeJSON(w, http.StatusOK, map[string]any{"start": start, "end": end, "allocations": suggestion.Allocations})
+		return
+	}
+
+	entries, failed := scheduler.SubmitAllocations(r.Context(), s.cfg, s.backend, s.db, s.mirrors, suggestion.Allocations, start, end, req.Description,
+		scheduler.RecordSuggestion(s.db, s.provider, req.Description, suggestion, nil), store.SourceAPI, io.Discard)
+	writeJSON(w, http.StatusCreated, map[string]any{"entries": toJSON(entries), "failed": failed})
+}
+
+// window resolves the request's time range in the schedule's zone.
+func (s *Server) window(req CreateRequest) (time.Time, time.Time, error) {
+	loc := s.cfg.Schedule.Location()
+	end := req.End
+	if end.IsZero() {
+		end = s.now().Truncate(time.Minute)
+	}
+	start := req.Start
+	if start.IsZero() {
+		interval := s.cfg.Schedule.IntervalFor(end.In(loc).Weekday())
+		if req.Minutes > 0 {
+			interval = time.Duration(req.Minutes) * time.Minute
+		}
+		start = end.Add(-interval)
+	}
+	if !start.Before(end) {
+		return time.Time{}, time.Time{}, errors.New("start must be before end")
+	}
+	return start.In(loc), end.In(loc), nil
+}
+
+func (s *Server) prompt(w http.ResponseWriter, r *http.Request) {
+	if err := s.promptNow(r.Context()); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, scheduler.ErrNotRunning) {
+			status = http.StatusServiceUnavailable
+		}
+		writeError(w, status, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusAccepted, map[string]any{"ok": true})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
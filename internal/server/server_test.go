@@ -0,0 +1,166 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/backend"
+	"github.com/christopherklint97/clockr/internal/clockify"
+	"github.com/christopherklint97/clockr/internal/config"
+	"github.com/christopherklint97/clockr/internal/demo"
+	"github.com/christopherklint97/clockr/internal/scheduler"
+	"github.com/christopherklint97/clockr/internal/store"
+)
+
+func newTestServer(t *testing.T) (*Server, *demo.Server) {
+	t.Helper()
+	t.Setenv("CLOCKR_HOME", t.TempDir())
+	db, err := store.Open(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	api := demo.NewServer()
+	t.Cleanup(api.Close)
+	client := clockify.NewClient("demo", api.URL, time.Hour, nil)
+
+	cfg := config.DefaultConfig()
+	s := New(&cfg, backend.Clockify(client, demo.WorkspaceID), db, &demo.Provider{}, "secret")
+	s.now = func() time.Time { return time.Now().Truncate(time.Hour).Add(-time.Hour) }
+	return s, api
+}
+
+func do(t *testing.T, h http.Handler, method, path, token, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, r)
+	return rec
+}
+
+func TestAuth(t *testing.T) {
+	s, _ := newTestServer(t)
+	h := s.Handler()
+
+	if rec := do(t, h, http.MethodGet, "/health", "", ""); rec.Code != http.StatusOK {
+		t.Errorf("health without token = %d", rec.Code)
+	}
+	for _, token := range []string{"", "wrong"} {
+		if rec := do(t, h, http.MethodGet, "/entries/today", token, ""); rec.Code != http.StatusUnauthorized {
+			t.Errorf("token %q: status %d, want 401", token, rec.Code)
+		}
+	}
+	if rec := do(t, h, http.MethodGet, "/entries/today", "secret", ""); rec.Code != http.StatusOK {
+		t.Errorf("with token = %d", rec.Code)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	s, _ := newTestServer(t)
+	if rec := do(t, s.Handler(), http.MethodGet, "/metrics", "secret", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("metrics while disabled = %d, want 404", rec.Code)
+	}
+
+	s.cfg.Metrics.Enabled = true
+	h := s.Handler()
+	if rec := do(t, h, http.MethodGet, "/metrics", "", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("metrics without token = %d, want 401", rec.Code)
+	}
+	rec := do(t, h, http.MethodGet, "/metrics", "secret", "")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "clockr_entries_logged_total") {
+		t.Errorf("metrics = %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestCreateEntries(t *testing.T) {
+	s, api := newTestServer(t)
+	h := s.Handler()
+
+	rec := do(t, h, http.MethodPost, "/entries", "secret", `{"description":"landing page and sprint planning","dry_run":true}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("dry run = %d: %s", rec.Code, rec.Body)
+	}
+	if n := len(api.Entries()); n != 0 {
+		t.Fatalf("dry run created %d entries", n)
+	}
+
+	rec = do(t, h, http.MethodPost, "/entries", "secret", `{"description":"landing page and sprint planning","minutes":60}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create = %d: %s", rec.Code, rec.Body)
+	}
+	var out struct {
+		Entries []EntryJSON `json:"entries"`
+		Failed  int         `json:"failed"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &out); err != nil {
+		t.Fatal(err)
+	}
+	if len(out.Entries) != 2 || out.Failed != 0 || len(api.Entries()) != 2 {
+		t.Fatalf("entries = %+v (clockify has %d)", out, len(api.Entries()))
+	}
+	if got := out.Entries[0].Minutes + out.Entries[1].Minutes; got != 60 {
+		t.Errorf("minutes = %d, want 60", got)
+	}
+	if !out.Entries[1].End.Equal(s.now()) {
+		t.Errorf("window ends %v, want %v", out.Entries[1].End, s.now())
+	}
+	stored, err := s.db.GetEntriesBetween(s.now().Add(-time.Hour), s.now())
+	if err != nil || len(stored) != 2 {
+		t.Fatalf("stored = %d entries, %v", len(stored), err)
+	}
+	for _, e := range stored {
+		if e.Source != store.SourceAPI {
+			t.Errorf("entry %d source = %q, want %q", e.ID, e.Source, store.SourceAPI)
+		}
+	}
+
+	if rec := do(t, h, http.MethodPost, "/entries", "secret", `{"description":" "}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("empty description = %d", rec.Code)
+	}
+}
+
+func TestPrompt_SchedulerNotRunning(t *testing.T) {
+	s, _ := newTestServer(t)
+	s.promptNow = func(context.Context) error { return scheduler.ErrNotRunning }
+
+	if rec := do(t, s.Handler(), http.MethodPost, "/prompt", "secret", ""); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503", rec.Code)
+	}
+}
+
+func TestTodayEntries_ClipsToToday(t *testing.T) {
+	s, _ := newTestServer(t)
+	s.cfg.Schedule.Timezone = "UTC"
+	s.now = func() time.Time { return time.Date(2026, 3, 3, 12, 0, 0, 0, time.UTC) }
+	for _, e := range []store.Entry{
+		// 23:00–01:00 counts one hour today.
+		{ProjectID: "p1", StartTime: time.Date(2026, 3, 2, 23, 0, 0, 0, time.UTC), EndTime: time.Date(2026, 3, 3, 1, 0, 0, 0, time.UTC), Minutes: 120, Status: "logged"},
+		{ProjectID: "p1", StartTime: time.Date(2026, 3, 3, 9, 0, 0, 0, time.UTC), EndTime: time.Date(2026, 3, 3, 10, 30, 0, 0, time.UTC), Minutes: 90, Status: "logged"},
+		{ProjectID: "p1", StartTime: time.Date(2026, 3, 4, 9, 0, 0, 0, time.UTC), EndTime: time.Date(2026, 3, 4, 10, 0, 0, 0, time.UTC), Minutes: 60, Status: "logged"},
+	} {
+		if _, err := s.db.InsertEntry(&e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rec := do(t, s.Handler(), http.MethodGet, "/entries/today", "secret", "")
+	var body struct {
+		Entries      []EntryJSON `json:"entries"`
+		TotalMinutes int         `json:"total_minutes"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("%v: %s", err, rec.Body)
+	}
+	if len(body.Entries) != 2 || body.TotalMinutes != 150 {
+		t.Errorf("today = %d entries, %d min; want 2 entries, 150 min", len(body.Entries), body.TotalMinutes)
+	}
+}
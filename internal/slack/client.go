@@ -0,0 +1,117 @@
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const defaultBaseURL = "https://slack.com/api"
+
+// Client calls the Slack Web API with a bot token (xoxb-…). The bot needs
+// the chat:write and im:write scopes.
+type Client struct {
+	token      string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewClient creates a Slack Web API client.
+func NewClient(token string) *Client {
+	return &Client{
+		token:      token,
+		baseURL:    defaultBaseURL,
+		httpClient: &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// SetBaseURL points the client at another API root, e.g. a test server.
+func (c *Client) SetBaseURL(url string) {
+	c.baseURL = url
+}
+
+// OpenDM returns the ID of the direct-message channel between the bot and
+// userID, opening it if needed.
+func (c *Client) OpenDM(ctx context.Context, userID string) (string, error) {
+	var resp struct {
+		Channel struct {
+			ID string `json:"id"`
+		} `json:"channel"`
+	}
+	if err := c.call(ctx, "conversations.open", map[string]string{"users": userID}, &resp); err != nil {
+		return "", err
+	}
+	return resp.Channel.ID, nil
+}
+
+// PostMessage sends text (Slack mrkdwn) to channel and returns the message
+// timestamp.
+func (c *Client) PostMessage(ctx context.Context, channel, text string) (string, error) {
+	var resp struct {
+		TS string `json:"ts"`
+	}
+	if err := c.call(ctx, "chat.postMessage", map[string]string{"channel": channel, "text": text}, &resp); err != nil {
+		return "", err
+	}
+	return resp.TS, nil
+}
+
+// SendDM posts text as a direct message to userID.
+func (c *Client) SendDM(ctx context.Context, userID, text string) error {
+	channel, err := c.OpenDM(ctx, userID)
+	if err != nil {
+		return err
+	}
+	_, err = c.PostMessage(ctx, channel, text)
+	return err
+}
+
+// call POSTs a JSON body to a Web API method. Slack answers 200 with
+// "ok": false on most errors, so both are checked.
+func (c *Client) call(ctx context.Context, method string, body any, out any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("encoding %s request: %w", method, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/"+method, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("slack %s: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("reading slack %s response: %w", method, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("slack %s: status %d", method, resp.StatusCode)
+	}
+
+	var status struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(raw, &status); err != nil {
+		return fmt.Errorf("decoding slack %s response: %w", method, err)
+	}
+	if !status.OK {
+		return fmt.Errorf("slack %s: %s", method, status.Error)
+	}
+	if out != nil {
+		if err := json.Unmarshal(raw, out); err != nil {
+			return fmt.Errorf("decoding slack %s response: %w", method, err)
+		}
+	}
+	return nil
+}
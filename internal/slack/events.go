@@ -0,0 +1,112 @@
+package slack
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// maxSkew is how old a signed request may be before it is treated as a
+// replay, as recommended by Slack.
+const maxSkew = 5 * time.Minute
+
+// Handler receives Slack Events API callbacks. It verifies each request
+// with the app's signing secret and passes the text of direct messages from
+// UserID to OnMessage, which runs in its own goroutine because Slack expects
+// an answer within three seconds.
+type Handler struct {
+	SigningSecret string
+	UserID        string
+	OnMessage     func(text string)
+
+	now func() time.Time
+}
+
+// NewHandler creates a Handler for direct messages from userID.
+func NewHandler(signingSecret, userID string, onMessage func(text string)) *Handler {
+	return &Handler{SigningSecret: signingSecret, UserID: userID, OnMessage: onMessage, now: time.Now}
+}
+
+type envelope struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Event     struct {
+		Type        string `json:"type"`
+		Subtype     string `json:"subtype"`
+		ChannelType string `json:"channel_type"`
+		User        string `json:"user"`
+		BotID       string `json:"bot_id"`
+		Text        string `json:"text"`
+	} `json:"event"`
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		http.Error(w, "reading body", http.StatusBadRequest)
+		return
+	}
+	if !h.verify(r.Header, body) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	var env envelope
+	if err := json.Unmarshal(body, &env); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+
+	switch env.Type {
+	case "url_verification":
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, env.Challenge)
+		return
+	case "event_callback":
+		// Slack redelivers events it thinks timed out; the first delivery
+		// was already handled.
+		if r.Header.Get("X-Slack-Retry-Num") != "" {
+			break
+		}
+		ev := env.Event
+		if ev.Type == "message" && ev.ChannelType == "im" && ev.Subtype == "" && ev.BotID == "" &&
+			ev.User == h.UserID && ev.Text != "" && h.OnMessage != nil {
+			go h.OnMessage(ev.Text)
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// verify checks the v0 request signature: HMAC-SHA256 of "v0:ts:body".
+func (h *Handler) verify(header http.Header, body []byte) bool {
+	ts := header.Get("X-Slack-Request-Timestamp")
+	sent, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return false
+	}
+	now := time.Now
+	if h.now != nil {
+		now = h.now
+	}
+	if d := now().Sub(time.Unix(sent, 0)); d > maxSkew || d < -maxSkew {
+		return false
+	}
+	return hmac.Equal([]byte(header.Get("X-Slack-Signature")), []byte(Sign(h.SigningSecret, ts, body)))
+}
+
+// Sign returns the X-Slack-Signature value for body sent at timestamp ts.
+func Sign(secret, ts string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + ts + ":"))
+	mac.Write(body)
+	return "v0=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,113 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSendDM(t *testing.T) {
+	var calls []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer xoxb-test" {
+			t.Errorf("Authorization = %q", got)
+		}
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		calls = append(calls, r.URL.Path)
+		switch r.URL.Path {
+		case "/conversations.open":
+			if body["users"] != "U1" {
+				t.Errorf("users = %q", body["users"])
+			}
+			w.Write([]byte(`{"ok":true,"channel":{"id":"D1"}}`))
+		case "/chat.postMessage":
+			if body["channel"] != "D1" || body["text"] != "hello" {
+				t.Errorf("postMessage body = %v", body)
+			}
+			w.Write([]byte(`{"ok":true,"ts":"1.2"}`))
+		}
+	}))
+	defer srv.Close()
+
+	c := NewClient("xoxb-test")
+	c.SetBaseURL(srv.URL)
+	if err := c.SendDM(context.Background(), "U1", "hello"); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(calls, ",") != "/conversations.open,/chat.postMessage" {
+		t.Errorf("calls = %v", calls)
+	}
+}
+
+func TestCall_NotOK(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":false,"error":"invalid_auth"}`))
+	}))
+	defer srv.Close()
+
+	c := NewClient("bad")
+	c.SetBaseURL(srv.URL)
+	_, err := c.PostMessage(context.Background(), "D1", "hi")
+	if err == nil || !strings.Contains(err.Error(), "invalid_auth") {
+		t.Fatalf("err = %v, want invalid_auth", err)
+	}
+}
+
+func signedRequest(secret string, ts time.Time, body string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/slack/events", strings.NewReader(body))
+	stamp := strconv.FormatInt(ts.Unix(), 10)
+	r.Header.Set("X-Slack-Request-Timestamp", stamp)
+	r.Header.Set("X-Slack-Signature", Sign(secret, stamp, []byte(body)))
+	return r
+}
+
+func TestHandler(t *testing.T) {
+	now := time.Date(2026, 10, 15, 10, 0, 0, 0, time.UTC)
+	got := make(chan string, 1)
+	h := NewHandler("s3cret", "U1", func(text string) { got <- text })
+	h.now = func() time.Time { return now }
+
+	// URL verification echoes the challenge.
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, signedRequest("s3cret", now, `{"type":"url_verification","challenge":"abc"}`))
+	if rec.Code != http.StatusOK || rec.Body.String() != "abc" {
+		t.Errorf("challenge: %d %q", rec.Code, rec.Body.String())
+	}
+
+	// A DM from the configured user is passed on.
+	msg := `{"type":"event_callback","event":{"type":"message","channel_type":"im","user":"U1","text":"code review"}}`
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, signedRequest("s3cret", now, msg))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	select {
+	case text := <-got:
+		if text != "code review" {
+			t.Errorf("text = %q", text)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("OnMessage not called")
+	}
+
+	// Wrong secret, stale timestamp, other users and bot echoes are ignored.
+	for name, r := range map[string]*http.Request{
+		"bad signature": signedRequest("other", now, msg),
+		"stale":         signedRequest("s3cret", now.Add(-10*time.Minute), msg),
+		"other user":    signedRequest("s3cret", now, strings.Replace(msg, `"U1"`, `"U2"`, 1)),
+		"bot":           signedRequest("s3cret", now, strings.Replace(msg, `"user":"U1"`, `"user":"U1","bot_id":"B1"`, 1)),
+	} {
+		h.ServeHTTP(httptest.NewRecorder(), r)
+		select {
+		case text := <-got:
+			t.Errorf("%s: OnMessage called with %q", name, text)
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,176 @@
+// Package sources gathers the context the AI sees next to the user's
+// description: calendar events, GitHub commits, plugin output and the
+// [context.custom] command. Each is a Provider, fetched side by side.
+package sources
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/ai"
+	"github.com/christopherklint97/clockr/internal/calendar"
+	"github.com/christopherklint97/clockr/internal/config"
+	"github.com/christopherklint97/clockr/internal/github"
+	"github.com/christopherklint97/clockr/internal/plugin"
+)
+
+// Item is one piece of context, labelled with its source and time.
+type Item = ai.ContextItem
+
+// Provider fetches context about a time window.
+type Provider interface {
+	// Name is shown in warnings, e.g. "calendar".
+	Name() string
+	// Fetch may return the items it got along with an error about the rest,
+	// e.g. one calendar of several.
+	Fetch(ctx context.Context, start, end time.Time) ([]Item, error)
+}
+
+// Collect fetches every provider at once and returns the items in provider
+// order, with their times in start's zone. A provider that fails is passed to warn, when it is not nil, and
+// the others, and whatever it did return, still count.
+func Collect(ctx context.Context, providers []Provider, start, end time.Time, warn func(Provider, error)) []Item {
+	results := make([][]Item, len(providers))
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	for i, p := range providers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			items, err := p.Fetch(ctx, start, end)
+			if err != nil && warn != nil {
+				mu.Lock()
+				warn(p, err)
+				mu.Unlock()
+			}
+			results[i] = items
+		}()
+	}
+	wg.Wait()
+
+	var out []Item
+	for _, items := range results {
+		for _, item := range items {
+			out = append(out, item.In(start.Location()))
+		}
+	}
+	return out
+}
+
+// Calendar provides calendar events with their start and length. The
+// events of the last fetch stay available from Events, for splitting at
+// meetings.
+type Calendar struct {
+	fetch func(ctx context.Context, start, end time.Time) ([]calendar.Event, error)
+
+	mu     sync.Mutex
+	events []calendar.Event
+}
+
+// NewCalendar returns a calendar provider that gets its events from fetch.
+func NewCalendar(fetch func(ctx context.Context, start, end time.Time) ([]calendar.Event, error)) *Calendar {
+	return &Calendar{fetch: fetch}
+}
+
+func (c *Calendar) Name() string { return "calendar" }
+
+func (c *Calendar) Fetch(ctx context.Context, start, end time.Time) ([]Item, error) {
+	events, err := c.fetch(ctx, start, end)
+	if err != nil && len(events) == 0 {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.events = events
+	c.mu.Unlock()
+	return CalendarItems(events, start.Location()), err
+}
+
+// CalendarItems labels events as calendar context with their start in loc
+// and length.
+func CalendarItems(events []calendar.Event, loc *time.Location) []Item {
+	items := make([]Item, len(events))
+	for i, e := range events {
+		items[i] = Item{Source: ai.SourceCalendar, Text: e.Summary, Time: e.StartTime, Minutes: int(e.EndTime.Sub(e.StartTime).Minutes())}.In(loc)
+	}
+	return items
+}
+
+// Events returns the events of the last successful fetch.
+func (c *Calendar) Events() []calendar.Event {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.events
+}
+
+// GitHub provides commit and pull request messages from fetch.
+func GitHub(fetch func(ctx context.Context, start, end time.Time) ([]github.CommitContext, error)) Provider {
+	return githubProvider{fetch: fetch}
+}
+
+type githubProvider struct {
+	fetch func(ctx context.Context, start, end time.Time) ([]github.CommitContext, error)
+}
+
+func (g githubProvider) Name() string { return "GitHub" }
+
+func (g githubProvider) Fetch(ctx context.Context, start, end time.Time) ([]Item, error) {
+	commits, err := g.fetch(ctx, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("GitHub fetch failed: %w", err)
+	}
+	return GitHubItems(commits, start.Location()), nil
+}
+
+// GitHubItems labels commits and PRs as context with their time in loc.
+func GitHubItems(commits []github.CommitContext, loc *time.Location) []Item {
+	items := make([]Item, len(commits))
+	for i, c := range commits {
+		source := ai.SourceCommit
+		if c.PR {
+			source = ai.SourcePR
+		}
+		items[i] = Item{Source: source, Text: c.Message, Time: c.Date}.In(loc)
+	}
+	return items
+}
+
+// Plugins returns a provider for each plugin that supports "context".
+func Plugins(plugins []plugin.Plugin) []Provider {
+	var out []Provider
+	for _, p := range plugins {
+		if p.Can(plugin.ActionContext) {
+			out = append(out, pluginProvider{p})
+		}
+	}
+	return out
+}
+
+type pluginProvider struct {
+	p plugin.Plugin
+}
+
+func (p pluginProvider) Name() string { return p.p.Name }
+
+func (p pluginProvider) Fetch(ctx context.Context, start, end time.Time) ([]Item, error) {
+	lines, err := p.p.Context(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return ai.TextContext(p.Name(), lines), nil
+}
+
+// Custom returns the [context.custom] command as a provider, or none when
+// no command is set.
+func Custom(cfg *config.Config) []Provider {
+	c := cfg.Context.Custom
+	if c.Command == "" {
+		return nil
+	}
+	name := c.Name
+	if name == "" {
+		name = "custom"
+	}
+	return Plugins([]plugin.Plugin{plugin.NewCommand(name, c.Command)})
+}
@@ -0,0 +1,102 @@
+package sources
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/ai"
+	"github.com/christopherklint97/clockr/internal/calendar"
+	"github.com/christopherklint97/clockr/internal/config"
+	"github.com/christopherklint97/clockr/internal/github"
+)
+
+func TestCollect(t *testing.T) {
+	cal := NewCalendar(func(context.Context, time.Time, time.Time) ([]calendar.Event, error) {
+		start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+		return []calendar.Event{{Summary: "Standup", StartTime: start, EndTime: start.Add(15 * time.Minute)}}, nil
+	})
+	gh := GitHub(func(context.Context, time.Time, time.Time) ([]github.CommitContext, error) {
+		return []github.CommitContext{{Message: "Fix login"}, {Message: "Add tests", PR: true}}, nil
+	})
+	broken := GitHub(func(context.Context, time.Time, time.Time) ([]github.CommitContext, error) {
+		return nil, errors.New("rate limited")
+	})
+
+	var warned []string
+	items := Collect(context.Background(), []Provider{cal, broken, gh}, time.Now().Add(-time.Hour), time.Now(), func(p Provider, err error) {
+		warned = append(warned, p.Name()+": "+err.Error())
+	})
+	if !slices.Equal(ai.ContextTexts(items), []string{"Standup", "Fix login", "Add tests"}) {
+		t.Errorf("items = %+v", items)
+	}
+	if items[0].Source != ai.SourceCalendar || items[0].Minutes != 15 || items[1].Source != ai.SourceCommit || items[2].Source != ai.SourcePR {
+		t.Errorf("items are not labelled: %+v", items)
+	}
+	if !slices.Equal(warned, []string{"GitHub: GitHub fetch failed: rate limited"}) {
+		t.Errorf("warnings = %q", warned)
+	}
+	if ev := cal.Events(); len(ev) != 1 || ev[0].Summary != "Standup" {
+		t.Errorf("calendar events = %+v", ev)
+	}
+}
+
+func TestCollect_ScheduleZone(t *testing.T) {
+	stockholm, err := time.LoadLocation("Europe/Stockholm")
+	if err != nil {
+		t.Skip(err)
+	}
+	// A Graph event comes back in UTC; the window is in the schedule's zone.
+	event := time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC)
+	cal := NewCalendar(func(context.Context, time.Time, time.Time) ([]calendar.Event, error) {
+		return []calendar.Event{{Summary: "Standup", StartTime: event, EndTime: event.Add(15 * time.Minute)}}, nil
+	})
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, stockholm)
+	items := Collect(context.Background(), []Provider{cal}, start, start.Add(time.Hour), nil)
+	if len(items) != 1 || items[0].String() != "[calendar 09:00–09:15, 15 min] Standup" {
+		t.Errorf("items = %v, want the event at 09:00 Stockholm time", items)
+	}
+}
+
+func TestCollect_PartialCalendar(t *testing.T) {
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	cal := NewCalendar(func(context.Context, time.Time, time.Time) ([]calendar.Event, error) {
+		return []calendar.Event{{Summary: "Standup", StartTime: start, EndTime: start.Add(15 * time.Minute)}}, errors.New("personal calendar: offline")
+	})
+
+	var warned int
+	items := Collect(context.Background(), []Provider{cal}, start, start.Add(time.Hour), func(Provider, error) { warned++ })
+	if warned != 1 || len(items) != 1 || len(cal.Events()) != 1 {
+		t.Errorf("warned %d, items %+v; want the work calendar kept with a warning", warned, items)
+	}
+}
+
+func TestCustom(t *testing.T) {
+	cfg := config.DefaultConfig()
+	if got := Custom(&cfg); len(got) != 0 {
+		t.Errorf("no command should mean no provider, got %d", len(got))
+	}
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell command")
+	}
+
+	cfg.Context.Custom = config.CustomContextConfig{Name: "browser", Command: `echo '{"items":["Read the RFC"]}'`}
+	providers := Custom(&cfg)
+	if len(providers) != 1 || providers[0].Name() != "browser" {
+		t.Fatalf("providers = %+v", providers)
+	}
+	items, err := providers[0].Fetch(context.Background(), time.Now().Add(-time.Hour), time.Now())
+	if err != nil || len(items) != 1 || items[0].Text != "Read the RFC" || items[0].Source != "browser" {
+		t.Errorf("Fetch = %+v, %v", items, err)
+	}
+
+	cfg.Context.Custom.Command = `echo '{"error":"no history"}'`
+	_, err = Custom(&cfg)[0].Fetch(context.Background(), time.Now(), time.Now())
+	if err == nil || !strings.Contains(err.Error(), "no history") {
+		t.Errorf("expected the command's error, got %v", err)
+	}
+}
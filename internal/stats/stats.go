@@ -0,0 +1,272 @@
+// Package stats computes the trends behind 'clockr stats': weekly time per
+// project, client, source, billable flag and tag, how many projects each day
+// was split across, and how the AI's suggestions fared. Filter narrows the
+// entries of both 'clockr stats' and 'clockr status'.
+package stats
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/ai"
+	"github.com/christopherklint97/clockr/internal/store"
+)
+
+// Trend is the time logged on one project, client, source, billable flag
+// or tag per week.
+type Trend struct {
+	Name  string
+	Weeks []int // minutes per week, oldest first
+	Total int
+}
+
+// Day is how many distinct projects a day's entries were on.
+type Day struct {
+	Date     time.Time
+	Projects int
+}
+
+// Suggestions is how the AI's suggestions fared in one period.
+type Suggestions struct {
+	Entries    int     // entries logged from a suggestion
+	Confidence float64 // mean confidence of the allocations they came from
+	Accepted   int     // suggestions accepted, from suggestion_edits
+	Edited     int     // of those, how many were changed first
+}
+
+// EditRate is the share of accepted suggestions that were changed.
+func (s Suggestions) EditRate() float64 {
+	if s.Accepted == 0 {
+		return 0
+	}
+	return float64(s.Edited) / float64(s.Accepted)
+}
+
+// ByProject returns the minutes per week on each project, starting at from,
+// most time first.
+func ByProject(entries []store.Entry, from time.Time, weeks int) []Trend {
+	return weekly(entries, from, weeks, func(e store.Entry) string {
+		if e.ClientName != "" {
+			return e.ClientName + " / " + e.ProjectName
+		}
+		return e.ProjectName
+	})
+}
+
+// ByClient is ByProject per client; projects without one are "No client".
+func ByClient(entries []store.Entry, from time.Time, weeks int) []Trend {
+	return weekly(entries, from, weeks, func(e store.Entry) string {
+		if e.ClientName != "" {
+			return e.ClientName
+		}
+		return "No client"
+	})
+}
+
+// BySource is ByProject per source, the way each entry was logged; entries
+// from before sources were recorded are "unknown".
+func BySource(entries []store.Entry, from time.Time, weeks int) []Trend {
+	return weekly(entries, from, weeks, func(e store.Entry) string {
+		if e.Source != "" {
+			return e.Source
+		}
+		return "unknown"
+	})
+}
+
+// ByBillable is ByProject split into "billable" and "non-billable".
+func ByBillable(entries []store.Entry, from time.Time, weeks int) []Trend {
+	return weekly(entries, from, weeks, func(e store.Entry) string {
+		if e.Billable {
+			return "billable"
+		}
+		return "non-billable"
+	})
+}
+
+// ByTag is ByProject per Clockify tag ID. An entry with several tags counts
+// towards each of them, so the totals can add up to more than the time
+// logged; untagged entries are "No tag".
+func ByTag(entries []store.Entry, from time.Time, weeks int) []Trend {
+	return weeklyMulti(entries, from, weeks, func(e store.Entry) []string {
+		if len(e.TagIDs) == 0 {
+			return []string{"No tag"}
+		}
+		return e.TagIDs
+	})
+}
+
+func weekly(entries []store.Entry, from time.Time, weeks int, key func(store.Entry) string) []Trend {
+	return weeklyMulti(entries, from, weeks, func(e store.Entry) []string { return []string{key(e)} })
+}
+
+func weeklyMulti(entries []store.Entry, from time.Time, weeks int, keys func(store.Entry) []string) []Trend {
+	byName := make(map[string]*Trend)
+	var trends []*Trend
+	for _, e := range entries {
+		for _, name := range keys(e) {
+			t, ok := byName[name]
+			if !ok {
+				t = &Trend{Name: name, Weeks: make([]int, weeks)}
+				byName[name] = t
+				trends = append(trends, t)
+			}
+			for w := range weeks {
+				start := from.AddDate(0, 0, 7*w)
+				m := e.MinutesWithin(start, start.AddDate(0, 0, 7))
+				t.Weeks[w] += m
+				t.Total += m
+			}
+		}
+	}
+
+	out := make([]Trend, 0, len(trends))
+	for _, t := range trends {
+		if t.Total > 0 {
+			out = append(out, *t)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Total != out[j].Total {
+			return out[i].Total > out[j].Total
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// Filter narrows a report to some of its entries. The zero Filter keeps
+// them all.
+type Filter struct {
+	Source   string // only entries logged this way, e.g. "scheduler"
+	Billable *bool  // only billable (true) or non-billable (false) entries
+	Tag      string // only entries with this Clockify tag ID
+}
+
+// Match reports whether e passes f.
+func (f Filter) Match(e store.Entry) bool {
+	if f.Source != "" && e.Source != f.Source {
+		return false
+	}
+	if f.Billable != nil && e.Billable != *f.Billable {
+		return false
+	}
+	if f.Tag != "" && !slices.Contains(e.TagIDs, f.Tag) {
+		return false
+	}
+	return true
+}
+
+// String describes f for a report header, e.g. "billable entries logged
+// by scheduler with tag 5f1c".
+func (f Filter) String() string {
+	s := "entries"
+	if f.Billable != nil {
+		if *f.Billable {
+			s = "billable entries"
+		} else {
+			s = "non-billable entries"
+		}
+	}
+	if f.Source != "" {
+		s += " logged by " + f.Source
+	}
+	if f.Tag != "" {
+		s += " with tag " + f.Tag
+	}
+	return s
+}
+
+// Apply returns the entries that pass f.
+func (f Filter) Apply(entries []store.Entry) []store.Entry {
+	if f == (Filter{}) {
+		return entries
+	}
+	var out []store.Entry
+	for _, e := range entries {
+		if f.Match(e) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// ContextSwitches counts the distinct projects worked on each day in
+// [from, to).
+func ContextSwitches(entries []store.Entry, from, to time.Time) []Day {
+	var days []Day
+	for d := from; d.Before(to); d = d.AddDate(0, 0, 1) {
+		next := d.AddDate(0, 0, 1)
+		projects := make(map[string]bool)
+		for _, e := range entries {
+			if e.MinutesWithin(d, next) > 0 {
+				projects[e.ProjectID] = true
+			}
+		}
+		days = append(days, Day{Date: d, Projects: len(projects)})
+	}
+	return days
+}
+
+// Confidence returns the mean confidence of the allocations that entries
+// were logged from, and how many entries that covers. suggestions holds the
+// stored suggestions by ID; entries whose suggestion is missing are left out.
+func Confidence(entries []store.Entry, suggestions map[int]ai.BatchSuggestion) (float64, int) {
+	var sum float64
+	n := 0
+	for _, e := range entries {
+		suggestion, ok := suggestions[e.SuggestionID]
+		if e.SuggestionID == 0 || !ok {
+			continue
+		}
+		if i := SuggestedAllocation(e, suggestion.Allocations); i >= 0 {
+			sum += suggestion.Allocations[i].Confidence
+			n++
+		}
+	}
+	if n == 0 {
+		return 0, 0
+	}
+	return sum / float64(n), n
+}
+
+// SuggestedAllocation returns the index of the allocation e was logged
+// from, or -1 when none is on e's project. Batch allocations are matched by
+// date and start time first.
+func SuggestedAllocation(e store.Entry, allocs []ai.BatchAllocation) int {
+	match := -1
+	for i, a := range allocs {
+		if a.ProjectID != e.ProjectID {
+			continue
+		}
+		if a.Date != "" && a.Date == e.StartTime.Format("2006-01-02") && a.StartTime == e.StartTime.Format("15:04") {
+			return i
+		}
+		if a.Description == e.Description || match < 0 {
+			match = i
+		}
+	}
+	return match
+}
+
+var sparks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline draws values as a row of block characters scaled to the
+// largest, with "·" for zero.
+func Sparkline(values []float64) string {
+	top := 0.0
+	for _, v := range values {
+		top = max(top, v)
+	}
+	var sb strings.Builder
+	for _, v := range values {
+		if v <= 0 || top <= 0 {
+			sb.WriteRune('·')
+			continue
+		}
+		sb.WriteRune(sparks[min(int(v/top*float64(len(sparks)-1)+0.5), len(sparks)-1)])
+	}
+	return sb.String()
+}
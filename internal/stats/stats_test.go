@@ -0,0 +1,169 @@
+package stats
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/ai"
+	"github.com/christopherklint97/clockr/internal/store"
+)
+
+func entry(day time.Time, hour, minutes int, projectID, project, client string) store.Entry {
+	start := day.Add(time.Duration(hour) * time.Hour)
+	return store.Entry{
+		ProjectID:   projectID,
+		ProjectName: project,
+		ClientName:  client,
+		StartTime:   start,
+		EndTime:     start.Add(time.Duration(minutes) * time.Minute),
+		Minutes:     minutes,
+	}
+}
+
+func TestByProjectAndClient(t *testing.T) {
+	monday := time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC)
+	entries := []store.Entry{
+		entry(monday, 9, 60, "p1", "Web", "Acme"),
+		entry(monday.AddDate(0, 0, 7), 9, 120, "p1", "Web", "Acme"),
+		entry(monday.AddDate(0, 0, 8), 9, 30, "p2", "Ops", ""),
+		entry(monday.AddDate(0, 0, 8), 10, 45, "p3", "API", "Acme"),
+	}
+
+	projects := ByProject(entries, monday, 2)
+	want := []Trend{
+		{Name: "Acme / Web", Weeks: []int{60, 120}, Total: 180},
+		{Name: "Acme / API", Weeks: []int{0, 45}, Total: 45},
+		{Name: "Ops", Weeks: []int{0, 30}, Total: 30},
+	}
+	if !reflect.DeepEqual(projects, want) {
+		t.Errorf("ByProject = %+v, want %+v", projects, want)
+	}
+
+	clients := ByClient(entries, monday, 2)
+	if len(clients) != 2 || clients[0].Name != "Acme" || clients[0].Total != 225 || clients[1].Name != "No client" {
+		t.Errorf("ByClient = %+v", clients)
+	}
+}
+
+// tagged is entry on project p1 with a source, billable flag and tags.
+func tagged(day time.Time, hour, minutes int, source string, billable bool, tags ...string) store.Entry {
+	e := entry(day, hour, minutes, "p1", "Web", "")
+	e.Source, e.Billable, e.TagIDs = source, billable, tags
+	return e
+}
+
+func TestBySourceBillableAndTag(t *testing.T) {
+	monday := time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC)
+	entries := []store.Entry{
+		tagged(monday, 9, 60, store.SourceScheduler, true, "t1", "t2"),
+		tagged(monday, 10, 30, store.SourceManual, false, "t2"),
+		tagged(monday.AddDate(0, 0, 7), 9, 45, "", true),
+	}
+
+	sources := BySource(entries, monday, 2)
+	want := []Trend{
+		{Name: "scheduler", Weeks: []int{60, 0}, Total: 60},
+		{Name: "unknown", Weeks: []int{0, 45}, Total: 45},
+		{Name: "manual", Weeks: []int{30, 0}, Total: 30},
+	}
+	if !reflect.DeepEqual(sources, want) {
+		t.Errorf("BySource = %+v, want %+v", sources, want)
+	}
+
+	billable := ByBillable(entries, monday, 2)
+	want = []Trend{
+		{Name: "billable", Weeks: []int{60, 45}, Total: 105},
+		{Name: "non-billable", Weeks: []int{30, 0}, Total: 30},
+	}
+	if !reflect.DeepEqual(billable, want) {
+		t.Errorf("ByBillable = %+v, want %+v", billable, want)
+	}
+
+	// The entry with two tags counts towards both.
+	tags := ByTag(entries, monday, 2)
+	want = []Trend{
+		{Name: "t2", Weeks: []int{90, 0}, Total: 90},
+		{Name: "t1", Weeks: []int{60, 0}, Total: 60},
+		{Name: "No tag", Weeks: []int{0, 45}, Total: 45},
+	}
+	if !reflect.DeepEqual(tags, want) {
+		t.Errorf("ByTag = %+v, want %+v", tags, want)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	monday := time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC)
+	a := tagged(monday, 9, 60, store.SourceScheduler, true, "t1", "t2")
+	b := tagged(monday, 10, 30, store.SourceManual, false, "t2")
+	c := tagged(monday, 11, 45, store.SourceScheduler, false)
+	entries := []store.Entry{a, b, c}
+	yes, no := true, false
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []store.Entry
+	}{
+		{"none", Filter{}, entries},
+		{"source", Filter{Source: store.SourceScheduler}, []store.Entry{a, c}},
+		{"billable", Filter{Billable: &yes}, []store.Entry{a}},
+		{"non-billable", Filter{Billable: &no}, []store.Entry{b, c}},
+		{"tag", Filter{Tag: "t2"}, []store.Entry{a, b}},
+		{"combined", Filter{Source: store.SourceScheduler, Billable: &no}, []store.Entry{c}},
+		{"no match", Filter{Tag: "t3"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Apply(entries); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Apply() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContextSwitches(t *testing.T) {
+	monday := time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC)
+	entries := []store.Entry{
+		entry(monday, 9, 60, "p1", "Web", ""),
+		entry(monday, 10, 60, "p2", "Ops", ""),
+		entry(monday, 11, 60, "p1", "Web", ""),
+		entry(monday, 23, 120, "p3", "API", ""), // runs into Tuesday
+	}
+	days := ContextSwitches(entries, monday, monday.AddDate(0, 0, 3))
+	got := []int{days[0].Projects, days[1].Projects, days[2].Projects}
+	if want := []int{3, 1, 0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("projects per day = %v, want %v", got, want)
+	}
+}
+
+func TestConfidence(t *testing.T) {
+	day := time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC)
+	suggestions := map[int]ai.BatchSuggestion{
+		1: {Allocations: []ai.BatchAllocation{
+			{ProjectID: "p1", Confidence: 0.9},
+			{ProjectID: "p2", Confidence: 0.5},
+		}},
+	}
+	a := entry(day, 9, 60, "p1", "Web", "")
+	a.SuggestionID = 1
+	b := entry(day, 10, 60, "p2", "Ops", "")
+	b.SuggestionID = 1
+	changed := entry(day, 11, 60, "p3", "API", "") // project changed from the suggestion
+	changed.SuggestionID = 1
+	manual := entry(day, 12, 60, "p1", "Web", "")
+
+	avg, n := Confidence([]store.Entry{a, b, changed, manual}, suggestions)
+	if n != 2 || avg < 0.699 || avg > 0.701 {
+		t.Errorf("Confidence = %.3f over %d, want 0.700 over 2", avg, n)
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	if got := Sparkline([]float64{0, 1, 4, 8}); got != "·▂▅█" {
+		t.Errorf("Sparkline = %q", got)
+	}
+	if got := Sparkline([]float64{0, 0}); got != "··" {
+		t.Errorf("Sparkline of zeros = %q", got)
+	}
+}
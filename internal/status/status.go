@@ -0,0 +1,153 @@
+// Package status builds the --output json form of 'clockr status' and the
+// entry JSON shared with 'clockr entry show'.
+package status
+
+import (
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/audit"
+	"github.com/christopherklint97/clockr/internal/scheduler"
+	"github.com/christopherklint97/clockr/internal/store"
+)
+
+// Report is 'clockr status' as JSON. Date is set for a single day; From,
+// To and Days for --week and --month.
+type Report struct {
+	Date            string                   `json:"date,omitempty"`
+	From            string                   `json:"from,omitempty"`
+	To              string                   `json:"to,omitempty"` // inclusive
+	Scheduler       *scheduler.ControlStatus `json:"scheduler"`    // null when the scheduler is not running
+	Days            []Day                    `json:"days,omitempty"`
+	Entries         []Entry                  `json:"entries"`
+	TotalMinutes    int                      `json:"total_minutes"`
+	OvertimeMinutes int                      `json:"overtime_minutes"`
+	ExpectedMinutes int                      `json:"expected_minutes"` // work hours so far
+	FlexMinutes     int                      `json:"flex_minutes"`     // TotalMinutes - ExpectedMinutes
+	Skipped         []Skip                   `json:"skipped"`
+	Gaps            []Gap                    `json:"gaps"`
+}
+
+// Day is one day of a --week or --month report.
+type Day struct {
+	Date            string `json:"date"`
+	Entries         int    `json:"entries"`
+	TotalMinutes    int    `json:"total_minutes"`
+	OvertimeMinutes int    `json:"overtime_minutes"`
+}
+
+// Gap is an unlogged stretch of work hours.
+type Gap struct {
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+	Minutes int       `json:"minutes"`
+}
+
+// Skip is the time skipped for one reason.
+type Skip struct {
+	Reason  string `json:"reason"`
+	Minutes int    `json:"minutes"`
+}
+
+// Entry is a stored entry as JSON.
+type Entry struct {
+	ID          int       `json:"id"`
+	ClockifyID  string    `json:"clockify_id,omitempty"`
+	ProjectID   string    `json:"project_id"`
+	ProjectName string    `json:"project_name"`
+	ClientName  string    `json:"client_name,omitempty"`
+	Description string    `json:"description"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	Minutes     int       `json:"minutes"`
+	Status      string    `json:"status"`
+	Overtime    bool      `json:"overtime"`
+	TaskID      string    `json:"task_id,omitempty"`
+	TagIDs      []string  `json:"tag_ids,omitempty"`
+	Billable    bool      `json:"billable"`
+	Source      string    `json:"source,omitempty"`
+}
+
+// NewEntry converts a stored entry.
+func NewEntry(e store.Entry) Entry {
+	return Entry{
+		ID:          e.ID,
+		ClockifyID:  e.ClockifyID,
+		ProjectID:   e.ProjectID,
+		ProjectName: e.ProjectName,
+		ClientName:  e.ClientName,
+		Description: e.Description,
+		Start:       e.StartTime,
+		End:         e.EndTime,
+		Minutes:     e.Minutes,
+		Status:      e.Status,
+		Overtime:    e.Overtime,
+		TaskID:      e.TaskID,
+		TagIDs:      e.TagIDs,
+		Billable:    e.Billable,
+		Source:      e.Source,
+	}
+}
+
+// New builds the report for [from, to), with a row per day when byDay is
+// set. sched is the running scheduler's status, or nil.
+func New(sched *scheduler.ControlStatus, entries []store.Entry, skips []store.Skip, gaps []audit.Interval, from, to time.Time, byDay bool) Report {
+	r := Report{
+		Scheduler: sched,
+		Entries:   []Entry{},
+		Skipped:   []Skip{},
+		Gaps:      []Gap{},
+	}
+	if !byDay {
+		r.Date = from.Format(time.DateOnly)
+	} else {
+		r.From = from.Format(time.DateOnly)
+		r.To = to.AddDate(0, 0, -1).Format(time.DateOnly)
+		for d := from; d.Before(to); d = d.AddDate(0, 0, 1) {
+			day := Day{Date: d.Format(time.DateOnly)}
+			for _, e := range entries {
+				if e.StartTime.Before(d.AddDate(0, 0, 1)) && e.EndTime.After(d) {
+					day.Entries++
+				}
+			}
+			day.TotalMinutes, day.OvertimeMinutes = Minutes(entries, d, d.AddDate(0, 0, 1))
+			r.Days = append(r.Days, day)
+		}
+	}
+
+	for _, e := range entries {
+		r.Entries = append(r.Entries, NewEntry(e))
+	}
+	r.TotalMinutes, r.OvertimeMinutes = Minutes(entries, from, to)
+	for _, t := range store.SkipTotals(skips) {
+		r.Skipped = append(r.Skipped, Skip{Reason: t.Reason, Minutes: t.Minutes})
+	}
+	for _, g := range gaps {
+		r.Gaps = append(r.Gaps, Gap{Start: g.Start, End: g.End, Minutes: g.Minutes()})
+	}
+	return r
+}
+
+// Flex returns the work hours expected in [from, to), as given by work,
+// and the flex balance: the regular time logged there minus that, positive
+// when ahead. Overtime is reported beside the balance, never in it.
+func Flex(entries []store.Entry, work []audit.Interval, from, to time.Time) (expected, balance int) {
+	for _, w := range work {
+		expected += w.Minutes()
+	}
+	regular, _ := Minutes(entries, from, to)
+	return expected, regular - expected
+}
+
+// Minutes sums the regular and overtime minutes of entries that fall in
+// [from, to).
+func Minutes(entries []store.Entry, from, to time.Time) (regular, overtime int) {
+	for _, e := range entries {
+		minutes := e.MinutesWithin(from, to)
+		if e.Overtime {
+			overtime += minutes
+		} else {
+			regular += minutes
+		}
+	}
+	return regular, overtime
+}
@@ -0,0 +1,122 @@
+package status
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/audit"
+	"github.com/christopherklint97/clockr/internal/scheduler"
+	"github.com/christopherklint97/clockr/internal/store"
+)
+
+func entry(start time.Time, minutes int, overtime bool) store.Entry {
+	return store.Entry{
+		ProjectID:   "p1",
+		ProjectName: "Web",
+		Description: "work",
+		StartTime:   start,
+		EndTime:     start.Add(time.Duration(minutes) * time.Minute),
+		Minutes:     minutes,
+		Status:      "logged",
+		Overtime:    overtime,
+	}
+}
+
+func TestNew_Day(t *testing.T) {
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	entries := []store.Entry{
+		entry(day.Add(9*time.Hour), 60, false),
+		entry(day.Add(18*time.Hour), 30, true),
+	}
+	skips := []store.Skip{{Minutes: 15, Reason: "lunch"}, {Minutes: 30, Reason: "lunch"}, {Minutes: 10}}
+	gaps := []audit.Interval{{Start: day.Add(10 * time.Hour), End: day.Add(11 * time.Hour)}}
+
+	r := New(nil, entries, skips, gaps, day, day.AddDate(0, 0, 1), false)
+	if r.Date != "2026-03-02" || r.From != "" || r.Days != nil {
+		t.Errorf("report period = %q %q %q %v, want only the date", r.Date, r.From, r.To, r.Days)
+	}
+	if r.TotalMinutes != 60 || r.OvertimeMinutes != 30 || len(r.Entries) != 2 {
+		t.Errorf("report = %d min, %d overtime, %d entries; want 60, 30, 2", r.TotalMinutes, r.OvertimeMinutes, len(r.Entries))
+	}
+	if want := []Skip{{"lunch", 45}, {"", 10}}; !reflect.DeepEqual(r.Skipped, want) {
+		t.Errorf("skipped = %+v, want %+v", r.Skipped, want)
+	}
+	if len(r.Gaps) != 1 || r.Gaps[0].Minutes != 60 {
+		t.Errorf("gaps = %+v, want one 60min gap", r.Gaps)
+	}
+}
+
+func TestNew_Week(t *testing.T) {
+	monday := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	entries := []store.Entry{
+		entry(monday.Add(9*time.Hour), 120, false),
+		// Tuesday 23:00 to Wednesday 01:00 counts an hour on each day.
+		entry(monday.AddDate(0, 0, 1).Add(23*time.Hour), 120, false),
+	}
+
+	r := New(nil, entries, nil, nil, monday, monday.AddDate(0, 0, 7), true)
+	if r.Date != "" || r.From != "2026-03-02" || r.To != "2026-03-08" {
+		t.Errorf("report period = %q %q %q, want 2026-03-02 to 2026-03-08", r.Date, r.From, r.To)
+	}
+	if len(r.Days) != 7 {
+		t.Fatalf("days = %d, want 7", len(r.Days))
+	}
+	want := []Day{
+		{Date: "2026-03-02", Entries: 1, TotalMinutes: 120},
+		{Date: "2026-03-03", Entries: 1, TotalMinutes: 60},
+		{Date: "2026-03-04", Entries: 1, TotalMinutes: 60},
+	}
+	if !reflect.DeepEqual(r.Days[:3], want) {
+		t.Errorf("days = %+v, want %+v", r.Days[:3], want)
+	}
+	if r.TotalMinutes != 240 {
+		t.Errorf("total = %d, want 240", r.TotalMinutes)
+	}
+}
+
+func TestFlex(t *testing.T) {
+	monday := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	work := []audit.Interval{
+		{Start: monday.Add(9 * time.Hour), End: monday.Add(17 * time.Hour)},
+		{Start: monday.AddDate(0, 0, 1).Add(9 * time.Hour), End: monday.AddDate(0, 0, 1).Add(12 * time.Hour)}, // up to now
+	}
+	entries := []store.Entry{
+		entry(monday.Add(9*time.Hour), 420, false),
+		entry(monday.AddDate(0, 0, 1).Add(9*time.Hour), 240, false),
+		entry(monday.Add(20*time.Hour), 90, true), // overtime stays out of the balance
+	}
+
+	expected, balance := Flex(entries, work, monday, monday.AddDate(0, 0, 7))
+	if expected != 660 || balance != 0 {
+		t.Errorf("Flex = %d expected, %+d balance; want 660, 0", expected, balance)
+	}
+	expected, balance = Flex(entries[:1], work, monday, monday.AddDate(0, 0, 7))
+	if expected != 660 || balance != -240 {
+		t.Errorf("Flex without Tuesday = %d expected, %+d balance; want 660, -240", expected, balance)
+	}
+}
+
+func TestNew_JSON(t *testing.T) {
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+
+	// Empty lists stay lists, and a stopped scheduler is null.
+	data, err := json.Marshal(New(nil, nil, nil, nil, day, day.AddDate(0, 0, 1), false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"date":"2026-03-02","scheduler":null,"entries":[],"total_minutes":0,"overtime_minutes":0,"expected_minutes":0,"flex_minutes":0,"skipped":[],"gaps":[]}`
+	if string(data) != want {
+		t.Errorf("JSON = %s\nwant   %s", data, want)
+	}
+
+	data, err = json.Marshal(New(&scheduler.ControlStatus{}, nil, nil, nil, day, day.AddDate(0, 0, 1), false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), `"scheduler":null`) {
+		t.Errorf("running scheduler missing from %s", data)
+	}
+}
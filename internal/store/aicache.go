@@ -0,0 +1,47 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// aiCacheRetention is how long AI responses are kept at most, whatever TTL
+// readers use.
+const aiCacheRetention = 24 * time.Hour
+
+// LoadAIResponse returns the AI response saved under key, or nil if there is
+// none younger than ttl.
+func (db *DB) LoadAIResponse(key string, ttl time.Duration) ([]byte, error) {
+	var data string
+	err := db.QueryRow(
+		"SELECT data FROM ai_cache WHERE key = ? AND created_at >= ?",
+		key, time.Now().Add(-ttl).UTC().Format(time.RFC3339),
+	).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loading AI response: %w", err)
+	}
+	return []byte(data), nil
+}
+
+// SaveAIResponse stores an AI response received now and drops responses
+// older than a day.
+func (db *DB) SaveAIResponse(key string, data []byte) error {
+	now := time.Now().UTC()
+	_, err := db.Exec(
+		`INSERT INTO ai_cache (key, data, created_at) VALUES (?, ?, ?)
+		 ON CONFLICT(key) DO UPDATE SET data = excluded.data, created_at = excluded.created_at`,
+		key, string(data), now.Format(time.RFC3339),
+	)
+	if err != nil {
+		return fmt.Errorf("saving AI response: %w", err)
+	}
+	if _, err := db.Exec("DELETE FROM ai_cache WHERE created_at < ?", now.Add(-aiCacheRetention).Format(time.RFC3339)); err != nil {
+		return fmt.Errorf("pruning AI responses: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package store
+
+import "fmt"
+
+// AuditDecision records how one field of an entry that differed between
+// clockr and Clockify was resolved.
+type AuditDecision struct {
+	EntryID    int
+	ClockifyID string
+	Field      string
+	Local      string
+	Remote     string
+	Chosen     string // "local", "remote" or "merged"
+	Value      string // the value kept
+}
+
+// LogAuditDecision appends a decision to the audit log.
+func (db *DB) LogAuditDecision(d AuditDecision) error {
+	_, err := db.Exec(
+		`INSERT INTO audit_log (entry_id, clockify_id, field, local_value, remote_value, chosen, value)
+		 VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		d.EntryID, d.ClockifyID, d.Field, d.Local, d.Remote, d.Chosen, d.Value,
+	)
+	if err != nil {
+		return fmt.Errorf("logging audit decision: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// LoadCache returns a cached Clockify response and when it was fetched, or
+// nil data if there is none.
+func (db *DB) LoadCache(key string) ([]byte, time.Time, error) {
+	var data, fetchedStr string
+	err := db.QueryRow("SELECT data, fetched_at FROM clockify_cache WHERE key = ?", key).Scan(&data, &fetchedStr)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, time.Time{}, nil
+	}
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("loading cache: %w", err)
+	}
+	fetchedAt, _ := time.Parse(time.RFC3339, fetchedStr)
+	return []byte(data), fetchedAt, nil
+}
+
+// SaveCache stores a Clockify response fetched now.
+func (db *DB) SaveCache(key string, data []byte) error {
+	_, err := db.Exec(
+		`INSERT INTO clockify_cache (key, data, fetched_at) VALUES (?, ?, ?)
+		 ON CONFLICT(key) DO UPDATE SET data = excluded.data, fetched_at = excluded.fetched_at`,
+		key, string(data), time.Now().UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return fmt.Errorf("saving cache: %w", err)
+	}
+	return nil
+}
+
+// closeWait bounds how long Close waits for work started with Go.
+const closeWait = 10 * time.Second
+
+// Go runs f on its own goroutine. Close waits for it, up to closeWait, so a
+// background cache refresh started by a short-lived command is still saved
+// before the process exits.
+func (db *DB) Go(f func()) {
+	db.background.Add(1)
+	go func() {
+		defer db.background.Done()
+		f()
+	}()
+}
+
+// Close waits for the work started with Go, then closes the database.
+func (db *DB) Close() error {
+	done := make(chan struct{})
+	go func() {
+		db.background.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(closeWait):
+	}
+	return db.DB.Close()
+}
+
+// ClearCache drops every cached Clockify response, so the next read fetches
+// fresh data.
+func (db *DB) ClearCache() error {
+	if _, err := db.Exec("DELETE FROM clockify_cache"); err != nil {
+		return fmt.Errorf("clearing cache: %w", err)
+	}
+	return nil
+}
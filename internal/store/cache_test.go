@@ -0,0 +1,75 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	db := testDB(t)
+
+	data, fetchedAt, err := db.LoadCache("projects:ws1")
+	if err != nil || data != nil || !fetchedAt.IsZero() {
+		t.Fatalf("LoadCache of a missing key = %q, %v, %v", data, fetchedAt, err)
+	}
+
+	before := time.Now().Add(-time.Second)
+	if err := db.SaveCache("projects:ws1", []byte(`["a"]`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SaveCache("projects:ws1", []byte(`["a","b"]`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SaveCache("clients:ws1", []byte(`[]`)); err != nil {
+		t.Fatal(err)
+	}
+	data, fetchedAt, err = db.LoadCache("projects:ws1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `["a","b"]` {
+		t.Errorf("LoadCache = %s, want the latest save", data)
+	}
+	if fetchedAt.Before(before) {
+		t.Errorf("fetched_at = %v, want about now", fetchedAt)
+	}
+
+	if err := db.ClearCache(); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"projects:ws1", "clients:ws1"} {
+		if data, _, _ := db.LoadCache(key); data != nil {
+			t.Errorf("%s = %s after ClearCache", key, data)
+		}
+	}
+}
+
+func TestClose_WaitsForBackgroundWork(t *testing.T) {
+	t.Setenv("CLOCKR_HOME", t.TempDir())
+	db, err := Open(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	started := make(chan struct{})
+	db.Go(func() {
+		close(started)
+		time.Sleep(50 * time.Millisecond)
+		if err := db.SaveCache("projects:ws1", []byte(`[]`)); err != nil {
+			t.Errorf("SaveCache from background work: %v", err)
+		}
+	})
+	<-started
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err = Open(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if data, _, _ := db.LoadCache("projects:ws1"); data == nil {
+		t.Error("the background save was lost when the database closed")
+	}
+}
@@ -2,31 +2,69 @@ package store
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
+	"sync"
 
+	"github.com/christopherklint97/clockr/internal/config"
 	_ "modernc.org/sqlite"
 )
 
+// ErrReadOnly is returned by write operations when the database is in read-only mode.
+var ErrReadOnly = errors.New("read-only mode: database writes are disabled")
+
 type DB struct {
 	*sql.DB
+	path       string
+	readOnly   bool
+	background sync.WaitGroup // see Go
 }
 
-func Open() (*DB, error) {
-	home, err := os.UserHomeDir()
+// Open opens the database and applies pending migrations. A read-only
+// database is never migrated; Open fails instead if its schema is behind
+// this build.
+func Open(readOnly bool) (*DB, error) {
+	db, err := OpenUnmigrated()
 	if err != nil {
-		return nil, fmt.Errorf("finding home directory: %w", err)
+		return nil, err
+	}
+	if readOnly {
+		db.readOnly = true
+		version, err := db.SchemaVersion()
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+		if latest := LatestSchemaVersion(); version < latest {
+			db.Close()
+			return nil, fmt.Errorf("database schema is at version %d, this clockr needs %d — run clockr once without read-only mode to migrate it", version, latest)
+		}
+		return db, nil
+	}
+	if _, err := db.Migrate(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("running migrations: %w", err)
 	}
+	return db, nil
+}
 
-	dir := filepath.Join(home, ".config", "clockr")
+// OpenUnmigrated opens the database without applying pending migrations,
+// for 'clockr db migrate'.
+func OpenUnmigrated() (*DB, error) {
+	dir, err := config.DataDir()
+	if err != nil {
+		return nil, err
+	}
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return nil, fmt.Errorf("creating data directory: %w", err)
 	}
 
 	dbPath := filepath.Join(dir, "clockr.db")
-	db, err := sql.Open("sqlite", dbPath+"?_pragma=journal_mode(WAL)")
+	// busy_timeout makes concurrent writers (the scheduler's goroutines,
+	// another clockr process) wait for the lock instead of failing.
+	db, err := sql.Open("sqlite", dbPath+"?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)")
 	if err != nil {
 		return nil, fmt.Errorf("opening database: %w", err)
 	}
@@ -35,49 +73,25 @@ func Open() (*DB, error) {
 		db.Close()
 		return nil, fmt.Errorf("connecting to database: %w", err)
 	}
+	return &DB{DB: db, path: dbPath}, nil
+}
 
-	store := &DB{db}
-	if err := store.migrate(); err != nil {
-		db.Close()
-		return nil, fmt.Errorf("running migrations: %w", err)
-	}
+// SetReadOnly enables or disables read-only mode. While enabled, every write
+// through Exec fails with ErrReadOnly; queries are unaffected.
+func (db *DB) SetReadOnly(readOnly bool) {
+	db.readOnly = readOnly
+}
 
-	return store, nil
+func (db *DB) ReadOnly() bool {
+	return db.readOnly
 }
 
-func (db *DB) migrate() error {
-	migrations := []string{
-		`CREATE TABLE IF NOT EXISTS entries (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			clockify_id TEXT,
-			project_id TEXT NOT NULL,
-			project_name TEXT NOT NULL,
-			description TEXT NOT NULL,
-			start_time DATETIME NOT NULL,
-			end_time DATETIME NOT NULL,
-			minutes INTEGER NOT NULL,
-			status TEXT NOT NULL DEFAULT 'logged',
-			raw_input TEXT,
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
-		)`,
-		`CREATE TABLE IF NOT EXISTS state (
-			key TEXT PRIMARY KEY,
-			value TEXT NOT NULL
-		)`,
-		`ALTER TABLE entries ADD COLUMN client_name TEXT NOT NULL DEFAULT ''`,
-	}
-
-	for _, m := range migrations {
-		if _, err := db.Exec(m); err != nil {
-			// Ignore "duplicate column" errors from ALTER TABLE migrations
-			if strings.Contains(err.Error(), "duplicate column") {
-				continue
-			}
-			return fmt.Errorf("executing migration: %w", err)
-		}
+// Exec shadows sql.DB.Exec so all writes go through the read-only guard.
+func (db *DB) Exec(query string, args ...any) (sql.Result, error) {
+	if db.readOnly {
+		return nil, ErrReadOnly
 	}
-
-	return nil
+	return db.DB.Exec(query, args...)
 }
 
 func (db *DB) GetState(key string) (string, error) {
@@ -96,3 +110,81 @@ func (db *DB) SetState(key, value string) error {
 	)
 	return err
 }
+
+// ClaimState sets key to value unless it already has that value, and
+// reports whether it changed. Callers that must act once per value (e.g.
+// once a day) use it so two goroutines or processes can't both act.
+func (db *DB) ClaimState(key, value string) (bool, error) {
+	result, err := db.Exec(
+		`INSERT INTO state (key, value) VALUES (?, ?)
+		 ON CONFLICT(key) DO UPDATE SET value = excluded.value WHERE state.value != excluded.value`,
+		key, value,
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+func (db *DB) DeleteState(key string) error {
+	_, err := db.Exec("DELETE FROM state WHERE key = ?", key)
+	return err
+}
+
+// TableRows returns every row of table as column → value, for 'clockr data
+// export'. Text and blob columns come back as strings.
+func (db *DB) TableRows(table string) ([]map[string]any, error) {
+	rows, err := db.Query(`SELECT * FROM "` + table + `"`)
+	if err != nil {
+		return nil, fmt.Errorf("querying %s: %w", table, err)
+	}
+	defer rows.Close()
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	out := []map[string]any{}
+	for rows.Next() {
+		values := make([]any, len(cols))
+		ptrs := make([]any, len(cols))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, fmt.Errorf("scanning %s: %w", table, err)
+		}
+		row := make(map[string]any, len(cols))
+		for i, c := range cols {
+			if b, ok := values[i].([]byte); ok {
+				values[i] = string(b)
+			}
+			row[c] = values[i]
+		}
+		out = append(out, row)
+	}
+	return out, rows.Err()
+}
+
+// AllState returns every key/value pair in the state table.
+func (db *DB) AllState() (map[string]string, error) {
+	rows, err := db.Query("SELECT key, value FROM state")
+	if err != nil {
+		return nil, fmt.Errorf("querying state: %w", err)
+	}
+	defer rows.Close()
+
+	state := make(map[string]string)
+	for rows.Next() {
+		var k, v string
+		if err := rows.Scan(&k, &v); err != nil {
+			return nil, fmt.Errorf("scanning state: %w", err)
+		}
+		state[k] = v
+	}
+	return state, rows.Err()
+}
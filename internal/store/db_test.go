@@ -0,0 +1,57 @@
+package store
+
+import (
+	"errors"
+	"testing"
+)
+
+// testDB opens a migrated database in a temporary CLOCKR_HOME.
+func testDB(t *testing.T) *DB {
+	t.Helper()
+	t.Setenv("CLOCKR_HOME", t.TempDir())
+	db, err := Open(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// tableExists reports whether the database has table name.
+func tableExists(t *testing.T, db *DB, name string) bool {
+	t.Helper()
+	var n int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`, name).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	return n > 0
+}
+
+func TestOpen_ReadOnlyDoesNotMigrate(t *testing.T) {
+	t.Setenv("CLOCKR_HOME", t.TempDir())
+	if _, err := Open(true); err == nil {
+		t.Fatal("Open(true) on a new database succeeded, want an error about the schema")
+	}
+	db, err := OpenUnmigrated()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if tableExists(t, db, "entries") || tableExists(t, db, "schema_version") {
+		t.Error("read-only Open created tables")
+	}
+
+	migrated, err := Open(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrated.Close()
+	ro, err := Open(true)
+	if err != nil {
+		t.Fatalf("Open(true) on a migrated database: %v", err)
+	}
+	defer ro.Close()
+	if err := ro.SetState("k", "v"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("SetState = %v, want ErrReadOnly", err)
+	}
+}
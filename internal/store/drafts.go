@@ -0,0 +1,68 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Draft is a description typed in the TUI that has not been logged yet,
+// kept so it survives a failed AI call or a crash. There is at most one.
+type Draft struct {
+	Text      string
+	StartTime time.Time // the prompt window it was typed for
+	EndTime   time.Time
+	UpdatedAt time.Time
+}
+
+// SaveDraft replaces the draft with d.
+func (db *DB) SaveDraft(d *Draft) error {
+	_, err := db.Exec(
+		`INSERT INTO drafts (id, text, start_time, end_time, updated_at)
+		 VALUES (1, ?, ?, ?, CURRENT_TIMESTAMP)
+		 ON CONFLICT (id) DO UPDATE SET
+			text = excluded.text,
+			start_time = excluded.start_time,
+			end_time = excluded.end_time,
+			updated_at = CURRENT_TIMESTAMP`,
+		d.Text, d.StartTime.UTC().Format(time.RFC3339), d.EndTime.UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return fmt.Errorf("saving draft: %w", err)
+	}
+	return nil
+}
+
+// LatestDraft returns the saved draft, or nil if there is none.
+func (db *DB) LatestDraft() (*Draft, error) {
+	var d Draft
+	var startStr, endStr, updatedStr string
+	err := db.QueryRow(`SELECT text, start_time, end_time, updated_at FROM drafts WHERE id = 1`).
+		Scan(&d.Text, &startStr, &endStr, &updatedStr)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying draft: %w", err)
+	}
+	if t, err := time.Parse(time.RFC3339, startStr); err == nil {
+		d.StartTime = t
+	}
+	if t, err := time.Parse(time.RFC3339, endStr); err == nil {
+		d.EndTime = t
+	}
+	if t, err := time.Parse(time.RFC3339, updatedStr); err == nil {
+		d.UpdatedAt = t
+	}
+	return &d, nil
+}
+
+// DeleteDraft forgets the draft once its description has been logged or the
+// window skipped.
+func (db *DB) DeleteDraft() error {
+	if _, err := db.Exec("DELETE FROM drafts"); err != nil {
+		return fmt.Errorf("deleting draft: %w", err)
+	}
+	return nil
+}
@@ -3,37 +3,93 @@ package store
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
+
+	"github.com/christopherklint97/clockr/internal/timezone"
 )
 
+// entryColumns is the column list scanned by queryEntries, in order.
+const entryColumns = "id, clockify_id, project_id, project_name, client_name, description, start_time, end_time, minutes, status, raw_input, overtime, retry_count, timezone, calendar_event_id, suggestion_id, task_id, tag_ids, billable, source, issue_key, created_at"
+
 type Entry struct {
-	ID          int
-	ClockifyID  string
-	ProjectID   string
-	ProjectName string
-	ClientName  string
-	Description string
-	StartTime   time.Time
-	EndTime     time.Time
-	Minutes     int
-	Status      string
-	RawInput    string
-	CreatedAt   time.Time
+	ID              int
+	ClockifyID      string
+	ProjectID       string
+	ProjectName     string
+	ClientName      string
+	Description     string
+	StartTime       time.Time
+	EndTime         time.Time
+	Minutes         int
+	Status          string
+	RawInput        string
+	Overtime        bool   // logged outside configured work hours via --overtime
+	RetryCount      int    // number of Clockify submission retries attempted
+	Timezone        string // IANA zone the entry was logged in, e.g. "Europe/Stockholm"; "" if unknown
+	CalendarEventID string // ID of the calendar event written back for this entry, if any
+	SuggestionID    int    // the entry_suggestions row the entry was logged from; 0 when not from the AI
+	// TaskID, TagIDs and Billable are what Clockify reports for the entry
+	// once created. Until then TagIDs holds the tags it will be created
+	// with (the overtime tag) and the others stay empty.
+	TaskID    string
+	TagIDs    []string
+	Billable  bool
+	Source    string // how the entry was logged, one of the Source constants; "" for entries from before it was recorded
+	IssueKey  string // the Jira issue the entry belongs to, if known
+	CreatedAt time.Time
 }
 
+// Entry sources, recorded in entries.source.
+const (
+	SourceManual    = "manual"    // clockr log and gaps
+	SourceScheduler = "scheduler" // a scheduler prompt
+	SourceBatch     = "batch"     // clockr log --from/--to
+	SourceSame      = "same"      // clockr log --same
+	SourceQuick     = "quick"     // clockr quick
+	SourceAPI       = "api"       // POST /entries on clockr serve
+	SourceSlack     = "slack"     // a Slack reply
+	SourceRecurring = "recurring" // [[recurring]] entries
+)
+
+// Sources lists the entry sources.
+var Sources = []string{SourceManual, SourceScheduler, SourceBatch, SourceSame, SourceQuick, SourceAPI, SourceSlack, SourceRecurring}
+
+// Location returns the zone the entry was logged in, or time.Local when it
+// was not recorded.
+func (e Entry) Location() *time.Location {
+	if e.Timezone != "" {
+		if loc, err := time.LoadLocation(e.Timezone); err == nil {
+			return loc
+		}
+	}
+	return time.Local
+}
+
+// InsertEntry stores e and sets e.ID. An empty Timezone is filled from
+// e.StartTime's zone.
 func (db *DB) InsertEntry(e *Entry) (int64, error) {
+	if e.Timezone == "" {
+		e.Timezone = timezone.Name(e.StartTime.Location())
+	}
 	result, err := db.Exec(
-		`INSERT INTO entries (clockify_id, project_id, project_name, client_name, description, start_time, end_time, minutes, status, raw_input)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		`INSERT INTO entries (clockify_id, project_id, project_name, client_name, description, start_time, end_time, minutes, status, raw_input, overtime, timezone, suggestion_id, task_id, tag_ids, billable, source, issue_key)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		e.ClockifyID, e.ProjectID, e.ProjectName, e.ClientName, e.Description,
 		e.StartTime.UTC().Format(time.RFC3339),
 		e.EndTime.UTC().Format(time.RFC3339),
-		e.Minutes, e.Status, e.RawInput,
+		e.Minutes, e.Status, e.RawInput, e.Overtime, e.Timezone, e.SuggestionID,
+		e.TaskID, strings.Join(e.TagIDs, ","), e.Billable, e.Source, e.IssueKey,
 	)
 	if err != nil {
 		return 0, fmt.Errorf("inserting entry: %w", err)
 	}
-	return result.LastInsertId()
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	e.ID = int(id)
+	return id, nil
 }
 
 func (db *DB) UpdateEntryStatus(id int, status, clockifyID string) error {
@@ -44,24 +100,85 @@ func (db *DB) UpdateEntryStatus(id int, status, clockifyID string) error {
 	return err
 }
 
+// UpdateEntryClockifyDetails records the task, tags and billable flag
+// Clockify reported for an entry, e.g. after a retry created it.
+func (db *DB) UpdateEntryClockifyDetails(id int, taskID string, tagIDs []string, billable bool) error {
+	_, err := db.Exec(
+		"UPDATE entries SET task_id = ?, tag_ids = ?, billable = ? WHERE id = ?",
+		taskID, strings.Join(tagIDs, ","), billable, id,
+	)
+	if err != nil {
+		return fmt.Errorf("updating entry details: %w", err)
+	}
+	return nil
+}
+
+// UpdateEntryTimes moves an entry, e.g. to match its times in Clockify.
+func (db *DB) UpdateEntryTimes(id int, start, end time.Time) error {
+	_, err := db.Exec(
+		"UPDATE entries SET start_time = ?, end_time = ?, minutes = ? WHERE id = ?",
+		start.UTC().Format(time.RFC3339), end.UTC().Format(time.RFC3339), int(end.Sub(start).Minutes()), id,
+	)
+	if err != nil {
+		return fmt.Errorf("updating entry times: %w", err)
+	}
+	return nil
+}
+
+// UpdateEntryDetails changes an entry's project and description.
+func (db *DB) UpdateEntryDetails(id int, projectID, projectName, clientName, description string) error {
+	_, err := db.Exec(
+		"UPDATE entries SET project_id = ?, project_name = ?, client_name = ?, description = ? WHERE id = ?",
+		projectID, projectName, clientName, description, id,
+	)
+	if err != nil {
+		return fmt.Errorf("updating entry: %w", err)
+	}
+	return nil
+}
+
+// DeleteEntry removes an entry, e.g. after rolling it back in Clockify.
+func (db *DB) DeleteEntry(id int) error {
+	if _, err := db.Exec("DELETE FROM entries WHERE id = ?", id); err != nil {
+		return fmt.Errorf("deleting entry: %w", err)
+	}
+	if _, err := db.Exec("DELETE FROM entry_mirrors WHERE entry_id = ?", id); err != nil {
+		return fmt.Errorf("deleting entry mirrors: %w", err)
+	}
+	return nil
+}
+
+// SetCalendarEventID records the calendar event written back for an entry.
+func (db *DB) SetCalendarEventID(id int, eventID string) error {
+	if _, err := db.Exec("UPDATE entries SET calendar_event_id = ? WHERE id = ?", eventID, id); err != nil {
+		return fmt.Errorf("saving calendar event id: %w", err)
+	}
+	return nil
+}
+
+// GetTodayEntries returns entries overlapping today, including one that
+// started before midnight.
 func (db *DB) GetTodayEntries() ([]Entry, error) {
 	now := time.Now()
 	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-	endOfDay := startOfDay.Add(24 * time.Hour)
+	return db.GetEntriesBetween(startOfDay, startOfDay.AddDate(0, 0, 1))
+}
 
-	return db.queryEntries(
-		`SELECT id, clockify_id, project_id, project_name, client_name, description, start_time, end_time, minutes, status, raw_input, created_at
-		 FROM entries
-		 WHERE start_time >= ? AND start_time < ?
-		 ORDER BY start_time ASC`,
-		startOfDay.UTC().Format(time.RFC3339),
-		endOfDay.UTC().Format(time.RFC3339),
-	)
+// GetEntry returns the entry with the given local ID, or nil if there is none.
+func (db *DB) GetEntry(id int) (*Entry, error) {
+	entries, err := db.queryEntries("SELECT "+entryColumns+" FROM entries WHERE id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	return &entries[0], nil
 }
 
 func (db *DB) GetLastEntry() (*Entry, error) {
 	entries, err := db.queryEntries(
-		`SELECT id, clockify_id, project_id, project_name, client_name, description, start_time, end_time, minutes, status, raw_input, created_at
+		"SELECT " + entryColumns + `
 		 FROM entries
 		 WHERE status = 'logged'
 		 ORDER BY created_at DESC
@@ -76,21 +193,88 @@ func (db *DB) GetLastEntry() (*Entry, error) {
 	return &entries[0], nil
 }
 
-func (db *DB) GetLastRawInput() (string, error) {
-	var rawInput sql.NullString
-	err := db.QueryRow(
-		`SELECT raw_input FROM entries
-		 WHERE status = 'logged' AND raw_input IS NOT NULL AND raw_input != '' AND raw_input != '(--same)'
-		 ORDER BY created_at DESC
-		 LIMIT 1`,
-	).Scan(&rawInput)
-	if err == sql.ErrNoRows {
-		return "", nil
+// GetEntriesBetween returns entries overlapping [start, end), oldest first,
+// so an entry spanning midnight shows up on both days. Use MinutesWithin to
+// count only the part inside the range.
+func (db *DB) GetEntriesBetween(start, end time.Time) ([]Entry, error) {
+	return db.queryEntries(
+		"SELECT "+entryColumns+`
+		 FROM entries
+		 WHERE start_time < ? AND end_time > ?
+		 ORDER BY start_time ASC`,
+		end.UTC().Format(time.RFC3339),
+		start.UTC().Format(time.RFC3339),
+	)
+}
+
+// MinutesWithin returns the entry's minutes that fall inside [start, end).
+// An entry wholly inside counts in full.
+func (e Entry) MinutesWithin(start, end time.Time) int {
+	if !e.StartTime.Before(start) && !e.EndTime.After(end) {
+		return e.Minutes
 	}
+	from, to := e.StartTime, e.EndTime
+	if from.Before(start) {
+		from = start
+	}
+	if to.After(end) {
+		to = end
+	}
+	if !to.After(from) {
+		return 0
+	}
+	return int(to.Sub(from).Minutes())
+}
+
+// SplitAtMidnight splits an entry spanning midnight, in its start time's
+// zone, into one entry per calendar day. Other entries are returned as is.
+func SplitAtMidnight(e Entry) []Entry {
+	var parts []Entry
+	for {
+		s := e.StartTime
+		midnight := time.Date(s.Year(), s.Month(), s.Day()+1, 0, 0, 0, 0, s.Location())
+		if !e.EndTime.After(midnight) {
+			if parts != nil {
+				e.Minutes = int(e.EndTime.Sub(e.StartTime).Minutes())
+			}
+			return append(parts, e)
+		}
+		part := e
+		part.EndTime = midnight
+		part.Minutes = int(midnight.Sub(s).Minutes())
+		parts = append(parts, part)
+		e.StartTime = midnight
+	}
+}
+
+// AllEntries returns every stored entry, oldest first.
+func (db *DB) AllEntries() ([]Entry, error) {
+	return db.queryEntries("SELECT " + entryColumns + " FROM entries ORDER BY start_time ASC")
+}
+
+// ProjectMinutesSince returns the minutes logged per project ID in entries
+// starting at or after since.
+func (db *DB) ProjectMinutesSince(since time.Time) (map[string]int, error) {
+	rows, err := db.Query(
+		`SELECT project_id, SUM(minutes) FROM entries
+		 WHERE status = 'logged' AND start_time >= ?
+		 GROUP BY project_id`,
+		since.UTC().Format(time.RFC3339),
+	)
 	if err != nil {
-		return "", err
+		return nil, fmt.Errorf("summing project minutes: %w", err)
+	}
+	defer rows.Close()
+	minutes := map[string]int{}
+	for rows.Next() {
+		var id string
+		var m int
+		if err := rows.Scan(&id, &m); err != nil {
+			return nil, fmt.Errorf("scanning project minutes: %w", err)
+		}
+		minutes[id] = m
 	}
-	return rawInput.String, nil
+	return minutes, rows.Err()
 }
 
 func (db *DB) DeleteFailedEntries() (int64, error) {
@@ -103,13 +287,34 @@ func (db *DB) DeleteFailedEntries() (int64, error) {
 
 func (db *DB) GetFailedEntries() ([]Entry, error) {
 	return db.queryEntries(
-		`SELECT id, clockify_id, project_id, project_name, client_name, description, start_time, end_time, minutes, status, raw_input, created_at
+		"SELECT " + entryColumns + `
 		 FROM entries
 		 WHERE status = 'failed'
 		 ORDER BY created_at ASC`,
 	)
 }
 
+// ClaimRetry marks a failed entry as being retried and reports whether the
+// caller won the claim. An entry can only be claimed again once lease has
+// passed since the last attempt, so concurrent clockr processes (scheduler and
+// `clockr log`) never submit the same entry twice.
+func (db *DB) ClaimRetry(id int, lease time.Duration) (bool, error) {
+	now := time.Now().UTC()
+	result, err := db.Exec(
+		`UPDATE entries SET retry_count = retry_count + 1, last_attempt_at = ?
+		 WHERE id = ? AND status = 'failed' AND (last_attempt_at IS NULL OR last_attempt_at < ?)`,
+		now.Format(time.RFC3339), id, now.Add(-lease).Format(time.RFC3339),
+	)
+	if err != nil {
+		return false, fmt.Errorf("claiming entry %d: %w", id, err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
 func (db *DB) queryEntries(query string, args ...interface{}) ([]Entry, error) {
 	rows, err := db.Query(query, args...)
 	if err != nil {
@@ -121,11 +326,12 @@ func (db *DB) queryEntries(query string, args ...interface{}) ([]Entry, error) {
 	for rows.Next() {
 		var e Entry
 		var clockifyID, clientName, rawInput sql.NullString
-		var startStr, endStr, createdStr string
+		var startStr, endStr, createdStr, tagIDs string
 
 		if err := rows.Scan(
 			&e.ID, &clockifyID, &e.ProjectID, &e.ProjectName, &clientName, &e.Description,
-			&startStr, &endStr, &e.Minutes, &e.Status, &rawInput, &createdStr,
+			&startStr, &endStr, &e.Minutes, &e.Status, &rawInput, &e.Overtime, &e.RetryCount, &e.Timezone, &e.CalendarEventID, &e.SuggestionID,
+			&e.TaskID, &tagIDs, &e.Billable, &e.Source, &e.IssueKey, &createdStr,
 		); err != nil {
 			return nil, fmt.Errorf("scanning entry: %w", err)
 		}
@@ -133,12 +339,16 @@ func (db *DB) queryEntries(query string, args ...interface{}) ([]Entry, error) {
 		e.ClockifyID = clockifyID.String
 		e.ClientName = clientName.String
 		e.RawInput = rawInput.String
+		if tagIDs != "" {
+			e.TagIDs = strings.Split(tagIDs, ",")
+		}
 
+		loc := e.Location()
 		if t, err := time.Parse(time.RFC3339, startStr); err == nil {
-			e.StartTime = t
+			e.StartTime = t.In(loc)
 		}
 		if t, err := time.Parse(time.RFC3339, endStr); err == nil {
-			e.EndTime = t
+			e.EndTime = t.In(loc)
 		}
 		if t, err := time.Parse(time.RFC3339, createdStr); err == nil {
 			e.CreatedAt = t
@@ -0,0 +1,73 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// EntrySuggestion is the AI answer a set of entries was logged from, kept
+// so 'clockr entry show' can explain later why a project was chosen.
+// Unlike SavedSuggestion it is never pruned.
+type EntrySuggestion struct {
+	ID             int
+	RawInput       string
+	Suggestion     string // JSON-encoded ai.Suggestion or ai.BatchSuggestion, as the AI returned it
+	Clarifications string // JSON-encoded []ai.Exchange
+	PromptVersion  string
+	Model          string
+	CreatedAt      time.Time
+}
+
+// SaveEntrySuggestion stores the AI's suggestion and the clarification
+// rounds that led to it, and returns the ID to set on the entries as
+// Entry.SuggestionID.
+func (db *DB) SaveEntrySuggestion(rawInput string, suggestion, clarifications any, promptVersion, model string) (int, error) {
+	suggestionJSON, err := json.Marshal(suggestion)
+	if err != nil {
+		return 0, fmt.Errorf("encoding suggestion: %w", err)
+	}
+	clarificationsJSON, err := json.Marshal(clarifications)
+	if err != nil {
+		return 0, fmt.Errorf("encoding clarifications: %w", err)
+	}
+	if string(clarificationsJSON) == "null" {
+		clarificationsJSON = []byte("[]")
+	}
+	result, err := db.Exec(
+		`INSERT INTO entry_suggestions (raw_input, suggestion, clarifications, prompt_version, model)
+		 VALUES (?, ?, ?, ?, ?)`,
+		rawInput, string(suggestionJSON), string(clarificationsJSON), promptVersion, model,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("saving entry suggestion: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return int(id), nil
+}
+
+// GetEntrySuggestion returns the suggestion with the given ID, or nil if
+// there is none.
+func (db *DB) GetEntrySuggestion(id int) (*EntrySuggestion, error) {
+	s := EntrySuggestion{ID: id}
+	var createdStr string
+	err := db.QueryRow(
+		`SELECT raw_input, suggestion, clarifications, prompt_version, model, created_at
+		 FROM entry_suggestions WHERE id = ?`, id,
+	).Scan(&s.RawInput, &s.Suggestion, &s.Clarifications, &s.PromptVersion, &s.Model, &createdStr)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying entry suggestion: %w", err)
+	}
+	if t, err := time.Parse(time.RFC3339, createdStr); err == nil {
+		s.CreatedAt = t
+	}
+	return &s, nil
+}
@@ -0,0 +1,141 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"modernc.org/sqlite"
+)
+
+// Path returns the database file's location.
+func (db *DB) Path() string {
+	return db.path
+}
+
+// Size returns the database's size in bytes, including its WAL file.
+func (db *DB) Size() (int64, error) {
+	info, err := os.Stat(db.path)
+	if err != nil {
+		return 0, err
+	}
+	size := info.Size()
+	if wal, err := os.Stat(db.path + "-wal"); err == nil {
+		size += wal.Size()
+	}
+	return size, nil
+}
+
+// Vacuum checkpoints the WAL and rebuilds the file, returning the space of
+// deleted rows to the file system. In WAL mode the rebuilt pages go to the
+// WAL first, so it is checkpointed again afterwards.
+func (db *DB) Vacuum() error {
+	if _, err := db.Exec("PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+		return fmt.Errorf("checkpointing: %w", err)
+	}
+	if _, err := db.Exec("VACUUM"); err != nil {
+		return fmt.Errorf("vacuuming: %w", err)
+	}
+	if _, err := db.Exec("PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+		return fmt.Errorf("checkpointing: %w", err)
+	}
+	return nil
+}
+
+// Backup copies the database to path with SQLite's online backup API, so it
+// is consistent even while the scheduler writes to it. path must not exist.
+func (db *DB) Backup(ctx context.Context, path string) error {
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%s already exists", path)
+	}
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("connecting to database: %w", err)
+	}
+	defer conn.Close()
+
+	err = conn.Raw(func(driverConn any) error {
+		src, ok := driverConn.(interface {
+			NewBackup(string) (*sqlite.Backup, error)
+		})
+		if !ok {
+			return fmt.Errorf("the sqlite driver does not support backups")
+		}
+		b, err := src.NewBackup(path)
+		if err != nil {
+			return err
+		}
+		for more := true; more; {
+			if more, err = b.Step(-1); err != nil {
+				b.Finish()
+				return err
+			}
+		}
+		return b.Finish()
+	})
+	if err != nil {
+		os.Remove(path)
+		return fmt.Errorf("backing up database: %w", err)
+	}
+	return nil
+}
+
+// PruneCount is how many rows Prune deleted from a table.
+type PruneCount struct {
+	Table string
+	Rows  int64
+}
+
+// pruneQueries delete the rows of each table that are older than the cutoff
+// (the single argument). Failed entries are kept for 'clockr retry', and
+// entry_suggestions and entry_mirrors only go once no entry refers to them.
+var pruneQueries = []struct {
+	table string
+	query string
+}{
+	{"entries", `DELETE FROM entries WHERE datetime(end_time) < datetime(?) AND status != 'failed'`},
+	{"entry_mirrors", `DELETE FROM entry_mirrors WHERE datetime(updated_at) < datetime(?)
+		AND entry_id NOT IN (SELECT id FROM entries)`},
+	{"entry_suggestions", `DELETE FROM entry_suggestions WHERE datetime(created_at) < datetime(?)
+		AND id NOT IN (SELECT suggestion_id FROM entries)`},
+	{"suggestions", `DELETE FROM suggestions WHERE datetime(end_time) < datetime(?)`},
+	{"drafts", `DELETE FROM drafts WHERE datetime(updated_at) < datetime(?)`},
+	{"skips", `DELETE FROM skips WHERE datetime(end_time) < datetime(?)`},
+	{"pauses", `DELETE FROM pauses WHERE end_time IS NOT NULL AND datetime(end_time) < datetime(?)`},
+	{"prompts", `DELETE FROM prompts WHERE datetime(tick) < datetime(?)`},
+	{"suggestion_edits", `DELETE FROM suggestion_edits WHERE datetime(end_time) < datetime(?)`},
+	{"audit_log", `DELETE FROM audit_log WHERE datetime(created_at) < datetime(?)`},
+	{"raw_inputs", `DELETE FROM raw_inputs WHERE datetime(created_at) < datetime(?)`},
+}
+
+// Prune deletes history older than before in one transaction and returns
+// the number of rows removed per table. The file only shrinks after Vacuum.
+func (db *DB) Prune(before time.Time) ([]PruneCount, error) {
+	if db.readOnly {
+		return nil, ErrReadOnly
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	cutoff := before.UTC().Format(time.RFC3339)
+	var counts []PruneCount
+	for _, q := range pruneQueries {
+		result, err := tx.Exec(q.query, cutoff)
+		if err != nil {
+			return nil, fmt.Errorf("pruning %s: %w", q.table, err)
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+		counts = append(counts, PruneCount{Table: q.table, Rows: n})
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("pruning: %w", err)
+	}
+	return counts, nil
+}
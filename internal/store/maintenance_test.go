@@ -0,0 +1,119 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVacuum_Size(t *testing.T) {
+	db := testDB(t)
+	fresh, err := db.Size()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Vacuum(); err != nil {
+		t.Fatal(err)
+	}
+	if after, _ := db.Size(); after > fresh {
+		t.Errorf("vacuuming a fresh database grew it from %d to %d bytes", fresh, after)
+	}
+
+	text := strings.Repeat("x", 4096)
+	for range 200 {
+		if err := db.AddRawInput(text); err != nil {
+			t.Fatal(err)
+		}
+	}
+	full, _ := db.Size()
+	if _, err := db.Exec(`DELETE FROM raw_inputs`); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Vacuum(); err != nil {
+		t.Fatal(err)
+	}
+	after, err := db.Size()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after >= full || after > fresh+fresh/2 {
+		t.Errorf("size after deleting and vacuuming = %d bytes, want about %d (full: %d)", after, fresh, full)
+	}
+}
+
+func TestBackup_RoundTrip(t *testing.T) {
+	db := testDB(t)
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	if _, err := db.InsertEntry(&Entry{ProjectID: "p1", ProjectName: "Alpha", Description: "Review", StartTime: start, EndTime: start.Add(time.Hour), Minutes: 60, Status: "logged"}); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "backup.db")
+	if err := db.Backup(context.Background(), path); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Backup(context.Background(), path); err == nil {
+		t.Error("Backup over an existing file succeeded")
+	}
+
+	backup, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer backup.Close()
+	var desc string
+	var version int
+	if err := backup.QueryRow(`SELECT description FROM entries`).Scan(&desc); err != nil || desc != "Review" {
+		t.Errorf("backed-up entry = %q, %v", desc, err)
+	}
+	if err := backup.QueryRow(`SELECT MAX(version) FROM schema_version`).Scan(&version); err != nil || version != LatestSchemaVersion() {
+		t.Errorf("backed-up schema version = %d, %v", version, err)
+	}
+}
+
+func TestPrune_Cutoff(t *testing.T) {
+	db := testDB(t)
+	cutoff := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	add := func(desc, status string, end time.Time) {
+		t.Helper()
+		e := Entry{ProjectID: "p1", ProjectName: "Alpha", Description: desc, StartTime: end.Add(-time.Hour), EndTime: end, Minutes: 60, Status: status}
+		if _, err := db.InsertEntry(&e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	add("old", "logged", cutoff.Add(-time.Hour))
+	add("old failed", "failed", cutoff.Add(-time.Hour))
+	add("new", "logged", cutoff.Add(time.Hour))
+	for _, end := range []time.Time{cutoff.Add(-time.Hour), cutoff.Add(time.Hour)} {
+		if _, err := db.InsertSkip(&Skip{StartTime: end.Add(-time.Hour), EndTime: end, Minutes: 60}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	counts, err := db.Prune(cutoff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]int64)
+	for _, c := range counts {
+		got[c.Table] = c.Rows
+	}
+	if got["entries"] != 1 || got["skips"] != 1 {
+		t.Errorf("pruned = %v, want 1 entry and 1 skip", got)
+	}
+
+	entries, err := db.AllEntries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var kept []string
+	for _, e := range entries {
+		kept = append(kept, e.Description)
+	}
+	if strings.Join(kept, ",") != "old failed,new" {
+		t.Errorf("entries kept = %v, want the failed one and the new one", kept)
+	}
+}
@@ -0,0 +1,350 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// migration is one numbered schema change. Up runs in a transaction when the
+// database is below Version; Down undoes it for 'clockr db migrate --to'.
+// Statements must tolerate databases created before schema_version existed,
+// where the change may already be in place.
+type migration struct {
+	Version int
+	Name    string
+	Up      []string
+	Down    []string
+}
+
+// migrations are applied in order. Append new ones at the end with the next
+// version; never edit or renumber one that has shipped.
+var migrations = []migration{
+	{1, "create entries", []string{`CREATE TABLE IF NOT EXISTS entries (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			clockify_id TEXT,
+			project_id TEXT NOT NULL,
+			project_name TEXT NOT NULL,
+			description TEXT NOT NULL,
+			start_time DATETIME NOT NULL,
+			end_time DATETIME NOT NULL,
+			minutes INTEGER NOT NULL,
+			status TEXT NOT NULL DEFAULT 'logged',
+			raw_input TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`}, []string{`DROP TABLE entries`}},
+	{2, "create state", []string{`CREATE TABLE IF NOT EXISTS state (
+			key TEXT PRIMARY KEY,
+			value TEXT NOT NULL
+		)`}, []string{`DROP TABLE state`}},
+	{3, "add entries.client_name", []string{`ALTER TABLE entries ADD COLUMN client_name TEXT NOT NULL DEFAULT ''`},
+		[]string{`ALTER TABLE entries DROP COLUMN client_name`}},
+	{4, "add entries.overtime", []string{`ALTER TABLE entries ADD COLUMN overtime INTEGER NOT NULL DEFAULT 0`},
+		[]string{`ALTER TABLE entries DROP COLUMN overtime`}},
+	{5, "add entries.retry_count", []string{`ALTER TABLE entries ADD COLUMN retry_count INTEGER NOT NULL DEFAULT 0`},
+		[]string{`ALTER TABLE entries DROP COLUMN retry_count`}},
+	{6, "add entries.last_attempt_at", []string{`ALTER TABLE entries ADD COLUMN last_attempt_at DATETIME`},
+		[]string{`ALTER TABLE entries DROP COLUMN last_attempt_at`}},
+	{7, "add entries.timezone", []string{`ALTER TABLE entries ADD COLUMN timezone TEXT NOT NULL DEFAULT ''`},
+		[]string{`ALTER TABLE entries DROP COLUMN timezone`}},
+	{8, "add entries.calendar_event_id", []string{`ALTER TABLE entries ADD COLUMN calendar_event_id TEXT NOT NULL DEFAULT ''`},
+		[]string{`ALTER TABLE entries DROP COLUMN calendar_event_id`}},
+	{9, "create skips", []string{`CREATE TABLE IF NOT EXISTS skips (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			start_time DATETIME NOT NULL,
+			end_time DATETIME NOT NULL,
+			minutes INTEGER NOT NULL,
+			reason TEXT NOT NULL DEFAULT '',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`}, []string{`DROP TABLE skips`}},
+	{10, "create pauses", []string{`CREATE TABLE IF NOT EXISTS pauses (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			start_time DATETIME NOT NULL,
+			end_time DATETIME,
+			reason TEXT NOT NULL DEFAULT '',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`}, []string{`DROP TABLE pauses`}},
+	{11, "create project_migrations", []string{`CREATE TABLE IF NOT EXISTS project_migrations (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			old_workspace_id TEXT NOT NULL,
+			new_workspace_id TEXT NOT NULL,
+			old_project_id TEXT NOT NULL,
+			new_project_id TEXT NOT NULL,
+			entries INTEGER NOT NULL DEFAULT 0,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`}, []string{`DROP TABLE project_migrations`}},
+	{12, "add skips.clockify_id", []string{`ALTER TABLE skips ADD COLUMN clockify_id TEXT NOT NULL DEFAULT ''`},
+		[]string{`ALTER TABLE skips DROP COLUMN clockify_id`}},
+	{13, "create suggestions", []string{`CREATE TABLE IF NOT EXISTS suggestions (
+			start_time DATETIME NOT NULL,
+			end_time DATETIME NOT NULL,
+			description TEXT NOT NULL,
+			suggestion TEXT NOT NULL,
+			context TEXT NOT NULL DEFAULT '[]',
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (start_time, end_time)
+		)`}, []string{`DROP TABLE suggestions`}},
+	{14, "create audit_log", []string{`CREATE TABLE IF NOT EXISTS audit_log (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			entry_id INTEGER NOT NULL,
+			clockify_id TEXT NOT NULL DEFAULT '',
+			field TEXT NOT NULL,
+			local_value TEXT NOT NULL DEFAULT '',
+			remote_value TEXT NOT NULL DEFAULT '',
+			chosen TEXT NOT NULL,
+			value TEXT NOT NULL DEFAULT '',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`}, []string{`DROP TABLE audit_log`}},
+	{15, "create clockify_cache", []string{`CREATE TABLE IF NOT EXISTS clockify_cache (
+			key TEXT PRIMARY KEY,
+			data TEXT NOT NULL,
+			fetched_at DATETIME NOT NULL
+		)`}, []string{`DROP TABLE clockify_cache`}},
+	{16, "create prompts", []string{`CREATE TABLE IF NOT EXISTS prompts (
+			tick DATETIME PRIMARY KEY,
+			answered INTEGER NOT NULL DEFAULT 0
+		)`}, []string{`DROP TABLE prompts`}},
+	{17, "create suggestion_edits", []string{`CREATE TABLE IF NOT EXISTS suggestion_edits (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			start_time DATETIME NOT NULL,
+			end_time DATETIME NOT NULL,
+			distance REAL NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`}, []string{`DROP TABLE suggestion_edits`}},
+	{18, "create ai_cache", []string{`CREATE TABLE IF NOT EXISTS ai_cache (
+			key TEXT PRIMARY KEY,
+			data TEXT NOT NULL,
+			created_at DATETIME NOT NULL
+		)`}, []string{`DROP TABLE ai_cache`}},
+	{19, "create entry_suggestions", []string{`CREATE TABLE IF NOT EXISTS entry_suggestions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			raw_input TEXT NOT NULL DEFAULT '',
+			suggestion TEXT NOT NULL,
+			clarifications TEXT NOT NULL DEFAULT '[]',
+			prompt_version TEXT NOT NULL DEFAULT '',
+			model TEXT NOT NULL DEFAULT '',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`}, []string{`DROP TABLE entry_suggestions`}},
+	{20, "add entries.suggestion_id", []string{`ALTER TABLE entries ADD COLUMN suggestion_id INTEGER NOT NULL DEFAULT 0`},
+		[]string{`ALTER TABLE entries DROP COLUMN suggestion_id`}},
+	{21, "create raw_inputs", []string{`CREATE TABLE IF NOT EXISTS raw_inputs (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			text TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		// Seed the history from entries logged before raw_inputs existed.
+		`INSERT INTO raw_inputs (text, created_at)
+		 SELECT raw_input, MIN(created_at) FROM entries
+		 WHERE raw_input IS NOT NULL AND raw_input != '' AND raw_input != '(--same)'
+		   AND NOT EXISTS (SELECT 1 FROM raw_inputs)
+		 GROUP BY raw_input ORDER BY MIN(id)`,
+	}, []string{`DROP TABLE raw_inputs`}},
+	{22, "create snippets", []string{`CREATE TABLE IF NOT EXISTS snippets (
+			name TEXT PRIMARY KEY,
+			text TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`}, []string{`DROP TABLE snippets`}},
+	{23, "add entries.task_id", []string{`ALTER TABLE entries ADD COLUMN task_id TEXT NOT NULL DEFAULT ''`},
+		[]string{`ALTER TABLE entries DROP COLUMN task_id`}},
+	{24, "add entries.tag_ids", []string{`ALTER TABLE entries ADD COLUMN tag_ids TEXT NOT NULL DEFAULT ''`},
+		[]string{`ALTER TABLE entries DROP COLUMN tag_ids`}},
+	{25, "add entries.billable", []string{`ALTER TABLE entries ADD COLUMN billable INTEGER NOT NULL DEFAULT 0`},
+		[]string{`ALTER TABLE entries DROP COLUMN billable`}},
+	{26, "add entries.source", []string{`ALTER TABLE entries ADD COLUMN source TEXT NOT NULL DEFAULT ''`,
+		// --same entries are the only ones whose source can be told afterwards.
+		`UPDATE entries SET source = 'same' WHERE raw_input = '(--same)' AND source = ''`,
+	}, []string{`ALTER TABLE entries DROP COLUMN source`}},
+	{27, "add entries.issue_key and tempo_worklog_id", []string{
+		`ALTER TABLE entries ADD COLUMN issue_key TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE entries ADD COLUMN tempo_worklog_id TEXT NOT NULL DEFAULT ''`,
+	}, []string{`ALTER TABLE entries DROP COLUMN tempo_worklog_id`, `ALTER TABLE entries DROP COLUMN issue_key`}},
+	{28, "create entry_mirrors", []string{`CREATE TABLE IF NOT EXISTS entry_mirrors (
+			entry_id INTEGER NOT NULL,
+			destination TEXT NOT NULL,
+			status TEXT NOT NULL,
+			remote_id TEXT NOT NULL DEFAULT '',
+			error TEXT NOT NULL DEFAULT '',
+			attempts INTEGER NOT NULL DEFAULT 0,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (entry_id, destination)
+		)`,
+		// Tempo worklogs were the only mirror before; they move into the table.
+		`INSERT INTO entry_mirrors (entry_id, destination, status, remote_id, attempts)
+			SELECT id, 'tempo', 'logged', tempo_worklog_id, 1 FROM entries WHERE tempo_worklog_id != ''`,
+		`ALTER TABLE entries DROP COLUMN tempo_worklog_id`,
+	}, []string{
+		`ALTER TABLE entries ADD COLUMN tempo_worklog_id TEXT NOT NULL DEFAULT ''`,
+		`UPDATE entries SET tempo_worklog_id = (SELECT remote_id FROM entry_mirrors
+			WHERE entry_id = entries.id AND destination = 'tempo' AND status = 'logged')
+			WHERE id IN (SELECT entry_id FROM entry_mirrors WHERE destination = 'tempo' AND status = 'logged')`,
+		`DROP TABLE entry_mirrors`,
+	}},
+	{29, "create drafts", []string{`CREATE TABLE IF NOT EXISTS drafts (
+			id INTEGER PRIMARY KEY CHECK (id = 1),
+			text TEXT NOT NULL,
+			start_time DATETIME NOT NULL,
+			end_time DATETIME NOT NULL,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`}, []string{`DROP TABLE drafts`}},
+	{30, "add skips.break_failed and break_attempt_at", []string{
+		`ALTER TABLE skips ADD COLUMN break_failed INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE skips ADD COLUMN break_attempt_at DATETIME`,
+	}, []string{`ALTER TABLE skips DROP COLUMN break_attempt_at`, `ALTER TABLE skips DROP COLUMN break_failed`}},
+}
+
+// LatestSchemaVersion is the version this build migrates to.
+func LatestSchemaVersion() int {
+	return migrations[len(migrations)-1].Version
+}
+
+// MigrationStatus is one migration and whether it has been applied.
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+func (db *DB) ensureSchemaVersionTable() error {
+	_, err := db.DB.Exec(`CREATE TABLE IF NOT EXISTS schema_version (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`)
+	if err != nil {
+		return fmt.Errorf("creating schema_version: %w", err)
+	}
+	return nil
+}
+
+// SchemaVersion returns the highest applied migration, 0 for a new database.
+// A read-only database without schema_version is at 0 and is left as it is.
+func (db *DB) SchemaVersion() (int, error) {
+	if db.readOnly {
+		var n int
+		if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_version'`).Scan(&n); err != nil {
+			return 0, fmt.Errorf("reading schema version: %w", err)
+		}
+		if n == 0 {
+			return 0, nil
+		}
+	} else if err := db.ensureSchemaVersionTable(); err != nil {
+		return 0, err
+	}
+	var version int
+	if err := db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_version`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("reading schema version: %w", err)
+	}
+	return version, nil
+}
+
+// MigrationStatuses lists every migration this build knows, oldest first.
+func (db *DB) MigrationStatuses() ([]MigrationStatus, error) {
+	if err := db.ensureSchemaVersionTable(); err != nil {
+		return nil, err
+	}
+	rows, err := db.Query(`SELECT version, applied_at FROM schema_version`)
+	if err != nil {
+		return nil, fmt.Errorf("querying schema_version: %w", err)
+	}
+	defer rows.Close()
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedStr string
+		if err := rows.Scan(&version, &appliedStr); err != nil {
+			return nil, fmt.Errorf("scanning schema_version: %w", err)
+		}
+		t, _ := time.Parse(time.RFC3339, appliedStr)
+		applied[version] = t
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, len(migrations))
+	for i, m := range migrations {
+		at, ok := applied[m.Version]
+		statuses[i] = MigrationStatus{Version: m.Version, Name: m.Name, Applied: ok, AppliedAt: at}
+	}
+	return statuses, nil
+}
+
+// Migrate applies every migration above the current schema version and
+// returns the versions applied. A database from before schema_version
+// existed starts at 0; the statements skip what is already there.
+func (db *DB) Migrate() ([]int, error) {
+	current, err := db.SchemaVersion()
+	if err != nil {
+		return nil, err
+	}
+	var applied []int
+	for _, m := range migrations {
+		if m.Version <= current {
+			continue
+		}
+		if err := db.apply(m.Version, m.Name, m.Up, true); err != nil {
+			return applied, fmt.Errorf("migration %d (%s): %w", m.Version, m.Name, err)
+		}
+		applied = append(applied, m.Version)
+	}
+	return applied, nil
+}
+
+// MigrateTo rolls the schema back to version by running the Down statements
+// of every later migration, newest first, and returns the versions undone.
+// Rolling back drops the tables and columns those migrations added, with
+// their data. The next Open migrates forward again, so use it right before
+// downgrading clockr.
+func (db *DB) MigrateTo(version int) ([]int, error) {
+	if db.readOnly {
+		return nil, ErrReadOnly
+	}
+	if version < 0 || version > LatestSchemaVersion() {
+		return nil, fmt.Errorf("version must be between 0 and %d, got %d", LatestSchemaVersion(), version)
+	}
+	current, err := db.SchemaVersion()
+	if err != nil {
+		return nil, err
+	}
+	var undone []int
+	for i := len(migrations) - 1; i >= 0; i-- {
+		m := migrations[i]
+		if m.Version <= version || m.Version > current {
+			continue
+		}
+		if err := db.apply(m.Version, m.Name, m.Down, false); err != nil {
+			return undone, fmt.Errorf("rolling back migration %d (%s): %w", m.Version, m.Name, err)
+		}
+		undone = append(undone, m.Version)
+	}
+	return undone, nil
+}
+
+// apply runs statements in one transaction and records (up) or forgets
+// (down) version in schema_version.
+func (db *DB) apply(version int, name string, statements []string, up bool) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			// Databases from before schema_version already have the columns.
+			if up && strings.Contains(err.Error(), "duplicate column") {
+				continue
+			}
+			return err
+		}
+	}
+	if up {
+		_, err = tx.Exec(`INSERT OR REPLACE INTO schema_version (version, name) VALUES (?, ?)`, version, name)
+	} else {
+		_, err = tx.Exec(`DELETE FROM schema_version WHERE version = ?`, version)
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
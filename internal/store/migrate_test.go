@@ -0,0 +1,127 @@
+package store
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMigrate_FreshDatabase(t *testing.T) {
+	db := testDB(t)
+	version, err := db.SchemaVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != LatestSchemaVersion() {
+		t.Errorf("SchemaVersion() = %d, want %d", version, LatestSchemaVersion())
+	}
+	statuses, err := db.MigrationStatuses()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range statuses {
+		if !s.Applied {
+			t.Errorf("migration %d (%s) not applied", s.Version, s.Name)
+		}
+	}
+	if applied, err := db.Migrate(); err != nil || len(applied) != 0 {
+		t.Errorf("second Migrate() = %v, %v; want nothing applied", applied, err)
+	}
+}
+
+func TestMigrate_LegacyDatabase(t *testing.T) {
+	t.Setenv("CLOCKR_HOME", t.TempDir())
+	db, err := OpenUnmigrated()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// The schema of releases before schema_version, client_name included.
+	for _, stmt := range []string{
+		`CREATE TABLE entries (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			clockify_id TEXT,
+			project_id TEXT NOT NULL,
+			project_name TEXT NOT NULL,
+			description TEXT NOT NULL,
+			start_time DATETIME NOT NULL,
+			end_time DATETIME NOT NULL,
+			minutes INTEGER NOT NULL,
+			status TEXT NOT NULL DEFAULT 'logged',
+			raw_input TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE state (key TEXT PRIMARY KEY, value TEXT NOT NULL)`,
+		`ALTER TABLE entries ADD COLUMN client_name TEXT NOT NULL DEFAULT ''`,
+		`INSERT INTO entries (clockify_id, project_id, project_name, client_name, description, start_time, end_time, minutes)
+		 VALUES ('c1', 'p1', 'Alpha', 'Acme', 'Review', '2026-03-02T09:00:00Z', '2026-03-02T10:00:00Z', 60)`,
+	} {
+		if _, err := db.DB.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	applied, err := db.Migrate()
+	if err != nil {
+		t.Fatalf("Migrate() = %v", err)
+	}
+	if len(applied) != LatestSchemaVersion() || applied[0] != 1 {
+		t.Errorf("applied = %v, want every migration from 1", applied)
+	}
+	entries, err := db.AllEntries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].ClientName != "Acme" || entries[0].Minutes != 60 {
+		t.Errorf("entries after migrating = %+v, want the legacy entry kept", entries)
+	}
+}
+
+func TestMigrateTo_RoundTrip(t *testing.T) {
+	db := testDB(t)
+	if err := db.SetState("k", "v"); err != nil {
+		t.Fatal(err)
+	}
+
+	undone, err := db.MigrateTo(2)
+	if err != nil {
+		t.Fatalf("MigrateTo(2) = %v", err)
+	}
+	var want []int
+	for v := LatestSchemaVersion(); v > 2; v-- {
+		want = append(want, v)
+	}
+	if !slices.Equal(undone, want) {
+		t.Errorf("undone = %v, want %v", undone, want)
+	}
+	if version, _ := db.SchemaVersion(); version != 2 {
+		t.Errorf("SchemaVersion() after MigrateTo(2) = %d", version)
+	}
+	if tableExists(t, db, "skips") {
+		t.Error("skips still exists after rolling back to 2")
+	}
+	if v, _ := db.GetState("k"); v != "v" {
+		t.Errorf("state kept through the rollback = %q, want %q", v, "v")
+	}
+
+	if _, err := db.Migrate(); err != nil {
+		t.Fatalf("Migrate() after MigrateTo(2) = %v", err)
+	}
+	if version, _ := db.SchemaVersion(); version != LatestSchemaVersion() {
+		t.Errorf("SchemaVersion() = %d, want %d", version, LatestSchemaVersion())
+	}
+	if !tableExists(t, db, "skips") || !tableExists(t, db, "drafts") {
+		t.Error("tables missing after migrating forward again")
+	}
+
+	// Every Down statement runs, and every Up runs again after it.
+	if _, err := db.MigrateTo(0); err != nil {
+		t.Fatalf("MigrateTo(0) = %v", err)
+	}
+	if tableExists(t, db, "entries") {
+		t.Error("entries still exists after rolling back to 0")
+	}
+	if _, err := db.Migrate(); err != nil {
+		t.Fatalf("Migrate() after MigrateTo(0) = %v", err)
+	}
+}
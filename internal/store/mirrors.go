@@ -0,0 +1,100 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Mirror is the state of one entry in one mirror destination.
+type Mirror struct {
+	EntryID     int
+	Destination string // e.g. "tempo", "csv", "harvest"
+	Status      string // "logged", "failed", or "writing" while claimed
+	RemoteID    string // the destination's ID for the copy, if it has one
+	Error       string // why the last attempt failed
+	Attempts    int
+	UpdatedAt   time.Time
+}
+
+// RecordMirror stores the outcome of writing an entry to a destination:
+// logged with remoteID when writeErr is nil, failed otherwise. Attempts
+// count up across calls.
+func (db *DB) RecordMirror(entryID int, destination, remoteID string, writeErr error) error {
+	status, msg := "logged", ""
+	if writeErr != nil {
+		status, msg = "failed", writeErr.Error()
+	}
+	_, err := db.Exec(
+		`INSERT INTO entry_mirrors (entry_id, destination, status, remote_id, error, attempts, updated_at)
+		 VALUES (?, ?, ?, ?, ?, 1, ?)
+		 ON CONFLICT (entry_id, destination) DO UPDATE SET
+			status = excluded.status, remote_id = excluded.remote_id, error = excluded.error,
+			attempts = attempts + 1, updated_at = excluded.updated_at`,
+		entryID, destination, status, remoteID, msg, time.Now().UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return fmt.Errorf("recording mirror: %w", err)
+	}
+	return nil
+}
+
+// ClaimMirror marks entryID as being written to destination and reports
+// whether the caller won the claim. Only an entry that has no copy yet, or
+// whose last write failed fewer than maxAttempts times, can be claimed, and a
+// claim that was never recorded expires after lease, so the scheduler's
+// passes and other clockr processes never write the same copy twice.
+func (db *DB) ClaimMirror(entryID int, destination string, maxAttempts int, lease time.Duration) (bool, error) {
+	now := time.Now().UTC()
+	result, err := db.Exec(
+		`INSERT INTO entry_mirrors (entry_id, destination, status, attempts, updated_at)
+		 VALUES (?, ?, 'writing', 0, ?)
+		 ON CONFLICT (entry_id, destination) DO UPDATE SET status = 'writing', updated_at = excluded.updated_at
+		 WHERE (entry_mirrors.status = 'failed' AND entry_mirrors.attempts < ?)
+			OR (entry_mirrors.status = 'writing' AND entry_mirrors.updated_at <= ?)`,
+		entryID, destination, now.Format(time.RFC3339), maxAttempts, now.Add(-lease).Format(time.RFC3339),
+	)
+	if err != nil {
+		return false, fmt.Errorf("claiming mirror of entry %d: %w", entryID, err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+// GetMirrors returns the mirror state of the given entries, keyed by entry
+// ID and then destination.
+func (db *DB) GetMirrors(entryIDs []int) (map[int]map[string]Mirror, error) {
+	out := make(map[int]map[string]Mirror)
+	if len(entryIDs) == 0 {
+		return out, nil
+	}
+	args := make([]any, len(entryIDs))
+	for i, id := range entryIDs {
+		args[i] = id
+	}
+	rows, err := db.Query(
+		`SELECT entry_id, destination, status, remote_id, error, attempts, updated_at FROM entry_mirrors
+		 WHERE entry_id IN (?`+strings.Repeat(", ?", len(entryIDs)-1)+`)`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying mirrors: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var m Mirror
+		var updated string
+		if err := rows.Scan(&m.EntryID, &m.Destination, &m.Status, &m.RemoteID, &m.Error, &m.Attempts, &updated); err != nil {
+			return nil, fmt.Errorf("scanning mirror: %w", err)
+		}
+		if t, err := time.Parse(time.RFC3339, updated); err == nil {
+			m.UpdatedAt = t
+		}
+		if out[m.EntryID] == nil {
+			out[m.EntryID] = make(map[string]Mirror)
+		}
+		out[m.EntryID][m.Destination] = m
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,102 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Pause suppresses scheduler prompts from StartTime until EndTime, or until
+// resumed if EndTime is zero.
+type Pause struct {
+	ID        int
+	StartTime time.Time
+	EndTime   time.Time
+	Reason    string
+	CreatedAt time.Time
+}
+
+// Indefinite reports whether the pause lasts until resumed.
+func (p Pause) Indefinite() bool {
+	return p.EndTime.IsZero()
+}
+
+func (db *DB) InsertPause(p *Pause) (int64, error) {
+	var end any
+	if !p.EndTime.IsZero() {
+		end = p.EndTime.UTC().Format(time.RFC3339)
+	}
+	result, err := db.Exec(
+		`INSERT INTO pauses (start_time, end_time, reason) VALUES (?, ?, ?)`,
+		p.StartTime.UTC().Format(time.RFC3339), end, p.Reason,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("inserting pause: %w", err)
+	}
+	return result.LastInsertId()
+}
+
+// ActivePause returns the pause in effect at t, or nil. When several
+// overlap, the one ending last wins.
+func (db *DB) ActivePause(t time.Time) (*Pause, error) {
+	ts := t.UTC().Format(time.RFC3339)
+	pauses, err := db.queryPauses(
+		`SELECT id, start_time, end_time, reason, created_at FROM pauses
+		 WHERE start_time <= ? AND (end_time IS NULL OR end_time > ?)
+		 ORDER BY end_time IS NULL DESC, end_time DESC
+		 LIMIT 1`,
+		ts, ts,
+	)
+	if err != nil || len(pauses) == 0 {
+		return nil, err
+	}
+	return &pauses[0], nil
+}
+
+// EndActivePauses ends every pause in effect at t and returns how many there
+// were.
+func (db *DB) EndActivePauses(t time.Time) (int64, error) {
+	ts := t.UTC().Format(time.RFC3339)
+	result, err := db.Exec(
+		`UPDATE pauses SET end_time = ? WHERE start_time <= ? AND (end_time IS NULL OR end_time > ?)`,
+		ts, ts, ts,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("ending pauses: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// AllPauses returns every recorded pause, oldest first.
+func (db *DB) AllPauses() ([]Pause, error) {
+	return db.queryPauses("SELECT id, start_time, end_time, reason, created_at FROM pauses ORDER BY start_time ASC")
+}
+
+func (db *DB) queryPauses(query string, args ...any) ([]Pause, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying pauses: %w", err)
+	}
+	defer rows.Close()
+
+	var pauses []Pause
+	for rows.Next() {
+		var p Pause
+		var startStr, createdStr string
+		var endStr sql.NullString
+		if err := rows.Scan(&p.ID, &startStr, &endStr, &p.Reason, &createdStr); err != nil {
+			return nil, fmt.Errorf("scanning pause: %w", err)
+		}
+		if t, err := time.Parse(time.RFC3339, startStr); err == nil {
+			p.StartTime = t
+		}
+		if t, err := time.Parse(time.RFC3339, endStr.String); err == nil {
+			p.EndTime = t
+		}
+		if t, err := time.Parse(time.RFC3339, createdStr); err == nil {
+			p.CreatedAt = t
+		}
+		pauses = append(pauses, p)
+	}
+	return pauses, rows.Err()
+}
@@ -0,0 +1,71 @@
+package store
+
+import (
+	"fmt"
+	"time"
+)
+
+// LogPrompt records a scheduled prompt for the window ending at tick.
+func (db *DB) LogPrompt(tick time.Time) error {
+	if _, err := db.Exec("INSERT OR IGNORE INTO prompts (tick) VALUES (?)", tick.UTC().Format(time.RFC3339)); err != nil {
+		return fmt.Errorf("logging prompt: %w", err)
+	}
+	return nil
+}
+
+// AnswerPrompts marks the prompts for windows ending in (start, end] as
+// answered, by logging or skipping.
+func (db *DB) AnswerPrompts(start, end time.Time) error {
+	_, err := db.Exec("UPDATE prompts SET answered = 1 WHERE tick > ? AND tick <= ?",
+		start.UTC().Format(time.RFC3339), end.UTC().Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("answering prompts: %w", err)
+	}
+	return nil
+}
+
+// PromptCounts returns how many prompts were shown in [start, end) and how
+// many of them were answered.
+func (db *DB) PromptCounts(start, end time.Time) (shown, answered int, err error) {
+	err = db.QueryRow("SELECT COUNT(*), COALESCE(SUM(answered), 0) FROM prompts WHERE tick >= ? AND tick < ?",
+		start.UTC().Format(time.RFC3339), end.UTC().Format(time.RFC3339)).Scan(&shown, &answered)
+	if err != nil {
+		return 0, 0, fmt.Errorf("counting prompts: %w", err)
+	}
+	return shown, answered, nil
+}
+
+// LogSuggestionEdit records how much of an AI suggestion was changed before
+// it was accepted, from 0 (as suggested) to 1 (rewritten).
+func (db *DB) LogSuggestionEdit(start, end time.Time, distance float64) error {
+	_, err := db.Exec("INSERT INTO suggestion_edits (start_time, end_time, distance) VALUES (?, ?, ?)",
+		start.UTC().Format(time.RFC3339), end.UTC().Format(time.RFC3339), distance)
+	if err != nil {
+		return fmt.Errorf("logging suggestion edit: %w", err)
+	}
+	return nil
+}
+
+// AverageEdit returns the mean edit distance of suggestions accepted for
+// windows starting in [start, end), and how many there were.
+func (db *DB) AverageEdit(start, end time.Time) (float64, int, error) {
+	var avg float64
+	var n int
+	err := db.QueryRow("SELECT COALESCE(AVG(distance), 0), COUNT(*) FROM suggestion_edits WHERE start_time >= ? AND start_time < ?",
+		start.UTC().Format(time.RFC3339), end.UTC().Format(time.RFC3339)).Scan(&avg, &n)
+	if err != nil {
+		return 0, 0, fmt.Errorf("averaging suggestion edits: %w", err)
+	}
+	return avg, n, nil
+}
+
+// EditCounts returns how many suggestions were accepted for windows starting
+// in [start, end), and how many of them were changed first.
+func (db *DB) EditCounts(start, end time.Time) (accepted, edited int, err error) {
+	err = db.QueryRow("SELECT COUNT(*), COALESCE(SUM(distance > 0), 0) FROM suggestion_edits WHERE start_time >= ? AND start_time < ?",
+		start.UTC().Format(time.RFC3339), end.UTC().Format(time.RFC3339)).Scan(&accepted, &edited)
+	if err != nil {
+		return 0, 0, fmt.Errorf("counting suggestion edits: %w", err)
+	}
+	return accepted, edited, nil
+}
@@ -0,0 +1,77 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RawInput is a description as typed at a prompt, before the AI saw it.
+type RawInput struct {
+	ID        int
+	Text      string
+	CreatedAt time.Time
+}
+
+// maxRawInputs is how many inputs the history keeps; older ones are
+// dropped as new ones are added.
+const maxRawInputs = 1000
+
+// AddRawInput records text in the input history, dropping the oldest inputs
+// beyond maxRawInputs. Blank text is ignored.
+func (db *DB) AddRawInput(text string) error {
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+	result, err := db.Exec(`INSERT INTO raw_inputs (text) VALUES (?)`, text)
+	if err != nil {
+		return fmt.Errorf("saving input: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	if _, err := db.Exec(`DELETE FROM raw_inputs WHERE id <= ?`, id-maxRawInputs); err != nil {
+		return fmt.Errorf("trimming input history: %w", err)
+	}
+	return nil
+}
+
+// GetRecentRawInputs returns up to n distinct inputs, most recently used
+// first. A description typed again moves to the front instead of appearing
+// twice.
+func (db *DB) GetRecentRawInputs(n int) ([]RawInput, error) {
+	rows, err := db.Query(
+		`SELECT id, text, created_at FROM raw_inputs
+		 WHERE id IN (SELECT MAX(id) FROM raw_inputs GROUP BY text)
+		 ORDER BY id DESC
+		 LIMIT ?`, n,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("querying input history: %w", err)
+	}
+	defer rows.Close()
+
+	var inputs []RawInput
+	for rows.Next() {
+		var r RawInput
+		var createdStr string
+		if err := rows.Scan(&r.ID, &r.Text, &createdStr); err != nil {
+			return nil, fmt.Errorf("scanning input: %w", err)
+		}
+		if t, err := time.Parse(time.RFC3339, createdStr); err == nil {
+			r.CreatedAt = t
+		}
+		inputs = append(inputs, r)
+	}
+	return inputs, rows.Err()
+}
+
+// GetLastRawInput returns the most recent input, or "" if there is none.
+func (db *DB) GetLastRawInput() (string, error) {
+	inputs, err := db.GetRecentRawInputs(1)
+	if err != nil || len(inputs) == 0 {
+		return "", err
+	}
+	return inputs[0].Text, nil
+}
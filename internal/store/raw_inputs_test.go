@@ -0,0 +1,46 @@
+package store
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestRawInputs(t *testing.T) {
+	db := testDB(t)
+	for _, text := range []string{"review", "  ", "standup", "review"} {
+		if err := db.AddRawInput(text); err != nil {
+			t.Fatal(err)
+		}
+	}
+	inputs, err := db.GetRecentRawInputs(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Blank input is skipped and a repeated one moves to the front.
+	if len(inputs) != 2 || inputs[0].Text != "review" || inputs[1].Text != "standup" {
+		t.Errorf("history = %+v, want review, standup", inputs)
+	}
+}
+
+func TestAddRawInput_Capped(t *testing.T) {
+	db := testDB(t)
+	for i := range maxRawInputs + 5 {
+		if err := db.AddRawInput(fmt.Sprintf("input %d", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var n int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM raw_inputs`).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	if n != maxRawInputs {
+		t.Errorf("history keeps %d inputs, want %d", n, maxRawInputs)
+	}
+	inputs, err := db.GetRecentRawInputs(maxRawInputs + 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last := inputs[len(inputs)-1].Text; last != "input 5" {
+		t.Errorf("oldest kept input = %q, want input 5", last)
+	}
+}
@@ -0,0 +1,154 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Skip is a prompt window the user deliberately left untracked.
+type Skip struct {
+	ID          int
+	StartTime   time.Time
+	EndTime     time.Time
+	Minutes     int
+	Reason      string // empty when no reason was given
+	ClockifyID  string // break entry logged for the skip under [coverage], if any
+	BreakFailed bool   // the break entry could not be logged and waits for a retry
+	CreatedAt   time.Time
+}
+
+// ReasonMinutes is the total skipped time for one reason.
+type ReasonMinutes struct {
+	Reason  string
+	Minutes int
+}
+
+// InsertSkip stores s and sets s.ID.
+func (db *DB) InsertSkip(s *Skip) (int64, error) {
+	result, err := db.Exec(
+		`INSERT INTO skips (start_time, end_time, minutes, reason) VALUES (?, ?, ?, ?)`,
+		s.StartTime.UTC().Format(time.RFC3339),
+		s.EndTime.UTC().Format(time.RFC3339),
+		s.Minutes, s.Reason,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("inserting skip: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	s.ID = int(id)
+	return id, nil
+}
+
+// SetSkipClockifyID records the break entry logged for a skip and takes the
+// skip off the retry queue.
+func (db *DB) SetSkipClockifyID(id int, clockifyID string) error {
+	if _, err := db.Exec("UPDATE skips SET clockify_id = ?, break_failed = 0 WHERE id = ?", clockifyID, id); err != nil {
+		return fmt.Errorf("saving skip clockify id: %w", err)
+	}
+	return nil
+}
+
+// MarkBreakFailed queues a skip whose break entry could not be logged for
+// a retry.
+func (db *DB) MarkBreakFailed(id int) error {
+	if _, err := db.Exec("UPDATE skips SET break_failed = 1 WHERE id = ? AND clockify_id = ''", id); err != nil {
+		return fmt.Errorf("queueing break for retry: %w", err)
+	}
+	return nil
+}
+
+// GetFailedBreaks returns the skips whose break entry still has to be
+// logged, oldest first.
+func (db *DB) GetFailedBreaks() ([]Skip, error) {
+	return db.querySkips(`SELECT ` + skipColumns + ` FROM skips
+		WHERE break_failed = 1 AND clockify_id = '' ORDER BY start_time ASC`)
+}
+
+// ClaimBreakRetry reserves a failed break for one attempt, like ClaimRetry
+// does for entries. It returns false if the break is already logged or was
+// attempted within lease.
+func (db *DB) ClaimBreakRetry(id int, lease time.Duration) (bool, error) {
+	now := time.Now().UTC()
+	result, err := db.Exec(
+		`UPDATE skips SET break_attempt_at = ?
+		 WHERE id = ? AND break_failed = 1 AND clockify_id = '' AND (break_attempt_at IS NULL OR break_attempt_at < ?)`,
+		now.Format(time.RFC3339), id, now.Add(-lease).Format(time.RFC3339),
+	)
+	if err != nil {
+		return false, fmt.Errorf("claiming break %d: %w", id, err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+// GetSkipsBetween returns skips starting in [start, end), oldest first.
+func (db *DB) GetSkipsBetween(start, end time.Time) ([]Skip, error) {
+	return db.querySkips(
+		`SELECT `+skipColumns+` FROM skips
+		 WHERE start_time >= ? AND start_time < ?
+		 ORDER BY start_time ASC`,
+		start.UTC().Format(time.RFC3339),
+		end.UTC().Format(time.RFC3339),
+	)
+}
+
+// AllSkips returns every recorded skip, oldest first.
+func (db *DB) AllSkips() ([]Skip, error) {
+	return db.querySkips("SELECT " + skipColumns + " FROM skips ORDER BY start_time ASC")
+}
+
+// SkipTotals sums skipped minutes per reason, largest first.
+func SkipTotals(skips []Skip) []ReasonMinutes {
+	byReason := make(map[string]int)
+	for _, s := range skips {
+		byReason[s.Reason] += s.Minutes
+	}
+	totals := make([]ReasonMinutes, 0, len(byReason))
+	for r, m := range byReason {
+		totals = append(totals, ReasonMinutes{Reason: r, Minutes: m})
+	}
+	sort.Slice(totals, func(i, j int) bool {
+		if totals[i].Minutes != totals[j].Minutes {
+			return totals[i].Minutes > totals[j].Minutes
+		}
+		return totals[i].Reason < totals[j].Reason
+	})
+	return totals
+}
+
+const skipColumns = "id, start_time, end_time, minutes, reason, clockify_id, break_failed, created_at"
+
+func (db *DB) querySkips(query string, args ...any) ([]Skip, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying skips: %w", err)
+	}
+	defer rows.Close()
+
+	var skips []Skip
+	for rows.Next() {
+		var s Skip
+		var startStr, endStr, createdStr string
+		if err := rows.Scan(&s.ID, &startStr, &endStr, &s.Minutes, &s.Reason, &s.ClockifyID, &s.BreakFailed, &createdStr); err != nil {
+			return nil, fmt.Errorf("scanning skip: %w", err)
+		}
+		if t, err := time.Parse(time.RFC3339, startStr); err == nil {
+			s.StartTime = t
+		}
+		if t, err := time.Parse(time.RFC3339, endStr); err == nil {
+			s.EndTime = t
+		}
+		if t, err := time.Parse(time.RFC3339, createdStr); err == nil {
+			s.CreatedAt = t
+		}
+		skips = append(skips, s)
+	}
+	return skips, rows.Err()
+}
@@ -0,0 +1,60 @@
+package store
+
+import (
+	"fmt"
+	"time"
+)
+
+// Snippet is a named piece of description text, inserted with Ctrl+T in
+// the TUI ('clockr templates').
+type Snippet struct {
+	Name      string
+	Text      string
+	CreatedAt time.Time
+}
+
+// SaveSnippet adds a snippet, replacing the text of one with the same name.
+func (db *DB) SaveSnippet(name, text string) error {
+	_, err := db.Exec(
+		`INSERT INTO snippets (name, text) VALUES (?, ?)
+		 ON CONFLICT(name) DO UPDATE SET text = excluded.text`,
+		name, text,
+	)
+	if err != nil {
+		return fmt.Errorf("saving snippet: %w", err)
+	}
+	return nil
+}
+
+// DeleteSnippet removes the named snippet and reports whether it existed.
+func (db *DB) DeleteSnippet(name string) (bool, error) {
+	result, err := db.Exec(`DELETE FROM snippets WHERE name = ?`, name)
+	if err != nil {
+		return false, fmt.Errorf("deleting snippet: %w", err)
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// Snippets returns every snippet, sorted by name.
+func (db *DB) Snippets() ([]Snippet, error) {
+	rows, err := db.Query(`SELECT name, text, created_at FROM snippets ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("querying snippets: %w", err)
+	}
+	defer rows.Close()
+
+	var snippets []Snippet
+	for rows.Next() {
+		var s Snippet
+		var createdStr string
+		if err := rows.Scan(&s.Name, &s.Text, &createdStr); err != nil {
+			return nil, fmt.Errorf("scanning snippet: %w", err)
+		}
+		if t, err := time.Parse(time.RFC3339, createdStr); err == nil {
+			s.CreatedAt = t
+		}
+		snippets = append(snippets, s)
+	}
+	return snippets, rows.Err()
+}
@@ -0,0 +1,84 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// savedSuggestionDays is how long an unused suggestion stays resumable.
+const savedSuggestionDays = 7
+
+// SavedSuggestion is the last AI suggestion shown for a prompt window, kept
+// so 'clockr log --resume' can reopen it without asking the AI again.
+type SavedSuggestion struct {
+	StartTime   time.Time
+	EndTime     time.Time
+	Description string
+	Suggestion  string // JSON-encoded ai.Suggestion
+	Context     string // JSON-encoded []ai.ContextItem
+	UpdatedAt   time.Time
+}
+
+// SaveSuggestion stores s as the suggestion for its window, replacing an
+// earlier one, and drops suggestions that are too old to resume.
+func (db *DB) SaveSuggestion(s *SavedSuggestion) error {
+	_, err := db.Exec(
+		`INSERT INTO suggestions (start_time, end_time, description, suggestion, context, updated_at)
+		 VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+		 ON CONFLICT (start_time, end_time) DO UPDATE SET
+			description = excluded.description,
+			suggestion = excluded.suggestion,
+			context = excluded.context,
+			updated_at = CURRENT_TIMESTAMP`,
+		s.StartTime.UTC().Format(time.RFC3339),
+		s.EndTime.UTC().Format(time.RFC3339),
+		s.Description, s.Suggestion, s.Context,
+	)
+	if err != nil {
+		return fmt.Errorf("saving suggestion: %w", err)
+	}
+	if _, err := db.Exec(fmt.Sprintf("DELETE FROM suggestions WHERE updated_at < datetime('now', '-%d days')", savedSuggestionDays)); err != nil {
+		return fmt.Errorf("pruning suggestions: %w", err)
+	}
+	return nil
+}
+
+// LatestSuggestion returns the most recently saved suggestion, or nil if
+// there is none.
+func (db *DB) LatestSuggestion() (*SavedSuggestion, error) {
+	var s SavedSuggestion
+	var startStr, endStr, updatedStr string
+	err := db.QueryRow(
+		`SELECT start_time, end_time, description, suggestion, context, updated_at FROM suggestions
+		 ORDER BY updated_at DESC, start_time DESC LIMIT 1`,
+	).Scan(&startStr, &endStr, &s.Description, &s.Suggestion, &s.Context, &updatedStr)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying suggestion: %w", err)
+	}
+	if t, err := time.Parse(time.RFC3339, startStr); err == nil {
+		s.StartTime = t
+	}
+	if t, err := time.Parse(time.RFC3339, endStr); err == nil {
+		s.EndTime = t
+	}
+	if t, err := time.Parse(time.RFC3339, updatedStr); err == nil {
+		s.UpdatedAt = t
+	}
+	return &s, nil
+}
+
+// DeleteSuggestion forgets the saved suggestion for a window once it has been
+// logged or skipped.
+func (db *DB) DeleteSuggestion(start, end time.Time) error {
+	_, err := db.Exec("DELETE FROM suggestions WHERE start_time = ? AND end_time = ?",
+		start.UTC().Format(time.RFC3339), end.UTC().Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("deleting suggestion: %w", err)
+	}
+	return nil
+}
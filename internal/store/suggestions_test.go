@@ -0,0 +1,155 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSavedSuggestion(t *testing.T) {
+	db := testDB(t)
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+
+	if s, err := db.LatestSuggestion(); err != nil || s != nil {
+		t.Fatalf("LatestSuggestion() on an empty db = %+v, %v", s, err)
+	}
+
+	// Saving the same window again replaces the suggestion.
+	for _, desc := range []string{"first try", "second try"} {
+		err := db.SaveSuggestion(&SavedSuggestion{StartTime: start, EndTime: end, Description: desc, Suggestion: `{"allocations":[]}`, Context: "[]"})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	var n int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM suggestions`).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("suggestions = %d rows, want 1", n)
+	}
+	s, err := db.LatestSuggestion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s == nil || s.Description != "second try" || !s.StartTime.Equal(start) || !s.EndTime.Equal(end) || s.UpdatedAt.IsZero() {
+		t.Fatalf("LatestSuggestion() = %+v, want the second try for 09:00–10:00", s)
+	}
+
+	if err := db.DeleteSuggestion(start, end); err != nil {
+		t.Fatal(err)
+	}
+	if s, err := db.LatestSuggestion(); err != nil || s != nil {
+		t.Errorf("LatestSuggestion() after delete = %+v, %v", s, err)
+	}
+}
+
+func TestSaveSuggestion_PrunesOld(t *testing.T) {
+	db := testDB(t)
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+	if err := db.SaveSuggestion(&SavedSuggestion{StartTime: start, EndTime: start.Add(time.Hour), Description: "old"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`UPDATE suggestions SET updated_at = datetime('now', '-8 days')`); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SaveSuggestion(&SavedSuggestion{StartTime: start.Add(time.Hour), EndTime: start.Add(2 * time.Hour), Description: "new"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var descs []string
+	rows, err := db.Query(`SELECT description FROM suggestions`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var d string
+		rows.Scan(&d)
+		descs = append(descs, d)
+	}
+	if len(descs) != 1 || descs[0] != "new" {
+		t.Errorf("suggestions left = %q, want only the new one", descs)
+	}
+}
+
+func TestEntrySuggestion(t *testing.T) {
+	db := testDB(t)
+
+	type allocation struct {
+		ProjectID  string  `json:"project_id"`
+		Confidence float64 `json:"confidence"`
+	}
+	id, err := db.SaveEntrySuggestion("fixed the login bug", []allocation{{"p1", 0.9}}, nil, "abc123", "model-x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id == 0 {
+		t.Fatal("SaveEntrySuggestion returned ID 0")
+	}
+
+	s, err := db.GetEntrySuggestion(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := EntrySuggestion{
+		ID:             id,
+		RawInput:       "fixed the login bug",
+		Suggestion:     `[{"project_id":"p1","confidence":0.9}]`,
+		Clarifications: "[]", // nil is stored as an empty list
+		PromptVersion:  "abc123",
+		Model:          "model-x",
+	}
+	if s == nil || s.CreatedAt.IsZero() {
+		t.Fatalf("GetEntrySuggestion(%d) = %+v, want a created row", id, s)
+	}
+	s.CreatedAt = time.Time{}
+	if *s != want {
+		t.Errorf("GetEntrySuggestion(%d) = %+v, want %+v", id, *s, want)
+	}
+
+	if s, err := db.GetEntrySuggestion(id + 1); err != nil || s != nil {
+		t.Errorf("GetEntrySuggestion(missing) = %+v, %v; want nil", s, err)
+	}
+}
+
+func TestSuggestionEdits(t *testing.T) {
+	db := testDB(t)
+	monday := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	at := func(day, hour int) time.Time { return monday.AddDate(0, 0, day).Add(time.Duration(hour) * time.Hour) }
+
+	for _, e := range []struct {
+		start    time.Time
+		distance float64
+	}{
+		{at(0, 9), 0},    // accepted as suggested
+		{at(0, 10), 0.5}, // changed first
+		{at(1, 9), 0.25},
+		{at(7, 9), 1}, // the next week
+	} {
+		if err := db.LogSuggestionEdit(e.start, e.start.Add(time.Hour), e.distance); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	accepted, edited, err := db.EditCounts(monday, monday.AddDate(0, 0, 7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if accepted != 3 || edited != 2 {
+		t.Errorf("EditCounts = %d accepted, %d edited; want 3, 2", accepted, edited)
+	}
+	avg, n, err := db.AverageEdit(monday, monday.AddDate(0, 0, 7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 || avg != 0.25 {
+		t.Errorf("AverageEdit = %v over %d, want 0.25 over 3", avg, n)
+	}
+
+	// An empty range counts nothing.
+	if accepted, edited, err := db.EditCounts(monday.AddDate(0, 0, 14), monday.AddDate(0, 0, 21)); err != nil || accepted != 0 || edited != 0 {
+		t.Errorf("EditCounts of an empty week = %d, %d, %v", accepted, edited, err)
+	}
+}
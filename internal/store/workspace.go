@@ -0,0 +1,82 @@
+package store
+
+import (
+	"fmt"
+	"time"
+)
+
+// ProjectMigration records that entries logged against OldProjectID were
+// moved to NewProjectID when switching Clockify workspaces.
+type ProjectMigration struct {
+	ID             int
+	OldWorkspaceID string
+	NewWorkspaceID string
+	OldProjectID   string
+	NewProjectID   string
+	Entries        int // local entries rewritten
+	CreatedAt      time.Time
+}
+
+// MigrateProjects points local entries at the new workspace's projects, using
+// projectIDs (old → new), and records each mapping so the history of an
+// entry's project survives the switch. The rewritten entries lose their
+// Clockify ID, which names a time entry in the old workspace, so audits and
+// edits don't look it up in the new one. It returns the number of entries
+// rewritten. Entries of unmapped projects are left as they are.
+func (db *DB) MigrateProjects(oldWorkspaceID, newWorkspaceID string, projectIDs map[string]string) (int64, error) {
+	if db.readOnly {
+		return 0, ErrReadOnly
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("starting migration: %w", err)
+	}
+	defer tx.Rollback()
+
+	var total int64
+	for oldID, newID := range projectIDs {
+		result, err := tx.Exec(`UPDATE entries SET project_id = ?, clockify_id = '' WHERE project_id = ?`, newID, oldID)
+		if err != nil {
+			return 0, fmt.Errorf("rewriting entries of %s: %w", oldID, err)
+		}
+		n, _ := result.RowsAffected()
+		total += n
+		if _, err := tx.Exec(
+			`INSERT INTO project_migrations (old_workspace_id, new_workspace_id, old_project_id, new_project_id, entries)
+			 VALUES (?, ?, ?, ?, ?)`,
+			oldWorkspaceID, newWorkspaceID, oldID, newID, n,
+		); err != nil {
+			return 0, fmt.Errorf("recording migration of %s: %w", oldID, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("committing migration: %w", err)
+	}
+	return total, nil
+}
+
+// AllProjectMigrations returns every recorded project mapping, oldest first.
+func (db *DB) AllProjectMigrations() ([]ProjectMigration, error) {
+	rows, err := db.Query(
+		`SELECT id, old_workspace_id, new_workspace_id, old_project_id, new_project_id, entries, created_at
+		 FROM project_migrations ORDER BY id ASC`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("querying project migrations: %w", err)
+	}
+	defer rows.Close()
+
+	var migrations []ProjectMigration
+	for rows.Next() {
+		var m ProjectMigration
+		var createdStr string
+		if err := rows.Scan(&m.ID, &m.OldWorkspaceID, &m.NewWorkspaceID, &m.OldProjectID, &m.NewProjectID, &m.Entries, &createdStr); err != nil {
+			return nil, fmt.Errorf("scanning project migration: %w", err)
+		}
+		if t, err := time.Parse(time.RFC3339, createdStr); err == nil {
+			m.CreatedAt = t
+		}
+		migrations = append(migrations, m)
+	}
+	return migrations, rows.Err()
+}
@@ -0,0 +1,54 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMigrateProjects(t *testing.T) {
+	db := testDB(t)
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	for i, e := range []Entry{
+		{ClockifyID: "old-1", ProjectID: "p-old", Status: "logged"},
+		{ProjectID: "p-old", Status: "failed"},
+		{ClockifyID: "old-3", ProjectID: "p-unmapped", Status: "logged"},
+	} {
+		e.StartTime = start.Add(time.Duration(i) * time.Hour)
+		e.EndTime = e.StartTime.Add(time.Hour)
+		e.Minutes = 60
+		if _, err := db.InsertEntry(&e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	n, err := db.MigrateProjects("ws-old", "ws-new", map[string]string{"p-old": "p-new"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("rewrote %d entries, want 2", n)
+	}
+
+	entries, err := db.GetEntriesBetween(start, start.Add(24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct{ project, clockifyID string }{
+		{"p-new", ""}, // the old workspace's entry ID is dropped
+		{"p-new", ""},
+		{"p-unmapped", "old-3"},
+	}
+	for i, w := range want {
+		if entries[i].ProjectID != w.project || entries[i].ClockifyID != w.clockifyID {
+			t.Errorf("entry %d = project %q, clockify %q; want %q, %q", i, entries[i].ProjectID, entries[i].ClockifyID, w.project, w.clockifyID)
+		}
+	}
+
+	migrations, err := db.AllProjectMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) != 1 || migrations[0].OldProjectID != "p-old" || migrations[0].NewProjectID != "p-new" || migrations[0].Entries != 2 {
+		t.Errorf("migrations = %+v", migrations)
+	}
+}
@@ -0,0 +1,442 @@
+// Package tempo logs worklogs to Tempo for Jira (API v4). The Client
+// implements backend.Backend for [backend] type = "tempo", offering Jira
+// issues as projects, and AddWorklog mirrors entries logged to another
+// backend. Tempo only knows Jira's numeric account and issue IDs, so the
+// client also talks to the Jira Cloud REST API to resolve them.
+package tempo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/clockify"
+	"github.com/christopherklint97/clockr/internal/httpmetrics"
+	"github.com/christopherklint97/clockr/internal/httpretry"
+)
+
+const defaultBaseURL = "https://api.tempo.io/4"
+
+// defaultJQL offers the user's open issues, most recently updated first.
+const defaultJQL = "assignee = currentUser() AND statusCategory != Done ORDER BY updated DESC"
+
+// ErrReadOnly is returned for any non-GET request while the client is read-only.
+var ErrReadOnly = errors.New("read-only mode: Tempo writes are disabled")
+
+// APIError is returned when Tempo or Jira responds with a non-2xx status.
+type APIError struct {
+	Service    string // "Tempo" or "Jira"
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s API error (status %d): %s", e.Service, e.StatusCode, strings.TrimSpace(e.Body))
+}
+
+// Jira is the Jira Cloud site worklogs are logged against, with an Atlassian
+// API token for the user.
+type Jira struct {
+	URL   string // e.g. "https://acme.atlassian.net"
+	Email string
+	Token string
+}
+
+// Client is a Tempo API client for one Jira user.
+type Client struct {
+	token      string
+	baseURL    string
+	jira       Jira
+	jql        string
+	httpClient *http.Client
+	logger     *slog.Logger
+	readOnly   bool
+	loc        *time.Location
+
+	mu        sync.Mutex
+	accountID string            // the Jira user, looked up on first use
+	issueIDs  map[string]string // issue key → Jira issue ID
+	issueKeys map[string]string // Jira issue ID → issue key
+}
+
+// NewClient returns a client authenticated with a Tempo API token, logging
+// worklogs as the Jira user jira authenticates.
+func NewClient(token, baseURL string, jira Jira, logger *slog.Logger) *Client {
+	if logger == nil {
+		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+	jira.URL = strings.TrimRight(jira.URL, "/")
+	return &Client{
+		token:   token,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		jira:    jira,
+		jql:     defaultJQL,
+		httpClient: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: httpmetrics.Wrap("tempo", nil),
+		},
+		logger:    logger,
+		loc:       time.Local,
+		issueIDs:  make(map[string]string),
+		issueKeys: make(map[string]string),
+	}
+}
+
+// SetTimeout limits how long each request may take.
+func (c *Client) SetTimeout(d time.Duration) {
+	c.httpClient.Timeout = d
+}
+
+// SetReadOnly blocks all write requests (anything other than GET) when enabled.
+func (c *Client) SetReadOnly(readOnly bool) {
+	c.readOnly = readOnly
+}
+
+// SetLocation sets the zone worklog dates and start times are in, the
+// schedule's zone. The default is time.Local.
+func (c *Client) SetLocation(loc *time.Location) {
+	c.loc = loc
+}
+
+// SetJQL sets the Jira query whose issues ListProjects returns. Empty keeps
+// the default, the open issues assigned to the user.
+func (c *Client) SetJQL(jql string) {
+	if jql != "" {
+		c.jql = jql
+	}
+}
+
+func (c *Client) Name() string { return "Tempo" }
+
+// tempoRequest calls the Tempo API at path.
+func (c *Client) tempoRequest(ctx context.Context, method, path string, body any) ([]byte, error) {
+	return c.doRequest(ctx, "Tempo", method, c.baseURL+path, body, func(req *http.Request) {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	})
+}
+
+// jiraRequest calls the Jira REST API at path.
+func (c *Client) jiraRequest(ctx context.Context, path string) ([]byte, error) {
+	return c.doRequest(ctx, "Jira", http.MethodGet, c.jira.URL+path, nil, func(req *http.Request) {
+		req.SetBasicAuth(c.jira.Email, c.jira.Token)
+	})
+}
+
+func (c *Client) doRequest(ctx context.Context, service, method, rawURL string, body any, auth func(*http.Request)) ([]byte, error) {
+	if c.readOnly && method != http.MethodGet {
+		c.logger.Debug("blocked write in read-only mode", "method", method, "url", rawURL)
+		return nil, ErrReadOnly
+	}
+
+	c.logger.Debug("tempo API request", "service", service, "method", method, "url", rawURL)
+	status, respBody, err := httpretry.JSON(ctx, c.httpClient, httpretry.Default, c.logger, service, method, rawURL, body, func(req *http.Request) {
+		auth(req)
+		req.Header.Set("Accept", "application/json")
+	})
+	if err != nil {
+		return nil, err
+	}
+	if status < 200 || status >= 300 {
+		c.logger.Error(service+" API request failed", "method", method, "url", rawURL, "status", status)
+		return nil, &APIError{Service: service, StatusCode: status, Body: string(respBody)}
+	}
+	return respBody, nil
+}
+
+// account returns the Jira account ID worklogs are logged as.
+func (c *Client) account(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	id := c.accountID
+	c.mu.Unlock()
+	if id != "" {
+		return id, nil
+	}
+	data, err := c.jiraRequest(ctx, "/rest/api/3/myself")
+	if err != nil {
+		return "", fmt.Errorf("getting Jira user: %w", err)
+	}
+	var me struct {
+		AccountID string `json:"accountId"`
+	}
+	if err := json.Unmarshal(data, &me); err != nil {
+		return "", fmt.Errorf("parsing Jira user: %w", err)
+	}
+	if me.AccountID == "" {
+		return "", errors.New("Jira did not return an account ID")
+	}
+	c.mu.Lock()
+	c.accountID = me.AccountID
+	c.mu.Unlock()
+	return me.AccountID, nil
+}
+
+// issue looks up a Jira issue by key or ID and remembers both.
+func (c *Client) issue(ctx context.Context, keyOrID string) (id, key string, err error) {
+	c.mu.Lock()
+	if id, ok := c.issueIDs[keyOrID]; ok {
+		c.mu.Unlock()
+		return id, keyOrID, nil
+	}
+	if key, ok := c.issueKeys[keyOrID]; ok {
+		c.mu.Unlock()
+		return keyOrID, key, nil
+	}
+	c.mu.Unlock()
+
+	data, err := c.jiraRequest(ctx, "/rest/api/3/issue/"+url.PathEscape(keyOrID)+"?fields=summary")
+	if err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+			return "", "", fmt.Errorf("Jira issue %s not found", keyOrID)
+		}
+		return "", "", fmt.Errorf("looking up Jira issue %s: %w", keyOrID, err)
+	}
+	var is struct {
+		ID  string `json:"id"`
+		Key string `json:"key"`
+	}
+	if err := json.Unmarshal(data, &is); err != nil {
+		return "", "", fmt.Errorf("parsing Jira issue: %w", err)
+	}
+	c.remember(is.ID, is.Key)
+	return is.ID, is.Key, nil
+}
+
+func (c *Client) remember(id, key string) {
+	c.mu.Lock()
+	c.issueIDs[key] = id
+	c.issueKeys[id] = key
+	c.mu.Unlock()
+}
+
+// ListProjects returns the Jira issues matching the JQL as projects: the
+// issue key is the ID, the summary the name and the Jira project the client.
+func (c *Client) ListProjects(ctx context.Context) ([]clockify.Project, error) {
+	var projects []clockify.Project
+	token := ""
+	for {
+		q := url.Values{}
+		q.Set("jql", c.jql)
+		q.Set("fields", "summary,project")
+		q.Set("maxResults", "100")
+		if token != "" {
+			q.Set("nextPageToken", token)
+		}
+		data, err := c.jiraRequest(ctx, "/rest/api/3/search/jql?"+q.Encode())
+		if err != nil {
+			return nil, fmt.Errorf("searching Jira issues: %w", err)
+		}
+		var page struct {
+			Issues []struct {
+				ID     string `json:"id"`
+				Key    string `json:"key"`
+				Fields struct {
+					Summary string `json:"summary"`
+					Project struct {
+						ID   string `json:"id"`
+						Name string `json:"name"`
+					} `json:"project"`
+				} `json:"fields"`
+			} `json:"issues"`
+			NextPageToken string `json:"nextPageToken"`
+		}
+		if err := json.Unmarshal(data, &page); err != nil {
+			return nil, fmt.Errorf("parsing Jira issues: %w", err)
+		}
+		for _, is := range page.Issues {
+			c.remember(is.ID, is.Key)
+			projects = append(projects, clockify.Project{
+				ID:         is.Key,
+				Name:       is.Key + " " + is.Fields.Summary,
+				ClientID:   is.Fields.Project.ID,
+				ClientName: is.Fields.Project.Name,
+			})
+		}
+		if page.NextPageToken == "" || len(page.Issues) == 0 {
+			return projects, nil
+		}
+		token = page.NextPageToken
+	}
+}
+
+type worklog struct {
+	TempoWorklogID int64 `json:"tempoWorklogId"`
+	Issue          struct {
+		ID int64 `json:"id"`
+	} `json:"issue"`
+	TimeSpentSeconds int    `json:"timeSpentSeconds"`
+	StartDate        string `json:"startDate"`
+	StartTime        string `json:"startTime"`
+	Description      string `json:"description"`
+}
+
+// toClockify converts w, with the issue key as the project.
+func (c *Client) toClockify(ctx context.Context, w worklog) clockify.TimeEntry {
+	out := clockify.TimeEntry{
+		ID:          strconv.FormatInt(w.TempoWorklogID, 10),
+		Description: w.Description,
+	}
+	issueID := strconv.FormatInt(w.Issue.ID, 10)
+	if _, key, err := c.issue(ctx, issueID); err == nil {
+		out.ProjectID = key
+	} else {
+		c.logger.Debug("resolving worklog issue", "issue", issueID, "error", err)
+		out.ProjectID = issueID
+	}
+	startTime := w.StartTime
+	if startTime == "" {
+		startTime = "00:00:00"
+	}
+	if start, err := time.ParseInLocation("2006-01-02 15:04:05", w.StartDate+" "+startTime, c.loc); err == nil {
+		out.TimeInterval.Start = start
+		out.TimeInterval.End = start.Add(time.Duration(w.TimeSpentSeconds) * time.Second)
+	}
+	return out
+}
+
+// AddWorklog logs start–end on the Jira issue issueKey and returns the
+// Tempo worklog ID.
+func (c *Client) AddWorklog(ctx context.Context, issueKey string, start, end time.Time, description string) (string, error) {
+	w, err := c.addWorklog(ctx, issueKey, start, end, description)
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(w.TempoWorklogID, 10), nil
+}
+
+func (c *Client) addWorklog(ctx context.Context, issueKey string, start, end time.Time, description string) (*worklog, error) {
+	seconds := int(end.Sub(start).Seconds())
+	if seconds <= 0 {
+		return nil, fmt.Errorf("worklog on %s has no duration", issueKey)
+	}
+	accountID, err := c.account(ctx)
+	if err != nil {
+		return nil, err
+	}
+	issueID, _, err := c.issue(ctx, issueKey)
+	if err != nil {
+		return nil, err
+	}
+	id, err := strconv.ParseInt(issueID, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%q is not a Jira issue ID", issueID)
+	}
+
+	local := start.In(c.loc)
+	body := map[string]any{
+		"authorAccountId":  accountID,
+		"issueId":          id,
+		"startDate":        local.Format("2006-01-02"),
+		"startTime":        local.Format("15:04:05"),
+		"timeSpentSeconds": seconds,
+		"description":      description,
+	}
+	data, err := c.tempoRequest(ctx, http.MethodPost, "/worklogs", body)
+	if err != nil {
+		return nil, fmt.Errorf("creating worklog: %w", err)
+	}
+	var created worklog
+	if err := json.Unmarshal(data, &created); err != nil {
+		return nil, fmt.Errorf("parsing worklog response: %w", err)
+	}
+	return &created, nil
+}
+
+// CreateEntry logs entry as a worklog on the issue whose key is its project ID.
+func (c *Client) CreateEntry(ctx context.Context, entry clockify.TimeEntryRequest) (*clockify.TimeEntry, error) {
+	start, err := time.Parse(time.RFC3339, entry.Start)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start %q: %w", entry.Start, err)
+	}
+	end, err := time.Parse(time.RFC3339, entry.End)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end %q: %w", entry.End, err)
+	}
+	w, err := c.addWorklog(ctx, entry.ProjectID, start, end, entry.Description)
+	if err != nil {
+		return nil, err
+	}
+	out := c.toClockify(ctx, *w)
+	return &out, nil
+}
+
+// ListEntries returns the user's worklogs that start in [start, end).
+func (c *Client) ListEntries(ctx context.Context, start, end time.Time) ([]clockify.TimeEntry, error) {
+	accountID, err := c.account(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var entries []clockify.TimeEntry
+	for offset := 0; ; {
+		q := url.Values{}
+		q.Set("from", start.In(c.loc).Format("2006-01-02"))
+		q.Set("to", end.In(c.loc).Format("2006-01-02"))
+		q.Set("limit", "1000")
+		q.Set("offset", strconv.Itoa(offset))
+		data, err := c.tempoRequest(ctx, http.MethodGet, "/worklogs/user/"+url.PathEscape(accountID)+"?"+q.Encode(), nil)
+		if err != nil {
+			return nil, fmt.Errorf("fetching worklogs: %w", err)
+		}
+		var page struct {
+			Results  []worklog `json:"results"`
+			Metadata struct {
+				Next string `json:"next"`
+			} `json:"metadata"`
+		}
+		if err := json.Unmarshal(data, &page); err != nil {
+			return nil, fmt.Errorf("parsing worklogs: %w", err)
+		}
+		for _, w := range page.Results {
+			e := c.toClockify(ctx, w)
+			if !e.TimeInterval.Start.Before(start) && e.TimeInterval.Start.Before(end) {
+				entries = append(entries, e)
+			}
+		}
+		if page.Metadata.Next == "" || len(page.Results) == 0 {
+			return entries, nil
+		}
+		offset += len(page.Results)
+	}
+}
+
+// DeleteEntry deletes the worklog with the given Tempo ID.
+func (c *Client) DeleteEntry(ctx context.Context, id string) error {
+	if _, err := c.tempoRequest(ctx, http.MethodDelete, "/worklogs/"+url.PathEscape(id), nil); err != nil {
+		return fmt.Errorf("deleting worklog: %w", err)
+	}
+	return nil
+}
+
+// CheckAccess verifies both the Jira and the Tempo tokens. Returns a
+// human-readable problem description, or "" if access looks fine.
+func (c *Client) CheckAccess(ctx context.Context) string {
+	accountID, err := c.account(ctx)
+	if err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden) {
+			return "Jira API token or email is invalid"
+		}
+		return fmt.Sprintf("Jira API unreachable: %v", err)
+	}
+	today := time.Now().In(c.loc).Format("2006-01-02")
+	if _, err := c.tempoRequest(ctx, http.MethodGet, "/worklogs/user/"+url.PathEscape(accountID)+"?from="+today+"&to="+today+"&limit=1", nil); err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden) {
+			return "Tempo API token is invalid or lacks worklog access"
+		}
+		return fmt.Sprintf("Tempo API unreachable: %v", err)
+	}
+	return ""
+}
@@ -0,0 +1,126 @@
+package tempo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/apitest"
+	"github.com/christopherklint97/clockr/internal/clockify"
+)
+
+// fakeTempo serves both the Tempo and the Jira API: issue WEB-1 (ID 10001)
+// and OPS-2 (ID 10002), one worklog, and records created worklogs.
+func fakeTempo(t *testing.T) (*Client, *apitest.Server) {
+	t.Helper()
+	srv := apitest.New(t)
+	jira := apitest.Basic("me@acme.com", "jira-tok")
+	tempo := apitest.Bearer("tempo-tok")
+	srv.Reply("GET /rest/api/3/myself", jira, `{"accountId": "acc-1"}`)
+	srv.Handle("GET /rest/api/3/issue/{key}", jira, func(w http.ResponseWriter, r *http.Request) {
+		switch r.PathValue("key") {
+		case "WEB-1", "10001":
+			w.Write([]byte(`{"id": "10001", "key": "WEB-1"}`))
+		case "OPS-2", "10002":
+			w.Write([]byte(`{"id": "10002", "key": "OPS-2"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	srv.Handle("GET /rest/api/3/search/jql", jira, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("nextPageToken") == "" {
+			w.Write([]byte(`{"issues": [{"id": "10001", "key": "WEB-1", "fields": {"summary": "Header", "project": {"id": "1", "name": "Website"}}}], "nextPageToken": "p2"}`))
+			return
+		}
+		w.Write([]byte(`{"issues": [{"id": "10002", "key": "OPS-2", "fields": {"summary": "Backups", "project": {"id": "2", "name": "Ops"}}}]}`))
+	})
+	srv.Reply("POST /worklogs", tempo, `{"tempoWorklogId": 77, "issue": {"id": 10001}, "timeSpentSeconds": 5400,
+		"startDate": "2026-03-02", "startTime": "09:00:00", "description": "Fixed the header"}`)
+	srv.Reply("GET /worklogs/user/acc-1", tempo, `{"results": [
+		{"tempoWorklogId": 5, "issue": {"id": 10002}, "timeSpentSeconds": 3600, "startDate": "2026-03-02", "startTime": "13:00:00", "description": "Restore test"},
+		{"tempoWorklogId": 6, "issue": {"id": 10002}, "timeSpentSeconds": 3600, "startDate": "2026-03-03", "startTime": "09:00:00"}
+	], "metadata": {"count": 2}}`)
+	c := NewClient("tempo-tok", srv.URL, Jira{URL: srv.URL, Email: "me@acme.com", Token: "jira-tok"}, nil)
+	c.SetLocation(time.UTC)
+	return c, srv
+}
+
+func TestListProjects_IssuesAcrossPages(t *testing.T) {
+	c, _ := fakeTempo(t)
+	projects, err := c.ListProjects(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(projects) != 2 || projects[0].ID != "WEB-1" || projects[0].Name != "WEB-1 Header" || projects[1].ClientName != "Ops" {
+		t.Fatalf("unexpected projects: %+v", projects)
+	}
+}
+
+func TestCreateEntry(t *testing.T) {
+	c, srv := fakeTempo(t)
+	entry, err := c.CreateEntry(context.Background(), clockify.TimeEntryRequest{
+		Start:       "2026-03-02T09:00:00Z",
+		End:         "2026-03-02T10:30:00Z",
+		ProjectID:   "WEB-1",
+		Description: "Fixed the header",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := srv.Written()[0]
+	if body["authorAccountId"] != "acc-1" || body["issueId"] != float64(10001) || body["timeSpentSeconds"] != float64(5400) ||
+		body["startDate"] != "2026-03-02" || body["startTime"] != "09:00:00" {
+		t.Errorf("unexpected request: %v", body)
+	}
+	if entry.ID != "77" || entry.ProjectID != "WEB-1" || entry.TimeInterval.End.Sub(entry.TimeInterval.Start) != 90*time.Minute {
+		t.Errorf("unexpected entry: %+v", entry)
+	}
+}
+
+func TestAddWorklog_UnknownIssue(t *testing.T) {
+	c, srv := fakeTempo(t)
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	if _, err := c.AddWorklog(context.Background(), "NOPE-1", start, start.Add(time.Hour), "x"); err == nil {
+		t.Fatal("expected an unknown issue to fail")
+	}
+	if len(srv.Written()) != 0 {
+		t.Error("nothing should be posted for an unknown issue")
+	}
+}
+
+func TestListEntries_InRange(t *testing.T) {
+	c, _ := fakeTempo(t)
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	entries, err := c.ListEntries(context.Background(), day, day.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].ID != "5" || entries[0].ProjectID != "OPS-2" || entries[0].TimeInterval.Start.Hour() != 13 {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+}
+
+func TestCheckAccess(t *testing.T) {
+	c, _ := fakeTempo(t)
+	if msg := c.CheckAccess(context.Background()); msg != "" {
+		t.Fatalf("unexpected problem: %s", msg)
+	}
+	c.token = "wrong"
+	if msg := c.CheckAccess(context.Background()); msg != "Tempo API token is invalid or lacks worklog access" {
+		t.Errorf("got %q", msg)
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	c, srv := fakeTempo(t)
+	c.SetReadOnly(true)
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	if _, err := c.AddWorklog(context.Background(), "WEB-1", start, start.Add(time.Hour), "x"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("err = %v, want ErrReadOnly", err)
+	}
+	if len(srv.Written()) != 0 {
+		t.Error("read-only mode should not post")
+	}
+}
@@ -0,0 +1,66 @@
+// Package timezone detects the system time zone while clockr runs. Go fixes
+// time.Local at startup, so a long-running scheduler would otherwise keep
+// using the zone it started in after the user travels.
+package timezone
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// localtimePath is the symlink that names the system zone on Linux and macOS.
+var localtimePath = "/etc/localtime"
+
+// Current returns the system time zone as it is now. It honors $TZ, then
+// the /etc/localtime symlink, and falls back to time.Local.
+func Current() *time.Location {
+	if tz, ok := os.LookupEnv("TZ"); ok {
+		tz = strings.TrimPrefix(tz, ":")
+		if tz == "" {
+			return time.UTC
+		}
+		if loc, err := time.LoadLocation(tz); err == nil {
+			return loc
+		}
+	}
+	if runtime.GOOS != "windows" {
+		if name := zoneFromLink(localtimePath); name != "" {
+			if loc, err := time.LoadLocation(name); err == nil {
+				return loc
+			}
+		}
+	}
+	return time.Local
+}
+
+// zoneFromLink extracts the IANA name from a symlink into a zoneinfo
+// directory, e.g. /usr/share/zoneinfo/Europe/Stockholm → Europe/Stockholm.
+func zoneFromLink(path string) string {
+	target, err := os.Readlink(path)
+	if err != nil {
+		return ""
+	}
+	target = filepath.ToSlash(target)
+	if _, name, ok := strings.Cut(target, "zoneinfo/"); ok {
+		return name
+	}
+	return ""
+}
+
+// Name returns the IANA name of loc, resolving time.Local to the system
+// zone. It returns "" when the name cannot be determined.
+func Name(loc *time.Location) string {
+	if loc == nil {
+		return ""
+	}
+	if loc == time.Local || loc.String() == "Local" {
+		loc = Current()
+		if loc == time.Local {
+			return ""
+		}
+	}
+	return loc.String()
+}
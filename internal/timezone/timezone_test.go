@@ -0,0 +1,32 @@
+package timezone
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCurrent_TZ(t *testing.T) {
+	t.Setenv("TZ", "America/New_York")
+	if got := Current().String(); got != "America/New_York" {
+		t.Errorf("Current() = %q, want America/New_York", got)
+	}
+	if got := Name(time.UTC); got != "UTC" {
+		t.Errorf("Name(UTC) = %q", got)
+	}
+}
+
+func TestZoneFromLink(t *testing.T) {
+	dir := t.TempDir()
+	link := filepath.Join(dir, "localtime")
+	if err := os.Symlink("/usr/share/zoneinfo/Asia/Tokyo", link); err != nil {
+		t.Skip("symlinks not supported:", err)
+	}
+	if got := zoneFromLink(link); got != "Asia/Tokyo" {
+		t.Errorf("zoneFromLink = %q, want Asia/Tokyo", got)
+	}
+	if got := zoneFromLink(filepath.Join(dir, "missing")); got != "" {
+		t.Errorf("zoneFromLink(missing) = %q, want empty", got)
+	}
+}
@@ -0,0 +1,345 @@
+// Package toggl logs time to Toggl Track (API v9) for [backend] type =
+// "toggl". It implements backend.Backend, translating projects and time
+// entries to the Clockify types the rest of clockr uses.
+package toggl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/clockify"
+	"github.com/christopherklint97/clockr/internal/httpmetrics"
+	"github.com/christopherklint97/clockr/internal/httpretry"
+)
+
+const defaultBaseURL = "https://api.track.toggl.com/api/v9"
+
+// ErrReadOnly is returned for any non-GET request while the client is read-only.
+var ErrReadOnly = errors.New("read-only mode: Toggl writes are disabled")
+
+// APIError is returned when Toggl responds with a non-2xx status.
+type APIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("Toggl API error (status %d): %s", e.StatusCode, strings.TrimSpace(e.Body))
+}
+
+// Client is a Toggl Track API client for one workspace.
+type Client struct {
+	token      string
+	baseURL    string
+	httpClient *http.Client
+	logger     *slog.Logger
+	readOnly   bool
+
+	mu          sync.Mutex
+	workspaceID string // the user's default workspace when not configured
+}
+
+// NewClient returns a client authenticated with an API token. An empty
+// workspaceID uses the user's default workspace.
+func NewClient(token, workspaceID, baseURL string, logger *slog.Logger) *Client {
+	if logger == nil {
+		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+	return &Client{
+		token:       token,
+		workspaceID: workspaceID,
+		baseURL:     strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: httpmetrics.Wrap("toggl", nil),
+		},
+		logger: logger,
+	}
+}
+
+// SetTimeout limits how long each request may take.
+func (c *Client) SetTimeout(d time.Duration) {
+	c.httpClient.Timeout = d
+}
+
+// SetReadOnly blocks all write requests (anything other than GET) when enabled.
+func (c *Client) SetReadOnly(readOnly bool) {
+	c.readOnly = readOnly
+}
+
+func (c *Client) Name() string { return "Toggl Track" }
+
+func (c *Client) doRequest(ctx context.Context, method, path string, body any) ([]byte, error) {
+	if c.readOnly && method != http.MethodGet {
+		c.logger.Debug("blocked write in read-only mode", "method", method, "path", path)
+		return nil, ErrReadOnly
+	}
+
+	c.logger.Debug("toggl API request", "method", method, "path", path)
+	status, respBody, err := httpretry.JSON(ctx, c.httpClient, httpretry.Default, c.logger, "Toggl", method, c.baseURL+path, body, func(req *http.Request) {
+		req.SetBasicAuth(c.token, "api_token")
+	})
+	if err != nil {
+		return nil, err
+	}
+	if status < 200 || status >= 300 {
+		c.logger.Error("Toggl API request failed", "method", method, "path", path, "status", status)
+		return nil, &APIError{StatusCode: status, Body: string(respBody)}
+	}
+	return respBody, nil
+}
+
+type user struct {
+	ID                 int64 `json:"id"`
+	DefaultWorkspaceID int64 `json:"default_workspace_id"`
+}
+
+func (c *Client) me(ctx context.Context) (*user, error) {
+	data, err := c.doRequest(ctx, http.MethodGet, "/me", nil)
+	if err != nil {
+		return nil, fmt.Errorf("getting user: %w", err)
+	}
+	var u user
+	if err := json.Unmarshal(data, &u); err != nil {
+		return nil, fmt.Errorf("parsing user: %w", err)
+	}
+	return &u, nil
+}
+
+// workspace returns the configured workspace ID, looking up the user's
+// default the first time when none is set.
+func (c *Client) workspace(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	id := c.workspaceID
+	c.mu.Unlock()
+	if id != "" {
+		return id, nil
+	}
+	u, err := c.me(ctx)
+	if err != nil {
+		return "", err
+	}
+	if u.DefaultWorkspaceID == 0 {
+		return "", fmt.Errorf("workspace ID not configured and user has no default workspace — set workspace_id in [toggl] or TOGGL_WORKSPACE_ID")
+	}
+	id = strconv.FormatInt(u.DefaultWorkspaceID, 10)
+	c.mu.Lock()
+	c.workspaceID = id
+	c.mu.Unlock()
+	return id, nil
+}
+
+// WorkspaceID returns the workspace entries are logged to.
+func (c *Client) WorkspaceID(ctx context.Context) (string, error) {
+	return c.workspace(ctx)
+}
+
+// ListProjects returns the workspace's active projects with their client
+// names.
+func (c *Client) ListProjects(ctx context.Context) ([]clockify.Project, error) {
+	wid, err := c.workspace(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	clientNames := make(map[int64]string)
+	if data, err := c.doRequest(ctx, http.MethodGet, "/workspaces/"+wid+"/clients", nil); err != nil {
+		c.logger.Debug("fetching clients", "error", err)
+	} else {
+		var clients []struct {
+			ID   int64  `json:"id"`
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal(data, &clients); err == nil {
+			for _, cl := range clients {
+				clientNames[cl.ID] = cl.Name
+			}
+		}
+	}
+
+	var projects []clockify.Project
+	for page := 1; ; page++ {
+		data, err := c.doRequest(ctx, http.MethodGet, fmt.Sprintf("/workspaces/%s/projects?active=true&per_page=200&page=%d", wid, page), nil)
+		if err != nil {
+			return nil, fmt.Errorf("fetching projects: %w", err)
+		}
+		var batch []struct {
+			ID       int64  `json:"id"`
+			Name     string `json:"name"`
+			ClientID *int64 `json:"client_id"`
+			Active   bool   `json:"active"`
+		}
+		if err := json.Unmarshal(data, &batch); err != nil {
+			return nil, fmt.Errorf("parsing projects: %w", err)
+		}
+		for _, p := range batch {
+			if !p.Active {
+				continue
+			}
+			project := clockify.Project{ID: strconv.FormatInt(p.ID, 10), Name: p.Name}
+			if p.ClientID != nil {
+				project.ClientID = strconv.FormatInt(*p.ClientID, 10)
+				project.ClientName = clientNames[*p.ClientID]
+			}
+			projects = append(projects, project)
+		}
+		if len(batch) < 200 {
+			return projects, nil
+		}
+	}
+}
+
+type timeEntry struct {
+	ID          int64      `json:"id"`
+	WorkspaceID int64      `json:"workspace_id"`
+	ProjectID   *int64     `json:"project_id"`
+	TaskID      *int64     `json:"task_id"`
+	Description string     `json:"description"`
+	Start       time.Time  `json:"start"`
+	Stop        *time.Time `json:"stop"`
+	TagIDs      []int64    `json:"tag_ids"`
+	Billable    bool       `json:"billable"`
+}
+
+// toClockify converts e; a running entry has no end.
+func toClockify(e timeEntry) clockify.TimeEntry {
+	out := clockify.TimeEntry{
+		ID:          strconv.FormatInt(e.ID, 10),
+		Description: e.Description,
+		Billable:    e.Billable,
+	}
+	if e.ProjectID != nil {
+		out.ProjectID = strconv.FormatInt(*e.ProjectID, 10)
+	}
+	if e.TaskID != nil {
+		out.TaskID = strconv.FormatInt(*e.TaskID, 10)
+	}
+	for _, id := range e.TagIDs {
+		out.TagIDs = append(out.TagIDs, strconv.FormatInt(id, 10))
+	}
+	out.TimeInterval.Start = e.Start
+	if e.Stop != nil {
+		out.TimeInterval.End = *e.Stop
+	}
+	return out
+}
+
+// CreateEntry logs entry in the workspace.
+func (c *Client) CreateEntry(ctx context.Context, entry clockify.TimeEntryRequest) (*clockify.TimeEntry, error) {
+	wid, err := c.workspace(ctx)
+	if err != nil {
+		return nil, err
+	}
+	workspaceID, err := strconv.ParseInt(wid, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%q is not a Toggl workspace ID", wid)
+	}
+	projectID, err := strconv.ParseInt(entry.ProjectID, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%q is not a Toggl project ID", entry.ProjectID)
+	}
+	start, err := time.Parse(time.RFC3339, entry.Start)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start %q: %w", entry.Start, err)
+	}
+	end, err := time.Parse(time.RFC3339, entry.End)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end %q: %w", entry.End, err)
+	}
+
+	body := map[string]any{
+		"created_with": "clockr",
+		"workspace_id": workspaceID,
+		"project_id":   projectID,
+		"description":  entry.Description,
+		"start":        entry.Start,
+		"stop":         entry.End,
+		"duration":     int64(end.Sub(start).Seconds()),
+	}
+	data, err := c.doRequest(ctx, http.MethodPost, "/workspaces/"+wid+"/time_entries", body)
+	if err != nil {
+		return nil, fmt.Errorf("creating time entry: %w", err)
+	}
+	var created timeEntry
+	if err := json.Unmarshal(data, &created); err != nil {
+		return nil, fmt.Errorf("parsing time entry response: %w", err)
+	}
+	out := toClockify(created)
+	return &out, nil
+}
+
+// ListEntries returns the user's entries in the workspace that start in
+// [start, end).
+func (c *Client) ListEntries(ctx context.Context, start, end time.Time) ([]clockify.TimeEntry, error) {
+	wid, err := c.workspace(ctx)
+	if err != nil {
+		return nil, err
+	}
+	q := url.Values{}
+	q.Set("start_date", start.UTC().Format(time.RFC3339))
+	q.Set("end_date", end.UTC().Format(time.RFC3339))
+	data, err := c.doRequest(ctx, http.MethodGet, "/me/time_entries?"+q.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("fetching time entries: %w", err)
+	}
+	var raw []timeEntry
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parsing time entries: %w", err)
+	}
+	var entries []clockify.TimeEntry
+	for _, e := range raw {
+		if strconv.FormatInt(e.WorkspaceID, 10) != wid {
+			continue
+		}
+		if !e.Start.Before(start) && e.Start.Before(end) {
+			entries = append(entries, toClockify(e))
+		}
+	}
+	return entries, nil
+}
+
+// DeleteEntry deletes the time entry with the given ID.
+func (c *Client) DeleteEntry(ctx context.Context, id string) error {
+	wid, err := c.workspace(ctx)
+	if err != nil {
+		return err
+	}
+	if _, err := c.doRequest(ctx, http.MethodDelete, "/workspaces/"+wid+"/time_entries/"+url.PathEscape(id), nil); err != nil {
+		return fmt.Errorf("deleting time entry: %w", err)
+	}
+	return nil
+}
+
+// CheckAccess verifies the API token authenticates and can read the
+// workspace. Returns a human-readable problem description, or "" if access
+// looks fine.
+func (c *Client) CheckAccess(ctx context.Context) string {
+	if _, err := c.me(ctx); err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden) {
+			return "Toggl API token is invalid or revoked"
+		}
+		return fmt.Sprintf("Toggl API unreachable: %v", err)
+	}
+	wid, err := c.workspace(ctx)
+	if err != nil {
+		return err.Error()
+	}
+	if _, err := c.doRequest(ctx, http.MethodGet, "/workspaces/"+wid, nil); err != nil {
+		return fmt.Sprintf("Toggl workspace %s is not accessible: %v", wid, err)
+	}
+	return ""
+}
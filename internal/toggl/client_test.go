@@ -0,0 +1,86 @@
+package toggl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/apitest"
+	"github.com/christopherklint97/clockr/internal/clockify"
+)
+
+// fakeToggl serves workspace 11 with two projects, one of them archived,
+// and records created entries.
+func fakeToggl(t *testing.T) (*Client, *apitest.Server) {
+	t.Helper()
+	srv := apitest.New(t)
+	auth := apitest.Basic("tok", "api_token")
+	srv.Reply("GET /me", auth, `{"id": 5, "default_workspace_id": 11}`)
+	srv.Reply("GET /workspaces/11/clients", auth, `[{"id": 3, "name": "Acme"}]`)
+	srv.Reply("GET /workspaces/11/projects", auth, `[
+		{"id": 7, "name": "Website", "client_id": 3, "active": true},
+		{"id": 8, "name": "Old site", "client_id": null, "active": false}
+	]`)
+	srv.Reply("POST /workspaces/11/time_entries", auth, `{"id": 9001, "workspace_id": 11, "project_id": 7, "description": "Fixed the header",
+		"start": "2026-03-02T09:00:00Z", "stop": "2026-03-02T10:30:00Z", "tag_ids": [4], "billable": true}`)
+	srv.Reply("GET /me/time_entries", auth, `[
+		{"id": 1, "workspace_id": 11, "project_id": 7, "start": "2026-03-02T09:00:00Z", "stop": "2026-03-02T10:00:00Z"},
+		{"id": 2, "workspace_id": 12, "project_id": 9, "start": "2026-03-02T11:00:00Z", "stop": "2026-03-02T12:00:00Z"},
+		{"id": 3, "workspace_id": 11, "project_id": null, "start": "2026-03-02T13:00:00Z", "stop": null}
+	]`)
+	return NewClient("tok", "", srv.URL, nil), srv
+}
+
+func TestListProjects_ActiveWithClientNames(t *testing.T) {
+	c, _ := fakeToggl(t)
+	projects, err := c.ListProjects(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(projects) != 1 || projects[0].ID != "7" || projects[0].ClientName != "Acme" {
+		t.Fatalf("unexpected projects: %+v", projects)
+	}
+}
+
+func TestCreateEntry(t *testing.T) {
+	c, srv := fakeToggl(t)
+	entry, err := c.CreateEntry(context.Background(), clockify.TimeEntryRequest{
+		Start:       "2026-03-02T09:00:00Z",
+		End:         "2026-03-02T10:30:00Z",
+		ProjectID:   "7",
+		Description: "Fixed the header",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := srv.Written()[0]
+	if body["workspace_id"] != float64(11) || body["duration"] != float64(5400) || body["created_with"] != "clockr" {
+		t.Errorf("unexpected request: %v", body)
+	}
+	if entry.ID != "9001" || len(entry.TagIDs) != 1 || entry.TagIDs[0] != "4" || !entry.Billable {
+		t.Errorf("unexpected entry: %+v", entry)
+	}
+}
+
+func TestListEntries_OnlyTheWorkspace(t *testing.T) {
+	c, _ := fakeToggl(t)
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	entries, err := c.ListEntries(context.Background(), day, day.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].ID != "1" || entries[1].ID != "3" {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+	if !entries[1].TimeInterval.End.IsZero() {
+		t.Error("a running entry should have no end")
+	}
+}
+
+func TestCheckAccess(t *testing.T) {
+	c, _ := fakeToggl(t)
+	c.token = "wrong"
+	if msg := c.CheckAccess(context.Background()); msg != "Toggl API token is invalid or revoked" {
+		t.Errorf("got %q", msg)
+	}
+}
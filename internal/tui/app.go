@@ -2,16 +2,25 @@ package tui
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"slices"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/christopherklint97/clockr/internal/ai"
+	"github.com/christopherklint97/clockr/internal/audit"
+	"github.com/christopherklint97/clockr/internal/backend"
+	"github.com/christopherklint97/clockr/internal/calendar"
 	"github.com/christopherklint97/clockr/internal/clockify"
+	"github.com/christopherklint97/clockr/internal/config"
+	"github.com/christopherklint97/clockr/internal/format"
+	"github.com/christopherklint97/clockr/internal/quality"
 	"github.com/christopherklint97/clockr/internal/store"
 )
 
@@ -24,11 +33,18 @@ const (
 	suggestionView
 	editView
 	confirmationView
+	skipReasonView
+	clarifyView
+	manualView
 )
 
 type Result struct {
-	Skipped bool
-	Entries []store.Entry
+	Skipped      bool
+	SkipReason   string
+	Interrupted  bool // true when the user cancelled with Ctrl+C instead of answering
+	AutoAccepted bool // true when the suggestion was accepted by the countdown
+	Entries      []store.Entry
+	Skip         *store.Skip // the recorded skip when Skipped, if it was stored
 }
 
 type aiResponseMsg struct {
@@ -38,6 +54,7 @@ type aiResponseMsg struct {
 
 type submitMsg struct {
 	entries []store.Entry
+	errs    []string // why each entry failed, "" when it was logged
 	err     error
 }
 
@@ -61,16 +78,50 @@ type App struct {
 	edit        editModel
 	result      *Result
 	errMsg      string
+	submitErrs  []string      // per entry in result.Entries; "" when it was logged
+	retryOf     []store.Entry // failed entries being fixed; replaced by the next submit
+	copied      string        // status of Ctrl+Y on the confirmation
+	draftSaved  string        // description last saved as the draft
 
 	startTime    time.Time
 	endTime      time.Time
 	provider     ai.Provider
 	projects     []clockify.Project
-	clockify     *clockify.Client
-	workspaceID  string
+	backend      backend.Backend
 	db           *store.DB
 	interval     time.Duration
-	contextItems []string
+	contextItems []ai.ContextItem
+	overtime     bool
+	overtimeTag  string // Clockify tag ID added to overtime entries
+	source       string // recorded on the entries, store.SourceManual by default
+	skipReasons  []string
+	skipReason   skipReasonModel
+	meetings     []calendar.Event // the window is split at their boundaries
+	logged       []audit.Interval // time already logged in the window; entries step over it
+	previous     []ai.Allocation  // last suggestion before a retry
+	aiOriginal   []ai.Allocation  // the AI's suggestion as first shown, for the edit score
+	formatter    *format.Formatter
+	rounding     clockify.Rounding
+
+	clarify        clarifyModel
+	clarifications []ai.Exchange // answers to the AI's questions about the current description
+	allProjects    bool          // send the AI every project, not the trimmed list
+	retried        bool          // the user asked again, so cached AI answers are not reused
+
+	regenerating bool // the running AI call replaces only row regenRow
+	regenRow     int
+
+	manual     manualModel
+	manualOnly bool // --manual: no AI provider, the entry is typed in by hand
+	autoStart  bool // --stdin: the description is given, so Init goes straight to the AI
+
+	splitMidnight bool          // log an entry spanning midnight as one per day
+	aiTimeout     time.Duration // how long a streaming AI call may stay silent
+
+	autoAcceptSeconds    int
+	autoAcceptConfidence float64
+	autoAcceptGen        int
+	autoAccepted         bool
 
 	thinkCh          <-chan string
 	thinkingText     string
@@ -80,17 +131,19 @@ type App struct {
 	termHeight       int
 
 	readyCh chan struct{} // signals PromptFileProvider that user pressed Enter
+
+	startup      func() Startup              // background fetch still to run or running; nil once done
+	afterStartup func() (tea.Model, tea.Cmd) // what to do once it is done
 }
 
 func NewApp(
 	startTime, endTime time.Time,
 	provider ai.Provider,
 	projects []clockify.Project,
-	client *clockify.Client,
-	workspaceID string,
+	b backend.Backend,
 	db *store.DB,
 	interval time.Duration,
-	contextItems []string,
+	contextItems []ai.ContextItem,
 	lastInput string,
 ) *App {
 	s := spinner.New()
@@ -103,22 +156,26 @@ func NewApp(
 	)
 
 	input := newInputModel(timeInfo)
-	input.lastInput = lastInput
+	input.history = loadHistory(db, lastInput)
+	input.snippets = loadSnippets(db)
+	input.context = input.context.withItems(contextItems)
+	input.draft = loadDraft(db)
 
 	return &App{
-		state:       durationView,
-		duration:    newDurationModel(int(interval.Minutes())),
-		input:       input,
-		spinner:     s,
-		startTime:   startTime,
-		endTime:     endTime,
-		provider:    provider,
-		projects:    projects,
-		clockify:    client,
-		workspaceID: workspaceID,
-		db:          db,
-		interval:    interval,
+		state:        durationView,
+		duration:     newDurationModel(int(interval.Minutes())),
+		input:        input,
+		spinner:      s,
+		startTime:    startTime,
+		endTime:      endTime,
+		provider:     provider,
+		projects:     projects,
+		backend:      b,
+		db:           db,
+		interval:     interval,
+		aiTimeout:    config.DefaultAITimeout,
 		contextItems: contextItems,
+		source:       store.SourceManual,
 	}
 }
 
@@ -126,8 +183,104 @@ func (a *App) SetInitialInput(text string) {
 	a.input.textarea.SetValue(text)
 }
 
+// Resume opens straight on a saved suggestion for the window, with the
+// description it was made from.
+func (a *App) Resume(description string, suggestion *ai.Suggestion) {
+	a.input.textarea.SetValue(description)
+	a.aiOriginal = slices.Clone(suggestion.Allocations)
+	a.suggestions = newSuggestionsModel(suggestion, a.projects)
+	a.suggestions.formatter = a.formatter
+	a.state = suggestionView
+}
+
+// SetDescription uses description for the window as given, skipping the
+// duration and description prompts and asking the AI straight away.
+func (a *App) SetDescription(description string) {
+	a.input.textarea.SetValue(description)
+	a.state = inputView
+	a.autoStart = true
+}
+
+// SetManual opens the manual entry form instead of the description box once
+// the duration is confirmed. The AI is not used, so provider may be nil.
+func (a *App) SetManual(manual bool) {
+	a.manualOnly = manual
+}
+
+// SetAITimeout sets how long a streaming AI call may go without output
+// before it is cancelled.
+func (a *App) SetAITimeout(d time.Duration) {
+	a.aiTimeout = d
+}
+
+// SetSplitAtMidnight logs entries that span midnight as one entry per day.
+func (a *App) SetSplitAtMidnight(split bool) {
+	a.splitMidnight = split
+}
+
+// SetOvertime tags every entry logged by this session as overtime, and
+// with the Clockify tag tagID unless it is empty.
+func (a *App) SetOvertime(overtime bool, tagID string) {
+	a.overtime = overtime
+	a.overtimeTag = tagID
+}
+
+// SetSource sets the store.Source recorded on the entries, e.g.
+// store.SourceScheduler for scheduler prompts.
+func (a *App) SetSource(source string) {
+	a.source = source
+}
+
+// SetSkipReasons sets the reasons offered when the prompt is skipped. With
+// none, skipping records no reason.
+func (a *App) SetSkipReasons(reasons []string) {
+	a.skipReasons = reasons
+}
+
+// SetMeetings makes suggestions split the window at the start and end of
+// these calendar events.
+func (a *App) SetMeetings(events []calendar.Event) {
+	a.meetings = events
+}
+
+// SetLogged keeps entries off time that is already logged in the window.
+// The interval given to NewApp should already leave it out.
+func (a *App) SetLogged(logged []audit.Interval) {
+	a.logged = logged
+}
+
+// SetAutoAccept makes confident suggestions accept themselves after seconds
+// unless a key is pressed. Every allocation must reach minConfidence.
+// Zero seconds disables it; read-only sessions never auto-accept.
+func (a *App) SetAutoAccept(seconds int, minConfidence float64) {
+	a.autoAcceptSeconds = seconds
+	a.autoAcceptConfidence = minConfidence
+}
+
+// SetFormatter applies f to descriptions after AI generation and edits.
+func (a *App) SetFormatter(f *format.Formatter) {
+	a.formatter = f
+}
+
+// SetRounding rounds allocation minutes by r after AI generation and edits.
+func (a *App) SetRounding(r clockify.Rounding) {
+	a.rounding = r
+}
+
 func (a *App) Init() tea.Cmd {
-	return tea.Batch(a.duration.textinput.Focus(), a.spinner.Tick)
+	if a.autoStart {
+		rememberInput(a.db, a.input.Value())
+		return a.withStartup(a.startLoading())
+	}
+	return a.withStartup(tea.Batch(a.duration.textinput.Focus(), a.spinner.Tick, draftTick()))
+}
+
+// withStartup adds the background startup fetch, if any, to cmd.
+func (a *App) withStartup(cmd tea.Cmd) tea.Cmd {
+	if a.startup == nil {
+		return cmd
+	}
+	return tea.Batch(cmd, a.startupCmd())
 }
 
 func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -147,13 +300,30 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		if msg.String() == "ctrl+c" {
-			a.result = &Result{Skipped: true}
+			a.result = &Result{Skipped: true, Interrupted: true}
 			return a, tea.Quit
 		}
+	case startupMsg:
+		return a.handleStartup(msg)
 	case aiResponseMsg:
 		return a.handleAIResponse(msg)
 	case submitMsg:
 		return a.handleSubmit(msg)
+	case clipboardMsg:
+		if a.state == confirmationView {
+			a.copied = clipboardStatus(msg)
+		} else {
+			a.suggestions.status = clipboardStatus(msg)
+		}
+		return a, nil
+	case autoAcceptMsg:
+		return a.handleAutoAccept(msg)
+	case draftTickMsg:
+		if a.result != nil {
+			return a, nil
+		}
+		a.saveDraft()
+		return a, draftTick()
 	case thinkingMsg:
 		a.thinkingText += msg.text
 		a.viewport.SetContent(a.thinkingText)
@@ -181,6 +351,12 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return a.updateEdit(msg)
 	case confirmationView:
 		return a.updateConfirmation(msg)
+	case skipReasonView:
+		return a.updateSkipReason(msg)
+	case clarifyView:
+		return a.updateClarify(msg)
+	case manualView:
+		return a.updateManual(msg)
 	}
 
 	return a, nil
@@ -195,7 +371,9 @@ func (a *App) View() string {
 	case loadingView:
 		elapsed := time.Since(a.loadingStartTime).Truncate(time.Second)
 		label := "Thinking..."
-		if _, ok := a.provider.(*ai.PromptFileProvider); ok {
+		if a.fetching() {
+			label = "Fetching projects and context..."
+		} else if _, ok := ai.Unwrap(a.provider).(*ai.PromptFileProvider); ok {
 			label = "Waiting for response..."
 		}
 		header := fmt.Sprintf("%s %s  %s", a.spinner.View(), label, dimStyle.Render(formatElapsed(elapsed)))
@@ -209,7 +387,16 @@ func (a *App) View() string {
 		if a.errMsg != "" {
 			return errorStyle.Render("Error: ") + a.errMsg + "\n\n" + helpStyle.Render("Press any key to exit")
 		}
-		return successStyle.Render("Entries logged successfully!") + "\n\n" + helpStyle.Render("Press any key to exit")
+		if a.readOnly() {
+			return warningStyle.Render("Read-only mode — suggestions previewed, nothing was logged.") + "\n\n" + helpStyle.Render("Press any key to exit")
+		}
+		return a.confirmationView()
+	case skipReasonView:
+		return a.skipReason.View()
+	case clarifyView:
+		return a.clarify.View()
+	case manualView:
+		return a.manual.View()
 	}
 	return ""
 }
@@ -218,24 +405,42 @@ func (a *App) GetResult() *Result {
 	return a.result
 }
 
+// readOnly reports whether the database is in read-only mode, in which case
+// accepting a suggestion only previews it.
+func (a *App) readOnly() bool {
+	return a.db != nil && a.db.ReadOnly()
+}
+
 func (a *App) updateDuration(msg tea.Msg) (tea.Model, tea.Cmd) {
 	if keyMsg, ok := msg.(tea.KeyMsg); ok {
 		if keyMsg.String() == "enter" {
 			minutes := a.duration.Value()
 			a.interval = time.Duration(minutes) * time.Minute
-			a.endTime = time.Now()
+			a.endTime = time.Now().In(a.startTime.Location())
 			a.startTime = a.endTime.Add(-a.interval)
+			if len(a.logged) > 0 {
+				a.startTime = audit.StartBefore(a.endTime, a.interval, a.logged)
+			}
 
 			timeInfo := fmt.Sprintf("%s – %s (%d min)",
 				a.startTime.Format("15:04"),
 				a.endTime.Format("15:04"),
 				minutes,
 			)
+			if logged := a.endTime.Sub(a.startTime) - a.interval; logged > 0 {
+				timeInfo += fmt.Sprintf(" — %d min already logged", int(logged.Minutes()))
+			}
+			if a.overtime {
+				timeInfo += " — overtime"
+			}
 
 			newInput := newInputModel(timeInfo)
-			newInput.lastInput = a.input.lastInput
+			newInput = newInput.withSaved(a.input)
 			newInput, _ = newInput.Update(tea.WindowSizeMsg{Width: a.termWidth, Height: a.termHeight})
 			a.input = newInput
+			if a.manualOnly {
+				return a.startManual()
+			}
 			a.state = inputView
 			return a, a.input.textarea.Focus()
 		}
@@ -247,24 +452,20 @@ func (a *App) updateDuration(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (a *App) updateInput(msg tea.Msg) (tea.Model, tea.Cmd) {
-	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && !a.input.Picking() {
+		if keyMsg.String() == "ctrl+s" {
+			return a.skip()
+		}
+		if keyMsg.String() == "ctrl+o" {
+			return a.startManual()
+		}
 		if keyMsg.String() == "enter" && a.input.Value() != "" {
 			// Save description immediately so it survives AI failures
-			if a.db != nil {
-				a.db.SetState("last_description", a.input.Value())
-			}
-			a.state = loadingView
-			a.thinkingText = ""
-			a.loadingStartTime = time.Now()
-			a.viewport = viewport.New(a.termWidth, max(a.termHeight-3, 1))
-			ch := make(chan string, 100)
-			a.thinkCh = ch
-			return a, tea.Batch(
-				a.spinner.Tick,
-				a.startAI(a.input.Value(), ch),
-				readThinking(ch),
-				tickCmd(),
-			)
+			rememberInput(a.db, a.input.Value())
+			a.saveDraft()
+			a.clarifications = nil
+			a.allProjects = false
+			return a, a.startLoading()
 		}
 	}
 
@@ -273,6 +474,130 @@ func (a *App) updateInput(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return a, cmd
 }
 
+// startLoading switches to the loading view and asks the AI about the
+// description together with any clarification answers.
+func (a *App) startLoading() tea.Cmd {
+	if a.fetching() {
+		_, cmd := a.waitForStartup(func() (tea.Model, tea.Cmd) { return a, a.startLoading() })
+		return cmd
+	}
+	a.regenerating = false
+	segments := a.segments()
+	return a.load(ai.WithClarifications(a.input.Value(), a.clarifications), a.interval, segments)
+}
+
+// regenerate asks the AI for a new version of the highlighted allocation,
+// keeping the other rows fixed.
+func (a *App) regenerate() tea.Cmd {
+	allocs := a.suggestions.suggestion.Allocations
+	row := a.suggestions.cursor
+	// Segments only line up with rows the model returned one per segment.
+	var segments []ai.Segment
+	if all := a.segments(); len(all) == len(allocs) {
+		segments = all[row : row+1]
+	}
+	a.regenerating, a.regenRow = true, row
+	description := ai.RegenerateDescription(ai.WithClarifications(a.input.Value(), a.clarifications), allocs, row)
+	return a.load(description, time.Duration(allocs[row].Minutes)*time.Minute, segments)
+}
+
+// segments splits the window at meetings. Windows with time already logged
+// are not split, as the segments would not add up to the interval.
+func (a *App) segments() []ai.Segment {
+	if len(a.logged) > 0 {
+		return nil
+	}
+	var meetings []ai.Segment
+	for _, e := range a.meetings {
+		if !a.input.context.excludes(e) {
+			meetings = append(meetings, ai.Segment{Start: e.StartTime, End: e.EndTime, Meeting: e.Summary})
+		}
+	}
+	return ai.SplitAtMeetings(a.startTime, a.endTime, meetings)
+}
+
+// sentContext is the context the user left in for the AI.
+func (a *App) sentContext() []ai.ContextItem {
+	return a.input.context.Included()
+}
+
+// load switches to the loading view and runs the AI on description.
+func (a *App) load(description string, interval time.Duration, segments []ai.Segment) tea.Cmd {
+	a.state = loadingView
+	a.thinkingText = ""
+	a.loadingStartTime = time.Now()
+	a.viewport = viewport.New(a.termWidth, max(a.termHeight-3, 1))
+	ch := make(chan string, 100)
+	a.thinkCh = ch
+	return tea.Batch(
+		a.spinner.Tick,
+		a.startAI(description, interval, segments, ch),
+		readThinking(ch),
+		tickCmd(),
+	)
+}
+
+// startManual opens the manual entry form, skipping the AI.
+func (a *App) startManual() (tea.Model, tea.Cmd) {
+	if a.fetching() {
+		return a.waitForStartup(a.startManual)
+	}
+	a.manual = newManualModel(a.projects, int(a.interval.Minutes()))
+	a.state = manualView
+	return a, textinput.Blink
+}
+
+// updateManual runs the manual entry form and submits its allocation.
+// Esc on the first step returns to the description box unless there is no AI.
+func (a *App) updateManual(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case keyMsg.String() == "ctrl+s":
+			return a.skip()
+		case keyMsg.String() == "esc" && a.manual.step == manualProject && !a.manualOnly:
+			a.state = inputView
+			return a, a.input.textarea.Focus()
+		}
+	}
+	var alloc ai.Allocation
+	var done bool
+	var cmd tea.Cmd
+	a.manual, alloc, done, cmd = a.manual.Update(msg)
+	if !done {
+		return a, cmd
+	}
+	allocs := []ai.Allocation{alloc}
+	formatAllocations(a.formatter, allocs)
+	roundAllocations(a.rounding, allocs)
+	if a.readOnly() {
+		a.result = &Result{Skipped: true}
+		a.state = confirmationView
+		return a, nil
+	}
+	return a, a.submitAllocations(allocs)
+}
+
+// updateClarify collects the answer to a clarification question and asks
+// the AI again with the whole exchange.
+func (a *App) updateClarify(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && keyMsg.String() == "esc" {
+		a.state = suggestionView
+		return a, nil
+	}
+	var exchange ai.Exchange
+	var done bool
+	var cmd tea.Cmd
+	a.clarify, exchange, done, cmd = a.clarify.Update(msg)
+	if done {
+		if exchange.Answer != "" {
+			a.clarifications = append(a.clarifications, exchange)
+		}
+		a.allProjects = a.allProjects || a.clarify.allProjects
+		return a, a.startLoading()
+	}
+	return a, cmd
+}
+
 func (a *App) updateLoading(msg tea.Msg) (tea.Model, tea.Cmd) {
 	if keyMsg, ok := msg.(tea.KeyMsg); ok {
 		if keyMsg.String() == "enter" && a.readyCh != nil {
@@ -296,22 +621,48 @@ func (a *App) updateLoading(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 func (a *App) updateSuggestion(msg tea.Msg) (tea.Model, tea.Cmd) {
 	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		// Any key means the user is reviewing; stop the auto-accept countdown.
+		a.suggestions.countdown = 0
 		switch keyMsg.String() {
 		case "a":
+			if a.readOnly() {
+				a.result = &Result{Skipped: true}
+				a.state = confirmationView
+				return a, nil
+			}
 			return a, a.submitAllocations(a.suggestions.suggestion.Allocations)
 		case "e":
 			a.state = editView
 			a.edit = newEditModel(a.suggestions.suggestion.Allocations, a.projects)
 			return a, nil
+		case "c":
+			if q := a.suggestions.suggestion.Clarification; q != "" {
+				a.clarify = newClarifyModel(q, a.clarifications, a.suggestions.suggestion.Hidden)
+				a.state = clarifyView
+				return a, textinput.Blink
+			}
 		case "r":
+			if s := a.suggestions.suggestion; s.Clarification == "" {
+				a.previous = slices.Clone(s.Allocations)
+			}
+			a.retried = true
 			a.state = inputView
 			newInput := newInputModel(a.input.timeInfo)
+			newInput = newInput.withSaved(a.input)
 			newInput, _ = newInput.Update(tea.WindowSizeMsg{Width: a.input.width, Height: a.input.height})
 			a.input = newInput
 			return a, a.input.textarea.Focus()
+		case "g":
+			if s := a.suggestions.suggestion; s.Clarification == "" && a.suggestions.cursor < len(s.Allocations) {
+				a.suggestions.status = ""
+				return a, a.regenerate()
+			}
+		case "y":
+			if allocs := a.suggestions.suggestion.Allocations; a.suggestions.cursor < len(allocs) {
+				return a, copyCmd("Description", allocs[a.suggestions.cursor].Description)
+			}
 		case "s":
-			a.result = &Result{Skipped: true}
-			return a, tea.Quit
+			return a.skip()
 		case "up", "k":
 			if a.suggestions.cursor > 0 {
 				a.suggestions.cursor--
@@ -328,8 +679,16 @@ func (a *App) updateSuggestion(msg tea.Msg) (tea.Model, tea.Cmd) {
 func (a *App) updateEdit(msg tea.Msg) (tea.Model, tea.Cmd) {
 	if keyMsg, ok := msg.(tea.KeyMsg); ok {
 		if keyMsg.String() == "esc" && !a.edit.editing {
+			if a.edit.incomplete() {
+				a.edit.errMsg = "Pick a project for every row, or delete the empty one"
+				return a, nil
+			}
 			a.suggestions.suggestion.Allocations = a.edit.allocations
+			a.suggestions.suggestion.Problems = nil // the edit is the user's call
+			formatAllocations(a.formatter, a.suggestions.suggestion.Allocations)
+			roundAllocations(a.rounding, a.suggestions.suggestion.Allocations)
 			a.state = suggestionView
+			a.saveSuggestion()
 			return a, nil
 		}
 	}
@@ -339,26 +698,238 @@ func (a *App) updateEdit(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return a, cmd
 }
 
+// skip asks for a skip reason, or finishes straight away if none are configured.
+func (a *App) skip() (tea.Model, tea.Cmd) {
+	if len(a.skipReasons) == 0 {
+		return a.finishSkip("")
+	}
+	a.skipReason = newSkipReasonModel(a.skipReasons)
+	a.state = skipReasonView
+	return a, nil
+}
+
+func (a *App) updateSkipReason(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var reason string
+	var done bool
+	a.skipReason, reason, done = a.skipReason.Update(msg)
+	if done {
+		return a.finishSkip(reason)
+	}
+	return a, nil
+}
+
+// finishSkip records the window as deliberately untracked and exits.
+func (a *App) finishSkip(reason string) (tea.Model, tea.Cmd) {
+	a.result = &Result{Skipped: true, SkipReason: reason}
+	if a.db != nil && !a.readOnly() {
+		a.db.DeleteSuggestion(a.startTime, a.endTime)
+		a.clearDraft()
+		skip := &store.Skip{
+			StartTime: a.startTime,
+			EndTime:   a.endTime,
+			Minutes:   int(a.endTime.Sub(a.startTime).Minutes()),
+			Reason:    reason,
+		}
+		if _, err := a.db.InsertSkip(skip); err == nil {
+			a.result.Skip = skip
+		}
+	}
+	return a, tea.Quit
+}
+
 func (a *App) updateConfirmation(msg tea.Msg) (tea.Model, tea.Cmd) {
-	if _, ok := msg.(tea.KeyMsg); ok {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		if keyMsg.String() == "e" && a.failedCount() > 0 {
+			return a.fixFailed()
+		}
+		if keyMsg.String() == "ctrl+y" && a.canCopySummary() {
+			return a, copyCmd("Summary", entriesSummary(a.result.Entries, a.submitErrs))
+		}
 		return a, tea.Quit
 	}
 	return a, nil
 }
 
+// canCopySummary reports whether the confirmation lists logged entries that
+// Ctrl+Y can copy.
+func (a *App) canCopySummary() bool {
+	return a.errMsg == "" && !a.readOnly() && a.result != nil && a.failedCount() < len(a.result.Entries)
+}
+
+// failedCount returns how many submitted entries Clockify rejected.
+func (a *App) failedCount() int {
+	n := 0
+	for _, e := range a.submitErrs {
+		if e != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// fixFailed opens the failed entries in the editor. Accepting them submits
+// only those again, replacing the failed rows.
+func (a *App) fixFailed() (tea.Model, tea.Cmd) {
+	a.retryOf = nil
+	var allocs []ai.Allocation
+	for i, e := range a.result.Entries {
+		if a.submitErrs[i] == "" {
+			continue
+		}
+		a.retryOf = append(a.retryOf, e)
+		allocs = append(allocs, ai.Allocation{
+			ProjectID:   e.ProjectID,
+			ProjectName: e.ProjectName,
+			ClientName:  e.ClientName,
+			Description: e.Description,
+			Minutes:     e.Minutes,
+			IssueKey:    e.IssueKey,
+			Confidence:  1,
+		})
+	}
+	a.suggestions = newSuggestionsModel(&ai.Suggestion{Allocations: allocs}, a.projects)
+	a.suggestions.termWidth = a.termWidth
+	a.suggestions.formatter = a.formatter
+	a.suggestions.status = dimStyle.Render(fmt.Sprintf("Fixing %d failed entries — [a]ccept submits them again", len(allocs)))
+	a.edit = newEditModel(allocs, a.projects)
+	a.state = editView
+	return a, nil
+}
+
+// confirmationView lists the submitted entries with their status and link,
+// and Clockify's reason next to each one it rejected.
+func (a *App) confirmationView() string {
+	failed := a.failedCount()
+	entries := a.result.Entries
+	var sb strings.Builder
+	if failed == 0 {
+		sb.WriteString(successStyle.Render("Entries logged successfully!"))
+	} else {
+		sb.WriteString(warningStyle.Render(fmt.Sprintf("Logged %d of %d entries — %d failed.", len(entries)-failed, len(entries), failed)))
+	}
+	sb.WriteString("\n\n")
+	width := max(a.termWidth-8, 40)
+	multiDay := spansDays(entries)
+	for i, e := range entries {
+		line := receiptLine(e, multiDay)
+		if a.submitErrs[i] == "" {
+			sb.WriteString("  " + successStyle.Render("✓") + " " + line + "\n")
+			if link := backend.EntryURL(a.backend, e.ClockifyID); link != "" {
+				sb.WriteString("    " + dimStyle.Render(link) + "\n")
+			}
+			continue
+		}
+		sb.WriteString("  " + errorStyle.Render("✗") + " " + line + "\n")
+		sb.WriteString("    " + errorStyle.Render(truncate(a.submitErrs[i], width)) + "\n")
+	}
+	sb.WriteString("\n")
+	if failed > 0 {
+		sb.WriteString(dimStyle.Render("Failed entries are kept and retried by 'clockr retry' and the scheduler."))
+		sb.WriteString("\n")
+	}
+	if a.copied != "" {
+		sb.WriteString(a.copied + "\n")
+	}
+	if failed == 0 {
+		sb.WriteString(helpStyle.Render("Ctrl+Y: copy a summary • any other key: exit"))
+	} else {
+		sb.WriteString(helpStyle.Render("e: fix and retry the failed entries • Ctrl+Y: copy a summary of the logged ones • any other key: exit"))
+	}
+	return sb.String()
+}
+
 func (a *App) handleAIResponse(msg aiResponseMsg) (tea.Model, tea.Cmd) {
+	if a.regenerating {
+		return a.handleRegenerated(msg)
+	}
 	if msg.err != nil {
 		a.state = confirmationView
 		a.errMsg = msg.err.Error()
 		return a, nil
 	}
 
-	a.suggestions = newSuggestionsModel(msg.suggestion)
+	formatAllocations(a.formatter, msg.suggestion.Allocations)
+	roundAllocations(a.rounding, msg.suggestion.Allocations)
+	a.aiOriginal = slices.Clone(msg.suggestion.Allocations)
+	a.suggestions = newSuggestionsModel(msg.suggestion, a.projects)
 	a.suggestions.termWidth = a.termWidth
+	a.suggestions.rounding = a.rounding
+	a.suggestions.formatter = a.formatter
+	a.suggestions.previous = a.previous
+	a.state = suggestionView
+	a.saveSuggestion()
+
+	if a.autoAcceptSeconds > 0 && !a.readOnly() && confidentEnough(msg.suggestion, a.autoAcceptConfidence) {
+		a.autoAcceptGen++
+		a.suggestions.countdown = a.autoAcceptSeconds
+		return a, autoAcceptTick(a.autoAcceptGen)
+	}
+	return a, nil
+}
+
+// saveSuggestion keeps the suggestion on screen so 'clockr log --resume' can
+// reopen it after an accidental exit.
+func (a *App) saveSuggestion() {
+	if a.db == nil {
+		return
+	}
+	data, err := json.Marshal(a.suggestions.suggestion)
+	if err != nil {
+		return
+	}
+	contextData, err := json.Marshal(a.sentContext())
+	if err != nil {
+		return
+	}
+	a.db.SaveSuggestion(&store.SavedSuggestion{
+		StartTime:   a.startTime,
+		EndTime:     a.endTime,
+		Description: a.input.Value(),
+		Suggestion:  string(data),
+		Context:     string(contextData),
+	})
+}
+
+// handleRegenerated swaps the regenerated row into the suggestion, scaled to
+// the row's minutes so the total stays the same. On failure the old row is
+// kept and the reason shown.
+func (a *App) handleRegenerated(msg aiResponseMsg) (tea.Model, tea.Cmd) {
+	a.regenerating = false
 	a.state = suggestionView
+	s := a.suggestions.suggestion
+	switch {
+	case msg.err != nil:
+		a.suggestions.status = errorStyle.Render("Regenerating failed: " + msg.err.Error())
+	case msg.suggestion.Clarification != "":
+		a.suggestions.status = warningStyle.Render("The AI needs more detail: " + msg.suggestion.Clarification)
+	case len(msg.suggestion.Allocations) == 0:
+		a.suggestions.status = warningStyle.Render("The AI returned no allocation — row kept")
+	default:
+		replacement := msg.suggestion.Allocations
+		ai.FitMinutes(replacement, s.Allocations[a.regenRow].Minutes)
+		formatAllocations(a.formatter, replacement)
+		roundAllocations(a.rounding, replacement)
+		a.suggestions.previous = slices.Clone(s.Allocations)
+		s.Allocations = slices.Replace(s.Allocations, a.regenRow, a.regenRow+1, replacement...)
+		a.suggestions.status = successStyle.Render(fmt.Sprintf("Regenerated row %d", a.regenRow+1))
+		a.saveSuggestion()
+	}
 	return a, nil
 }
 
+// handleAutoAccept advances the countdown and submits when it reaches zero.
+func (a *App) handleAutoAccept(msg autoAcceptMsg) (tea.Model, tea.Cmd) {
+	if a.state != suggestionView || msg.gen != a.autoAcceptGen || a.suggestions.countdown <= 0 {
+		return a, nil
+	}
+	a.suggestions.countdown--
+	if a.suggestions.countdown > 0 {
+		return a, autoAcceptTick(msg.gen)
+	}
+	a.autoAccepted = true
+	return a, a.submitAllocations(a.suggestions.suggestion.Allocations)
+}
+
 func (a *App) handleSubmit(msg submitMsg) (tea.Model, tea.Cmd) {
 	if msg.err != nil {
 		a.state = confirmationView
@@ -366,20 +937,49 @@ func (a *App) handleSubmit(msg submitMsg) (tea.Model, tea.Cmd) {
 		return a, nil
 	}
 
-	a.result = &Result{Entries: msg.entries}
+	entries, errs := msg.entries, msg.errs
+	if a.retryOf != nil && a.result != nil {
+		// Keep what the earlier submit logged; the fixed entries replace the failed ones.
+		var kept []store.Entry
+		var keptErrs []string
+		for i, e := range a.result.Entries {
+			if a.submitErrs[i] == "" {
+				kept = append(kept, e)
+				keptErrs = append(keptErrs, "")
+			}
+		}
+		entries, errs = append(kept, entries...), append(keptErrs, errs...)
+		a.retryOf = nil
+	}
+	a.result = &Result{Entries: entries, AutoAccepted: a.autoAccepted}
+	a.submitErrs = errs
+	if a.db != nil {
+		a.db.DeleteSuggestion(a.startTime, a.endTime)
+		a.clearDraft()
+	}
+	if a.autoAccepted {
+		// Nobody is at the keyboard to dismiss the confirmation.
+		return a, tea.Quit
+	}
 	a.state = confirmationView
 	return a, nil
 }
 
 // startAI runs the AI provider in a goroutine, streaming thinking text to ch.
-func (a *App) startAI(description string, ch chan<- string) tea.Cmd {
+func (a *App) startAI(description string, interval time.Duration, segments []ai.Segment, ch chan<- string) tea.Cmd {
 	return func() tea.Msg {
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
+		if a.allProjects {
+			ctx = ai.WithAllProjects(ctx)
+		}
+		if a.retried {
+			ctx = ai.WithoutCache(ctx)
+		}
 
-		switch p := a.provider.(type) {
+		switch p := ai.Unwrap(a.provider).(type) {
 		case *ai.OpenRouterProvider:
-			resetIdle := idleTimeout(cancel, 2*time.Minute)
+			resetIdle := idleTimeout(cancel, a.aiTimeout)
 			p.OnThinking = func(text string) {
 				resetIdle()
 				select {
@@ -401,7 +1001,8 @@ func (a *App) startAI(description string, ch chan<- string) tea.Cmd {
 		}
 		defer close(ch)
 
-		suggestion, err := a.provider.MatchProjects(ctx, description, a.projects, a.interval, a.contextItems)
+		suggestion, err := a.provider.MatchProjects(ctx, description, a.projects, interval, a.sentContext(), segments)
+		ai.AlignToSegments(suggestion, segments)
 		return aiResponseMsg{suggestion: suggestion, err: err}
 	}
 }
@@ -461,60 +1062,140 @@ func idleTimeout(cancel context.CancelFunc, idleLimit time.Duration) (resetFunc
 	return reset
 }
 
-func (a *App) submitAllocations(allocations []ai.Allocation) tea.Cmd {
-	return func() tea.Msg {
-		ctx := context.Background()
-		var entries []store.Entry
+// recordSuggestion stores the AI's answer as first shown, with the
+// clarification rounds behind it, for 'clockr entry show'. It returns the ID
+// for the entries, or 0 when it could not be stored.
+func (a *App) recordSuggestion() int {
+	id, err := a.db.SaveEntrySuggestion(a.input.Value(), &ai.Suggestion{Allocations: a.aiOriginal}, a.clarifications, ai.PromptVersion(), ai.ModelName(a.provider))
+	if err != nil {
+		return 0
+	}
+	return id
+}
 
-		for _, alloc := range allocations {
-			allocDuration := time.Duration(alloc.Minutes) * time.Minute
-			entryStart := a.startTime
-			entryEnd := entryStart.Add(allocDuration)
+// submitAllocations places the allocations and returns the command that
+// creates them. Placement, the suggestion record and dropping replaced
+// failed rows happen here, in Update; the command only talks to the backend
+// and stores the outcome.
+func (a *App) submitAllocations(allocations []ai.Allocation) tea.Cmd {
+	suggestionID := 0
+	if a.aiOriginal != nil && len(a.retryOf) == 0 && a.db != nil {
+		a.db.LogSuggestionEdit(a.startTime, a.endTime, quality.EditDistance(a.aiOriginal, allocations))
+		suggestionID = a.recordSuggestion()
+	}
 
-			if entryEnd.After(a.endTime) {
-				entryEnd = a.endTime
+	start, busy := a.startTime, a.logged
+	if len(a.retryOf) > 0 {
+		suggestionID = a.retryOf[0].SuggestionID
+		// Resubmitting fixed entries: start where the first failed one did,
+		// step over the entries the earlier submit did log, and drop the
+		// failed rows so they are not retried as well.
+		start = a.retryOf[0].StartTime
+		for _, e := range a.retryOf {
+			if e.StartTime.Before(start) {
+				start = e.StartTime
+			}
+			if a.db != nil && e.ID != 0 {
+				a.db.DeleteEntry(e.ID)
 			}
+		}
+		busy = slices.Clone(busy)
+		for i, e := range a.result.Entries {
+			if a.submitErrs[i] == "" {
+				busy = append(busy, audit.Interval{Start: e.StartTime, End: e.EndTime})
+			}
+		}
+	}
+	parts := a.placeEntries(allocations, start, busy, suggestionID)
 
+	b, db := a.backend, a.db
+	return func() tea.Msg {
+		ctx := context.Background()
+		var entries []store.Entry
+		var errs []string
+		for _, part := range parts {
 			entry := clockify.TimeEntryRequest{
-				Start:       entryStart.UTC().Format("2006-01-02T15:04:05Z"),
-				End:         entryEnd.UTC().Format("2006-01-02T15:04:05Z"),
-				ProjectID:   alloc.ProjectID,
-				Description: alloc.Description,
+				Start:       part.StartTime.UTC().Format("2006-01-02T15:04:05Z"),
+				End:         part.EndTime.UTC().Format("2006-01-02T15:04:05Z"),
+				ProjectID:   part.ProjectID,
+				Description: part.Description,
+				TagIDs:      part.TagIDs,
 			}
 
-			created, err := a.clockify.CreateTimeEntry(ctx, a.workspaceID, entry)
+			created, err := b.CreateEntry(ctx, entry)
 
-			status := "logged"
-			clockifyID := ""
+			part.Status = "logged"
 			if err != nil {
-				status = "failed"
+				part.Status = "failed"
+				errs = append(errs, clockify.FriendlyError(err))
 			} else {
-				clockifyID = created.ID
+				part.ClockifyID = created.ID
+				part.TaskID, part.TagIDs, part.Billable = created.TaskID, created.TagIDs, created.Billable
+				errs = append(errs, "")
 			}
 
-			storeEntry := store.Entry{
-				ClockifyID:  clockifyID,
-				ProjectID:   alloc.ProjectID,
-				ProjectName: alloc.ProjectName,
-				ClientName:  alloc.ClientName,
-				Description: alloc.Description,
-				StartTime:   entryStart,
-				EndTime:     entryEnd,
-				Minutes:     alloc.Minutes,
-				Status:      status,
-				RawInput:    a.input.Value(),
+			if db != nil {
+				db.InsertEntry(&part)
 			}
 
-			if a.db != nil {
-				a.db.InsertEntry(&storeEntry)
-			}
+			entries = append(entries, part)
+		}
+		return submitMsg{entries: entries, errs: errs}
+	}
+}
 
-			entries = append(entries, storeEntry)
+// placeEntries lays allocations back to back from start, stepping over busy
+// time and splitting an allocation around it, capped at the window's end.
+func (a *App) placeEntries(allocations []ai.Allocation, start time.Time, busy []audit.Interval, suggestionID int) []store.Entry {
+	var parts []store.Entry
+	for _, alloc := range allocations {
+		allocDuration := time.Duration(alloc.Minutes) * time.Minute
+		entryEnd := start.Add(allocDuration)
 
-			// Advance start time for next allocation
-			a.startTime = entryEnd
+		pieces := []audit.Interval{{Start: start, End: entryEnd}}
+		if len(busy) > 0 {
+			pieces = audit.Place(start, allocDuration, busy)
+		}
+
+		for _, piece := range pieces {
+			if !piece.Start.Before(a.endTime) {
+				break
+			}
+			entryEnd = piece.End
+			if entryEnd.After(a.endTime) {
+				entryEnd = a.endTime
+			}
+			minutes := alloc.Minutes
+			if len(pieces) > 1 {
+				minutes = int(entryEnd.Sub(piece.Start).Minutes())
+			}
+
+			storeEntry := store.Entry{
+				ProjectID:    alloc.ProjectID,
+				ProjectName:  alloc.ProjectName,
+				ClientName:   alloc.ClientName,
+				Description:  alloc.Description,
+				StartTime:    piece.Start,
+				EndTime:      entryEnd,
+				Minutes:      minutes,
+				RawInput:     a.input.Value(),
+				Overtime:     a.overtime,
+				SuggestionID: suggestionID,
+				Source:       a.source,
+				IssueKey:     alloc.IssueKey,
+			}
+			if a.overtime && a.overtimeTag != "" {
+				storeEntry.TagIDs = []string{a.overtimeTag}
+			}
+			if a.splitMidnight {
+				parts = append(parts, store.SplitAtMidnight(storeEntry)...)
+			} else {
+				parts = append(parts, storeEntry)
+			}
 		}
 
-		return submitMsg{entries: entries}
+		// The next allocation starts where this one ended.
+		start = entryEnd
 	}
+	return parts
 }
@@ -0,0 +1,54 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/christopherklint97/clockr/internal/ai"
+	"github.com/christopherklint97/clockr/internal/clockify"
+)
+
+// archivedClientProjects returns the projects whose client is archived, by ID.
+func archivedClientProjects(projects []clockify.Project) map[string]clockify.Project {
+	archived := make(map[string]clockify.Project)
+	for _, p := range projects {
+		if p.ClientArchived {
+			archived[p.ID] = p
+		}
+	}
+	return archived
+}
+
+// archivedClientWarning warns once per project in ids that belongs to an
+// archived client, or returns "" when none do.
+func archivedClientWarning(ids []string, archived map[string]clockify.Project) string {
+	var lines []string
+	seen := make(map[string]bool)
+	for _, id := range ids {
+		p, ok := archived[id]
+		if !ok || seen[id] {
+			continue
+		}
+		seen[id] = true
+		lines = append(lines, warningStyle.Render("⚠ Client "+p.ClientName+" is archived — time on "+p.Name+" will be rejected at invoicing"))
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func allocationProjectIDs(allocs []ai.Allocation) []string {
+	ids := make([]string, len(allocs))
+	for i, a := range allocs {
+		ids[i] = a.ProjectID
+	}
+	return ids
+}
+
+func batchProjectIDs(allocs []ai.BatchAllocation) []string {
+	ids := make([]string, len(allocs))
+	for i, a := range allocs {
+		ids[i] = a.ProjectID
+	}
+	return ids
+}
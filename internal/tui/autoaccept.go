@@ -0,0 +1,34 @@
+package tui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/christopherklint97/clockr/internal/ai"
+)
+
+// autoAcceptMsg counts down one second of an auto-accept. gen ties it to the
+// suggestion that started the countdown, so ticks from an earlier run are
+// ignored after a retry.
+type autoAcceptMsg struct {
+	gen int
+}
+
+func autoAcceptTick(gen int) tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return autoAcceptMsg{gen: gen}
+	})
+}
+
+// confidentEnough reports whether every allocation of s reaches minConfidence.
+func confidentEnough(s *ai.Suggestion, minConfidence float64) bool {
+	if s == nil || s.Clarification != "" || len(s.Allocations) == 0 {
+		return false
+	}
+	for _, a := range s.Allocations {
+		if a.Confidence < minConfidence {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,67 @@
+package tui
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/christopherklint97/clockr/internal/ai"
+)
+
+func newAutoAcceptApp(seconds int) *App {
+	now := time.Now()
+	app := NewApp(now.Add(-time.Hour), now, nil, nil, nil, nil, time.Hour, nil, "")
+	app.SetAutoAccept(seconds, 0.8)
+	return app
+}
+
+func suggestionWithConfidence(c float64) *ai.Suggestion {
+	return &ai.Suggestion{Allocations: []ai.Allocation{
+		{ProjectID: "p1", ProjectName: "Alpha", Minutes: 60, Description: "Review", Confidence: c},
+	}}
+}
+
+func TestAutoAccept_CountdownStopsOnKey(t *testing.T) {
+	app := newAutoAcceptApp(3)
+	_, cmd := app.handleAIResponse(aiResponseMsg{suggestion: suggestionWithConfidence(0.9)})
+	if cmd == nil || app.suggestions.countdown != 3 {
+		t.Fatalf("expected countdown to start, got %d", app.suggestions.countdown)
+	}
+
+	app.Update(autoAcceptMsg{gen: app.autoAcceptGen})
+	if app.suggestions.countdown != 2 {
+		t.Errorf("countdown = %d after one tick, want 2", app.suggestions.countdown)
+	}
+
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	if app.suggestions.countdown != 0 {
+		t.Error("a key press should cancel the countdown")
+	}
+	app.Update(autoAcceptMsg{gen: app.autoAcceptGen})
+	if app.suggestions.countdown != 0 || app.autoAccepted {
+		t.Error("ticks after cancelling should do nothing")
+	}
+}
+
+func TestAutoAccept_NeedsConfidence(t *testing.T) {
+	app := newAutoAcceptApp(20)
+	app.handleAIResponse(aiResponseMsg{suggestion: suggestionWithConfidence(0.6)})
+	if app.suggestions.countdown != 0 {
+		t.Error("low-confidence suggestions should not auto-accept")
+	}
+
+	app = newAutoAcceptApp(0)
+	app.handleAIResponse(aiResponseMsg{suggestion: suggestionWithConfidence(0.95)})
+	if app.suggestions.countdown != 0 {
+		t.Error("auto-accept should be off by default")
+	}
+}
+
+func TestAutoAccept_StaleTickIgnored(t *testing.T) {
+	app := newAutoAcceptApp(5)
+	app.handleAIResponse(aiResponseMsg{suggestion: suggestionWithConfidence(0.9)})
+	app.Update(autoAcceptMsg{gen: app.autoAcceptGen - 1})
+	if app.suggestions.countdown != 5 {
+		t.Errorf("tick from an earlier run changed the countdown to %d", app.suggestions.countdown)
+	}
+}
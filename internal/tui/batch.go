@@ -3,6 +3,7 @@ package tui
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -13,7 +14,10 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/christopherklint97/clockr/internal/ai"
+	"github.com/christopherklint97/clockr/internal/backend"
 	"github.com/christopherklint97/clockr/internal/clockify"
+	"github.com/christopherklint97/clockr/internal/config"
+	"github.com/christopherklint97/clockr/internal/format"
 	"github.com/christopherklint97/clockr/internal/store"
 )
 
@@ -25,6 +29,7 @@ const (
 	batchSuggestionView
 	batchEditView
 	batchConfirmationView
+	batchClarifyView
 )
 
 type batchAIResponseMsg struct {
@@ -34,6 +39,7 @@ type batchAIResponseMsg struct {
 
 type batchSubmitMsg struct {
 	entries []store.Entry
+	errs    []string // per entry; "" when it was logged
 	err     error
 }
 
@@ -47,12 +53,30 @@ type BatchApp struct {
 	result      *Result
 	errMsg      string
 
-	days        []ai.DaySlot
-	provider    ai.Provider
-	projects    []clockify.Project
-	clockify    *clockify.Client
-	workspaceID string
-	db          *store.DB
+	days           []ai.DaySlot
+	provider       ai.Provider
+	projects       []clockify.Project
+	backend        backend.Backend
+	db             *store.DB
+	previous       []ai.BatchAllocation // last suggestion before a retry
+	formatter      *format.Formatter
+	rounding       clockify.Rounding
+	regenDate      string   // day being regenerated; "" for a full run
+	editDate       string   // day open in the edit view
+	skippedDays    []string // days the user skipped, for the confirmation
+	clarify        clarifyModel
+	clarifications []ai.Exchange        // answers to the AI's questions about the current description
+	aiOriginal     []ai.BatchAllocation // the AI's suggestion as first shown, stored with the entries
+	allProjects    bool                 // send the AI every project, not the trimmed list
+	retried        bool                 // the user asked again, so cached AI answers are not reused
+	submitErrs     []string             // per result entry; "" when it was logged
+	copied         string               // status of Ctrl+Y on the confirmation
+	dryRun         bool
+	planned        []store.Entry // entries a dry run would have created
+	rollback       rollbackState
+	rollbackErrs   []string // entries that could not be deleted when rolling back
+	splitMidnight  bool     // log an entry spanning midnight as one per day
+	aiTimeout      time.Duration
 
 	thinkCh          <-chan string
 	thinkingText     string
@@ -60,6 +84,7 @@ type BatchApp struct {
 	loadingStartTime time.Time
 	termWidth        int
 	termHeight       int
+	draftSaved       string // description last saved as the draft
 
 	readyCh chan struct{} // signals PromptFileProvider that user pressed Enter
 }
@@ -68,8 +93,7 @@ func NewBatchApp(
 	days []ai.DaySlot,
 	provider ai.Provider,
 	projects []clockify.Project,
-	client *clockify.Client,
-	workspaceID string,
+	b backend.Backend,
 	db *store.DB,
 	lastInput string,
 ) *BatchApp {
@@ -85,18 +109,20 @@ func NewBatchApp(
 		days[0].Date, days[totalDays-1].Date, totalDays, totalMin)
 
 	input := newInputModel(timeInfo)
-	input.lastInput = lastInput
+	input.history = loadHistory(db, lastInput)
+	input.snippets = loadSnippets(db)
+	input.draft = loadDraft(db)
 
 	return &BatchApp{
-		state:       batchInputView,
-		input:       input,
-		spinner:     s,
-		days:        days,
-		provider:    provider,
-		projects:    projects,
-		clockify:    client,
-		workspaceID: workspaceID,
-		db:          db,
+		state:     batchInputView,
+		input:     input,
+		spinner:   s,
+		days:      days,
+		provider:  provider,
+		projects:  projects,
+		backend:   b,
+		db:        db,
+		aiTimeout: config.DefaultAITimeout,
 	}
 }
 
@@ -104,8 +130,35 @@ func (a *BatchApp) SetInitialInput(text string) {
 	a.input.textarea.SetValue(text)
 }
 
+// SetDryRun makes accepting show the entries that would be created instead
+// of creating them.
+func (a *BatchApp) SetDryRun(dryRun bool) {
+	a.dryRun = dryRun
+}
+
+// SetAITimeout sets how long a streaming AI call may go without output
+// before it is cancelled.
+func (a *BatchApp) SetAITimeout(d time.Duration) {
+	a.aiTimeout = d
+}
+
+// SetSplitAtMidnight logs entries that span midnight as one entry per day.
+func (a *BatchApp) SetSplitAtMidnight(split bool) {
+	a.splitMidnight = split
+}
+
+// SetFormatter applies f to descriptions after AI generation and edits.
+func (a *BatchApp) SetFormatter(f *format.Formatter) {
+	a.formatter = f
+}
+
+// SetRounding rounds allocation minutes by r after AI generation and edits.
+func (a *BatchApp) SetRounding(r clockify.Rounding) {
+	a.rounding = r
+}
+
 func (a *BatchApp) Init() tea.Cmd {
-	return tea.Batch(a.input.textarea.Focus(), a.spinner.Tick)
+	return tea.Batch(a.input.textarea.Focus(), a.spinner.Tick, draftTick())
 }
 
 func (a *BatchApp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -125,13 +178,22 @@ func (a *BatchApp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		if msg.String() == "ctrl+c" {
-			a.result = &Result{Skipped: true}
+			a.result = &Result{Skipped: true, Interrupted: true}
 			return a, tea.Quit
 		}
 	case batchAIResponseMsg:
 		return a.handleAIResponse(msg)
 	case batchSubmitMsg:
 		return a.handleSubmit(msg)
+	case batchRollbackMsg:
+		return a.handleRollback(msg)
+	case clipboardMsg:
+		if a.state == batchConfirmationView {
+			a.copied = clipboardStatus(msg)
+		} else {
+			a.suggestions.status = clipboardStatus(msg)
+		}
+		return a, nil
 	case thinkingMsg:
 		a.thinkingText += msg.text
 		a.viewport.SetContent(a.thinkingText)
@@ -144,6 +206,12 @@ func (a *BatchApp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return a, tickCmd()
 		}
 		return a, nil
+	case draftTickMsg:
+		if a.result != nil {
+			return a, nil
+		}
+		a.saveDraft()
+		return a, draftTick()
 	}
 
 	switch a.state {
@@ -157,6 +225,8 @@ func (a *BatchApp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return a.updateEdit(msg)
 	case batchConfirmationView:
 		return a.updateConfirmation(msg)
+	case batchClarifyView:
+		return a.updateClarify(msg)
 	}
 
 	return a, nil
@@ -169,7 +239,7 @@ func (a *BatchApp) View() string {
 	case batchLoadingView:
 		elapsed := time.Since(a.loadingStartTime).Truncate(time.Second)
 		label := "Thinking..."
-		if _, ok := a.provider.(*ai.PromptFileProvider); ok {
+		if _, ok := ai.Unwrap(a.provider).(*ai.PromptFileProvider); ok {
 			label = "Waiting for response..."
 		}
 		header := fmt.Sprintf("%s %s  %s", a.spinner.View(), label, dimStyle.Render(formatElapsed(elapsed)))
@@ -184,6 +254,8 @@ func (a *BatchApp) View() string {
 			return errorStyle.Render("Error: ") + a.errMsg + "\n\n" + helpStyle.Render("Press any key to exit")
 		}
 		return a.confirmationView()
+	case batchClarifyView:
+		return a.clarify.View()
 	}
 	return ""
 }
@@ -192,25 +264,19 @@ func (a *BatchApp) GetResult() *Result {
 	return a.result
 }
 
+func (a *BatchApp) readOnly() bool {
+	return a.db != nil && a.db.ReadOnly()
+}
+
 func (a *BatchApp) updateInput(msg tea.Msg) (tea.Model, tea.Cmd) {
-	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && !a.input.Picking() {
 		if keyMsg.String() == "enter" && a.input.Value() != "" {
 			// Save description immediately so it survives AI failures
-			if a.db != nil {
-				a.db.SetState("last_description", a.input.Value())
-			}
-			a.state = batchLoadingView
-			a.thinkingText = ""
-			a.loadingStartTime = time.Now()
-			a.viewport = viewport.New(a.termWidth, max(a.termHeight-3, 1))
-			ch := make(chan string, 100)
-			a.thinkCh = ch
-			return a, tea.Batch(
-				a.spinner.Tick,
-				a.startAI(a.input.Value(), ch),
-				readThinking(ch),
-				tickCmd(),
-			)
+			rememberInput(a.db, a.input.Value())
+			a.saveDraft()
+			a.clarifications = nil
+			a.allProjects = false
+			return a, a.startLoading(a.days)
 		}
 	}
 
@@ -219,6 +285,22 @@ func (a *BatchApp) updateInput(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return a, cmd
 }
 
+// startLoading switches to the loading view and asks the AI about days.
+func (a *BatchApp) startLoading(days []ai.DaySlot) tea.Cmd {
+	a.state = batchLoadingView
+	a.thinkingText = ""
+	a.loadingStartTime = time.Now()
+	a.viewport = viewport.New(a.termWidth, max(a.termHeight-3, 1))
+	ch := make(chan string, 100)
+	a.thinkCh = ch
+	return tea.Batch(
+		a.spinner.Tick,
+		a.startAI(ai.WithClarifications(a.input.Value(), a.clarifications), days, ch),
+		readThinking(ch),
+		tickCmd(),
+	)
+}
+
 func (a *BatchApp) updateLoading(msg tea.Msg) (tea.Model, tea.Cmd) {
 	if keyMsg, ok := msg.(tea.KeyMsg); ok {
 		if keyMsg.String() == "enter" && a.readyCh != nil {
@@ -241,40 +323,137 @@ func (a *BatchApp) updateLoading(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (a *BatchApp) updateSuggestion(msg tea.Msg) (tea.Model, tea.Cmd) {
-	if keyMsg, ok := msg.(tea.KeyMsg); ok {
-		switch keyMsg.String() {
-		case "a":
-			return a, a.submitAllocations(a.suggestions.suggestion.Allocations)
-		case "e":
-			a.state = batchEditView
-			a.edit = newBatchEditModel(a.suggestions.suggestion.Allocations, a.projects)
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return a, nil
+	}
+	m := &a.suggestions
+	m.status = ""
+	switch keyMsg.String() {
+	case "a":
+		if m.decide(dayAccepted) {
+			return a.finish()
+		}
+	case "x":
+		if m.decide(daySkipped) {
+			return a.finish()
+		}
+	case "A":
+		m.acceptPending()
+		return a.finish()
+	case "g":
+		day, ok := a.daySlot(m.date())
+		if !ok {
+			m.status = warningStyle.Render("This day is outside the requested range and cannot be regenerated.")
 			return a, nil
-		case "r":
-			a.state = batchInputView
-			newInput := newInputModel(a.input.timeInfo)
-			newInput, _ = newInput.Update(tea.WindowSizeMsg{Width: a.input.width, Height: a.input.height})
-			a.input = newInput
-			return a, a.input.textarea.Focus()
-		case "s":
-			a.result = &Result{Skipped: true}
-			return a, tea.Quit
-		case "up", "k":
-			if a.suggestions.cursor > 0 {
-				a.suggestions.cursor--
-			}
-		case "down", "j":
-			if a.suggestions.cursor < len(a.suggestions.suggestion.Allocations)-1 {
-				a.suggestions.cursor++
-			}
+		}
+		a.previous = slices.Clone(m.suggestion.Allocations)
+		a.regenDate = day.Date
+		return a, a.startLoading([]ai.DaySlot{day})
+	case "e":
+		if m.date() == "" {
+			return a, nil
+		}
+		a.editDate = m.date()
+		a.state = batchEditView
+		a.edit = newBatchEditModel(m.dayAllocations(), a.projects)
+		return a, nil
+	case "c":
+		if q := m.suggestion.Clarification; q != "" {
+			a.clarify = newClarifyModel(q, a.clarifications, m.suggestion.Hidden)
+			a.state = batchClarifyView
+			return a, textinput.Blink
+		}
+	case "r":
+		if s := m.suggestion; s.Clarification == "" {
+			a.previous = slices.Clone(s.Allocations)
+		}
+		a.retried = true
+		a.state = batchInputView
+		newInput := newInputModel(a.input.timeInfo)
+		newInput = newInput.withSaved(a.input)
+		newInput, _ = newInput.Update(tea.WindowSizeMsg{Width: a.input.width, Height: a.input.height})
+		a.input = newInput
+		return a, a.input.textarea.Focus()
+	case "y":
+		if i := m.selected(); i >= 0 {
+			return a, copyCmd("Description", m.suggestion.Allocations[i].Description)
+		}
+	case "s":
+		a.result = &Result{Skipped: true}
+		return a, tea.Quit
+	case "left", "h", "pgup":
+		m.setPage(m.page - 1)
+	case "right", "l", "pgdown":
+		m.setPage(m.page + 1)
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(m.dayIndices())-1 {
+			m.cursor++
 		}
 	}
 	return a, nil
 }
 
+// updateClarify collects the answer to a clarification question and asks
+// the AI again about the whole range with the exchange so far.
+func (a *BatchApp) updateClarify(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && keyMsg.String() == "esc" {
+		a.state = batchSuggestionView
+		return a, nil
+	}
+	var exchange ai.Exchange
+	var done bool
+	var cmd tea.Cmd
+	a.clarify, exchange, done, cmd = a.clarify.Update(msg)
+	if done {
+		if exchange.Answer != "" {
+			a.clarifications = append(a.clarifications, exchange)
+		}
+		a.allProjects = a.allProjects || a.clarify.allProjects
+		return a, a.startLoading(a.days)
+	}
+	return a, cmd
+}
+
+// finish submits the accepted days once every day has a decision.
+func (a *BatchApp) finish() (tea.Model, tea.Cmd) {
+	a.skippedDays = a.suggestions.skippedDates()
+	accepted := a.suggestions.accepted()
+	if a.dryRun && len(accepted) > 0 {
+		return a.previewAllocations(accepted)
+	}
+	if a.readOnly() || len(accepted) == 0 {
+		if !a.readOnly() {
+			a.clearDraft()
+		}
+		a.result = &Result{Skipped: true}
+		a.state = batchConfirmationView
+		return a, nil
+	}
+	return a, a.submitAllocations(accepted)
+}
+
+// daySlot returns the requested day with the given date.
+func (a *BatchApp) daySlot(date string) (ai.DaySlot, bool) {
+	for _, d := range a.days {
+		if d.Date == date {
+			return d, true
+		}
+	}
+	return ai.DaySlot{}, false
+}
+
 func (a *BatchApp) updateEdit(msg tea.Msg) (tea.Model, tea.Cmd) {
 	if keyMsg, ok := msg.(tea.KeyMsg); ok {
 		if keyMsg.String() == "esc" && !a.edit.editing {
-			a.suggestions.suggestion.Allocations = a.edit.allocations
+			formatBatchAllocations(a.formatter, a.edit.allocations)
+			roundBatchAllocations(a.rounding, a.edit.allocations)
+			a.suggestions.replaceDay(a.editDate, a.edit.allocations)
+			a.suggestions.suggestion.Problems = nil // the edit is the user's call
 			a.state = batchSuggestionView
 			return a, nil
 		}
@@ -286,25 +465,65 @@ func (a *BatchApp) updateEdit(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (a *BatchApp) updateConfirmation(msg tea.Msg) (tea.Model, tea.Cmd) {
-	if _, ok := msg.(tea.KeyMsg); ok {
-		return a, tea.Quit
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok || a.rollback == rollbackRunning {
+		return a, nil
 	}
-	return a, nil
+	if keyMsg.String() == "u" && a.canRollBack() {
+		a.rollback = rollbackRunning
+		return a, a.rollBack()
+	}
+	if keyMsg.String() == "ctrl+y" && a.canCopySummary() {
+		return a, copyCmd("Summary", entriesSummary(a.result.Entries, a.submitErrs))
+	}
+	return a, tea.Quit
 }
 
 func (a *BatchApp) handleAIResponse(msg batchAIResponseMsg) (tea.Model, tea.Cmd) {
+	if a.regenDate != "" {
+		return a.handleRegenResponse(msg)
+	}
 	if msg.err != nil {
 		a.state = batchConfirmationView
 		a.errMsg = msg.err.Error()
 		return a, nil
 	}
 
-	a.suggestions = newBatchSuggestionsModel(msg.suggestion)
+	formatBatchAllocations(a.formatter, msg.suggestion.Allocations)
+	roundBatchAllocations(a.rounding, msg.suggestion.Allocations)
+	a.aiOriginal = slices.Clone(msg.suggestion.Allocations)
+	a.suggestions = newBatchSuggestionsModel(msg.suggestion, a.projects)
 	a.suggestions.termWidth = a.termWidth
+	a.suggestions.rounding = a.rounding
+	a.suggestions.formatter = a.formatter
+	a.suggestions.previous = a.previous
 	a.state = batchSuggestionView
 	return a, nil
 }
 
+// handleRegenResponse replaces the regenerated day and returns to the
+// pager. On failure the day keeps its previous allocations.
+func (a *BatchApp) handleRegenResponse(msg batchAIResponseMsg) (tea.Model, tea.Cmd) {
+	date := a.regenDate
+	a.regenDate = ""
+	a.state = batchSuggestionView
+	switch {
+	case msg.err != nil:
+		a.suggestions.status = errorStyle.Render("Regenerating " + date + " failed: " + msg.err.Error())
+		return a, nil
+	case msg.suggestion.Clarification != "":
+		a.suggestions.status = warningStyle.Render("Regenerating " + date + " needs clarification: " + msg.suggestion.Clarification)
+		return a, nil
+	}
+
+	formatBatchAllocations(a.formatter, msg.suggestion.Allocations)
+	roundBatchAllocations(a.rounding, msg.suggestion.Allocations)
+	a.suggestions.replaceDay(date, msg.suggestion.Allocations)
+	a.suggestions.decisions[date] = dayPending
+	a.suggestions.previous = a.previous
+	return a, nil
+}
+
 func (a *BatchApp) handleSubmit(msg batchSubmitMsg) (tea.Model, tea.Cmd) {
 	if msg.err != nil {
 		a.state = batchConfirmationView
@@ -313,19 +532,27 @@ func (a *BatchApp) handleSubmit(msg batchSubmitMsg) (tea.Model, tea.Cmd) {
 	}
 
 	a.result = &Result{Entries: msg.entries}
+	a.submitErrs = msg.errs
+	a.clearDraft()
 	a.state = batchConfirmationView
 	return a, nil
 }
 
 // startAI runs the AI provider in a goroutine, streaming thinking text to ch.
-func (a *BatchApp) startAI(description string, ch chan<- string) tea.Cmd {
+func (a *BatchApp) startAI(description string, days []ai.DaySlot, ch chan<- string) tea.Cmd {
 	return func() tea.Msg {
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
+		if a.allProjects {
+			ctx = ai.WithAllProjects(ctx)
+		}
+		if a.retried {
+			ctx = ai.WithoutCache(ctx)
+		}
 
-		switch p := a.provider.(type) {
+		switch p := ai.Unwrap(a.provider).(type) {
 		case *ai.OpenRouterProvider:
-			resetIdle := idleTimeout(cancel, 2*time.Minute)
+			resetIdle := idleTimeout(cancel, a.aiTimeout)
 			p.OnThinking = func(text string) {
 				resetIdle()
 				select {
@@ -347,123 +574,271 @@ func (a *BatchApp) startAI(description string, ch chan<- string) tea.Cmd {
 		}
 		defer close(ch)
 
-		suggestion, err := a.provider.MatchProjectsBatch(ctx, description, a.projects, a.days)
+		suggestion, err := a.provider.MatchProjectsBatch(ctx, description, a.projects, days)
 		return batchAIResponseMsg{suggestion: suggestion, err: err}
 	}
 }
 
-func (a *BatchApp) submitAllocations(allocations []ai.BatchAllocation) tea.Cmd {
-	return func() tea.Msg {
-		ctx := context.Background()
-		var entries []store.Entry
+// location returns the zone the day slots were built in, so entries land on
+// the intended wall-clock times.
+func (a *BatchApp) location() *time.Location {
+	if len(a.days) > 0 {
+		return a.days[0].Start.Location()
+	}
+	return time.Local
+}
 
-		for _, alloc := range allocations {
-			entryStart, err := parseBatchTime(alloc.Date, alloc.StartTime)
-			if err != nil {
-				return batchSubmitMsg{err: fmt.Errorf("parsing start time for %s: %w", alloc.Date, err)}
-			}
-			entryEnd, err := parseBatchTime(alloc.Date, alloc.EndTime)
-			if err != nil {
-				return batchSubmitMsg{err: fmt.Errorf("parsing end time for %s: %w", alloc.Date, err)}
-			}
+func parseBatchTime(date, timeStr string, loc *time.Location) (time.Time, error) {
+	combined := date + " " + timeStr
+	return time.ParseInLocation("2006-01-02 15:04", combined, loc)
+}
 
-			entry := clockify.TimeEntryRequest{
-				Start:       entryStart.UTC().Format("2006-01-02T15:04:05Z"),
-				End:         entryEnd.UTC().Format("2006-01-02T15:04:05Z"),
-				ProjectID:   alloc.ProjectID,
-				Description: alloc.Description,
-			}
+// --- Batch suggestions model ---
 
-			created, err := a.clockify.CreateTimeEntry(ctx, a.workspaceID, entry)
+// dayDecision is what the user chose for one day of a batch suggestion.
+type dayDecision int
 
-			status := "logged"
-			clockifyID := ""
-			if err != nil {
-				status = "failed"
-			} else {
-				clockifyID = created.ID
-			}
+const (
+	dayPending dayDecision = iota
+	dayAccepted
+	daySkipped
+)
 
-			storeEntry := store.Entry{
-				ClockifyID:  clockifyID,
-				ProjectID:   alloc.ProjectID,
-				ProjectName: alloc.ProjectName,
-				ClientName:  alloc.ClientName,
-				Description: alloc.Description,
-				StartTime:   entryStart,
-				EndTime:     entryEnd,
-				Minutes:     alloc.Minutes,
-				Status:      status,
-				RawInput:    a.input.Value(),
-			}
+// batchSuggestionsModel shows one day of the suggestion at a time, so long
+// ranges fit the terminal and each day can be accepted or skipped on its own.
+type batchSuggestionsModel struct {
+	suggestion *ai.BatchSuggestion
+	dates      []string // days in the suggestion, in order
+	decisions  map[string]dayDecision
+	page       int // index into dates
+	cursor     int // allocation within the current day
+	termWidth  int
+	status     string                      // feedback line, e.g. after copying a description
+	previous   []ai.BatchAllocation        // the run before a retry, diffed against; nil on the first run
+	archived   map[string]clockify.Project // projects under an archived client
+	rounding   clockify.Rounding           // workspace rounding applied to the minutes
+	formatter  *format.Formatter           // flags descriptions breaking max_length or pattern
+}
 
-			if a.db != nil {
-				a.db.InsertEntry(&storeEntry)
-			}
+func newBatchSuggestionsModel(s *ai.BatchSuggestion, projects []clockify.Project) batchSuggestionsModel {
+	return batchSuggestionsModel{
+		suggestion: s,
+		dates:      allocationDates(s.Allocations),
+		decisions:  make(map[string]dayDecision),
+		archived:   archivedClientProjects(projects),
+	}
+}
 
-			entries = append(entries, storeEntry)
+// allocationDates returns the distinct dates of allocs, sorted.
+func allocationDates(allocs []ai.BatchAllocation) []string {
+	var dates []string
+	for _, a := range allocs {
+		if !slices.Contains(dates, a.Date) {
+			dates = append(dates, a.Date)
 		}
+	}
+	slices.Sort(dates)
+	return dates
+}
 
-		return batchSubmitMsg{entries: entries}
+// date returns the day currently shown, or "" when there are none.
+func (m batchSuggestionsModel) date() string {
+	if m.page < 0 || m.page >= len(m.dates) {
+		return ""
 	}
+	return m.dates[m.page]
 }
 
-func (a *BatchApp) confirmationView() string {
-	if a.result == nil || len(a.result.Entries) == 0 {
-		return successStyle.Render("No entries to log.") + "\n\n" + helpStyle.Render("Press any key to exit")
+// dayIndices returns the indices of the current day's allocations.
+func (m batchSuggestionsModel) dayIndices() []int {
+	var idx []int
+	for i, a := range m.suggestion.Allocations {
+		if a.Date == m.date() {
+			idx = append(idx, i)
+		}
 	}
+	return idx
+}
 
-	dayCount := make(map[string]int)
-	dayMinutes := make(map[string]int)
-	for _, e := range a.result.Entries {
-		date := e.StartTime.Local().Format("2006-01-02")
-		dayCount[date]++
-		dayMinutes[date] += e.Minutes
+// selected returns the index of the allocation under the cursor, or -1.
+func (m batchSuggestionsModel) selected() int {
+	if idx := m.dayIndices(); m.cursor < len(idx) {
+		return idx[m.cursor]
 	}
+	return -1
+}
 
-	var sb strings.Builder
-	sb.WriteString(successStyle.Render(fmt.Sprintf("Logged %d entries across %d days!", len(a.result.Entries), len(dayCount))))
-	sb.WriteString("\n\n")
+// dayAllocations returns a copy of the current day's allocations.
+func (m batchSuggestionsModel) dayAllocations() []ai.BatchAllocation {
+	var allocs []ai.BatchAllocation
+	for _, i := range m.dayIndices() {
+		allocs = append(allocs, m.suggestion.Allocations[i])
+	}
+	return allocs
+}
 
-	for _, d := range a.days {
-		if count, ok := dayCount[d.Date]; ok {
-			sb.WriteString(fmt.Sprintf("  %s %s: %d entries, %d min\n", d.Date, d.Weekday, count, dayMinutes[d.Date]))
+// replaceDay swaps date's allocations for allocs (only those on date are
+// kept), in the position the day had before.
+func (m *batchSuggestionsModel) replaceDay(date string, allocs []ai.BatchAllocation) {
+	var out []ai.BatchAllocation
+	inserted := false
+	insert := func() {
+		for _, a := range allocs {
+			if a.Date == date {
+				out = append(out, a)
+			}
+		}
+		inserted = true
+	}
+	for _, a := range m.suggestion.Allocations {
+		if a.Date == date {
+			if !inserted {
+				insert()
+			}
+			continue
+		}
+		if !inserted && a.Date > date {
+			insert()
 		}
+		out = append(out, a)
+	}
+	if !inserted {
+		insert()
 	}
+	m.suggestion.Allocations = out
+	m.dates = allocationDates(out)
+	if i := slices.Index(m.dates, date); i >= 0 {
+		m.page = i
+	}
+	m.page = min(m.page, max(len(m.dates)-1, 0))
+	m.cursor = 0
+}
 
-	sb.WriteString("\n")
-	sb.WriteString(helpStyle.Render("Press any key to exit"))
-	return sb.String()
+// setPage moves to day i, clamped to the range.
+func (m *batchSuggestionsModel) setPage(i int) {
+	m.page = max(min(i, len(m.dates)-1), 0)
+	m.cursor = 0
 }
 
-func parseBatchTime(date, timeStr string) (time.Time, error) {
-	combined := date + " " + timeStr
-	return time.ParseInLocation("2006-01-02 15:04", combined, time.Now().Location())
+// decide records d for the current day and moves to the next pending day.
+// It reports whether every day now has a decision.
+func (m *batchSuggestionsModel) decide(d dayDecision) bool {
+	if m.date() == "" {
+		return true
+	}
+	m.decisions[m.date()] = d
+	for step := 1; step <= len(m.dates); step++ {
+		i := (m.page + step) % len(m.dates)
+		if m.decisions[m.dates[i]] == dayPending {
+			m.setPage(i)
+			return false
+		}
+	}
+	return true
 }
 
-// --- Batch suggestions model ---
+// acceptPending accepts every day without a decision yet.
+func (m *batchSuggestionsModel) acceptPending() {
+	for _, d := range m.dates {
+		if m.decisions[d] == dayPending {
+			m.decisions[d] = dayAccepted
+		}
+	}
+}
 
-type batchSuggestionsModel struct {
-	suggestion *ai.BatchSuggestion
-	cursor     int
-	termWidth  int
+// accepted returns the allocations of accepted days, in order.
+func (m batchSuggestionsModel) accepted() []ai.BatchAllocation {
+	var allocs []ai.BatchAllocation
+	for _, a := range m.suggestion.Allocations {
+		if m.decisions[a.Date] == dayAccepted {
+			allocs = append(allocs, a)
+		}
+	}
+	return allocs
 }
 
-func newBatchSuggestionsModel(s *ai.BatchSuggestion) batchSuggestionsModel {
-	return batchSuggestionsModel{suggestion: s}
+// skippedDates returns the days the user skipped.
+func (m batchSuggestionsModel) skippedDates() []string {
+	var dates []string
+	for _, d := range m.dates {
+		if m.decisions[d] == daySkipped {
+			dates = append(dates, d)
+		}
+	}
+	return dates
+}
+
+func (m batchSuggestionsModel) counts() (accepted, skipped, pending int) {
+	for _, d := range m.dates {
+		switch m.decisions[d] {
+		case dayAccepted:
+			accepted++
+		case daySkipped:
+			skipped++
+		default:
+			pending++
+		}
+	}
+	return accepted, skipped, pending
+}
+
+// renderDayStrip shows every day with its decision, the current one
+// highlighted, wrapped to width.
+func (m batchSuggestionsModel) renderDayStrip(width int) string {
+	var lines []string
+	line, lineWidth := "", 0
+	for i, d := range m.dates {
+		label := d
+		if t, err := time.Parse("2006-01-02", d); err == nil {
+			label = t.Format("Mon 02")
+		}
+		var token string
+		switch m.decisions[d] {
+		case dayAccepted:
+			token = successStyle.Render("✓ " + label)
+		case daySkipped:
+			token = dimStyle.Render("✗ " + label)
+		default:
+			token = "· " + label
+		}
+		if i == m.page {
+			token = highlightStyle.Render("[") + token + highlightStyle.Render("]")
+		} else {
+			token = " " + token + " "
+		}
+		w := lipgloss.Width(token)
+		if width > 0 && lineWidth > 0 && lineWidth+w > width {
+			lines = append(lines, line)
+			line, lineWidth = "", 0
+		}
+		line += token
+		lineWidth += w
+	}
+	return strings.Join(append(lines, line), "\n")
 }
 
 func (m batchSuggestionsModel) View() string {
 	if m.suggestion.Clarification != "" {
 		return warningStyle.Render("Clarification needed: ") + m.suggestion.Clarification + "\n\n" +
+			helpStyle.Render("[c] answer • [r]etry with more detail • [s]kip")
+	}
+	if len(m.dates) == 0 {
+		return warningStyle.Render("The AI suggested no allocations.") + "\n\n" +
 			helpStyle.Render("[r]etry with more detail • [s]kip")
 	}
 
 	var sb strings.Builder
 	sb.WriteString(titleStyle.Render("Suggested Batch Allocations"))
 	sb.WriteString("\n")
+	stripWidth := 0
+	if m.termWidth > 0 {
+		stripWidth = m.termWidth - 4
+	}
+	sb.WriteString(m.renderDayStrip(stripWidth))
+	sb.WriteString("\n\n")
 
-	// Compute column widths across all allocations
+	// Compute column widths across all allocations, so columns stay put
+	// when paging between days
 	maxProject := 0
 	maxMinutes := 0
 	maxTimeRange := 0
@@ -491,12 +866,28 @@ func (m batchSuggestionsModel) View() string {
 		maxDesc = max(maxDesc, len(a.Description))
 	}
 
+	// After a retry, mark what changed relative to the previous run
+	var changes []allocChange
+	var removed []diffRow
+	maxDelta := 0
+	diffWidth := 0
+	if m.previous != nil {
+		changes, removed = diffRows(batchAllocationRows(m.previous), batchAllocationRows(m.suggestion.Allocations))
+		for _, c := range changes {
+			maxDelta = max(maxDelta, len(c.deltaText()))
+		}
+		diffWidth = 2 // mark + space
+		if maxDelta > 0 {
+			diffWidth += maxDelta + 2
+		}
+	}
+
 	// Truncate columns to fit terminal width
-	// Layout: prefix(2) + project + gap(2) + minutes + gap(2) + confidence(4) + gap(2) + timeRange + gap(2) + desc
+	// Layout: prefix(2) + [mark(2)] + project + gap(2) + minutes + [gap(2) + delta] + gap(2) + confidence(4) + gap(2) + timeRange + gap(2) + desc
 	// Box overhead: border(2) + padding(2) = 4
 	if m.termWidth > 0 {
 		available := m.termWidth - 4
-		fixed := 14 + maxMinutes + maxTimeRange // prefix(2) + 4 gaps(8) + confidence(4) + minutes + timeRange
+		fixed := 14 + maxMinutes + maxTimeRange + diffWidth // prefix(2) + 4 gaps(8) + confidence(4) + minutes + timeRange
 		remaining := available - fixed
 		if remaining < maxProject+maxDesc {
 			projectCap := min(maxProject, 35)
@@ -514,66 +905,76 @@ func (m batchSuggestionsModel) View() string {
 		}
 	}
 
-	// Group allocations by date for display
-	type dayGroup struct {
-		date        string
-		allocations []int // indices into the full allocations slice
-		totalMin    int
+	indices := m.dayIndices()
+	totalMin := 0
+	for _, i := range indices {
+		totalMin += m.suggestion.Allocations[i].Minutes
 	}
-	var groups []dayGroup
-	groupMap := make(map[string]int)
-
-	for i, a := range m.suggestion.Allocations {
-		idx, ok := groupMap[a.Date]
-		if !ok {
-			idx = len(groups)
-			groupMap[a.Date] = idx
-			groups = append(groups, dayGroup{date: a.Date})
-		}
-		groups[idx].allocations = append(groups[idx].allocations, i)
-		groups[idx].totalMin += a.Minutes
+	weekday := ""
+	if t, err := time.Parse("2006-01-02", m.date()); err == nil {
+		weekday = t.Weekday().String()[:3]
+	}
+	dayHeader := fmt.Sprintf("%s %s (%d min) — day %d of %d", weekday, m.date(), totalMin, m.page+1, len(m.dates))
+	sb.WriteString(subtitleStyle.Render(dayHeader))
+	switch m.decisions[m.date()] {
+	case dayAccepted:
+		sb.WriteString("  " + successStyle.Render("accepted"))
+	case daySkipped:
+		sb.WriteString("  " + dimStyle.Render("skipped"))
 	}
+	sb.WriteString("\n")
 
-	globalIdx := 0
-	for _, g := range groups {
-		// Parse date for weekday display
-		weekday := ""
-		if t, err := time.Parse("2006-01-02", g.date); err == nil {
-			weekday = t.Weekday().String()[:3]
+	for pos, allocIdx := range indices {
+		r := rowMap[allocIdx]
+		prefix := "  "
+		if pos == m.cursor {
+			prefix = "> "
 		}
 
-		dayHeader := fmt.Sprintf("%s %s (%d min)", weekday, g.date, g.totalMin)
-		sb.WriteString(subtitleStyle.Render(dayHeader))
-		sb.WriteString("\n")
-
-		for _, allocIdx := range g.allocations {
-			r := rowMap[allocIdx]
-			prefix := "  "
-			if globalIdx == m.cursor {
-				prefix = "> "
-			}
-
-			line := fmt.Sprintf("%s%-*s  %*s  %s  %s  %s",
-				prefix,
-				maxProject, r.project,
-				maxMinutes, r.minutes,
-				dimStyle.Render(fmt.Sprintf("%4s", r.confidence)),
-				r.timeRange,
-				r.desc,
-			)
-
-			if globalIdx == m.cursor {
-				line = highlightStyle.Render(line)
+		mark, delta := "", ""
+		if changes != nil {
+			mark = changes[allocIdx].mark() + " "
+			if maxDelta > 0 {
+				delta = "  " + changes[allocIdx].renderDelta(maxDelta)
 			}
+		}
 
-			sb.WriteString(line)
-			sb.WriteString("\n")
-			globalIdx++
+		line := fmt.Sprintf("%s%s%-*s  %*s%s  %s  %s  %s",
+			prefix,
+			mark,
+			maxProject, r.project,
+			maxMinutes, r.minutes,
+			delta,
+			dimStyle.Render(fmt.Sprintf("%4s", r.confidence)),
+			r.timeRange,
+			r.desc,
+		)
+
+		if pos == m.cursor {
+			line = highlightStyle.Render(line)
 		}
+		sb.WriteString(line)
+		sb.WriteString("\n")
 	}
 
+	if m.previous != nil {
+		sb.WriteString("\n")
+		sb.WriteString(renderDiffFooter(changes, removed))
+	}
+
+	accepted, skipped, pending := m.counts()
+	sb.WriteString("\n")
+	sb.WriteString(archivedClientWarning(batchProjectIDs(m.dayAllocations()), m.archived))
+	sb.WriteString(descriptionWarning(m.formatter, batchDescriptions(m.dayAllocations())))
+	sb.WriteString(problemsWarning(m.suggestion.Problems))
+	sb.WriteString(roundingNote(m.rounding))
+	sb.WriteString(dimStyle.Render(fmt.Sprintf("%d accepted, %d skipped, %d to review — entries are logged once every day is decided", accepted, skipped, pending)))
 	sb.WriteString("\n")
-	sb.WriteString(helpStyle.Render("[a]ccept all • [e]dit • [r]etry • [s]kip"))
+	if m.status != "" {
+		sb.WriteString(m.status)
+		sb.WriteString("\n")
+	}
+	sb.WriteString(helpStyle.Render("[a]ccept day • [x] skip day • [g] regenerate day • [e]dit day • [A]ccept all remaining • ←/→ day • [y] copy • [r]etry all • [s]kip all"))
 
 	return boxStyle.Render(sb.String())
 }
@@ -598,6 +999,7 @@ type batchEditModel struct {
 	textInput   textinput.Model
 	editing     bool
 	filtered    []clockify.Project
+	archived    map[string]clockify.Project // projects under an archived client
 }
 
 func newBatchEditModel(allocations []ai.BatchAllocation, projects []clockify.Project) batchEditModel {
@@ -608,6 +1010,7 @@ func newBatchEditModel(allocations []ai.BatchAllocation, projects []clockify.Pro
 	return batchEditModel{
 		allocations: allocations,
 		projects:    projects,
+		archived:    archivedClientProjects(projects),
 		textInput:   ti,
 	}
 }
@@ -767,6 +1170,7 @@ func (m batchEditModel) View() string {
 		sb.WriteString("\n")
 	}
 
+	sb.WriteString(archivedClientWarning(batchProjectIDs(m.allocations), m.archived))
 	sb.WriteString("\n")
 	sb.WriteString(fmt.Sprintf("Field: %s\n", selectedStyle.Render(fieldNames[m.field])))
 
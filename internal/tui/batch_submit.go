@@ -0,0 +1,384 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/christopherklint97/clockr/internal/ai"
+	"github.com/christopherklint97/clockr/internal/clockify"
+	"github.com/christopherklint97/clockr/internal/harvest"
+	"github.com/christopherklint97/clockr/internal/store"
+	"github.com/christopherklint97/clockr/internal/tempo"
+	"github.com/christopherklint97/clockr/internal/toggl"
+)
+
+// batchRetryDelay is the pause before the automatic retry pass over entries
+// that failed to submit.
+var batchRetryDelay = 3 * time.Second
+
+// rollbackState tracks undoing a partially submitted batch.
+type rollbackState int
+
+const (
+	rollbackNone rollbackState = iota
+	rollbackRunning
+	rollbackDone
+)
+
+type batchRollbackMsg struct {
+	deleted int
+	kept    []store.Entry // still in Clockify because deleting them failed
+	errs    []string
+}
+
+// plannedEntries turns accepted allocations into the entries to create.
+func (a *BatchApp) plannedEntries(allocations []ai.BatchAllocation) ([]store.Entry, error) {
+	loc := a.location()
+	var entries []store.Entry
+	for _, alloc := range allocations {
+		start, err := parseBatchTime(alloc.Date, alloc.StartTime, loc)
+		if err != nil {
+			return nil, fmt.Errorf("parsing start time for %s: %w", alloc.Date, err)
+		}
+		end, err := parseBatchTime(alloc.Date, alloc.EndTime, loc)
+		if err != nil {
+			return nil, fmt.Errorf("parsing end time for %s: %w", alloc.Date, err)
+		}
+		if !end.After(start) {
+			// An end at or before the start runs past midnight
+			end = end.AddDate(0, 0, 1)
+		}
+		entry := store.Entry{
+			ProjectID:   alloc.ProjectID,
+			ProjectName: alloc.ProjectName,
+			ClientName:  alloc.ClientName,
+			Description: alloc.Description,
+			StartTime:   start,
+			EndTime:     end,
+			Minutes:     alloc.Minutes,
+			RawInput:    a.input.Value(),
+			Source:      store.SourceBatch,
+			IssueKey:    alloc.IssueKey,
+		}
+		if a.splitMidnight {
+			entries = append(entries, store.SplitAtMidnight(entry)...)
+		} else {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// previewAllocations shows what would be created without touching Clockify
+// or the database.
+func (a *BatchApp) previewAllocations(allocations []ai.BatchAllocation) (tea.Model, tea.Cmd) {
+	a.state = batchConfirmationView
+	planned, err := a.plannedEntries(allocations)
+	if err != nil {
+		a.errMsg = err.Error()
+		return a, nil
+	}
+	a.planned = planned
+	a.result = &Result{}
+	return a, nil
+}
+
+// submitAllocations creates every entry, then retries the ones that failed
+// once. Failed entries are still stored, so 'clockr retry' and the scheduler
+// pick them up later; the result screen reports each entry.
+func (a *BatchApp) submitAllocations(allocations []ai.BatchAllocation) tea.Cmd {
+	return func() tea.Msg {
+		entries, err := a.plannedEntries(allocations)
+		if err != nil {
+			return batchSubmitMsg{err: err}
+		}
+
+		if a.db != nil && a.aiOriginal != nil {
+			id, err := a.db.SaveEntrySuggestion(a.input.Value(), &ai.BatchSuggestion{Allocations: a.aiOriginal}, a.clarifications, ai.PromptVersion(), ai.ModelName(a.provider))
+			if err == nil {
+				for i := range entries {
+					entries[i].SuggestionID = id
+				}
+			}
+		}
+
+		ctx := context.Background()
+		errs := make([]string, len(entries))
+		var retry []int
+		for i := range entries {
+			if err := a.createEntry(ctx, &entries[i]); err != nil {
+				errs[i] = clockify.FriendlyError(err)
+				if retryable(err) {
+					retry = append(retry, i)
+				}
+			}
+			if a.db != nil {
+				a.db.InsertEntry(&entries[i])
+			}
+		}
+
+		if len(retry) > 0 {
+			time.Sleep(batchRetryDelay)
+		}
+		for _, i := range retry {
+			if err := a.createEntry(ctx, &entries[i]); err != nil {
+				errs[i] = clockify.FriendlyError(err)
+				continue
+			}
+			errs[i] = ""
+			if a.db != nil && entries[i].ID != 0 {
+				a.db.UpdateEntryStatus(entries[i].ID, entries[i].Status, entries[i].ClockifyID)
+			}
+		}
+
+		return batchSubmitMsg{entries: entries, errs: errs}
+	}
+}
+
+// createEntry submits e to the backend and sets its status and Clockify ID.
+func (a *BatchApp) createEntry(ctx context.Context, e *store.Entry) error {
+	created, err := a.backend.CreateEntry(ctx, clockify.TimeEntryRequest{
+		Start:       e.StartTime.UTC().Format("2006-01-02T15:04:05Z"),
+		End:         e.EndTime.UTC().Format("2006-01-02T15:04:05Z"),
+		ProjectID:   e.ProjectID,
+		Description: e.Description,
+	})
+	if err != nil {
+		e.Status = "failed"
+		return err
+	}
+	e.Status = "logged"
+	e.ClockifyID = created.ID
+	e.TaskID, e.TagIDs, e.Billable = created.TaskID, created.TagIDs, created.Billable
+	return nil
+}
+
+// retryable reports whether a failed submission may succeed if tried again.
+// The backend rejecting the entry itself (a 4xx other than 429) will not.
+func retryable(err error) bool {
+	var apiErr *clockify.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == 429 || apiErr.StatusCode >= 500
+	}
+	var harvestErr *harvest.APIError
+	if errors.As(err, &harvestErr) {
+		return harvestErr.StatusCode == 429 || harvestErr.StatusCode >= 500
+	}
+	var togglErr *toggl.APIError
+	if errors.As(err, &togglErr) {
+		return togglErr.StatusCode == 429 || togglErr.StatusCode >= 500
+	}
+	var tempoErr *tempo.APIError
+	if errors.As(err, &tempoErr) {
+		return tempoErr.StatusCode == 429 || tempoErr.StatusCode >= 500
+	}
+	return !errors.Is(err, clockify.ErrReadOnly) && !errors.Is(err, harvest.ErrReadOnly) && !errors.Is(err, toggl.ErrReadOnly) && !errors.Is(err, tempo.ErrReadOnly)
+}
+
+// failedCount returns how many submitted entries failed.
+func (a *BatchApp) failedCount() int {
+	n := 0
+	for _, e := range a.submitErrs {
+		if e != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// canRollBack reports whether the batch was only partly logged, so undoing
+// the logged entries is offered.
+func (a *BatchApp) canRollBack() bool {
+	if a.rollback != rollbackNone || a.result == nil {
+		return false
+	}
+	failed := a.failedCount()
+	return failed > 0 && failed < len(a.result.Entries)
+}
+
+// canCopySummary reports whether the confirmation lists logged entries that
+// Ctrl+Y can copy.
+func (a *BatchApp) canCopySummary() bool {
+	if a.errMsg != "" || a.readOnly() || a.rollback != rollbackNone || a.result == nil || (a.dryRun && len(a.planned) > 0) {
+		return false
+	}
+	return a.failedCount() < len(a.result.Entries)
+}
+
+// rollBack deletes the batch's logged entries from the backend and removes the
+// whole batch from the database, so failed entries are not retried either.
+func (a *BatchApp) rollBack() tea.Cmd {
+	entries := a.result.Entries
+	return func() tea.Msg {
+		ctx := context.Background()
+		var msg batchRollbackMsg
+		for _, e := range entries {
+			if e.ClockifyID != "" {
+				if err := a.backend.DeleteEntry(ctx, e.ClockifyID); err != nil {
+					msg.kept = append(msg.kept, e)
+					msg.errs = append(msg.errs, fmt.Sprintf("%s — %s: %v", e.ProjectName, e.Description, err))
+					continue
+				}
+				msg.deleted++
+			}
+			if a.db != nil && e.ID != 0 {
+				a.db.DeleteEntry(e.ID)
+			}
+		}
+		return msg
+	}
+}
+
+func (a *BatchApp) handleRollback(msg batchRollbackMsg) (tea.Model, tea.Cmd) {
+	a.rollback = rollbackDone
+	a.result = &Result{Entries: msg.kept}
+	a.rollbackErrs = msg.errs
+	return a, nil
+}
+
+func (a *BatchApp) confirmationView() string {
+	if a.dryRun && len(a.planned) > 0 {
+		return a.dryRunView()
+	}
+	if a.readOnly() {
+		return warningStyle.Render("Read-only mode — suggestions previewed, nothing was logged.") + "\n\n" + helpStyle.Render("Press any key to exit")
+	}
+	switch a.rollback {
+	case rollbackRunning:
+		return a.spinner.View() + " Rolling back..."
+	case rollbackDone:
+		return a.rollbackView()
+	}
+	if a.result == nil || len(a.result.Entries) == 0 {
+		return successStyle.Render("No entries to log.") + "\n\n" + helpStyle.Render("Press any key to exit")
+	}
+
+	entries := a.result.Entries
+	failed := a.failedCount()
+
+	var sb strings.Builder
+	if failed == 0 {
+		sb.WriteString(successStyle.Render(fmt.Sprintf("Logged %d entries across %d days!", len(entries), len(entryDates(entries)))))
+	} else {
+		sb.WriteString(warningStyle.Render(fmt.Sprintf("Logged %d of %d entries — %d failed.", len(entries)-failed, len(entries), failed)))
+	}
+	sb.WriteString("\n\n")
+	a.writeEntryList(&sb, entries, a.submitErrs)
+	if len(a.skippedDays) > 0 {
+		sb.WriteString(dimStyle.Render(fmt.Sprintf("  Skipped: %s", strings.Join(a.skippedDays, ", "))))
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("\n")
+	if failed > 0 {
+		sb.WriteString(dimStyle.Render("Failed entries are kept and retried by 'clockr retry' and the scheduler."))
+		sb.WriteString("\n")
+	}
+	if a.copied != "" {
+		sb.WriteString(a.copied + "\n")
+	}
+	var keys []string
+	if a.canRollBack() {
+		keys = append(keys, "u: roll back the logged entries")
+	}
+	if a.canCopySummary() {
+		keys = append(keys, "Ctrl+Y: copy a summary")
+	}
+	if len(keys) > 0 {
+		sb.WriteString(helpStyle.Render(strings.Join(keys, " • ") + " • any other key: exit"))
+	} else {
+		sb.WriteString(helpStyle.Render("Press any key to exit"))
+	}
+	return sb.String()
+}
+
+func (a *BatchApp) dryRunView() string {
+	var sb strings.Builder
+	sb.WriteString(warningStyle.Render(fmt.Sprintf("Dry run — would log %d entries across %d days. Nothing was sent to Clockify.",
+		len(a.planned), len(entryDates(a.planned)))))
+	sb.WriteString("\n\n")
+	a.writeEntryList(&sb, a.planned, nil)
+	if len(a.skippedDays) > 0 {
+		sb.WriteString(dimStyle.Render(fmt.Sprintf("  Skipped: %s", strings.Join(a.skippedDays, ", "))))
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n")
+	sb.WriteString(helpStyle.Render("Press any key to exit"))
+	return sb.String()
+}
+
+func (a *BatchApp) rollbackView() string {
+	var sb strings.Builder
+	if len(a.rollbackErrs) == 0 {
+		sb.WriteString(successStyle.Render("Rolled back — nothing from this batch is left in Clockify."))
+	} else {
+		sb.WriteString(warningStyle.Render(fmt.Sprintf("Rolled back partly — %d entries could not be deleted:", len(a.rollbackErrs))))
+		sb.WriteString("\n")
+		for _, e := range a.rollbackErrs {
+			sb.WriteString(errorStyle.Render("  ✗ " + truncate(e, max(a.termWidth-4, 40))))
+			sb.WriteString("\n")
+		}
+	}
+	sb.WriteString("\n\n")
+	sb.WriteString(helpStyle.Render("Press any key to exit"))
+	return sb.String()
+}
+
+// writeEntryList lists entries by day. errs, when set, marks each entry as
+// logged or failed with its error.
+func (a *BatchApp) writeEntryList(sb *strings.Builder, entries []store.Entry, errs []string) {
+	width := max(a.termWidth-8, 40)
+	for _, d := range a.days {
+		var lines []string
+		count, minutes := 0, 0
+		for i, e := range entries {
+			if a.dayFor(e) != d.Date {
+				continue
+			}
+			count++
+			minutes += e.Minutes
+			line := fmt.Sprintf("%s–%s  %s — %s (%d min)",
+				e.StartTime.Format("15:04"), e.EndTime.Format("15:04"), e.ProjectName, e.Description, e.Minutes)
+			switch {
+			case errs == nil:
+				lines = append(lines, "    • "+line)
+			case errs[i] == "":
+				lines = append(lines, "    "+successStyle.Render("✓")+" "+line)
+			default:
+				lines = append(lines, "    "+errorStyle.Render("✗")+" "+line,
+					"      "+errorStyle.Render(truncate(errs[i], width)))
+			}
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		fmt.Fprintf(sb, "  %s %s: %d entries, %d min\n", d.Date, d.Weekday, count, minutes)
+		sb.WriteString(strings.Join(lines, "\n"))
+		sb.WriteString("\n")
+	}
+}
+
+// dayFor returns the date of the day slot e belongs to: its own date, or the
+// latest earlier slot when it is the part of an entry after midnight.
+func (a *BatchApp) dayFor(e store.Entry) string {
+	date := e.StartTime.Format("2006-01-02")
+	for i := len(a.days) - 1; i >= 0; i-- {
+		if a.days[i].Date <= date {
+			return a.days[i].Date
+		}
+	}
+	return date
+}
+
+// entryDates returns the distinct dates of entries.
+func entryDates(entries []store.Entry) map[string]bool {
+	dates := make(map[string]bool)
+	for _, e := range entries {
+		dates[e.StartTime.Format("2006-01-02")] = true
+	}
+	return dates
+}
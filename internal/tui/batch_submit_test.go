@@ -0,0 +1,120 @@
+package tui
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/ai"
+	"github.com/christopherklint97/clockr/internal/backend"
+	"github.com/christopherklint97/clockr/internal/clockify"
+)
+
+// fakeClockify accepts time entries except for project "bad", which it
+// rejects with a 400, and records deleted entry IDs.
+func fakeClockify(t *testing.T) (*clockify.Client, *[]string) {
+	t.Helper()
+	var deleted []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			var req clockify.TimeEntryRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			if req.ProjectID == "bad" {
+				http.Error(w, `{"message":"project archived"}`, http.StatusBadRequest)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]string{"id": "te-" + req.ProjectID})
+		case http.MethodDelete:
+			deleted = append(deleted, r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:])
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return clockify.NewClient("key", srv.URL, time.Hour, logger), &deleted
+}
+
+func testBatchApp(client *clockify.Client) *BatchApp {
+	day := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	days := []ai.DaySlot{{Date: "2026-03-02", Weekday: "Monday", Start: day, End: day.Add(8 * time.Hour), Minutes: 480}}
+	return NewBatchApp(days, nil, nil, backend.Clockify(client, "ws"), nil, "")
+}
+
+var partialBatch = []ai.BatchAllocation{
+	{Date: "2026-03-02", StartTime: "09:00", EndTime: "13:00", ProjectID: "p1", ProjectName: "Alpha", Minutes: 240, Description: "Review"},
+	{Date: "2026-03-02", StartTime: "13:00", EndTime: "17:00", ProjectID: "bad", ProjectName: "Old", Minutes: 240, Description: "Build"},
+}
+
+func TestBatchSubmit_ReportsEachEntry(t *testing.T) {
+	batchRetryDelay = 0
+	client, _ := fakeClockify(t)
+	a := testBatchApp(client)
+
+	a.handleSubmit(a.submitAllocations(partialBatch)().(batchSubmitMsg))
+
+	entries := a.result.Entries
+	if len(entries) != 2 || entries[0].Status != "logged" || entries[1].Status != "failed" {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+	if a.submitErrs[0] != "" || !strings.Contains(a.submitErrs[1], "archived") {
+		t.Errorf("unexpected errors %q", a.submitErrs)
+	}
+	view := a.confirmationView()
+	if !strings.Contains(view, "Logged 1 of 2 entries") || !strings.Contains(view, "roll back") {
+		t.Errorf("result screen should report the partial batch:\n%s", view)
+	}
+}
+
+func TestBatchSubmit_RollBack(t *testing.T) {
+	batchRetryDelay = 0
+	client, deleted := fakeClockify(t)
+	a := testBatchApp(client)
+	a.handleSubmit(a.submitAllocations(partialBatch)().(batchSubmitMsg))
+
+	if !a.canRollBack() {
+		t.Fatal("expected rollback to be offered")
+	}
+	a.handleRollback(a.rollBack()().(batchRollbackMsg))
+
+	if len(*deleted) != 1 || (*deleted)[0] != "te-p1" {
+		t.Errorf("deleted = %v, want [te-p1]", *deleted)
+	}
+	if len(a.result.Entries) != 0 || a.canRollBack() {
+		t.Errorf("nothing should be left after rolling back: %+v", a.result.Entries)
+	}
+}
+
+func TestBatchSubmit_DryRun(t *testing.T) {
+	client, _ := fakeClockify(t)
+	client.SetReadOnly(true)
+	a := testBatchApp(client)
+	a.SetDryRun(true)
+
+	a.previewAllocations(partialBatch)
+
+	if len(a.result.Entries) != 0 || len(a.planned) != 2 {
+		t.Fatalf("dry run should plan entries without logging them: %+v", a.result)
+	}
+	if view := a.confirmationView(); !strings.Contains(view, "Dry run — would log 2 entries") {
+		t.Errorf("unexpected dry-run view:\n%s", view)
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	if retryable(&clockify.APIError{StatusCode: 400}) {
+		t.Error("a 400 should not be retried")
+	}
+	if !retryable(&clockify.APIError{StatusCode: 503}) || !retryable(errors.New("connection reset")) {
+		t.Error("server and transport errors should be retried")
+	}
+	if retryable(clockify.ErrReadOnly) {
+		t.Error("read-only mode should not be retried")
+	}
+}
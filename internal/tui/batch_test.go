@@ -0,0 +1,90 @@
+package tui
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/christopherklint97/clockr/internal/ai"
+)
+
+func testBatchSuggestion() *ai.BatchSuggestion {
+	return &ai.BatchSuggestion{Allocations: []ai.BatchAllocation{
+		{Date: "2026-03-02", ProjectID: "p1", ProjectName: "Alpha", Minutes: 240, Description: "Review"},
+		{Date: "2026-03-02", ProjectID: "p2", ProjectName: "Beta", Minutes: 240, Description: "Build"},
+		{Date: "2026-03-03", ProjectID: "p1", ProjectName: "Alpha", Minutes: 480, Description: "Review"},
+		{Date: "2026-03-04", ProjectID: "p2", ProjectName: "Beta", Minutes: 480, Description: "Build"},
+	}}
+}
+
+func TestBatchSuggestions_DecideDays(t *testing.T) {
+	m := newBatchSuggestionsModel(testBatchSuggestion(), nil)
+	if len(m.dates) != 3 || m.date() != "2026-03-02" {
+		t.Fatalf("unexpected dates %v", m.dates)
+	}
+	if len(m.dayIndices()) != 2 {
+		t.Errorf("first day should have 2 allocations, got %d", len(m.dayIndices()))
+	}
+
+	// Skip the middle day first, then accept the rest in order.
+	m.setPage(1)
+	if m.decide(daySkipped) {
+		t.Fatal("decide reported done with days pending")
+	}
+	if m.date() != "2026-03-04" {
+		t.Errorf("expected to move to the next pending day, got %s", m.date())
+	}
+	if m.decide(dayAccepted) {
+		t.Fatal("decide reported done with a day pending")
+	}
+	if m.date() != "2026-03-02" {
+		t.Errorf("expected to wrap to the first pending day, got %s", m.date())
+	}
+	if !m.decide(dayAccepted) {
+		t.Fatal("expected every day to be decided")
+	}
+
+	var dates []string
+	for _, a := range m.accepted() {
+		dates = append(dates, a.Date)
+	}
+	if want := []string{"2026-03-02", "2026-03-02", "2026-03-04"}; !slices.Equal(dates, want) {
+		t.Errorf("accepted dates = %v, want %v", dates, want)
+	}
+	if got := m.skippedDates(); !slices.Equal(got, []string{"2026-03-03"}) {
+		t.Errorf("skipped = %v", got)
+	}
+}
+
+func TestBatchSuggestions_ReplaceDay(t *testing.T) {
+	m := newBatchSuggestionsModel(testBatchSuggestion(), nil)
+	m.replaceDay("2026-03-03", []ai.BatchAllocation{
+		{Date: "2026-03-03", ProjectID: "p2", ProjectName: "Beta", Minutes: 300, Description: "Build"},
+		{Date: "2026-03-03", ProjectID: "p3", ProjectName: "Gamma", Minutes: 180, Description: "Docs"},
+		{Date: "2026-03-09", ProjectID: "p3", ProjectName: "Gamma", Minutes: 60, Description: "Stray"},
+	})
+
+	var got []string
+	for _, a := range m.suggestion.Allocations {
+		got = append(got, a.Date+" "+a.ProjectID)
+	}
+	want := []string{"2026-03-02 p1", "2026-03-02 p2", "2026-03-03 p2", "2026-03-03 p3", "2026-03-04 p2"}
+	if !slices.Equal(got, want) {
+		t.Errorf("allocations = %v, want %v", got, want)
+	}
+	if m.date() != "2026-03-03" {
+		t.Errorf("expected the replaced day to be shown, got %s", m.date())
+	}
+}
+
+func TestBatchSuggestions_ViewShowsOneDay(t *testing.T) {
+	m := newBatchSuggestionsModel(testBatchSuggestion(), nil)
+	m.setPage(1)
+	view := m.View()
+	if !strings.Contains(view, "day 2 of 3") || !strings.Contains(view, "480min") {
+		t.Errorf("expected the second day, got:\n%s", view)
+	}
+	if strings.Contains(view, "240min") {
+		t.Errorf("view should not include other days' allocations:\n%s", view)
+	}
+}
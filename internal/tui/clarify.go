@@ -0,0 +1,77 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/christopherklint97/clockr/internal/ai"
+)
+
+// clarifyModel is the follow-up box for answering the AI's clarification
+// question. The original description is not touched; the answer is added to
+// the conversation for the next call.
+type clarifyModel struct {
+	question    string
+	history     []ai.Exchange
+	input       textinput.Model
+	hidden      int  // projects the AI was not shown
+	allProjects bool // the answer was sent with Ctrl+A: show the AI every project
+}
+
+func newClarifyModel(question string, history []ai.Exchange, hidden int) clarifyModel {
+	ti := textinput.New()
+	ti.Placeholder = "Your answer..."
+	ti.CharLimit = 0
+	ti.Width = 70
+	ti.Focus()
+	return clarifyModel{question: question, history: history, input: ti, hidden: hidden}
+}
+
+// Update handles typing. It returns the exchange and true once the user
+// submits a non-empty answer with Enter. Ctrl+A also submits, with or
+// without an answer, and sets allProjects when projects were left out.
+func (m clarifyModel) Update(msg tea.Msg) (clarifyModel, ai.Exchange, bool, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && keyMsg.String() == "ctrl+a" && m.hidden > 0 {
+		m.allProjects = true
+		answer := strings.TrimSpace(m.input.Value())
+		if answer == "" {
+			return m, ai.Exchange{}, true, nil
+		}
+		return m, ai.Exchange{Question: m.question, Answer: answer}, true, nil
+	}
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && keyMsg.String() == "enter" {
+		answer := strings.TrimSpace(m.input.Value())
+		if answer == "" {
+			return m, ai.Exchange{}, false, nil
+		}
+		return m, ai.Exchange{Question: m.question, Answer: answer}, true, nil
+	}
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, ai.Exchange{}, false, cmd
+}
+
+func (m clarifyModel) View() string {
+	var sb strings.Builder
+	sb.WriteString(titleStyle.Render("Answer the AI"))
+	sb.WriteString("\n")
+	for _, e := range m.history {
+		sb.WriteString(dimStyle.Render("Q: " + e.Question))
+		sb.WriteString("\n")
+		sb.WriteString(dimStyle.Render("A: " + e.Answer))
+		sb.WriteString("\n")
+	}
+	sb.WriteString(warningStyle.Render("Q: "))
+	sb.WriteString(m.question)
+	sb.WriteString("\n\n")
+	sb.WriteString(m.input.View())
+	sb.WriteString("\n\n")
+	if m.hidden > 0 {
+		sb.WriteString(helpStyle.Render(fmt.Sprintf("Enter: send • Ctrl+A: send with all projects (%d more) • Esc: back", m.hidden)))
+	} else {
+		sb.WriteString(helpStyle.Render("Enter: send • Esc: back"))
+	}
+	return sb.String()
+}
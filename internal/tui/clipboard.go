@@ -0,0 +1,61 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/christopherklint97/clockr/internal/clipboard"
+	"github.com/christopherklint97/clockr/internal/store"
+)
+
+// clipboardMsg reports the result of a copy started by copyCmd.
+type clipboardMsg struct {
+	what string // e.g. "Description", for the status line
+	err  error
+}
+
+func copyCmd(what, text string) tea.Cmd {
+	return func() tea.Msg {
+		return clipboardMsg{what: what, err: clipboard.Copy(text)}
+	}
+}
+
+func clipboardStatus(msg clipboardMsg) string {
+	if msg.err != nil {
+		return errorStyle.Render("Copy failed: " + msg.err.Error())
+	}
+	return successStyle.Render(msg.what + " copied to clipboard")
+}
+
+// pasteMsg carries the clipboard text read by pasteCmd.
+type pasteMsg struct {
+	text string
+	err  error
+}
+
+// pasteCmd reads the clipboard with the system tools, which keep line breaks
+// that some terminals lose when pasting into the text area.
+func pasteCmd() tea.Msg {
+	text, err := clipboard.Paste()
+	return pasteMsg{text: text, err: err}
+}
+
+// entriesSummary lists the logged entries for pasting into standup notes,
+// one "- 09:00–10:00 Project: Description (60 min)" line each. Entries with
+// an error in errs are left out. The day is shown when they span several.
+func entriesSummary(entries []store.Entry, errs []string) string {
+	var lines []string
+	multiDay := spansDays(entries)
+	for i, e := range entries {
+		if i < len(errs) && errs[i] != "" {
+			continue
+		}
+		when := e.StartTime.Format("15:04") + "–" + e.EndTime.Format("15:04")
+		if multiDay {
+			when = e.StartTime.Format("Mon Jan 2 ") + when
+		}
+		lines = append(lines, fmt.Sprintf("- %s %s: %s (%d min)", when, e.ProjectName, e.Description, e.Minutes))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,43 @@
+package tui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/store"
+)
+
+func TestEntriesSummary(t *testing.T) {
+	day := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	entries := []store.Entry{
+		{StartTime: day, EndTime: day.Add(time.Hour), ProjectName: "Platform", Description: "Fixed login", Minutes: 60},
+		{StartTime: day.Add(time.Hour), EndTime: day.Add(90 * time.Minute), ProjectName: "Meetings", Description: "Planning", Minutes: 30},
+	}
+	want := "- 09:00–10:00 Platform: Fixed login (60 min)"
+	if got := entriesSummary(entries, []string{"", "rejected"}); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	entries[1].StartTime = day.AddDate(0, 0, 1)
+	entries[1].EndTime = entries[1].StartTime.Add(30 * time.Minute)
+	got := entriesSummary(entries, nil)
+	if !strings.HasPrefix(got, "- Mon Mar 2 09:00–10:00 Platform") || !strings.Contains(got, "\n- Tue Mar 3 ") {
+		t.Errorf("multi-day summary should name the days, got %q", got)
+	}
+}
+
+func TestInputModel_Paste(t *testing.T) {
+	m := newInputModel("")
+	m.textarea.SetValue("Worked on:\n")
+	m, _ = m.Update(pasteMsg{text: "- auth\n- billing"})
+	if m.Value() != "Worked on:\n- auth\n- billing" {
+		t.Errorf("got %q, want the lines kept", m.Value())
+	}
+
+	m, _ = m.Update(pasteMsg{err: errors.New("no clipboard tool found")})
+	if !strings.Contains(m.View(), "Paste failed") {
+		t.Error("a failed paste should be shown")
+	}
+}
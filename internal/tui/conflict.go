@@ -0,0 +1,172 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Sides of a conflicting field.
+const (
+	ChooseLocal  = "local"
+	ChooseRemote = "remote"
+	ChooseMerged = "merged"
+)
+
+// ConflictField is one field that differs between the local entry and
+// Clockify. Editable fields can also take a hand-merged value.
+type ConflictField struct {
+	Name     string
+	Local    string
+	Remote   string
+	Editable bool
+}
+
+// ConflictChoice is the side picked for a field, and the value for a merge.
+type ConflictChoice struct {
+	Field  string
+	Chosen string
+	Value  string
+}
+
+type ConflictResult struct {
+	Choices []ConflictChoice // one per field, in order; empty when skipped or quit
+	Skipped bool
+	Quit    bool
+}
+
+type conflictModel struct {
+	title   string
+	fields  []ConflictField
+	choices []ConflictChoice
+	cursor  int
+
+	editing bool
+	input   textinput.Model
+
+	result   *ConflictResult
+	quitting bool
+}
+
+// NewConflictApp shows fields side by side and lets the user pick the local
+// or Clockify value of each. Clockify's value is picked to start with.
+func NewConflictApp(title string, fields []ConflictField) *conflictModel {
+	m := &conflictModel{title: title, fields: fields}
+	for _, f := range fields {
+		m.choices = append(m.choices, ConflictChoice{Field: f.Name, Chosen: ChooseRemote, Value: f.Remote})
+	}
+	return m
+}
+
+func (m *conflictModel) GetResult() *ConflictResult {
+	return m.result
+}
+
+func (m *conflictModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *conflictModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	if m.editing {
+		return m.updateEditing(keyMsg)
+	}
+
+	switch keyMsg.String() {
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(m.fields)-1 {
+			m.cursor++
+		}
+	case "left", "h":
+		m.choices[m.cursor] = ConflictChoice{Field: m.fields[m.cursor].Name, Chosen: ChooseLocal, Value: m.fields[m.cursor].Local}
+	case "right", "l":
+		m.choices[m.cursor] = ConflictChoice{Field: m.fields[m.cursor].Name, Chosen: ChooseRemote, Value: m.fields[m.cursor].Remote}
+	case "e":
+		if m.fields[m.cursor].Editable {
+			m.input = textinput.New()
+			m.input.SetValue(m.choices[m.cursor].Value)
+			m.input.Width = 60
+			m.editing = true
+			return m, m.input.Focus()
+		}
+	case "enter":
+		return m.finish(&ConflictResult{Choices: m.choices})
+	case "s":
+		return m.finish(&ConflictResult{Skipped: true})
+	case "q", "esc", "ctrl+c":
+		return m.finish(&ConflictResult{Quit: true})
+	}
+	return m, nil
+}
+
+func (m *conflictModel) updateEditing(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		m.editing = false
+		m.choices[m.cursor] = ConflictChoice{Field: m.fields[m.cursor].Name, Chosen: ChooseMerged, Value: strings.TrimSpace(m.input.Value())}
+		return m, nil
+	case "esc":
+		m.editing = false
+		return m, nil
+	case "ctrl+c":
+		return m.finish(&ConflictResult{Quit: true})
+	}
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+func (m *conflictModel) finish(r *ConflictResult) (tea.Model, tea.Cmd) {
+	m.result = r
+	m.quitting = true
+	return m, tea.Quit
+}
+
+func (m *conflictModel) View() string {
+	if m.quitting {
+		return ""
+	}
+
+	s := titleStyle.Render("clockr — Resolve conflict") + "\n"
+	s += dimStyle.Render(m.title) + "\n\n"
+
+	side := func(value string, picked bool) string {
+		cell := fmt.Sprintf("%-30s", truncate(value, 30))
+		if picked {
+			return selectedStyle.Render("● " + cell)
+		}
+		return dimStyle.Render("○ " + cell)
+	}
+	s += dimStyle.Render(fmt.Sprintf("  %-12s   %-30s   %s", "", "clockr", "Clockify")) + "\n"
+	for i, f := range m.fields {
+		c := m.choices[i]
+		name := fmt.Sprintf("%-12s", f.Name)
+		if i == m.cursor {
+			name = highlightStyle.Render("> " + name)
+		} else {
+			name = "  " + name
+		}
+		s += fmt.Sprintf("%s %s %s\n", name, side(f.Local, c.Chosen == ChooseLocal), side(f.Remote, c.Chosen == ChooseRemote))
+		if c.Chosen == ChooseMerged {
+			s += "               " + selectedStyle.Render("● merged: "+c.Value) + "\n"
+		}
+	}
+
+	s += "\n"
+	if m.editing {
+		s += m.input.View() + "\n"
+		s += helpStyle.Render("enter keep merged value • esc cancel")
+		return s
+	}
+	s += helpStyle.Render("↑/↓ field • ←/→ clockr/Clockify • e merge by hand • enter apply • s skip • q quit")
+	return s
+}
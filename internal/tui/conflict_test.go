@@ -0,0 +1,53 @@
+package tui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func conflictFields() []ConflictField {
+	return []ConflictField{
+		{Name: "project", Local: "Acme", Remote: "Internal"},
+		{Name: "description", Local: "Review", Remote: "Review PRs", Editable: true},
+	}
+}
+
+func TestConflictApp_PicksPerField(t *testing.T) {
+	m := NewConflictApp("entry", conflictFields())
+	m.Update(tea.KeyMsg{Type: tea.KeyLeft}) // project: keep clockr's
+	m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("e")})
+	m.input.SetValue("Review PRs for auth")
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter}) // keep the merged text
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	r := m.GetResult()
+	if r == nil || cmd == nil {
+		t.Fatal("expected a result and quit after enter")
+	}
+	want := []ConflictChoice{
+		{Field: "project", Chosen: ChooseLocal, Value: "Acme"},
+		{Field: "description", Chosen: ChooseMerged, Value: "Review PRs for auth"},
+	}
+	if len(r.Choices) != len(want) {
+		t.Fatalf("choices = %+v", r.Choices)
+	}
+	for i := range want {
+		if r.Choices[i] != want[i] {
+			t.Errorf("choice %d = %+v, want %+v", i, r.Choices[i], want[i])
+		}
+	}
+}
+
+func TestConflictApp_NotEditableAndSkip(t *testing.T) {
+	m := NewConflictApp("entry", conflictFields())
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("e")})
+	if m.editing {
+		t.Error("project should not be editable")
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	if r := m.GetResult(); r == nil || !r.Skipped || len(r.Choices) != 0 {
+		t.Errorf("result = %+v, want skipped", r)
+	}
+}
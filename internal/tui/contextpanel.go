@@ -0,0 +1,143 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/christopherklint97/clockr/internal/ai"
+	"github.com/christopherklint97/clockr/internal/calendar"
+)
+
+// contextPanelModel lists the context that will be sent to the AI with the
+// description, so a declined meeting or an unrelated commit can be left out.
+// It is collapsed to a one-line summary until opened with Ctrl+X.
+type contextPanelModel struct {
+	items    []ai.ContextItem
+	excluded []bool
+	fetching bool // the startup fetch may still add items
+	open     bool
+	cursor   int
+}
+
+// withItems shows items, keeping what was excluded among those already
+// listed; later items are only ever appended.
+func (m contextPanelModel) withItems(items []ai.ContextItem) contextPanelModel {
+	excluded := make([]bool, len(items))
+	copy(excluded, m.excluded)
+	m.items = items
+	m.excluded = excluded
+	return m
+}
+
+// Included returns the items the user has not excluded.
+func (m contextPanelModel) Included() []ai.ContextItem {
+	var out []ai.ContextItem
+	for i, item := range m.items {
+		if !m.excluded[i] {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// excludes reports whether e is a meeting the user excluded, so the window
+// is not split at it either. Items are matched to the event they were made
+// from, by its summary, start and length, whatever part of it falls in the
+// window.
+func (m contextPanelModel) excludes(e calendar.Event) bool {
+	minutes := int(e.EndTime.Sub(e.StartTime).Minutes())
+	for i, item := range m.items {
+		if m.excluded[i] && item.Source == ai.SourceCalendar && item.Text == e.Summary && item.Time.Equal(e.StartTime) && item.Minutes == minutes {
+			return true
+		}
+	}
+	return false
+}
+
+// Update handles keys while the panel is open. Space toggles the selected
+// item; esc, enter or Ctrl+X closes the panel.
+func (m contextPanelModel) Update(msg tea.KeyMsg) contextPanelModel {
+	switch msg.String() {
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(m.items)-1 {
+			m.cursor++
+		}
+	case " ", "x":
+		m.excluded[m.cursor] = !m.excluded[m.cursor]
+	case "a":
+		all := !m.excluded[m.cursor]
+		for i := range m.excluded {
+			m.excluded[i] = all
+		}
+	case "esc", "enter", "ctrl+x":
+		m.open = false
+	}
+	return m
+}
+
+// Summary is the collapsed line shown above the text area, e.g.
+// "Context: 2 calendar, 3 commit (1 excluded)".
+func (m contextPanelModel) Summary() string {
+	if len(m.items) == 0 {
+		if m.fetching {
+			return "Context: fetching…"
+		}
+		return ""
+	}
+	var order []string
+	counts := map[string]int{}
+	for _, item := range m.items {
+		source := item.Source
+		if source == "" {
+			source = "other"
+		}
+		if counts[source] == 0 {
+			order = append(order, source)
+		}
+		counts[source]++
+	}
+	parts := make([]string, len(order))
+	for i, source := range order {
+		parts[i] = fmt.Sprintf("%d %s", counts[source], source)
+	}
+	line := "Context: " + strings.Join(parts, ", ")
+	if n := len(m.items) - len(m.Included()); n > 0 {
+		line += fmt.Sprintf(" (%d excluded)", n)
+	}
+	if m.fetching {
+		line += ", fetching more…"
+	}
+	return line
+}
+
+func (m contextPanelModel) View(width int) string {
+	var b strings.Builder
+	b.WriteString(highlightStyle.Render("Context sent to the AI") + "\n\n")
+	width = max(width-10, 20)
+	for i, item := range m.items {
+		box := "[x]"
+		if m.excluded[i] {
+			box = "[ ]"
+		}
+		line := item.String()
+		if r := []rune(line); len(r) > width {
+			line = string(r[:width-1]) + "…"
+		}
+		switch {
+		case i == m.cursor:
+			b.WriteString(selectedStyle.Render(fmt.Sprintf("> %s %s", box, line)) + "\n")
+		case m.excluded[i]:
+			b.WriteString(dimStyle.Render(fmt.Sprintf("  %s %s", box, line)) + "\n")
+		default:
+			b.WriteString(fmt.Sprintf("  %s %s\n", box, line))
+		}
+	}
+	b.WriteString(helpStyle.Render("↑/↓: select • Space: include/exclude • a: toggle all • Esc: back"))
+	return b.String()
+}
@@ -0,0 +1,100 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/christopherklint97/clockr/internal/ai"
+	"github.com/christopherklint97/clockr/internal/calendar"
+	"github.com/christopherklint97/clockr/internal/sources"
+)
+
+func TestInputModel_ContextPanel(t *testing.T) {
+	standup := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	m := newInputModel("09:00 – 10:00 (60 min)")
+	m.context = m.context.withItems([]ai.ContextItem{
+		{Source: ai.SourceCalendar, Text: "Standup", Time: standup, Minutes: 15},
+		{Source: ai.SourceCommit, Text: "api: Fix login"},
+	})
+	if got := m.context.Summary(); got != "Context: 1 calendar, 1 commit" {
+		t.Errorf("summary = %q", got)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlX})
+	if !m.Picking() {
+		t.Fatal("Ctrl+X should open the context panel")
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.Picking() {
+		t.Fatal("esc should close the context panel")
+	}
+
+	included := m.context.Included()
+	if len(included) != 1 || included[0].Text != "api: Fix login" {
+		t.Errorf("included = %v, want only the commit", included)
+	}
+	if !strings.Contains(m.context.Summary(), "(1 excluded)") {
+		t.Errorf("summary = %q, want the exclusion counted", m.context.Summary())
+	}
+	if !m.context.excludes(calendar.Event{Summary: "Standup", StartTime: standup, EndTime: standup.Add(15 * time.Minute)}) {
+		t.Error("an excluded meeting should not split the window")
+	}
+
+	// Items fetched later keep the earlier choice.
+	m.context = m.context.withItems(append(m.context.items, ai.ContextItem{Source: "jira", Text: "ABC-1"}))
+	if included := m.context.Included(); len(included) != 2 {
+		t.Errorf("included after more items = %v", included)
+	}
+}
+
+func TestApp_ExcludedMeetingBeforeWindow(t *testing.T) {
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.Local)
+	end := start.Add(time.Hour)
+	// Planning started before the window, so its segment would begin at
+	// 09:00, not at its own start.
+	events := []calendar.Event{
+		{Summary: "Planning", StartTime: start.Add(-30 * time.Minute), EndTime: start.Add(30 * time.Minute)},
+		{Summary: "Review", StartTime: start.Add(40 * time.Minute), EndTime: end},
+	}
+	app := NewApp(start, end, nil, nil, nil, nil, time.Hour, sources.CalendarItems(events, time.Local), "")
+	app.SetMeetings(events)
+	if segs := app.segments(); len(segs) != 3 {
+		t.Fatalf("segments = %+v, want 3", segs)
+	}
+
+	app.input.context.excluded[0] = true
+	segs := app.segments()
+	if len(segs) != 2 || segs[0].Meeting != "" || segs[1].Meeting != "Review" {
+		t.Errorf("segments with Planning excluded = %+v, want free time then Review", segs)
+	}
+}
+
+func TestInputModel_ContextPrefill(t *testing.T) {
+	m := newInputModel("")
+	m.context = m.context.withItems([]ai.ContextItem{
+		{Source: ai.SourceCalendar, Text: "Standup", Minutes: 15},
+		{Source: ai.SourceCommit, Text: "api: Fix login"},
+		{Source: ai.SourceCalendar, Text: "Declined sync", Minutes: 30},
+	})
+	m.context.excluded[2] = true
+	m.textarea.SetValue("Worked on auth:")
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlP})
+	if want := "Worked on auth:\n- Standup (15 min)\n- api: Fix login"; m.Value() != want {
+		t.Errorf("got %q, want %q", m.Value(), want)
+	}
+}
+
+func TestInputModel_NoContext(t *testing.T) {
+	m := newInputModel("")
+	if m, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlX}); m.Picking() {
+		t.Error("Ctrl+X opened an empty context panel")
+	}
+	if got := m.context.Summary(); got != "" {
+		t.Errorf("summary = %q, want none", got)
+	}
+}
@@ -0,0 +1,145 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/christopherklint97/clockr/internal/ai"
+)
+
+// changeKind describes how an allocation differs from the previous run.
+type changeKind int
+
+const (
+	unchanged changeKind = iota
+	added                // project not in the previous run
+	changed              // same project, different minutes or description
+)
+
+// diffRow is the part of an allocation compared between runs. key identifies
+// the same allocation across runs (the project, plus the date in batch mode).
+type diffRow struct {
+	key     string
+	label   string
+	minutes int
+	desc    string
+}
+
+// allocChange is the diff result for one allocation of the new run.
+type allocChange struct {
+	kind  changeKind
+	delta int // minutes relative to the previous run
+}
+
+// diffRows matches each row of cur to the first unused row of prev with the
+// same key. It returns one change per cur row, and the prev rows that were
+// not matched.
+func diffRows(prev, cur []diffRow) ([]allocChange, []diffRow) {
+	used := make([]bool, len(prev))
+	changes := make([]allocChange, len(cur))
+	for i, c := range cur {
+		changes[i] = allocChange{kind: added}
+		for j, p := range prev {
+			if used[j] || p.key != c.key {
+				continue
+			}
+			used[j] = true
+			changes[i].delta = c.minutes - p.minutes
+			if changes[i].delta == 0 && c.desc == p.desc {
+				changes[i].kind = unchanged
+			} else {
+				changes[i].kind = changed
+			}
+			break
+		}
+	}
+
+	var removed []diffRow
+	for j, p := range prev {
+		if !used[j] {
+			removed = append(removed, p)
+		}
+	}
+	return changes, removed
+}
+
+func allocationRows(allocs []ai.Allocation) []diffRow {
+	rows := make([]diffRow, len(allocs))
+	for i, a := range allocs {
+		rows[i] = diffRow{key: a.ProjectID, label: a.ProjectName, minutes: a.Minutes, desc: a.Description}
+	}
+	return rows
+}
+
+func batchAllocationRows(allocs []ai.BatchAllocation) []diffRow {
+	rows := make([]diffRow, len(allocs))
+	for i, a := range allocs {
+		rows[i] = diffRow{
+			key:     a.Date + "|" + a.ProjectID,
+			label:   a.Date + " " + a.ProjectName,
+			minutes: a.Minutes,
+			desc:    a.Description,
+		}
+	}
+	return rows
+}
+
+// mark is the one-character marker shown before a row.
+func (c allocChange) mark() string {
+	switch c.kind {
+	case added:
+		return successStyle.Render("+")
+	case changed:
+		return warningStyle.Render("~")
+	}
+	return " "
+}
+
+// deltaText is the minute change shown after a row's minutes, unstyled.
+func (c allocChange) deltaText() string {
+	switch {
+	case c.kind == added:
+		return "new"
+	case c.delta != 0:
+		return fmt.Sprintf("%+d", c.delta)
+	}
+	return ""
+}
+
+func (c allocChange) renderDelta(width int) string {
+	text := fmt.Sprintf("%*s", width, c.deltaText())
+	switch {
+	case c.kind == added || c.delta > 0:
+		return successStyle.Render(text)
+	case c.delta < 0:
+		return errorStyle.Render(text)
+	}
+	return text
+}
+
+// renderDiffFooter summarises the changes and lists removed allocations.
+func renderDiffFooter(changes []allocChange, removed []diffRow) string {
+	var nAdded, nChanged int
+	for _, c := range changes {
+		switch c.kind {
+		case added:
+			nAdded++
+		case changed:
+			nChanged++
+		}
+	}
+
+	var sb strings.Builder
+	for _, r := range removed {
+		sb.WriteString(errorStyle.Render("- "))
+		sb.WriteString(dimStyle.Render(fmt.Sprintf("%s  %dmin  %s", r.label, r.minutes, r.desc)))
+		sb.WriteString("\n")
+	}
+	if nAdded+nChanged+len(removed) == 0 {
+		sb.WriteString(dimStyle.Render("Same allocations as the previous run"))
+	} else {
+		sb.WriteString(dimStyle.Render(fmt.Sprintf("vs previous run: %d added, %d changed, %d removed", nAdded, nChanged, len(removed))))
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
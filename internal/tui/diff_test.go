@@ -0,0 +1,79 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/christopherklint97/clockr/internal/ai"
+	"github.com/christopherklint97/clockr/internal/clockify"
+)
+
+func TestDiffRows(t *testing.T) {
+	prev := allocationRows([]ai.Allocation{
+		{ProjectID: "p1", ProjectName: "Alpha", Minutes: 30, Description: "Review"},
+		{ProjectID: "p2", ProjectName: "Beta", Minutes: 20, Description: "Standup"},
+		{ProjectID: "p3", ProjectName: "Gamma", Minutes: 10, Description: "Email"},
+	})
+	cur := allocationRows([]ai.Allocation{
+		{ProjectID: "p1", ProjectName: "Alpha", Minutes: 30, Description: "Review"},
+		{ProjectID: "p2", ProjectName: "Beta", Minutes: 15, Description: "Standup"},
+		{ProjectID: "p4", ProjectName: "Delta", Minutes: 15, Description: "Planning"},
+	})
+
+	changes, removed := diffRows(prev, cur)
+	want := []allocChange{{kind: unchanged}, {kind: changed, delta: -5}, {kind: added}}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("change %d = %+v, want %+v", i, changes[i], want[i])
+		}
+	}
+	if len(removed) != 1 || removed[0].label != "Gamma" {
+		t.Errorf("removed = %+v, want Gamma", removed)
+	}
+
+	footer := renderDiffFooter(changes, removed)
+	if !strings.Contains(footer, "1 added, 1 changed, 1 removed") {
+		t.Errorf("unexpected footer %q", footer)
+	}
+}
+
+func TestDiffRows_DescriptionOnly(t *testing.T) {
+	prev := []diffRow{{key: "p1", minutes: 60, desc: "Fix bug"}}
+	cur := []diffRow{{key: "p1", minutes: 60, desc: "Fix login bug"}}
+	changes, removed := diffRows(prev, cur)
+	if changes[0].kind != changed || changes[0].deltaText() != "" || len(removed) != 0 {
+		t.Errorf("unexpected diff %+v, removed %+v", changes, removed)
+	}
+}
+
+func TestSuggestionsView_ShowsDiff(t *testing.T) {
+	m := newSuggestionsModel(&ai.Suggestion{Allocations: []ai.Allocation{
+		{ProjectID: "p1", ProjectName: "Alpha", Minutes: 45, Description: "Review"},
+	}}, nil)
+	if strings.Contains(m.View(), "previous run") {
+		t.Error("first run should not show a diff")
+	}
+	m.previous = []ai.Allocation{{ProjectID: "p1", ProjectName: "Alpha", Minutes: 30, Description: "Review"}}
+	if view := m.View(); !strings.Contains(view, "+15") || !strings.Contains(view, "0 added, 1 changed, 0 removed") {
+		t.Errorf("diff missing from view:\n%s", view)
+	}
+}
+
+func TestSuggestionsView_WarnsArchivedClient(t *testing.T) {
+	projects := []clockify.Project{
+		{ID: "p1", Name: "Alpha", ClientName: "Acme"},
+		{ID: "p2", Name: "Legacy", ClientName: "Globex", ClientArchived: true},
+	}
+	m := newSuggestionsModel(&ai.Suggestion{Allocations: []ai.Allocation{
+		{ProjectID: "p1", ProjectName: "Alpha", Minutes: 30, Description: "Review"},
+		{ProjectID: "p2", ProjectName: "Legacy", Minutes: 30, Description: "Fix"},
+	}}, projects)
+
+	view := m.View()
+	if !strings.Contains(view, "Client Globex is archived") || strings.Contains(view, "Client Acme") {
+		t.Errorf("view should warn about Globex only:\n%s", view)
+	}
+	if edit := newEditModel(m.suggestion.Allocations[:1], projects).View(); strings.Contains(edit, "archived") {
+		t.Errorf("edit view warns without an archived client:\n%s", edit)
+	}
+}
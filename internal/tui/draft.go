@@ -0,0 +1,81 @@
+package tui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/christopherklint97/clockr/internal/store"
+)
+
+// draftInterval is how often the description being typed is saved as the
+// draft, so a crash or a failed AI call loses at most this much typing.
+const draftInterval = 3 * time.Second
+
+type draftTickMsg struct{}
+
+func draftTick() tea.Cmd {
+	return tea.Tick(draftInterval, func(time.Time) tea.Msg { return draftTickMsg{} })
+}
+
+// loadDraft returns the draft left by an earlier session, to offer in the
+// input view, or nil.
+func loadDraft(db *store.DB) *store.Draft {
+	if db == nil {
+		return nil
+	}
+	d, _ := db.LatestDraft()
+	if d == nil || d.Text == "" {
+		return nil
+	}
+	return d
+}
+
+// saveDraft stores the description when it changed since the last save.
+func (a *App) saveDraft() {
+	a.draftSaved = storeDraft(a.db, a.readOnly(), a.input.Value(), a.startTime, a.endTime, a.draftSaved)
+}
+
+// clearDraft forgets the draft once the window is logged or skipped.
+func (a *App) clearDraft() {
+	if a.db.DeleteDraft() == nil {
+		a.draftSaved = a.input.Value()
+	}
+}
+
+// saveDraft stores the description typed for the batch, spanning its days,
+// when it changed since the last save.
+func (a *BatchApp) saveDraft() {
+	start, end := a.days[0].Start, a.days[len(a.days)-1].End
+	a.draftSaved = storeDraft(a.db, a.readOnly(), a.input.Value(), start, end, a.draftSaved)
+}
+
+// clearDraft forgets the draft once the days are logged or all skipped.
+func (a *BatchApp) clearDraft() {
+	if a.db != nil && a.db.DeleteDraft() == nil {
+		a.draftSaved = a.input.Value()
+	}
+}
+
+// storeDraft saves text as the draft for the window start–end unless it is
+// the text already saved, and returns the text saved now.
+func storeDraft(db *store.DB, readOnly bool, text string, start, end time.Time, saved string) string {
+	if db == nil || readOnly || text == "" || text == saved {
+		return saved
+	}
+	if err := db.SaveDraft(&store.Draft{Text: text, StartTime: start, EndTime: end}); err != nil {
+		return saved
+	}
+	return text
+}
+
+// restoreDraft puts the offered draft in the text area, in place of an
+// empty description or at the cursor.
+func (m inputModel) restoreDraft() inputModel {
+	if m.textarea.Value() == "" {
+		m.textarea.SetValue(m.draft.Text)
+	} else {
+		m = insertPrefill(m, m.draft.Text)
+	}
+	m.draft = nil
+	return m
+}
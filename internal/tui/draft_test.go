@@ -0,0 +1,86 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/christopherklint97/clockr/internal/ai"
+	"github.com/christopherklint97/clockr/internal/store"
+)
+
+func TestDraft_SavedAndRestored(t *testing.T) {
+	t.Setenv("CLOCKR_HOME", t.TempDir())
+	db, err := store.Open(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	now := time.Now()
+	app := NewApp(now.Add(-time.Hour), now, nil, nil, nil, db, time.Hour, nil, "")
+	app.input.textarea.SetValue("half-written notes")
+	app.Update(draftTickMsg{})
+
+	d, err := db.LatestDraft()
+	if err != nil || d == nil || d.Text != "half-written notes" {
+		t.Fatalf("draft = %+v, %v", d, err)
+	}
+
+	// The next session offers it and Ctrl+G puts it back.
+	next := NewApp(now.Add(-time.Hour), now, nil, nil, nil, db, time.Hour, nil, "")
+	if !strings.Contains(next.input.View(), "Ctrl+G: restore it") {
+		t.Fatalf("draft not offered:\n%s", next.input.View())
+	}
+	next.input, _ = next.input.Update(tea.KeyMsg{Type: tea.KeyCtrlG})
+	if next.input.Value() != "half-written notes" {
+		t.Errorf("restored = %q", next.input.Value())
+	}
+	if next.input.draft != nil {
+		t.Error("the offer should go away once restored")
+	}
+
+	next.finishSkip("")
+	if d, _ := db.LatestDraft(); d != nil {
+		t.Errorf("draft = %+v, want it cleared after skipping", d)
+	}
+}
+
+func TestBatchDraft_SavedAndCleared(t *testing.T) {
+	t.Setenv("CLOCKR_HOME", t.TempDir())
+	db, err := store.Open(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	day := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	days := []ai.DaySlot{
+		{Date: "2026-03-02", Start: day, End: day.Add(8 * time.Hour), Minutes: 480},
+		{Date: "2026-03-03", Start: day.AddDate(0, 0, 1), End: day.AddDate(0, 0, 1).Add(8 * time.Hour), Minutes: 480},
+	}
+	app := NewBatchApp(days, nil, nil, nil, db, "")
+	app.input.textarea.SetValue("monday: reviews, tuesday: release")
+	app.Update(draftTickMsg{})
+
+	d, err := db.LatestDraft()
+	if err != nil || d == nil || d.Text != "monday: reviews, tuesday: release" {
+		t.Fatalf("draft = %+v, %v", d, err)
+	}
+	if !d.StartTime.Equal(days[0].Start) || !d.EndTime.Equal(days[1].End) {
+		t.Errorf("draft window = %v–%v, want the batch's days", d.StartTime, d.EndTime)
+	}
+
+	// A later batch, after a crash, offers it.
+	next := NewBatchApp(days, nil, nil, nil, db, "")
+	if !strings.Contains(next.input.View(), "Ctrl+G: restore it") {
+		t.Fatalf("draft not offered:\n%s", next.input.View())
+	}
+
+	next.handleSubmit(batchSubmitMsg{entries: []store.Entry{{Status: "logged"}}, errs: []string{""}})
+	if d, _ := db.LatestDraft(); d != nil {
+		t.Errorf("draft = %+v, want it cleared once logged", d)
+	}
+}
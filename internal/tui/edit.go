@@ -2,6 +2,7 @@ package tui
 
 import (
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -27,6 +28,9 @@ type editModel struct {
 	textInput   textinput.Model
 	editing     bool
 	filtered    []clockify.Project
+	total       int                         // minutes the rows must add up to; kept when rows are added or removed
+	errMsg      string                      // shown until the next key
+	archived    map[string]clockify.Project // projects under an archived client
 }
 
 func newEditModel(allocations []ai.Allocation, projects []clockify.Project) editModel {
@@ -34,11 +38,68 @@ func newEditModel(allocations []ai.Allocation, projects []clockify.Project) edit
 	ti.CharLimit = 200
 	ti.Width = 50
 
+	total := 0
+	for _, a := range allocations {
+		total += a.Minutes
+	}
 	return editModel{
-		allocations: allocations,
+		allocations: slices.Clone(allocations),
 		projects:    projects,
+		archived:    archivedClientProjects(projects),
 		textInput:   ti,
+		total:       total,
+	}
+}
+
+// incomplete reports whether a row still has no project, e.g. after "n".
+func (m editModel) incomplete() bool {
+	for _, a := range m.allocations {
+		if a.ProjectID == "" {
+			return true
+		}
 	}
+	return false
+}
+
+// insertRow adds alloc after the cursor with an even share of the total,
+// takes those minutes from the other rows in proportion, and moves the
+// cursor to it.
+func (m *editModel) insertRow(alloc ai.Allocation) {
+	if len(m.allocations) >= m.total {
+		m.errMsg = "No minutes left for another row"
+		return
+	}
+	alloc.Minutes = max(m.total/(len(m.allocations)+1), 1)
+	others := m.allocations
+	ai.FitMinutes(others, m.total-alloc.Minutes)
+	m.cursor++
+	m.allocations = slices.Insert(others, m.cursor, alloc)
+}
+
+// splitRow halves the row under the cursor into two identical rows.
+func (m *editModel) splitRow() {
+	row := m.allocations[m.cursor]
+	if row.Minutes < 2 {
+		m.errMsg = "Too short to split"
+		return
+	}
+	first := row.Minutes / 2
+	m.allocations[m.cursor].Minutes = first
+	row.Minutes -= first
+	m.allocations = slices.Insert(m.allocations, m.cursor+1, row)
+	m.cursor++
+}
+
+// deleteRow removes the row under the cursor and gives its minutes to the
+// others in proportion.
+func (m *editModel) deleteRow() {
+	if len(m.allocations) == 1 {
+		m.errMsg = "Can't delete the only row — skip the window instead"
+		return
+	}
+	m.allocations = slices.Delete(m.allocations, m.cursor, m.cursor+1)
+	ai.FitMinutes(m.allocations, m.total)
+	m.cursor = min(m.cursor, len(m.allocations)-1)
 }
 
 func (m editModel) Update(msg tea.Msg) (editModel, tea.Cmd) {
@@ -50,7 +111,17 @@ func (m editModel) Update(msg tea.Msg) (editModel, tea.Cmd) {
 
 func (m editModel) updateNavigating(msg tea.Msg) (editModel, tea.Cmd) {
 	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		m.errMsg = ""
 		switch keyMsg.String() {
+		case "n":
+			m.insertRow(ai.Allocation{Confidence: 1})
+			m.field = editProject
+		case "c":
+			m.insertRow(m.allocations[m.cursor])
+		case "s":
+			m.splitRow()
+		case "d":
+			m.deleteRow()
 		case "up", "k":
 			if m.cursor > 0 {
 				m.cursor--
@@ -154,6 +225,9 @@ func (m editModel) View() string {
 		if a.ClientName != "" {
 			project = a.ProjectName + " (" + a.ClientName + ")"
 		}
+		if a.ProjectID == "" {
+			project = "(choose a project)"
+		}
 		minutes := fmt.Sprintf("%dmin", a.Minutes)
 		rows[i] = rowData{project: project, minutes: minutes, desc: a.Description}
 		if len(project) > maxProject {
@@ -178,6 +252,7 @@ func (m editModel) View() string {
 		sb.WriteString("\n")
 	}
 
+	sb.WriteString(archivedClientWarning(allocationProjectIDs(m.allocations), m.archived))
 	sb.WriteString("\n")
 	sb.WriteString(fmt.Sprintf("Field: %s\n", selectedStyle.Render(fieldNames[m.field])))
 
@@ -200,8 +275,15 @@ func (m editModel) View() string {
 		}
 	}
 
+	if m.errMsg != "" {
+		sb.WriteString(errorStyle.Render(m.errMsg))
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("\n")
+	sb.WriteString(helpStyle.Render(fmt.Sprintf("Total: %d min", m.total)))
 	sb.WriteString("\n")
-	sb.WriteString(helpStyle.Render("Enter: edit field • Tab: next field • j/k: nav • Esc: done editing"))
+	sb.WriteString(helpStyle.Render("Enter: edit field • Tab: next field • j/k: nav • n: new row • c: duplicate • s: split • d: delete • Esc: done editing"))
 
 	return boxStyle.Render(sb.String())
 }
@@ -0,0 +1,77 @@
+package tui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/christopherklint97/clockr/internal/ai"
+)
+
+func editRows() []ai.Allocation {
+	return []ai.Allocation{
+		{ProjectID: "p1", ProjectName: "Alpha", Minutes: 120, Description: "Review"},
+		{ProjectID: "p2", ProjectName: "Beta", Minutes: 60, Description: "Emails"},
+	}
+}
+
+func pressEdit(m editModel, key string) editModel {
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+	return m
+}
+
+func minutesOf(allocs []ai.Allocation) (list []int, sum int) {
+	for _, a := range allocs {
+		list = append(list, a.Minutes)
+		sum += a.Minutes
+	}
+	return list, sum
+}
+
+func TestEdit_RowActionsKeepTotal(t *testing.T) {
+	tests := []struct {
+		key  string
+		want []int
+	}{
+		{"s", []int{60, 60, 60}},
+		{"d", []int{180}},
+		{"n", []int{80, 60, 40}},
+		{"c", []int{80, 60, 40}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			m := pressEdit(newEditModel(editRows(), nil), tt.key)
+			got, sum := minutesOf(m.allocations)
+			if sum != 180 {
+				t.Errorf("total = %d after %q, want 180", sum, tt.key)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("minutes = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("minutes = %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestEdit_NewRowNeedsProject(t *testing.T) {
+	m := pressEdit(newEditModel(editRows(), nil), "n")
+	if !m.incomplete() || m.cursor != 1 || m.field != editProject {
+		t.Errorf("a new row should be selected and need a project: cursor %d field %d", m.cursor, m.field)
+	}
+	m = pressEdit(m, "d")
+	if m.incomplete() || len(m.allocations) != 2 {
+		t.Errorf("deleting the new row should leave the original rows: %+v", m.allocations)
+	}
+}
+
+func TestEdit_DeleteLastRowRefused(t *testing.T) {
+	m := newEditModel(editRows()[:1], nil)
+	m = pressEdit(m, "d")
+	if len(m.allocations) != 1 || m.errMsg == "" {
+		t.Errorf("the only row should not be deleted: %+v", m.allocations)
+	}
+}
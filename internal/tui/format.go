@@ -0,0 +1,113 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/christopherklint97/clockr/internal/ai"
+	"github.com/christopherklint97/clockr/internal/clockify"
+	"github.com/christopherklint97/clockr/internal/format"
+)
+
+// formatAllocations applies the [format] rules to each description in place.
+func formatAllocations(f *format.Formatter, allocs []ai.Allocation) {
+	for i, a := range allocs {
+		allocs[i].Description = f.Description(a.ProjectID, a.ProjectName, a.ClientName, a.Description)
+	}
+}
+
+func formatBatchAllocations(f *format.Formatter, allocs []ai.BatchAllocation) {
+	for i, a := range allocs {
+		allocs[i].Description = f.Description(a.ProjectID, a.ProjectName, a.ClientName, a.Description)
+	}
+}
+
+// descriptionWarning flags each description that breaks the [format]
+// max_length or pattern rules, by row number, or returns "" when all pass.
+func descriptionWarning(f *format.Formatter, descs []string) string {
+	var lines []string
+	for i, d := range descs {
+		if problems := f.Check(d); len(problems) > 0 {
+			lines = append(lines, warningStyle.Render(fmt.Sprintf("⚠ Row %d: %s", i+1, strings.Join(problems, "; "))))
+		}
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// problemsWarning lists what was still wrong with the AI's answer after it
+// was sent back for correction, or returns "" when nothing was.
+func problemsWarning(problems []string) string {
+	if len(problems) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString(warningStyle.Render("⚠ The AI's answer still breaks the rules — review or edit before accepting:"))
+	sb.WriteString("\n")
+	for _, p := range problems {
+		sb.WriteString(warningStyle.Render("  - " + p))
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+func allocationDescriptions(allocs []ai.Allocation) []string {
+	descs := make([]string, len(allocs))
+	for i, a := range allocs {
+		descs[i] = a.Description
+	}
+	return descs
+}
+
+func batchDescriptions(allocs []ai.BatchAllocation) []string {
+	descs := make([]string, len(allocs))
+	for i, a := range allocs {
+		descs[i] = a.Description
+	}
+	return descs
+}
+
+// roundAllocations applies the rounding rule to the allocations in place, by
+// their cumulative ends so the window's total is rounded once, and the
+// minutes shown are the minutes logged.
+func roundAllocations(r clockify.Rounding, allocs []ai.Allocation) {
+	minutes := make([]int, len(allocs))
+	for i, a := range allocs {
+		minutes[i] = a.Minutes
+	}
+	for i, m := range r.ApplyCumulative(minutes) {
+		allocs[i].Minutes = m
+	}
+}
+
+// roundBatchAllocations is roundAllocations for each day of a batch.
+func roundBatchAllocations(r clockify.Rounding, allocs []ai.BatchAllocation) {
+	days := make(map[string][]int) // date → indexes in allocs
+	var order []string
+	for i, a := range allocs {
+		if days[a.Date] == nil {
+			order = append(order, a.Date)
+		}
+		days[a.Date] = append(days[a.Date], i)
+	}
+	for _, date := range order {
+		idx := days[date]
+		minutes := make([]int, len(idx))
+		for j, i := range idx {
+			minutes[j] = allocs[i].Minutes
+		}
+		for j, m := range r.ApplyCumulative(minutes) {
+			allocs[idx[j]].Minutes = m
+		}
+	}
+}
+
+// roundingNote tells the user minutes were rounded, or "" when they weren't.
+func roundingNote(r clockify.Rounding) string {
+	if !r.Enabled() {
+		return ""
+	}
+	return dimStyle.Render("Minutes rounded to the "+r.String()+" ([format] round_minutes)") + "\n"
+}
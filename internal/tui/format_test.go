@@ -0,0 +1,73 @@
+package tui
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/christopherklint97/clockr/internal/ai"
+	"github.com/christopherklint97/clockr/internal/clockify"
+	"github.com/christopherklint97/clockr/internal/config"
+	"github.com/christopherklint97/clockr/internal/format"
+)
+
+func TestRoundAllocations(t *testing.T) {
+	tests := []struct {
+		round string
+		in    []int
+		want  []int
+	}{
+		// Cumulative ends 20, 40 and 60 round to 15, 45 and 60: the total stays
+		// an hour, where rounding each 20 alone would log 45 minutes.
+		{"Round to nearest", []int{20, 20, 20}, []int{15, 30, 15}},
+		// 5 would round away at 45 → 45, so it keeps one step.
+		{"Round to nearest", []int{22, 23, 5, 60}, []int{15, 30, 15, 45}},
+		{"Round up to", []int{16, 30}, []int{30, 30}},
+		{"Round down to", []int{29, 10}, []int{15, 15}},
+	}
+	for _, tt := range tests {
+		allocs := make([]ai.Allocation, len(tt.in))
+		for i, m := range tt.in {
+			allocs[i].Minutes = m
+		}
+		roundAllocations(clockify.Rounding{Round: tt.round, Minutes: "15"}, allocs)
+		for i, a := range allocs {
+			if a.Minutes != tt.want[i] {
+				t.Errorf("%s %d = %d, want %d", tt.round, tt.in[i], a.Minutes, tt.want[i])
+			}
+		}
+	}
+
+	allocs := []ai.Allocation{{Minutes: 22}}
+	roundAllocations(clockify.Rounding{}, allocs)
+	if allocs[0].Minutes != 22 {
+		t.Errorf("no rounding changed 22 to %d", allocs[0].Minutes)
+	}
+}
+
+func TestRoundBatchAllocations(t *testing.T) {
+	allocs := []ai.BatchAllocation{
+		{Date: "2026-03-02", Minutes: 20}, {Date: "2026-03-03", Minutes: 20},
+		{Date: "2026-03-02", Minutes: 20}, {Date: "2026-03-02", Minutes: 20},
+	}
+	roundBatchAllocations(clockify.Rounding{Round: "Round to nearest", Minutes: "15"}, allocs)
+	var got []int
+	for _, a := range allocs {
+		got = append(got, a.Minutes)
+	}
+	// Each day is rounded on its own: 20, 20, 20 on Monday → 15, 30, 15.
+	if want := []int{15, 15, 30, 15}; !slices.Equal(got, want) {
+		t.Errorf("minutes = %v, want %v", got, want)
+	}
+}
+
+func TestDescriptionWarning(t *testing.T) {
+	f := format.New(config.FormatConfig{MaxLength: 10})
+	got := descriptionWarning(f, []string{"Short", "Far too long a description"})
+	if !strings.Contains(got, "Row 2: 26 characters, over the limit of 10") || strings.Contains(got, "Row 1") {
+		t.Errorf("unexpected warning %q", got)
+	}
+	if got := descriptionWarning(nil, []string{"Far too long a description"}); got != "" {
+		t.Errorf("nil formatter warned: %q", got)
+	}
+}
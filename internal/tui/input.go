@@ -1,17 +1,34 @@
 package tui
 
 import (
+	"fmt"
+	"slices"
+	"strings"
+
 	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/christopherklint97/clockr/internal/ai"
+	"github.com/christopherklint97/clockr/internal/store"
 )
 
+// historySize is how many past descriptions Ctrl+R offers.
+const historySize = 20
+
 type inputModel struct {
-	textarea      textarea.Model
-	timeInfo      string
-	width         int
-	height        int
-	lastInput     string // previous description available via Ctrl+R
-	loadedLastMsg bool   // true after Ctrl+R was used (for transient feedback)
+	textarea textarea.Model
+	timeInfo string
+	width    int
+	height   int
+	history  []string // past descriptions, newest first, browsable via Ctrl+R
+	browsing bool     // the history list is open
+	cursor   int      // selected history item while browsing
+	snippets []store.Snippet
+	picking  bool // the Ctrl+T snippet picker is open
+	picker   snippetPickerModel
+	context  contextPanelModel // Ctrl+X: what is sent to the AI besides the text
+	status   string            // e.g. why Ctrl+V could not paste
+	draft    *store.Draft      // unsent description from an earlier session, offered until restored
 }
 
 func newInputModel(timeInfo string) inputModel {
@@ -31,6 +48,50 @@ func newInputModel(timeInfo string) inputModel {
 	}
 }
 
+// loadHistory returns the recent descriptions from db, adding lastInput in
+// front when the history doesn't have it yet.
+func loadHistory(db *store.DB, lastInput string) []string {
+	var history []string
+	if db != nil {
+		inputs, _ := db.GetRecentRawInputs(historySize)
+		for _, in := range inputs {
+			history = append(history, in.Text)
+		}
+	}
+	if lastInput != "" && !slices.Contains(history, lastInput) {
+		history = append([]string{lastInput}, history...)
+	}
+	return history
+}
+
+func loadSnippets(db *store.DB) []store.Snippet {
+	if db == nil {
+		return nil
+	}
+	snippets, _ := db.Snippets()
+	return snippets
+}
+
+// withSaved returns m with the history and snippets of prev, for a fresh
+// input after a retry.
+func (m inputModel) withSaved(prev inputModel) inputModel {
+	m.history = prev.history
+	m.snippets = prev.snippets
+	m.context = prev.context
+	m.draft = prev.draft
+	return m
+}
+
+// rememberInput records a submitted description for --repeat and the
+// Ctrl+R history.
+func rememberInput(db *store.DB, text string) {
+	if db == nil {
+		return
+	}
+	db.SetState("last_description", text)
+	db.AddRawInput(text)
+}
+
 func (m inputModel) Update(msg tea.Msg) (inputModel, tea.Cmd) {
 	if wsMsg, ok := msg.(tea.WindowSizeMsg); ok {
 		m.width = wsMsg.Width
@@ -38,15 +99,62 @@ func (m inputModel) Update(msg tea.Msg) (inputModel, tea.Cmd) {
 		if m.width > 4 {
 			m.textarea.SetWidth(m.width - 4)
 		}
-		if m.height > 5 {
-			m.textarea.SetHeight(m.height - 5)
+		if m.height > 6 {
+			m.textarea.SetHeight(m.height - 6)
+		}
+		return m, nil
+	}
+	if paste, ok := msg.(pasteMsg); ok {
+		if paste.err != nil {
+			m.status = errorStyle.Render("Paste failed: " + paste.err.Error())
+			return m, nil
 		}
+		m.status = ""
+		m.textarea.InsertString(paste.text)
 		return m, nil
 	}
+	if m.picking {
+		picker, text, done, cmd := m.picker.Update(msg)
+		m.picker = picker
+		if done {
+			m.picking = false
+			if text != "" {
+				m = insertSnippet(m, text)
+			}
+			return m, m.textarea.Focus()
+		}
+		return m, cmd
+	}
 	if keyMsg, ok := msg.(tea.KeyMsg); ok {
-		if keyMsg.String() == "ctrl+r" && m.lastInput != "" {
-			m.textarea.SetValue(m.lastInput)
-			m.loadedLastMsg = true
+		if m.browsing {
+			return m.updateHistory(keyMsg), nil
+		}
+		if m.context.open {
+			m.context = m.context.Update(keyMsg)
+			if !m.context.open {
+				return m, m.textarea.Focus()
+			}
+			return m, nil
+		}
+		switch {
+		case keyMsg.String() == "ctrl+r" && len(m.history) > 0:
+			m.browsing = true
+			m.cursor = 0
+			return m, nil
+		case keyMsg.String() == "ctrl+t" && len(m.snippets) > 0:
+			m.picking = true
+			m.picker = newSnippetPickerModel(m.snippets)
+			m.textarea.Blur()
+			return m, textinput.Blink
+		case keyMsg.String() == "ctrl+g" && m.draft != nil:
+			return m.restoreDraft(), nil
+		case keyMsg.String() == "ctrl+v":
+			return m, pasteCmd
+		case keyMsg.String() == "ctrl+p" && len(m.context.Included()) > 0:
+			return insertPrefill(m, ai.FormatPrefill(m.context.Included())), nil
+		case keyMsg.String() == "ctrl+x" && len(m.context.items) > 0:
+			m.context.open = true
+			m.textarea.Blur()
 			return m, nil
 		}
 	}
@@ -55,16 +163,100 @@ func (m inputModel) Update(msg tea.Msg) (inputModel, tea.Cmd) {
 	return m, cmd
 }
 
+// updateHistory handles keys while the history list is open. Enter puts the
+// selected description in the text area for editing; esc closes the list.
+func (m inputModel) updateHistory(msg tea.KeyMsg) inputModel {
+	switch msg.String() {
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j", "ctrl+r":
+		if m.cursor < len(m.history)-1 {
+			m.cursor++
+		}
+	case "enter":
+		m.textarea.SetValue(m.history[m.cursor])
+		m.browsing = false
+	case "esc":
+		m.browsing = false
+	}
+	return m
+}
+
+// Picking reports whether the history list, snippet picker or context
+// panel is open, so the parent model lets it have enter and esc.
+func (m inputModel) Picking() bool {
+	return m.browsing || m.picking || m.context.open
+}
+
 func (m inputModel) View() string {
 	header := titleStyle.Render("clockr — Time Entry")
 	timeLabel := subtitleStyle.Render(m.timeInfo)
-	helpParts := "Enter: submit • Ctrl+C: cancel"
-	if m.lastInput != "" {
-		helpParts += " • Ctrl+R: load last description"
+	if m.browsing {
+		return header + "\n" + timeLabel + "\n" + m.historyView()
+	}
+	if m.picking {
+		return header + "\n" + timeLabel + "\n" + m.picker.View()
+	}
+	if m.context.open {
+		return header + "\n" + timeLabel + "\n" + m.context.View(m.width)
+	}
+	helpParts := "Enter: submit • Ctrl+O: enter manually • Ctrl+S: skip • Ctrl+C: cancel"
+	if len(m.history) > 0 {
+		helpParts += " • Ctrl+R: past descriptions"
+	}
+	if len(m.snippets) > 0 {
+		helpParts += " • Ctrl+T: insert snippet"
+	}
+	if len(m.context.items) > 0 {
+		helpParts += " • Ctrl+P: insert context • Ctrl+X: review context"
 	}
 	help := helpStyle.Render(helpParts)
 
-	return header + "\n" + timeLabel + "\n" + m.textarea.View() + "\n" + help
+	var contextLine string
+	if m.draft != nil {
+		contextLine = warningStyle.Render(fmt.Sprintf("Unsent description from %s — Ctrl+G: restore it", m.draft.UpdatedAt.Local().Format("Mon 15:04"))) + "\n"
+	}
+	if summary := m.context.Summary(); summary != "" {
+		contextLine += dimStyle.Render(summary) + "\n"
+	}
+	if m.status != "" {
+		help = m.status + "\n" + help
+	}
+	return header + "\n" + timeLabel + "\n" + contextLine + m.textarea.View() + "\n" + help
+}
+
+// insertPrefill adds the context summary at the cursor, on a line of its
+// own when there is text before it.
+func insertPrefill(m inputModel, text string) inputModel {
+	if v := m.textarea.Value(); v != "" && !strings.HasSuffix(v, "\n") {
+		text = "\n" + text
+	}
+	m.textarea.InsertString(text)
+	return m
+}
+
+func (m inputModel) historyView() string {
+	var b strings.Builder
+	b.WriteString(highlightStyle.Render("Past descriptions") + "\n\n")
+	width := m.width - 6
+	if width < 20 {
+		width = 20
+	}
+	for i, text := range m.history {
+		line := strings.Join(strings.Fields(text), " ")
+		if r := []rune(line); len(r) > width {
+			line = string(r[:width-1]) + "…"
+		}
+		if i == m.cursor {
+			b.WriteString(selectedStyle.Render(fmt.Sprintf("> %s", line)) + "\n")
+		} else {
+			b.WriteString(fmt.Sprintf("  %s\n", line))
+		}
+	}
+	b.WriteString(helpStyle.Render("↑/↓: select • Enter: edit this description • Esc: back"))
+	return b.String()
 }
 
 func (m inputModel) Value() string {
@@ -0,0 +1,60 @@
+package tui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/christopherklint97/clockr/internal/store"
+)
+
+func TestInputModel_History(t *testing.T) {
+	m := newInputModel("09:00 – 10:00 (60 min)")
+	m.history = []string{"fixed auth bug", "reviewed PRs", "standup and planning"}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	if !m.Picking() {
+		t.Fatal("Ctrl+R should open the history")
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown}) // stays on the last item
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyUp})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.Picking() || m.Value() != "reviewed PRs" {
+		t.Fatalf("got %q (browsing %v), want the second description loaded", m.Value(), m.Picking())
+	}
+
+	// The loaded text stays editable, and esc leaves it untouched.
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("!")})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.Picking() || m.Value() != "reviewed PRs!" {
+		t.Errorf("got %q (browsing %v) after esc", m.Value(), m.Picking())
+	}
+}
+
+func TestInputModel_NoHistory(t *testing.T) {
+	m := newInputModel("")
+	if m, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlR}); m.Picking() {
+		t.Error("Ctrl+R opened an empty history")
+	}
+	if history := loadHistory(nil, "reviewed PRs"); len(history) != 1 || history[0] != "reviewed PRs" {
+		t.Errorf("loadHistory without a database = %v", history)
+	}
+}
+
+func TestInputModel_Snippets(t *testing.T) {
+	m := newInputModel("")
+	m.snippets = []store.Snippet{{Name: "standup", Text: "Daily standup"}, {Name: "review", Text: "Code review"}}
+	m.textarea.SetValue("Fixed auth,")
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlT})
+	if !m.Picking() {
+		t.Fatal("Ctrl+T should open the snippet picker")
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("rev")})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.Picking() || m.Value() != "Fixed auth, Code review" {
+		t.Errorf("got %q (picking %v), want the snippet appended", m.Value(), m.Picking())
+	}
+}
@@ -0,0 +1,232 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/christopherklint97/clockr/internal/ai"
+	"github.com/christopherklint97/clockr/internal/clockify"
+)
+
+type manualStep int
+
+const (
+	manualProject manualStep = iota
+	manualMinutes
+	manualDescription
+)
+
+// manualListSize is how many matching projects the picker shows.
+const manualListSize = 8
+
+// manualModel builds a single allocation by hand, without the AI: pick a
+// project, then type minutes and a description.
+type manualModel struct {
+	projects []clockify.Project
+	filtered []clockify.Project
+	cursor   int
+	step     manualStep
+	input    textinput.Model
+	alloc    ai.Allocation
+	minutes  int // the window's length, offered as the default
+	errMsg   string
+}
+
+func newManualModel(projects []clockify.Project, minutes int) manualModel {
+	ti := textinput.New()
+	ti.CharLimit = 200
+	ti.Width = 50
+	ti.Placeholder = "Search project..."
+	ti.Focus()
+	return manualModel{projects: projects, filtered: projects, input: ti, minutes: minutes}
+}
+
+// Update handles one step. It returns the allocation and true once the
+// description is entered. Esc goes back a step; on the first step the
+// caller decides what it means.
+func (m manualModel) Update(msg tea.Msg) (manualModel, ai.Allocation, bool, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "enter":
+			return m.next()
+		case "esc":
+			if m.step > manualProject {
+				m.step--
+				m.errMsg = ""
+				m.restoreInput()
+			}
+			return m, ai.Allocation{}, false, nil
+		case "up", "ctrl+p":
+			if m.step == manualProject && m.cursor > 0 {
+				m.cursor--
+			}
+			return m, ai.Allocation{}, false, nil
+		case "down", "ctrl+n":
+			if m.step == manualProject && m.cursor < min(len(m.filtered), manualListSize)-1 {
+				m.cursor++
+			}
+			return m, ai.Allocation{}, false, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	if m.step == manualProject {
+		m.filtered = filterProjects(m.projects, m.input.Value())
+		m.cursor = 0
+	}
+	return m, ai.Allocation{}, false, cmd
+}
+
+// next accepts the current step and moves on.
+func (m manualModel) next() (manualModel, ai.Allocation, bool, tea.Cmd) {
+	m.errMsg = ""
+	switch m.step {
+	case manualProject:
+		if len(m.filtered) == 0 {
+			m.errMsg = "No project matches"
+			return m, ai.Allocation{}, false, nil
+		}
+		p := m.filtered[m.cursor]
+		m.alloc.ProjectID, m.alloc.ProjectName, m.alloc.ClientName = p.ID, p.Name, p.ClientName
+		m.step = manualMinutes
+	case manualMinutes:
+		v, err := strconv.Atoi(strings.TrimSpace(m.input.Value()))
+		if err != nil || v <= 0 {
+			m.errMsg = "Minutes must be a positive number"
+			return m, ai.Allocation{}, false, nil
+		}
+		m.alloc.Minutes = v
+		m.step = manualDescription
+	case manualDescription:
+		desc := strings.TrimSpace(m.input.Value())
+		if desc == "" {
+			m.errMsg = "Description is required"
+			return m, ai.Allocation{}, false, nil
+		}
+		m.alloc.Description = desc
+		m.alloc.Confidence = 1
+		return m, m.alloc, true, nil
+	}
+	m.restoreInput()
+	return m, ai.Allocation{}, false, nil
+}
+
+// restoreInput fills the input with what is known for the current step.
+func (m *manualModel) restoreInput() {
+	switch m.step {
+	case manualProject:
+		m.input.SetValue("")
+		m.input.Placeholder = "Search project..."
+		m.filtered = m.projects
+		m.cursor = 0
+	case manualMinutes:
+		minutes := m.alloc.Minutes
+		if minutes == 0 {
+			minutes = m.minutes
+		}
+		m.input.SetValue(strconv.Itoa(minutes))
+		m.input.Placeholder = "Minutes"
+	case manualDescription:
+		m.input.SetValue(m.alloc.Description)
+		m.input.Placeholder = "Description"
+	}
+	m.input.CursorEnd()
+}
+
+func (m manualModel) View() string {
+	var sb strings.Builder
+	sb.WriteString(titleStyle.Render("Manual Entry"))
+	sb.WriteString("\n")
+	if m.step > manualProject {
+		project := m.alloc.ProjectName
+		if m.alloc.ClientName != "" {
+			project += " (" + m.alloc.ClientName + ")"
+		}
+		sb.WriteString(dimStyle.Render("Project: " + project))
+		sb.WriteString("\n")
+	}
+	if m.step > manualMinutes {
+		sb.WriteString(dimStyle.Render(fmt.Sprintf("Minutes: %d", m.alloc.Minutes)))
+		sb.WriteString("\n")
+	}
+	sb.WriteString(m.input.View())
+	sb.WriteString("\n")
+
+	if m.step == manualProject {
+		for i, p := range m.filtered[:min(len(m.filtered), manualListSize)] {
+			display := p.Name
+			if p.ClientName != "" {
+				display = p.Name + " (" + p.ClientName + ")"
+			}
+			if i == m.cursor {
+				sb.WriteString(highlightStyle.Render("> " + display))
+			} else {
+				sb.WriteString(dimStyle.Render("  " + display))
+			}
+			sb.WriteString("\n")
+		}
+	}
+	if m.errMsg != "" {
+		sb.WriteString(errorStyle.Render(m.errMsg))
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("\n")
+	sb.WriteString(helpStyle.Render("Enter: next • ↑/↓: pick project • Esc: back • Ctrl+C: cancel"))
+	return boxStyle.Render(sb.String())
+}
+
+// filterProjects returns the projects whose name or client fuzzy-matches
+// query, best match first.
+func filterProjects(projects []clockify.Project, query string) []clockify.Project {
+	if strings.TrimSpace(query) == "" {
+		return projects
+	}
+	type scored struct {
+		project clockify.Project
+		score   int
+	}
+	var matches []scored
+	for _, p := range projects {
+		score, ok := fuzzyScore(query, p.Name+" "+p.ClientName)
+		if ok {
+			matches = append(matches, scored{p, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+	out := make([]clockify.Project, len(matches))
+	for i, m := range matches {
+		out[i] = m.project
+	}
+	return out
+}
+
+// fuzzyScore reports whether the letters of query appear in order in target,
+// ignoring case and spaces in the query. Consecutive letters and letters at
+// the start of a word score higher.
+func fuzzyScore(query, target string) (int, bool) {
+	q := []rune(strings.ToLower(strings.ReplaceAll(query, " ", "")))
+	t := []rune(strings.ToLower(target))
+	score, qi, prev := 0, 0, -2
+	for ti := 0; ti < len(t) && qi < len(q); ti++ {
+		if t[ti] != q[qi] {
+			continue
+		}
+		score++
+		if ti == prev+1 {
+			score += 2
+		}
+		if ti == 0 || !unicode.IsLetter(t[ti-1]) && !unicode.IsDigit(t[ti-1]) {
+			score += 3
+		}
+		prev = ti
+		qi++
+	}
+	return score, qi == len(q)
+}
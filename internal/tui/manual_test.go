@@ -0,0 +1,83 @@
+package tui
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/christopherklint97/clockr/internal/clockify"
+)
+
+var manualProjects = []clockify.Project{
+	{ID: "p1", Name: "Internal Meetings"},
+	{ID: "p2", Name: "Mobile App", ClientName: "Acme"},
+	{ID: "p3", Name: "Marketing Site"},
+}
+
+func TestFilterProjects_Fuzzy(t *testing.T) {
+	got := filterProjects(manualProjects, "mapp")
+	if len(got) != 1 || got[0].ID != "p2" {
+		t.Fatalf("mapp should only match Mobile App, got %+v", got)
+	}
+	got = filterProjects(manualProjects, "ms")
+	if len(got) != 2 || got[0].ID != "p3" {
+		t.Errorf("a word-start match should rank first, got %+v", got)
+	}
+	if got := filterProjects(manualProjects, "acme"); len(got) != 1 || got[0].ID != "p2" {
+		t.Errorf("client names should match too, got %+v", got)
+	}
+	if got := filterProjects(manualProjects, "zzz"); len(got) != 0 {
+		t.Errorf("expected no matches, got %+v", got)
+	}
+}
+
+func typeKeys(m manualModel, s string) manualModel {
+	for _, r := range s {
+		m, _, _, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	return m
+}
+
+func TestManualModel_BuildsAllocation(t *testing.T) {
+	enter := tea.KeyMsg{Type: tea.KeyEnter}
+	m := newManualModel(manualProjects, 60)
+
+	m = typeKeys(m, "site")
+	m, _, _, _ = m.Update(enter)
+	if m.step != manualMinutes || m.input.Value() != "60" {
+		t.Fatalf("minutes should default to the window, got step %d value %q", m.step, m.input.Value())
+	}
+	m.input.SetValue("45")
+	m, _, _, _ = m.Update(enter)
+
+	m, _, done, _ := m.Update(enter)
+	if done || m.errMsg == "" {
+		t.Fatal("an empty description should be rejected")
+	}
+	m = typeKeys(m, "Landing page copy")
+	_, alloc, done, _ := m.Update(enter)
+	if !done || alloc.ProjectID != "p3" || alloc.Minutes != 45 || alloc.Description != "Landing page copy" {
+		t.Errorf("unexpected allocation %+v (done=%v)", alloc, done)
+	}
+}
+
+func TestManual_EscReturnsToInput(t *testing.T) {
+	now := time.Now()
+	app := NewApp(now.Add(-time.Hour), now, nil, manualProjects, nil, nil, time.Hour, nil, "")
+	app.state = inputView
+	app.Update(tea.KeyMsg{Type: tea.KeyCtrlO})
+	if app.state != manualView {
+		t.Fatalf("Ctrl+O should open the manual form, state = %v", app.state)
+	}
+	app.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if app.state != inputView {
+		t.Errorf("Esc should go back to the description box, state = %v", app.state)
+	}
+
+	app.SetManual(true)
+	app.startManual()
+	app.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if app.state != manualView {
+		t.Error("without the AI there is no description box to go back to")
+	}
+}
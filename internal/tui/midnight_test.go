@@ -0,0 +1,58 @@
+package tui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/ai"
+	"github.com/christopherklint97/clockr/internal/backend"
+)
+
+func TestSubmitAllocations_SplitsAtMidnight(t *testing.T) {
+	client, _ := fakeClockify(t)
+	start := time.Date(2026, 3, 2, 23, 30, 0, 0, time.UTC)
+	app := NewApp(start, start.Add(time.Hour), nil, nil, backend.Clockify(client, "ws"), nil, time.Hour, nil, "")
+	app.SetSplitAtMidnight(true)
+
+	msg := app.submitAllocations([]ai.Allocation{{ProjectID: "p1", ProjectName: "Alpha", Minutes: 60, Description: "Deploy"}})().(submitMsg)
+
+	if len(msg.entries) != 2 {
+		t.Fatalf("expected the entry split in two, got %+v", msg.entries)
+	}
+	first, second := msg.entries[0], msg.entries[1]
+	if first.Minutes != 30 || second.Minutes != 30 || !first.EndTime.Equal(second.StartTime) || second.StartTime.Day() != 3 {
+		t.Errorf("unexpected parts %+v / %+v", first, second)
+	}
+	if first.Status != "logged" || second.Status != "logged" {
+		t.Errorf("both parts should be logged: %q, %q", first.Status, second.Status)
+	}
+}
+
+func TestSubmitAllocations_KeepsMidnightEntry(t *testing.T) {
+	client, _ := fakeClockify(t)
+	start := time.Date(2026, 3, 2, 23, 30, 0, 0, time.UTC)
+	app := NewApp(start, start.Add(time.Hour), nil, nil, backend.Clockify(client, "ws"), nil, time.Hour, nil, "")
+
+	msg := app.submitAllocations([]ai.Allocation{{ProjectID: "p1", ProjectName: "Alpha", Minutes: 60, Description: "Deploy"}})().(submitMsg)
+
+	if len(msg.entries) != 1 || msg.entries[0].Minutes != 60 {
+		t.Errorf("expected one 60-minute entry, got %+v", msg.entries)
+	}
+}
+
+func TestPlannedEntries_PastMidnight(t *testing.T) {
+	a := testBatchApp(nil)
+	a.SetSplitAtMidnight(true)
+	planned, err := a.plannedEntries([]ai.BatchAllocation{
+		{Date: "2026-03-02", StartTime: "23:00", EndTime: "01:00", ProjectID: "p1", ProjectName: "Alpha", Minutes: 120, Description: "Release"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(planned) != 2 || planned[1].EndTime.Day() != 3 || planned[1].Minutes != 60 {
+		t.Fatalf("expected a 60+60 split ending on the next day, got %+v", planned)
+	}
+	if a.dayFor(planned[1]) != "2026-03-02" {
+		t.Errorf("the part after midnight should be listed under its work day, got %s", a.dayFor(planned[1]))
+	}
+}
@@ -0,0 +1,162 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/christopherklint97/clockr/internal/clockify"
+)
+
+// ProjectMapping is what the user entered for one new project during
+// onboarding. Empty mappings mean the project was skipped.
+type ProjectMapping struct {
+	Project  clockify.Project
+	Aliases  []string
+	Keywords []string
+	Repos    []string
+}
+
+// Empty reports whether nothing was entered for the project.
+func (m ProjectMapping) Empty() bool {
+	return len(m.Aliases) == 0 && len(m.Keywords) == 0 && len(m.Repos) == 0
+}
+
+// OnboardResult holds the mappings entered for new projects.
+type OnboardResult struct {
+	Mappings []ProjectMapping // one per project that got at least one value
+	Canceled bool
+}
+
+var onboardFields = []string{"Aliases", "Keywords", "GitHub repos"}
+
+// OnboardApp asks for aliases, keywords and repos for each new project.
+type OnboardApp struct {
+	projects []clockify.Project
+	current  int
+	inputs   []textinput.Model
+	focus    int
+	mappings []ProjectMapping
+	result   *OnboardResult
+}
+
+func NewOnboardApp(projects []clockify.Project) *OnboardApp {
+	a := &OnboardApp{projects: projects}
+	a.resetInputs()
+	return a
+}
+
+func (a *OnboardApp) resetInputs() {
+	placeholders := []string{"other names, e.g. acme, acme corp", "words in descriptions, e.g. invoice, billing", "owner/repo, ..."}
+	a.inputs = make([]textinput.Model, len(onboardFields))
+	for i := range a.inputs {
+		ti := textinput.New()
+		ti.Placeholder = placeholders[i]
+		ti.CharLimit = 500
+		ti.Width = 60
+		a.inputs[i] = ti
+	}
+	a.focus = 0
+	a.inputs[0].Focus()
+}
+
+func (a *OnboardApp) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (a *OnboardApp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "ctrl+c":
+			a.result = &OnboardResult{Mappings: a.mappings, Canceled: true}
+			return a, tea.Quit
+		case "esc":
+			return a.next(false)
+		case "tab", "down":
+			return a, a.setFocus((a.focus + 1) % len(a.inputs))
+		case "shift+tab", "up":
+			return a, a.setFocus((a.focus + len(a.inputs) - 1) % len(a.inputs))
+		case "enter":
+			if a.focus < len(a.inputs)-1 {
+				return a, a.setFocus(a.focus + 1)
+			}
+			return a.next(true)
+		}
+	}
+
+	var cmd tea.Cmd
+	a.inputs[a.focus], cmd = a.inputs[a.focus].Update(msg)
+	return a, cmd
+}
+
+func (a *OnboardApp) setFocus(i int) tea.Cmd {
+	a.inputs[a.focus].Blur()
+	a.focus = i
+	return a.inputs[i].Focus()
+}
+
+// next saves the current project's answers (unless skipped) and moves on.
+func (a *OnboardApp) next(save bool) (tea.Model, tea.Cmd) {
+	if save {
+		m := ProjectMapping{
+			Project:  a.projects[a.current],
+			Aliases:  splitList(a.inputs[0].Value()),
+			Keywords: splitList(a.inputs[1].Value()),
+			Repos:    splitList(a.inputs[2].Value()),
+		}
+		if !m.Empty() {
+			a.mappings = append(a.mappings, m)
+		}
+	}
+	a.current++
+	if a.current >= len(a.projects) {
+		a.result = &OnboardResult{Mappings: a.mappings}
+		return a, tea.Quit
+	}
+	a.resetInputs()
+	return a, textinput.Blink
+}
+
+func (a *OnboardApp) View() string {
+	if a.current >= len(a.projects) {
+		return ""
+	}
+	p := a.projects[a.current]
+
+	var sb strings.Builder
+	sb.WriteString(titleStyle.Render(fmt.Sprintf("New project %d of %d", a.current+1, len(a.projects))))
+	sb.WriteString("\n")
+	name := p.Name
+	if p.ClientName != "" {
+		name += " (" + p.ClientName + ")"
+	}
+	sb.WriteString(highlightStyle.Render(name))
+	sb.WriteString("\n\n")
+
+	for i, label := range onboardFields {
+		prefix := "  "
+		if i == a.focus {
+			prefix = "> "
+		}
+		fmt.Fprintf(&sb, "%s%-13s %s\n", prefix, label, a.inputs[i].View())
+	}
+
+	sb.WriteString(helpStyle.Render("comma-separated • tab/enter next field • enter on last field saves • esc skip project • ctrl+c stop"))
+	return boxStyle.Render(sb.String())
+}
+
+func (a *OnboardApp) GetResult() *OnboardResult {
+	return a.result
+}
+
+// splitList splits a comma-separated answer, dropping empty items.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,44 @@
+package tui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/christopherklint97/clockr/internal/clockify"
+)
+
+func typeText(a *OnboardApp, s string) {
+	a.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)})
+}
+
+func TestOnboardApp(t *testing.T) {
+	app := NewOnboardApp([]clockify.Project{{ID: "p1", Name: "Acme"}, {ID: "p2", Name: "Beta"}, {ID: "p3", Name: "Gamma"}})
+
+	// Project 1: alias and repo
+	typeText(app, "acme, acme corp ")
+	app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	typeText(app, "acme/api")
+	app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	// Project 2: skipped
+	typeText(app, "beta")
+	app.Update(tea.KeyMsg{Type: tea.KeyEsc})
+
+	// Project 3: nothing entered
+	app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	_, cmd := app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	result := app.GetResult()
+	if result == nil || cmd == nil {
+		t.Fatal("expected onboarding to finish")
+	}
+	if len(result.Mappings) != 1 {
+		t.Fatalf("expected one mapping, got %+v", result.Mappings)
+	}
+	m := result.Mappings[0]
+	if m.Project.ID != "p1" || len(m.Aliases) != 2 || m.Aliases[1] != "acme corp" || len(m.Repos) != 1 {
+		t.Errorf("unexpected mapping %+v", m)
+	}
+}
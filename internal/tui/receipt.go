@@ -0,0 +1,47 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/christopherklint97/clockr/internal/backend"
+	"github.com/christopherklint97/clockr/internal/store"
+)
+
+// receiptLine describes one submitted entry: time range, project,
+// description, minutes and status. The day is included when multiDay.
+func receiptLine(e store.Entry, multiDay bool) string {
+	when := e.StartTime.Format("15:04") + "–" + e.EndTime.Format("15:04")
+	if multiDay {
+		when = e.StartTime.Format("Mon Jan 2 ") + when
+	}
+	return fmt.Sprintf("%s  %s — %s (%d min)  %s", when, e.ProjectName, e.Description, e.Minutes, e.Status)
+}
+
+func spansDays(entries []store.Entry) bool {
+	return len(entries) > 0 && entries[0].StartTime.Format("2006-01-02") != entries[len(entries)-1].StartTime.Format("2006-01-02")
+}
+
+// Receipt lists the submitted entries for printing once the TUI has exited,
+// each with its link in b's web UI when it was logged and b has one.
+func Receipt(entries []store.Entry, b backend.Backend) string {
+	if len(entries) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	logged := 0
+	for _, e := range entries {
+		if e.Status == "logged" {
+			logged++
+		}
+	}
+	fmt.Fprintf(&sb, "Logged %d of %d entries:\n", logged, len(entries))
+	multiDay := spansDays(entries)
+	for _, e := range entries {
+		sb.WriteString("  " + receiptLine(e, multiDay) + "\n")
+		if link := backend.EntryURL(b, e.ClockifyID); link != "" && e.Status == "logged" {
+			sb.WriteString("    " + link + "\n")
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,52 @@
+package tui
+
+import (
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/christopherklint97/clockr/internal/ai"
+	"github.com/christopherklint97/clockr/internal/backend"
+	"github.com/christopherklint97/clockr/internal/clockify"
+)
+
+func TestReceipt_ListsEntriesWithLinks(t *testing.T) {
+	client, _ := fakeClockify(t)
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	b := backend.Clockify(client, "ws")
+	app := NewApp(start, start.Add(time.Hour), nil, nil, b, nil, time.Hour, nil, "")
+
+	app.handleSubmit(app.submitAllocations([]ai.Allocation{
+		{ProjectID: "p1", ProjectName: "Alpha", Minutes: 30, Description: "Review"},
+		{ProjectID: "bad", ProjectName: "Old", Minutes: 30, Description: "Build"},
+	})().(submitMsg))
+
+	receipt := Receipt(app.result.Entries, b)
+	for _, want := range []string{
+		"Logged 1 of 2 entries:",
+		"Alpha — Review (30 min)  logged",
+		"Old — Build (30 min)  failed",
+		"/tracker?workspaceId=ws&timeEntryId=te-p1",
+	} {
+		if !strings.Contains(receipt, want) {
+			t.Errorf("receipt missing %q:\n%s", want, receipt)
+		}
+	}
+	if strings.Count(receipt, "/tracker?") != 1 {
+		t.Errorf("only the logged entry should get a link:\n%s", receipt)
+	}
+	if view := app.View(); !strings.Contains(view, "timeEntryId=te-p1") {
+		t.Errorf("confirmation should link the logged entry:\n%s", view)
+	}
+}
+
+func TestEntryURL_UsesWebApp(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	client := clockify.NewClient("key", "", time.Hour, logger)
+	got := backend.EntryURL(backend.Clockify(client, "ws1"), "te1")
+	if want := "https://app.clockify.me/tracker?workspaceId=ws1&timeEntryId=te1"; got != want {
+		t.Errorf("EntryURL = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,39 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResume_OpensOnSuggestion(t *testing.T) {
+	now := time.Now()
+	app := NewApp(now.Add(-time.Hour), now, nil, nil, nil, nil, time.Hour, nil, "")
+	app.Resume("code review", suggestionWithConfidence(0.9))
+
+	if app.state != suggestionView {
+		t.Fatalf("state = %v, want the suggestion view", app.state)
+	}
+	if app.input.Value() != "code review" {
+		t.Errorf("description = %q, want it restored for retries", app.input.Value())
+	}
+	if view := app.View(); !strings.Contains(view, "Alpha") {
+		t.Errorf("resumed view should show the saved allocation:\n%s", view)
+	}
+}
+
+func TestSetDescription_StartsAIOnInit(t *testing.T) {
+	now := time.Now()
+	app := NewApp(now.Add(-time.Hour), now, nil, nil, nil, nil, time.Hour, nil, "")
+	app.SetDescription("abc123 fix login redirect")
+
+	if cmd := app.Init(); cmd == nil {
+		t.Fatal("Init should start the AI call")
+	}
+	if app.state != loadingView {
+		t.Errorf("state = %v, want loading without the duration and description prompts", app.state)
+	}
+	if app.input.Value() != "abc123 fix login redirect" {
+		t.Errorf("description = %q, want the piped text", app.input.Value())
+	}
+}
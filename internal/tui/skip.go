@@ -0,0 +1,64 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// skipReasonModel is a quick list of reasons shown when a prompt is skipped.
+type skipReasonModel struct {
+	reasons []string
+	cursor  int
+}
+
+func newSkipReasonModel(reasons []string) skipReasonModel {
+	return skipReasonModel{reasons: reasons}
+}
+
+// Update handles navigation and returns the chosen reason and true once the
+// user picks one. Esc chooses no reason.
+func (m skipReasonModel) Update(msg tea.Msg) (skipReasonModel, string, bool) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, "", false
+	}
+	switch key := keyMsg.String(); key {
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(m.reasons)-1 {
+			m.cursor++
+		}
+	case "enter":
+		return m, m.reasons[m.cursor], true
+	case "esc":
+		return m, "", true
+	default:
+		if len(key) == 1 && key[0] >= '1' && key[0] <= '9' {
+			if i := int(key[0] - '1'); i < len(m.reasons) {
+				return m, m.reasons[i], true
+			}
+		}
+	}
+	return m, "", false
+}
+
+func (m skipReasonModel) View() string {
+	var sb strings.Builder
+	sb.WriteString(titleStyle.Render("Why are you skipping this window?"))
+	sb.WriteString("\n")
+	for i, r := range m.reasons {
+		line := fmt.Sprintf("  %d. %s", i+1, r)
+		if i == m.cursor {
+			line = highlightStyle.Render(fmt.Sprintf("> %d. %s", i+1, r))
+		}
+		sb.WriteString(line)
+		sb.WriteString("\n")
+	}
+	sb.WriteString(helpStyle.Render("↑/↓ + Enter or 1–9: choose • Esc: no reason"))
+	return boxStyle.Render(sb.String())
+}
@@ -0,0 +1,40 @@
+package tui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSkipReasonModel_Enter(t *testing.T) {
+	m := newSkipReasonModel([]string{"lunch", "personal"})
+	m, _, done := m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if done {
+		t.Fatal("moving the cursor should not choose")
+	}
+	_, reason, done := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if !done || reason != "personal" {
+		t.Errorf("got (%q, %v), want (personal, true)", reason, done)
+	}
+}
+
+func TestSkipReasonModel_NumberAndEsc(t *testing.T) {
+	m := newSkipReasonModel([]string{"lunch", "personal"})
+	if _, reason, done := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("1")}); !done || reason != "lunch" {
+		t.Errorf("got (%q, %v), want (lunch, true)", reason, done)
+	}
+	if _, _, done := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("5")}); done {
+		t.Error("out-of-range number should be ignored")
+	}
+	if _, reason, done := m.Update(tea.KeyMsg{Type: tea.KeyEsc}); !done || reason != "" {
+		t.Errorf("got (%q, %v), want (\"\", true)", reason, done)
+	}
+}
+
+func TestApp_SkipWithoutReasons(t *testing.T) {
+	app := &App{state: suggestionView}
+	app.skip()
+	if app.result == nil || !app.result.Skipped || app.result.SkipReason != "" {
+		t.Fatalf("expected immediate skip without reason, got %+v", app.result)
+	}
+}
@@ -0,0 +1,120 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/christopherklint97/clockr/internal/store"
+)
+
+// snippetPickerModel picks a saved snippet ('clockr templates') by typing
+// part of its name or text.
+type snippetPickerModel struct {
+	snippets []store.Snippet
+	filtered []store.Snippet
+	cursor   int
+	input    textinput.Model
+}
+
+func newSnippetPickerModel(snippets []store.Snippet) snippetPickerModel {
+	ti := textinput.New()
+	ti.CharLimit = 100
+	ti.Width = 50
+	ti.Placeholder = "Search snippets..."
+	ti.Focus()
+	return snippetPickerModel{snippets: snippets, filtered: snippets, input: ti}
+}
+
+// Update returns the chosen snippet text and true on enter, or "" and true
+// on esc.
+func (m snippetPickerModel) Update(msg tea.Msg) (snippetPickerModel, string, bool, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "enter":
+			if m.cursor < len(m.filtered) {
+				return m, m.filtered[m.cursor].Text, true, nil
+			}
+			return m, "", false, nil
+		case "esc":
+			return m, "", true, nil
+		case "up", "ctrl+p":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+			return m, "", false, nil
+		case "down", "ctrl+n":
+			if m.cursor < min(len(m.filtered), manualListSize)-1 {
+				m.cursor++
+			}
+			return m, "", false, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	m.filtered = filterSnippets(m.snippets, m.input.Value())
+	if m.cursor >= len(m.filtered) {
+		m.cursor = max(len(m.filtered)-1, 0)
+	}
+	return m, "", false, cmd
+}
+
+func (m snippetPickerModel) View() string {
+	var sb strings.Builder
+	sb.WriteString(highlightStyle.Render("Insert snippet") + "\n\n")
+	sb.WriteString(m.input.View() + "\n\n")
+	if len(m.filtered) == 0 {
+		sb.WriteString(dimStyle.Render("  No snippet matches") + "\n")
+	}
+	for i, s := range m.filtered {
+		if i == manualListSize {
+			sb.WriteString(dimStyle.Render(fmt.Sprintf("  … %d more", len(m.filtered)-manualListSize)) + "\n")
+			break
+		}
+		line := fmt.Sprintf("%s — %s", s.Name, strings.Join(strings.Fields(s.Text), " "))
+		if i == m.cursor {
+			sb.WriteString(selectedStyle.Render("> "+line) + "\n")
+		} else {
+			sb.WriteString("  " + line + "\n")
+		}
+	}
+	sb.WriteString(helpStyle.Render("Enter: insert • ↑/↓: pick • Esc: back"))
+	return sb.String()
+}
+
+// filterSnippets returns the snippets whose name or text fuzzy-matches
+// query, best match first.
+func filterSnippets(snippets []store.Snippet, query string) []store.Snippet {
+	if strings.TrimSpace(query) == "" {
+		return snippets
+	}
+	type scored struct {
+		snippet store.Snippet
+		score   int
+	}
+	var matches []scored
+	for _, s := range snippets {
+		if score, ok := fuzzyScore(query, s.Name+" "+s.Text); ok {
+			matches = append(matches, scored{s, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+	out := make([]store.Snippet, len(matches))
+	for i, m := range matches {
+		out[i] = m.snippet
+	}
+	return out
+}
+
+// insertSnippet adds text at the text area's cursor, after a space when
+// the description so far ends in a word.
+func insertSnippet(m inputModel, text string) inputModel {
+	if v := m.textarea.Value(); v != "" && !strings.HasSuffix(v, " ") && !strings.HasSuffix(v, "\n") {
+		text = " " + text
+	}
+	m.textarea.InsertString(text)
+	return m
+}
@@ -0,0 +1,73 @@
+package tui
+
+import (
+	"time"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/christopherklint97/clockr/internal/ai"
+	"github.com/christopherklint97/clockr/internal/calendar"
+	"github.com/christopherklint97/clockr/internal/clockify"
+)
+
+// Startup is what the prompt needs from Clockify and the context sources.
+type Startup struct {
+	Projects     []clockify.Project
+	ContextItems []ai.ContextItem
+	Events       []calendar.Event // meetings to split the window at
+	Rounding     clockify.Rounding
+	Err          error // projects could not be fetched
+}
+
+type startupMsg Startup
+
+// SetStartup runs load in the background while the user types, instead of
+// before the TUI opens. The AI and the manual form wait for it.
+func (a *App) SetStartup(load func() Startup) {
+	a.startup = load
+	a.input.context.fetching = true
+}
+
+func (a *App) startupCmd() tea.Cmd {
+	load := a.startup
+	return func() tea.Msg { return startupMsg(load()) }
+}
+
+// fetching reports whether the background startup fetch is still running.
+func (a *App) fetching() bool {
+	return a.startup != nil
+}
+
+// waitForStartup shows the spinner until the startup fetch is done, then
+// runs next.
+func (a *App) waitForStartup(next func() (tea.Model, tea.Cmd)) (tea.Model, tea.Cmd) {
+	a.afterStartup = next
+	a.state = loadingView
+	a.thinkingText = ""
+	a.loadingStartTime = time.Now()
+	a.viewport = viewport.New(a.termWidth, max(a.termHeight-3, 1))
+	return a, tea.Batch(a.spinner.Tick, tickCmd())
+}
+
+func (a *App) handleStartup(msg startupMsg) (tea.Model, tea.Cmd) {
+	a.startup = nil
+	a.input.context.fetching = false
+	if msg.Err != nil {
+		a.state = confirmationView
+		a.errMsg = msg.Err.Error()
+		return a, nil
+	}
+	a.projects = msg.Projects
+	a.contextItems = append(a.contextItems, msg.ContextItems...)
+	a.input.context = a.input.context.withItems(a.contextItems)
+	a.SetMeetings(msg.Events)
+	a.rounding = msg.Rounding
+
+	next := a.afterStartup
+	a.afterStartup = nil
+	if next == nil {
+		return a, nil
+	}
+	return next()
+}
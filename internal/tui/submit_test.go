@@ -0,0 +1,70 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/christopherklint97/clockr/internal/ai"
+	"github.com/christopherklint97/clockr/internal/backend"
+)
+
+func TestSubmit_ShowsRejectionAndRetriesFix(t *testing.T) {
+	client, _ := fakeClockify(t)
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	app := NewApp(start, start.Add(time.Hour), nil, nil, backend.Clockify(client, "ws"), nil, time.Hour, nil, "")
+
+	app.handleSubmit(app.submitAllocations([]ai.Allocation{
+		{ProjectID: "p1", ProjectName: "Alpha", Minutes: 30, Description: "Review"},
+		{ProjectID: "bad", ProjectName: "Old", Minutes: 30, Description: "Build"},
+	})().(submitMsg))
+
+	view := app.View()
+	if !strings.Contains(view, "Logged 1 of 2 entries") || !strings.Contains(view, "project is archived") {
+		t.Fatalf("confirmation should explain the rejected entry:\n%s", view)
+	}
+
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("e")})
+	if app.state != editView || len(app.edit.allocations) != 1 || app.edit.allocations[0].ProjectID != "bad" {
+		t.Fatalf("e should open the failed entry for editing, state %v allocations %+v", app.state, app.edit.allocations)
+	}
+
+	fixed := []ai.Allocation{{ProjectID: "p2", ProjectName: "Beta", Minutes: 30, Description: "Build"}}
+	app.handleSubmit(app.submitAllocations(fixed)().(submitMsg))
+
+	entries := app.result.Entries
+	if len(entries) != 2 || app.failedCount() != 0 {
+		t.Fatalf("entries = %+v, errs %q; want both logged", entries, app.submitErrs)
+	}
+	if !entries[1].StartTime.Equal(start.Add(30 * time.Minute)) {
+		t.Errorf("fixed entry starts at %v, want where the failed one did", entries[1].StartTime)
+	}
+}
+
+func TestSubmit_FixStepsOverLoggedEntries(t *testing.T) {
+	client, _ := fakeClockify(t)
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	app := NewApp(start, start.Add(time.Hour), nil, nil, backend.Clockify(client, "ws"), nil, time.Hour, nil, "")
+
+	// The first entry fails and the second is logged at 09:20–10:00.
+	app.handleSubmit(app.submitAllocations([]ai.Allocation{
+		{ProjectID: "bad", ProjectName: "Old", Minutes: 20, Description: "Build"},
+		{ProjectID: "p1", ProjectName: "Alpha", Minutes: 40, Description: "Review"},
+	})().(submitMsg))
+	if !app.startTime.Equal(start) {
+		t.Errorf("submitting moved the window start to %v", app.startTime)
+	}
+
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("e")})
+	fixed := []ai.Allocation{{ProjectID: "p2", ProjectName: "Beta", Minutes: 30, Description: "Build"}}
+	app.handleSubmit(app.submitAllocations(fixed)().(submitMsg))
+
+	// Only 09:00–09:20 is free; the rest would run past the window.
+	if len(app.result.Entries) != 2 {
+		t.Fatalf("entries = %+v", app.result.Entries)
+	}
+	if got := app.result.Entries[1]; got.ProjectID != "p2" || !got.StartTime.Equal(start) || !got.EndTime.Equal(start.Add(20*time.Minute)) || got.Minutes != 20 {
+		t.Errorf("fixed entry = %+v, want 09:00–09:20 before the logged one", got)
+	}
+}
@@ -6,6 +6,8 @@ import (
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/christopherklint97/clockr/internal/ai"
+	"github.com/christopherklint97/clockr/internal/clockify"
+	"github.com/christopherklint97/clockr/internal/format"
 )
 
 // truncate shortens s to maxWidth display characters, appending "..." if truncated.
@@ -39,16 +41,22 @@ type suggestionsModel struct {
 	suggestion *ai.Suggestion
 	cursor     int
 	termWidth  int
+	status     string                      // feedback line, e.g. after copying a description
+	previous   []ai.Allocation             // the run before a retry, diffed against; nil on the first run
+	countdown  int                         // seconds until auto-accept; 0 when not counting down
+	archived   map[string]clockify.Project // projects under an archived client
+	rounding   clockify.Rounding           // workspace rounding applied to the minutes
+	formatter  *format.Formatter           // flags descriptions breaking max_length or pattern
 }
 
-func newSuggestionsModel(s *ai.Suggestion) suggestionsModel {
-	return suggestionsModel{suggestion: s}
+func newSuggestionsModel(s *ai.Suggestion, projects []clockify.Project) suggestionsModel {
+	return suggestionsModel{suggestion: s, archived: archivedClientProjects(projects)}
 }
 
 func (m suggestionsModel) View() string {
 	if m.suggestion.Clarification != "" {
 		return warningStyle.Render("Clarification needed: ") + m.suggestion.Clarification + "\n\n" +
-			helpStyle.Render("[r]etry with more detail • [s]kip")
+			helpStyle.Render("[c] answer • [r]etry with more detail • [s]kip")
 	}
 
 	var sb strings.Builder
@@ -80,12 +88,28 @@ func (m suggestionsModel) View() string {
 		maxDesc = max(maxDesc, len(a.Description))
 	}
 
+	// After a retry, mark what changed relative to the previous run
+	var changes []allocChange
+	var removed []diffRow
+	maxDelta := 0
+	diffWidth := 0
+	if m.previous != nil {
+		changes, removed = diffRows(allocationRows(m.previous), allocationRows(m.suggestion.Allocations))
+		for _, c := range changes {
+			maxDelta = max(maxDelta, len(c.deltaText()))
+		}
+		diffWidth = 2 // mark + space
+		if maxDelta > 0 {
+			diffWidth += maxDelta + 2
+		}
+	}
+
 	// Truncate columns to fit terminal width
-	// Layout: prefix(2) + project + gap(2) + minutes + gap(2) + confidence(4) + gap(2) + desc
+	// Layout: prefix(2) + [mark(2)] + project + gap(2) + minutes + [gap(2) + delta] + gap(2) + confidence(4) + gap(2) + desc
 	// Box overhead: border(2) + padding(2) = 4
 	if m.termWidth > 0 {
 		available := m.termWidth - 4
-		fixed := 12 + maxMinutes // prefix(2) + 3 gaps(6) + confidence(4) + minutes
+		fixed := 12 + maxMinutes + diffWidth // prefix(2) + 3 gaps(6) + confidence(4) + minutes
 		remaining := available - fixed
 		if remaining < maxProject+maxDesc {
 			projectCap := min(maxProject, 35)
@@ -109,10 +133,20 @@ func (m suggestionsModel) View() string {
 			prefix = "> "
 		}
 
-		line := fmt.Sprintf("%s%-*s  %*s  %s  %s",
+		mark, delta := "", ""
+		if changes != nil {
+			mark = changes[i].mark() + " "
+			if maxDelta > 0 {
+				delta = "  " + changes[i].renderDelta(maxDelta)
+			}
+		}
+
+		line := fmt.Sprintf("%s%s%-*s  %*s%s  %s  %s",
 			prefix,
+			mark,
 			maxProject, r.project,
 			maxMinutes, r.minutes,
+			delta,
 			dimStyle.Render(fmt.Sprintf("%4s", r.confidence)),
 			r.desc,
 		)
@@ -125,8 +159,25 @@ func (m suggestionsModel) View() string {
 		sb.WriteString("\n")
 	}
 
+	if m.previous != nil {
+		sb.WriteString("\n")
+		sb.WriteString(renderDiffFooter(changes, removed))
+	}
+
 	sb.WriteString("\n")
-	sb.WriteString(helpStyle.Render("[a]ccept • [e]dit • [r]etry • [s]kip"))
+	sb.WriteString(archivedClientWarning(allocationProjectIDs(m.suggestion.Allocations), m.archived))
+	sb.WriteString(descriptionWarning(m.formatter, allocationDescriptions(m.suggestion.Allocations)))
+	sb.WriteString(problemsWarning(m.suggestion.Problems))
+	sb.WriteString(roundingNote(m.rounding))
+	if m.countdown > 0 {
+		sb.WriteString(warningStyle.Render(fmt.Sprintf("Auto-accepting in %ds — press any key to review", m.countdown)))
+		sb.WriteString("\n")
+	}
+	if m.status != "" {
+		sb.WriteString(m.status)
+		sb.WriteString("\n")
+	}
+	sb.WriteString(helpStyle.Render("[a]ccept • [e]dit • [g] regenerate row • [y] copy • [r]etry • [s]kip"))
 
 	return boxStyle.Render(sb.String())
 }